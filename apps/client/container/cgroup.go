@@ -0,0 +1,109 @@
+package container
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Where cgroups are mounted inside a container
+const cgroupRoot = "/sys/fs/cgroup"
+
+// Limits above this are "unlimited" in cgroup v1, which has no max keyword
+const unlimitedMemory = math.MaxInt64 / 2
+
+func readTrimmed(path string) (string, bool) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(raw)), true
+}
+
+// Number of CPUs the cgroup at root may use, false if it isn't limited
+func cpuLimit(root string) (float64, bool) {
+	var quota, period float64
+	if raw, ok := readTrimmed(filepath.Join(root, "cpu.max")); ok {
+		// cgroup v2: "<quota> <period>" or "max <period>"
+		parts := strings.Fields(raw)
+		if len(parts) != 2 || parts[0] == "max" {
+			return 0, false
+		}
+		var err error
+		if quota, err = strconv.ParseFloat(parts[0], 64); err != nil {
+			return 0, false
+		}
+		if period, err = strconv.ParseFloat(parts[1], 64); err != nil {
+			return 0, false
+		}
+	} else {
+		// cgroup v1, a quota of -1 means unlimited
+		rawQuota, ok := readTrimmed(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+		if !ok {
+			return 0, false
+		}
+		rawPeriod, ok := readTrimmed(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+		if !ok {
+			return 0, false
+		}
+		var err error
+		if quota, err = strconv.ParseFloat(rawQuota, 64); err != nil || quota <= 0 {
+			return 0, false
+		}
+		if period, err = strconv.ParseFloat(rawPeriod, 64); err != nil {
+			return 0, false
+		}
+	}
+	if quota <= 0 || period <= 0 {
+		return 0, false
+	}
+	return quota / period, true
+}
+
+// Bytes of memory the cgroup at root may use, false if it isn't limited
+func memoryLimit(root string) (int64, bool) {
+	raw, ok := readTrimmed(filepath.Join(root, "memory.max"))
+	if !ok {
+		raw, ok = readTrimmed(filepath.Join(root, "memory", "memory.limit_in_bytes"))
+		if !ok {
+			return 0, false
+		}
+	}
+	if raw == "max" {
+		return 0, false
+	}
+	limit, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || limit <= 0 || limit >= unlimitedMemory {
+		return 0, false
+	}
+	return limit, true
+}
+
+func workerThreads(root string, numCPU int) int {
+	limit, ok := cpuLimit(root)
+	if !ok {
+		return numCPU
+	}
+	// Running more threads than the quota allows only gets them throttled
+	threads := int(math.Ceil(limit))
+	if threads > numCPU {
+		return numCPU
+	}
+	if threads < 1 {
+		return 1
+	}
+	return threads
+}
+
+// Number of CPU worker threads to run, respects the container's CPU quota
+func WorkerThreads() int {
+	return workerThreads(cgroupRoot, runtime.NumCPU())
+}
+
+// The container's memory limit in bytes, false if there is none
+func MemoryLimit() (int64, bool) {
+	return memoryLimit(cgroupRoot)
+}
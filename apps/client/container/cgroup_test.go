@@ -0,0 +1,61 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCgroupV2(t *testing.T) {
+	root := t.TempDir()
+	// No cgroup files
+	utils.AssertEqual(t, 8, workerThreads(root, 8))
+	_, ok := memoryLimit(root)
+	utils.AssertEqual(t, false, ok)
+
+	writeFile(t, filepath.Join(root, "cpu.max"), "max 100000\n")
+	writeFile(t, filepath.Join(root, "memory.max"), "max\n")
+	utils.AssertEqual(t, 8, workerThreads(root, 8))
+	_, ok = memoryLimit(root)
+	utils.AssertEqual(t, false, ok)
+
+	writeFile(t, filepath.Join(root, "cpu.max"), "150000 100000\n")
+	writeFile(t, filepath.Join(root, "memory.max"), "536870912\n")
+	utils.AssertEqual(t, 2, workerThreads(root, 8))
+	limit, ok := memoryLimit(root)
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, int64(536870912), limit)
+
+	// The quota can't give us more CPUs than we have
+	writeFile(t, filepath.Join(root, "cpu.max"), "1600000 100000\n")
+	utils.AssertEqual(t, 8, workerThreads(root, 8))
+
+	writeFile(t, filepath.Join(root, "cpu.max"), "10000 100000\n")
+	utils.AssertEqual(t, 1, workerThreads(root, 8))
+}
+
+func TestCgroupV1(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "cpu", "cpu.cfs_quota_us"), "-1\n")
+	writeFile(t, filepath.Join(root, "cpu", "cpu.cfs_period_us"), "100000\n")
+	writeFile(t, filepath.Join(root, "memory", "memory.limit_in_bytes"), "9223372036854771712\n")
+	utils.AssertEqual(t, 4, workerThreads(root, 4))
+	_, ok := memoryLimit(root)
+	utils.AssertEqual(t, false, ok)
+
+	writeFile(t, filepath.Join(root, "cpu", "cpu.cfs_quota_us"), "300000\n")
+	writeFile(t, filepath.Join(root, "memory", "memory.limit_in_bytes"), "1073741824\n")
+	utils.AssertEqual(t, 3, workerThreads(root, 4))
+	limit, ok := memoryLimit(root)
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, int64(1073741824), limit)
+}
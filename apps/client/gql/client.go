@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/Khan/genqlient/graphql"
+	"github.com/bananocoin/boompow/apps/client/i18n"
 )
 
 type GQLError string
@@ -14,6 +15,8 @@ type GQLError string
 const (
 	InvalidUsernamePasssword GQLError = "Invalid username or password"
 	ServerError                       = "Unknown server error, try again later"
+	TwoFactorRequired                 = "Two factor code required"
+	InvalidTwoFactorCode              = "Invalid two factor code"
 )
 
 var client graphql.Client
@@ -36,33 +39,48 @@ func InitGQLClientWithToken(url string, token string) {
 	client = graphql.NewClient(url, &http.Client{Transport: &authedTransport{wrapped: http.DefaultTransport, token: token}})
 }
 
-func Login(ctx context.Context, email string, password string) (*loginUserResponse, GQLError) {
+// twoFactorCode is only needed for accounts with two factor authentication, leave it empty otherwise
+func Login(ctx context.Context, email string, password string, twoFactorCode string) (*loginUserResponse, GQLError) {
 	resp, err := loginUser(ctx, client, LoginInput{
-		Email:    email,
-		Password: password,
+		Email:         email,
+		Password:      password,
+		TwoFactorCode: twoFactorCode,
 	})
 
 	if err != nil {
-		fmt.Printf("Error logging in %v", err)
+		if strings.Contains(err.Error(), "two_factor_required") {
+			return nil, TwoFactorRequired
+		}
+		fmt.Print(i18n.T(i18n.LoginError, err))
 		if strings.Contains(err.Error(), "invalid email or password") {
 			return nil, InvalidUsernamePasssword
 		}
+		if strings.Contains(err.Error(), "invalid two factor code") {
+			return nil, InvalidTwoFactorCode
+		}
 		return nil, ServerError
 	}
 
 	return resp, ""
 }
 
-func RefreshToken(ctx context.Context, token string) (string, error) {
+// Returns a new access token and the refresh token to use next time, a refresh token only works once
+func RefreshToken(ctx context.Context, token string) (string, string, error) {
 	resp, err := refreshToken(ctx, client, RefreshTokenInput{
-		Token: token,
+		RefreshToken: token,
 	})
 
 	if err != nil {
-		fmt.Printf("\nError refreshing authentication token! You may need to restart the client and re-login %v", err)
-		return "", err
+		fmt.Print(i18n.T(i18n.TokenRefreshError, err))
+		return "", "", err
 	}
-	fmt.Printf("\n👮 Refreshed authentication token")
+	fmt.Print(i18n.T(i18n.TokenRefreshed))
+
+	return resp.RefreshToken.Token, resp.RefreshToken.RefreshToken, nil
+}
 
-	return resp.RefreshToken, nil
+// Shares a benchmark result with the server's hardware leaderboard, needs a client with a token
+func SubmitBenchmark(ctx context.Context, input BenchmarkInput) error {
+	_, err := submitBenchmark(ctx, client, input)
+	return err
 }
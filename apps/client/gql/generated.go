@@ -8,9 +8,44 @@ import (
 	"github.com/Khan/genqlient/graphql"
 )
 
+type BenchmarkBackend string
+
+const (
+	BenchmarkBackendGpu BenchmarkBackend = "GPU"
+	BenchmarkBackendCpu BenchmarkBackend = "CPU"
+)
+
+type BenchmarkInput struct {
+	Hardware             string           `json:"hardware"`
+	Backend              BenchmarkBackend `json:"backend"`
+	DifficultyMultiplier int              `json:"difficultyMultiplier"`
+	Runs                 int              `json:"runs"`
+	AverageSeconds       float64          `json:"averageSeconds"`
+	ClientVersion        string           `json:"clientVersion"`
+}
+
+// GetHardware returns BenchmarkInput.Hardware, and is useful for accessing the field via an interface.
+func (v *BenchmarkInput) GetHardware() string { return v.Hardware }
+
+// GetBackend returns BenchmarkInput.Backend, and is useful for accessing the field via an interface.
+func (v *BenchmarkInput) GetBackend() BenchmarkBackend { return v.Backend }
+
+// GetDifficultyMultiplier returns BenchmarkInput.DifficultyMultiplier, and is useful for accessing the field via an interface.
+func (v *BenchmarkInput) GetDifficultyMultiplier() int { return v.DifficultyMultiplier }
+
+// GetRuns returns BenchmarkInput.Runs, and is useful for accessing the field via an interface.
+func (v *BenchmarkInput) GetRuns() int { return v.Runs }
+
+// GetAverageSeconds returns BenchmarkInput.AverageSeconds, and is useful for accessing the field via an interface.
+func (v *BenchmarkInput) GetAverageSeconds() float64 { return v.AverageSeconds }
+
+// GetClientVersion returns BenchmarkInput.ClientVersion, and is useful for accessing the field via an interface.
+func (v *BenchmarkInput) GetClientVersion() string { return v.ClientVersion }
+
 type LoginInput struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email         string `json:"email"`
+	Password      string `json:"password"`
+	TwoFactorCode string `json:"twoFactorCode"`
 }
 
 // GetEmail returns LoginInput.Email, and is useful for accessing the field via an interface.
@@ -19,12 +54,15 @@ func (v *LoginInput) GetEmail() string { return v.Email }
 // GetPassword returns LoginInput.Password, and is useful for accessing the field via an interface.
 func (v *LoginInput) GetPassword() string { return v.Password }
 
+// GetTwoFactorCode returns LoginInput.TwoFactorCode, and is useful for accessing the field via an interface.
+func (v *LoginInput) GetTwoFactorCode() string { return v.TwoFactorCode }
+
 type RefreshTokenInput struct {
-	Token string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
 }
 
-// GetToken returns RefreshTokenInput.Token, and is useful for accessing the field via an interface.
-func (v *RefreshTokenInput) GetToken() string { return v.Token }
+// GetRefreshToken returns RefreshTokenInput.RefreshToken, and is useful for accessing the field via an interface.
+func (v *RefreshTokenInput) GetRefreshToken() string { return v.RefreshToken }
 
 // __loginUserInput is used internally by genqlient
 type __loginUserInput struct {
@@ -42,14 +80,26 @@ type __refreshTokenInput struct {
 // GetInput returns __refreshTokenInput.Input, and is useful for accessing the field via an interface.
 func (v *__refreshTokenInput) GetInput() RefreshTokenInput { return v.Input }
 
+// __submitBenchmarkInput is used internally by genqlient
+type __submitBenchmarkInput struct {
+	Input BenchmarkInput `json:"input"`
+}
+
+// GetInput returns __submitBenchmarkInput.Input, and is useful for accessing the field via an interface.
+func (v *__submitBenchmarkInput) GetInput() BenchmarkInput { return v.Input }
+
 // loginUserLoginLoginResponse includes the requested fields of the GraphQL type LoginResponse.
 type loginUserLoginLoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
 }
 
 // GetToken returns loginUserLoginLoginResponse.Token, and is useful for accessing the field via an interface.
 func (v *loginUserLoginLoginResponse) GetToken() string { return v.Token }
 
+// GetRefreshToken returns loginUserLoginLoginResponse.RefreshToken, and is useful for accessing the field via an interface.
+func (v *loginUserLoginLoginResponse) GetRefreshToken() string { return v.RefreshToken }
+
 // loginUserResponse is returned by loginUser on success.
 type loginUserResponse struct {
 	Login loginUserLoginLoginResponse `json:"login"`
@@ -58,13 +108,35 @@ type loginUserResponse struct {
 // GetLogin returns loginUserResponse.Login, and is useful for accessing the field via an interface.
 func (v *loginUserResponse) GetLogin() loginUserLoginLoginResponse { return v.Login }
 
+// refreshTokenRefreshTokenTokenPair includes the requested fields of the GraphQL type TokenPair.
+type refreshTokenRefreshTokenTokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// GetToken returns refreshTokenRefreshTokenTokenPair.Token, and is useful for accessing the field via an interface.
+func (v *refreshTokenRefreshTokenTokenPair) GetToken() string { return v.Token }
+
+// GetRefreshToken returns refreshTokenRefreshTokenTokenPair.RefreshToken, and is useful for accessing the field via an interface.
+func (v *refreshTokenRefreshTokenTokenPair) GetRefreshToken() string { return v.RefreshToken }
+
 // refreshTokenResponse is returned by refreshToken on success.
 type refreshTokenResponse struct {
-	RefreshToken string `json:"refreshToken"`
+	RefreshToken refreshTokenRefreshTokenTokenPair `json:"refreshToken"`
 }
 
 // GetRefreshToken returns refreshTokenResponse.RefreshToken, and is useful for accessing the field via an interface.
-func (v *refreshTokenResponse) GetRefreshToken() string { return v.RefreshToken }
+func (v *refreshTokenResponse) GetRefreshToken() refreshTokenRefreshTokenTokenPair {
+	return v.RefreshToken
+}
+
+// submitBenchmarkResponse is returned by submitBenchmark on success.
+type submitBenchmarkResponse struct {
+	SubmitBenchmark bool `json:"submitBenchmark"`
+}
+
+// GetSubmitBenchmark returns submitBenchmarkResponse.SubmitBenchmark, and is useful for accessing the field via an interface.
+func (v *submitBenchmarkResponse) GetSubmitBenchmark() bool { return v.SubmitBenchmark }
 
 func loginUser(
 	ctx context.Context,
@@ -77,6 +149,7 @@ func loginUser(
 mutation loginUser ($input: LoginInput!) {
 	login(input: $input) {
 		token
+		refreshToken
 	}
 }
 `,
@@ -107,7 +180,10 @@ func refreshToken(
 		OpName: "refreshToken",
 		Query: `
 mutation refreshToken ($input: RefreshTokenInput!) {
-	refreshToken(input: $input)
+	refreshToken(input: $input) {
+		token
+		refreshToken
+	}
 }
 `,
 		Variables: &__refreshTokenInput{
@@ -127,3 +203,33 @@ mutation refreshToken ($input: RefreshTokenInput!) {
 
 	return &data, err
 }
+
+func submitBenchmark(
+	ctx context.Context,
+	client graphql.Client,
+	input BenchmarkInput,
+) (*submitBenchmarkResponse, error) {
+	req := &graphql.Request{
+		OpName: "submitBenchmark",
+		Query: `
+mutation submitBenchmark ($input: BenchmarkInput!) {
+	submitBenchmark(input: $input)
+}
+`,
+		Variables: &__submitBenchmarkInput{
+			Input: input,
+		},
+	}
+	var err error
+
+	var data submitBenchmarkResponse
+	resp := &graphql.Response{Data: &data}
+
+	err = client.MakeRequest(
+		ctx,
+		req,
+		resp,
+	)
+
+	return &data, err
+}
@@ -0,0 +1,38 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bananocoin/boompow/apps/client/i18n"
+	"github.com/bananocoin/boompow/apps/client/logging"
+)
+
+type Status struct {
+	// Connected to the server and able to receive work
+	Connected bool `json:"connected"`
+	// Work requests waiting to be computed
+	Queued int `json:"queued"`
+}
+
+func handler(status func() Status) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		current := status()
+		w.Header().Set("Content-Type", "application/json")
+		if !current.Connected {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(current)
+	}
+}
+
+// Serve exposes /healthz on addr for container orchestration, it answers 503 while we are not connected to the server
+func Serve(addr string, status func() Status) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handler(status))
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			logging.Event("health_error", logging.Fields{"error": err.Error()}, i18n.Format(i18n.HealthError), err)
+		}
+	}()
+}
@@ -0,0 +1,27 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestHealthz(t *testing.T) {
+	status := Status{Connected: false, Queued: 2}
+	h := handler(func() Status { return status })
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	utils.AssertEqual(t, http.StatusServiceUnavailable, rec.Code)
+
+	status.Connected = true
+	rec = httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	utils.AssertEqual(t, http.StatusOK, rec.Code)
+	var body Status
+	utils.AssertEqual(t, nil, json.NewDecoder(rec.Body).Decode(&body))
+	utils.AssertEqual(t, status, body)
+}
@@ -0,0 +1,71 @@
+package i18n
+
+var de = map[Message]string{
+	Exiting:            "👋 Beende...\n",
+	InvalidLanguage:    "⚠️ Ungültiges lang-Argument: %v\n",
+	InvalidGPUArgument: "⚠️ Ungültiges GPU-Argument - keine Zahl: %s",
+	InvalidServers:     "⚠️ Ungültiges servers-Argument: %v\n",
+	GPUListed:          "\n⚡ GPU %d",
+	GPUUsing:           "\n⚡ Verwende GPU %d",
+	GPUPlatform:        "\nPlattform: %s",
+	GPUVendor:          "\nHersteller: %s",
+	GPUDriver:          "\nTreiber: %s",
+	NoGPUFound:         "\n🚨 Keine GPU gefunden!",
+	NoGPUSelected:      "\n🚨 Keine GPU gefunden oder ungültige GPU ausgewählt!",
+	NoGPUHint:          "\nDieser Fehler kann ignoriert werden, wenn PoW nur auf der CPU berechnet werden soll\nAndernfalls prüfe, ob die GPU-Treiber richtig installiert sind und ob dein Gerät OpenCL 2.0 unterstützt\n\n",
+	GPUUnusable:        "\n⚠️ GPU kann nicht verwendet werden %v",
+	NoGPUInitialized:   "\n⚠️ Keine GPU konnte initialisiert werden, verwende die CPU",
+	CPUThreads:         "\n🧮 Verwende %d CPU-Threads (%s %s)",
+	UsingGPUOnly:       "\nVerwende nur die GPU für work_generate...\n\n",
+	UsingCPUOnly:       "\nVerwende nur die CPU für work_generate...\n\n",
+	UsingGPUAndCPU:     "\nVerwende GPU+CPU für work_generate...\n\n",
+	BenchmarkRun:       "\nDurchlauf %d",
+	BenchmarkTook:      "\nDauer: %fs",
+	BenchmarkAverage:   "\n\nDurchschnitt: %fs",
+	BenchmarkError:     "💥 Fehler beim Senden des Benchmarks %v\n",
+	BenchmarkSubmitted: "📊 Benchmark für %s gesendet, danke!\n",
+
+	EnterEmail:           "➡️ E-Mail eingeben: ",
+	ErrorReadingEmail:    "\n⚠️ Fehler beim Lesen der E-Mail",
+	InvalidEmail:         "\n⚠️ Ungültige E-Mail\n\n",
+	EnterPassword:        "➡️ Passwort eingeben: ",
+	ErrorReadingPassword: "\n⚠️ Fehler beim Lesen des Passworts",
+	LoggingIn:            "\n\n🔒 Anmeldung...",
+	ServerUnreachableTry: "\n💥 Server nicht erreichbar, versuche %s",
+	EnterTwoFactorCode:   "\n➡️ Zwei-Faktor-Code eingeben: ",
+	ErrorReadingCode:     "\n⚠️ Fehler beim Lesen des Zwei-Faktor-Codes",
+	TwoFactorRequired:    "\n❌ Zwei-Faktor-Code erforderlich\n\n",
+	InvalidTwoFactorCode: "\n❌ Ungültiger Zwei-Faktor-Code\n\n",
+	InvalidCredentials:   "\n❌ Ungültige E-Mail oder ungültiges Passwort\n\n",
+	ServerUnreachable:    "\n💥 Server nicht erreichbar, versuche es später erneut\n",
+	LoginError:           "Fehler bei der Anmeldung %v",
+	LoggedIn:             "\n\n🔓 Erfolgreich angemeldet als %s\n\n",
+	TokenRefreshError:    "\nFehler beim Erneuern des Authentifizierungstokens! Eventuell muss der Client neu gestartet und die Anmeldung wiederholt werden %v",
+	TokenRefreshed:       "\n👮 Authentifizierungstoken erneuert",
+
+	Starting:            "\n🚀 Verbinde mit BoomPOW...",
+	HelloError:          "Fehler: hello %v",
+	HelloAck:            "\n🤝 Verwende Protokollversion %d mit %s",
+	WSClosed:            "Websocket geschlossen %s",
+	WSDisconnected:      "Websocket getrennt %s",
+	WSReadError:         "Fehler: ReadJSON %s",
+	ProtocolRejected:    "\n🚨 Der Server hat unsere Verbindung abgelehnt: %s",
+	ServerDisconnected:  "\n🔌 Der Server hat unsere Verbindung geschlossen: %s, verbinde neu",
+	WorkRejected:        "\n🚫 Lehne fehlerhafte Arbeitsanfrage %s ab: %s",
+	WorkAboveMax:        "\n😒 Ignoriere Arbeitsanfrage %s mit Schwierigkeit %dx über unserem Maximum von %dx",
+	WorkBelowMin:        "\n😒 Ignoriere Arbeitsanfrage %s mit Schwierigkeit %dx unter unserem Minimum von %dx",
+	WorkPrecache:        "\n😒 Ignoriere Precache-Anfrage %s",
+	WorkReceived:        "\n🦋 Arbeitsanfrage %s mit Schwierigkeit %dx erhalten",
+	BacklogFull:         "\nWarteschlange ist voll, überspringe Hash %s",
+	BlockAwarded:        "\n💰 Belohnten Block erhalten %s\n💰 Deine nächste geschätzte Auszahlung beträgt %f%% oder %f BAN",
+	PreferServerIgnored: "\n😒 Ignoriere Anfrage zum Wechsel auf den unbekannten Server %s",
+	PreferServer:        "\n🔀 Wechsle zu Server %s",
+	UnknownMessage:      "\n🦋 Unbekannte Nachricht erhalten %s\n",
+	ResultError:         "\n❌ Fehler: beim Senden des Ergebnisses für %s %v",
+	WorkError:           "\n❌ Fehler: beim Erzeugen der Arbeit für %s\n",
+	WorkTimeout:         "\n❌ Fehler: länger als %s gebraucht, um die Arbeit für %s zu erzeugen",
+	WorkCancelled:       "\n🛑 Ein anderer Worker hat %s gelöst, Arbeit daran abgebrochen",
+	ResultFallback:      "\n📮 Websocket ist nicht verfügbar, sende Ergebnis für %s über HTTP",
+	HeartbeatError:      "Fehler: heartbeat %v",
+	HealthError:         "\n❌ Health-Endpunkt wurde beendet %v\n",
+}
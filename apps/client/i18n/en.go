@@ -0,0 +1,144 @@
+package i18n
+
+const (
+	// Setup
+	Exiting            Message = "exiting"
+	InvalidLanguage    Message = "invalid_language"
+	InvalidGPUArgument Message = "invalid_gpu_argument"
+	InvalidServers     Message = "invalid_servers"
+	GPUListed          Message = "gpu_listed"
+	GPUUsing           Message = "gpu_using"
+	GPUPlatform        Message = "gpu_platform"
+	GPUVendor          Message = "gpu_vendor"
+	GPUDriver          Message = "gpu_driver"
+	NoGPUFound         Message = "no_gpu_found"
+	NoGPUSelected      Message = "no_gpu_selected"
+	NoGPUHint          Message = "no_gpu_hint"
+	GPUUnusable        Message = "gpu_unusable"
+	NoGPUInitialized   Message = "no_gpu_initialized"
+	CPUThreads         Message = "cpu_threads"
+	UsingGPUOnly       Message = "using_gpu_only"
+	UsingCPUOnly       Message = "using_cpu_only"
+	UsingGPUAndCPU     Message = "using_gpu_and_cpu"
+	BenchmarkRun       Message = "benchmark_run"
+	BenchmarkTook      Message = "benchmark_took"
+	BenchmarkAverage   Message = "benchmark_average"
+	BenchmarkError     Message = "benchmark_error"
+	BenchmarkSubmitted Message = "benchmark_submitted"
+
+	// Login
+	EnterEmail           Message = "enter_email"
+	ErrorReadingEmail    Message = "error_reading_email"
+	InvalidEmail         Message = "invalid_email"
+	EnterPassword        Message = "enter_password"
+	ErrorReadingPassword Message = "error_reading_password"
+	LoggingIn            Message = "logging_in"
+	ServerUnreachableTry Message = "server_unreachable_try"
+	EnterTwoFactorCode   Message = "enter_two_factor_code"
+	ErrorReadingCode     Message = "error_reading_code"
+	TwoFactorRequired    Message = "two_factor_required"
+	InvalidTwoFactorCode Message = "invalid_two_factor_code"
+	InvalidCredentials   Message = "invalid_credentials"
+	ServerUnreachable    Message = "server_unreachable"
+	LoginError           Message = "login_error"
+	LoggedIn             Message = "logged_in"
+	TokenRefreshError    Message = "token_refresh_error"
+	TokenRefreshed       Message = "token_refreshed"
+
+	// Working
+	Starting            Message = "starting"
+	HelloError          Message = "hello_error"
+	HelloAck            Message = "hello_ack"
+	WSClosed            Message = "ws_closed"
+	WSDisconnected      Message = "ws_disconnected"
+	WSReadError         Message = "ws_read_error"
+	ProtocolRejected    Message = "protocol_rejected"
+	ServerDisconnected  Message = "server_disconnected"
+	WorkRejected        Message = "work_rejected"
+	WorkAboveMax        Message = "work_above_max"
+	WorkBelowMin        Message = "work_below_min"
+	WorkPrecache        Message = "work_precache"
+	WorkReceived        Message = "work_received"
+	BacklogFull         Message = "backlog_full"
+	BlockAwarded        Message = "block_awarded"
+	PreferServerIgnored Message = "prefer_server_ignored"
+	PreferServer        Message = "prefer_server"
+	UnknownMessage      Message = "unknown_message"
+	ResultError         Message = "result_error"
+	WorkError           Message = "work_error"
+	WorkTimeout         Message = "work_timeout"
+	WorkCancelled       Message = "work_cancelled"
+	ResultFallback      Message = "result_fallback"
+	HeartbeatError      Message = "heartbeat_error"
+	HealthError         Message = "health_error"
+)
+
+var en = map[Message]string{
+	Exiting:            "👋 Exiting...\n",
+	InvalidLanguage:    "⚠️ Invalid lang argument: %v\n",
+	InvalidGPUArgument: "⚠️ Invalid GPU argument - not a number: %s",
+	InvalidServers:     "⚠️ Invalid servers argument: %v\n",
+	GPUListed:          "\n⚡ GPU %d",
+	GPUUsing:           "\n⚡ Using GPU %d",
+	GPUPlatform:        "\nPlatform: %s",
+	GPUVendor:          "\nVendor: %s",
+	GPUDriver:          "\nDriver: %s",
+	NoGPUFound:         "\n🚨 No GPU Found!",
+	NoGPUSelected:      "\n🚨 No GPU Found or Invalid GPU Selected!",
+	NoGPUHint:          "\nThis error is safe to ignore if you intended to generate PoW on CPU only\nOtherwise you may want to check your GPU drivers and ensure it is properly installed, as well as ensure your device supports OpenCL 2.0\n\n",
+	GPUUnusable:        "\n⚠️ Unable to use GPU %v",
+	NoGPUInitialized:   "\n⚠️ Unable to initialize any GPUs, using CPU",
+	CPUThreads:         "\n🧮 Using %d CPU threads (%s %s)",
+	UsingGPUOnly:       "\nOnly using GPU for work_generate...\n\n",
+	UsingCPUOnly:       "\nOnly using CPU for work_generate...\n\n",
+	UsingGPUAndCPU:     "\nUsing GPU+CPU for work_generate...\n\n",
+	BenchmarkRun:       "\nRun %d",
+	BenchmarkTook:      "\nTook: %fs",
+	BenchmarkAverage:   "\n\nAverage: %fs",
+	BenchmarkError:     "💥 Error submitting benchmark %v\n",
+	BenchmarkSubmitted: "📊 Submitted benchmark for %s, thanks!\n",
+
+	EnterEmail:           "➡️ Enter Email: ",
+	ErrorReadingEmail:    "\n⚠️ Error reading email",
+	InvalidEmail:         "\n⚠️ Invalid email\n\n",
+	EnterPassword:        "➡️ Enter Password: ",
+	ErrorReadingPassword: "\n⚠️ Error reading password",
+	LoggingIn:            "\n\n🔒 Logging in...",
+	ServerUnreachableTry: "\n💥 Error reaching server, trying %s",
+	EnterTwoFactorCode:   "\n➡️ Enter Two Factor Code: ",
+	ErrorReadingCode:     "\n⚠️ Error reading two factor code",
+	TwoFactorRequired:    "\n❌ Two factor code required\n\n",
+	InvalidTwoFactorCode: "\n❌ Invalid two factor code\n\n",
+	InvalidCredentials:   "\n❌ Invalid email or password\n\n",
+	ServerUnreachable:    "\n💥 Error reaching server, try again later\n",
+	LoginError:           "Error logging in %v",
+	LoggedIn:             "\n\n🔓 Successfully logged in as %s\n\n",
+	TokenRefreshError:    "\nError refreshing authentication token! You may need to restart the client and re-login %v",
+	TokenRefreshed:       "\n👮 Refreshed authentication token",
+
+	Starting:            "\n🚀 Initiating connection to BoomPOW...",
+	HelloError:          "Error: hello %v",
+	HelloAck:            "\n🤝 Speaking protocol version %d with %s",
+	WSClosed:            "Websocket closed %s",
+	WSDisconnected:      "Websocket disconnected %s",
+	WSReadError:         "Error: ReadJSON %s",
+	ProtocolRejected:    "\n🚨 Server refused our connection: %s",
+	ServerDisconnected:  "\n🔌 Server closed our connection: %s, reconnecting",
+	WorkRejected:        "\n🚫 Rejecting malformed work request %s: %s",
+	WorkAboveMax:        "\n😒 Ignoring work request %s with difficulty %dx above our max %dx",
+	WorkBelowMin:        "\n😒 Ignoring work request %s with difficulty %dx below our min %dx",
+	WorkPrecache:        "\n😒 Ignoring precache request %s",
+	WorkReceived:        "\n🦋 Received work request %s with difficulty %dx",
+	BacklogFull:         "\nBacklog is too large, skipping hash %s",
+	BlockAwarded:        "\n💰 Received block awarded %s\n💰 Your current estimated next payout is %f%% or %f BAN",
+	PreferServerIgnored: "\n😒 Ignoring request to switch to unknown server %s",
+	PreferServer:        "\n🔀 Switching to server %s",
+	UnknownMessage:      "\n🦋 Received unknown message %s\n",
+	ResultError:         "\n❌ Error: sending result for %s %v",
+	WorkError:           "\n❌ Error: generate work for %s\n",
+	WorkTimeout:         "\n❌ Error: took longer than %s to generate work for %s",
+	WorkCancelled:       "\n🛑 Another worker solved %s, stopped working on it",
+	ResultFallback:      "\n📮 Websocket is down, sending result for %s over HTTP",
+	HeartbeatError:      "Error: heartbeat %v",
+	HealthError:         "\n❌ Health endpoint stopped %v\n",
+}
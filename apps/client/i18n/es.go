@@ -0,0 +1,71 @@
+package i18n
+
+var es = map[Message]string{
+	Exiting:            "👋 Saliendo...\n",
+	InvalidLanguage:    "⚠️ Argumento lang no válido: %v\n",
+	InvalidGPUArgument: "⚠️ Argumento de GPU no válido - no es un número: %s",
+	InvalidServers:     "⚠️ Argumento servers no válido: %v\n",
+	GPUListed:          "\n⚡ GPU %d",
+	GPUUsing:           "\n⚡ Usando la GPU %d",
+	GPUPlatform:        "\nPlataforma: %s",
+	GPUVendor:          "\nFabricante: %s",
+	GPUDriver:          "\nControlador: %s",
+	NoGPUFound:         "\n🚨 ¡No se encontró ninguna GPU!",
+	NoGPUSelected:      "\n🚨 ¡No se encontró ninguna GPU o la GPU seleccionada no es válida!",
+	NoGPUHint:          "\nPuedes ignorar este error si querías generar PoW solo con la CPU\nSi no, revisa que los controladores de tu GPU estén bien instalados y que tu dispositivo sea compatible con OpenCL 2.0\n\n",
+	GPUUnusable:        "\n⚠️ No se puede usar la GPU %v",
+	NoGPUInitialized:   "\n⚠️ No se pudo inicializar ninguna GPU, se usará la CPU",
+	CPUThreads:         "\n🧮 Usando %d hilos de CPU (%s %s)",
+	UsingGPUOnly:       "\nUsando solo la GPU para work_generate...\n\n",
+	UsingCPUOnly:       "\nUsando solo la CPU para work_generate...\n\n",
+	UsingGPUAndCPU:     "\nUsando GPU+CPU para work_generate...\n\n",
+	BenchmarkRun:       "\nRonda %d",
+	BenchmarkTook:      "\nTardó: %fs",
+	BenchmarkAverage:   "\n\nPromedio: %fs",
+	BenchmarkError:     "💥 Error al enviar el benchmark %v\n",
+	BenchmarkSubmitted: "📊 Benchmark enviado para %s, ¡gracias!\n",
+
+	EnterEmail:           "➡️ Introduce tu email: ",
+	ErrorReadingEmail:    "\n⚠️ Error al leer el email",
+	InvalidEmail:         "\n⚠️ Email no válido\n\n",
+	EnterPassword:        "➡️ Introduce tu contraseña: ",
+	ErrorReadingPassword: "\n⚠️ Error al leer la contraseña",
+	LoggingIn:            "\n\n🔒 Iniciando sesión...",
+	ServerUnreachableTry: "\n💥 No se pudo contactar con el servidor, probando %s",
+	EnterTwoFactorCode:   "\n➡️ Introduce el código de verificación en dos pasos: ",
+	ErrorReadingCode:     "\n⚠️ Error al leer el código de verificación en dos pasos",
+	TwoFactorRequired:    "\n❌ Se necesita el código de verificación en dos pasos\n\n",
+	InvalidTwoFactorCode: "\n❌ Código de verificación en dos pasos no válido\n\n",
+	InvalidCredentials:   "\n❌ Email o contraseña incorrectos\n\n",
+	ServerUnreachable:    "\n💥 No se pudo contactar con el servidor, inténtalo más tarde\n",
+	LoginError:           "Error al iniciar sesión %v",
+	LoggedIn:             "\n\n🔓 Sesión iniciada como %s\n\n",
+	TokenRefreshError:    "\n¡Error al renovar el token de autenticación! Puede que tengas que reiniciar el cliente e iniciar sesión de nuevo %v",
+	TokenRefreshed:       "\n👮 Token de autenticación renovado",
+
+	Starting:            "\n🚀 Conectando con BoomPOW...",
+	HelloError:          "Error: hello %v",
+	HelloAck:            "\n🤝 Usando la versión %d del protocolo con %s",
+	WSClosed:            "Websocket cerrado %s",
+	WSDisconnected:      "Websocket desconectado %s",
+	WSReadError:         "Error: ReadJSON %s",
+	ProtocolRejected:    "\n🚨 El servidor rechazó nuestra conexión: %s",
+	ServerDisconnected:  "\n🔌 El servidor cerró nuestra conexión: %s, reconectando",
+	WorkRejected:        "\n🚫 Rechazando la solicitud de trabajo mal formada %s: %s",
+	WorkAboveMax:        "\n😒 Ignorando la solicitud de trabajo %s con dificultad %dx, por encima de nuestro máximo de %dx",
+	WorkBelowMin:        "\n😒 Ignorando la solicitud de trabajo %s con dificultad %dx, por debajo de nuestro mínimo de %dx",
+	WorkPrecache:        "\n😒 Ignorando la solicitud de precache %s",
+	WorkReceived:        "\n🦋 Solicitud de trabajo recibida %s con dificultad %dx",
+	BacklogFull:         "\nLa cola está llena, saltando el hash %s",
+	BlockAwarded:        "\n💰 Bloque premiado recibido %s\n💰 Tu próximo pago estimado es %f%% o %f BAN",
+	PreferServerIgnored: "\n😒 Ignorando la petición de cambiar al servidor desconocido %s",
+	PreferServer:        "\n🔀 Cambiando al servidor %s",
+	UnknownMessage:      "\n🦋 Mensaje desconocido recibido %s\n",
+	ResultError:         "\n❌ Error: al enviar el resultado de %s %v",
+	WorkError:           "\n❌ Error: al generar el trabajo para %s\n",
+	WorkTimeout:         "\n❌ Error: se tardó más de %s en generar el trabajo para %s",
+	WorkCancelled:       "\n🛑 Otro trabajador resolvió %s, se dejó de trabajar en él",
+	ResultFallback:      "\n📮 El websocket no está disponible, enviando el resultado de %s por HTTP",
+	HeartbeatError:      "Error: heartbeat %v",
+	HealthError:         "\n❌ El endpoint de salud se detuvo %v\n",
+}
@@ -0,0 +1,71 @@
+package i18n
+
+var fr = map[Message]string{
+	Exiting:            "👋 Fermeture...\n",
+	InvalidLanguage:    "⚠️ Argument lang invalide : %v\n",
+	InvalidGPUArgument: "⚠️ Argument GPU invalide - ce n'est pas un nombre : %s",
+	InvalidServers:     "⚠️ Argument servers invalide : %v\n",
+	GPUListed:          "\n⚡ GPU %d",
+	GPUUsing:           "\n⚡ Utilisation du GPU %d",
+	GPUPlatform:        "\nPlateforme : %s",
+	GPUVendor:          "\nFabricant : %s",
+	GPUDriver:          "\nPilote : %s",
+	NoGPUFound:         "\n🚨 Aucun GPU trouvé !",
+	NoGPUSelected:      "\n🚨 Aucun GPU trouvé ou GPU sélectionné invalide !",
+	NoGPUHint:          "\nVous pouvez ignorer cette erreur si vous vouliez générer le PoW uniquement sur le CPU\nSinon, vérifiez que les pilotes de votre GPU sont correctement installés et que votre appareil prend en charge OpenCL 2.0\n\n",
+	GPUUnusable:        "\n⚠️ Impossible d'utiliser le GPU %v",
+	NoGPUInitialized:   "\n⚠️ Impossible d'initialiser un GPU, utilisation du CPU",
+	CPUThreads:         "\n🧮 Utilisation de %d threads CPU (%s %s)",
+	UsingGPUOnly:       "\nUtilisation du GPU uniquement pour work_generate...\n\n",
+	UsingCPUOnly:       "\nUtilisation du CPU uniquement pour work_generate...\n\n",
+	UsingGPUAndCPU:     "\nUtilisation du GPU+CPU pour work_generate...\n\n",
+	BenchmarkRun:       "\nEssai %d",
+	BenchmarkTook:      "\nDurée : %fs",
+	BenchmarkAverage:   "\n\nMoyenne : %fs",
+	BenchmarkError:     "💥 Erreur lors de l'envoi du benchmark %v\n",
+	BenchmarkSubmitted: "📊 Benchmark envoyé pour %s, merci !\n",
+
+	EnterEmail:           "➡️ Entrez votre email : ",
+	ErrorReadingEmail:    "\n⚠️ Erreur de lecture de l'email",
+	InvalidEmail:         "\n⚠️ Email invalide\n\n",
+	EnterPassword:        "➡️ Entrez votre mot de passe : ",
+	ErrorReadingPassword: "\n⚠️ Erreur de lecture du mot de passe",
+	LoggingIn:            "\n\n🔒 Connexion...",
+	ServerUnreachableTry: "\n💥 Serveur injoignable, essai de %s",
+	EnterTwoFactorCode:   "\n➡️ Entrez le code d'authentification à deux facteurs : ",
+	ErrorReadingCode:     "\n⚠️ Erreur de lecture du code d'authentification à deux facteurs",
+	TwoFactorRequired:    "\n❌ Code d'authentification à deux facteurs requis\n\n",
+	InvalidTwoFactorCode: "\n❌ Code d'authentification à deux facteurs invalide\n\n",
+	InvalidCredentials:   "\n❌ Email ou mot de passe invalide\n\n",
+	ServerUnreachable:    "\n💥 Serveur injoignable, réessayez plus tard\n",
+	LoginError:           "Erreur de connexion %v",
+	LoggedIn:             "\n\n🔓 Connecté en tant que %s\n\n",
+	TokenRefreshError:    "\nErreur lors du renouvellement du jeton d'authentification ! Vous devrez peut-être redémarrer le client et vous reconnecter %v",
+	TokenRefreshed:       "\n👮 Jeton d'authentification renouvelé",
+
+	Starting:            "\n🚀 Connexion à BoomPOW...",
+	HelloError:          "Erreur : hello %v",
+	HelloAck:            "\n🤝 Utilisation de la version %d du protocole avec %s",
+	WSClosed:            "Websocket fermé %s",
+	WSDisconnected:      "Websocket déconnecté %s",
+	WSReadError:         "Erreur : ReadJSON %s",
+	ProtocolRejected:    "\n🚨 Le serveur a refusé notre connexion : %s",
+	ServerDisconnected:  "\n🔌 Le serveur a fermé notre connexion : %s, reconnexion",
+	WorkRejected:        "\n🚫 Rejet de la demande de travail malformée %s : %s",
+	WorkAboveMax:        "\n😒 Demande de travail %s ignorée, difficulté %dx au-dessus de notre maximum de %dx",
+	WorkBelowMin:        "\n😒 Demande de travail %s ignorée, difficulté %dx en dessous de notre minimum de %dx",
+	WorkPrecache:        "\n😒 Demande de precache %s ignorée",
+	WorkReceived:        "\n🦋 Demande de travail reçue %s avec une difficulté de %dx",
+	BacklogFull:         "\nFile d'attente pleine, hash %s ignoré",
+	BlockAwarded:        "\n💰 Bloc récompensé reçu %s\n💰 Votre prochain paiement estimé est de %f%% soit %f BAN",
+	PreferServerIgnored: "\n😒 Demande de passage au serveur inconnu %s ignorée",
+	PreferServer:        "\n🔀 Passage au serveur %s",
+	UnknownMessage:      "\n🦋 Message inconnu reçu %s\n",
+	ResultError:         "\n❌ Erreur : envoi du résultat pour %s %v",
+	WorkError:           "\n❌ Erreur : génération du travail pour %s\n",
+	WorkTimeout:         "\n❌ Erreur : plus de %s pour générer le travail pour %s",
+	WorkCancelled:       "\n🛑 Un autre travailleur a résolu %s, travail interrompu",
+	ResultFallback:      "\n📮 Le websocket est coupé, envoi du résultat pour %s par HTTP",
+	HeartbeatError:      "Erreur : heartbeat %v",
+	HealthError:         "\n❌ L'endpoint de santé s'est arrêté %v\n",
+}
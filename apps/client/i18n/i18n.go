@@ -0,0 +1,87 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Identifies a message shown to the user, the English text is the fallback for missing translations
+type Message string
+
+// Fallback when the locale isn't one we have messages for
+const DefaultLanguage = "en"
+
+var catalogs = map[string]map[Message]string{
+	"en": en,
+	"es": es,
+	"pt": pt,
+	"fr": fr,
+	"de": de,
+}
+
+// Set once at startup, before anything prints
+var language = DefaultLanguage
+
+// Languages returns the supported language codes, sorted
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Turns a locale like pt_BR.UTF-8 or de-DE into a supported language code, "" if it isn't supported
+func parseLocale(locale string) string {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogs[lang]; !ok {
+		return ""
+	}
+	return lang
+}
+
+// Detect picks the language from the locale environment variables in the order gettext uses them, English if none is supported
+func Detect(getenv func(string) string) string {
+	for _, key := range []string{"LANGUAGE", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		// LANGUAGE is a list of preferences
+		for _, locale := range strings.Split(getenv(key), ":") {
+			if lang := parseLocale(locale); lang != "" {
+				return lang
+			}
+		}
+	}
+	return DefaultLanguage
+}
+
+// SetLanguage switches the messages to a supported language, e.g. es or pt_BR
+func SetLanguage(locale string) error {
+	lang := parseLocale(locale)
+	if lang == "" {
+		return fmt.Errorf("unsupported language %s, supported languages are %s", locale, strings.Join(Languages(), ", "))
+	}
+	language = lang
+	return nil
+}
+
+// Language returns the language messages are shown in
+func Language() string {
+	return language
+}
+
+// Format returns the format string of the message in the current language, for printf style functions
+func Format(msg Message) string {
+	if format, ok := catalogs[language][msg]; ok {
+		return format
+	}
+	return en[msg]
+}
+
+// T formats the message in the current language
+func T(msg Message, args ...interface{}) string {
+	return fmt.Sprintf(Format(msg), args...)
+}
@@ -0,0 +1,52 @@
+package i18n
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestDetect(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+	utils.AssertEqual(t, "en", Detect(env(map[string]string{})))
+	utils.AssertEqual(t, "en", Detect(env(map[string]string{"LANG": "C"})))
+	utils.AssertEqual(t, "pt", Detect(env(map[string]string{"LANG": "pt_BR.UTF-8"})))
+	utils.AssertEqual(t, "es", Detect(env(map[string]string{"LC_ALL": "es_ES.UTF-8", "LANG": "de_DE.UTF-8"})))
+	utils.AssertEqual(t, "fr", Detect(env(map[string]string{"LC_MESSAGES": "fr_FR", "LANG": "en_US.UTF-8"})))
+	// The first supported preference wins
+	utils.AssertEqual(t, "de", Detect(env(map[string]string{"LANGUAGE": "ja:de", "LANG": "es_MX.UTF-8"})))
+	utils.AssertEqual(t, "es", Detect(env(map[string]string{"LANGUAGE": "ja", "LANG": "es_MX.UTF-8"})))
+}
+
+func TestSetLanguage(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+	utils.AssertEqual(t, true, SetLanguage("ja") != nil)
+	utils.AssertEqual(t, "en", Language())
+	utils.AssertEqual(t, nil, SetLanguage("pt-BR"))
+	utils.AssertEqual(t, "pt", Language())
+	utils.AssertEqual(t, "\n\n🔓 Conectado como a@b.c\n\n", T(LoggedIn, "a@b.c"))
+}
+
+var verbs = regexp.MustCompile(`%[^a-z%]*[a-z%]`)
+
+// A translation with other verbs than English would print garbage
+func TestCatalogsMatchEnglish(t *testing.T) {
+	for _, lang := range Languages() {
+		catalog := catalogs[lang]
+		utils.AssertEqual(t, len(en), len(catalog))
+		for msg, format := range en {
+			translated, ok := catalog[msg]
+			if !ok {
+				t.Errorf("%s is missing %s", lang, msg)
+				continue
+			}
+			if strings.Join(verbs.FindAllString(format, -1), " ") != strings.Join(verbs.FindAllString(translated, -1), " ") {
+				t.Errorf("%s has other verbs than English for %s", lang, msg)
+			}
+		}
+	}
+}
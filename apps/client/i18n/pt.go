@@ -0,0 +1,71 @@
+package i18n
+
+var pt = map[Message]string{
+	Exiting:            "👋 Saindo...\n",
+	InvalidLanguage:    "⚠️ Argumento lang inválido: %v\n",
+	InvalidGPUArgument: "⚠️ Argumento de GPU inválido - não é um número: %s",
+	InvalidServers:     "⚠️ Argumento servers inválido: %v\n",
+	GPUListed:          "\n⚡ GPU %d",
+	GPUUsing:           "\n⚡ Usando a GPU %d",
+	GPUPlatform:        "\nPlataforma: %s",
+	GPUVendor:          "\nFabricante: %s",
+	GPUDriver:          "\nDriver: %s",
+	NoGPUFound:         "\n🚨 Nenhuma GPU encontrada!",
+	NoGPUSelected:      "\n🚨 Nenhuma GPU encontrada ou GPU selecionada inválida!",
+	NoGPUHint:          "\nVocê pode ignorar este erro se pretendia gerar PoW apenas com a CPU\nCaso contrário, verifique se os drivers da sua GPU estão instalados corretamente e se o seu dispositivo suporta OpenCL 2.0\n\n",
+	GPUUnusable:        "\n⚠️ Não foi possível usar a GPU %v",
+	NoGPUInitialized:   "\n⚠️ Não foi possível inicializar nenhuma GPU, usando a CPU",
+	CPUThreads:         "\n🧮 Usando %d threads de CPU (%s %s)",
+	UsingGPUOnly:       "\nUsando apenas a GPU para work_generate...\n\n",
+	UsingCPUOnly:       "\nUsando apenas a CPU para work_generate...\n\n",
+	UsingGPUAndCPU:     "\nUsando GPU+CPU para work_generate...\n\n",
+	BenchmarkRun:       "\nRodada %d",
+	BenchmarkTook:      "\nLevou: %fs",
+	BenchmarkAverage:   "\n\nMédia: %fs",
+	BenchmarkError:     "💥 Erro ao enviar o benchmark %v\n",
+	BenchmarkSubmitted: "📊 Benchmark enviado para %s, obrigado!\n",
+
+	EnterEmail:           "➡️ Digite seu email: ",
+	ErrorReadingEmail:    "\n⚠️ Erro ao ler o email",
+	InvalidEmail:         "\n⚠️ Email inválido\n\n",
+	EnterPassword:        "➡️ Digite sua senha: ",
+	ErrorReadingPassword: "\n⚠️ Erro ao ler a senha",
+	LoggingIn:            "\n\n🔒 Entrando...",
+	ServerUnreachableTry: "\n💥 Não foi possível acessar o servidor, tentando %s",
+	EnterTwoFactorCode:   "\n➡️ Digite o código de autenticação de dois fatores: ",
+	ErrorReadingCode:     "\n⚠️ Erro ao ler o código de autenticação de dois fatores",
+	TwoFactorRequired:    "\n❌ É necessário o código de autenticação de dois fatores\n\n",
+	InvalidTwoFactorCode: "\n❌ Código de autenticação de dois fatores inválido\n\n",
+	InvalidCredentials:   "\n❌ Email ou senha inválidos\n\n",
+	ServerUnreachable:    "\n💥 Não foi possível acessar o servidor, tente novamente mais tarde\n",
+	LoginError:           "Erro ao entrar %v",
+	LoggedIn:             "\n\n🔓 Conectado como %s\n\n",
+	TokenRefreshError:    "\nErro ao renovar o token de autenticação! Talvez seja necessário reiniciar o cliente e entrar novamente %v",
+	TokenRefreshed:       "\n👮 Token de autenticação renovado",
+
+	Starting:            "\n🚀 Conectando ao BoomPOW...",
+	HelloError:          "Erro: hello %v",
+	HelloAck:            "\n🤝 Usando a versão %d do protocolo com %s",
+	WSClosed:            "Websocket fechado %s",
+	WSDisconnected:      "Websocket desconectado %s",
+	WSReadError:         "Erro: ReadJSON %s",
+	ProtocolRejected:    "\n🚨 O servidor recusou nossa conexão: %s",
+	ServerDisconnected:  "\n🔌 O servidor fechou nossa conexão: %s, reconectando",
+	WorkRejected:        "\n🚫 Rejeitando a solicitação de trabalho malformada %s: %s",
+	WorkAboveMax:        "\n😒 Ignorando a solicitação de trabalho %s com dificuldade %dx, acima do nosso máximo de %dx",
+	WorkBelowMin:        "\n😒 Ignorando a solicitação de trabalho %s com dificuldade %dx, abaixo do nosso mínimo de %dx",
+	WorkPrecache:        "\n😒 Ignorando a solicitação de precache %s",
+	WorkReceived:        "\n🦋 Solicitação de trabalho recebida %s com dificuldade %dx",
+	BacklogFull:         "\nA fila está cheia, pulando o hash %s",
+	BlockAwarded:        "\n💰 Bloco premiado recebido %s\n💰 Seu próximo pagamento estimado é %f%% ou %f BAN",
+	PreferServerIgnored: "\n😒 Ignorando o pedido para trocar para o servidor desconhecido %s",
+	PreferServer:        "\n🔀 Trocando para o servidor %s",
+	UnknownMessage:      "\n🦋 Mensagem desconhecida recebida %s\n",
+	ResultError:         "\n❌ Erro: ao enviar o resultado de %s %v",
+	WorkError:           "\n❌ Erro: ao gerar o trabalho para %s\n",
+	WorkTimeout:         "\n❌ Erro: levou mais de %s para gerar o trabalho para %s",
+	WorkCancelled:       "\n🛑 Outro trabalhador resolveu %s, trabalho interrompido",
+	ResultFallback:      "\n📮 O websocket está fora do ar, enviando o resultado de %s por HTTP",
+	HeartbeatError:      "Erro: heartbeat %v",
+	HealthError:         "\n❌ O endpoint de saúde parou %v\n",
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Fields attached to an event in JSON logs
+type Fields map[string]interface{}
+
+var (
+	mu      sync.Mutex
+	jsonOut bool
+)
+
+// SetJSON switches from human readable output to one JSON object per line, for log collectors
+func SetJSON(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	jsonOut = enabled
+}
+
+// Event prints the formatted message, or a JSON line with the event name, message and fields when JSON logs are enabled
+func Event(event string, fields Fields, format string, args ...interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	msg := fmt.Sprintf(format, args...)
+	if !jsonOut {
+		fmt.Print(msg)
+		return
+	}
+	line := Fields{}
+	for k, v := range fields {
+		line[k] = v
+	}
+	line["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	line["event"] = event
+	line["msg"] = trimMessage(msg)
+	encoded, err := json.Marshal(line)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error encoding log line %v\n", err)
+		return
+	}
+	fmt.Println(string(encoded))
+}
@@ -0,0 +1,13 @@
+package logging
+
+import (
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestTrimMessage(t *testing.T) {
+	utils.AssertEqual(t, "Received work request abc with difficulty 64x", trimMessage("\n🦋 Received work request abc with difficulty 64x"))
+	utils.AssertEqual(t, "Error: took longer than 10s", trimMessage("\n❌ Error: took longer than 10s\n"))
+	utils.AssertEqual(t, "Your current estimated next payout is 1.5% or 2 BAN", trimMessage("\n💰 Your current estimated next payout is 1.5% or 2 BAN"))
+}
@@ -0,0 +1,20 @@
+package logging
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Messages are written for terminals, strip the line breaks and emoji so JSON logs are plain text
+func trimMessage(msg string) string {
+	msg = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' {
+			return ' '
+		}
+		if r > unicode.MaxLatin1 && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return -1
+		}
+		return r
+	}, msg)
+	return strings.Join(strings.Fields(msg), " ")
+}
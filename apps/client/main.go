@@ -8,13 +8,18 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/Inkeliz/go-opencl/opencl"
+	"github.com/bananocoin/boompow/apps/client/container"
 	"github.com/bananocoin/boompow/apps/client/gql"
+	"github.com/bananocoin/boompow/apps/client/health"
+	"github.com/bananocoin/boompow/apps/client/i18n"
+	"github.com/bananocoin/boompow/apps/client/logging"
 	"github.com/bananocoin/boompow/apps/client/websocket"
 	"github.com/bananocoin/boompow/apps/client/work"
 	"github.com/bananocoin/boompow/libs/utils/misc"
@@ -134,7 +139,7 @@ func SetupCloseHandler(ctx context.Context, cancel context.CancelFunc) {
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
 	go func() {
 		<-c
-		fmt.Print("👋 Exiting...\n")
+		fmt.Print(i18n.T(i18n.Exiting))
 		cancel()
 		os.Exit(0)
 	}()
@@ -155,15 +160,30 @@ func main() {
 	// Benchmark
 	benchmark := flag.Int("benchmark", 0, "Run a benchmark for the given number of random hashes")
 	benchmarkDifficulty := flag.Int("benchmark-difficulty", 64, "The difficulty multiplier for the benchmark")
+	benchmarkSubmit := flag.String("benchmark-submit", "", "Share the benchmark result with the server's hardware leaderboard under this hardware name, e.g. \"RTX 3070\" (optional, requires login)")
 	// To login without username and password prompt
 	argEmail := flag.String("email", "", "The email (username) to use for the worker (optional)")
 	argPassword := flag.String("password", "", "The password to use for the worker (optional)")
+	argTwoFactorCode := flag.String("two-factor-code", "", "The code from your authenticator app, if two factor authentication is enabled (optional)")
 	// OpenCL related things
 	listDevices := flag.Bool("list-devices", false, "List available OpenCL devices/GPUs (optional)")
 	gpus := flag.String("gpus", "0", "The GPUs to use for PoW, comma separated e.g. --gpu 0,1,2 (optional, default 0)")
 	version := flag.Bool("version", false, "Display the version")
+	// Running in containers
+	healthAddr := flag.String("health-addr", "", "Serve a /healthz endpoint on this address, e.g. :8081 (optional)")
+	logFormat := flag.String("log-format", "text", "Output format, text or json (one JSON object per line)")
+	lang := flag.String("lang", "", "The language of the output, e.g. es or pt (optional, detected from the locale by default)")
+	serverList := flag.String("servers", "", "Comma separated server URLs to fail over between in order, e.g. https://boompow.banano.cc,https://backup.example (optional)")
 	flag.Parse()
 
+	i18n.SetLanguage(i18n.Detect(os.Getenv))
+	if *lang != "" {
+		if err := i18n.SetLanguage(*lang); err != nil {
+			fmt.Print(i18n.T(i18n.InvalidLanguage, err))
+			os.Exit(1)
+		}
+	}
+
 	if *version {
 		fmt.Printf("BoomPOW version: %s\n", Version)
 		os.Exit(0)
@@ -176,23 +196,32 @@ func main() {
 	for _, gpu := range gpuSplit {
 		asInt, err := strconv.Atoi(gpu)
 		if err != nil {
-			fmt.Printf("⚠️ Invalid GPU argument - not a number: %s", gpu)
+			fmt.Print(i18n.T(i18n.InvalidGPUArgument, gpu))
 			os.Exit(1)
 		}
 		gpuSplitInt = append(gpuSplitInt, asInt)
 	}
 
-	printBanner()
+	if *logFormat == "json" {
+		logging.SetJSON(true)
+	} else {
+		printBanner()
+	}
+
+	// Stay under the container's memory limit instead of getting OOM killed
+	if limit, ok := container.MemoryLimit(); ok {
+		debug.SetMemoryLimit(limit / 10 * 9)
+	}
 
 	gpuInfo, err := getGPUInfo()
 
 	// See if we just want to list the deviecs
 	if *listDevices {
 		for key := range gpuInfo {
-			fmt.Printf("\n⚡ GPU %d", key)
-			fmt.Printf("\nPlatform: %s", gpuInfo[key].platformName)
-			fmt.Printf("\nVendor: %s", gpuInfo[key].vendor)
-			fmt.Printf("\nDriver: %s", gpuInfo[key].driverVersion)
+			fmt.Print(i18n.T(i18n.GPUListed, key))
+			fmt.Print(i18n.T(i18n.GPUPlatform, gpuInfo[key].platformName))
+			fmt.Print(i18n.T(i18n.GPUVendor, gpuInfo[key].vendor))
+			fmt.Print(i18n.T(i18n.GPUDriver, gpuInfo[key].driverVersion))
 		}
 		fmt.Printf("\n")
 		os.Exit(0)
@@ -200,53 +229,75 @@ func main() {
 
 	found := false
 	var devicesToUse []opencl.Device
+	var gpuNames []string
 
 	if err != nil {
-		fmt.Printf("\n🚨 No GPU Found!")
-		fmt.Printf("\nThis error is safe to ignore if you intended to generate PoW on CPU only")
-		fmt.Printf("\nOtherwise you may want to check your GPU drivers and ensure it is properly installed, as well as ensure your device supports OpenCL 2.0\n\n")
+		fmt.Print(i18n.T(i18n.NoGPUFound))
+		fmt.Print(i18n.T(i18n.NoGPUHint))
 	} else {
 		for key := range gpuInfo {
 			if !misc.Contains(gpuSplitInt, key) {
 				continue
 			}
 			found = true
-			fmt.Printf("\n⚡ Using GPU %d", key)
-			fmt.Printf("\nPlatform: %s", gpuInfo[key].platformName)
-			fmt.Printf("\nVendor: %s", gpuInfo[key].vendor)
-			fmt.Printf("\nDriver: %s", gpuInfo[key].driverVersion)
+			fmt.Print(i18n.T(i18n.GPUUsing, key))
+			fmt.Print(i18n.T(i18n.GPUPlatform, gpuInfo[key].platformName))
+			fmt.Print(i18n.T(i18n.GPUVendor, gpuInfo[key].vendor))
+			fmt.Print(i18n.T(i18n.GPUDriver, gpuInfo[key].driverVersion))
 			devicesToUse = append(devicesToUse, gpuInfo[key].device)
+			gpuNames = append(gpuNames, fmt.Sprintf("%s (%s)", gpuInfo[key].vendor, gpuInfo[key].platformName))
 		}
 		fmt.Printf("\n")
 		if !found {
-			fmt.Printf("\n🚨 No GPU Found or Invalid GPU Selected!")
-			fmt.Printf("\nThis error is safe to ignore if you intended to generate PoW on CPU only")
-			fmt.Printf("\nOtherwise you may want to check your GPU drivers and ensure it is properly installed, as well as ensure your device supports OpenCL 2.0\n\n")
+			fmt.Print(i18n.T(i18n.NoGPUSelected))
+			fmt.Print(i18n.T(i18n.NoGPUHint))
 		}
 	}
 	if *gpuOnly && found {
-		fmt.Printf("\nOnly using GPU for work_generate...\n\n")
+		fmt.Print(i18n.T(i18n.UsingGPUOnly))
 	} else if !found {
-		fmt.Printf("\nOnly using CPU for work_generate...\n\n")
+		fmt.Print(i18n.T(i18n.UsingCPUOnly))
 	} else {
-		fmt.Printf("\nUsing GPU+CPU for work_generate...\n\n")
+		fmt.Print(i18n.T(i18n.UsingGPUAndCPU))
 	}
 
 	// Check benchmark
+	var benchmarkResult *work.BenchmarkResult
 	if *benchmark > 0 {
-		work.RunBenchmark(*benchmark, *benchmarkDifficulty, *gpuOnly, devicesToUse)
-		os.Exit(0)
+		benchmarkResult = work.RunBenchmark(*benchmark, *benchmarkDifficulty, *gpuOnly, devicesToUse)
+		// Only continue to login if the result is shared
+		if *benchmarkSubmit == "" {
+			os.Exit(0)
+		}
+		fmt.Printf("\n\n")
 	}
 
+	servers := []websocket.Server{{GraphQLURL: GraphQLURL, WSURL: WSUrl}}
+	if *serverList != "" {
+		servers, err = websocket.ParseServers(*serverList)
+		if err != nil {
+			fmt.Print(i18n.T(i18n.InvalidServers, err))
+			os.Exit(1)
+		}
+	}
+	serverFailover := websocket.NewServerList(servers)
+	// Keep the GraphQL client on the same server as the websocket
+	serverFailover.OnChange(func(server websocket.Server) {
+		gql.InitGQLClient(server.GraphQLURL)
+	})
+
 	// Define context
 	ctx, cancel := context.WithCancel(context.Background())
-	gql.InitGQLClient(GraphQLURL)
+	gql.InitGQLClient(serverFailover.Current().GraphQLURL)
 
 	// Handle interrupts gracefully
 	SetupCloseHandler(ctx, cancel)
 
 	// Create WS Service
-	WSService = websocket.NewWebsocketService(WSUrl, *maxDifficulty, *minDifficulty, *noPrecache)
+	WSService = websocket.NewWebsocketService(serverFailover, *maxDifficulty, *minDifficulty, *noPrecache)
+
+	// Access tokens are short lived, this gets new ones
+	var refreshToken string
 
 	// Loop to get username and password and login
 	for {
@@ -256,23 +307,23 @@ func main() {
 		var email string
 
 		if *argEmail == "" {
-			fmt.Print("➡️ Enter Email: ")
+			fmt.Print(i18n.T(i18n.EnterEmail))
 			rawEmail, err := reader.ReadString('\n')
 
 			if err != nil {
-				fmt.Printf("\n⚠️ Error reading email")
+				fmt.Print(i18n.T(i18n.ErrorReadingEmail))
 				continue
 			}
 
 			email = strings.TrimSpace(rawEmail)
 
 			if !validation.IsValidEmail(email) {
-				fmt.Printf("\n⚠️ Invalid email\n\n")
+				fmt.Print(i18n.T(i18n.InvalidEmail))
 				continue
 			}
 		} else {
 			if !validation.IsValidEmail(*argEmail) {
-				fmt.Printf("\n⚠️ Invalid email\n\n")
+				fmt.Print(i18n.T(i18n.InvalidEmail))
 				os.Exit(1)
 			}
 			email = *argEmail
@@ -281,11 +332,11 @@ func main() {
 		var password string
 
 		if *argPassword == "" {
-			fmt.Print("➡️ Enter Password: ")
+			fmt.Print(i18n.T(i18n.EnterPassword))
 			bytePassword, err := term.ReadPassword(int(syscall.Stdin))
 
 			if err != nil {
-				fmt.Printf("\n⚠️ Error reading password")
+				fmt.Print(i18n.T(i18n.ErrorReadingPassword))
 				continue
 			}
 
@@ -295,39 +346,99 @@ func main() {
 		}
 
 		// Login
-		fmt.Printf("\n\n🔒 Logging in...")
-		resp, gqlErr := gql.Login(ctx, email, password)
-		if gqlErr == gql.InvalidUsernamePasssword {
-			fmt.Printf("\n❌ Invalid email or password\n\n")
+		fmt.Print(i18n.T(i18n.LoggingIn))
+		twoFactorCode := *argTwoFactorCode
+		resp, gqlErr := gql.Login(ctx, email, password, twoFactorCode)
+		for tries := 1; gqlErr == gql.ServerError && tries < serverFailover.Len(); tries++ {
+			server := serverFailover.Next()
+			fmt.Print(i18n.T(i18n.ServerUnreachableTry, server.GraphQLURL))
+			resp, gqlErr = gql.Login(ctx, email, password, twoFactorCode)
+		}
+		if gqlErr == gql.TwoFactorRequired && twoFactorCode == "" && *argPassword == "" {
+			fmt.Print(i18n.T(i18n.EnterTwoFactorCode))
+			rawCode, err := reader.ReadString('\n')
+			if err != nil {
+				fmt.Print(i18n.T(i18n.ErrorReadingCode))
+				continue
+			}
+			twoFactorCode = strings.TrimSpace(rawCode)
+			resp, gqlErr = gql.Login(ctx, email, password, twoFactorCode)
+		}
+		if gqlErr == gql.TwoFactorRequired || gqlErr == gql.InvalidTwoFactorCode {
+			if gqlErr == gql.TwoFactorRequired {
+				fmt.Print(i18n.T(i18n.TwoFactorRequired))
+			} else {
+				fmt.Print(i18n.T(i18n.InvalidTwoFactorCode))
+			}
+			if *argPassword != "" {
+				os.Exit(1)
+			}
+			continue
+		} else if gqlErr == gql.InvalidUsernamePasssword {
+			fmt.Print(i18n.T(i18n.InvalidCredentials))
 			if *argPassword != "" {
 				os.Exit(1)
 			}
 			continue
 		} else if gqlErr == gql.ServerError {
-			fmt.Printf("\n💥 Error reaching server, try again later\n")
+			fmt.Print(i18n.T(i18n.ServerUnreachable))
 			os.Exit(1)
 		}
-		fmt.Printf("\n\n🔓 Successfully logged in as %s\n\n", email)
+		fmt.Print(i18n.T(i18n.LoggedIn, email))
 		WSService.SetAuthToken(resp.Login.Token)
+		refreshToken = resp.Login.RefreshToken
 		break
 	}
 
-	// Setup a cron job to auto-update auth tokens
+	if benchmarkResult != nil {
+		backend := gql.BenchmarkBackendCpu
+		if len(devicesToUse) > 0 {
+			backend = gql.BenchmarkBackendGpu
+		}
+		gql.InitGQLClientWithToken(serverFailover.Current().GraphQLURL, WSService.AuthToken)
+		err := gql.SubmitBenchmark(ctx, gql.BenchmarkInput{
+			Hardware:             *benchmarkSubmit,
+			Backend:              backend,
+			DifficultyMultiplier: benchmarkResult.DifficultyMultiplier,
+			Runs:                 benchmarkResult.Runs,
+			AverageSeconds:       benchmarkResult.AverageSeconds,
+			ClientVersion:        Version,
+		})
+		if err != nil {
+			fmt.Print(i18n.T(i18n.BenchmarkError, err))
+			os.Exit(1)
+		}
+		fmt.Print(i18n.T(i18n.BenchmarkSubmitted, *benchmarkSubmit))
+		os.Exit(0)
+	}
+
+	// Setup a cron job to auto-update auth tokens before they expire
 	scheduler := gocron.NewScheduler(time.UTC)
-	scheduler.Every(1).Hour().Do(func() {
-		authToken, err := gql.RefreshToken(ctx, WSService.AuthToken)
+	scheduler.Every(10).Minutes().Do(func() {
+		authToken, nextRefreshToken, err := gql.RefreshToken(ctx, refreshToken)
 		if err == nil {
+			refreshToken = nextRefreshToken
 			WSService.SetAuthToken(authToken)
 		}
 	})
-	scheduler.StartAt(time.Now().Add(time.Hour))
+	scheduler.StartAt(time.Now().Add(10 * time.Minute))
 	scheduler.StartAsync()
 
-	fmt.Printf("\n🚀 Initiating connection to BoomPOW...")
+	logging.Event("starting", logging.Fields{"version": Version, "url": serverFailover.Current().WSURL}, i18n.Format(i18n.Starting))
 
 	// Create work processor
 	workProcessor := work.NewWorkProcessor(WSService, *gpuOnly, devicesToUse)
 	workProcessor.StartAsync()
+	WSService.SetWorkerInfo(Version, gpuNames, workProcessor.WorkPool.Threads)
+
+	if *healthAddr != "" {
+		health.Serve(*healthAddr, func() health.Status {
+			return health.Status{
+				Connected: WSService.WS.IsConnected(),
+				Queued:    workProcessor.Queue.Len(),
+			}
+		})
+	}
 
-	WSService.StartWSClient(ctx, workProcessor.WorkQueueChan, workProcessor.Queue)
+	WSService.StartWSClient(ctx, workProcessor.WorkQueueChan, workProcessor.CancelChan, workProcessor.Queue)
 }
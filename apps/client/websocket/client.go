@@ -2,10 +2,12 @@ package websocket
 
 import (
 	"context"
-	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/bananocoin/boompow/apps/client/i18n"
+	"github.com/bananocoin/boompow/apps/client/logging"
 	"github.com/bananocoin/boompow/apps/client/models"
 	serializableModels "github.com/bananocoin/boompow/libs/models"
 )
@@ -13,19 +15,32 @@ import (
 type WebsocketService struct {
 	WS            *RecConn
 	AuthToken     string
-	URL           string
+	servers       *ServerList
 	maxDifficulty int
 	minDifficulty int
 	skipPrecache  bool
+	// Block awarded messages are redelivered until acknowledged
+	awarded *messageDeduper
+	// Malformed work requests rejected since we started, by reason, only used by the read loop
+	rejected map[string]int
+	// Last heartbeat sent over HTTP while the websocket was down, only used by the read loop
+	lastHeartbeat time.Time
+	// Sent to the server in the hello on every connection
+	info serializableModels.WorkerHello
+	// Features the server acknowledged on this connection
+	mu       sync.Mutex
+	features []string
 }
 
-func NewWebsocketService(url string, maxDifficulty int, minDifficulty int, skipPrecache bool) *WebsocketService {
+func NewWebsocketService(servers *ServerList, maxDifficulty int, minDifficulty int, skipPrecache bool) *WebsocketService {
 	return &WebsocketService{
 		WS:            &RecConn{},
-		URL:           url,
+		servers:       servers,
 		maxDifficulty: maxDifficulty,
 		minDifficulty: minDifficulty,
 		skipPrecache:  skipPrecache,
+		awarded:       newMessageDeduper(dedupeSize),
+		rejected:      make(map[string]int),
 	}
 }
 
@@ -36,12 +51,75 @@ func (ws *WebsocketService) SetAuthToken(authToken string) {
 	})
 }
 
-func (ws *WebsocketService) StartWSClient(ctx context.Context, workQueueChan chan *serializableModels.ClientMessage, queue *models.RandomAccessQueue) {
+// The hello has to fit in a 512 byte frame, bigger rigs only report their first GPUs
+const (
+	maxHelloGPUs       = 4
+	maxHelloGPUNameLen = 40
+)
+
+// Tells the server what we run on in the hello, call before starting the client
+func (ws *WebsocketService) SetWorkerInfo(clientVersion string, gpus []string, cpuThreads int) {
+	if len(gpus) > maxHelloGPUs {
+		gpus = gpus[:maxHelloGPUs]
+	}
+	names := make([]string, len(gpus))
+	for i, name := range gpus {
+		if len(name) > maxHelloGPUNameLen {
+			name = name[:maxHelloGPUNameLen]
+		}
+		names[i] = name
+	}
+	ws.info = serializableModels.WorkerHello{ClientVersion: clientVersion, GPUs: names, CPUThreads: cpuThreads}
+}
+
+// The first thing we send on every connection, nothing is negotiated until the server acknowledges it
+func (ws *WebsocketService) sayHello() error {
+	ws.setFeatures(nil)
+	// Returning an error exits the client, the read loop notices a broken connection anyway
+	if err := ws.WS.WriteJSON(ws.hello()); err != nil {
+		logging.Event("hello_error", logging.Fields{"url": ws.WS.GetURL(), "error": err.Error()}, i18n.Format(i18n.HelloError), err)
+	}
+	return nil
+}
+
+func (ws *WebsocketService) hello() serializableModels.ClientWorkResponse {
+	hello := ws.info
+	hello.ProtocolVersion = serializableModels.ProtocolVersion
+	hello.Features = serializableModels.ProtocolFeatures
+	hello.MinDifficulty = ws.minDifficulty
+	hello.MaxDifficulty = ws.maxDifficulty
+	hello.SkipPrecache = ws.skipPrecache
+	return serializableModels.ClientWorkResponse{Hello: &hello}
+}
+
+func (ws *WebsocketService) setFeatures(features []string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.features = features
+}
+
+// Whether the server acknowledged the feature on this connection
+func (ws *WebsocketService) negotiated(feature string) bool {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for _, f := range ws.features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+func (ws *WebsocketService) StartWSClient(ctx context.Context, workQueueChan chan *serializableModels.ClientMessage, cancelChan chan string, queue *models.RandomAccessQueue) {
 	if ws.AuthToken == "" {
 		panic("Tired to start websocket client without auth token")
 	}
-	// Start the websocket connection
-	ws.WS.Dial(ws.URL, http.Header{
+	// Start the websocket connection, rotating through the servers when it fails
+	ws.WS.Failover = func() string {
+		return ws.servers.Next().WSURL
+	}
+	ws.WS.SubscribeHandler = ws.sayHello
+	ws.WS.Dial(ws.servers.Current().WSURL, http.Header{
 		"Authorization": {ws.AuthToken},
 	})
 
@@ -49,48 +127,66 @@ func (ws *WebsocketService) StartWSClient(ctx context.Context, workQueueChan cha
 		select {
 		case <-ctx.Done():
 			go ws.WS.Close()
-			fmt.Printf("Websocket closed %s", ws.WS.GetURL())
+			logging.Event("ws_closed", logging.Fields{"url": ws.WS.GetURL()}, i18n.Format(i18n.WSClosed), ws.WS.GetURL())
 			return
 		default:
 			if !ws.WS.IsConnected() {
-				fmt.Printf("Websocket disconnected %s", ws.WS.GetURL())
+				logging.Event("ws_disconnected", logging.Fields{"url": ws.WS.GetURL()}, i18n.Format(i18n.WSDisconnected), ws.WS.GetURL())
+				ws.heartbeat()
 				time.Sleep(2 * time.Second)
 				continue
 			}
 
 			var serverMsg serializableModels.ClientMessage
 			err := ws.WS.ReadJSON(&serverMsg)
+			if reason, detail, ok := refusal(err); ok {
+				if reason.Code == serializableModels.DisconnectProtocolRejected.Code {
+					logging.Event("protocol_rejected", logging.Fields{"url": ws.WS.GetURL(), "reason": detail}, i18n.Format(i18n.ProtocolRejected), detail)
+				} else {
+					logging.Event("server_refused", logging.Fields{"url": ws.WS.GetURL(), "code": reason.Code, "detail": detail}, i18n.Format(i18n.ProtocolRejected), reason.Text(detail))
+				}
+				return
+			}
+			if reason, detail, ok := closeReason(err); ok {
+				logging.Event("server_disconnected", logging.Fields{"url": ws.WS.GetURL(), "code": reason.Code, "detail": detail}, i18n.Format(i18n.ServerDisconnected), reason.Text(detail))
+				continue
+			}
 			if err != nil {
-				fmt.Printf("Error: ReadJSON %s", ws.WS.GetURL())
+				logging.Event("ws_read_error", logging.Fields{"url": ws.WS.GetURL()}, i18n.Format(i18n.WSReadError), ws.WS.GetURL())
 				continue
 			}
 
 			// Determine type of message
-			if serverMsg.MessageType == serializableModels.WorkGenerate {
+			if serverMsg.MessageType == serializableModels.HelloAck {
+				ws.setFeatures(serverMsg.Features)
+				logging.Event("hello_ack", logging.Fields{"url": ws.WS.GetURL(), "protocol": serverMsg.ProtocolVersion, "features": serverMsg.Features}, i18n.Format(i18n.HelloAck), serverMsg.ProtocolVersion, ws.WS.GetURL())
+			} else if serverMsg.MessageType == serializableModels.WorkGenerate {
+				// Tell the server instead of ignoring it, so it frees our slot and can debug the request
+				if reason := serverMsg.Malformed(); reason != "" {
+					rejection := ws.rejection(serverMsg, reason)
+					logging.Event("work_rejected", logging.Fields{"hash": serverMsg.Hash, "difficulty": serverMsg.DifficultyMultiplier, "reason": reason, "rejected": rejection.RejectedCounts[reason]}, i18n.Format(i18n.WorkRejected), serverMsg.Hash, reason)
+					ws.WS.WriteJSON(rejection)
+					continue
+				}
 				if serverMsg.DifficultyMultiplier > ws.maxDifficulty {
-					fmt.Printf("\n😒 Ignoring work request %s with difficulty %dx above our max %dx", serverMsg.Hash, serverMsg.DifficultyMultiplier, ws.maxDifficulty)
+					logging.Event("work_ignored", logging.Fields{"hash": serverMsg.Hash, "difficulty": serverMsg.DifficultyMultiplier, "reason": "above_max"}, i18n.Format(i18n.WorkAboveMax), serverMsg.Hash, serverMsg.DifficultyMultiplier, ws.maxDifficulty)
 					continue
 				}
 				if serverMsg.DifficultyMultiplier < ws.minDifficulty {
-					fmt.Printf("\n😒 Ignoring work request %s with difficulty %dx below our min %dx", serverMsg.Hash, serverMsg.DifficultyMultiplier, ws.minDifficulty)
+					logging.Event("work_ignored", logging.Fields{"hash": serverMsg.Hash, "difficulty": serverMsg.DifficultyMultiplier, "reason": "below_min"}, i18n.Format(i18n.WorkBelowMin), serverMsg.Hash, serverMsg.DifficultyMultiplier, ws.minDifficulty)
 					continue
 				}
 
 				if ws.skipPrecache && serverMsg.Precache {
-					fmt.Printf("\n😒 Ignoring precache request %s", serverMsg.Hash)
+					logging.Event("work_ignored", logging.Fields{"hash": serverMsg.Hash, "difficulty": serverMsg.DifficultyMultiplier, "reason": "precache"}, i18n.Format(i18n.WorkPrecache), serverMsg.Hash)
 					continue
 				}
 
-				fmt.Printf("\n🦋 Received work request %s with difficulty %dx", serverMsg.Hash, serverMsg.DifficultyMultiplier)
-
-				if len(serverMsg.Hash) != 64 {
-					fmt.Printf("\nReceived invalid hash, skipping")
-					continue
-				}
+				logging.Event("work_received", logging.Fields{"hash": serverMsg.Hash, "difficulty": serverMsg.DifficultyMultiplier}, i18n.Format(i18n.WorkReceived), serverMsg.Hash, serverMsg.DifficultyMultiplier)
 
 				// If the backlog is too large, no-op
 				if queue.Len() > 99 {
-					fmt.Printf("\nBacklog is too large, skipping hash %s", serverMsg.Hash)
+					logging.Event("work_ignored", logging.Fields{"hash": serverMsg.Hash, "reason": "backlog_full"}, i18n.Format(i18n.BacklogFull), serverMsg.Hash)
 					continue
 				}
 
@@ -100,15 +196,46 @@ func (ws *WebsocketService) StartWSClient(ctx context.Context, workQueueChan cha
 				// Signal channel that we have work to do
 				workQueueChan <- &serverMsg
 			} else if serverMsg.MessageType == serializableModels.WorkCancel {
-				// Delete pending work from queue
-				// ! TODO - can we cancel currently runing work calculations?
+				// Delete pending work from queue and stop solving it if we already started
 				queue.Delete(serverMsg.Hash)
+				select {
+				case cancelChan <- serverMsg.Hash:
+				default:
+					// Only the request being solved can be stopped, there's no point in blocking the read loop for it
+				}
 			} else if serverMsg.MessageType == serializableModels.BlockAwarded {
-				fmt.Printf("\n💰 Received block awarded %s", serverMsg.Hash)
-				fmt.Printf("\n💰 Your current estimated next payout is %f%% or %f BAN", serverMsg.PercentOfPool, serverMsg.EstimatedAward)
+				if serverMsg.MessageID != "" {
+					// Acknowledge repeats too, the previous acknowledgement may have been lost
+					ws.WS.WriteJSON(serializableModels.ClientWorkResponse{AckMessageID: serverMsg.MessageID})
+					if ws.awarded.Seen(serverMsg.MessageID) {
+						continue
+					}
+				}
+				logging.Event("block_awarded", logging.Fields{"hash": serverMsg.Hash, "percentOfPool": serverMsg.PercentOfPool, "estimatedAward": serverMsg.EstimatedAward}, i18n.Format(i18n.BlockAwarded), serverMsg.Hash, serverMsg.PercentOfPool, serverMsg.EstimatedAward)
+			} else if serverMsg.MessageType == serializableModels.PreferServer {
+				server, ok := ws.servers.Prefer(serverMsg.ServerURL)
+				if !ok {
+					logging.Event("prefer_server_ignored", logging.Fields{"url": serverMsg.ServerURL}, i18n.Format(i18n.PreferServerIgnored), serverMsg.ServerURL)
+					continue
+				}
+				if server.WSURL != ws.WS.GetURL() {
+					logging.Event("prefer_server", logging.Fields{"url": server.WSURL}, i18n.Format(i18n.PreferServer), server.WSURL)
+					ws.WS.setURL(server.WSURL)
+					ws.WS.CloseAndReconnect()
+				}
 			} else {
-				fmt.Printf("\n🦋 Received unknown message %s\n", serverMsg.MessageType)
+				logging.Event("unknown_message", logging.Fields{"type": serverMsg.MessageType}, i18n.Format(i18n.UnknownMessage), serverMsg.MessageType)
 			}
 		}
 	}
 }
+
+// Builds the response rejecting a malformed work request, carrying the counts of everything rejected so far
+func (ws *WebsocketService) rejection(msg serializableModels.ClientMessage, reason string) serializableModels.ClientWorkResponse {
+	ws.rejected[reason]++
+	counts := make(map[string]int, len(ws.rejected))
+	for r, count := range ws.rejected {
+		counts[r] = count
+	}
+	return serializableModels.ClientWorkResponse{RequestID: msg.RequestID, Hash: msg.Hash, Rejected: reason, RejectedCounts: counts}
+}
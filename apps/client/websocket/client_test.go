@@ -0,0 +1,71 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/gorilla/websocket"
+)
+
+func TestRejection(t *testing.T) {
+	ws := NewWebsocketService(nil, 128, 1, false)
+	msg := serializableModels.ClientMessage{RequestID: "1", Hash: "abc"}
+
+	rejection := ws.rejection(msg, serializableModels.RejectInvalidHash)
+	utils.AssertEqual(t, "1", rejection.RequestID)
+	utils.AssertEqual(t, "invalid_hash", rejection.Rejected)
+	utils.AssertEqual(t, map[string]int{"invalid_hash": 1}, rejection.RejectedCounts)
+
+	// Counts add up and earlier rejections keep their own copy
+	latest := ws.rejection(msg, serializableModels.RejectInvalidDifficulty)
+	utils.AssertEqual(t, map[string]int{"invalid_hash": 1, "invalid_difficulty": 1}, latest.RejectedCounts)
+	utils.AssertEqual(t, 1, len(rejection.RejectedCounts))
+}
+
+func TestRefusal(t *testing.T) {
+	// Servers before reasons only sent the details
+	reason, detail, ok := refusal(&websocket.CloseError{Code: serializableModels.ProtocolRejectedCloseCode, Text: "upgrade"})
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, serializableModels.DisconnectProtocolRejected, reason)
+	utils.AssertEqual(t, "upgrade", detail)
+
+	reason, detail, ok = refusal(&websocket.CloseError{Code: 4003, Text: "banned"})
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, serializableModels.DisconnectBanned, reason)
+	utils.AssertEqual(t, "", detail)
+
+	// Reconnecting helps with these
+	_, _, ok = refusal(&websocket.CloseError{Code: websocket.CloseGoingAway})
+	utils.AssertEqual(t, false, ok)
+	_, _, ok = refusal(&websocket.CloseError{Code: websocket.CloseGoingAway, Text: "maintenance: until 2026-10-17T06:00:00Z"})
+	utils.AssertEqual(t, false, ok)
+	_, _, ok = refusal(ErrNotConnected)
+	utils.AssertEqual(t, false, ok)
+}
+
+func TestCloseReason(t *testing.T) {
+	reason, detail, ok := closeReason(&websocket.CloseError{Code: websocket.CloseGoingAway, Text: "maintenance: until 2026-10-17T06:00:00Z"})
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, serializableModels.DisconnectMaintenance, reason)
+	utils.AssertEqual(t, "until 2026-10-17T06:00:00Z", detail)
+
+	// Close frames without a reason are left to the usual read error handling
+	_, _, ok = closeReason(&websocket.CloseError{Code: websocket.CloseGoingAway})
+	utils.AssertEqual(t, false, ok)
+	_, _, ok = closeReason(ErrNotConnected)
+	utils.AssertEqual(t, false, ok)
+}
+
+func TestHelloFitsFrame(t *testing.T) {
+	ws := NewWebsocketService(nil, 4096, 1024, true)
+	gpu := "Advanced Micro Devices, Inc. (AMD Accelerated Parallel Processing)"
+	ws.SetWorkerInfo("v1.10.100-rc.10", []string{gpu, gpu, gpu, gpu, gpu, gpu, gpu, gpu}, 1024)
+	utils.AssertEqual(t, maxHelloGPUs, len(ws.info.GPUs))
+
+	frame, err := json.Marshal(ws.hello())
+	utils.AssertEqual(t, nil, err)
+	// The hub's frame limit
+	utils.AssertEqual(t, true, len(frame) <= 512)
+}
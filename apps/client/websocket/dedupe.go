@@ -0,0 +1,37 @@
+package websocket
+
+import "sync"
+
+// How many message IDs are remembered, redeliveries arrive soon after reconnecting so this only needs to cover a few
+const dedupeSize = 1000
+
+// Remembers recently seen message IDs, forgetting the oldest once full
+type messageDeduper struct {
+	mu    sync.Mutex
+	seen  map[string]struct{}
+	order []string
+	size  int
+}
+
+func newMessageDeduper(size int) *messageDeduper {
+	return &messageDeduper{
+		seen: make(map[string]struct{}, size),
+		size: size,
+	}
+}
+
+// Records the ID and returns whether it was seen before
+func (d *messageDeduper) Seen(id string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.seen[id]; ok {
+		return true
+	}
+	if len(d.order) >= d.size {
+		delete(d.seen, d.order[0])
+		d.order = d.order[1:]
+	}
+	d.seen[id] = struct{}{}
+	d.order = append(d.order, id)
+	return false
+}
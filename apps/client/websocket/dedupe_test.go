@@ -0,0 +1,18 @@
+package websocket
+
+import (
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestMessageDeduper(t *testing.T) {
+	deduper := newMessageDeduper(2)
+	utils.AssertEqual(t, false, deduper.Seen("a"))
+	utils.AssertEqual(t, true, deduper.Seen("a"))
+	utils.AssertEqual(t, false, deduper.Seen("b"))
+	// Forgets the oldest
+	utils.AssertEqual(t, false, deduper.Seen("c"))
+	utils.AssertEqual(t, false, deduper.Seen("a"))
+	utils.AssertEqual(t, true, deduper.Seen("c"))
+}
@@ -0,0 +1,69 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/client/i18n"
+	"github.com/bananocoin/boompow/apps/client/logging"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+)
+
+// How often we tell the server we're alive while the websocket is down, well within its heartbeat TTL
+const fallbackHeartbeatInterval = 30 * time.Second
+
+var fallbackClient = &http.Client{Timeout: 10 * time.Second}
+
+// SubmitResult sends a result over the websocket, or over HTTP if the websocket died while we were solving
+func (ws *WebsocketService) SubmitResult(result serializableModels.ClientWorkResponse) error {
+	if err := ws.WS.WriteJSON(result); err == nil {
+		return nil
+	}
+	logging.Event("result_fallback", logging.Fields{"hash": result.Hash}, i18n.Format(i18n.ResultFallback), result.Hash)
+	return ws.postFallback(serializableModels.WorkerFallbackRequest{Results: []serializableModels.ClientWorkResponse{result}})
+}
+
+// SubmitProgress tells the server we're still solving a request, only sent over the websocket since the hub that sent the request is the one waiting on it
+func (ws *WebsocketService) SubmitProgress(progress serializableModels.ClientWorkResponse) error {
+	// Servers that don't understand it would strike us for malformed frames
+	if !ws.negotiated(serializableModels.FeatureProgress) {
+		return nil
+	}
+	return ws.WS.WriteJSON(progress)
+}
+
+// Keeps the server from counting us as offline while the websocket reconnects
+func (ws *WebsocketService) heartbeat() {
+	if time.Since(ws.lastHeartbeat) < fallbackHeartbeatInterval {
+		return
+	}
+	ws.lastHeartbeat = time.Now()
+	if err := ws.postFallback(serializableModels.WorkerFallbackRequest{}); err != nil {
+		logging.Event("heartbeat_error", logging.Fields{"error": err.Error()}, i18n.Format(i18n.HeartbeatError), err)
+	}
+}
+
+func (ws *WebsocketService) postFallback(request serializableModels.WorkerFallbackRequest) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, ws.servers.Current().ResultsURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", ws.AuthToken)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := fallbackClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("server responded %s", resp.Status)
+	}
+	return nil
+}
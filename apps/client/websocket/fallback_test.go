@@ -0,0 +1,38 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestSubmitResultFallback(t *testing.T) {
+	var received serializableModels.WorkerFallbackRequest
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	target, _ := ParseServer(server.URL)
+	ws := NewWebsocketService(NewServerList([]Server{target}), 128, 1, false)
+	ws.AuthToken = "token"
+
+	// Never connected, so it goes over HTTP
+	err := ws.SubmitResult(serializableModels.ClientWorkResponse{RequestID: "1", Hash: "abc", Result: "def"})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "token", auth)
+	utils.AssertEqual(t, []serializableModels.ClientWorkResponse{{RequestID: "1", Hash: "abc", Result: "def"}}, received.Results)
+
+	// Heartbeats are throttled
+	ws.heartbeat()
+	utils.AssertEqual(t, 0, len(received.Results))
+	received.Results = []serializableModels.ClientWorkResponse{{RequestID: "2"}}
+	ws.heartbeat()
+	utils.AssertEqual(t, 1, len(received.Results))
+}
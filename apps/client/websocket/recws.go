@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	serializableModels "github.com/bananocoin/boompow/libs/models"
 	"github.com/gorilla/websocket"
 	"github.com/jpillora/backoff"
 )
@@ -65,6 +66,9 @@ type RecConn struct {
 	KeepAliveTimeout time.Duration
 	// NonVerbose suppress connecting/reconnecting messages.
 	NonVerbose bool
+	// Failover is called after a failed dial and returns the url to try next,
+	// the same url is retried if nil
+	Failover func() string
 
 	isConnected bool
 	mu          sync.RWMutex
@@ -203,6 +207,11 @@ func (rc *RecConn) ReadJSON(v interface{}) error {
 			rc.Close()
 			return nil
 		}
+		// Reconnecting won't change the server's mind
+		if _, _, ok := refusal(err); ok {
+			rc.Close()
+			return err
+		}
 		if err != nil {
 			rc.CloseAndReconnect()
 		}
@@ -211,6 +220,21 @@ func (rc *RecConn) ReadJSON(v interface{}) error {
 	return err
 }
 
+// The reason the server gave for closing our connection, servers before reasons only gave one for refusals
+func closeReason(err error) (serializableModels.DisconnectReason, string, bool) {
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		return serializableModels.DisconnectReason{}, "", false
+	}
+	return serializableModels.ParseDisconnect(closeErr.Code, closeErr.Text)
+}
+
+// The reason the server refused us for good, reconnecting won't help with those
+func refusal(err error) (serializableModels.DisconnectReason, string, bool) {
+	reason, detail, ok := closeReason(err)
+	return reason, detail, ok && !reason.Reconnect
+}
+
 func (rc *RecConn) setURL(url string) {
 	rc.mu.Lock()
 	defer rc.mu.Unlock()
@@ -443,7 +467,7 @@ func (rc *RecConn) connect() {
 
 	for {
 		nextItvl := b.Duration()
-		wsConn, httpResp, err := rc.dialer.Dial(rc.url, rc.reqHeader)
+		wsConn, httpResp, err := rc.dialer.Dial(rc.GetURL(), rc.reqHeader)
 
 		rc.mu.Lock()
 		rc.Conn = wsConn
@@ -473,9 +497,15 @@ func (rc *RecConn) connect() {
 			return
 		}
 
+		if rc.Failover != nil {
+			if next, err := rc.parseURL(rc.Failover()); err == nil {
+				rc.setURL(next)
+			}
+		}
+
 		if !rc.getNonVerbose() {
 			log.Println(err)
-			log.Println("Dial: will try again in", nextItvl, "seconds.")
+			log.Println("Dial: will try", rc.GetURL(), "in", nextItvl, "seconds.")
 		}
 
 		time.Sleep(nextItvl)
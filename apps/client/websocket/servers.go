@@ -0,0 +1,122 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// A BoomPOW server, the same deployment is reachable on all URLs
+type Server struct {
+	GraphQLURL string
+	WSURL      string
+	// Where results go while the websocket is down
+	ResultsURL string
+}
+
+// Derive the GraphQL and websocket URLs from a server's base URL, e.g. https://boompow.banano.cc
+func ParseServer(base string) (Server, error) {
+	u, err := url.Parse(strings.TrimSpace(base))
+	if err != nil {
+		return Server{}, err
+	}
+	if u.Host == "" {
+		return Server{}, fmt.Errorf("invalid server url %s", base)
+	}
+	var wsScheme string
+	switch u.Scheme {
+	case "https":
+		wsScheme = "wss"
+	case "http":
+		wsScheme = "ws"
+	default:
+		return Server{}, fmt.Errorf("server url must be http or https %s", base)
+	}
+	path := strings.TrimRight(u.Path, "/")
+	return Server{
+		GraphQLURL: fmt.Sprintf("%s://%s%s/graphql", u.Scheme, u.Host, path),
+		WSURL:      fmt.Sprintf("%s://%s%s/ws/worker", wsScheme, u.Host, path),
+		ResultsURL: fmt.Sprintf("%s://%s%s/worker/results", u.Scheme, u.Host, path),
+	}, nil
+}
+
+// Parse a comma separated list of server base URLs
+func ParseServers(list string) ([]Server, error) {
+	servers := []Server{}
+	for _, base := range strings.Split(list, ",") {
+		if strings.TrimSpace(base) == "" {
+			continue
+		}
+		server, err := ParseServer(base)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, server)
+	}
+	if len(servers) == 0 {
+		return nil, errors.New("no servers given")
+	}
+	return servers, nil
+}
+
+// Ordered list of servers to fail over between, the first one is preferred
+type ServerList struct {
+	mu       sync.Mutex
+	servers  []Server
+	current  int
+	onChange func(Server)
+}
+
+func NewServerList(servers []Server) *ServerList {
+	return &ServerList{
+		servers: servers,
+	}
+}
+
+// OnChange is called whenever we move to another server
+func (l *ServerList) OnChange(onChange func(Server)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onChange = onChange
+}
+
+func (l *ServerList) Len() int {
+	return len(l.servers)
+}
+
+func (l *ServerList) Current() Server {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.servers[l.current]
+}
+
+func (l *ServerList) moveTo(i int) Server {
+	changed := i != l.current
+	l.current = i
+	if changed && l.onChange != nil {
+		l.onChange(l.servers[i])
+	}
+	return l.servers[i]
+}
+
+// Next moves to the next server in the list, wrapping around to the first
+func (l *ServerList) Next() Server {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.moveTo((l.current + 1) % len(l.servers))
+}
+
+// Prefer moves to the server with the given websocket or GraphQL URL
+// Only configured servers can be preferred, so a server can't send us to an arbitrary host
+func (l *ServerList) Prefer(serverURL string) (Server, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, server := range l.servers {
+		if server.WSURL == serverURL || server.GraphQLURL == serverURL {
+			return l.moveTo(i), true
+		}
+	}
+	return Server{}, false
+}
@@ -0,0 +1,48 @@
+package websocket
+
+import (
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestParseServers(t *testing.T) {
+	servers, err := ParseServers("https://boompow.banano.cc, http://localhost:8080/")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, []Server{
+		{GraphQLURL: "https://boompow.banano.cc/graphql", WSURL: "wss://boompow.banano.cc/ws/worker", ResultsURL: "https://boompow.banano.cc/worker/results"},
+		{GraphQLURL: "http://localhost:8080/graphql", WSURL: "ws://localhost:8080/ws/worker", ResultsURL: "http://localhost:8080/worker/results"},
+	}, servers)
+
+	_, err = ParseServers("")
+	utils.AssertNotEqual(t, nil, err)
+	_, err = ParseServers("ftp://boompow.banano.cc")
+	utils.AssertNotEqual(t, nil, err)
+}
+
+func TestServerList(t *testing.T) {
+	servers, _ := ParseServers("https://a.example,https://b.example,https://c.example")
+	list := NewServerList(servers)
+	changes := []string{}
+	list.OnChange(func(server Server) {
+		changes = append(changes, server.GraphQLURL)
+	})
+
+	utils.AssertEqual(t, "wss://a.example/ws/worker", list.Current().WSURL)
+	utils.AssertEqual(t, "wss://b.example/ws/worker", list.Next().WSURL)
+	utils.AssertEqual(t, "wss://c.example/ws/worker", list.Next().WSURL)
+	// Wraps around
+	utils.AssertEqual(t, "wss://a.example/ws/worker", list.Next().WSURL)
+
+	server, ok := list.Prefer("wss://c.example/ws/worker")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, "https://c.example/graphql", server.GraphQLURL)
+	// Unknown servers are refused
+	_, ok = list.Prefer("wss://evil.example/ws/worker")
+	utils.AssertEqual(t, false, ok)
+	utils.AssertEqual(t, "wss://c.example/ws/worker", list.Current().WSURL)
+	// Preferring the current server is not a change
+	list.Prefer("https://c.example/graphql")
+
+	utils.AssertEqual(t, []string{"https://b.example/graphql", "https://c.example/graphql", "https://a.example/graphql", "https://c.example/graphql"}, changes)
+}
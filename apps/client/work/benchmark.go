@@ -1,16 +1,24 @@
 package work
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
 	"time"
 
 	"github.com/Inkeliz/go-opencl/opencl"
+	"github.com/bananocoin/boompow/apps/client/i18n"
 	"github.com/bananocoin/boompow/libs/models"
 )
 
-func RunBenchmark(nHashes int, difficultyMultiplier int, gpuOnly bool, devices []opencl.Device) {
+type BenchmarkResult struct {
+	Runs                 int
+	DifficultyMultiplier int
+	AverageSeconds       float64
+}
+
+func RunBenchmark(nHashes int, difficultyMultiplier int, gpuOnly bool, devices []opencl.Device) *BenchmarkResult {
 	workPool := NewWorkPool(gpuOnly, devices)
 	if difficultyMultiplier < 1 {
 		difficultyMultiplier = 1
@@ -22,20 +30,25 @@ func RunBenchmark(nHashes int, difficultyMultiplier int, gpuOnly bool, devices [
 			panic("Failed to generate hash")
 		}
 
-		fmt.Printf("\nRun %d", i+1)
+		fmt.Print(i18n.T(i18n.BenchmarkRun, i+1))
 		startT := time.Now()
 
-		_, err := workPool.WorkGenerate(&models.ClientMessage{
+		_, err := workPool.WorkGenerate(context.Background(), &models.ClientMessage{
 			Hash:                 hex.EncodeToString(bytes),
 			DifficultyMultiplier: difficultyMultiplier,
-		})
+		}, nil)
 		if err != nil {
 			panic("Failed to generate work")
 		}
 		endT := time.Now()
 		delta := endT.Sub(startT).Seconds()
 		totalDelta += delta
-		fmt.Printf("\nTook: %fs", delta)
+		fmt.Print(i18n.T(i18n.BenchmarkTook, delta))
+	}
+	fmt.Print(i18n.T(i18n.BenchmarkAverage, totalDelta/float64(nHashes)))
+	return &BenchmarkResult{
+		Runs:                 nHashes,
+		DifficultyMultiplier: difficultyMultiplier,
+		AverageSeconds:       totalDelta / float64(nHashes),
 	}
-	fmt.Printf("\n\nAverage: %fs", totalDelta/float64(nHashes))
 }
@@ -0,0 +1,34 @@
+// Package kernel computes nano work values on the CPU.
+package kernel
+
+import "encoding/binary"
+
+//go:generate go run gen.go
+
+const (
+	iv0 = 0x6a09e667f3bcc908
+	iv1 = 0xbb67ae8584caa73b
+	iv2 = 0x3c6ef372fe94f82b
+	iv3 = 0xa54ff53a5f1d36f1
+	iv4 = 0x510e527fade682d1
+	iv5 = 0x9b05688c2b3e6c1f
+	iv6 = 0x1f83d9abfb41bd6b
+	iv7 = 0x5be0cd19137e2179
+)
+
+// Parameter block of an unkeyed 8 byte digest
+const h0 = iv0 ^ 0x01010008
+
+// Work input is always 40 bytes, the nonce followed by the root, so it fits in the last and only block
+const inputLength = 40
+
+// Root is the block hash (or account public key) work is generated for, as little endian words
+type Root [4]uint64
+
+func NewRoot(root []byte) *Root {
+	var r Root
+	for i := range r {
+		r[i] = binary.LittleEndian.Uint64(root[i*8:])
+	}
+	return &r
+}
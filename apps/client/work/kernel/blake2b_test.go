@@ -0,0 +1,91 @@
+package kernel
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"golang.org/x/crypto/blake2b"
+)
+
+func referenceValue(nonce uint64, root []byte) uint64 {
+	h, _ := blake2b.New(8, nil)
+	input := make([]byte, 40)
+	binary.LittleEndian.PutUint64(input, nonce)
+	copy(input[8:], root)
+	h.Write(input)
+	return binary.LittleEndian.Uint64(h.Sum(nil))
+}
+
+func TestValueMatchesBlake2b(t *testing.T) {
+	root := make([]byte, 32)
+	for i := 0; i < 1000; i++ {
+		rand.Read(root)
+		nonceBytes := make([]byte, 8)
+		rand.Read(nonceBytes)
+		nonce := binary.LittleEndian.Uint64(nonceBytes)
+		utils.AssertEqual(t, referenceValue(nonce, root), Value(nonce, NewRoot(root)))
+	}
+}
+
+func BenchmarkValue(b *testing.B) {
+	root := NewRoot(make([]byte, 32))
+	for i := 0; i < b.N; i++ {
+		Value(uint64(i), root)
+	}
+}
+
+func BenchmarkStdlib(b *testing.B) {
+	root := make([]byte, 32)
+	for i := 0; i < b.N; i++ {
+		referenceValue(uint64(i), root)
+	}
+}
+
+func TestImplementationsMatch(t *testing.T) {
+	root := make([]byte, 32)
+	rand.Read(root)
+	r := NewRoot(root)
+	for nonce := uint64(0); nonce < 100; nonce++ {
+		utils.AssertEqual(t, Value(nonce, r), stdlibValue(nonce, r))
+	}
+}
+
+func TestSolve(t *testing.T) {
+	root := make([]byte, 32)
+	rand.Read(root)
+	// Low difficulty so it's quick
+	difficulty := uint64(0xff00000000000000)
+	nonce, err := Solve(context.Background(), root, difficulty, 4, nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, stdlibValue(nonce, NewRoot(root)) >= difficulty)
+
+	// Impossible difficulty until cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var attempts atomic.Uint64
+	_, err = Solve(ctx, root, ^uint64(0), 2, &attempts)
+	utils.AssertEqual(t, context.DeadlineExceeded, err)
+	utils.AssertEqual(t, true, attempts.Load() > 0)
+	utils.AssertEqual(t, uint64(0), attempts.Load()%batchSize)
+}
+
+func TestSearchImplementationsMatch(t *testing.T) {
+	root := make([]byte, 32)
+	rand.Read(root)
+	r := NewRoot(root)
+	// Starts just before the nonces wrap around
+	start := ^uint64(0) - 100
+	for _, difficulty := range []uint64{0xff00000000000000, ^uint64(0)} {
+		want, wantOK := scalar(Value)(start, r, difficulty)
+		for _, candidate := range candidates() {
+			got, ok := candidate.search(start, r, difficulty)
+			utils.AssertEqual(t, wantOK, ok)
+			utils.AssertEqual(t, want, got)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+//go:build ignore
+
+// Generates value.go, the unrolled blake2b rounds for work values
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+)
+
+var sigma = [10][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// Columns then diagonals
+var mix = [8][4]int{{0, 4, 8, 12}, {1, 5, 9, 13}, {2, 6, 10, 14}, {3, 7, 11, 15}, {0, 5, 10, 15}, {1, 6, 11, 12}, {2, 7, 8, 13}, {3, 4, 9, 14}}
+
+// Only the first 5 message words (nonce and root) are ever set
+const messageWords = 5
+
+func add(b *bytes.Buffer, a, c, word int) {
+	if word < messageWords {
+		fmt.Fprintf(b, "\tv%d += v%d + m%d\n", a, c, word)
+	} else {
+		fmt.Fprintf(b, "\tv%d += v%d\n", a, c)
+	}
+}
+
+func main() {
+	b := &bytes.Buffer{}
+	b.WriteString(`// Code generated by gen.go. DO NOT EDIT.
+
+package kernel
+
+import "math/bits"
+
+// Value is the work value of nonce for root, blake2b with an 8 byte digest specialized for the 40 byte input
+// It's a single block with only the first 5 message words set, so the rounds are unrolled and the zero words left out
+func Value(nonce uint64, root *Root) uint64 {
+	m0, m1, m2, m3, m4 := nonce, root[0], root[1], root[2], root[3]
+	v0, v1, v2, v3, v4, v5, v6, v7 := uint64(h0), uint64(iv1), uint64(iv2), uint64(iv3), uint64(iv4), uint64(iv5), uint64(iv6), uint64(iv7)
+	v8, v9, v10, v11, v12, v13, v14, v15 := uint64(iv0), uint64(iv1), uint64(iv2), uint64(iv3), uint64(iv4^inputLength), uint64(iv5), ^uint64(iv6), uint64(iv7)
+`)
+	for r := 0; r < 12; r++ {
+		s := sigma[r%10]
+		fmt.Fprintf(b, "\n\t// Round %d\n", r+1)
+		for i, v := range mix {
+			a, bb, c, d := v[0], v[1], v[2], v[3]
+			add(b, a, bb, s[2*i])
+			fmt.Fprintf(b, "\tv%d = bits.RotateLeft64(v%d^v%d, -32)\n", d, d, a)
+			fmt.Fprintf(b, "\tv%d += v%d\n", c, d)
+			fmt.Fprintf(b, "\tv%d = bits.RotateLeft64(v%d^v%d, -24)\n", bb, bb, c)
+			add(b, a, bb, s[2*i+1])
+			fmt.Fprintf(b, "\tv%d = bits.RotateLeft64(v%d^v%d, -16)\n", d, d, a)
+			fmt.Fprintf(b, "\tv%d += v%d\n", c, d)
+			fmt.Fprintf(b, "\tv%d = bits.RotateLeft64(v%d^v%d, -63)\n", bb, bb, c)
+		}
+	}
+	b.WriteString("\n\treturn h0 ^ v0 ^ v8\n}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile("value.go", src, 0644); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,122 @@
+//go:build ignore
+
+// Generates value_arm64.s, the blake2b rounds of gen.go for two nonces at once in NEON registers
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+var sigma = [10][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// Columns then diagonals
+var mix = [8][4]int{{0, 4, 8, 12}, {1, 5, 9, 13}, {2, 6, 10, 14}, {3, 7, 11, 15}, {0, 5, 10, 15}, {1, 6, 11, 12}, {2, 7, 8, 13}, {3, 4, 9, 14}}
+
+// Only the first 5 message words (nonce and root) are ever set
+const messageWords = 5
+
+// V0-V15 are the state, V16-V20 the message words and V21 is scratch
+const (
+	message = 16
+	scratch = 21
+)
+
+var iv = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+// Parameter block of an unkeyed 8 byte digest
+var h0 = iv[0] ^ 0x01010008
+
+// Initial state, the same as value.go's for the 40 byte input
+var state = [16]uint64{
+	h0, iv[1], iv[2], iv[3], iv[4], iv[5], iv[6], iv[7],
+	iv[0], iv[1], iv[2], iv[3], iv[4] ^ 40, iv[5], ^iv[6], iv[7],
+}
+
+func add(b *bytes.Buffer, a, c, word int) {
+	fmt.Fprintf(b, "\tVADD V%d.D2, V%d.D2, V%d.D2\n", c, a, a)
+	if word < messageWords {
+		fmt.Fprintf(b, "\tVADD V%d.D2, V%d.D2, V%d.D2\n", message+word, a, a)
+	}
+}
+
+// x = (x ^ y) rotated right by n, shifting left into x and inserting the right shift
+func xorRotate(b *bytes.Buffer, x, y, n int) {
+	if n == 32 {
+		fmt.Fprintf(b, "\tVEOR V%d.B16, V%d.B16, V%d.B16\n", y, x, x)
+		fmt.Fprintf(b, "\tVREV64 V%d.S4, V%d.S4\n", x, x)
+		return
+	}
+	fmt.Fprintf(b, "\tVEOR V%d.B16, V%d.B16, V%d.B16\n", y, x, scratch)
+	fmt.Fprintf(b, "\tVSHL $%d, V%d.D2, V%d.D2\n", 64-n, scratch, x)
+	fmt.Fprintf(b, "\tVSRI $%d, V%d.D2, V%d.D2\n", n, scratch, x)
+}
+
+func main() {
+	b := &bytes.Buffer{}
+	b.WriteString(`// Code generated by gen_arm64.go. DO NOT EDIT.
+
+#include "textflag.h"
+
+// func valueNEON(nonce uint64, root *Root, out *[2]uint64)
+TEXT ·valueNEON(SB), NOSPLIT, $0-24
+	MOVD nonce+0(FP), R0
+	MOVD root+8(FP), R1
+	MOVD out+16(FP), R2
+
+	// m0 is nonce in the first lane and nonce+1 in the second, the root is the same in both
+	VMOV R0, V16.D[0]
+	ADD  $1, R0
+	VMOV R0, V16.D[1]
+`)
+	for i := 0; i < 4; i++ {
+		fmt.Fprintf(b, "\tMOVD %d(R1), R3\n\tVDUP R3, V%d.D2\n", i*8, message+1+i)
+	}
+	b.WriteString("\n")
+	for i, v := range state {
+		fmt.Fprintf(b, "\tMOVD $%#016x, R3\n\tVDUP R3, V%d.D2\n", v, i)
+	}
+	for r := 0; r < 12; r++ {
+		s := sigma[r%10]
+		fmt.Fprintf(b, "\n\t// Round %d\n", r+1)
+		for i, v := range mix {
+			a, bb, c, d := v[0], v[1], v[2], v[3]
+			add(b, a, bb, s[2*i])
+			xorRotate(b, d, a, 32)
+			fmt.Fprintf(b, "\tVADD V%d.D2, V%d.D2, V%d.D2\n", d, c, c)
+			xorRotate(b, bb, c, 24)
+			add(b, a, bb, s[2*i+1])
+			xorRotate(b, d, a, 16)
+			fmt.Fprintf(b, "\tVADD V%d.D2, V%d.D2, V%d.D2\n", d, c, c)
+			xorRotate(b, bb, c, 63)
+		}
+	}
+	fmt.Fprintf(b, `
+	// h0 ^ v0 ^ v8
+	VEOR V8.B16, V0.B16, V0.B16
+	MOVD $%#016x, R3
+	VDUP R3, V%d.D2
+	VEOR V%d.B16, V0.B16, V0.B16
+	VST1 [V0.D2], (R2)
+	RET
+`, h0, scratch, scratch)
+
+	if err := os.WriteFile("value_arm64.s", b.Bytes(), 0644); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,156 @@
+package kernel
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/sys/cpu"
+)
+
+// How many nonces a thread tries between checking if it should stop
+const batchSize = 1 << 12
+
+type valueFunc func(nonce uint64, root *Root) uint64
+
+// Tries batchSize nonces from nonce, returning the first whose value is at least difficulty
+type searchFunc func(nonce uint64, root *Root, difficulty uint64) (uint64, bool)
+
+type implementation struct {
+	name   string
+	search searchFunc
+}
+
+// One nonce at a time
+func scalar(value valueFunc) searchFunc {
+	return func(nonce uint64, root *Root, difficulty uint64) (uint64, bool) {
+		for end := nonce + batchSize; nonce != end; nonce++ {
+			if value(nonce, root) >= difficulty {
+				return nonce, true
+			}
+		}
+		return 0, false
+	}
+}
+
+// Go's blake2b, fastest where it has assembly
+func stdlibValue(nonce uint64, root *Root) uint64 {
+	var input [inputLength]byte
+	binary.LittleEndian.PutUint64(input[:], nonce)
+	for i, word := range root {
+		binary.LittleEndian.PutUint64(input[8+i*8:], word)
+	}
+	h, _ := blake2b.New(8, nil)
+	h.Write(input[:])
+	var sum [8]byte
+	return binary.LittleEndian.Uint64(h.Sum(sum[:0]))
+}
+
+var (
+	selectOnce   sync.Once
+	selected     searchFunc
+	selectedName string
+)
+
+// Go's blake2b only has assembly for x86 with SSE4.1/AVX/AVX2, everywhere else (arm64, riscv64, ...) it's generic Go
+func stdlibAccelerated() bool {
+	return runtime.GOARCH == "amd64" && (cpu.X86.HasAVX2 || cpu.X86.HasAVX || cpu.X86.HasSSE41)
+}
+
+func measure(f searchFunc, d time.Duration) int {
+	root := &Root{}
+	n := 0
+	for start := time.Now(); time.Since(start) < d; n += batchSize {
+		// Nothing reaches the maximum difficulty, so the whole batch is tried
+		f(uint64(n), root, ^uint64(0))
+	}
+	return n
+}
+
+// The specialized kernel unless this machine has a faster one
+func candidates() []implementation {
+	found := archImplementations()
+	if stdlibAccelerated() {
+		found = append(found, implementation{"blake2b-asm", scalar(stdlibValue)})
+	}
+	return found
+}
+
+func selectImplementation() {
+	best := implementation{"specialized", scalar(Value)}
+	bestRate := 0
+	for _, candidate := range candidates() {
+		// They're close, so let the machine decide
+		if bestRate == 0 {
+			bestRate = measure(best.search, 50*time.Millisecond)
+		}
+		if rate := measure(candidate.search, 50*time.Millisecond); rate > bestRate {
+			best, bestRate = candidate, rate
+		}
+	}
+	selected, selectedName = best.search, best.name
+}
+
+// Implementation returns the name of the implementation used on this machine
+func Implementation() string {
+	selectOnce.Do(selectImplementation)
+	return selectedName
+}
+
+// Solve searches for a nonce whose work value for root is at least difficulty on threads goroutines
+// The nonces tried are added to attempts as they go, when it isn't nil
+func Solve(ctx context.Context, root []byte, difficulty uint64, threads int, attempts *atomic.Uint64) (uint64, error) {
+	if len(root) != 32 {
+		return 0, errors.New("root must be 32 bytes")
+	}
+	if threads < 1 {
+		threads = 1
+	}
+	selectOnce.Do(selectImplementation)
+	search := selected
+	r := NewRoot(root)
+
+	var start [8]byte
+	if _, err := rand.Read(start[:]); err != nil {
+		return 0, err
+	}
+	base := binary.LittleEndian.Uint64(start[:])
+
+	var done atomic.Bool
+	results := make(chan uint64, threads)
+	var wg sync.WaitGroup
+	for t := 0; t < threads; t++ {
+		wg.Add(1)
+		// Spread the threads over the nonce space
+		go func(nonce uint64) {
+			defer wg.Done()
+			for ; !done.Load(); nonce += batchSize {
+				if found, ok := search(nonce, r, difficulty); ok {
+					results <- found
+					done.Store(true)
+					return
+				}
+				if attempts != nil {
+					attempts.Add(batchSize)
+				}
+			}
+		}(base + uint64(t)*(^uint64(0)/uint64(threads)))
+	}
+
+	defer func() {
+		done.Store(true)
+		wg.Wait()
+	}()
+	select {
+	case nonce := <-results:
+		return nonce, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
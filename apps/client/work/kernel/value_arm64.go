@@ -0,0 +1,48 @@
+package kernel
+
+import "golang.org/x/sys/cpu"
+
+//go:generate go run gen_arm64.go
+
+// The values of nonce and nonce+1 in out, both lanes of the NEON registers run the rounds
+//
+//go:noescape
+func valueNEON(nonce uint64, root *Root, out *[2]uint64)
+
+func searchNEON(nonce uint64, root *Root, difficulty uint64) (uint64, bool) {
+	var values [2]uint64
+	// batchSize is even, so this lands on end
+	for end := nonce + batchSize; nonce != end; nonce += 2 {
+		valueNEON(nonce, root, &values)
+		if values[0] >= difficulty {
+			return nonce, true
+		}
+		if values[1] >= difficulty {
+			return nonce + 1, true
+		}
+	}
+	return 0, false
+}
+
+// Checked against Value at startup, a wrong kernel would only ever produce invalid work
+func neonMatches() bool {
+	root := &Root{iv0, iv1, iv2, iv3}
+	var values [2]uint64
+	// Odd, so one pair wraps around to 0
+	nonce := ^uint64(0) - 32
+	for i := 0; i < 32; i, nonce = i+1, nonce+2 {
+		valueNEON(nonce, root, &values)
+		if values[0] != Value(nonce, root) || values[1] != Value(nonce+1, root) {
+			return false
+		}
+	}
+	return true
+}
+
+// SVE isn't used, Go's assembler can't encode it, so CPUs with SVE run the NEON kernel too
+func archImplementations() []implementation {
+	if !cpu.ARM64.HasASIMD || !neonMatches() {
+		return nil
+	}
+	return []implementation{{"neon", searchNEON}}
+}
@@ -0,0 +1,1587 @@
+// Code generated by gen_arm64.go. DO NOT EDIT.
+
+#include "textflag.h"
+
+// func valueNEON(nonce uint64, root *Root, out *[2]uint64)
+TEXT ·valueNEON(SB), NOSPLIT, $0-24
+	MOVD nonce+0(FP), R0
+	MOVD root+8(FP), R1
+	MOVD out+16(FP), R2
+
+	// m0 is nonce in the first lane and nonce+1 in the second, the root is the same in both
+	VMOV R0, V16.D[0]
+	ADD  $1, R0
+	VMOV R0, V16.D[1]
+	MOVD 0(R1), R3
+	VDUP R3, V17.D2
+	MOVD 8(R1), R3
+	VDUP R3, V18.D2
+	MOVD 16(R1), R3
+	VDUP R3, V19.D2
+	MOVD 24(R1), R3
+	VDUP R3, V20.D2
+
+	MOVD $0x6a09e667f2bdc900, R3
+	VDUP R3, V0.D2
+	MOVD $0xbb67ae8584caa73b, R3
+	VDUP R3, V1.D2
+	MOVD $0x3c6ef372fe94f82b, R3
+	VDUP R3, V2.D2
+	MOVD $0xa54ff53a5f1d36f1, R3
+	VDUP R3, V3.D2
+	MOVD $0x510e527fade682d1, R3
+	VDUP R3, V4.D2
+	MOVD $0x9b05688c2b3e6c1f, R3
+	VDUP R3, V5.D2
+	MOVD $0x1f83d9abfb41bd6b, R3
+	VDUP R3, V6.D2
+	MOVD $0x5be0cd19137e2179, R3
+	VDUP R3, V7.D2
+	MOVD $0x6a09e667f3bcc908, R3
+	VDUP R3, V8.D2
+	MOVD $0xbb67ae8584caa73b, R3
+	VDUP R3, V9.D2
+	MOVD $0x3c6ef372fe94f82b, R3
+	VDUP R3, V10.D2
+	MOVD $0xa54ff53a5f1d36f1, R3
+	VDUP R3, V11.D2
+	MOVD $0x510e527fade682f9, R3
+	VDUP R3, V12.D2
+	MOVD $0x9b05688c2b3e6c1f, R3
+	VDUP R3, V13.D2
+	MOVD $0xe07c265404be4294, R3
+	VDUP R3, V14.D2
+	MOVD $0x5be0cd19137e2179, R3
+	VDUP R3, V15.D2
+
+	// Round 1
+	VADD V4.D2, V0.D2, V0.D2
+	VADD V16.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V0.D2, V0.D2
+	VADD V17.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VADD V18.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VADD V19.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VADD V20.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+
+	// Round 2
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VADD V20.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VADD V17.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VADD V16.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VADD V18.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VADD V19.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+
+	// Round 3
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VADD V16.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VADD V18.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VADD V19.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VADD V17.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VADD V20.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+
+	// Round 4
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VADD V19.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VADD V17.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VADD V18.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VADD V20.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VADD V16.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+
+	// Round 5
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V0.D2, V0.D2
+	VADD V16.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VADD V18.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VADD V20.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VADD V17.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VADD V19.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+
+	// Round 6
+	VADD V4.D2, V0.D2, V0.D2
+	VADD V18.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VADD V16.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VADD V19.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VADD V20.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VADD V17.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+
+	// Round 7
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VADD V17.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VADD V20.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VADD V16.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VADD V19.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VADD V18.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+
+	// Round 8
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VADD V17.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VADD V19.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VADD V16.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VADD V20.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VADD V18.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+
+	// Round 9
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VADD V19.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VADD V16.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VADD V18.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VADD V17.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VADD V20.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+
+	// Round 10
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V0.D2, V0.D2
+	VADD V18.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VADD V20.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VADD V17.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VADD V19.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VADD V16.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+
+	// Round 11
+	VADD V4.D2, V0.D2, V0.D2
+	VADD V16.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V0.D2, V0.D2
+	VADD V17.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VADD V18.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VADD V19.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VADD V20.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+
+	// Round 12
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V0.D2, V0.D2
+	VEOR V0.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V8.D2, V8.D2
+	VEOR V8.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VADD V20.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VEOR V1.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V9.D2, V9.D2
+	VEOR V9.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VEOR V2.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V10.D2, V10.D2
+	VEOR V10.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V3.D2, V3.D2
+	VEOR V3.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V11.D2, V11.D2
+	VEOR V11.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VADD V17.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V15.B16
+	VREV64 V15.S4, V15.S4
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $40, V21.D2, V5.D2
+	VSRI $24, V21.D2, V5.D2
+	VADD V5.D2, V0.D2, V0.D2
+	VEOR V0.B16, V15.B16, V21.B16
+	VSHL $48, V21.D2, V15.D2
+	VSRI $16, V21.D2, V15.D2
+	VADD V15.D2, V10.D2, V10.D2
+	VEOR V10.B16, V5.B16, V21.B16
+	VSHL $1, V21.D2, V5.D2
+	VSRI $63, V21.D2, V5.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VADD V16.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V12.B16
+	VREV64 V12.S4, V12.S4
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $40, V21.D2, V6.D2
+	VSRI $24, V21.D2, V6.D2
+	VADD V6.D2, V1.D2, V1.D2
+	VADD V18.D2, V1.D2, V1.D2
+	VEOR V1.B16, V12.B16, V21.B16
+	VSHL $48, V21.D2, V12.D2
+	VSRI $16, V21.D2, V12.D2
+	VADD V12.D2, V11.D2, V11.D2
+	VEOR V11.B16, V6.B16, V21.B16
+	VSHL $1, V21.D2, V6.D2
+	VSRI $63, V21.D2, V6.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V13.B16
+	VREV64 V13.S4, V13.S4
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $40, V21.D2, V7.D2
+	VSRI $24, V21.D2, V7.D2
+	VADD V7.D2, V2.D2, V2.D2
+	VEOR V2.B16, V13.B16, V21.B16
+	VSHL $48, V21.D2, V13.D2
+	VSRI $16, V21.D2, V13.D2
+	VADD V13.D2, V8.D2, V8.D2
+	VEOR V8.B16, V7.B16, V21.B16
+	VSHL $1, V21.D2, V7.D2
+	VSRI $63, V21.D2, V7.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V14.B16
+	VREV64 V14.S4, V14.S4
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $40, V21.D2, V4.D2
+	VSRI $24, V21.D2, V4.D2
+	VADD V4.D2, V3.D2, V3.D2
+	VADD V19.D2, V3.D2, V3.D2
+	VEOR V3.B16, V14.B16, V21.B16
+	VSHL $48, V21.D2, V14.D2
+	VSRI $16, V21.D2, V14.D2
+	VADD V14.D2, V9.D2, V9.D2
+	VEOR V9.B16, V4.B16, V21.B16
+	VSHL $1, V21.D2, V4.D2
+	VSRI $63, V21.D2, V4.D2
+
+	// h0 ^ v0 ^ v8
+	VEOR V8.B16, V0.B16, V0.B16
+	MOVD $0x6a09e667f2bdc900, R3
+	VDUP R3, V21.D2
+	VEOR V21.B16, V0.B16, V0.B16
+	VST1 [V0.D2], (R2)
+	RET
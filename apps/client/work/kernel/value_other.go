@@ -0,0 +1,8 @@
+//go:build !arm64
+
+package kernel
+
+// Only arm64 has a kernel of its own
+func archImplementations() []implementation {
+	return nil
+}
@@ -1,12 +1,19 @@
 package work
 
 import (
+	"context"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"runtime"
+	"sync"
+	"sync/atomic"
 
 	"github.com/Inkeliz/go-opencl/opencl"
+	"github.com/bananocoin/boompow/apps/client/container"
+	"github.com/bananocoin/boompow/apps/client/i18n"
+	"github.com/bananocoin/boompow/apps/client/work/kernel"
 	serializableModels "github.com/bananocoin/boompow/libs/models"
 	"github.com/bananocoin/boompow/libs/utils/validation"
 	"github.com/bbedward/nanopow"
@@ -14,7 +21,12 @@ import (
 )
 
 type WorkPool struct {
+	// GPU workers, nil when there are none
 	Pool *nanopow.Pool
+	// Held for a whole GPU search, a nanopow worker can't run two at once
+	gpu sync.Mutex
+	// CPU worker threads, 0 when only using the GPU
+	Threads int
 }
 
 func NewWorkPool(gpuOnly bool, devices []opencl.Device) *WorkPool {
@@ -24,48 +36,112 @@ func NewWorkPool(gpuOnly bool, devices []opencl.Device) *WorkPool {
 		if gpuErr == nil {
 			pool.Workers = append(pool.Workers, gpu)
 		} else {
-			fmt.Printf("\n⚠️ Unable to use GPU %v", gpuErr)
+			fmt.Print(i18n.T(i18n.GPUUnusable, gpuErr))
 		}
 	}
 
 	if gpuOnly && len(pool.Workers) == 0 {
 		panic("Unable to initialize any GPUs, but gpu-only was set")
 	} else if len(pool.Workers) == 0 {
-		fmt.Printf("\n⚠️ Unable to initialize any GPUs, using CPU")
+		fmt.Print(i18n.T(i18n.NoGPUInitialized))
 	}
 
+	wp := &WorkPool{}
+	if len(pool.Workers) > 0 {
+		wp.Pool = pool
+	}
 	if !gpuOnly {
-		threads := runtime.NumCPU()
-		cpu, cpuErr := nanopow.NewWorkerCPUThread(uint64(threads))
-		if cpuErr == nil {
-			pool.Workers = append(pool.Workers, cpu)
-		} else {
-			panic(fmt.Sprintf("Unable to initialize work pool for CPU %v", cpuErr))
-		}
+		wp.Threads = container.WorkerThreads()
+		fmt.Print(i18n.T(i18n.CPUThreads, wp.Threads, runtime.GOARCH, kernel.Implementation()))
 	}
 
-	return &WorkPool{
-		Pool: pool,
-	}
+	return wp
 }
 
-func (p *WorkPool) WorkGenerate(item *serializableModels.ClientMessage) (string, error) {
+// Nonces tried on the CPU are added to attempts when it isn't nil, GPUs can't report theirs
+// Returns ctx's error once it's done, e.g. when another worker solved it
+func (p *WorkPool) WorkGenerate(ctx context.Context, item *serializableModels.ClientMessage, attempts *atomic.Uint64) (string, error) {
 	decoded, err := hex.DecodeString(item.Hash)
 	if err != nil {
 		return "", err
 	}
-	work, err := p.Pool.GenerateWork(decoded, validation.CalculateDifficulty(int64(item.DifficultyMultiplier)))
+	difficulty := validation.CalculateDifficulty(int64(item.DifficultyMultiplier))
+
+	work, err := p.generate(ctx, decoded, difficulty, attempts)
 	if err != nil {
 		return "", err
 	}
 
-	if !nanopow.IsValid(decoded, validation.CalculateDifficulty(int64(item.DifficultyMultiplier)), work) {
+	if !nanopow.IsValid(decoded, difficulty, work) {
 		klog.Errorf("\n⚠️ Generated invalid work for %s", item.Hash)
 		return "", errors.New("Invalid work")
 	}
 	return WorkToString(work), nil
 }
 
+// GPUs and CPU race for the result until ctx is done
+// nanopow can't be cancelled, so a GPU search that lost keeps the GPUs until it's done and the next one waits for it
+func (p *WorkPool) generate(ctx context.Context, root []byte, difficulty uint64, attempts *atomic.Uint64) (nanopow.Work, error) {
+	if p.Pool == nil {
+		nonce, err := kernel.Solve(ctx, root, difficulty, p.Threads, attempts)
+		return nonceToWork(nonce), err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		work nanopow.Work
+		err  error
+	}
+	results := make(chan result, 2)
+	go func() {
+		work, err := p.gpuGenerate(ctx, root, difficulty)
+		results <- result{work, err}
+	}()
+	if p.Threads > 0 {
+		go func() {
+			nonce, err := kernel.Solve(ctx, root, difficulty, p.Threads, attempts)
+			results <- result{nonceToWork(nonce), err}
+		}()
+	}
+	select {
+	case r := <-results:
+		return r.work, r.err
+	case <-ctx.Done():
+		return nanopow.Work{}, ctx.Err()
+	}
+}
+
+// Like nanopow's Pool.GenerateWork, but holds the GPUs until every worker has stopped
+// Skips the search if ctx is done while waiting for the previous one
+func (p *WorkPool) gpuGenerate(ctx context.Context, root []byte, difficulty uint64) (nanopow.Work, error) {
+	p.gpu.Lock()
+	defer p.gpu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nanopow.Work{}, err
+	}
+
+	search := nanopow.NewContext()
+	var wg sync.WaitGroup
+	for _, worker := range p.Pool.Workers {
+		wg.Add(1)
+		go func(worker nanopow.WorkerGenerator) {
+			defer wg.Done()
+			worker.GenerateWork(search, root, difficulty)
+		}(worker)
+	}
+	// Result stops the other workers, they return after their current batch
+	work := search.Result()
+	wg.Wait()
+	return work, nil
+}
+
+// Same byte order as nanopow results
+func nonceToWork(nonce uint64) (w nanopow.Work) {
+	binary.BigEndian.PutUint64(w[:], nonce)
+	return w
+}
+
 func WorkToString(w nanopow.Work) string {
 	n := make([]byte, 8)
 	copy(n, w[:])
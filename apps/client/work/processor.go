@@ -2,11 +2,13 @@ package work
 
 import (
 	"context"
-	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Inkeliz/go-opencl/opencl"
+	"github.com/bananocoin/boompow/apps/client/i18n"
+	"github.com/bananocoin/boompow/apps/client/logging"
 	"github.com/bananocoin/boompow/apps/client/models"
 	"github.com/bananocoin/boompow/apps/client/websocket"
 	serializableModels "github.com/bananocoin/boompow/libs/models"
@@ -16,9 +18,15 @@ type WorkProcessor struct {
 	Queue *models.RandomAccessQueue
 	// WorkQueueChan is where we write requests from the websocket
 	WorkQueueChan chan *serializableModels.ClientMessage
-	WSService     *websocket.WebsocketService
-	WorkPool      *WorkPool
-	mu            sync.Mutex
+	// CancelChan is where the websocket writes the hashes other workers solved
+	CancelChan chan string
+	WSService  *websocket.WebsocketService
+	WorkPool   *WorkPool
+	mu         sync.Mutex
+	// The request being solved and how to stop solving it
+	cancelMu    sync.Mutex
+	solvingHash string
+	cancelSolve context.CancelFunc
 }
 
 func NewWorkProcessor(ws *websocket.WebsocketService, gpuOnly bool, devices []opencl.Device) *WorkProcessor {
@@ -26,53 +34,117 @@ func NewWorkProcessor(ws *websocket.WebsocketService, gpuOnly bool, devices []op
 	return &WorkProcessor{
 		Queue:         models.NewRandomAccessQueue(),
 		WorkQueueChan: make(chan *serializableModels.ClientMessage, 100),
+		CancelChan:    make(chan string, 100),
 		WSService:     ws,
 		WorkPool:      wp,
 	}
 }
 
+// How often we tell the server we're still solving a request, often enough that it doesn't count us as stalled
+const progressInterval = 5 * time.Second
+
+// We stop waiting on a request after this, the server has given up on it unless we kept reporting progress
+const solveTimeout = 2 * time.Minute
+
 // RequestQueueWorker - is a worker that receives work requests directly from the websocket, adds them to the queue, and determines what should be worked on next
 func (wp *WorkProcessor) StartRequestQueueWorker() {
 	for range wp.WorkQueueChan {
 		// Pop random unit of work from queue, begin computation
 		workItem := wp.Queue.PopRandom()
 		if workItem != nil {
-			ctx, cancel := context.WithCancel(context.Background())
-			defer cancel()
-			// Generate work with timeout
-			ch := make(chan string)
+			wp.process(workItem)
+		}
+	}
+}
 
-			go func() {
-				wp.mu.Lock()
-				defer wp.mu.Unlock()
-				result, err := wp.WorkPool.WorkGenerate(workItem)
-				if err != nil {
-					result = ""
-				}
+// Stops solving the requests of hashes another worker already solved, queued ones are removed by the websocket
+func (wp *WorkProcessor) StartCancelWorker() {
+	for hash := range wp.CancelChan {
+		wp.cancelMu.Lock()
+		if wp.solvingHash == hash && wp.cancelSolve != nil {
+			wp.cancelSolve()
+		}
+		wp.cancelMu.Unlock()
+	}
+}
 
-				select {
-				default:
-					ch <- result
-				case <-ctx.Done():
-				}
-			}()
+func (wp *WorkProcessor) process(workItem *serializableModels.ClientMessage) {
+	start := time.Now()
+	// Also stops the solver when we stop waiting on it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wp.cancelMu.Lock()
+	wp.solvingHash, wp.cancelSolve = workItem.Hash, cancel
+	wp.cancelMu.Unlock()
+	defer func() {
+		wp.cancelMu.Lock()
+		wp.solvingHash, wp.cancelSolve = "", nil
+		wp.cancelMu.Unlock()
+	}()
+	// Progress is only reported once we're solving it, not while an earlier request still holds the pool
+	var solving atomic.Bool
+	var attempts atomic.Uint64
+	// Buffered so the solver can finish after we stopped waiting on it
+	ch := make(chan string, 1)
+
+	go func() {
+		wp.mu.Lock()
+		defer wp.mu.Unlock()
+		solving.Store(true)
+		result, err := wp.WorkPool.WorkGenerate(ctx, workItem, &attempts)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			result = ""
+		}
+		ch <- result
+	}()
 
-			select {
-			case result := <-ch:
-				if result != "" {
-					// Send result back to server
-					clientWorkResult := serializableModels.ClientWorkResponse{
-						RequestID: workItem.RequestID,
-						Hash:      workItem.Hash,
-						Result:    result,
-					}
-					wp.WSService.WS.WriteJSON(clientWorkResult)
-				} else {
-					fmt.Printf("\n❌ Error: generate work for %s\n", workItem.Hash)
+	progress := time.NewTicker(progressInterval)
+	defer progress.Stop()
+	timeout := time.NewTimer(solveTimeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case result := <-ch:
+			if result != "" {
+				// Send result back to server
+				clientWorkResult := serializableModels.ClientWorkResponse{
+					RequestID: workItem.RequestID,
+					Hash:      workItem.Hash,
+					Result:    result,
 				}
-			case <-time.After(10 * time.Second):
-				fmt.Printf("\n❌ Error: took longer than 10s to generate work for %s", workItem.Hash)
+				if err := wp.WSService.SubmitResult(clientWorkResult); err != nil {
+					logging.Event("result_error", logging.Fields{"hash": workItem.Hash, "error": err.Error()}, i18n.Format(i18n.ResultError), workItem.Hash, err)
+				}
+				logging.Event("work_solved", logging.Fields{"hash": workItem.Hash, "difficulty": workItem.DifficultyMultiplier, "ms": time.Since(start).Milliseconds()}, "")
+			} else {
+				logging.Event("work_error", logging.Fields{"hash": workItem.Hash}, i18n.Format(i18n.WorkError), workItem.Hash)
+			}
+			return
+		case <-progress.C:
+			if !solving.Load() {
+				continue
+			}
+			report := serializableModels.ClientWorkResponse{
+				RequestID: workItem.RequestID,
+				Hash:      workItem.Hash,
+				Progress: &serializableModels.WorkProgress{
+					Iterations: attempts.Load(),
+					ElapsedMs:  time.Since(start).Milliseconds(),
+				},
+			}
+			// Missing one doesn't matter, the next one follows shortly
+			if err := wp.WSService.SubmitProgress(report); err != nil {
+				logging.Event("progress_error", logging.Fields{"hash": workItem.Hash, "error": err.Error()}, "")
 			}
+		case <-ctx.Done():
+			logging.Event("work_cancelled", logging.Fields{"hash": workItem.Hash, "ms": time.Since(start).Milliseconds()}, i18n.Format(i18n.WorkCancelled), workItem.Hash)
+			return
+		case <-timeout.C:
+			logging.Event("work_timeout", logging.Fields{"hash": workItem.Hash}, i18n.Format(i18n.WorkTimeout), solveTimeout, workItem.Hash)
+			return
 		}
 	}
 }
@@ -80,4 +152,5 @@ func (wp *WorkProcessor) StartRequestQueueWorker() {
 // Start both workers
 func (wp *WorkProcessor) StartAsync() {
 	go wp.StartRequestQueueWorker()
+	go wp.StartCancelWorker()
 }
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler"
@@ -15,22 +20,42 @@ import (
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/bananocoin/boompow/apps/server/graph"
 	"github.com/bananocoin/boompow/apps/server/graph/generated"
+	"github.com/bananocoin/boompow/apps/server/src/alerts"
+	"github.com/bananocoin/boompow/apps/server/src/bootstrap"
+	"github.com/bananocoin/boompow/apps/server/src/challenge"
+	serverconfig "github.com/bananocoin/boompow/apps/server/src/config"
 	"github.com/bananocoin/boompow/apps/server/src/controller"
+	"github.com/bananocoin/boompow/apps/server/src/crosscheck"
 	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/devtools"
+	"github.com/bananocoin/boompow/apps/server/src/email"
+	"github.com/bananocoin/boompow/apps/server/src/eventbus"
+	"github.com/bananocoin/boompow/apps/server/src/geo"
+	"github.com/bananocoin/boompow/apps/server/src/health"
+	"github.com/bananocoin/boompow/apps/server/src/incidents"
+	"github.com/bananocoin/boompow/apps/server/src/introspection"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/maintenance"
+	"github.com/bananocoin/boompow/apps/server/src/metrics"
 	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/apps/server/src/models"
 	"github.com/bananocoin/boompow/apps/server/src/net"
+	"github.com/bananocoin/boompow/apps/server/src/payouts"
+	"github.com/bananocoin/boompow/apps/server/src/preflight"
 	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/apps/server/src/research"
+	"github.com/bananocoin/boompow/apps/server/src/sampling"
 	serializableModels "github.com/bananocoin/boompow/libs/models"
 	"github.com/bananocoin/boompow/libs/utils"
 	netutils "github.com/bananocoin/boompow/libs/utils/net"
 	"github.com/bitfield/script"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
-	"github.com/go-chi/httprate"
 	"github.com/go-co-op/gocron"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/joho/godotenv"
+	"gorm.io/gorm"
 	"k8s.io/klog/v2"
 )
 
@@ -42,8 +67,29 @@ func usage() {
 }
 
 func runServer() {
-	database.GetRedisDB().WipeAllConnectedClients()
 	godotenv.Load()
+	if err := loadLogLevels(); err != nil {
+		klog.Errorf("Error loading log levels %v", err)
+	}
+	go reloadLogLevelsOnHangup()
+	if utils.PreflightOnStartup() {
+		if !runPreflight(serverconfig.PREFLIGHT_CHECK_TIMEOUT_SECONDS * time.Second) {
+			os.Exit(1)
+		}
+	}
+	// Wait for our dependencies rather than relying on the container restarting until they're up
+	fmt.Println("🧱 Connecting to redis...")
+	if err := database.WaitFor("redis", database.GetRedisDB().Ping, utils.GetStartupTimeout()); err != nil {
+		fmt.Printf("Error connecting to redis %v", err)
+		os.Exit(1)
+	}
+	distributed := utils.GetHubMode() == "distributed"
+	if distributed {
+		// Other replicas keep their clients, ours from a previous run are gone
+		database.GetRedisDB().ReplaceReplicaClients(utils.GetReplicaID(), map[string]string{})
+	} else {
+		database.GetRedisDB().WipeAllConnectedClients()
+	}
 	// Setup database conn
 	config := &database.Config{
 		Host:     os.Getenv("DB_HOST"),
@@ -54,9 +100,10 @@ func runServer() {
 		DBName:   os.Getenv("DB_NAME"),
 	}
 	fmt.Println("🏡 Connecting to database...")
-	db, err := database.NewConnection(config)
+	db, err := database.NewConnectionWhenReady(config, utils.GetStartupTimeout())
 	if err != nil {
-		panic(err)
+		fmt.Printf("Error connecting to database %v", err)
+		os.Exit(1)
 	}
 
 	fmt.Println("🦋 Running database migrations...")
@@ -75,96 +122,422 @@ func runServer() {
 	userRepo := repository.NewUserService((db))
 	workRepo := repository.NewWorkService(db, userRepo)
 	paymentRepo := repository.NewPaymentService(db)
+	tenantRepo := repository.NewTenantService(db)
+	eventRepo := repository.NewEventService(db)
+	apiKeyRepo := repository.NewAPIKeyService(db)
+	auditLogRepo := repository.NewAuditLogService(db)
+	workSourceRepo := repository.NewWorkSourceService(db)
+	staleAccountRepo := repository.NewStaleAccountService(db)
+	counterSnapshotRepo := repository.NewCounterSnapshotService(db)
+	// Redis may have come back empty, so the counters of the last snapshot are put back
+	restoreCounters := func() {
+		restored, err := repository.RestoreLostCounters(counterSnapshotRepo, time.Now())
+		if err != nil {
+			klog.Errorf("Error restoring redis counters %v", err)
+		} else if restored > 0 {
+			klog.Infof("Restored %d redis counters from the last snapshot", restored)
+		}
+	}
+	restoreCounters()
+	// Provisioning from the environment, tokens aren't logged here so set them in the services file or use -bootstrap
+	bootstrapConfig, err := bootstrap.LoadConfig()
+	if err != nil {
+		fmt.Printf("Error loading bootstrap config %v", err)
+		os.Exit(1)
+	}
+	if !bootstrapConfig.Empty() {
+		if _, err := bootstrap.Run(bootstrapConfig, userRepo, tenantRepo); err != nil {
+			fmt.Printf("Error bootstrapping %v", err)
+			os.Exit(1)
+		}
+	}
+	if utils.DevLoginEnabled() {
+		if err := devtools.Seed(userRepo, serverconfig.DEFAULT_TENANT_ID); err != nil {
+			fmt.Printf("Error seeding dev accounts %v", err)
+			os.Exit(1)
+		}
+	}
+	rollupRepo := repository.NewRollupService(db)
+	statsStore, err := newStatsStore(db)
+	if err != nil {
+		fmt.Printf("Error setting up the stats store %v", err)
+		os.Exit(1)
+	}
+	workRepo.SetStatsStore(statsStore)
+	awardRepo := repository.NewAwardRateService(db)
+	payoutRepo := repository.NewPayoutAddressService(db)
+	benchmarkRepo := repository.NewBenchmarkService(db)
+	leaderboardRepo := repository.NewLeaderboardService(db)
+	alertRepo := repository.NewAlertService(db)
+	incidentRepo := repository.NewIncidentService(db)
+	incidentManager := incidents.NewManager(incidentRepo, utils.GetStatusWebhookURL())
+	maintenanceRepo := repository.NewMaintenanceService(db)
+	maintenanceSchedule := maintenance.NewSchedule(maintenanceRepo)
+	usageRepo := repository.NewUsageService(db)
+	activityRepo := repository.NewActivityService(db)
+	hubPolicyRepo := repository.NewHubPolicyService(db)
+	if err := controller.LoadPolicy(hubPolicyRepo); err != nil {
+		klog.Errorf("Error loading hub policy, using the default %v", err)
+	}
+	if err := maintenanceSchedule.Refresh(time.Now()); err != nil {
+		klog.Errorf("Error loading maintenance windows %v", err)
+	}
+
+	// Seed the stats rollups the first time we run with them
+	_, rollupsInPostgres := statsStore.(*repository.PostgresStatsStore)
+	if rollupsInPostgres {
+		if err := rollupRepo.BackfillDifficultyRollups(); err != nil {
+			klog.Errorf("Error backfilling difficulty rollups %v", err)
+		}
+	}
+
+	// Geolocation is optional, without a database there are no per-country stats
+	var geoLocator geo.Locator
+	if path := utils.GetGeoIPDatabasePath(); path != "" {
+		maxMind, err := geo.NewMaxMindLocator(path)
+		if err != nil {
+			klog.Errorf("Error opening GeoIP database %s, per-country stats are off %v", path, err)
+		} else {
+			defer maxMind.Close()
+			geoLocator = maxMind
+		}
+	}
 
 	precacheMap := &sync.Map{}
+	// Sampling set on other servers is picked up with the refresh every minute
+	requestSampling := graph.RequestSampling{Sampler: sampling.NewSampler()}
+	if err := requestSampling.Refresh(); err != nil {
+		klog.Errorf("Error loading request sampling %v", err)
+	}
+	if err := controller.RefreshShedding(); err != nil {
+		klog.Errorf("Error loading load shedding %v", err)
+	}
 
-	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: &graph.Resolver{
-		UserRepo:    userRepo,
-		WorkRepo:    workRepo,
-		PaymentRepo: paymentRepo,
-		PrecacheMap: precacheMap,
-	}}))
+	emailTemplateRepo := repository.NewEmailTemplateService(db)
+	email.Templates = emailTemplateRepo
+	payoutCycleRepo := repository.NewPayoutCycleService(db)
+	payoutReportKey, err := payouts.SigningKey()
+	if err != nil {
+		klog.Errorf("Error loading payout report key, reports are published unsigned %v", err)
+	}
+
+	twoFactorKey, err := utils.GetTwoFactorKey()
+	if err != nil {
+		fmt.Printf("Error loading the two factor key %v", err)
+		os.Exit(1)
+	}
+	twoFactorRepo := repository.NewTwoFactorService(db, twoFactorKey)
+	if encrypted, err := twoFactorRepo.EncryptPlaintextSecrets(); err != nil {
+		klog.Errorf("Error encrypting two factor secrets %v", err)
+	} else if encrypted > 0 {
+		klog.Infof("Encrypted %d two factor secrets stored before they were encrypted", encrypted)
+	}
+
+	resolver := &graph.Resolver{
+		UserRepo:            userRepo,
+		WorkRepo:            workRepo,
+		PaymentRepo:         paymentRepo,
+		TenantRepo:          tenantRepo,
+		EventRepo:           eventRepo,
+		RollupRepo:          rollupRepo,
+		StatsStore:          statsStore,
+		AwardRepo:           awardRepo,
+		PayoutRepo:          payoutRepo,
+		BenchmarkRepo:       benchmarkRepo,
+		AlertRepo:           alertRepo,
+		IncidentRepo:        incidentRepo,
+		Incidents:           incidentManager,
+		MaintenanceRepo:     maintenanceRepo,
+		Maintenance:         maintenanceSchedule,
+		UsageRepo:           usageRepo,
+		ActivityRepo:        activityRepo,
+		HubPolicyRepo:       hubPolicyRepo,
+		TwoFactorRepo:       twoFactorRepo,
+		SecurityWebhookRepo: repository.NewSecurityWebhookService(db),
+		SecurityNotifier:    alerts.NewWebhookNotifier(),
+		AuditLogRepo:        auditLogRepo,
+		RoleRepo:            repository.NewRoleService(db),
+		CollisionRepo:       repository.NewEmailCollisionService(db),
+		APIKeyRepo:          apiKeyRepo,
+		BoostRepo:           repository.NewBoostService(db),
+		WorkSourceRepo:      workSourceRepo,
+		StaleAccountRepo:    staleAccountRepo,
+		CounterSnapshotRepo: counterSnapshotRepo,
+		LeaderboardRepo:     leaderboardRepo,
+		EmailTemplateRepo:   emailTemplateRepo,
+		PayoutCycleRepo:     payoutCycleRepo,
+		PayoutReportKey:     payoutReportKey,
+		Sampler:             requestSampling.Sampler,
+		PrecacheMap:         precacheMap,
+		GeoLocator:          geoLocator,
+	}
+	if difficulty := utils.GetPowChallengeDifficulty(); difficulty > 0 {
+		powChallenges := challenge.NewPowVerifier(utils.GetJwtKey(), difficulty, serverconfig.POW_CHALLENGE_VALID_MINUTES*time.Minute)
+		resolver.ChallengeVerifier = powChallenges
+		resolver.PowChallenges = powChallenges
+	}
+
+	schema := generated.NewExecutableSchema(generated.Config{Resolvers: resolver, Directives: generated.DirectiveRoot{Auth: graph.Auth, HasPermission: graph.HasPermission}})
+	srv := handler.New(schema)
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
+	srv.AddTransport(graph.BatchPOST{})
 	srv.AddTransport(transport.POST{})
-	// Configure WebSocket with CORS
+	// Browsers can only call the API and open websockets from BPOW_ALLOWED_ORIGINS
+	originValidator, err := middleware.NewOriginValidator(utils.GetAllowedOrigins())
+	if err != nil {
+		fmt.Printf("Invalid BPOW_ALLOWED_ORIGINS %v", err)
+		os.Exit(1)
+	}
+	controller.Upgrader.CheckOrigin = originValidator.CheckOrigin
 	srv.AddTransport(&transport.Websocket{
 		Upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				return true
-			},
+			CheckOrigin:     originValidator.CheckOrigin,
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
 		},
 		KeepAlivePingInterval: 10 * time.Second,
+		InitFunc:              middleware.WebsocketInit(userRepo),
 	})
 	if utils.GetEnv("ENVIRONMENT", "development") == "development" {
 		srv.Use(extension.Introspection{})
 	}
+	srv.Use(graph.CacheControl{})
+	srv.Use(requestSampling)
+	srv.Use(&graph.RateLimitReport{})
+	srv.Use(graph.ResolverMetrics{})
+	srv.Use(graph.AdminAudit{Repo: auditLogRepo})
+	// Clients can send just the hash of the example operations, or of queries they sent before
+	srv.Use(extension.AutomaticPersistedQuery{Cache: graph.NewPersistedQueryCache(serverconfig.PERSISTED_QUERY_CACHE_SIZE)})
 
 	// Setup router
 	router := chi.NewRouter()
-	// ! TODO - this is temporary, need to set origins in prod
 	router.Use(cors.Handler(cors.Options{
-		// AllowedOrigins:   []string{"https://foo.com"}, // Use this to allow specific origin hosts
-		//AllowedOrigins:   []string{"*"},
-		AllowOriginFunc:  func(r *http.Request, origin string) bool { return true },
+		AllowOriginFunc:  originValidator.AllowOrigin,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", middleware.TenantHeader, middleware.IdempotencyKeyHeader, middleware.ChallengeHeader, middleware.ChallengeSolutionHeader},
+		ExposedHeaders:   []string{"Link", "ETag", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "X-Query-Cost"},
 		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
-	// if utils.GetEnv("ENVIRONMENT", "development") == "development" {
-	// 	router.Use(cors.New(cors.Options{
-	// 		AllowOriginFunc: func(origin string) bool {
-	// 			return true
-	// 		},
-	// 	}).Handler)
-	// } else {
-	// 	router.Use(cors.New(cors.Options{
-	// 		AllowedOrigins:   []string{"https://*.banano.cc"},
-	// 		AllowCredentials: true,
-	// 		Debug:            true,
-	// 	}).Handler)
-	// }
-	router.Use(middleware.AuthMiddleware(userRepo))
-	// Rate limiting middleware
-	router.Use(httprate.Limit(
-		20,            // requests
-		1*time.Minute, // per duration
-		// an oversimplified example of rate limiting by a custom header
-		httprate.WithKeyFuncs(func(r *http.Request) (string, error) {
+	router.Use(middleware.ClientIPMiddleware())
+	router.Use(middleware.TenantMiddleware())
+	// Rate limits per anonymous IP, user and service, shared by all servers through redis
+	if utils.GetRateLimitMode() != "queue" {
+		router.Use(middleware.NewSlidingRateLimiter(middleware.RateLimits{
+			middleware.RateLimitAnonymous: utils.GetAnonymousRateLimit(),
+			middleware.RateLimitUser:      utils.GetUserRateLimit(),
+			middleware.RateLimitService:   utils.GetServiceRateLimit(),
+		}, database.GetRedisDB(), middleware.NewRateLimitUserLookup(userRepo, apiKeyRepo)).Handler)
+	}
+	router.Use(middleware.AuthMiddleware(userRepo, apiKeyRepo, workSourceRepo, auditLogRepo))
+	router.Use(middleware.APIKeyRateLimit())
+	router.Use(middleware.IdempotencyMiddleware())
+	router.Use(middleware.ChallengeMiddleware())
+	// Queue mode smooths bursts in memory on each server instead
+	if utils.GetRateLimitMode() == "queue" {
+		rateLimitKey := func(r *http.Request) (string, error) {
 			requester := middleware.AuthorizedServiceToken(r.Context())
 			if requester != nil {
 				// Return a random string, effectively disabling rate limiting for services
 				return uuid.New().String(), nil
 			}
 			return netutils.GetIPAddress(r), nil
-		}),
-	))
+		}
+		router.Use(middleware.NewQueuedRateLimiter(
+			20,            // requests
+			1*time.Minute, // per duration
+			utils.GetRateLimitQueueSize(),
+			utils.GetRateLimitMaxWait(),
+			rateLimitKey,
+		).WithMaintenance(func(now time.Time) bool {
+			return maintenanceSchedule.Active(now) != nil
+		}).WithOverrides(middleware.UserRateLimitOverride).Handler)
+	}
 	if utils.GetEnv("ENVIRONMENT", "development") == "development" {
 		router.Handle("/", playground.Handler("GraphQL playground", "/graphql"))
 		log.Printf("🚀 connect to http://localhost:%s/ for GraphQL playground", port)
 	}
-	router.Handle("/graphql", srv)
+	if utils.DevLoginEnabled() {
+		router.Handle("/dev/login", devtools.LoginHandler("/", time.Now))
+		log.Printf("🔑 log in to the playground as a dev account at http://localhost:%s/dev/login", port)
+	}
+	router.With(middleware.CacheControlMiddleware()).Handle("/graphql", srv)
+	// For generating client SDKs
+	router.Get("/graphql/schema.graphql", graph.SchemaHandler(schema.Schema()))
+	router.Get("/graphql/operations.json", graph.OperationsHandler())
+	router.Get("/payouts/{cycleID}/report.json", payouts.ReportHandler(payoutCycleRepo, payoutReportKey))
+	router.Get("/email/unsubscribe/{token}", payouts.UnsubscribeHandler(userRepo))
+	router.Get("/health/live", health.LiveHandler)
+	router.Get("/health/ready", health.ReadyHandler(map[string]health.Check{
+		"postgres": func() error {
+			return database.Ping(db)
+		},
+		"redis": func() error {
+			if !database.GetRedisDB().Healthy() {
+				return errors.New("not connected")
+			}
+			return nil
+		},
+	}))
 
 	// Setup channel for stats processing job
 	statsChan := make(chan repository.WorkMessage, 100)
 	// Setup channel for sending block awarded messages
-	blockAwardedChan := make(chan serializableModels.ClientMessage)
+	blockAwardedChan := make(chan serializableModels.ClientMessage, 100)
+
+	// Cross-check a sample of results with other validators, in case our own validation has a bug
+	validationPeers, err := crosscheck.ParsePeers(utils.GetValidationPeers(), &http.Client{Timeout: serverconfig.CROSSCHECK_TIMEOUT_SECONDS * time.Second})
+	if err != nil {
+		fmt.Printf("Error setting up validation peers %v", err)
+		os.Exit(1)
+	}
+	if len(validationPeers) > 0 {
+		controller.CrossCheck = crosscheck.NewChecker(validationPeers, utils.GetValidationSamplePercent(), utils.GetValidationAlertWebhookURL())
+	}
 
 	// Setup WS endpoint
 	controller.ActiveHub = controller.NewHub(&statsChan)
+	controller.NewValidationPool(controller.ActiveHub, utils.GetValidationWorkers(), serverconfig.VALIDATION_QUEUE_SIZE)
+	// Replicas share work requests and results through redis
+	if distributed {
+		cluster := controller.NewCluster(controller.ActiveHub, utils.GetReplicaID(), database.GetRedisDB().PublishHubMessage)
+		go cluster.Run()
+		klog.Infof("Running the hub in distributed mode as replica %s", cluster.ReplicaID)
+	}
 	go controller.ActiveHub.Run()
+	// Fake workers for local development, results forwarded between replicas aren't recognized as theirs
+	if workers := utils.GetSimulatedWorkers(); workers > 0 {
+		if distributed {
+			klog.Warningf("Not starting %d simulated workers, they only work in local hub mode", workers)
+		} else {
+			emails, err := devtools.SeedSimulatedWorkers(userRepo, serverconfig.DEFAULT_TENANT_ID, workers)
+			if err != nil {
+				fmt.Printf("Error seeding simulated workers %v", err)
+				os.Exit(1)
+			}
+			controller.NewSimulation(controller.SimulationSettings{
+				TenantID:     serverconfig.DEFAULT_TENANT_ID,
+				Latency:      utils.GetSimulatedLatency(),
+				ErrorPercent: utils.GetSimulatedErrorPercent(),
+			}, time.Now().UnixNano()).Start(controller.ActiveHub, emails)
+		}
+	}
+	err = metrics.RegisterHub(metrics.Registry, func() int {
+		return controller.ActiveHub.Snapshot().Workers
+	}, func() int {
+		return controller.ActiveHub.Snapshot().StatsQueue
+	}, func() int {
+		depth := 0
+		for _, n := range controller.ActiveHub.Snapshot().BroadcastQueue {
+			depth += n
+		}
+		return depth
+	}, func() int {
+		return controller.ActiveHub.Snapshot().ValidationBacklog
+	})
+	if err != nil {
+		klog.Errorf("Error registering hub metrics %v", err)
+	}
+	// Redis may come back empty after an outage, so the connected clients are rebuilt from the hub
+	go database.GetRedisDB().WatchConnection(serverconfig.REDIS_WATCH_INTERVAL_SECONDS*time.Second, func() {
+		if _, err := controller.ActiveHub.ReconcileConnectedClients(); err != nil {
+			klog.Errorf("Error reconciling connected clients after reconnecting to redis %v", err)
+		}
+		restoreCounters()
+	})
+	// Workers with nothing to do precache the frontiers requesters registered, if the hub policy enables it
+	idlePrecacher := controller.NewIdlePrecacher(controller.ActiveHub, func(tenantID string, hash string, difficultyMultiplier int) bool {
+		_, err := workRepo.RetrieveWorkFromCache(tenantID, hash, difficultyMultiplier)
+		return err == nil
+	})
+	go idlePrecacher.Run(serverconfig.IDLE_PRECACHE_CHECK_SECONDS * time.Second)
 	router.HandleFunc("/ws/worker", func(w http.ResponseWriter, r *http.Request) {
 		controller.WorkerChl(controller.ActiveHub, w, r)
 	})
+	// Fallback for workers whose websocket died mid-solve
+	router.HandleFunc("/worker/results", func(w http.ResponseWriter, r *http.Request) {
+		controller.WorkerFallback(controller.ActiveHub, w, r)
+	})
+
+	// Push database changes to subscribers
+	go database.ListenForNotifications(config, serverconfig.DB_NOTIFY_CHANNEL, controller.UserEvents.PublishNotification)
+
+	// Optionally persist hub events, dropping them rather than stalling the hub if the database can't keep up
+	// Events recorded once they're drained on shutdown aren't persisted
+	drainHubEvents := func() {}
+	if utils.PersistHubEvents() {
+		hubEventChan := make(chan models.HubEvent, 1000)
+		var hubEventsMu sync.Mutex
+		hubEventsClosed := false
+		controller.HubEvents.AddListener(func(event models.HubEvent) {
+			hubEventsMu.Lock()
+			defer hubEventsMu.Unlock()
+			if hubEventsClosed {
+				return
+			}
+			select {
+			case hubEventChan <- event:
+			default:
+				klog.Warningf("Hub event sink is full, dropping %s event", event.Type)
+			}
+		})
+		hubEventsDone := make(chan struct{})
+		go func() {
+			eventRepo.HubEventSink(hubEventChan)
+			close(hubEventsDone)
+		}()
+		drainHubEvents = func() {
+			hubEventsMu.Lock()
+			hubEventsClosed = true
+			close(hubEventChan)
+			hubEventsMu.Unlock()
+			<-hubEventsDone
+		}
+	}
+
+	// Alert providers who opted in when all their workers are gone
+	offlineMonitor := alerts.NewOfflineMonitor(alertRepo, alerts.NewWebhookNotifier(), maintenanceSchedule, database.GetRedisDB())
+	controller.HubEvents.AddListener(offlineMonitor.HandleEvent)
+
+	// Wake the stats subscriptions of providers with new activity
+	controller.HubEvents.AddListener(controller.ProviderActivity.HandleEvent)
 
 	// Stats stats processing job
-	go workRepo.StatsWorker(statsChan, &blockAwardedChan)
+	statsDone := make(chan struct{})
+	go func() {
+		workRepo.StatsWorker(statsChan, &blockAwardedChan)
+		close(statsDone)
+	}()
+	awardedChan := blockAwardedChan
+
+	// Publish pool events for downstream consumers
+	if busURL := utils.GetEventBusURL(); busURL != "" {
+		publisher, err := eventbus.NewNatsPublisher(busURL)
+		if err != nil {
+			fmt.Printf("Error setting up the event bus %v", err)
+			os.Exit(1)
+		}
+		bus := eventbus.NewBus(publisher, utils.GetEventBusPrefix(), 10000)
+		go bus.Run()
+		controller.HubEvents.AddListener(bus.HandleHubEvent)
+		controller.UserEvents.AddListener(bus.HandleUserEvent)
+		awardedChan = make(chan serializableModels.ClientMessage, 100)
+		go func() {
+			for msg := range blockAwardedChan {
+				bus.HandleBlockAwarded(msg)
+				awardedChan <- msg
+			}
+			close(awardedChan)
+		}()
+	}
+
 	// Job for sending block awarded messages to user
-	go controller.ActiveHub.BlockAwardedWorker(blockAwardedChan)
+	awardsDone := make(chan struct{})
+	go func() {
+		controller.ActiveHub.BlockAwardedWorker(awardedChan)
+		close(awardsDone)
+	}()
 
 	// Setup callback clients for pre-caching
 
@@ -183,7 +556,7 @@ func runServer() {
 			}
 
 			// We want to precache this if we don't have it
-			_, err := workRepo.RetrieveWorkFromCache(msg.Hash, 64)
+			_, err := workRepo.RetrieveWorkFromCache(serverconfig.DEFAULT_TENANT_ID, msg.Hash, 64)
 			if err == nil {
 				// Already cached
 				continue
@@ -197,8 +570,14 @@ func runServer() {
 				Hash:                 msg.Hash,
 				DifficultyMultiplier: 64,
 				Precache:             true,
+				TenantID:             serverconfig.DEFAULT_TENANT_ID,
 			}
 
+			// Don't wait on each one during maintenance, so the backlog drains while there are fewer workers
+			if maintenanceSchedule.Active(time.Now()) != nil {
+				go controller.BroadcastWorkRequestAndWait(workRequest)
+				continue
+			}
 			controller.BroadcastWorkRequestAndWait(workRequest)
 			if msg.Block.Subtype != "send" {
 				continue
@@ -221,7 +600,7 @@ func runServer() {
 			}
 
 			// We want to precache this if we don't have it
-			_, err := workRepo.RetrieveWorkFromCache(msg.Hash, 1)
+			_, err := workRepo.RetrieveWorkFromCache(serverconfig.DEFAULT_TENANT_ID, msg.Hash, 1)
 			if err == nil {
 				// Already cached
 				continue
@@ -235,8 +614,13 @@ func runServer() {
 				Hash:                 msg.Hash,
 				DifficultyMultiplier: 1,
 				Precache:             true,
+				TenantID:             serverconfig.DEFAULT_TENANT_ID,
 			}
 
+			if maintenanceSchedule.Active(time.Now()) != nil {
+				go controller.BroadcastWorkRequestAndWait(workRequest)
+				continue
+			}
 			controller.BroadcastWorkRequestAndWait(workRequest)
 			if msg.Block.Subtype != "send" {
 				continue
@@ -245,16 +629,212 @@ func runServer() {
 	}()
 
 	// Update stats and setup cron
-	repository.UpdateStats(paymentRepo, workRepo)
+	repository.UpdateStats(paymentRepo, workRepo, tenantRepo)
 	scheduler := gocron.NewScheduler(time.UTC)
 	scheduler.Every(10).Minutes().Do(func() {
-		repository.UpdateStats(paymentRepo, workRepo)
+		repository.UpdateStats(paymentRepo, workRepo, tenantRepo)
+	})
+	// The leaderboard query only reads what this computed
+	scheduler.Every(serverconfig.LEADERBOARD_REFRESH_MINUTES).Minutes().Do(func() {
+		if err := repository.RefreshLeaderboards(leaderboardRepo, tenantRepo, time.Now()); err != nil {
+			klog.Errorf("Error refreshing leaderboards %v", err)
+		}
+	})
+	// Expire keys that leaked without a TTL and drop entries of clients that are gone
+	scheduler.Every(1).Hour().Do(func() {
+		if _, err := database.GetRedisDB().AuditKeys(controller.ActiveHub.ConnectedIPs(), true); err != nil {
+			klog.Errorf("Error auditing redis keys %v", err)
+		}
+	})
+	// Metrics and adminMetrics show the last check, warnings are logged
+	scheduler.Every(serverconfig.REDIS_MEMORY_CHECK_MINUTES).Minutes().Do(func() {
+		if _, err := database.GetRedisDB().CheckMemory(time.Now()); err != nil {
+			klog.Errorf("Error checking redis memory %v", err)
+		}
+	})
+	scheduler.Every(serverconfig.RECONCILE_CLIENTS_INTERVAL_MINUTES).Minutes().Do(func() {
+		if _, err := controller.ActiveHub.ReconcileConnectedClients(); err != nil {
+			klog.Errorf("Error reconciling connected clients %v", err)
+		}
+	})
+	scheduler.Every(serverconfig.COUNTER_SNAPSHOT_INTERVAL_MINUTES).Minutes().Do(func() {
+		tenants, err := tenantRepo.GetAllTenants()
+		if err != nil {
+			klog.Errorf("Error getting tenants for the counter snapshot %v", err)
+			return
+		}
+		tenantIDs := make([]string, len(tenants))
+		for i, tenant := range tenants {
+			tenantIDs[i] = tenant.ID
+		}
+		if err := repository.SnapshotCounters(counterSnapshotRepo, tenantIDs, time.Now()); err != nil {
+			klog.Errorf("Error snapshotting redis counters %v", err)
+		}
 	})
+	// Payouts and stats must not silently run off diverged counters
+	if rollupsInPostgres {
+		scheduler.Every(1).Hour().Do(func() {
+			if _, err := repository.CheckStatsConsistency(rollupRepo, true, time.Now()); err != nil {
+				klog.Errorf("Error checking stats consistency %v", err)
+			}
+		})
+	}
+	scheduler.Every(1).Minute().Do(func() {
+		if err := maintenanceSchedule.Refresh(time.Now()); err != nil {
+			klog.Errorf("Error refreshing maintenance windows %v", err)
+		}
+		offlineMonitor.Check(time.Now())
+		if err := controller.LoadPolicy(hubPolicyRepo); err != nil {
+			klog.Errorf("Error refreshing hub policy %v", err)
+		}
+		if err := requestSampling.Refresh(); err != nil {
+			klog.Errorf("Error refreshing request sampling %v", err)
+		}
+	})
+	// Shedding has to reach every server quickly during an incident
+	scheduler.Every(serverconfig.LOAD_SHEDDING_REFRESH_SECONDS).Seconds().Do(func() {
+		if err := controller.RefreshShedding(); err != nil {
+			klog.Errorf("Error refreshing load shedding %v", err)
+		}
+	})
+	scheduler.Every(1).Minute().Do(func() {
+		if err := incidentManager.RunDetector(len(controller.ActiveHub.ConnectedIPs()), controller.HubEvents.All(), time.Now()); err != nil {
+			klog.Errorf("Error running incident detector %v", err)
+		}
+	})
+	// Statements of months that ended, unsent ones are retried every day
+	scheduler.Every(1).Day().At("01:00").Do(func() {
+		if err := repository.SendUsageStatements(usageRepo, userRepo, time.Now()); err != nil {
+			klog.Errorf("Error sending usage statements %v", err)
+		}
+	})
+	// Statements of payout cycles once all their payments were broadcast
+	scheduler.Every(serverconfig.PAYOUT_STATEMENT_INTERVAL_MINUTES).Minutes().Do(func() {
+		if err := payouts.SendStatements(payoutCycleRepo, userRepo, time.Now(), utils.GetPayoutHourUTC()); err != nil {
+			klog.Errorf("Error sending payout statements %v", err)
+		}
+	})
+	// Off unless BPOW_STALE_ACCOUNT_REMIND_DAYS is set
+	scheduler.Every(1).Day().At("02:00").Do(func() {
+		report, err := repository.RunStaleAccountCleanup(staleAccountRepo, models.GetStaleAccountPolicy(), time.Now())
+		if err != nil {
+			klog.Errorf("Error cleaning up stale accounts %v", err)
+		} else if report != nil {
+			klog.Infof("Stale accounts: %d reminded, %d disabled, %d anonymized, %d failed", report.Reminded, report.Disabled, report.Anonymized, report.Failed)
+		}
+	})
+	scheduler.StartAsync()
 
-	log.Fatal(http.ListenAndServe(":"+port, router))
+	// Prometheus and sibling services use a separate port that should only be reachable within the cluster
+	internalRouter := chi.NewRouter()
+	internalRouter.Handle("/metrics", metrics.Handler())
+	internalAPIKey, err := utils.GetSecret("BPOW_INTERNAL_API_KEY")
+	if err != nil {
+		klog.Errorf("Error reading the internal API key %v", err)
+	}
+	// Sibling services validate tokens with the internal API key
+	if internalAPIKey != "" {
+		internalRouter.Handle("/tokens/introspect", introspection.Handler(userRepo, apiKeyRepo, internalAPIKey))
+	}
+	internalServer := &http.Server{Addr: ":" + utils.GetInternalPort(), Handler: internalRouter}
+	go func() {
+		if err := internalServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-signals.Done()
+	stopSignals()
+	klog.Infof("Shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), serverconfig.SHUTDOWN_TIMEOUT_SECONDS*time.Second)
+	defer cancel()
+	// No new work is accepted, work requests already being solved still get their result since the workers stay connected
+	if err := server.Shutdown(ctx); err != nil {
+		klog.Errorf("Error waiting for in-flight requests %v", err)
+	}
+	scheduler.Stop()
+	// Workers tell their operators when to expect the server back
+	if window := maintenanceSchedule.Active(time.Now()); window != nil {
+		controller.ActiveHub.StopFor(serializableModels.DisconnectMaintenance, "until "+window.EndsAt.UTC().Format(time.RFC3339))
+	} else {
+		controller.ActiveHub.Stop()
+	}
+	// The stats of the last results, and their block awarded messages, are saved before exiting
+	close(statsChan)
+	<-statsDone
+	close(blockAwardedChan)
+	<-awardsDone
+	drainHubEvents()
+	if err := internalServer.Shutdown(ctx); err != nil {
+		klog.Errorf("Error shutting down the internal server %v", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+	klog.Infof("Shut down")
 }
 
-func createService(serviceName string, serviceURL string) {
+// Work stats stay in postgres unless BPOW_STATS_STORE=clickhouse
+func newStatsStore(db *gorm.DB) (repository.StatsStore, error) {
+	switch utils.GetStatsStore() {
+	case "postgres":
+		return repository.NewPostgresStatsStore(db), nil
+	case "clickhouse":
+		store := repository.NewClickHouseStatsStore(utils.GetClickHouseURL())
+		return store, store.CreateTable()
+	}
+	return nil, fmt.Errorf("unknown stats store %s", utils.GetStatsStore())
+}
+
+// Log levels come from BPOW_LOG_LEVELS_FILE if it's set, otherwise BPOW_LOG_LEVELS
+func loadLogLevels() error {
+	if path := utils.GetLogLevelsFile(); path != "" {
+		return logging.LoadFile(path)
+	}
+	return logging.ApplyLevels(utils.GetLogLevels())
+}
+
+func reloadLogLevelsOnHangup() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	for range hangup {
+		if err := loadLogLevels(); err != nil {
+			klog.Errorf("Error reloading log levels %v", err)
+			continue
+		}
+		klog.Infof("Reloaded log levels")
+	}
+}
+
+func auditRedisKeys(fix bool) {
+	godotenv.Load()
+	// Connected clients are only known to the running server, so they aren't checked here
+	report, err := database.GetRedisDB().AuditKeys(nil, fix)
+	if err != nil {
+		fmt.Printf("❌ Error auditing redis keys %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("🔍 Scanned %d keys\n", report.Scanned)
+	for _, key := range report.MissingTTL {
+		fmt.Printf("Missing TTL: %s\n", key)
+	}
+	for _, key := range report.Unknown {
+		fmt.Printf("Unknown key without TTL: %s\n", key)
+	}
+	if fix && len(report.MissingTTL) > 0 {
+		fmt.Printf("✅ Expired %d keys\n", len(report.MissingTTL))
+	}
+}
+
+func createService(serviceName string, serviceURL string, tenantID string) {
 	godotenv.Load()
 	// Setup database conn
 	config := &database.Config{
@@ -272,9 +852,16 @@ func createService(serviceName string, serviceURL string) {
 	}
 
 	userRepo := repository.NewUserService((db))
+	tenantRepo := repository.NewTenantService(db)
+
+	tenant, err := tenantRepo.GetTenant(tenantID)
+	if err != nil {
+		fmt.Printf("Unknown tenant %s\n", tenantID)
+		os.Exit(1)
+	}
 
 	// Create user
-	token, err := userRepo.CreateService(fmt.Sprintf("%s@banano.cc", serviceName), serviceName, serviceURL)
+	token, err := userRepo.CreateService(fmt.Sprintf("%s@banano.cc", serviceName), serviceName, serviceURL, tenant.ID)
 	if err != nil {
 		panic(err)
 	}
@@ -282,6 +869,154 @@ func createService(serviceName string, serviceURL string) {
 	fmt.Printf("🔑 Service created with token: %s", token)
 }
 
+// Returns false if any check failed
+func runPreflight(timeout time.Duration) bool {
+	config := &database.Config{
+		Host:     os.Getenv("DB_HOST"),
+		Port:     os.Getenv("DB_PORT"),
+		Password: os.Getenv("DB_PASS"),
+		User:     os.Getenv("DB_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   os.Getenv("DB_NAME"),
+	}
+	fmt.Println("🛫 Running preflight checks...")
+	results := preflight.Run(context.Background(), preflight.Checks(config, utils.GetPreflightEmail()), timeout)
+	preflight.Report(os.Stdout, results)
+	if preflight.Failed(results) {
+		fmt.Println("❌ Preflight checks failed")
+		return false
+	}
+	return true
+}
+
+func runBootstrap() {
+	godotenv.Load()
+	bootstrapConfig, err := bootstrap.LoadConfig()
+	if err != nil {
+		fmt.Printf("❌ Error loading bootstrap config %v\n", err)
+		os.Exit(1)
+	}
+	if bootstrapConfig.Empty() {
+		fmt.Println("🤷 Nothing to bootstrap, set BPOW_BOOTSTRAP_ADMIN_EMAIL or BPOW_BOOTSTRAP_SERVICES_FILE")
+		os.Exit(1)
+	}
+	// Setup database conn
+	config := &database.Config{
+		Host:     os.Getenv("DB_HOST"),
+		Port:     os.Getenv("DB_PORT"),
+		Password: os.Getenv("DB_PASS"),
+		User:     os.Getenv("DB_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   os.Getenv("DB_NAME"),
+	}
+	fmt.Println("🏡 Connecting to database...")
+	db, err := database.NewConnection(config)
+	if err != nil {
+		panic(err)
+	}
+	if err := database.Migrate(db); err != nil {
+		panic(err)
+	}
+
+	tokens, err := bootstrap.Run(bootstrapConfig, repository.NewUserService(db), repository.NewTenantService(db))
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if bootstrapConfig.AdminEmail != "" {
+		fmt.Printf("👑 Admin %s is ready\n", bootstrapConfig.AdminEmail)
+	}
+	for name, token := range tokens {
+		fmt.Printf("🔑 Service %s has token: %s\n", name, token)
+	}
+}
+
+func createTenant(id string, name string, prizePool int, maxDifficultyMultiplier int, networkDifficultyMultiplier int, clampExcessDifficulty bool) {
+	godotenv.Load()
+	// Setup database conn
+	config := &database.Config{
+		Host:     os.Getenv("DB_HOST"),
+		Port:     os.Getenv("DB_PORT"),
+		Password: os.Getenv("DB_PASS"),
+		User:     os.Getenv("DB_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   os.Getenv("DB_NAME"),
+	}
+	fmt.Println("🏡 Connecting to database...")
+	db, err := database.NewConnection(config)
+	if err != nil {
+		panic(err)
+	}
+
+	tenantRepo := repository.NewTenantService(db)
+
+	tenant, err := tenantRepo.CreateTenant(id, name, prizePool, maxDifficultyMultiplier, networkDifficultyMultiplier, clampExcessDifficulty)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("🏊 Tenant %s created\n", tenant.ID)
+}
+
+func exportDataset(outPath string, since time.Duration, k int) {
+	godotenv.Load()
+	// Setup database conn
+	config := &database.Config{
+		Host:     os.Getenv("DB_HOST"),
+		Port:     os.Getenv("DB_PORT"),
+		Password: os.Getenv("DB_PASS"),
+		User:     os.Getenv("DB_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   os.Getenv("DB_NAME"),
+	}
+	fmt.Println("🏡 Connecting to database...")
+	db, err := database.NewConnection(config)
+	if err != nil {
+		panic(err)
+	}
+
+	statsStore, err := newStatsStore(db)
+	if err != nil {
+		panic(err)
+	}
+
+	records, err := statsStore.GetResearchRecords(time.Now().Add(-since))
+	if err != nil {
+		panic(err)
+	}
+
+	// A fixed salt keeps pseudonyms stable across exports, otherwise every export is unlinkable
+	salt := []byte(utils.GetEnv("BPOW_RESEARCH_SALT", ""))
+	if len(salt) == 0 {
+		salt = make([]byte, 32)
+		if _, err := rand.Read(salt); err != nil {
+			panic(err)
+		}
+	}
+
+	rows, err := research.Anonymize(records, salt, time.Hour)
+	if err != nil {
+		panic(err)
+	}
+	rows, suppressed := research.EnforceKAnonymity(rows, k)
+	fmt.Printf("🕵️ Suppressed %d of %d records that did not meet %d-anonymity\n", suppressed, len(records), k)
+	if len(rows) == 0 {
+		fmt.Println("🤷 Nothing left to export")
+		os.Exit(1)
+	}
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	if err := research.WriteCSV(f, rows); err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("📦 Exported %d records to %s\n", len(rows), outPath)
+}
+
 func main() {
 	flag.Usage = usage
 	klog.InitFlags(nil)
@@ -298,6 +1033,22 @@ func main() {
 	addService := flag.Bool("addService", false, "Add service")
 	serviceName := flag.String("serviceName", "", "Service name")
 	serviceURL := flag.String("serviceURL", "", "Service URL")
+	tenantID := flag.String("tenant", serverconfig.DEFAULT_TENANT_ID, "Tenant (pool) the service or tenant belongs to")
+	addTenant := flag.Bool("addTenant", false, "Add tenant")
+	tenantName := flag.String("tenantName", "", "Tenant name")
+	tenantPrizePool := flag.Int("tenantPrizePool", 0, "Daily prize pool of the tenant, 0 uses the global prize pool")
+	tenantMaxDifficulty := flag.Int("tenantMaxDifficulty", 0, "Max difficulty multiplier of the tenant, 0 uses the global max")
+	tenantNetworkDifficulty := flag.Int("tenantNetworkDifficulty", 0, "Difficulty multiplier the tenant's network needs, 0 uses BPOW_NETWORK_DIFFICULTY_MULTIPLIER")
+	tenantClampDifficulty := flag.Bool("tenantClampDifficulty", false, "Clamp requests of requesters habitually asking for more than the network difficulty")
+	exportData := flag.Bool("exportDataset", false, "Export anonymized research dataset")
+	exportOut := flag.String("exportOut", "dataset.csv", "Path to write the research dataset to")
+	exportSince := flag.Duration("exportSince", 30*24*time.Hour, "How far back to include work in the research dataset")
+	exportK := flag.Int("exportK", 5, "Minimum equivalence class size (k-anonymity) for exported records")
+	auditRedis := flag.Bool("auditRedis", false, "Report redis keys that are missing a TTL")
+	auditRedisFix := flag.Bool("auditRedisFix", false, "Expire the keys found by -auditRedis")
+	runPreflightFlag := flag.Bool("preflight", false, "Check the configuration, postgres, redis, JWT key, email and nodes, then exit")
+	printSchema := flag.Bool("printSchema", false, "Print the schema as SDL for client SDK generation")
+	runBootstrapFlag := flag.Bool("bootstrap", false, "Create the admin and services configured with BPOW_BOOTSTRAP_* if they don't exist and print the service tokens")
 	flag.Parse()
 
 	if *gqlGen {
@@ -319,7 +1070,38 @@ func main() {
 			flag.Usage()
 			os.Exit(1)
 		}
-		createService(*serviceName, *serviceURL)
+		createService(*serviceName, *serviceURL, *tenantID)
+		os.Exit(0)
+	}
+	if *addTenant {
+		if *tenantID == "" || *tenantName == "" {
+			flag.Usage()
+			os.Exit(1)
+		}
+		createTenant(*tenantID, *tenantName, *tenantPrizePool, *tenantMaxDifficulty, *tenantNetworkDifficulty, *tenantClampDifficulty)
+		os.Exit(0)
+	}
+	if *exportData {
+		exportDataset(*exportOut, *exportSince, *exportK)
+		os.Exit(0)
+	}
+	if *auditRedis {
+		auditRedisKeys(*auditRedisFix)
+		os.Exit(0)
+	}
+	if *runPreflightFlag {
+		godotenv.Load()
+		if !runPreflight(serverconfig.PREFLIGHT_CHECK_TIMEOUT_SECONDS * time.Second) {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if *runBootstrapFlag {
+		runBootstrap()
+		os.Exit(0)
+	}
+	if *printSchema {
+		fmt.Print(graph.SchemaSDL(generated.NewExecutableSchema(generated.Config{}).Schema()))
 		os.Exit(0)
 	}
 	usage()
@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
+)
+
+// Failing to record activity doesn't fail what the user did
+func (r *Resolver) recordAccountEvent(ctx context.Context, userID uuid.UUID, eventType models.AccountEventType, detail string) {
+	clientIP := middleware.ClientIP(ctx)
+	securityEvent, notify := r.securityEvent(userID, eventType, clientIP)
+	if err := r.ActivityRepo.RecordAccountEvent(userID, eventType, detail, clientIP); err != nil {
+		klog.Errorf("Error recording %s account event %v", eventType, err)
+	}
+	if action, ok := auditedAccountEvents[eventType]; ok {
+		recordAudit(ctx, r.AuditLogRepo, models.AuditLogEntry{Action: action, Detail: detail, Success: true})
+	}
+	// In the background, a slow or failing webhook doesn't hold up the user
+	if notify {
+		go r.postSecurityEvent(userID, securityEvent, detail, clientIP)
+	}
+}
+
+func activityToModel(page pagination.Page[repository.ActivityItem]) *model.ActivityConnection {
+	connection := &model.ActivityConnection{
+		Nodes:    make([]*model.ActivityEvent, len(page.Items)),
+		PageInfo: pageInfoToModel(page),
+	}
+	for i, item := range page.Items {
+		event := &model.ActivityEvent{
+			Type:      item.Type,
+			Detail:    item.Detail,
+			BlockHash: item.BlockHash,
+			CreatedAt: item.CreatedAt.UTC().Format(time.RFC3339Nano),
+		}
+		if item.ClientIP != "" {
+			clientIP := item.ClientIP
+			event.ClientIP = &clientIP
+		}
+		if item.Type == repository.ActivityPayoutReceived {
+			amount := item.AmountBanano()
+			event.AmountBanano = &amount
+		}
+		connection.Nodes[i] = event
+	}
+	return connection
+}
@@ -0,0 +1,32 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestActivityPage(t *testing.T) {
+	at := time.Date(2022, 9, 1, 12, 0, 0, 123, time.UTC)
+	items := []repository.ActivityItem{
+		{ID: "2", Type: "login", ClientIP: "127.0.0.1", CreatedAt: at.Add(time.Minute)},
+		{ID: "1", Type: repository.ActivityPayoutReceived, AmountRaw: "1000000000000000000000000000000", CreatedAt: at},
+	}
+	connection := activityToModel(pagination.NewPage(items, pagination.Args{First: 1}, repository.ActivityItem.Cursor))
+	utils.AssertEqual(t, 1, len(connection.Nodes))
+	utils.AssertEqual(t, "127.0.0.1", *connection.Nodes[0].ClientIP)
+	utils.AssertEqual(t, true, connection.Nodes[0].AmountBanano == nil)
+	// The extra item only tells there's a next page, starting after the last item
+	utils.AssertEqual(t, true, connection.PageInfo.HasNextPage)
+	args, err := pagination.ParseArgs(nil, connection.PageInfo.EndCursor)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, args.After.Time.Equal(at.Add(time.Minute)))
+	utils.AssertEqual(t, "2", args.After.ID)
+
+	connection = activityToModel(pagination.NewPage(items[1:], args, repository.ActivityItem.Cursor))
+	utils.AssertEqual(t, "10.00", *connection.Nodes[0].AmountBanano)
+	utils.AssertEqual(t, false, connection.PageInfo.HasNextPage)
+}
@@ -0,0 +1,138 @@
+package graph
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/filter"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+// List fields are named like their enum values in lower case
+func filterField(field string) string {
+	return strings.ToLower(field)
+}
+
+func filterCondition(field string, op model.FilterOperator, values []string) filter.Condition {
+	return filter.Condition{Field: filterField(field), Op: filter.Op(strings.ToLower(string(op))), Values: values}
+}
+
+func userListQuery(filters []*model.UserFilter, sort *model.UserSort) (*filter.Query, error) {
+	conditions := make([]filter.Condition, len(filters))
+	for i, f := range filters {
+		conditions[i] = filterCondition(string(f.Field), f.Op, f.Values)
+	}
+	var listSort *filter.Sort
+	if sort != nil {
+		listSort = &filter.Sort{Field: filterField(string(sort.Field)), Descending: sort.Descending}
+	}
+	return repository.UserList.Compile(conditions, listSort)
+}
+
+func workResultListQuery(filters []*model.WorkResultFilter, sort *model.WorkResultSort) (*filter.Query, error) {
+	conditions := make([]filter.Condition, len(filters))
+	for i, f := range filters {
+		conditions[i] = filterCondition(string(f.Field), f.Op, f.Values)
+	}
+	var listSort *filter.Sort
+	if sort != nil {
+		listSort = &filter.Sort{Field: filterField(string(sort.Field)), Descending: sort.Descending}
+	}
+	return repository.WorkResultList.Compile(conditions, listSort)
+}
+
+func auditLogListQuery(filters []*model.AuditLogFilter, sort *model.AuditLogSort) (*filter.Query, error) {
+	conditions := make([]filter.Condition, len(filters))
+	for i, f := range filters {
+		conditions[i] = filterCondition(string(f.Field), f.Op, f.Values)
+	}
+	var listSort *filter.Sort
+	if sort != nil {
+		listSort = &filter.Sort{Field: filterField(string(sort.Field)), Descending: sort.Descending}
+	}
+	return repository.AuditLogList.Compile(conditions, listSort)
+}
+
+// Work the user provided, or requested if they're a requester
+func userWorkHistoryQuery(user *models.User) (*filter.Query, error) {
+	field := "provided_by"
+	if user.Type == models.REQUESTER {
+		field = "requested_by"
+	}
+	return repository.WorkResultList.Compile([]filter.Condition{{Field: field, Op: filter.Eq, Values: []string{user.ID.String()}}}, nil)
+}
+
+func (r *Resolver) workResultConnection(query *filter.Query, args pagination.Args) (*model.WorkResultConnection, error) {
+	results, err := r.WorkRepo.ListWorkResults(query, args)
+	if err != nil {
+		return nil, errors.New("error retrieving work results")
+	}
+	page := pagination.NewPage(results, args, repository.WorkResultCursor)
+	connection := &model.WorkResultConnection{
+		Nodes:    make([]*model.WorkResult, len(page.Items)),
+		PageInfo: pageInfoToModel(page),
+		Count: func() (int, error) {
+			return r.WorkRepo.CountWorkResults(query)
+		},
+	}
+	for i := range page.Items {
+		connection.Nodes[i] = workResultToModel(&page.Items[i])
+	}
+	return connection, nil
+}
+
+// Nil for times that weren't set
+func optionalISOString(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := utils.GenerateISOString(*t)
+	return &s
+}
+
+func adminUserToModel(user *models.User) *model.AdminUser {
+	ret := &model.AdminUser{
+		ID:                  user.ID.String(),
+		Email:               user.Email,
+		Type:                model.UserType(user.Type),
+		TenantID:            user.TenantID,
+		EmailVerified:       user.EmailVerified,
+		CanRequestWork:      user.CanRequestWork,
+		TwoFactorEnabled:    user.TwoFactorEnabled,
+		StaleExempt:         user.StaleExempt,
+		InvalidResultCount:  user.InvalidResultCount,
+		ServiceName:         user.ServiceName,
+		Roles:               make([]model.AccountRole, len(user.Roles)),
+		CreatedAt:           utils.GenerateISOString(user.CreatedAt),
+		UpdatedAt:           utils.GenerateISOString(user.UpdatedAt),
+		LastProvidedWorkAt:  optionalISOString(user.LastProvidedWorkAt),
+		LastRequestedWorkAt: optionalISOString(user.LastRequestedWorkAt),
+		DisabledAt:          optionalISOString(user.DisabledAt),
+	}
+	for i, role := range user.Roles {
+		ret.Roles[i] = model.AccountRole(role.Role)
+	}
+	return ret
+}
+
+func workResultToModel(result *models.WorkResult) *model.WorkResult {
+	return &model.WorkResult{
+		ID:                   result.ID.String(),
+		Hash:                 result.Hash,
+		Result:               result.Result,
+		DifficultyMultiplier: result.DifficultyMultiplier,
+		TenantID:             result.TenantID,
+		ProvidedBy:           result.ProvidedBy.String(),
+		RequestedBy:          result.RequestedBy.String(),
+		Awarded:              result.Awarded,
+		Precache:             result.Precache,
+		SolveLatencyMs:       int(result.SolveLatencyMs),
+		CreditPercent:        result.CreditPercent,
+		CreatedAt:            utils.GenerateISOString(result.CreatedAt),
+	}
+}
@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/filter"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+func TestListFieldsAreDeclared(t *testing.T) {
+	for _, field := range model.AllUserListField {
+		_, ok := repository.UserList.Fields[filterField(string(field))]
+		utils.AssertEqual(t, true, ok)
+	}
+	for _, field := range model.AllWorkResultListField {
+		_, ok := repository.WorkResultList.Fields[filterField(string(field))]
+		utils.AssertEqual(t, true, ok)
+	}
+	// Every op works on some field
+	for _, op := range model.AllFilterOperator {
+		field, value := model.UserListFieldInvalidResultCount, "1"
+		switch op {
+		case model.FilterOperatorContains:
+			field = model.UserListFieldEmail
+		case model.FilterOperatorIsNull:
+			field, value = model.UserListFieldDisabledAt, "true"
+		}
+		_, err := userListQuery([]*model.UserFilter{{Field: field, Op: op, Values: []string{value}}}, nil)
+		utils.AssertEqual(t, nil, err)
+	}
+}
+
+func TestUserListQuery(t *testing.T) {
+	query, err := userListQuery([]*model.UserFilter{
+		{Field: model.UserListFieldEmail, Op: model.FilterOperatorContains, Values: []string{"example"}},
+		{Field: model.UserListFieldDisabledAt, Op: model.FilterOperatorIsNull, Values: []string{"true"}},
+	}, &model.UserSort{Field: model.UserListFieldUpdatedAt, Descending: false})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, []filter.Clause{
+		{SQL: "email ILIKE ?", Args: []interface{}{"%example%"}},
+		{SQL: "disabled_at IS NULL"},
+	}, query.Clauses)
+	utils.AssertEqual(t, filter.Sort{Field: "updated_at"}, query.Sort)
+
+	_, err = userListQuery(nil, &model.UserSort{Field: model.UserListFieldEmail, Descending: true})
+	utils.AssertEqual(t, "bad_request:email can't be sorted on", err.Error())
+
+	_, err = workResultListQuery([]*model.WorkResultFilter{{Field: model.WorkResultListFieldProvidedBy, Op: model.FilterOperatorEq, Values: []string{"nobody"}}}, nil)
+	utils.AssertEqual(t, "bad_request:invalid value for provided_by", err.Error())
+}
+
+func TestAdminUserToModel(t *testing.T) {
+	user := &models.User{
+		Base:  models.Base{ID: uuid.New()},
+		Email: "admin@example.com",
+		Type:  models.PROVIDER,
+		Roles: []models.UserRole{{Role: models.RoleAdmin}},
+	}
+	ret := adminUserToModel(user)
+	utils.AssertEqual(t, user.ID.String(), ret.ID)
+	utils.AssertEqual(t, []model.AccountRole{model.AccountRoleAdmin}, ret.Roles)
+	utils.AssertEqual(t, true, ret.DisabledAt == nil)
+	utils.AssertEqual(t, model.UserTypeProvider, ret.Type)
+}
+
+func TestUserWorkHistoryQuery(t *testing.T) {
+	provider := &models.User{Base: models.Base{ID: uuid.New()}, Type: models.PROVIDER}
+	query, err := userWorkHistoryQuery(provider)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, []filter.Clause{{SQL: "provided_by = ?", Args: []interface{}{provider.ID}}}, query.Clauses)
+
+	requester := &models.User{Base: models.Base{ID: uuid.New()}, Type: models.REQUESTER}
+	query, err = userWorkHistoryQuery(requester)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, []filter.Clause{{SQL: "requested_by = ?", Args: []interface{}{requester.ID}}}, query.Clauses)
+	utils.AssertEqual(t, filter.Sort{Field: "created_at", Descending: true}, query.Sort)
+}
@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/controller"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+// The parts of the admin metrics this server counted itself
+func adminMetricsToModel(rates controller.RequestRates, events map[models.HubEventType]int, hub controller.HubSnapshot, quarantined int) *model.AdminMetrics {
+	return &model.AdminMetrics{
+		WindowMinutes:         int(rates.Window.Minutes()),
+		WorkRequests:          rates.Requests,
+		WorkRequestsPerMinute: rates.PerMinute(),
+		CachedWorkRequests:    rates.Cached,
+		FailedWorkRequests:    rates.Failed,
+		ErrorRate:             rates.ErrorRate(),
+		Timeouts:              events[models.HubEventTimeout],
+		Rejections:            events[models.HubEventRejected],
+		OpenWorkRequests:      hub.OpenRequests,
+		BroadcastQueue:        broadcastQueueToModel(hub.BroadcastQueue),
+		StatsQueue:            hub.StatsQueue,
+		ServerWorkers:         hub.Workers,
+		QuarantinedWorkers:    quarantined,
+	}
+}
+
+func broadcastQueueToModel(depths map[controller.Priority]int) []*model.BroadcastQueueDepth {
+	ret := make([]*model.BroadcastQueueDepth, len(controller.Priorities))
+	for i, priority := range controller.Priorities {
+		ret[i] = &model.BroadcastQueueDepth{Priority: priority.String(), Depth: depths[priority]}
+	}
+	return ret
+}
+
+// Nil before the first check
+func redisMemoryToModel(report *database.RedisMemoryReport) *model.RedisMemory {
+	if report == nil {
+		return nil
+	}
+	prefixes := make([]*model.RedisPrefixMemory, len(report.Prefixes))
+	for i, prefix := range report.Prefixes {
+		prefixes[i] = &model.RedisPrefixMemory{Prefix: prefix.Prefix, Keys: prefix.Keys, Bytes: int(prefix.Bytes)}
+	}
+	return &model.RedisMemory{
+		CheckedAt:         utils.GenerateISOString(report.CheckedAt),
+		UsedBytes:         int(report.UsedBytes),
+		MaxBytes:          int(report.MaxBytes),
+		UsedPercent:       report.UsedPercent(),
+		EvictionPolicy:    report.EvictionPolicy,
+		CountersEvictable: report.CountersEvictable(),
+		Warnings:          report.Warnings(),
+		Prefixes:          prefixes,
+	}
+}
@@ -0,0 +1,48 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/controller"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestAdminMetricsToModel(t *testing.T) {
+	rates := controller.RequestRates{Window: 5 * time.Minute, Requests: 50, Cached: 10, Failed: 5}
+	events := map[models.HubEventType]int{models.HubEventTimeout: 3, models.HubEventRejected: 1, models.HubEventResult: 40}
+	metrics := adminMetricsToModel(rates, events, controller.HubSnapshot{Workers: 7, OpenRequests: 2, BroadcastQueue: map[controller.Priority]int{controller.PriorityPrecache: 3}}, 4)
+
+	utils.AssertEqual(t, 5, metrics.WindowMinutes)
+	utils.AssertEqual(t, 10.0, metrics.WorkRequestsPerMinute)
+	utils.AssertEqual(t, 0.1, metrics.ErrorRate)
+	utils.AssertEqual(t, 3, metrics.Timeouts)
+	utils.AssertEqual(t, 1, metrics.Rejections)
+	utils.AssertEqual(t, 2, metrics.OpenWorkRequests)
+	utils.AssertEqual(t, 7, metrics.ServerWorkers)
+	utils.AssertEqual(t, 4, metrics.QuarantinedWorkers)
+	// Every priority is listed, most urgent first
+	utils.AssertEqual(t, 5, len(metrics.BroadcastQueue))
+	utils.AssertEqual(t, "control", metrics.BroadcastQueue[0].Priority)
+	utils.AssertEqual(t, "precache", metrics.BroadcastQueue[3].Priority)
+	utils.AssertEqual(t, 3, metrics.BroadcastQueue[3].Depth)
+}
+
+func TestRedisMemoryToModel(t *testing.T) {
+	utils.AssertEqual(t, true, redisMemoryToModel(nil) == nil)
+
+	memory := redisMemoryToModel(&database.RedisMemoryReport{
+		CheckedAt:      time.Date(2022, 10, 1, 9, 0, 0, 0, time.UTC),
+		UsedBytes:      850,
+		MaxBytes:       1000,
+		EvictionPolicy: "allkeys-lru",
+		Prefixes:       []database.RedisPrefixMemory{{Prefix: "cache:", Keys: 3, Bytes: 600}},
+	})
+	utils.AssertEqual(t, 85.0, memory.UsedPercent)
+	utils.AssertEqual(t, true, memory.CountersEvictable)
+	utils.AssertEqual(t, 2, len(memory.Warnings))
+	utils.AssertEqual(t, "cache:", memory.Prefixes[0].Prefix)
+	utils.AssertEqual(t, 600, memory.Prefixes[0].Bytes)
+}
@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+// Returns the trimmed name, names are for telling keys apart, e.g. the service or environment using them
+func validateAPIKeyInput(input model.GenerateAPIKeyInput) (string, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > 64 {
+		return "", errors.New("bad_request:name must be 1 to 64 characters")
+	}
+	if input.RateLimitPerMinute != nil && (*input.RateLimitPerMinute < 1 || *input.RateLimitPerMinute > config.MAX_API_KEY_RATE_LIMIT) {
+		return "", fmt.Errorf("bad_request:rateLimitPerMinute must be between 1 and %d", config.MAX_API_KEY_RATE_LIMIT)
+	}
+	return name, nil
+}
+
+func apiKeyError(err error) error {
+	if errors.Is(err, repository.ErrTooManyAPIKeys) {
+		return fmt.Errorf("bad_request:at most %d api keys, revoke one first", config.MAX_API_KEYS_PER_USER)
+	}
+	return errors.New("error generating api key")
+}
+
+func apiKeyToModel(apiKey *models.APIKey) *model.APIKey {
+	ret := &model.APIKey{
+		ID:                 apiKey.ID.String(),
+		Name:               apiKey.Name,
+		Hint:               apiKey.Hint,
+		RateLimitPerMinute: apiKey.RateLimitPerMinute,
+		CreatedAt:          utils.GenerateISOString(apiKey.CreatedAt),
+	}
+	if apiKey.LastUsedAt != nil {
+		lastUsedAt := utils.GenerateISOString(*apiKey.LastUsedAt)
+		ret.LastUsedAt = &lastUsedAt
+	}
+	return ret
+}
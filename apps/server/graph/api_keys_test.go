@@ -0,0 +1,41 @@
+package graph
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestValidateAPIKeyInput(t *testing.T) {
+	name, err := validateAPIKeyInput(model.GenerateAPIKeyInput{Name: "  wallet backend "})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "wallet backend", name)
+
+	_, err = validateAPIKeyInput(model.GenerateAPIKeyInput{Name: " "})
+	utils.AssertNotEqual(t, nil, err)
+	_, err = validateAPIKeyInput(model.GenerateAPIKeyInput{Name: strings.Repeat("a", 65)})
+	utils.AssertNotEqual(t, nil, err)
+	zero := 0
+	_, err = validateAPIKeyInput(model.GenerateAPIKeyInput{Name: "faucet", RateLimitPerMinute: &zero})
+	utils.AssertNotEqual(t, nil, err)
+	limit := 60
+	_, err = validateAPIKeyInput(model.GenerateAPIKeyInput{Name: "faucet", RateLimitPerMinute: &limit})
+	utils.AssertEqual(t, nil, err)
+
+	utils.AssertEqual(t, true, strings.HasPrefix(apiKeyError(repository.ErrTooManyAPIKeys).Error(), "bad_request:"))
+	utils.AssertEqual(t, "error generating api key", apiKeyError(errors.New("connection refused")).Error())
+}
+
+func TestAPIKeyToModel(t *testing.T) {
+	lastUsedAt := time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC)
+	apiKey := apiKeyToModel(&models.APIKey{Name: "faucet", Hint: "1a2b3c4d", LastUsedAt: &lastUsedAt})
+	utils.AssertEqual(t, "1a2b3c4d", apiKey.Hint)
+	utils.AssertEqual(t, "2022-10-01T08:00:00Z", *apiKey.LastUsedAt)
+	utils.AssertEqual(t, true, apiKey.RateLimitPerMinute == nil)
+}
@@ -0,0 +1,136 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/apps/server/src/sampling"
+	"github.com/vektah/gqlparser/v2/ast"
+	"k8s.io/klog/v2"
+)
+
+// Account events of the request's user that also go to the audit log, logins are recorded by the login mutation
+var auditedAccountEvents = map[models.AccountEventType]models.AuditAction{
+	models.AccountEventPasswordChanged:        models.AuditPasswordChanged,
+	models.AccountEventPayoutAddressesChanged: models.AuditPayoutAddressesChanged,
+}
+
+// Failing to record doesn't fail what was done, entries without an actor are by the request's user if there is one
+func recordAudit(ctx context.Context, repo repository.AuditLogRepo, entry models.AuditLogEntry) {
+	if repo == nil {
+		return
+	}
+	if entry.ActorID == nil {
+		if user := middleware.RequestUser(ctx); user != nil {
+			entry.ActorID = &user.ID
+			entry.ActorEmail = user.Email
+		}
+	}
+	entry.ClientIP = middleware.ClientIP(ctx)
+	if err := repo.RecordAuditEvent(&entry); err != nil {
+		klog.Errorf("Error recording %s audit event %v", entry.Action, err)
+	}
+}
+
+// Logins have no user on the request yet
+func (r *Resolver) recordLogin(ctx context.Context, user *models.User) {
+	recordAudit(ctx, r.AuditLogRepo, models.AuditLogEntry{Action: models.AuditLogin, ActorID: &user.ID, ActorEmail: user.Email, Success: true})
+}
+
+func (r *Resolver) recordLoginFailure(ctx context.Context, email string, reason string) {
+	recordAudit(ctx, r.AuditLogRepo, models.AuditLogEntry{Action: models.AuditLoginFailed, Target: email, Detail: reason})
+}
+
+// Records every admin mutation, the fields behind @hasPermission or @auth(requires: ADMIN), with its sanitized arguments
+type AdminAudit struct {
+	Repo repository.AuditLogRepo
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = AdminAudit{}
+
+func (AdminAudit) ExtensionName() string {
+	return "AdminAudit"
+}
+
+func (AdminAudit) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (a AdminAudit) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Object != "Mutation" || !isAdminField(fc.Field.Definition) {
+		return next(ctx)
+	}
+	res, err := next(ctx)
+	entry := models.AuditLogEntry{Action: models.AuditAdminAction, Target: fc.Field.Name, Success: err == nil}
+	entry.Detail = sanitizedArgs(fc.Args)
+	recordAudit(ctx, a.Repo, entry)
+	return res, err
+}
+
+// Arguments are typed inputs, they're sanitized as JSON
+func sanitizedArgs(args map[string]interface{}) string {
+	b, err := json.Marshal(args)
+	if err != nil {
+		return ""
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return ""
+	}
+	b, err = json.Marshal(sampling.Sanitize(decoded))
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func isAdminField(field *ast.FieldDefinition) bool {
+	if field == nil {
+		return false
+	}
+	if field.Directives.ForName("hasPermission") != nil {
+		return true
+	}
+	auth := field.Directives.ForName("auth")
+	if auth == nil {
+		return false
+	}
+	requires := auth.Arguments.ForName("requires")
+	return requires != nil && requires.Value != nil && requires.Value.Raw == string(model.RoleAdmin)
+}
+
+func auditLogEntryToModel(entry *models.AuditLogEntry) *model.AuditLogEntry {
+	ret := &model.AuditLogEntry{
+		ID:        entry.ID.String(),
+		Action:    string(entry.Action),
+		Success:   entry.Success,
+		CreatedAt: entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	if entry.ActorID != nil {
+		actorID := entry.ActorID.String()
+		ret.ActorID = &actorID
+	}
+	if entry.ActorEmail != "" {
+		ret.ActorEmail = &entry.ActorEmail
+	}
+	if entry.Target != "" {
+		ret.Target = &entry.Target
+	}
+	if entry.Detail != "" {
+		ret.Detail = &entry.Detail
+	}
+	if entry.ClientIP != "" {
+		ret.ClientIP = &entry.ClientIP
+	}
+	return ret
+}
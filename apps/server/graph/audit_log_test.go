@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/src/filter"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+type fakeAuditLogRepo struct {
+	entries []models.AuditLogEntry
+}
+
+func (r *fakeAuditLogRepo) RecordAuditEvent(entry *models.AuditLogEntry) error {
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *fakeAuditLogRepo) ListAuditLog(query *filter.Query, args pagination.Args) ([]models.AuditLogEntry, error) {
+	return r.entries, nil
+}
+
+func (r *fakeAuditLogRepo) CountAuditLog(query *filter.Query) (int, error) {
+	return len(r.entries), nil
+}
+
+func TestAdminAudit(t *testing.T) {
+	repo := &fakeAuditLogRepo{}
+	audit := AdminAudit{Repo: repo}
+	field := func(object string, directives ast.DirectiveList, args map[string]interface{}) context.Context {
+		return graphql.WithFieldContext(context.Background(), &graphql.FieldContext{
+			Object: object,
+			Field:  graphql.CollectedField{Field: &ast.Field{Name: "banUser", Definition: &ast.FieldDefinition{Name: "banUser", Directives: directives}}},
+			Args:   args,
+		})
+	}
+	permission := ast.DirectiveList{{Name: "hasPermission"}}
+	admin := ast.DirectiveList{{Name: "auth", Arguments: ast.ArgumentList{{Name: "requires", Value: &ast.Value{Raw: "ADMIN", Kind: ast.EnumValue}}}}}
+	user := ast.DirectiveList{{Name: "auth", Arguments: ast.ArgumentList{{Name: "requires", Value: &ast.Value{Raw: "USER", Kind: ast.EnumValue}}}}}
+	ok := func(ctx context.Context) (interface{}, error) { return true, nil }
+
+	audit.InterceptField(field("Mutation", permission, map[string]interface{}{"email": "joe@example.com", "password": "hunter2"}), ok)
+	audit.InterceptField(field("Mutation", admin, nil), func(ctx context.Context) (interface{}, error) { return nil, errors.New("nope") })
+	// Queries and user mutations aren't admin actions
+	audit.InterceptField(field("Query", permission, nil), ok)
+	audit.InterceptField(field("Mutation", user, nil), ok)
+
+	utils.AssertEqual(t, 2, len(repo.entries))
+	utils.AssertEqual(t, models.AuditAdminAction, repo.entries[0].Action)
+	utils.AssertEqual(t, "banUser", repo.entries[0].Target)
+	utils.AssertEqual(t, true, repo.entries[0].Success)
+	// Credentials in the arguments are redacted
+	utils.AssertEqual(t, `{"email":"joe@example.com","password":"[redacted]"}`, repo.entries[0].Detail)
+	utils.AssertEqual(t, false, repo.entries[1].Success)
+}
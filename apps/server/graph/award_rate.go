@@ -0,0 +1,20 @@
+package graph
+
+import (
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+	"github.com/bananocoin/boompow/libs/utils/number"
+)
+
+func awardRateToModel(rate *models.AwardRate) *model.AwardRate {
+	bananoPerUnit, _ := number.RawToBanano(rate.RawPerUnit, false)
+	return &model.AwardRate{
+		ID:            rate.ID.String(),
+		TenantID:      rate.TenantID,
+		BananoPerUnit: bananoPerUnit,
+		RawPerUnit:    rate.RawPerUnit,
+		EffectiveAt:   utils.GenerateISOString(rate.EffectiveAt),
+		CreatedAt:     utils.GenerateISOString(rate.CreatedAt),
+	}
+}
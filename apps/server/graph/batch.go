@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Runs the operations of a JSON array POSTed to /graphql one after another and answers with an array of their responses
+// Each operation fails on its own without failing the others, they all run with the request's authorization
+// Has to be added before transport.POST, which would refuse the array
+type BatchPOST struct{}
+
+var _ graphql.Transport = BatchPOST{}
+
+func (BatchPOST) Supports(r *http.Request) bool {
+	return middleware.IsGraphQLBatch(r)
+}
+
+func (BatchPOST) Do(w http.ResponseWriter, r *http.Request, exec graphql.GraphExecutor) {
+	w.Header().Set("Content-Type", "application/json")
+
+	start := graphql.Now()
+	var batch []*graphql.RawParams
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&batch); err != nil {
+		writeBatchError(w, "json body could not be decoded: "+err.Error())
+		return
+	}
+	if len(batch) == 0 {
+		writeBatchError(w, "batch has no operations")
+		return
+	}
+	if len(batch) > config.GRAPHQL_MAX_BATCH_SIZE {
+		writeBatchError(w, fmt.Sprintf("batch has %d operations, at most %d are allowed", len(batch), config.GRAPHQL_MAX_BATCH_SIZE))
+		return
+	}
+	readTime := graphql.TraceTiming{Start: start, End: graphql.Now()}
+
+	ctx, totalCost := middleware.WithBatchCost(r.Context())
+	responses := make([]*graphql.Response, len(batch))
+	for i, params := range batch {
+		if params == nil {
+			responses[i] = &graphql.Response{Errors: gqlerror.List{{Message: "operation must be an object"}}}
+			continue
+		}
+		params.Headers = r.Header
+		params.ReadTime = readTime
+		rc, errs := exec.CreateOperationContext(ctx, params)
+		if errs != nil {
+			responses[i] = exec.DispatchError(graphql.WithOperationContext(ctx, rc), errs)
+			continue
+		}
+		// A subscription would have the batch wait on it forever
+		if rc.Operation.Operation == ast.Subscription {
+			responses[i] = exec.DispatchError(graphql.WithOperationContext(ctx, rc), gqlerror.List{{Message: "subscriptions can't be batched"}})
+			continue
+		}
+		handler, operationCtx := exec.DispatchOperation(ctx, rc)
+		responses[i] = handler(operationCtx)
+	}
+	w.Header().Set("X-Query-Cost", strconv.Itoa(totalCost()))
+
+	b, err := json.Marshal(responses)
+	if err != nil {
+		panic(err)
+	}
+	w.Write(b)
+}
+
+func writeBatchError(w http.ResponseWriter, msg string) {
+	w.WriteHeader(http.StatusBadRequest)
+	b, _ := json.Marshal(&graphql.Response{Errors: gqlerror.List{{Message: msg}}})
+	w.Write(b)
+}
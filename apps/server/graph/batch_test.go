@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/bananocoin/boompow/apps/server/graph/generated"
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestBatchPOST(t *testing.T) {
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: &Resolver{}, Directives: generated.DirectiveRoot{Auth: Auth, HasPermission: HasPermission}}))
+	srv.AddTransport(BatchPOST{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(&RateLimitReport{})
+	limiter := middleware.NewQueuedRateLimiter(20, time.Minute, 0, 0, func(r *http.Request) (string, error) {
+		return "client", nil
+	})
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		limiter.Handler(srv).ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Operations fail on their own, and each counts against the limit
+	rec := post(`[
+		{"query": "{ difficultyPresets { name } }"},
+		{"query": "{ nope }"},
+		{"query": "{ getUser { email } }"},
+		{"query": "query($n: Int!) { validateWork(input: {hash: \"3F93C5CD2E314FA16702189041E68E68C07B27961BF37F0B7705145BEFBA3AA3\", work: \"205452237a9b01f4\", difficultyMultiplier: $n}) }", "variables": {"n": 1}}
+	]`)
+	utils.AssertEqual(t, http.StatusOK, rec.Code)
+	var responses []struct {
+		Data   map[string]json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	utils.AssertEqual(t, nil, json.Unmarshal(rec.Body.Bytes(), &responses))
+	utils.AssertEqual(t, 4, len(responses))
+	utils.AssertEqual(t, 0, len(responses[0].Errors))
+	utils.AssertEqual(t, true, len(responses[0].Data["difficultyPresets"]) > 2)
+	utils.AssertEqual(t, 1, len(responses[1].Errors))
+	// Directives still check the request's authorization for each operation
+	utils.AssertEqual(t, 1, len(responses[2].Errors))
+	utils.AssertEqual(t, "true", string(responses[3].Data["validateWork"]))
+	utils.AssertEqual(t, "16", rec.Header().Get("X-RateLimit-Remaining"))
+	utils.AssertEqual(t, "5", rec.Header().Get("X-Query-Cost"))
+
+	// Single operations are left to the POST transport
+	rec = post(`{"query": "{ difficultyPresets { name } }"}`)
+	utils.AssertEqual(t, http.StatusOK, rec.Code)
+	utils.AssertEqual(t, true, strings.HasPrefix(rec.Body.String(), `{"data"`))
+
+	// Oversized batches are refused as a whole and count as one request
+	operations := make([]string, config.GRAPHQL_MAX_BATCH_SIZE+1)
+	for i := range operations {
+		operations[i] = `{"query": "{ difficultyPresets { name } }"}`
+	}
+	rec = post("[" + strings.Join(operations, ",") + "]")
+	utils.AssertEqual(t, http.StatusBadRequest, rec.Code)
+	utils.AssertEqual(t, true, strings.Contains(rec.Body.String(), fmt.Sprintf("at most %d", config.GRAPHQL_MAX_BATCH_SIZE)))
+	rec = post("[]")
+	utils.AssertEqual(t, http.StatusBadRequest, rec.Code)
+	utils.AssertEqual(t, "13", rec.Header().Get("X-RateLimit-Remaining"))
+}
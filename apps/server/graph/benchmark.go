@@ -0,0 +1,24 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/privacy"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+)
+
+// Work per second is blurred if public stats noise is enabled, hardware only one provider runs would give away their capacity
+func hardwareBenchmarksToModel(leaderboard []repository.HardwareBenchmark, blurrer privacy.Blurrer, now time.Time) []*model.HardwareBenchmark {
+	ret := make([]*model.HardwareBenchmark, len(leaderboard))
+	for i, entry := range leaderboard {
+		ret[i] = &model.HardwareBenchmark{
+			Hardware:      entry.Hardware,
+			Backend:       model.BenchmarkBackend(entry.Backend),
+			Profiles:      entry.Profiles,
+			Providers:     entry.Providers,
+			WorkPerSecond: blurrer.Blur(entry.Hardware+":"+entry.Backend, entry.WorkPerSecond, now),
+		}
+	}
+	return ret
+}
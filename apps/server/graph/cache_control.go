@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Public queries that CDNs can cache, and for how long
+var cacheableQueries = map[string]time.Duration{
+	"status":                 15 * time.Second,
+	"incidentHistory":        time.Minute,
+	"maintenanceWindows":     time.Minute,
+	"hubPolicy":              time.Minute,
+	"payoutCalendar":         time.Minute,
+	"difficultyDistribution": time.Minute,
+	"hardwareLeaderboard":    5 * time.Minute,
+	"awardRateHistory":       5 * time.Minute,
+	"networkMap":             5 * time.Minute,
+}
+
+// Marks GET queries that only ask for cacheable fields, see middleware.CacheControlMiddleware
+type CacheControl struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = CacheControl{}
+
+func (CacheControl) ExtensionName() string {
+	return "CacheControl"
+}
+
+func (CacheControl) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (CacheControl) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	if maxAge := cacheMaxAge(graphql.GetOperationContext(ctx)); maxAge > 0 {
+		middleware.SetCacheMaxAge(ctx, maxAge)
+	}
+	return next(ctx)
+}
+
+// The shortest max age of the requested fields, zero if any of them can't be cached
+func cacheMaxAge(oc *graphql.OperationContext) time.Duration {
+	if oc == nil || oc.Operation == nil || oc.Operation.Operation != ast.Query {
+		return 0
+	}
+	var maxAge time.Duration
+	for _, field := range graphql.CollectFields(oc, oc.Operation.SelectionSet, []string{"Query"}) {
+		fieldMaxAge, ok := cacheableQueries[field.Name]
+		if !ok {
+			return 0
+		}
+		if maxAge == 0 || fieldMaxAge < maxAge {
+			maxAge = fieldMaxAge
+		}
+	}
+	return maxAge
+}
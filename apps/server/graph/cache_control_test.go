@@ -0,0 +1,27 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/graph/generated"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/vektah/gqlparser/v2"
+)
+
+func TestCacheMaxAge(t *testing.T) {
+	schema := generated.NewExecutableSchema(generated.Config{Resolvers: &Resolver{}}).Schema()
+	maxAge := func(query string) time.Duration {
+		doc := gqlparser.MustLoadQuery(schema, query)
+		return cacheMaxAge(&graphql.OperationContext{Doc: doc, Operation: doc.Operations[0], Variables: map[string]interface{}{}})
+	}
+
+	utils.AssertEqual(t, 15*time.Second, maxAge(`{ status { status } }`))
+	// The shortest wins
+	utils.AssertEqual(t, 15*time.Second, maxAge(`{ status { status } awardRateHistory { id } }`))
+	utils.AssertEqual(t, 5*time.Minute, maxAge(`{ hardwareLeaderboard { hardware } }`))
+	// Challenges must be fresh
+	utils.AssertEqual(t, time.Duration(0), maxAge(`{ status { status } powChallenge { hash } }`))
+	utils.AssertEqual(t, time.Duration(0), maxAge(`mutation { resetPassword(input: {email: "a@b.c"}) }`))
+}
@@ -0,0 +1,32 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+)
+
+// Anonymous endpoints that are prone to abuse require a solved challenge, if a verifier is configured
+func (r *Resolver) requireChallenge(ctx context.Context) error {
+	if r.ChallengeVerifier == nil {
+		return nil
+	}
+	response := middleware.ChallengeResponse(ctx)
+	if response == nil {
+		return errors.New("challenge_required")
+	}
+	if err := r.ChallengeVerifier.Verify(*response, r.now()); err != nil {
+		return err
+	}
+	// Challenges are stateless, so remember solved ones until they expire to prevent replays
+	fresh, err := database.GetRedisDB().ConsumeChallenge(response.Challenge)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return errors.New("challenge_already_used")
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package graph
+
+import (
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+func connectedWorkersToModel(snapshots []models.ConnectedWorkersSnapshot) []*model.ConnectedWorkersPoint {
+	ret := make([]*model.ConnectedWorkersPoint, len(snapshots))
+	for i, snapshot := range snapshots {
+		ret[i] = &model.ConnectedWorkersPoint{
+			TakenAt:          utils.GenerateISOString(snapshot.TakenAt),
+			ConnectedWorkers: int(snapshot.Workers),
+		}
+	}
+	return ret
+}
@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/crosscheck"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+// Checks the shape of work sent to us, not whether it's valid
+func checkWorkInput(hash string, work string, difficultyMultiplier int) error {
+	if _, err := hex.DecodeString(hash); err != nil || len(hash) != 64 {
+		return errors.New("bad_request:invalid hash")
+	}
+	if _, err := hex.DecodeString(work); err != nil || len(work) != 16 {
+		return errors.New("bad_request:invalid work")
+	}
+	if difficultyMultiplier < 1 {
+		return errors.New("bad_request:difficultyMultiplier must be at least 1")
+	}
+	return nil
+}
+
+func crossCheckToModel(stats crosscheck.Stats) *model.ValidationCrossCheck {
+	peers := make([]*model.ValidationPeerStats, len(stats.Peers))
+	for i, peer := range stats.Peers {
+		peers[i] = &model.ValidationPeerStats{
+			Peer:          peer.Peer,
+			Checked:       peer.Checked,
+			Disagreements: peer.Disagreements,
+			Errors:        peer.Errors,
+		}
+		if peer.LastError != "" {
+			lastError := peer.LastError
+			peers[i].LastError = &lastError
+		}
+	}
+	disagreements := make([]*model.ValidationDisagreement, len(stats.RecentDisagreements))
+	for i, disagreement := range stats.RecentDisagreements {
+		disagreements[i] = &model.ValidationDisagreement{
+			Peer:                 disagreement.Peer,
+			Hash:                 disagreement.Hash,
+			Work:                 disagreement.Work,
+			DifficultyMultiplier: disagreement.DifficultyMultiplier,
+			OursValid:            disagreement.OursValid,
+			At:                   utils.GenerateISOString(disagreement.At),
+		}
+	}
+	return &model.ValidationCrossCheck{
+		SamplePercent:       stats.SamplePercent,
+		Skipped:             stats.Skipped,
+		Peers:               peers,
+		RecentDisagreements: disagreements,
+	}
+}
@@ -0,0 +1,32 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/crosscheck"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestCheckWorkInput(t *testing.T) {
+	hash := strings.Repeat("A", 64)
+	utils.AssertEqual(t, nil, checkWorkInput(hash, "205452237a9b01f4", 1))
+	utils.AssertEqual(t, "bad_request:invalid hash", checkWorkInput("ABCD", "205452237a9b01f4", 1).Error())
+	utils.AssertEqual(t, "bad_request:invalid work", checkWorkInput(hash, "zz5452237a9b01f4", 1).Error())
+	utils.AssertNotEqual(t, nil, checkWorkInput(hash, "205452237a9b01f4", 0))
+}
+
+func TestCrossCheckToModel(t *testing.T) {
+	stats := crosscheck.Stats{
+		SamplePercent: 1,
+		Peers:         []crosscheck.PeerStats{{Peer: "node:http://[::1]:7072", Checked: 3}, {Peer: "boompow:https://boompow.banano.cc/graphql", Errors: 1, LastError: "timeout"}},
+		RecentDisagreements: []crosscheck.Disagreement{
+			{Peer: "node:http://[::1]:7072", OursValid: true, At: time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC)},
+		},
+	}
+	ret := crossCheckToModel(stats)
+	utils.AssertEqual(t, (*string)(nil), ret.Peers[0].LastError)
+	utils.AssertEqual(t, "timeout", *ret.Peers[1].LastError)
+	utils.AssertEqual(t, "2022-10-01T08:00:00Z", ret.RecentDisagreements[0].At)
+}
@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
+)
+
+// Sent in the difficultyAdvisory extension of workGenerate responses
+type difficultyAdvisory struct {
+	RequestedDifficultyMultiplier int   `json:"requestedDifficultyMultiplier"`
+	SuggestedDifficultyMultiplier int   `json:"suggestedDifficultyMultiplier"`
+	ExcessRequests                int64 `json:"excessRequests"`
+	// The request was done at the suggested difficulty instead
+	Clamped bool `json:"clamped"`
+}
+
+// Only requesters who keep asking for more than the network needs are advised, nil otherwise
+func adviseDifficulty(requested int, network int, streak int64, clamp bool) *difficultyAdvisory {
+	if requested <= network || streak < config.DIFFICULTY_ADVISORY_STREAK {
+		return nil
+	}
+	return &difficultyAdvisory{
+		RequestedDifficultyMultiplier: requested,
+		SuggestedDifficultyMultiplier: network,
+		ExcessRequests:                streak,
+		Clamped:                       clamp,
+	}
+}
+
+// Returns the difficulty multiplier to work at, which is only lowered if the tenant clamps excess difficulty
+func (r *Resolver) checkExcessDifficulty(ctx context.Context, userID uuid.UUID, tenant *models.Tenant, requested int) int {
+	network := tenant.GetNetworkDifficultyMultiplier()
+	if requested <= network {
+		if _, err := database.GetRedisDB().ResetExcessDifficulty(userID); err != nil {
+			klog.Errorf("Error resetting excess difficulty for %s %v", userID, err)
+		}
+		return requested
+	}
+	streak, err := database.GetRedisDB().IncrementExcessDifficulty(userID, r.now())
+	if err != nil {
+		klog.Errorf("Error counting excess difficulty for %s %v", userID, err)
+		return requested
+	}
+	advisory := adviseDifficulty(requested, network, streak, tenant.ClampExcessDifficulty)
+	if advisory == nil {
+		return requested
+	}
+	graphql.RegisterExtension(ctx, "difficultyAdvisory", advisory)
+	if advisory.Clamped {
+		return network
+	}
+	return requested
+}
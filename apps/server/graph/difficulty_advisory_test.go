@@ -0,0 +1,22 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestAdviseDifficulty(t *testing.T) {
+	// At or below the network difficulty
+	utils.AssertEqual(t, true, adviseDifficulty(1, 1, config.DIFFICULTY_ADVISORY_STREAK, false) == nil)
+	// Not habitual yet
+	utils.AssertEqual(t, true, adviseDifficulty(64, 1, config.DIFFICULTY_ADVISORY_STREAK-1, false) == nil)
+
+	advisory := adviseDifficulty(64, 1, config.DIFFICULTY_ADVISORY_STREAK, false)
+	utils.AssertEqual(t, 64, advisory.RequestedDifficultyMultiplier)
+	utils.AssertEqual(t, 1, advisory.SuggestedDifficultyMultiplier)
+	utils.AssertEqual(t, int64(config.DIFFICULTY_ADVISORY_STREAK), advisory.ExcessRequests)
+	utils.AssertEqual(t, false, advisory.Clamped)
+	utils.AssertEqual(t, true, adviseDifficulty(64, 1, config.DIFFICULTY_ADVISORY_STREAK, true).Clamped)
+}
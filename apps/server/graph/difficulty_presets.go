@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/libs/utils/validation"
+)
+
+// The difficulty multiplier a request asked for directly or with a preset, 0 if it asked for neither
+func requestedDifficultyMultiplier(difficultyMultiplier *int, preset *string, difficulty *string) (int, error) {
+	if preset == nil {
+		if difficulty != nil {
+			return 0, errors.New("bad_request:difficulty is only used with the custom preset")
+		}
+		if difficultyMultiplier == nil {
+			return 0, nil
+		}
+		return *difficultyMultiplier, nil
+	}
+	if difficultyMultiplier != nil {
+		return 0, errors.New("bad_request:give either difficultyMultiplier or preset")
+	}
+	if strings.ToLower(*preset) == validation.CustomPreset {
+		if difficulty == nil {
+			return 0, errors.New("bad_request:the custom preset needs a difficulty")
+		}
+		threshold, err := validation.ParseThreshold(*difficulty)
+		if err != nil {
+			return 0, errors.New("bad_request:difficulty must be 16 hex characters")
+		}
+		return validation.MultiplierForThreshold(threshold), nil
+	}
+	if difficulty != nil {
+		return 0, errors.New("bad_request:difficulty is only used with the custom preset")
+	}
+	found, ok := validation.LookupDifficultyPreset(*preset)
+	if !ok {
+		return 0, fmt.Errorf("bad_request:unknown difficulty preset %s", *preset)
+	}
+	return found.DifficultyMultiplier(), nil
+}
+
+func difficultyPresetsToModel(presets []validation.DifficultyPreset) []*model.DifficultyPreset {
+	ret := make([]*model.DifficultyPreset, len(presets))
+	for i, preset := range presets {
+		ret[i] = &model.DifficultyPreset{
+			Name:                 preset.VersionedName(),
+			Network:              preset.Network,
+			Version:              preset.Version,
+			Threshold:            fmt.Sprintf("%016x", preset.Threshold),
+			DifficultyMultiplier: preset.DifficultyMultiplier(),
+			Current:              preset.Current(),
+		}
+	}
+	return ret
+}
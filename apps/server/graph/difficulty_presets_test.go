@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/bananocoin/boompow/libs/utils/validation"
+)
+
+func TestRequestedDifficultyMultiplier(t *testing.T) {
+	multiplier, preset, custom, threshold := 8, "nano_send", "custom", "fffffff800000001"
+
+	requested, err := requestedDifficultyMultiplier(nil, nil, nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, requested)
+	requested, err = requestedDifficultyMultiplier(&multiplier, nil, nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 8, requested)
+	requested, err = requestedDifficultyMultiplier(nil, &preset, nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 64, requested)
+	requested, err = requestedDifficultyMultiplier(nil, &custom, &threshold)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 65, requested)
+
+	_, err = requestedDifficultyMultiplier(&multiplier, &preset, nil)
+	utils.AssertEqual(t, "bad_request:give either difficultyMultiplier or preset", err.Error())
+	_, err = requestedDifficultyMultiplier(nil, nil, &threshold)
+	utils.AssertEqual(t, "bad_request:difficulty is only used with the custom preset", err.Error())
+	_, err = requestedDifficultyMultiplier(nil, &preset, &threshold)
+	utils.AssertEqual(t, "bad_request:difficulty is only used with the custom preset", err.Error())
+	_, err = requestedDifficultyMultiplier(nil, &custom, nil)
+	utils.AssertEqual(t, "bad_request:the custom preset needs a difficulty", err.Error())
+	unknown := "nano_send_v9"
+	_, err = requestedDifficultyMultiplier(nil, &unknown, nil)
+	utils.AssertEqual(t, "bad_request:unknown difficulty preset nano_send_v9", err.Error())
+}
+
+func TestDifficultyPresetsToModel(t *testing.T) {
+	presets := difficultyPresetsToModel(validation.DifficultyPresets)
+	utils.AssertEqual(t, len(validation.DifficultyPresets), len(presets))
+	utils.AssertEqual(t, "banano_send_v1", presets[0].Name)
+	utils.AssertEqual(t, "fffffe0000000000", presets[0].Threshold)
+	utils.AssertEqual(t, true, presets[0].Current)
+	utils.AssertEqual(t, "nano_send_v1", presets[2].Name)
+	utils.AssertEqual(t, false, presets[2].Current)
+}
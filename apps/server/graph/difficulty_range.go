@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Error code of requests for a difficulty outside of the requester's range
+const DifficultyOutOfRangeCode = "DIFFICULTY_OUT_OF_RANGE"
+
+// A requester asked for a difficulty outside of the range an admin allowed them
+type DifficultyOutOfRangeError struct {
+	Requested int
+	Min       int
+	Max       int
+}
+
+func (e *DifficultyOutOfRangeError) Error() string {
+	return fmt.Sprintf("bad_request:difficultyMultiplier must be between %d and %d for this account", e.Min, e.Max)
+}
+
+// The error carries the code and the range in its extensions, so clients don't have to parse the message
+func checkDifficultyRange(ctx context.Context, user *models.User, tenant *models.Tenant, requested int) error {
+	if !user.HasDifficultyRange() {
+		return nil
+	}
+	min, max := user.DifficultyRange(tenant.GetMaxDifficultyMultiplier())
+	if requested >= min && requested <= max {
+		return nil
+	}
+	gqlErr := gqlerror.WrapPath(graphql.GetPath(ctx), &DifficultyOutOfRangeError{Requested: requested, Min: min, Max: max})
+	gqlErr.Extensions = map[string]interface{}{
+		"code": DifficultyOutOfRangeCode,
+		"min":  min,
+		"max":  max,
+	}
+	return gqlErr
+}
+
+// Nil if the requester can ask for anything the tenant allows
+func difficultyRangeToModel(user *models.User, tenant *models.Tenant) *model.DifficultyRange {
+	if !user.HasDifficultyRange() {
+		return nil
+	}
+	min, max := user.DifficultyRange(tenant.GetMaxDifficultyMultiplier())
+	return &model.DifficultyRange{Min: min, Max: max}
+}
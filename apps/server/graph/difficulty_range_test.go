@@ -0,0 +1,33 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestCheckDifficultyRange(t *testing.T) {
+	tenant := &models.Tenant{MaxDifficultyMultiplier: 64}
+	faucet := &models.User{MaxDifficultyMultiplier: 1}
+	utils.AssertEqual(t, nil, checkDifficultyRange(context.Background(), faucet, tenant, 1))
+	// No range leaves it to the tenant
+	utils.AssertEqual(t, nil, checkDifficultyRange(context.Background(), &models.User{}, tenant, 64))
+
+	err := checkDifficultyRange(context.Background(), faucet, tenant, 8)
+	var rangeErr *DifficultyOutOfRangeError
+	utils.AssertEqual(t, true, errors.As(err, &rangeErr))
+	utils.AssertEqual(t, 8, rangeErr.Requested)
+	var gqlErr *gqlerror.Error
+	utils.AssertEqual(t, true, errors.As(err, &gqlErr))
+	utils.AssertEqual(t, "bad_request:difficultyMultiplier must be between 1 and 1 for this account", gqlErr.Message)
+	utils.AssertEqual(t, map[string]interface{}{"code": DifficultyOutOfRangeCode, "min": 1, "max": 1}, gqlErr.Extensions)
+
+	// Only a minimum keeps the tenant's maximum
+	err = checkDifficultyRange(context.Background(), &models.User{MinDifficultyMultiplier: 8}, tenant, 4)
+	utils.AssertEqual(t, true, errors.As(err, &rangeErr))
+	utils.AssertEqual(t, 64, rangeErr.Max)
+}
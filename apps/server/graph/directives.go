@@ -0,0 +1,38 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+)
+
+// Checks the role a field requires, see @auth in the schema
+var roleCheckers = map[model.Role]func(context.Context) *middleware.UserContextValue{
+	model.RoleUser:           middleware.AuthorizedUser,
+	model.RoleProvider:       middleware.AuthorizedProvider,
+	model.RoleRequester:      middleware.AuthorizedRequester,
+	model.RoleServiceToken:   middleware.AuthorizedServiceToken,
+	model.RoleAdmin:          middleware.AuthorizedAdmin,
+	model.RoleChangePassword: middleware.AuthorizedChangePassword,
+}
+
+// Implements @auth, resolvers behind it can rely on the matching middleware.AuthorizedX returning the user
+func Auth(ctx context.Context, obj interface{}, next graphql.Resolver, requires model.Role) (interface{}, error) {
+	checker, ok := roleCheckers[requires]
+	if !ok || checker(ctx) == nil {
+		return nil, fmt.Errorf("access denied")
+	}
+	return next(ctx)
+}
+
+// Implements @hasPermission, resolvers behind it can rely on middleware.AuthorizedUser returning the user
+func HasPermission(ctx context.Context, obj interface{}, next graphql.Resolver, permission model.Permission) (interface{}, error) {
+	if !middleware.HasPermission(ctx, models.Permission(permission)) {
+		return nil, fmt.Errorf("access denied")
+	}
+	return next(ctx)
+}
@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"golang.org/x/exp/slices"
+)
+
+func TestAuthDirective(t *testing.T) {
+	// Every role needs a check, otherwise its fields are closed to everyone
+	for _, role := range model.AllRole {
+		_, ok := roleCheckers[role]
+		utils.AssertEqual(t, true, ok)
+	}
+
+	called := false
+	next := func(ctx context.Context) (interface{}, error) {
+		called = true
+		return true, nil
+	}
+	for _, role := range model.AllRole {
+		_, err := Auth(context.Background(), nil, next, role)
+		utils.AssertNotEqual(t, nil, err)
+	}
+	_, err := Auth(context.Background(), nil, next, model.Role("UNKNOWN"))
+	utils.AssertNotEqual(t, nil, err)
+	utils.AssertEqual(t, false, called)
+}
+
+func TestHasPermissionDirective(t *testing.T) {
+	// Permissions in the schema are the ones roles grant
+	utils.AssertEqual(t, len(models.AllPermissions), len(model.AllPermission))
+	for _, permission := range model.AllPermission {
+		utils.AssertEqual(t, true, slices.Contains(models.AllPermissions, models.Permission(permission)))
+	}
+
+	called := false
+	next := func(ctx context.Context) (interface{}, error) {
+		called = true
+		return true, nil
+	}
+	for _, permission := range model.AllPermission {
+		_, err := HasPermission(context.Background(), nil, next, permission)
+		utils.AssertNotEqual(t, nil, err)
+	}
+	utils.AssertEqual(t, false, called)
+}
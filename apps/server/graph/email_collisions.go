@@ -0,0 +1,24 @@
+package graph
+
+import (
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+func emailCollisionToModel(collision models.EmailCollision) *model.EmailCollision {
+	emails := make([]string, len(collision.Users))
+	for i, user := range collision.Users {
+		emails[i] = user.Email
+	}
+	ret := &model.EmailCollision{
+		NormalizedEmail: collision.NormalizedEmail,
+		Emails:          emails,
+		FlaggedAt:       utils.GenerateISOString(collision.FlaggedAt),
+	}
+	if collision.ReviewedAt != nil {
+		reviewedAt := utils.GenerateISOString(*collision.ReviewedAt)
+		ret.ReviewedAt = &reviewedAt
+	}
+	return ret
+}
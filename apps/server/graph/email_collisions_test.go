@@ -0,0 +1,26 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestEmailCollisionToModel(t *testing.T) {
+	flaggedAt := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	collision := emailCollisionToModel(models.EmailCollision{
+		NormalizedEmail: "helloworld@gmail.com",
+		FlaggedAt:       flaggedAt,
+		Users:           []models.User{{Email: "helloworld@gmail.com"}, {Email: "hello.world@gmail.com"}},
+	})
+	utils.AssertEqual(t, "helloworld@gmail.com", collision.NormalizedEmail)
+	utils.AssertEqual(t, []string{"helloworld@gmail.com", "hello.world@gmail.com"}, collision.Emails)
+	utils.AssertEqual(t, (*string)(nil), collision.ReviewedAt)
+
+	reviewedAt := flaggedAt.Add(time.Hour)
+	collision = emailCollisionToModel(models.EmailCollision{NormalizedEmail: "helloworld@gmail.com", FlaggedAt: flaggedAt, ReviewedAt: &reviewedAt})
+	utils.AssertEqual(t, 0, len(collision.Emails))
+	utils.AssertEqual(t, "2022-08-01T13:00:00Z", *collision.ReviewedAt)
+}
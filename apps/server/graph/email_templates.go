@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/email"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	env "github.com/bananocoin/boompow/libs/utils"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+// e.g. en, pt-br
+var emailLanguagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// The language emails are sent in if it's not set
+func emailTemplateLanguage(language *string) (string, error) {
+	if language == nil {
+		return env.GetEmailLanguage(), nil
+	}
+	normalized := strings.ToLower(strings.TrimSpace(*language))
+	if !emailLanguagePattern.MatchString(normalized) {
+		return "", errors.New("bad_request:invalid language")
+	}
+	return normalized, nil
+}
+
+func emailTemplateToModel(template *models.EmailTemplate) *model.EmailTemplate {
+	createdAt := utils.GenerateISOString(template.CreatedAt)
+	return &model.EmailTemplate{
+		Name:      template.Name,
+		Language:  template.Language,
+		Version:   template.Version,
+		Subject:   template.Subject,
+		Body:      template.Body,
+		CreatedAt: &createdAt,
+	}
+}
+
+// The built-in emails are only in the default language
+func builtinEmailTemplates() ([]*model.EmailTemplate, error) {
+	var ret []*model.EmailTemplate
+	for _, name := range email.TemplateNames() {
+		subject, body, err := email.BuiltinTemplate(name)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &model.EmailTemplate{
+			Name:     name,
+			Language: models.DefaultEmailLanguage,
+			Subject:  subject,
+			Body:     body,
+		})
+	}
+	return ret, nil
+}
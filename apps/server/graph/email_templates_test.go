@@ -0,0 +1,31 @@
+package graph
+
+import (
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestEmailTemplateLanguage(t *testing.T) {
+	language, err := emailTemplateLanguage(nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "en", language)
+
+	input := " PT-BR"
+	language, err = emailTemplateLanguage(&input)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "pt-br", language)
+
+	input = "../en"
+	_, err = emailTemplateLanguage(&input)
+	utils.AssertNotEqual(t, nil, err)
+}
+
+func TestBuiltinEmailTemplates(t *testing.T) {
+	templates, err := builtinEmailTemplates()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 8, len(templates))
+	utils.AssertEqual(t, "confirmemail", templates[0].Name)
+	utils.AssertEqual(t, 0, templates[0].Version)
+	utils.AssertEqual(t, (*string)(nil), templates[0].CreatedAt)
+}
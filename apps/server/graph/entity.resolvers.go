@@ -0,0 +1,45 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bananocoin/boompow/apps/server/graph/generated"
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+	"github.com/google/uuid"
+)
+
+// FindUserByID is the resolver for the findUserByID field.
+func (r *entityResolver) FindUserByID(ctx context.Context, id string) (*model.User, error) {
+	// Users' emails are private, the gateway forwards the caller's Authorization header
+	caller := middleware.AuthorizedUser(ctx)
+	if caller == nil || (caller.User.ID.String() != id && middleware.AuthorizedAdmin(ctx) == nil) {
+		return nil, fmt.Errorf("access denied")
+	}
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	user, err := r.UserRepo.GetUser(&userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &model.User{
+		ID:         user.ID.String(),
+		Email:      user.Email,
+		CreatedAt:  utils.GenerateISOString(user.CreatedAt),
+		UpdatedAt:  utils.GenerateISOString(user.UpdatedAt),
+		Type:       model.UserType(user.Type),
+		BanAddress: user.BanAddress,
+	}, nil
+}
+
+// Entity returns generated.EntityResolver implementation.
+func (r *Resolver) Entity() generated.EntityResolver { return &entityResolver{r} }
+
+type entityResolver struct{ *Resolver }
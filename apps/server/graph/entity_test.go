@@ -0,0 +1,16 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+func TestFindUserByIDRequiresAuth(t *testing.T) {
+	// Never reaches the repo without a caller
+	user, err := (&Resolver{}).Entity().FindUserByID(context.Background(), uuid.NewString())
+	utils.AssertNotEqual(t, nil, err)
+	utils.AssertEqual(t, true, user == nil)
+}
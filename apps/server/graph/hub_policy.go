@@ -0,0 +1,49 @@
+package graph
+
+import (
+	"sort"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/controller"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+func hubPolicyToModel(policy *models.HubPolicy) *model.HubPolicy {
+	ret := &model.HubPolicy{
+		TimeoutSeconds:            policy.TimeoutSeconds,
+		Retries:                   policy.Retries,
+		MaxInFlightPerWorker:      policy.MaxInFlightPerWorker,
+		OnDemandWeight:            policy.OnDemandWeight,
+		PrecacheWeight:            policy.PrecacheWeight,
+		ExclusionSharePercent:     policy.ExclusionSharePercent,
+		ExclusionMinClients:       policy.ExclusionMinClients,
+		IdlePrecacheSeconds:       policy.IdlePrecacheSeconds,
+		IdlePrecacheCreditPercent: policy.IdlePrecacheCreditPercent,
+		LateResultGraceSeconds:    policy.LateResultGraceSeconds,
+		ProgressExtensionSeconds:  policy.ProgressExtensionSeconds,
+		TargetedDifficulty:        policy.TargetedDifficulty,
+		SolveSLASeconds:           policy.SolveSLASeconds,
+	}
+	// The default policy was never saved
+	if !policy.UpdatedAt.IsZero() {
+		updatedAt := utils.GenerateISOString(policy.UpdatedAt)
+		ret.UpdatedAt = &updatedAt
+	}
+	return ret
+}
+
+func workerSolveTimesToModel(worker controller.WorkerSolveTimes) *model.WorkerSolveTimes {
+	ret := &model.WorkerSolveTimes{
+		IPAddress:    worker.IPAddress,
+		Email:        worker.Email,
+		Difficulties: make([]*model.SolveTime, 0, len(worker.Difficulties)),
+	}
+	for difficulty, stats := range worker.Difficulties {
+		ret.Difficulties = append(ret.Difficulties, &model.SolveTime{DifficultyMultiplier: difficulty, Solves: stats.Solves, AverageMs: int(stats.Average.Milliseconds())})
+	}
+	sort.Slice(ret.Difficulties, func(i, j int) bool {
+		return ret.Difficulties[i].DifficultyMultiplier < ret.Difficulties[j].DifficultyMultiplier
+	})
+	return ret
+}
@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
+)
+
+// Run generate once per Idempotency-Key, retries with the same key get the original result
+// The fingerprint identifies the request, so a key can't be reused for a different one
+func withIdempotency(ctx context.Context, userID uuid.UUID, fingerprint string, generate func() (string, error)) (string, error) {
+	key := middleware.IdempotencyKey(ctx)
+	if key == "" {
+		return generate()
+	}
+
+	reserved, err := database.GetRedisDB().ReserveIdempotencyKey(userID, key, fingerprint)
+	if err != nil {
+		return "", err
+	}
+	if !reserved {
+		originalFingerprint, result, err := database.GetRedisDB().GetIdempotentResult(userID, key)
+		if err != nil {
+			return "", err
+		}
+		if originalFingerprint != fingerprint {
+			return "", errors.New("bad_request:idempotency key was already used for a different request")
+		}
+		if result == "" {
+			return "", errors.New("conflict:a request with this idempotency key is still in progress")
+		}
+		return result, nil
+	}
+
+	result, err := generate()
+	if err != nil {
+		// Failed requests don't count, the client should be able to retry them
+		if _, delErr := database.GetRedisDB().ReleaseIdempotencyKey(userID, key); delErr != nil {
+			klog.Errorf("Error releasing idempotency key %s: %v", key, delErr)
+		}
+		return "", err
+	}
+	if err := database.GetRedisDB().SetIdempotentResult(userID, key, fingerprint, result); err != nil {
+		klog.Errorf("Error storing idempotent result for key %s: %v", key, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,43 @@
+package graph
+
+import (
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+func incidentToModel(incident *models.Incident) *model.Incident {
+	ret := &model.Incident{
+		ID:          incident.ID.String(),
+		Title:       incident.Title,
+		Description: incident.Description,
+		Severity:    model.IncidentSeverity(incident.Severity),
+		Automatic:   incident.DetectorKey != "",
+		CreatedAt:   utils.GenerateISOString(incident.CreatedAt),
+	}
+	if incident.ResolvedAt != nil {
+		resolvedAt := utils.GenerateISOString(*incident.ResolvedAt)
+		ret.ResolvedAt = &resolvedAt
+	}
+	return ret
+}
+
+func incidentsToModel(incidents []models.Incident) []*model.Incident {
+	ret := make([]*model.Incident, len(incidents))
+	for i := range incidents {
+		ret[i] = incidentToModel(&incidents[i])
+	}
+	return ret
+}
+
+// The worst open incident decides the status
+func poolStatus(open []models.Incident) model.PoolStatus {
+	status := model.PoolStatusOperational
+	for _, incident := range open {
+		if incident.Severity == models.IncidentOutage {
+			return model.PoolStatusOutage
+		}
+		status = model.PoolStatusDegraded
+	}
+	return status
+}
@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"math"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/privacy"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+// Defaults to and is capped at the size of the stored leaderboard
+func leaderboardLimit(limit *int) int {
+	if limit == nil || *limit < 1 || *limit > config.LEADERBOARD_SIZE {
+		return config.LEADERBOARD_SIZE
+	}
+	return *limit
+}
+
+// Ranks are exact, the numbers are blurred like the other public stats
+func leaderboardToModel(window model.LeaderboardWindow, entries []models.LeaderboardEntry, blurrer privacy.Blurrer, now time.Time) *model.Leaderboard {
+	ret := &model.Leaderboard{
+		Window:  window,
+		Entries: make([]*model.LeaderboardEntry, len(entries)),
+	}
+	if len(entries) > 0 {
+		refreshedAt := utils.GenerateISOString(entries[0].RefreshedAt)
+		ret.RefreshedAt = &refreshedAt
+	}
+	for i, entry := range entries {
+		key := entry.BanAddress + ":" + string(window)
+		ret.Entries[i] = &model.LeaderboardEntry{
+			Rank:          entry.Rank,
+			BanAddress:    entry.BanAddress,
+			WorkUnits:     int(math.Round(blurrer.Blur(key+":units", float64(entry.WorkUnits), now))),
+			DifficultySum: int(math.Round(blurrer.Blur(key+":difficulty", float64(entry.DifficultySum), now))),
+		}
+	}
+	return ret
+}
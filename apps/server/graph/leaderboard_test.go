@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/privacy"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestLeaderboardToModel(t *testing.T) {
+	now := time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)
+	empty := leaderboardToModel(model.LeaderboardWindowDay, nil, privacy.NewBlurrer(0), now)
+	utils.AssertEqual(t, true, empty.RefreshedAt == nil)
+	utils.AssertEqual(t, 0, len(empty.Entries))
+
+	entries := []models.LeaderboardEntry{
+		{Rank: 1, BanAddress: "ban_1", WorkUnits: 1234, DifficultySum: 5678, RefreshedAt: now},
+		{Rank: 2, BanAddress: "ban_2", WorkUnits: 10, DifficultySum: 640, RefreshedAt: now},
+	}
+	leaderboard := leaderboardToModel(model.LeaderboardWindowWeek, entries, privacy.NewBlurrer(0), now)
+	utils.AssertEqual(t, "2022-09-01T12:00:00Z", *leaderboard.RefreshedAt)
+	utils.AssertEqual(t, model.LeaderboardEntry{Rank: 1, BanAddress: "ban_1", WorkUnits: 1234, DifficultySum: 5678}, *leaderboard.Entries[0])
+
+	// Blurred to two significant digits, the ranks stay
+	blurred := leaderboardToModel(model.LeaderboardWindowWeek, entries, privacy.NewBlurrer(10), now)
+	utils.AssertEqual(t, 1, blurred.Entries[0].Rank)
+	utils.AssertEqual(t, 0, blurred.Entries[0].WorkUnits%10)
+
+	limit := 500
+	utils.AssertEqual(t, 100, leaderboardLimit(nil))
+	utils.AssertEqual(t, 100, leaderboardLimit(&limit))
+	limit = 10
+	utils.AssertEqual(t, 10, leaderboardLimit(&limit))
+}
+
+func TestLeaderboardWindowsMatch(t *testing.T) {
+	utils.AssertEqual(t, len(model.AllLeaderboardWindow), len(models.LeaderboardWindows))
+	for i, window := range model.AllLeaderboardWindow {
+		utils.AssertEqual(t, string(window), string(models.LeaderboardWindows[i]))
+	}
+	now := time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)
+	utils.AssertEqual(t, now.Add(-7*24*time.Hour), *models.LeaderboardWeek.Since(now))
+	utils.AssertEqual(t, true, models.LeaderboardAll.Since(now) == nil)
+}
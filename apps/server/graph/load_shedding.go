@@ -0,0 +1,75 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/controller"
+	"github.com/bananocoin/boompow/apps/server/src/shedding"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Error code of work requests that were shed, they can be sent again after retryAfterSeconds
+const RetryLaterCode = "RETRY_LATER"
+
+func loadSheddingFromInput(input model.LoadSheddingInput, startedBy string, now time.Time) (shedding.Config, error) {
+	if len(input.Tiers) == 0 && len(input.DifficultyClasses) == 0 {
+		return shedding.Config{}, errors.New("bad_request:tiers or difficultyClasses are required")
+	}
+	if input.Minutes < 1 || input.Minutes > config.LOAD_SHEDDING_MAX_MINUTES {
+		return shedding.Config{}, fmt.Errorf("bad_request:minutes must be between 1 and %d", config.LOAD_SHEDDING_MAX_MINUTES)
+	}
+	sheddingConfig := shedding.Config{
+		Tiers:             make([]shedding.Tier, len(input.Tiers)),
+		DifficultyClasses: make([]shedding.DifficultyClass, len(input.DifficultyClasses)),
+		StartedBy:         startedBy,
+		Until:             now.Add(time.Duration(input.Minutes) * time.Minute),
+	}
+	for i, tier := range input.Tiers {
+		sheddingConfig.Tiers[i] = shedding.Tier(strings.ToLower(tier.String()))
+	}
+	for i, class := range input.DifficultyClasses {
+		sheddingConfig.DifficultyClasses[i] = shedding.DifficultyClass(strings.ToLower(class.String()))
+	}
+	if input.Reason != nil {
+		sheddingConfig.Reason = strings.TrimSpace(*input.Reason)
+	}
+	return sheddingConfig, nil
+}
+
+func loadSheddingToModel(sheddingConfig *shedding.Config) *model.LoadShedding {
+	ret := &model.LoadShedding{
+		Tiers:             make([]model.RequesterTier, len(sheddingConfig.Tiers)),
+		DifficultyClasses: make([]model.DifficultyClass, len(sheddingConfig.DifficultyClasses)),
+		StartedBy:         sheddingConfig.StartedBy,
+		Until:             utils.GenerateISOString(sheddingConfig.Until),
+	}
+	for i, tier := range sheddingConfig.Tiers {
+		ret.Tiers[i] = model.RequesterTier(strings.ToUpper(string(tier)))
+	}
+	for i, class := range sheddingConfig.DifficultyClasses {
+		ret.DifficultyClasses[i] = model.DifficultyClass(strings.ToUpper(string(class)))
+	}
+	if sheddingConfig.Reason != "" {
+		ret.Reason = &sheddingConfig.Reason
+	}
+	return ret
+}
+
+// The error carries the code and when shedding stops in its extensions, so clients know when to try again
+func retryLaterError(ctx context.Context, err *controller.RetryLaterError, now time.Time) error {
+	gqlErr := gqlerror.WrapPath(graphql.GetPath(ctx), err)
+	gqlErr.Extensions = map[string]interface{}{
+		"code":              RetryLaterCode,
+		"retryAfterSeconds": int(math.Ceil(err.Until.Sub(now).Seconds())),
+	}
+	return gqlErr
+}
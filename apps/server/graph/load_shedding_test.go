@@ -0,0 +1,43 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/controller"
+	"github.com/bananocoin/boompow/apps/server/src/shedding"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestLoadSheddingInput(t *testing.T) {
+	now := time.Unix(1664611200, 0)
+	_, err := loadSheddingFromInput(model.LoadSheddingInput{Minutes: 10}, "admin@example.com", now)
+	utils.AssertEqual(t, "bad_request:tiers or difficultyClasses are required", err.Error())
+	_, err = loadSheddingFromInput(model.LoadSheddingInput{Tiers: []model.RequesterTier{model.RequesterTierStandard}, Minutes: 241}, "admin@example.com", now)
+	utils.AssertEqual(t, "bad_request:minutes must be between 1 and 240", err.Error())
+
+	reason := " node outage "
+	input := model.LoadSheddingInput{Tiers: []model.RequesterTier{model.RequesterTierStandard}, DifficultyClasses: []model.DifficultyClass{model.DifficultyClassPrecache}, Reason: &reason, Minutes: 30}
+	sheddingConfig, err := loadSheddingFromInput(input, "admin@example.com", now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, shedding.Config{Tiers: []shedding.Tier{shedding.TierStandard}, DifficultyClasses: []shedding.DifficultyClass{shedding.ClassPrecache}, Reason: "node outage", StartedBy: "admin@example.com", Until: now.Add(30 * time.Minute)}, sheddingConfig)
+
+	ret := loadSheddingToModel(&sheddingConfig)
+	utils.AssertEqual(t, input.Tiers, ret.Tiers)
+	utils.AssertEqual(t, input.DifficultyClasses, ret.DifficultyClasses)
+	utils.AssertEqual(t, "node outage", *ret.Reason)
+}
+
+func TestRetryLaterError(t *testing.T) {
+	now := time.Unix(1664611200, 0)
+	err := retryLaterError(context.Background(), &controller.RetryLaterError{Until: now.Add(90*time.Second + time.Millisecond)}, now)
+	var gqlErr *gqlerror.Error
+	utils.AssertEqual(t, true, errors.As(err, &gqlErr))
+	utils.AssertEqual(t, map[string]interface{}{"code": RetryLaterCode, "retryAfterSeconds": 91}, gqlErr.Extensions)
+	var retryLater *controller.RetryLaterError
+	utils.AssertEqual(t, true, errors.As(err, &retryLater))
+}
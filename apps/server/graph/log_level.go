@@ -0,0 +1,15 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+)
+
+func subsystemLogLevelToModel(subsystem logging.Subsystem) *model.SubsystemLogLevel {
+	return &model.SubsystemLogLevel{
+		Subsystem: model.LogSubsystem(strings.ToUpper(string(subsystem))),
+		Level:     model.LogLevel(strings.ToUpper(logging.GetLevel(subsystem).String())),
+	}
+}
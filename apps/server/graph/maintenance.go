@@ -0,0 +1,19 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+func maintenanceWindowToModel(window *models.MaintenanceWindow, now time.Time) *model.MaintenanceWindow {
+	return &model.MaintenanceWindow{
+		ID:          window.ID.String(),
+		Description: window.Description,
+		StartsAt:    utils.GenerateISOString(window.StartsAt),
+		EndsAt:      utils.GenerateISOString(window.EndsAt),
+		Active:      window.ActiveAt(now),
+	}
+}
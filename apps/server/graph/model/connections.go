@@ -0,0 +1,51 @@
+package model
+
+// Count counts every item of the list, it's only called when totalCount is requested
+
+type ActivityConnection struct {
+	Nodes    []*ActivityEvent    `json:"nodes"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+	Count    func() (int, error) `json:"-"`
+}
+
+type PayoutAddressHistoryConnection struct {
+	Nodes    []*PayoutAddressHistory `json:"nodes"`
+	PageInfo *PageInfo               `json:"pageInfo"`
+	Count    func() (int, error)     `json:"-"`
+}
+
+type RequestSampleConnection struct {
+	Nodes    []*RequestSample    `json:"nodes"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+	Count    func() (int, error) `json:"-"`
+}
+
+type PastPayoutCycleConnection struct {
+	Nodes    []*PastPayoutCycle  `json:"nodes"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+	Count    func() (int, error) `json:"-"`
+}
+
+type StaleAccountReportConnection struct {
+	Nodes    []*StaleAccountReport `json:"nodes"`
+	PageInfo *PageInfo             `json:"pageInfo"`
+	Count    func() (int, error)   `json:"-"`
+}
+
+type AdminUserConnection struct {
+	Nodes    []*AdminUser        `json:"nodes"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+	Count    func() (int, error) `json:"-"`
+}
+
+type WorkResultConnection struct {
+	Nodes    []*WorkResult       `json:"nodes"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+	Count    func() (int, error) `json:"-"`
+}
+
+type AuditLogConnection struct {
+	Nodes    []*AuditLogEntry    `json:"nodes"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+	Count    func() (int, error) `json:"-"`
+}
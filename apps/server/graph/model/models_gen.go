@@ -8,27 +8,336 @@ import (
 	"strconv"
 )
 
+type ActivityEvent struct {
+	Type         string  `json:"type"`
+	Detail       string  `json:"detail"`
+	ClientIP     *string `json:"clientIp"`
+	BlockHash    *string `json:"blockHash"`
+	AmountBanano *string `json:"amountBanano"`
+	CreatedAt    string  `json:"createdAt"`
+}
+
+type AdminMetrics struct {
+	WindowMinutes         int                    `json:"windowMinutes"`
+	WorkRequests          int                    `json:"workRequests"`
+	WorkRequestsPerMinute float64                `json:"workRequestsPerMinute"`
+	CachedWorkRequests    int                    `json:"cachedWorkRequests"`
+	FailedWorkRequests    int                    `json:"failedWorkRequests"`
+	ErrorRate             float64                `json:"errorRate"`
+	Timeouts              int                    `json:"timeouts"`
+	Rejections            int                    `json:"rejections"`
+	OpenWorkRequests      int                    `json:"openWorkRequests"`
+	BroadcastQueue        []*BroadcastQueueDepth `json:"broadcastQueue"`
+	StatsQueue            int                    `json:"statsQueue"`
+	ServerWorkers         int                    `json:"serverWorkers"`
+	ConnectedWorkers      int                    `json:"connectedWorkers"`
+	QuarantinedWorkers    int                    `json:"quarantinedWorkers"`
+	LastPayoutCycle       *PastPayoutCycle       `json:"lastPayoutCycle"`
+	LastPayoutComplete    *bool                  `json:"lastPayoutComplete"`
+	NextPayoutAt          string                 `json:"nextPayoutAt"`
+	RedisMemory           *RedisMemory           `json:"redisMemory"`
+}
+
+type AdminUser struct {
+	ID                  string        `json:"id"`
+	Email               string        `json:"email"`
+	Type                UserType      `json:"type"`
+	TenantID            string        `json:"tenantId"`
+	EmailVerified       bool          `json:"emailVerified"`
+	CanRequestWork      bool          `json:"canRequestWork"`
+	TwoFactorEnabled    bool          `json:"twoFactorEnabled"`
+	StaleExempt         bool          `json:"staleExempt"`
+	InvalidResultCount  int           `json:"invalidResultCount"`
+	ServiceName         *string       `json:"serviceName"`
+	Roles               []AccountRole `json:"roles"`
+	CreatedAt           string        `json:"createdAt"`
+	UpdatedAt           string        `json:"updatedAt"`
+	LastProvidedWorkAt  *string       `json:"lastProvidedWorkAt"`
+	LastRequestedWorkAt *string       `json:"lastRequestedWorkAt"`
+	DisabledAt          *string       `json:"disabledAt"`
+}
+
+type APIKey struct {
+	ID                 string  `json:"id"`
+	Name               string  `json:"name"`
+	Hint               string  `json:"hint"`
+	RateLimitPerMinute *int    `json:"rateLimitPerMinute"`
+	CreatedAt          string  `json:"createdAt"`
+	LastUsedAt         *string `json:"lastUsedAt"`
+}
+
+type AuditLogEntry struct {
+	ID         string  `json:"id"`
+	Action     string  `json:"action"`
+	ActorID    *string `json:"actorId"`
+	ActorEmail *string `json:"actorEmail"`
+	Target     *string `json:"target"`
+	Detail     *string `json:"detail"`
+	ClientIP   *string `json:"clientIp"`
+	Success    bool    `json:"success"`
+	CreatedAt  string  `json:"createdAt"`
+}
+
+type AuditLogFilter struct {
+	Field  AuditLogListField `json:"field"`
+	Op     FilterOperator    `json:"op"`
+	Values []string          `json:"values"`
+}
+
+type AuditLogSort struct {
+	Field      AuditLogListField `json:"field"`
+	Descending bool              `json:"descending"`
+}
+
+type AwardRate struct {
+	ID            string  `json:"id"`
+	TenantID      string  `json:"tenantId"`
+	BananoPerUnit float64 `json:"bananoPerUnit"`
+	RawPerUnit    string  `json:"rawPerUnit"`
+	EffectiveAt   string  `json:"effectiveAt"`
+	CreatedAt     string  `json:"createdAt"`
+}
+
+type BenchmarkInput struct {
+	Hardware             string           `json:"hardware"`
+	Backend              BenchmarkBackend `json:"backend"`
+	DifficultyMultiplier int              `json:"difficultyMultiplier"`
+	Runs                 int              `json:"runs"`
+	AverageSeconds       float64          `json:"averageSeconds"`
+	ClientVersion        *string          `json:"clientVersion"`
+}
+
+type BoostEconomics struct {
+	Boosts        int     `json:"boosts"`
+	Minutes       int     `json:"minutes"`
+	Buyers        int     `json:"buyers"`
+	RevenueBanano float64 `json:"revenueBanano"`
+	RevenueRaw    string  `json:"revenueRaw"`
+}
+
+type BoostPricing struct {
+	PricePerMinuteBanano float64 `json:"pricePerMinuteBanano"`
+	PricePerMinuteRaw    string  `json:"pricePerMinuteRaw"`
+	DailyCapMinutes      int     `json:"dailyCapMinutes"`
+}
+
+type BroadcastQueueDepth struct {
+	Priority string `json:"priority"`
+	Depth    int    `json:"depth"`
+}
+
 type ChangePasswordInput struct {
 	NewPassword string `json:"newPassword"`
 }
 
+type ConnectedWorkersPoint struct {
+	TakenAt          string `json:"takenAt"`
+	ConnectedWorkers int    `json:"connectedWorkers"`
+}
+
+type CountryStats struct {
+	Continent        string `json:"continent"`
+	Country          string `json:"country"`
+	ConnectedWorkers int    `json:"connectedWorkers"`
+	Requests         int    `json:"requests"`
+}
+
+type CreditAccount struct {
+	BalanceBanano         float64        `json:"balanceBanano"`
+	BalanceRaw            string         `json:"balanceRaw"`
+	Pricing               *BoostPricing  `json:"pricing"`
+	BoostMinutesLeftToday int            `json:"boostMinutesLeftToday"`
+	BoostedUntil          *string        `json:"boostedUntil"`
+	History               []*CreditEntry `json:"history"`
+}
+
+type CreditEntry struct {
+	Kind         string  `json:"kind"`
+	AmountBanano float64 `json:"amountBanano"`
+	AmountRaw    string  `json:"amountRaw"`
+	Reference    string  `json:"reference"`
+	CreatedAt    string  `json:"createdAt"`
+}
+
+type DeclareIncidentInput struct {
+	Title       string           `json:"title"`
+	Description *string          `json:"description"`
+	Severity    IncidentSeverity `json:"severity"`
+}
+
+type DifficultyBucket struct {
+	DifficultyMultiplier int `json:"difficultyMultiplier"`
+	Count                int `json:"count"`
+}
+
+type DifficultyPreset struct {
+	Name                 string `json:"name"`
+	Network              string `json:"network"`
+	Version              int    `json:"version"`
+	Threshold            string `json:"threshold"`
+	DifficultyMultiplier int    `json:"difficultyMultiplier"`
+	Current              bool   `json:"current"`
+}
+
+type DifficultyRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+type EmailCollision struct {
+	NormalizedEmail string   `json:"normalizedEmail"`
+	Emails          []string `json:"emails"`
+	FlaggedAt       string   `json:"flaggedAt"`
+	ReviewedAt      *string  `json:"reviewedAt"`
+}
+
+type EmailPreview struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+}
+
+type EmailTemplate struct {
+	Name      string  `json:"name"`
+	Language  string  `json:"language"`
+	Version   int     `json:"version"`
+	Subject   string  `json:"subject"`
+	Body      string  `json:"body"`
+	CreatedAt *string `json:"createdAt"`
+}
+
+type EmailTemplateInput struct {
+	Name     string  `json:"name"`
+	Language *string `json:"language"`
+	Subject  string  `json:"subject"`
+	Body     string  `json:"body"`
+}
+
+type GenerateAPIKeyInput struct {
+	Name               string `json:"name"`
+	RateLimitPerMinute *int   `json:"rateLimitPerMinute"`
+}
+
+type GeneratedAPIKey struct {
+	Key    string  `json:"key"`
+	APIKey *APIKey `json:"apiKey"`
+}
+
 type GetUserResponse struct {
-	Email          string   `json:"email"`
-	Type           UserType `json:"type"`
-	BanAddress     *string  `json:"banAddress"`
-	ServiceName    *string  `json:"serviceName"`
-	ServiceWebsite *string  `json:"serviceWebsite"`
-	EmailVerified  bool     `json:"emailVerified"`
-	CanRequestWork bool     `json:"canRequestWork"`
+	Email                 string           `json:"email"`
+	Type                  UserType         `json:"type"`
+	BanAddress            *string          `json:"banAddress"`
+	ServiceName           *string          `json:"serviceName"`
+	ServiceWebsite        *string          `json:"serviceWebsite"`
+	EmailVerified         bool             `json:"emailVerified"`
+	CanRequestWork        bool             `json:"canRequestWork"`
+	IncludeWorkTimings    bool             `json:"includeWorkTimings"`
+	PayoutStatementEmails bool             `json:"payoutStatementEmails"`
+	TwoFactorEnabled      bool             `json:"twoFactorEnabled"`
+	UnpaidWork            *int             `json:"unpaidWork"`
+	PayoutAddresses       []*PayoutAddress `json:"payoutAddresses"`
+	DifficultyRange       *DifficultyRange `json:"difficultyRange"`
+}
+
+type HardwareBenchmark struct {
+	Hardware      string           `json:"hardware"`
+	Backend       BenchmarkBackend `json:"backend"`
+	Profiles      int              `json:"profiles"`
+	Providers     int              `json:"providers"`
+	WorkPerSecond float64          `json:"workPerSecond"`
+}
+
+type HubEvent struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	RequestID   string `json:"requestId"`
+	Hash        string `json:"hash"`
+	ClientIP    string `json:"clientIp"`
+	ClientEmail string `json:"clientEmail"`
+	TenantID    string `json:"tenantId"`
+	Detail      string `json:"detail"`
+	Timestamp   string `json:"timestamp"`
+}
+
+type HubPolicy struct {
+	TimeoutSeconds            int     `json:"timeoutSeconds"`
+	Retries                   int     `json:"retries"`
+	MaxInFlightPerWorker      int     `json:"maxInFlightPerWorker"`
+	OnDemandWeight            int     `json:"onDemandWeight"`
+	PrecacheWeight            int     `json:"precacheWeight"`
+	ExclusionSharePercent     int     `json:"exclusionSharePercent"`
+	ExclusionMinClients       int     `json:"exclusionMinClients"`
+	IdlePrecacheSeconds       int     `json:"idlePrecacheSeconds"`
+	IdlePrecacheCreditPercent int     `json:"idlePrecacheCreditPercent"`
+	LateResultGraceSeconds    int     `json:"lateResultGraceSeconds"`
+	ProgressExtensionSeconds  int     `json:"progressExtensionSeconds"`
+	TargetedDifficulty        int     `json:"targetedDifficulty"`
+	SolveSLASeconds           int     `json:"solveSlaSeconds"`
+	UpdatedAt                 *string `json:"updatedAt"`
+}
+
+type HubPolicyInput struct {
+	TimeoutSeconds            int  `json:"timeoutSeconds"`
+	Retries                   int  `json:"retries"`
+	MaxInFlightPerWorker      int  `json:"maxInFlightPerWorker"`
+	OnDemandWeight            int  `json:"onDemandWeight"`
+	PrecacheWeight            int  `json:"precacheWeight"`
+	ExclusionSharePercent     int  `json:"exclusionSharePercent"`
+	ExclusionMinClients       int  `json:"exclusionMinClients"`
+	IdlePrecacheSeconds       *int `json:"idlePrecacheSeconds"`
+	IdlePrecacheCreditPercent *int `json:"idlePrecacheCreditPercent"`
+	LateResultGraceSeconds    *int `json:"lateResultGraceSeconds"`
+	ProgressExtensionSeconds  *int `json:"progressExtensionSeconds"`
+	TargetedDifficulty        *int `json:"targetedDifficulty"`
+	SolveSLASeconds           *int `json:"solveSlaSeconds"`
+}
+
+type Incident struct {
+	ID          string           `json:"id"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Severity    IncidentSeverity `json:"severity"`
+	Automatic   bool             `json:"automatic"`
+	CreatedAt   string           `json:"createdAt"`
+	ResolvedAt  *string          `json:"resolvedAt"`
+}
+
+type Leaderboard struct {
+	Window      LeaderboardWindow   `json:"window"`
+	RefreshedAt *string             `json:"refreshedAt"`
+	Entries     []*LeaderboardEntry `json:"entries"`
+}
+
+type LeaderboardEntry struct {
+	Rank          int    `json:"rank"`
+	BanAddress    string `json:"banAddress"`
+	WorkUnits     int    `json:"workUnits"`
+	DifficultySum int    `json:"difficultySum"`
+}
+
+type LoadShedding struct {
+	Tiers             []RequesterTier   `json:"tiers"`
+	DifficultyClasses []DifficultyClass `json:"difficultyClasses"`
+	Reason            *string           `json:"reason"`
+	StartedBy         string            `json:"startedBy"`
+	Until             string            `json:"until"`
+}
+
+type LoadSheddingInput struct {
+	Tiers             []RequesterTier   `json:"tiers"`
+	DifficultyClasses []DifficultyClass `json:"difficultyClasses"`
+	Reason            *string           `json:"reason"`
+	Minutes           int               `json:"minutes"`
 }
 
 type LoginInput struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email         string  `json:"email"`
+	Password      string  `json:"password"`
+	TwoFactorCode *string `json:"twoFactorCode"`
 }
 
 type LoginResponse struct {
 	Token          string   `json:"token"`
+	RefreshToken   string   `json:"refreshToken"`
 	Email          string   `json:"email"`
 	Type           UserType `json:"type"`
 	BanAddress     *string  `json:"banAddress"`
@@ -37,8 +346,213 @@ type LoginResponse struct {
 	EmailVerified  bool     `json:"emailVerified"`
 }
 
+type MaintenanceWindow struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	StartsAt    string `json:"startsAt"`
+	EndsAt      string `json:"endsAt"`
+	Active      bool   `json:"active"`
+}
+
+type MaintenanceWindowInput struct {
+	Description *string `json:"description"`
+	StartsAt    string  `json:"startsAt"`
+	EndsAt      string  `json:"endsAt"`
+}
+
+type OfflineAlert struct {
+	Channel      AlertChannel `json:"channel"`
+	Target       *string      `json:"target"`
+	AfterMinutes int          `json:"afterMinutes"`
+}
+
+type OfflineAlertInput struct {
+	Channel      AlertChannel `json:"channel"`
+	Target       *string      `json:"target"`
+	AfterMinutes int          `json:"afterMinutes"`
+}
+
+type PageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor"`
+}
+
+type PastPayoutCycle struct {
+	ID            string `json:"id"`
+	CreatedAt     string `json:"createdAt"`
+	PrizePool     int    `json:"prizePool"`
+	ProviderCount int    `json:"providerCount"`
+}
+
+type PayoutAddress struct {
+	BanAddress string `json:"banAddress"`
+	Percent    int    `json:"percent"`
+}
+
+type PayoutAddressHistory struct {
+	BanAddress      string `json:"banAddress"`
+	TotalPaidBanano string `json:"totalPaidBanano"`
+	PaymentCount    int    `json:"paymentCount"`
+	LastPaidAt      string `json:"lastPaidAt"`
+}
+
+type PayoutAddressInput struct {
+	BanAddress string `json:"banAddress"`
+	Percent    int    `json:"percent"`
+}
+
+type PayoutCalendar struct {
+	PrizePool           int            `json:"prizePool"`
+	Cycles              []*PayoutCycle `json:"cycles"`
+	WalletBalanceBanano *float64       `json:"walletBalanceBanano"`
+	WalletBalanceRaw    *string        `json:"walletBalanceRaw"`
+	BalanceCheckedAt    *string        `json:"balanceCheckedAt"`
+}
+
+type PayoutCycle struct {
+	PayoutAt       string           `json:"payoutAt"`
+	RequiredBanano float64          `json:"requiredBanano"`
+	RequiredRaw    string           `json:"requiredRaw"`
+	Funding        PrizePoolFunding `json:"funding"`
+}
+
+type PayoutProjection struct {
+	PayoutAt         string  `json:"payoutAt"`
+	UnpaidDifficulty int     `json:"unpaidDifficulty"`
+	PercentOfPool    float64 `json:"percentOfPool"`
+	ProjectedBanano  float64 `json:"projectedBanano"`
+	ProjectedRaw     string  `json:"projectedRaw"`
+}
+
+type PayoutReport struct {
+	CycleID     string                  `json:"cycleId"`
+	CreatedAt   string                  `json:"createdAt"`
+	PrizePool   int                     `json:"prizePool"`
+	Providers   []*PayoutReportProvider `json:"providers"`
+	Payments    []*PayoutReportPayment  `json:"payments"`
+	Complete    bool                    `json:"complete"`
+	MatchesWork bool                    `json:"matchesWork"`
+	JSON        string                  `json:"json"`
+	Sha256      string                  `json:"sha256"`
+	Signature   *string                 `json:"signature"`
+	PublicKey   *string                 `json:"publicKey"`
+	DownloadURL string                  `json:"downloadUrl"`
+}
+
+type PayoutReportPayment struct {
+	Provider    string  `json:"provider"`
+	Destination string  `json:"destination"`
+	AmountRaw   string  `json:"amountRaw"`
+	BlockHash   *string `json:"blockHash"`
+}
+
+type PayoutReportProvider struct {
+	BanAddress           string `json:"banAddress"`
+	UnpaidCount          int    `json:"unpaidCount"`
+	DifficultySum        int    `json:"difficultySum"`
+	RatedAwardRaw        string `json:"ratedAwardRaw"`
+	UnratedDifficultySum int    `json:"unratedDifficultySum"`
+}
+
+type PoolStatusResponse struct {
+	Status           PoolStatus  `json:"status"`
+	ConnectedWorkers int         `json:"connectedWorkers"`
+	Incidents        []*Incident `json:"incidents"`
+}
+
+type PowChallenge struct {
+	Hash       string `json:"hash"`
+	Difficulty string `json:"difficulty"`
+	ExpiresAt  string `json:"expiresAt"`
+}
+
+type PriorityBoost struct {
+	ID          string  `json:"id"`
+	StartsAt    string  `json:"startsAt"`
+	EndsAt      string  `json:"endsAt"`
+	Minutes     int     `json:"minutes"`
+	PriceBanano float64 `json:"priceBanano"`
+	PriceRaw    string  `json:"priceRaw"`
+}
+
+type ProviderStats struct {
+	AcceptedWork      int               `json:"acceptedWork"`
+	EstimatedEarnings *PayoutProjection `json:"estimatedEarnings"`
+	Workers           []*ProviderWorker `json:"workers"`
+	UpdatedAt         string            `json:"updatedAt"`
+}
+
+type ProviderWorker struct {
+	IPAddress     string   `json:"ipAddress"`
+	ConnectedAt   string   `json:"connectedAt"`
+	ClientVersion *string  `json:"clientVersion"`
+	Gpus          []string `json:"gpus"`
+	CPUThreads    *int     `json:"cpuThreads"`
+	InFlight      int      `json:"inFlight"`
+}
+
+type QuarantinedWorker struct {
+	IPAddress string `json:"ipAddress"`
+	Email     string `json:"email"`
+	Until     string `json:"until"`
+}
+
+type RecoverAccountInput struct {
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	BackupCode string `json:"backupCode"`
+}
+
+type RedisMemory struct {
+	CheckedAt         string               `json:"checkedAt"`
+	UsedBytes         int                  `json:"usedBytes"`
+	MaxBytes          int                  `json:"maxBytes"`
+	UsedPercent       float64              `json:"usedPercent"`
+	EvictionPolicy    string               `json:"evictionPolicy"`
+	CountersEvictable bool                 `json:"countersEvictable"`
+	Warnings          []string             `json:"warnings"`
+	Prefixes          []*RedisPrefixMemory `json:"prefixes"`
+}
+
+type RedisPrefixMemory struct {
+	Prefix string `json:"prefix"`
+	Keys   int    `json:"keys"`
+	Bytes  int    `json:"bytes"`
+}
+
 type RefreshTokenInput struct {
-	Token string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+type RegisterFrontiersInput struct {
+	Hashes               []string `json:"hashes"`
+	DifficultyMultiplier *int     `json:"difficultyMultiplier"`
+	Preset               *string  `json:"preset"`
+	Difficulty           *string  `json:"difficulty"`
+}
+
+type RequestSample struct {
+	ID            string   `json:"id"`
+	UserEmail     *string  `json:"userEmail"`
+	OperationName *string  `json:"operationName"`
+	Query         string   `json:"query"`
+	Variables     *string  `json:"variables"`
+	Response      *string  `json:"response"`
+	Errors        []string `json:"errors"`
+	DurationMs    int      `json:"durationMs"`
+	CreatedAt     string   `json:"createdAt"`
+}
+
+type RequestSampling struct {
+	UserEmail *string `json:"userEmail"`
+	Percent   float64 `json:"percent"`
+	Until     string  `json:"until"`
+}
+
+type RequestSamplingInput struct {
+	UserEmail *string `json:"userEmail"`
+	Percent   float64 `json:"percent"`
+	Minutes   int     `json:"minutes"`
 }
 
 type ResendConfirmationEmailInput struct {
@@ -49,6 +563,43 @@ type ResetPasswordInput struct {
 	Email string `json:"email"`
 }
 
+type ScheduleAwardRateInput struct {
+	BananoPerUnit float64 `json:"bananoPerUnit"`
+	EffectiveAt   string  `json:"effectiveAt"`
+	TenantID      *string `json:"tenantId"`
+}
+
+type SecurityWebhook struct {
+	URL       string `json:"url"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type SecurityWebhookRegistration struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+type SolveTime struct {
+	DifficultyMultiplier int `json:"difficultyMultiplier"`
+	Solves               int `json:"solves"`
+	AverageMs            int `json:"averageMs"`
+}
+
+type SourceUsage struct {
+	Source   string `json:"source"`
+	Requests int    `json:"requests"`
+	Cached   int    `json:"cached"`
+}
+
+type StaleAccountReport struct {
+	ID         string `json:"id"`
+	CreatedAt  string `json:"createdAt"`
+	Reminded   int    `json:"reminded"`
+	Disabled   int    `json:"disabled"`
+	Anonymized int    `json:"anonymized"`
+	Failed     int    `json:"failed"`
+}
+
 type Stats struct {
 	ConnectedWorkers       int                 `json:"connectedWorkers"`
 	TotalPaidBanano        string              `json:"totalPaidBanano"`
@@ -57,6 +608,15 @@ type Stats struct {
 	Services               []*StatsServiceType `json:"services"`
 }
 
+type StatsDrift struct {
+	Hour                 string `json:"hour"`
+	TenantID             string `json:"tenantId"`
+	DifficultyMultiplier int    `json:"difficultyMultiplier"`
+	Rollup               int    `json:"rollup"`
+	Actual               int    `json:"actual"`
+	Corrected            bool   `json:"corrected"`
+}
+
 type StatsServiceType struct {
 	Name     string `json:"name"`
 	Website  string `json:"website"`
@@ -68,6 +628,41 @@ type StatsUserType struct {
 	TotalPaidBanano string `json:"totalPaidBanano"`
 }
 
+type SubmitWorkInput struct {
+	Hash                 string `json:"hash"`
+	Work                 string `json:"work"`
+	DifficultyMultiplier int    `json:"difficultyMultiplier"`
+}
+
+type SubsystemLogLevel struct {
+	Subsystem LogSubsystem `json:"subsystem"`
+	Level     LogLevel     `json:"level"`
+}
+
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthURL string `json:"otpauthUrl"`
+}
+
+type UsageLine struct {
+	DifficultyMultiplier int `json:"difficultyMultiplier"`
+	Requests             int `json:"requests"`
+	Cached               int `json:"cached"`
+}
+
+type UsageStatement struct {
+	Month    string       `json:"month"`
+	Requests int          `json:"requests"`
+	Cached   int          `json:"cached"`
+	Closed   bool         `json:"closed"`
+	Lines    []*UsageLine `json:"lines"`
+}
+
 type User struct {
 	ID         string   `json:"id"`
 	Email      string   `json:"email"`
@@ -77,6 +672,20 @@ type User struct {
 	BanAddress *string  `json:"banAddress"`
 }
 
+func (User) IsEntity() {}
+
+type UserEvent struct {
+	Type         string  `json:"type"`
+	BlockHash    *string `json:"blockHash"`
+	AmountBanano *string `json:"amountBanano"`
+}
+
+type UserFilter struct {
+	Field  UserListField  `json:"field"`
+	Op     FilterOperator `json:"op"`
+	Values []string       `json:"values"`
+}
+
 type UserInput struct {
 	Email          string   `json:"email"`
 	Password       string   `json:"password"`
@@ -86,6 +695,47 @@ type UserInput struct {
 	ServiceWebsite *string  `json:"serviceWebsite"`
 }
 
+type UserRoles struct {
+	Email       string        `json:"email"`
+	Roles       []AccountRole `json:"roles"`
+	Permissions []Permission  `json:"permissions"`
+}
+
+type UserSort struct {
+	Field      UserListField `json:"field"`
+	Descending bool          `json:"descending"`
+}
+
+type ValidateWorkInput struct {
+	Hash                 string `json:"hash"`
+	Work                 string `json:"work"`
+	DifficultyMultiplier int    `json:"difficultyMultiplier"`
+}
+
+type ValidationCrossCheck struct {
+	SamplePercent       float64                   `json:"samplePercent"`
+	Skipped             int                       `json:"skipped"`
+	Peers               []*ValidationPeerStats    `json:"peers"`
+	RecentDisagreements []*ValidationDisagreement `json:"recentDisagreements"`
+}
+
+type ValidationDisagreement struct {
+	Peer                 string `json:"peer"`
+	Hash                 string `json:"hash"`
+	Work                 string `json:"work"`
+	DifficultyMultiplier int    `json:"difficultyMultiplier"`
+	OursValid            bool   `json:"oursValid"`
+	At                   string `json:"at"`
+}
+
+type ValidationPeerStats struct {
+	Peer          string  `json:"peer"`
+	Checked       int     `json:"checked"`
+	Disagreements int     `json:"disagreements"`
+	Errors        int     `json:"errors"`
+	LastError     *string `json:"lastError"`
+}
+
 type VerifyEmailInput struct {
 	Email string `json:"email"`
 	Token string `json:"token"`
@@ -97,48 +747,936 @@ type VerifyServiceInput struct {
 }
 
 type WorkGenerateInput struct {
+	Hash                 string  `json:"hash"`
+	DifficultyMultiplier *int    `json:"difficultyMultiplier"`
+	Preset               *string `json:"preset"`
+	Difficulty           *string `json:"difficulty"`
+	BlockAward           *bool   `json:"blockAward"`
+}
+
+type WorkResult struct {
+	ID                   string `json:"id"`
 	Hash                 string `json:"hash"`
+	Result               string `json:"result"`
 	DifficultyMultiplier int    `json:"difficultyMultiplier"`
-	BlockAward           *bool  `json:"blockAward"`
+	TenantID             string `json:"tenantId"`
+	ProvidedBy           string `json:"providedBy"`
+	RequestedBy          string `json:"requestedBy"`
+	Awarded              bool   `json:"awarded"`
+	Precache             bool   `json:"precache"`
+	SolveLatencyMs       int    `json:"solveLatencyMs"`
+	CreditPercent        int    `json:"creditPercent"`
+	CreatedAt            string `json:"createdAt"`
 }
 
-type UserType string
+type WorkResultFilter struct {
+	Field  WorkResultListField `json:"field"`
+	Op     FilterOperator      `json:"op"`
+	Values []string            `json:"values"`
+}
+
+type WorkResultSort struct {
+	Field      WorkResultListField `json:"field"`
+	Descending bool                `json:"descending"`
+}
+
+type WorkSource struct {
+	Name          string `json:"name"`
+	DailyQuota    *int   `json:"dailyQuota"`
+	RequestsToday int    `json:"requestsToday"`
+	CreatedAt     string `json:"createdAt"`
+}
+
+type WorkerAbuseStats struct {
+	MalformedFrames int                  `json:"malformedFrames"`
+	OversizedFrames int                  `json:"oversizedFrames"`
+	Quarantines     int                  `json:"quarantines"`
+	Quarantined     []*QuarantinedWorker `json:"quarantined"`
+}
+
+type WorkerSolveTimes struct {
+	IPAddress    string       `json:"ipAddress"`
+	Email        string       `json:"email"`
+	Difficulties []*SolveTime `json:"difficulties"`
+}
+
+type AccountRole string
 
 const (
-	UserTypeProvider  UserType = "PROVIDER"
-	UserTypeRequester UserType = "REQUESTER"
+	AccountRoleAdmin     AccountRole = "ADMIN"
+	AccountRoleModerator AccountRole = "MODERATOR"
+	AccountRoleBanned    AccountRole = "BANNED"
 )
 
-var AllUserType = []UserType{
-	UserTypeProvider,
-	UserTypeRequester,
+var AllAccountRole = []AccountRole{
+	AccountRoleAdmin,
+	AccountRoleModerator,
+	AccountRoleBanned,
 }
 
-func (e UserType) IsValid() bool {
+func (e AccountRole) IsValid() bool {
 	switch e {
-	case UserTypeProvider, UserTypeRequester:
+	case AccountRoleAdmin, AccountRoleModerator, AccountRoleBanned:
 		return true
 	}
 	return false
 }
 
-func (e UserType) String() string {
+func (e AccountRole) String() string {
 	return string(e)
 }
 
-func (e *UserType) UnmarshalGQL(v interface{}) error {
+func (e *AccountRole) UnmarshalGQL(v interface{}) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = UserType(str)
+	*e = AccountRole(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid UserType", str)
+		return fmt.Errorf("%s is not a valid AccountRole", str)
 	}
 	return nil
 }
 
-func (e UserType) MarshalGQL(w io.Writer) {
+func (e AccountRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type AlertChannel string
+
+const (
+	AlertChannelEmail   AlertChannel = "EMAIL"
+	AlertChannelWebhook AlertChannel = "WEBHOOK"
+	AlertChannelDiscord AlertChannel = "DISCORD"
+)
+
+var AllAlertChannel = []AlertChannel{
+	AlertChannelEmail,
+	AlertChannelWebhook,
+	AlertChannelDiscord,
+}
+
+func (e AlertChannel) IsValid() bool {
+	switch e {
+	case AlertChannelEmail, AlertChannelWebhook, AlertChannelDiscord:
+		return true
+	}
+	return false
+}
+
+func (e AlertChannel) String() string {
+	return string(e)
+}
+
+func (e *AlertChannel) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AlertChannel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AlertChannel", str)
+	}
+	return nil
+}
+
+func (e AlertChannel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type AuditLogListField string
+
+const (
+	AuditLogListFieldAction     AuditLogListField = "ACTION"
+	AuditLogListFieldActorID    AuditLogListField = "ACTOR_ID"
+	AuditLogListFieldActorEmail AuditLogListField = "ACTOR_EMAIL"
+	AuditLogListFieldTarget     AuditLogListField = "TARGET"
+	AuditLogListFieldClientIP   AuditLogListField = "CLIENT_IP"
+	AuditLogListFieldSuccess    AuditLogListField = "SUCCESS"
+	AuditLogListFieldCreatedAt  AuditLogListField = "CREATED_AT"
+)
+
+var AllAuditLogListField = []AuditLogListField{
+	AuditLogListFieldAction,
+	AuditLogListFieldActorID,
+	AuditLogListFieldActorEmail,
+	AuditLogListFieldTarget,
+	AuditLogListFieldClientIP,
+	AuditLogListFieldSuccess,
+	AuditLogListFieldCreatedAt,
+}
+
+func (e AuditLogListField) IsValid() bool {
+	switch e {
+	case AuditLogListFieldAction, AuditLogListFieldActorID, AuditLogListFieldActorEmail, AuditLogListFieldTarget, AuditLogListFieldClientIP, AuditLogListFieldSuccess, AuditLogListFieldCreatedAt:
+		return true
+	}
+	return false
+}
+
+func (e AuditLogListField) String() string {
+	return string(e)
+}
+
+func (e *AuditLogListField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AuditLogListField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AuditLogListField", str)
+	}
+	return nil
+}
+
+func (e AuditLogListField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type BenchmarkBackend string
+
+const (
+	BenchmarkBackendGpu BenchmarkBackend = "GPU"
+	BenchmarkBackendCPU BenchmarkBackend = "CPU"
+)
+
+var AllBenchmarkBackend = []BenchmarkBackend{
+	BenchmarkBackendGpu,
+	BenchmarkBackendCPU,
+}
+
+func (e BenchmarkBackend) IsValid() bool {
+	switch e {
+	case BenchmarkBackendGpu, BenchmarkBackendCPU:
+		return true
+	}
+	return false
+}
+
+func (e BenchmarkBackend) String() string {
+	return string(e)
+}
+
+func (e *BenchmarkBackend) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = BenchmarkBackend(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid BenchmarkBackend", str)
+	}
+	return nil
+}
+
+func (e BenchmarkBackend) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type DifficultyClass string
+
+const (
+	DifficultyClassPrecache DifficultyClass = "PRECACHE"
+	DifficultyClassBase     DifficultyClass = "BASE"
+	DifficultyClassElevated DifficultyClass = "ELEVATED"
+)
+
+var AllDifficultyClass = []DifficultyClass{
+	DifficultyClassPrecache,
+	DifficultyClassBase,
+	DifficultyClassElevated,
+}
+
+func (e DifficultyClass) IsValid() bool {
+	switch e {
+	case DifficultyClassPrecache, DifficultyClassBase, DifficultyClassElevated:
+		return true
+	}
+	return false
+}
+
+func (e DifficultyClass) String() string {
+	return string(e)
+}
+
+func (e *DifficultyClass) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DifficultyClass(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DifficultyClass", str)
+	}
+	return nil
+}
+
+func (e DifficultyClass) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type FilterOperator string
+
+const (
+	FilterOperatorEq       FilterOperator = "EQ"
+	FilterOperatorNe       FilterOperator = "NE"
+	FilterOperatorLt       FilterOperator = "LT"
+	FilterOperatorLte      FilterOperator = "LTE"
+	FilterOperatorGt       FilterOperator = "GT"
+	FilterOperatorGte      FilterOperator = "GTE"
+	FilterOperatorIn       FilterOperator = "IN"
+	FilterOperatorContains FilterOperator = "CONTAINS"
+	FilterOperatorIsNull   FilterOperator = "IS_NULL"
+)
+
+var AllFilterOperator = []FilterOperator{
+	FilterOperatorEq,
+	FilterOperatorNe,
+	FilterOperatorLt,
+	FilterOperatorLte,
+	FilterOperatorGt,
+	FilterOperatorGte,
+	FilterOperatorIn,
+	FilterOperatorContains,
+	FilterOperatorIsNull,
+}
+
+func (e FilterOperator) IsValid() bool {
+	switch e {
+	case FilterOperatorEq, FilterOperatorNe, FilterOperatorLt, FilterOperatorLte, FilterOperatorGt, FilterOperatorGte, FilterOperatorIn, FilterOperatorContains, FilterOperatorIsNull:
+		return true
+	}
+	return false
+}
+
+func (e FilterOperator) String() string {
+	return string(e)
+}
+
+func (e *FilterOperator) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FilterOperator(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FilterOperator", str)
+	}
+	return nil
+}
+
+func (e FilterOperator) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type IncidentSeverity string
+
+const (
+	IncidentSeverityDegraded IncidentSeverity = "DEGRADED"
+	IncidentSeverityOutage   IncidentSeverity = "OUTAGE"
+)
+
+var AllIncidentSeverity = []IncidentSeverity{
+	IncidentSeverityDegraded,
+	IncidentSeverityOutage,
+}
+
+func (e IncidentSeverity) IsValid() bool {
+	switch e {
+	case IncidentSeverityDegraded, IncidentSeverityOutage:
+		return true
+	}
+	return false
+}
+
+func (e IncidentSeverity) String() string {
+	return string(e)
+}
+
+func (e *IncidentSeverity) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = IncidentSeverity(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid IncidentSeverity", str)
+	}
+	return nil
+}
+
+func (e IncidentSeverity) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type LeaderboardWindow string
+
+const (
+	LeaderboardWindowDay   LeaderboardWindow = "DAY"
+	LeaderboardWindowWeek  LeaderboardWindow = "WEEK"
+	LeaderboardWindowMonth LeaderboardWindow = "MONTH"
+	LeaderboardWindowAll   LeaderboardWindow = "ALL"
+)
+
+var AllLeaderboardWindow = []LeaderboardWindow{
+	LeaderboardWindowDay,
+	LeaderboardWindowWeek,
+	LeaderboardWindowMonth,
+	LeaderboardWindowAll,
+}
+
+func (e LeaderboardWindow) IsValid() bool {
+	switch e {
+	case LeaderboardWindowDay, LeaderboardWindowWeek, LeaderboardWindowMonth, LeaderboardWindowAll:
+		return true
+	}
+	return false
+}
+
+func (e LeaderboardWindow) String() string {
+	return string(e)
+}
+
+func (e *LeaderboardWindow) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LeaderboardWindow(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LeaderboardWindow", str)
+	}
+	return nil
+}
+
+func (e LeaderboardWindow) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type LogLevel string
+
+const (
+	LogLevelError   LogLevel = "ERROR"
+	LogLevelWarning LogLevel = "WARNING"
+	LogLevelInfo    LogLevel = "INFO"
+	LogLevelDebug   LogLevel = "DEBUG"
+)
+
+var AllLogLevel = []LogLevel{
+	LogLevelError,
+	LogLevelWarning,
+	LogLevelInfo,
+	LogLevelDebug,
+}
+
+func (e LogLevel) IsValid() bool {
+	switch e {
+	case LogLevelError, LogLevelWarning, LogLevelInfo, LogLevelDebug:
+		return true
+	}
+	return false
+}
+
+func (e LogLevel) String() string {
+	return string(e)
+}
+
+func (e *LogLevel) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LogLevel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LogLevel", str)
+	}
+	return nil
+}
+
+func (e LogLevel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type LogSubsystem string
+
+const (
+	LogSubsystemHub     LogSubsystem = "HUB"
+	LogSubsystemAuth    LogSubsystem = "AUTH"
+	LogSubsystemStats   LogSubsystem = "STATS"
+	LogSubsystemPayouts LogSubsystem = "PAYOUTS"
+	LogSubsystemRedis   LogSubsystem = "REDIS"
+)
+
+var AllLogSubsystem = []LogSubsystem{
+	LogSubsystemHub,
+	LogSubsystemAuth,
+	LogSubsystemStats,
+	LogSubsystemPayouts,
+	LogSubsystemRedis,
+}
+
+func (e LogSubsystem) IsValid() bool {
+	switch e {
+	case LogSubsystemHub, LogSubsystemAuth, LogSubsystemStats, LogSubsystemPayouts, LogSubsystemRedis:
+		return true
+	}
+	return false
+}
+
+func (e LogSubsystem) String() string {
+	return string(e)
+}
+
+func (e *LogSubsystem) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LogSubsystem(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LogSubsystem", str)
+	}
+	return nil
+}
+
+func (e LogSubsystem) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Permission string
+
+const (
+	PermissionBanUsers        Permission = "BAN_USERS"
+	PermissionModerateWorkers Permission = "MODERATE_WORKERS"
+	PermissionAdjustPayouts   Permission = "ADJUST_PAYOUTS"
+	PermissionManageRoles     Permission = "MANAGE_ROLES"
+)
+
+var AllPermission = []Permission{
+	PermissionBanUsers,
+	PermissionModerateWorkers,
+	PermissionAdjustPayouts,
+	PermissionManageRoles,
+}
+
+func (e Permission) IsValid() bool {
+	switch e {
+	case PermissionBanUsers, PermissionModerateWorkers, PermissionAdjustPayouts, PermissionManageRoles:
+		return true
+	}
+	return false
+}
+
+func (e Permission) String() string {
+	return string(e)
+}
+
+func (e *Permission) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Permission(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Permission", str)
+	}
+	return nil
+}
+
+func (e Permission) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PoolStatus string
+
+const (
+	PoolStatusOperational PoolStatus = "OPERATIONAL"
+	PoolStatusDegraded    PoolStatus = "DEGRADED"
+	PoolStatusOutage      PoolStatus = "OUTAGE"
+)
+
+var AllPoolStatus = []PoolStatus{
+	PoolStatusOperational,
+	PoolStatusDegraded,
+	PoolStatusOutage,
+}
+
+func (e PoolStatus) IsValid() bool {
+	switch e {
+	case PoolStatusOperational, PoolStatusDegraded, PoolStatusOutage:
+		return true
+	}
+	return false
+}
+
+func (e PoolStatus) String() string {
+	return string(e)
+}
+
+func (e *PoolStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PoolStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PoolStatus", str)
+	}
+	return nil
+}
+
+func (e PoolStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PrizePoolFunding string
+
+const (
+	PrizePoolFundingFunded      PrizePoolFunding = "FUNDED"
+	PrizePoolFundingUnderfunded PrizePoolFunding = "UNDERFUNDED"
+	PrizePoolFundingUnknown     PrizePoolFunding = "UNKNOWN"
+)
+
+var AllPrizePoolFunding = []PrizePoolFunding{
+	PrizePoolFundingFunded,
+	PrizePoolFundingUnderfunded,
+	PrizePoolFundingUnknown,
+}
+
+func (e PrizePoolFunding) IsValid() bool {
+	switch e {
+	case PrizePoolFundingFunded, PrizePoolFundingUnderfunded, PrizePoolFundingUnknown:
+		return true
+	}
+	return false
+}
+
+func (e PrizePoolFunding) String() string {
+	return string(e)
+}
+
+func (e *PrizePoolFunding) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PrizePoolFunding(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PrizePoolFunding", str)
+	}
+	return nil
+}
+
+func (e PrizePoolFunding) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RequesterTier string
+
+const (
+	RequesterTierStandard RequesterTier = "STANDARD"
+	RequesterTierBoosted  RequesterTier = "BOOSTED"
+)
+
+var AllRequesterTier = []RequesterTier{
+	RequesterTierStandard,
+	RequesterTierBoosted,
+}
+
+func (e RequesterTier) IsValid() bool {
+	switch e {
+	case RequesterTierStandard, RequesterTierBoosted:
+		return true
+	}
+	return false
+}
+
+func (e RequesterTier) String() string {
+	return string(e)
+}
+
+func (e *RequesterTier) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RequesterTier(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RequesterTier", str)
+	}
+	return nil
+}
+
+func (e RequesterTier) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Role string
+
+const (
+	RoleUser           Role = "USER"
+	RoleProvider       Role = "PROVIDER"
+	RoleRequester      Role = "REQUESTER"
+	RoleServiceToken   Role = "SERVICE_TOKEN"
+	RoleAdmin          Role = "ADMIN"
+	RoleChangePassword Role = "CHANGE_PASSWORD"
+)
+
+var AllRole = []Role{
+	RoleUser,
+	RoleProvider,
+	RoleRequester,
+	RoleServiceToken,
+	RoleAdmin,
+	RoleChangePassword,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleUser, RoleProvider, RoleRequester, RoleServiceToken, RoleAdmin, RoleChangePassword:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type StatsRange string
+
+const (
+	StatsRangeDay   StatsRange = "DAY"
+	StatsRangeWeek  StatsRange = "WEEK"
+	StatsRangeMonth StatsRange = "MONTH"
+)
+
+var AllStatsRange = []StatsRange{
+	StatsRangeDay,
+	StatsRangeWeek,
+	StatsRangeMonth,
+}
+
+func (e StatsRange) IsValid() bool {
+	switch e {
+	case StatsRangeDay, StatsRangeWeek, StatsRangeMonth:
+		return true
+	}
+	return false
+}
+
+func (e StatsRange) String() string {
+	return string(e)
+}
+
+func (e *StatsRange) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = StatsRange(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid StatsRange", str)
+	}
+	return nil
+}
+
+func (e StatsRange) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type UserListField string
+
+const (
+	UserListFieldEmail               UserListField = "EMAIL"
+	UserListFieldType                UserListField = "TYPE"
+	UserListFieldTenantID            UserListField = "TENANT_ID"
+	UserListFieldEmailVerified       UserListField = "EMAIL_VERIFIED"
+	UserListFieldCanRequestWork      UserListField = "CAN_REQUEST_WORK"
+	UserListFieldTwoFactorEnabled    UserListField = "TWO_FACTOR_ENABLED"
+	UserListFieldStaleExempt         UserListField = "STALE_EXEMPT"
+	UserListFieldInvalidResultCount  UserListField = "INVALID_RESULT_COUNT"
+	UserListFieldServiceName         UserListField = "SERVICE_NAME"
+	UserListFieldCreatedAt           UserListField = "CREATED_AT"
+	UserListFieldUpdatedAt           UserListField = "UPDATED_AT"
+	UserListFieldLastProvidedWorkAt  UserListField = "LAST_PROVIDED_WORK_AT"
+	UserListFieldLastRequestedWorkAt UserListField = "LAST_REQUESTED_WORK_AT"
+	UserListFieldDisabledAt          UserListField = "DISABLED_AT"
+)
+
+var AllUserListField = []UserListField{
+	UserListFieldEmail,
+	UserListFieldType,
+	UserListFieldTenantID,
+	UserListFieldEmailVerified,
+	UserListFieldCanRequestWork,
+	UserListFieldTwoFactorEnabled,
+	UserListFieldStaleExempt,
+	UserListFieldInvalidResultCount,
+	UserListFieldServiceName,
+	UserListFieldCreatedAt,
+	UserListFieldUpdatedAt,
+	UserListFieldLastProvidedWorkAt,
+	UserListFieldLastRequestedWorkAt,
+	UserListFieldDisabledAt,
+}
+
+func (e UserListField) IsValid() bool {
+	switch e {
+	case UserListFieldEmail, UserListFieldType, UserListFieldTenantID, UserListFieldEmailVerified, UserListFieldCanRequestWork, UserListFieldTwoFactorEnabled, UserListFieldStaleExempt, UserListFieldInvalidResultCount, UserListFieldServiceName, UserListFieldCreatedAt, UserListFieldUpdatedAt, UserListFieldLastProvidedWorkAt, UserListFieldLastRequestedWorkAt, UserListFieldDisabledAt:
+		return true
+	}
+	return false
+}
+
+func (e UserListField) String() string {
+	return string(e)
+}
+
+func (e *UserListField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UserListField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UserListField", str)
+	}
+	return nil
+}
+
+func (e UserListField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type UserType string
+
+const (
+	UserTypeProvider  UserType = "PROVIDER"
+	UserTypeRequester UserType = "REQUESTER"
+)
+
+var AllUserType = []UserType{
+	UserTypeProvider,
+	UserTypeRequester,
+}
+
+func (e UserType) IsValid() bool {
+	switch e {
+	case UserTypeProvider, UserTypeRequester:
+		return true
+	}
+	return false
+}
+
+func (e UserType) String() string {
+	return string(e)
+}
+
+func (e *UserType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UserType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UserType", str)
+	}
+	return nil
+}
+
+func (e UserType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type WorkResultListField string
+
+const (
+	WorkResultListFieldHash                 WorkResultListField = "HASH"
+	WorkResultListFieldTenantID             WorkResultListField = "TENANT_ID"
+	WorkResultListFieldProvidedBy           WorkResultListField = "PROVIDED_BY"
+	WorkResultListFieldRequestedBy          WorkResultListField = "REQUESTED_BY"
+	WorkResultListFieldDifficultyMultiplier WorkResultListField = "DIFFICULTY_MULTIPLIER"
+	WorkResultListFieldAwarded              WorkResultListField = "AWARDED"
+	WorkResultListFieldPrecache             WorkResultListField = "PRECACHE"
+	WorkResultListFieldSolveLatencyMs       WorkResultListField = "SOLVE_LATENCY_MS"
+	WorkResultListFieldCreditPercent        WorkResultListField = "CREDIT_PERCENT"
+	WorkResultListFieldCreatedAt            WorkResultListField = "CREATED_AT"
+)
+
+var AllWorkResultListField = []WorkResultListField{
+	WorkResultListFieldHash,
+	WorkResultListFieldTenantID,
+	WorkResultListFieldProvidedBy,
+	WorkResultListFieldRequestedBy,
+	WorkResultListFieldDifficultyMultiplier,
+	WorkResultListFieldAwarded,
+	WorkResultListFieldPrecache,
+	WorkResultListFieldSolveLatencyMs,
+	WorkResultListFieldCreditPercent,
+	WorkResultListFieldCreatedAt,
+}
+
+func (e WorkResultListField) IsValid() bool {
+	switch e {
+	case WorkResultListFieldHash, WorkResultListFieldTenantID, WorkResultListFieldProvidedBy, WorkResultListFieldRequestedBy, WorkResultListFieldDifficultyMultiplier, WorkResultListFieldAwarded, WorkResultListFieldPrecache, WorkResultListFieldSolveLatencyMs, WorkResultListFieldCreditPercent, WorkResultListFieldCreatedAt:
+		return true
+	}
+	return false
+}
+
+func (e WorkResultListField) String() string {
+	return string(e)
+}
+
+func (e *WorkResultListField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WorkResultListField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WorkResultListField", str)
+	}
+	return nil
+}
+
+func (e WorkResultListField) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/controller"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/geo"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+)
+
+// Counts the request towards the location of the requester's IP, the IP itself isn't stored
+func (r *Resolver) recordRequestCountry(ctx context.Context, tenantID string) {
+	if r.GeoLocator == nil {
+		return
+	}
+	if err := database.GetRedisDB().IncrementGeoRequests(tenantID, r.GeoLocator.Locate(middleware.ClientIP(ctx)).Key(), r.now()); err != nil {
+		logging.Errorf(logging.Stats, "Error recording request country %v", err)
+	}
+}
+
+// Workers connected right now and requests over the range by country, public stats group countries with small counts
+func (r *Resolver) countryStats(ctx context.Context, statsRange model.StatsRange, public bool) ([]*model.CountryStats, error) {
+	if r.GeoLocator == nil {
+		return []*model.CountryStats{}, nil
+	}
+	tenantID := middleware.RequestTenant(ctx)
+	now := r.now()
+	stored, err := database.GetRedisDB().GetGeoRequests(tenantID, statsRangeSince(statsRange, now), now)
+	if err != nil {
+		return nil, err
+	}
+	requests := map[geo.Location]int64{}
+	for key, count := range stored {
+		requests[geo.ParseLocation(key)] += count
+	}
+	workers := geo.CountLocations(r.GeoLocator, controller.ActiveHub.TenantIPs(tenantID))
+	if public {
+		workers = geo.GroupSmall(workers, config.GEO_PUBLIC_MIN_COUNT)
+		requests = geo.GroupSmall(requests, config.GEO_PUBLIC_MIN_COUNT)
+	}
+	return countryStatsToModel(geo.Merge(workers, requests)), nil
+}
+
+func countryStatsToModel(stats []geo.CountryStats) []*model.CountryStats {
+	ret := make([]*model.CountryStats, len(stats))
+	for i, s := range stats {
+		ret[i] = &model.CountryStats{
+			Continent:        s.Continent,
+			Country:          s.Country,
+			ConnectedWorkers: int(s.ConnectedWorkers),
+			Requests:         int(s.Requests),
+		}
+	}
+	return ret
+}
@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/controller"
+	"github.com/bananocoin/boompow/apps/server/src/geo"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/libs/utils/clock"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+type fakeLocator map[string]geo.Location
+
+func (f fakeLocator) Locate(ip string) geo.Location {
+	if location, ok := f[ip]; ok {
+		return location
+	}
+	return geo.UnknownLocation
+}
+
+// Context of a request from ip
+func requestFrom(ip string) context.Context {
+	var ctx context.Context
+	middleware.ClientIPMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		req.Header.Set("X-Real-Ip", ip)
+		return req
+	}())
+	return ctx
+}
+
+func TestCountryStats(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	au := geo.Location{Continent: "OC", Country: "AU"}
+	nz := geo.Location{Continent: "OC", Country: "NZ"}
+	r := &Resolver{
+		GeoLocator: fakeLocator{"1.1.1.1": au, "1.1.1.2": au, "1.1.1.3": au, "2.2.2.2": nz},
+		Clock:      clock.NewFake(time.Date(2022, 10, 3, 12, 0, 0, 0, time.UTC)),
+	}
+	controller.ActiveHub = controller.NewHub(nil)
+	for _, ip := range []string{"1.1.1.1", "1.1.1.2", "1.1.1.3", "2.2.2.2"} {
+		controller.ActiveHub.Clients[&controller.Client{IPAddress: ip, TenantID: "default"}] = true
+	}
+	for i := 0; i < 3; i++ {
+		r.recordRequestCountry(requestFrom("2.2.2.2"), "default")
+	}
+
+	stats, err := r.countryStats(context.Background(), model.StatsRangeDay, false)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, []*model.CountryStats{
+		{Continent: "OC", Country: "AU", ConnectedWorkers: 3},
+		{Continent: "OC", Country: "NZ", ConnectedWorkers: 1, Requests: 3},
+	}, stats)
+
+	// The lone worker in NZ is only shown as part of the continent
+	stats, err = r.countryStats(context.Background(), model.StatsRangeDay, true)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, []*model.CountryStats{
+		{Continent: "OC", Country: "AU", ConnectedWorkers: 3},
+		{Continent: "OC", Country: "NZ", Requests: 3},
+		{Continent: "ZZ", Country: "ZZ", ConnectedWorkers: 1},
+	}, stats)
+
+	// Off without a GeoIP database
+	stats, err = (&Resolver{}).countryStats(context.Background(), model.StatsRangeDay, true)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(stats))
+}
@@ -0,0 +1,17 @@
+package graph
+
+import (
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+)
+
+func offlineAlertToModel(alert *models.OfflineAlert) *model.OfflineAlert {
+	ret := &model.OfflineAlert{
+		Channel:      model.AlertChannel(alert.Channel),
+		AfterMinutes: alert.AfterMinutes,
+	}
+	if alert.Target != "" {
+		ret.Target = &alert.Target
+	}
+	return ret
+}
@@ -0,0 +1,29 @@
+package graph
+
+import (
+	"errors"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+)
+
+func pageInfoToModel[T any](page pagination.Page[T]) *model.PageInfo {
+	info := &model.PageInfo{HasNextPage: page.HasNextPage}
+	if page.EndCursor != nil {
+		endCursor := page.EndCursor.String()
+		info.EndCursor = &endCursor
+	}
+	return info
+}
+
+// Resolves totalCount of a connection
+func totalCount(count func() (int, error)) (int, error) {
+	if count == nil {
+		return 0, errors.New("list can't be counted")
+	}
+	n, err := count()
+	if err != nil {
+		return 0, errors.New("error counting list")
+	}
+	return n, nil
+}
@@ -0,0 +1,17 @@
+package graph
+
+import (
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+)
+
+func payoutAddressesToModel(addresses []models.PayoutAddress) []*model.PayoutAddress {
+	ret := make([]*model.PayoutAddress, len(addresses))
+	for i, address := range addresses {
+		ret[i] = &model.PayoutAddress{
+			BanAddress: address.BanAddress,
+			Percent:    address.Percent,
+		}
+	}
+	return ret
+}
@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/payouts"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+	"github.com/bananocoin/boompow/libs/utils/number"
+)
+
+func payoutCalendarToModel(prizePool int, cycles []payouts.Cycle, balance *database.PrizePoolBalance) *model.PayoutCalendar {
+	ret := &model.PayoutCalendar{
+		PrizePool: prizePool,
+		Cycles:    make([]*model.PayoutCycle, len(cycles)),
+	}
+	for i, cycle := range cycles {
+		ret.Cycles[i] = &model.PayoutCycle{
+			PayoutAt:       utils.GenerateISOString(cycle.PayoutAt),
+			RequiredBanano: number.RawIntToBanano(cycle.RequiredRaw),
+			RequiredRaw:    cycle.RequiredRaw.String(),
+			Funding:        model.PrizePoolFunding(cycle.Funding),
+		}
+	}
+	if raw := balanceRaw(balance); raw != nil {
+		checkedAt := utils.GenerateISOString(balance.CheckedAt)
+		banano := number.RawIntToBanano(raw)
+		ret.WalletBalanceBanano = &banano
+		ret.WalletBalanceRaw = &balance.Raw
+		ret.BalanceCheckedAt = &checkedAt
+	}
+	return ret
+}
+
+// Raw is nil when moneybags hasn't recorded the balance yet
+func balanceRaw(balance *database.PrizePoolBalance) *big.Int {
+	if balance == nil {
+		return nil
+	}
+	raw, err := number.RawToBigInt(balance.Raw)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
+
+func payoutProjectionToModel(payoutAt time.Time, unpaidDifficulty int, totalDifficulty int, projectedRaw *big.Int) *model.PayoutProjection {
+	ret := &model.PayoutProjection{
+		PayoutAt:         utils.GenerateISOString(payoutAt),
+		UnpaidDifficulty: unpaidDifficulty,
+		ProjectedBanano:  number.RawIntToBanano(projectedRaw),
+		ProjectedRaw:     projectedRaw.String(),
+	}
+	if totalDifficulty > 0 {
+		ret.PercentOfPool = float64(unpaidDifficulty) / float64(totalDifficulty) * 100
+	}
+	return ret
+}
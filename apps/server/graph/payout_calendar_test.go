@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/payouts"
+	"github.com/bananocoin/boompow/libs/utils/number"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestPayoutCalendarToModel(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	calendar := payoutCalendarToModel(1000, payouts.Calendar(now, 8, 2, number.BananoIntToRaw(1000), new(big.Int), nil), nil)
+	utils.AssertEqual(t, 2, len(calendar.Cycles))
+	utils.AssertEqual(t, model.PrizePoolFundingUnknown, calendar.Cycles[0].Funding)
+	utils.AssertEqual(t, (*float64)(nil), calendar.WalletBalanceBanano)
+
+	balance := &database.PrizePoolBalance{Raw: number.BananoIntToRaw(1500).String(), CheckedAt: now}
+	calendar = payoutCalendarToModel(1000, payouts.Calendar(now, 8, 2, number.BananoIntToRaw(1000), new(big.Int), balanceRaw(balance)), balance)
+	utils.AssertEqual(t, model.PrizePoolFundingFunded, calendar.Cycles[0].Funding)
+	utils.AssertEqual(t, model.PrizePoolFundingUnderfunded, calendar.Cycles[1].Funding)
+	utils.AssertEqual(t, 1000.0, calendar.Cycles[0].RequiredBanano)
+	utils.AssertEqual(t, "100000000000000000000000000000000", calendar.Cycles[0].RequiredRaw)
+	utils.AssertEqual(t, 1500.0, *calendar.WalletBalanceBanano)
+	utils.AssertEqual(t, "150000000000000000000000000000000", *calendar.WalletBalanceRaw)
+}
+
+func TestPayoutProjectionToModel(t *testing.T) {
+	now := time.Date(2022, 10, 2, 8, 0, 0, 0, time.UTC)
+	projection := payoutProjectionToModel(now, 100, 400, number.BananoIntToRaw(250))
+	utils.AssertEqual(t, 25.0, projection.PercentOfPool)
+	utils.AssertEqual(t, 250.0, projection.ProjectedBanano)
+	utils.AssertEqual(t, "25000000000000000000000000000000", projection.ProjectedRaw)
+
+	// Nothing unpaid yet
+	projection = payoutProjectionToModel(now, 0, 0, new(big.Int))
+	utils.AssertEqual(t, 0.0, projection.PercentOfPool)
+}
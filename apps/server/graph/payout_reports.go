@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"fmt"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/payouts"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+// Where the report of a cycle can be downloaded, relative to the API
+func payoutReportURL(cycleID string) string {
+	return fmt.Sprintf("/payouts/%s/report.json", cycleID)
+}
+
+func pastPayoutCycleToModel(cycle models.PayoutCycle) *model.PastPayoutCycle {
+	return &model.PastPayoutCycle{
+		ID:            cycle.ID.String(),
+		CreatedAt:     utils.GenerateISOString(cycle.CreatedAt),
+		PrizePool:     cycle.PrizePool,
+		ProviderCount: len(cycle.Inputs),
+	}
+}
+
+func payoutReportToModel(report payouts.Report, signed *payouts.SignedReport) *model.PayoutReport {
+	ret := &model.PayoutReport{
+		CycleID:     report.CycleID,
+		CreatedAt:   report.CreatedAt,
+		PrizePool:   report.PrizePool,
+		Providers:   make([]*model.PayoutReportProvider, len(report.Inputs)),
+		Payments:    make([]*model.PayoutReportPayment, len(report.Outputs)),
+		Complete:    report.Complete(),
+		MatchesWork: report.Verify() == nil,
+		JSON:        string(signed.Report),
+		Sha256:      signed.SHA256,
+		DownloadURL: payoutReportURL(report.CycleID),
+	}
+	for i, input := range report.Inputs {
+		ret.Providers[i] = &model.PayoutReportProvider{
+			BanAddress:           input.BanAddress,
+			UnpaidCount:          input.UnpaidCount,
+			DifficultySum:        input.DifficultySum,
+			RatedAwardRaw:        input.RatedAwardRaw,
+			UnratedDifficultySum: input.UnratedDifficultySum,
+		}
+	}
+	for i, output := range report.Outputs {
+		ret.Payments[i] = &model.PayoutReportPayment{
+			Provider:    output.Provider,
+			Destination: output.Destination,
+			AmountRaw:   output.AmountRaw,
+			BlockHash:   output.BlockHash,
+		}
+	}
+	if signed.Signature != "" {
+		ret.Signature = &signed.Signature
+		ret.PublicKey = &signed.PublicKey
+	}
+	return ret
+}
@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	env "github.com/bananocoin/boompow/libs/utils"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+	"github.com/bananocoin/boompow/libs/utils/number"
+)
+
+func boostPricing() models.BoostPricing {
+	return models.BoostPricing{
+		BananoPerMinute: env.GetBoostPriceBananoPerMinute(),
+		DailyCapMinutes: env.GetBoostDailyCapMinutes(),
+	}
+}
+
+func boostPricingToModel(pricing models.BoostPricing) *model.BoostPricing {
+	ret := &model.BoostPricing{
+		PricePerMinuteBanano: pricing.BananoPerMinute,
+		PricePerMinuteRaw:    "0",
+		DailyCapMinutes:      pricing.DailyCapMinutes,
+	}
+	if perMinute, err := pricing.PriceRaw(1); err == nil {
+		ret.PricePerMinuteRaw = perMinute.String()
+	}
+	return ret
+}
+
+func validateBoostMinutes(minutes int, pricing models.BoostPricing) error {
+	if !pricing.Enabled() {
+		return errors.New("bad_request:priority boosts aren't sold")
+	}
+	if minutes < 1 || minutes > pricing.DailyCapMinutes {
+		return fmt.Errorf("bad_request:minutes must be between 1 and %d", pricing.DailyCapMinutes)
+	}
+	return nil
+}
+
+func boostError(err error, pricing models.BoostPricing) error {
+	switch {
+	case errors.Is(err, repository.ErrInsufficientCredit):
+		return errors.New("bad_request:not enough credit for this boost")
+	case errors.Is(err, repository.ErrBoostCapReached):
+		return fmt.Errorf("bad_request:at most %d minutes of boost per day", pricing.DailyCapMinutes)
+	}
+	return errors.New("error purchasing boost")
+}
+
+// Returns the block hash in upper case, like work hashes
+func validateCreditDeposit(amountBanano float64, blockHash string) (*big.Int, string, error) {
+	blockHash = strings.ToUpper(strings.TrimSpace(blockHash))
+	if _, err := hex.DecodeString(blockHash); err != nil || len(blockHash) != 64 {
+		return nil, "", errors.New("bad_request:invalid block hash")
+	}
+	amount, err := number.RawToBigInt(number.BananoToRaw(amountBanano))
+	if err != nil || amount.Sign() <= 0 {
+		return nil, "", errors.New("bad_request:amountBanano must be positive")
+	}
+	return amount, blockHash, nil
+}
+
+func priorityBoostToModel(boost *models.PriorityBoost) *model.PriorityBoost {
+	price, _ := number.RawToBigInt(boost.PriceRaw)
+	return &model.PriorityBoost{
+		ID:          boost.ID.String(),
+		StartsAt:    utils.GenerateISOString(boost.StartsAt),
+		EndsAt:      utils.GenerateISOString(boost.EndsAt),
+		Minutes:     boost.Minutes,
+		PriceBanano: number.RawIntToBanano(price),
+		PriceRaw:    boost.PriceRaw,
+	}
+}
+
+func creditEntryToModel(entry *models.CreditEntry) *model.CreditEntry {
+	amount, _ := number.RawToBigInt(entry.AmountRaw)
+	return &model.CreditEntry{
+		Kind:         string(entry.Kind),
+		AmountBanano: number.RawIntToBanano(amount),
+		AmountRaw:    entry.AmountRaw,
+		Reference:    entry.Reference,
+		CreatedAt:    utils.GenerateISOString(entry.CreatedAt),
+	}
+}
+
+func creditAccountToModel(balance *big.Int, pricing models.BoostPricing, boughtToday int, active *models.PriorityBoost, entries []models.CreditEntry) *model.CreditAccount {
+	ret := &model.CreditAccount{
+		BalanceBanano:         number.RawIntToBanano(balance),
+		BalanceRaw:            balance.String(),
+		Pricing:               boostPricingToModel(pricing),
+		BoostMinutesLeftToday: pricing.DailyCapMinutes - boughtToday,
+		History:               make([]*model.CreditEntry, len(entries)),
+	}
+	if ret.BoostMinutesLeftToday < 0 {
+		ret.BoostMinutesLeftToday = 0
+	}
+	if active != nil {
+		boostedUntil := utils.GenerateISOString(active.EndsAt)
+		ret.BoostedUntil = &boostedUntil
+	}
+	for i := range entries {
+		ret.History[i] = creditEntryToModel(&entries[i])
+	}
+	return ret
+}
+
+func boostEconomicsToModel(economics *repository.BoostEconomics) *model.BoostEconomics {
+	revenue, err := number.RawToBigInt(economics.RevenueRaw)
+	if err != nil {
+		revenue = big.NewInt(0)
+	}
+	return &model.BoostEconomics{
+		Boosts:        economics.Boosts,
+		Minutes:       economics.Minutes,
+		Buyers:        economics.Buyers,
+		RevenueBanano: number.RawIntToBanano(revenue),
+		RevenueRaw:    revenue.String(),
+	}
+}
@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestValidateBoostMinutes(t *testing.T) {
+	utils.AssertNotEqual(t, nil, validateBoostMinutes(10, models.BoostPricing{DailyCapMinutes: 60}))
+
+	pricing := models.BoostPricing{BananoPerMinute: 1, DailyCapMinutes: 60}
+	utils.AssertEqual(t, nil, validateBoostMinutes(60, pricing))
+	utils.AssertNotEqual(t, nil, validateBoostMinutes(0, pricing))
+	utils.AssertNotEqual(t, nil, validateBoostMinutes(61, pricing))
+}
+
+func TestValidateCreditDeposit(t *testing.T) {
+	hash := "3f93c5cd2e314fa16702189041e68e68c07b27961bf37f0b7705145bec28ca45"
+	amount, blockHash, err := validateCreditDeposit(12.5, " "+hash)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "1250000000000000000000000000000", amount.String())
+	utils.AssertEqual(t, "3F93C5CD2E314FA16702189041E68E68C07B27961BF37F0B7705145BEC28CA45", blockHash)
+
+	_, _, err = validateCreditDeposit(0.001, hash)
+	utils.AssertNotEqual(t, nil, err)
+	_, _, err = validateCreditDeposit(10, "ban_1abc")
+	utils.AssertNotEqual(t, nil, err)
+}
+
+func TestCreditAccountToModel(t *testing.T) {
+	pricing := models.BoostPricing{BananoPerMinute: 0.5, DailyCapMinutes: 60}
+	balance, _ := new(big.Int).SetString("3000000000000000000000000000000", 10)
+	endsAt := time.Date(2022, 8, 1, 12, 30, 0, 0, time.UTC)
+	entries := []models.CreditEntry{
+		{AmountRaw: "-1000000000000000000000000000000", Kind: models.CreditEntryBoost, Reference: "boost:1"},
+		{AmountRaw: "4000000000000000000000000000000", Kind: models.CreditEntryDeposit, Reference: "ABC"},
+	}
+	account := creditAccountToModel(balance, pricing, 20, &models.PriorityBoost{EndsAt: endsAt}, entries)
+	utils.AssertEqual(t, 30.0, account.BalanceBanano)
+	utils.AssertEqual(t, "50000000000000000000000000000", account.Pricing.PricePerMinuteRaw)
+	utils.AssertEqual(t, 40, account.BoostMinutesLeftToday)
+	utils.AssertEqual(t, "2022-08-01T12:30:00Z", *account.BoostedUntil)
+	utils.AssertEqual(t, -10.0, account.History[0].AmountBanano)
+	utils.AssertEqual(t, "deposit", account.History[1].Kind)
+
+	// Lowering the cap doesn't leave a negative allowance
+	account = creditAccountToModel(balance, models.BoostPricing{DailyCapMinutes: 10}, 20, nil, nil)
+	utils.AssertEqual(t, 0, account.BoostMinutesLeftToday)
+	utils.AssertEqual(t, "0", account.Pricing.PricePerMinuteRaw)
+	utils.AssertEqual(t, (*string)(nil), account.BoostedUntil)
+}
+
+func TestBoostEconomicsToModel(t *testing.T) {
+	economics := boostEconomicsToModel(&repository.BoostEconomics{Boosts: 3, Minutes: 45, Buyers: 2, RevenueRaw: "2250000000000000000000000000000"})
+	utils.AssertEqual(t, 22.5, economics.RevenueBanano)
+	utils.AssertEqual(t, 2, economics.Buyers)
+}
@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/controller"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/payouts"
+	env "github.com/bananocoin/boompow/libs/utils"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+// What the provider would be paid at the next payout, along with how many results it's for
+func (r *Resolver) payoutProjection(provider *models.User) (*model.PayoutProjection, int, error) {
+	tenant, err := r.TenantRepo.GetTenant(provider.TenantID)
+	if err != nil {
+		return nil, 0, errors.New("unknown tenant")
+	}
+	unpaid, amounts, err := r.WorkRepo.EstimatePayouts(tenant.ID, tenant.GetPrizePool())
+	if err != nil {
+		return nil, 0, errors.New("error estimating payouts")
+	}
+	totalDifficulty := 0
+	unpaidDifficulty := 0
+	unpaidCount := 0
+	projected := new(big.Int)
+	for i, u := range unpaid {
+		totalDifficulty += u.DifficultySum
+		if u.ProvidedBy == provider.ID {
+			unpaidDifficulty = u.DifficultySum
+			unpaidCount = u.UnpaidCount
+			projected = amounts[i]
+		}
+	}
+	return payoutProjectionToModel(payouts.NextPayout(r.now(), env.GetPayoutHourUTC()), unpaidDifficulty, totalDifficulty, projected), unpaidCount, nil
+}
+
+func (r *Resolver) providerStats(provider *models.User) (*model.ProviderStats, error) {
+	projection, acceptedWork, err := r.payoutProjection(provider)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ProviderStats{
+		AcceptedWork:      acceptedWork,
+		EstimatedEarnings: projection,
+		Workers:           providerWorkersToModel(controller.ActiveHub.ProviderWorkers(provider.Email)),
+		UpdatedAt:         utils.GenerateISOString(r.now()),
+	}, nil
+}
+
+func providerWorkersToModel(workers []controller.ProviderWorker) []*model.ProviderWorker {
+	ret := make([]*model.ProviderWorker, len(workers))
+	for i := range workers {
+		worker := &workers[i]
+		ret[i] = &model.ProviderWorker{
+			IPAddress:   worker.IPAddress,
+			ConnectedAt: utils.GenerateISOString(worker.ConnectedAt),
+			Gpus:        worker.GPUs,
+			InFlight:    worker.InFlight,
+		}
+		if ret[i].Gpus == nil {
+			ret[i].Gpus = []string{}
+		}
+		if worker.ClientVersion != "" {
+			ret[i].ClientVersion = &worker.ClientVersion
+		}
+		if worker.CPUThreads > 0 {
+			ret[i].CPUThreads = &worker.CPUThreads
+		}
+	}
+	return ret
+}
@@ -0,0 +1,26 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/controller"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestProviderWorkersToModel(t *testing.T) {
+	connectedAt := time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)
+	version := "1.0.0"
+	threads := 8
+	workers := providerWorkersToModel([]controller.ProviderWorker{
+		{IPAddress: "1.1.1.1", ConnectedAt: connectedAt, ClientVersion: version, GPUs: []string{"RTX 3080"}, CPUThreads: threads, InFlight: 2},
+		// Didn't say hello
+		{IPAddress: "2.2.2.2", ConnectedAt: connectedAt},
+	})
+	utils.AssertEqual(t, 2, len(workers))
+	utils.AssertEqual(t, model.ProviderWorker{IPAddress: "1.1.1.1", ConnectedAt: "2022-09-01T12:00:00Z", ClientVersion: workers[0].ClientVersion, Gpus: []string{"RTX 3080"}, CPUThreads: workers[0].CPUThreads, InFlight: 2}, *workers[0])
+	utils.AssertEqual(t, version, *workers[0].ClientVersion)
+	utils.AssertEqual(t, threads, *workers[0].CPUThreads)
+	utils.AssertEqual(t, model.ProviderWorker{IPAddress: "2.2.2.2", ConnectedAt: "2022-09-01T12:00:00Z", Gpus: []string{}}, *workers[1])
+}
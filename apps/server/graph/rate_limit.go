@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/complexity"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Reports the quota left and the cost of the query in the response extensions, so clients can throttle themselves
+type RateLimitReport struct {
+	es graphql.ExecutableSchema
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = &RateLimitReport{}
+
+func (*RateLimitReport) ExtensionName() string {
+	return "RateLimitReport"
+}
+
+func (r *RateLimitReport) Validate(schema graphql.ExecutableSchema) error {
+	r.es = schema
+	return nil
+}
+
+func (r *RateLimitReport) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	response := next(ctx)
+	oc := graphql.GetOperationContext(ctx)
+	// Subscriptions are a single websocket connection, they don't go through the rate limiter per message
+	if response == nil || oc == nil || oc.Operation == nil || oc.Operation.Operation == ast.Subscription {
+		return response
+	}
+	cost := complexity.Calculate(r.es, oc.Operation, oc.Variables)
+	middleware.ReportQueryCost(ctx, cost)
+	// A remaining quota would give cacheable responses a different ETag every time
+	if middleware.CacheMaxAge(ctx) > 0 {
+		return response
+	}
+	if report := rateLimitReport(middleware.GetRateLimitStatus(ctx), cost); report != nil {
+		if response.Extensions == nil {
+			response.Extensions = map[string]interface{}{}
+		}
+		response.Extensions["rateLimit"] = report
+	}
+	return response
+}
+
+func rateLimitReport(status *middleware.RateLimitStatus, cost int) map[string]interface{} {
+	if status == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"limit":     status.Limit,
+		"remaining": status.Remaining,
+		"resetAt":   status.Reset.UTC(),
+		"cost":      cost,
+	}
+}
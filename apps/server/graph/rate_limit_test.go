@@ -0,0 +1,41 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/graph/generated"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/vektah/gqlparser/v2"
+)
+
+func TestRateLimitReport(t *testing.T) {
+	es := generated.NewExecutableSchema(generated.Config{Resolvers: &Resolver{}})
+	report := &RateLimitReport{}
+	report.Validate(es)
+	doc := gqlparser.MustLoadQuery(es.Schema(), `{ status { status } }`)
+
+	var response *graphql.Response
+	limiter := middleware.NewQueuedRateLimiter(20, time.Minute, 0, 0, func(r *http.Request) (string, error) {
+		return "client", nil
+	})
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := graphql.WithOperationContext(r.Context(), &graphql.OperationContext{Doc: doc, Operation: doc.Operations[0], Variables: map[string]interface{}{}})
+		response = report.InterceptResponse(ctx, func(ctx context.Context) *graphql.Response {
+			return &graphql.Response{}
+		})
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql", nil))
+
+	extension := response.Extensions["rateLimit"].(map[string]interface{})
+	utils.AssertEqual(t, 20, extension["limit"])
+	utils.AssertEqual(t, 19, extension["remaining"])
+	utils.AssertEqual(t, 2, extension["cost"])
+	utils.AssertEqual(t, "2", rec.Header().Get("X-Query-Cost"))
+}
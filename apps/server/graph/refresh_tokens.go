@@ -0,0 +1,31 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	"github.com/google/uuid"
+)
+
+// Issues an access token with the refresh token that continues family, an empty family starts a new session
+// twoFactor says whether the session was signed in with a second factor
+func (r *Resolver) issueTokens(email string, family string, twoFactor bool) (*model.TokenPair, error) {
+	email = strings.ToLower(email)
+	token, err := auth.GenerateSessionToken(email, twoFactor, r.now)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := auth.GenerateRandHexString()
+	if err != nil {
+		return nil, err
+	}
+	if family == "" {
+		family = uuid.NewString()
+	}
+	if err := database.GetRedisDB().SetRefreshToken(refreshToken, database.RefreshToken{Email: email, Family: family, IssuedAt: r.now(), TwoFactor: twoFactor}); err != nil {
+		return nil, err
+	}
+	return &model.TokenPair{Token: token, RefreshToken: refreshToken}, nil
+}
@@ -0,0 +1,43 @@
+package graph
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	"github.com/bananocoin/boompow/libs/utils/clock"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestIssueTokens(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	os.Setenv("PRIV_KEY", "value")
+	defer os.Unsetenv("PRIV_KEY")
+	now := time.Now().Truncate(time.Second)
+	r := &Resolver{Clock: clock.NewFake(now)}
+
+	tokens, err := r.issueTokens("Joe@example.com", "", true)
+	utils.AssertEqual(t, nil, err)
+	parsed, err := auth.ParseSessionToken(tokens.Token, time.Now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "joe@example.com", parsed.Email)
+	utils.AssertEqual(t, now, parsed.IssuedAt)
+	utils.AssertEqual(t, true, parsed.TwoFactor)
+
+	session, err := database.GetRedisDB().ConsumeRefreshToken(tokens.RefreshToken)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "joe@example.com", session.Email)
+	utils.AssertNotEqual(t, "", session.Family)
+	utils.AssertEqual(t, true, session.TwoFactor)
+
+	// Refreshing stays in the same session
+	refreshed, err := r.issueTokens(session.Email, session.Family, session.TwoFactor)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertNotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+	next, err := database.GetRedisDB().ConsumeRefreshToken(refreshed.RefreshToken)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, session.Family, next.Family)
+	utils.AssertEqual(t, true, next.TwoFactor)
+}
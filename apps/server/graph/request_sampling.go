@@ -0,0 +1,163 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/bananocoin/boompow/apps/server/src/sampling"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Records sanitized requests and responses while an admin has sampling turned on, see sampling.Sampler
+type RequestSampling struct {
+	Sampler *sampling.Sampler
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = RequestSampling{}
+
+func (RequestSampling) ExtensionName() string {
+	return "RequestSampling"
+}
+
+func (RequestSampling) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// Refresh picks up sampling set on other servers, and turns it off once it's expired
+func (r RequestSampling) Refresh() error {
+	samplingConfig, err := database.GetRedisDB().GetRequestSampling()
+	if err != nil {
+		return err
+	}
+	r.Sampler.Set(samplingConfig)
+	return nil
+}
+
+func (r RequestSampling) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	oc := graphql.GetOperationContext(ctx)
+	// Subscriptions stream many responses, sampling them would record the whole stream
+	if oc == nil || (oc.Operation != nil && oc.Operation.Operation == ast.Subscription) {
+		return next(ctx)
+	}
+	userEmail := ""
+	if user := middleware.RequestUser(ctx); user != nil {
+		userEmail = user.Email
+	}
+	if !r.Sampler.ShouldSample(userEmail, rand.Float64()*100, time.Now()) {
+		return next(ctx)
+	}
+
+	start := time.Now()
+	response := next(ctx)
+	sample := newSample(oc, userEmail, response, start)
+	if err := database.GetRedisDB().AddRequestSample(sample); err != nil {
+		logging.Errorf(logging.Redis, "Error recording request sample %v", err)
+	}
+	return response
+}
+
+func newSample(oc *graphql.OperationContext, userEmail string, response *graphql.Response, start time.Time) sampling.Sample {
+	sample := sampling.Sample{
+		ID:            uuid.New().String(),
+		UserEmail:     userEmail,
+		OperationName: oc.OperationName,
+		Query:         sampling.SanitizeQuery(oc.RawQuery),
+		Variables:     sanitizeJSON(oc.Variables),
+		DurationMs:    time.Since(start).Milliseconds(),
+		CreatedAt:     start,
+	}
+	if response != nil {
+		var data interface{}
+		if err := json.Unmarshal(response.Data, &data); err == nil {
+			sample.Response = sanitizeJSON(data)
+		}
+		for _, err := range response.Errors {
+			sample.Errors = append(sample.Errors, err.Message)
+		}
+	}
+	return sample
+}
+
+func sanitizeJSON(value interface{}) json.RawMessage {
+	b, err := json.Marshal(sampling.Sanitize(value))
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func requestSamplingToModel(samplingConfig *sampling.Config) *model.RequestSampling {
+	ret := &model.RequestSampling{
+		Percent: samplingConfig.Percent,
+		Until:   utils.GenerateISOString(samplingConfig.Until),
+	}
+	if samplingConfig.UserEmail != "" {
+		ret.UserEmail = &samplingConfig.UserEmail
+	}
+	return ret
+}
+
+func requestSampleToModel(sample sampling.Sample) *model.RequestSample {
+	ret := &model.RequestSample{
+		ID:         sample.ID,
+		Query:      sample.Query,
+		Errors:     sample.Errors,
+		DurationMs: int(sample.DurationMs),
+		CreatedAt:  utils.GenerateISOString(sample.CreatedAt),
+	}
+	if ret.Errors == nil {
+		ret.Errors = []string{}
+	}
+	if sample.UserEmail != "" {
+		ret.UserEmail = &sample.UserEmail
+	}
+	if sample.OperationName != "" {
+		ret.OperationName = &sample.OperationName
+	}
+	if len(sample.Variables) > 0 {
+		variables := string(sample.Variables)
+		ret.Variables = &variables
+	}
+	if len(sample.Response) > 0 {
+		response := string(sample.Response)
+		ret.Response = &response
+	}
+	return ret
+}
+
+func sampleCursor(sample sampling.Sample) pagination.Cursor {
+	return pagination.Cursor{Time: sample.CreatedAt, ID: sample.ID}
+}
+
+// Samples are kept in redis by the millisecond, so they're put in cursor order before paging
+func requestSamplesToModel(samples []sampling.Sample, args pagination.Args) *model.RequestSampleConnection {
+	sort.SliceStable(samples, func(i, j int) bool {
+		return sampleCursor(samples[j]).After(sampleCursor(samples[i]))
+	})
+	page := pagination.Slice(samples, args, sampleCursor)
+	connection := &model.RequestSampleConnection{
+		Nodes:    make([]*model.RequestSample, len(page.Items)),
+		PageInfo: pageInfoToModel(page),
+		Count: func() (int, error) {
+			return len(samples), nil
+		},
+	}
+	for i, sample := range page.Items {
+		connection.Nodes[i] = requestSampleToModel(sample)
+	}
+	return connection
+}
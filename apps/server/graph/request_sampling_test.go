@@ -0,0 +1,56 @@
+package graph
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/bananocoin/boompow/apps/server/src/sampling"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestNewSample(t *testing.T) {
+	oc := &graphql.OperationContext{
+		OperationName: "Login",
+		RawQuery:      `mutation Login($password: String!) { login(input: {email: "joe@example.com", password: $password}) { token } }`,
+		Variables:     map[string]interface{}{"password": "hunter2"},
+	}
+	response := &graphql.Response{
+		Data:   json.RawMessage(`{"login":{"token":"jwt"}}`),
+		Errors: gqlerror.List{{Message: "slow down"}},
+	}
+	sample := newSample(oc, "joe@example.com", response, time.Now())
+
+	utils.AssertEqual(t, `mutation Login($password: String!) { login(input: {email: "[redacted]", password: $password}) { token } }`, sample.Query)
+	utils.AssertEqual(t, `{"password":"[redacted]"}`, string(sample.Variables))
+	utils.AssertEqual(t, `{"login":{"token":"[redacted]"}}`, string(sample.Response))
+	utils.AssertEqual(t, []string{"slow down"}, sample.Errors)
+
+	model := requestSampleToModel(sample)
+	utils.AssertEqual(t, "Login", *model.OperationName)
+	utils.AssertEqual(t, "joe@example.com", *model.UserEmail)
+	utils.AssertEqual(t, `{"login":{"token":"[redacted]"}}`, *model.Response)
+}
+
+func TestRequestSamplesPage(t *testing.T) {
+	at := time.Now()
+	// Same millisecond in redis, the ID breaks the tie
+	samples := []sampling.Sample{{ID: "a", CreatedAt: at}, {ID: "b", CreatedAt: at}, {ID: "c", CreatedAt: at.Add(-time.Second)}}
+	first := 2
+	connection := requestSamplesToModel(samples, pagination.Args{First: first})
+	utils.AssertEqual(t, "b", connection.Nodes[0].ID)
+	utils.AssertEqual(t, "a", connection.Nodes[1].ID)
+	utils.AssertEqual(t, true, connection.PageInfo.HasNextPage)
+	count, err := totalCount(connection.Count)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 3, count)
+
+	args, _ := pagination.ParseArgs(&first, connection.PageInfo.EndCursor)
+	connection = requestSamplesToModel(samples, args)
+	utils.AssertEqual(t, 1, len(connection.Nodes))
+	utils.AssertEqual(t, "c", connection.Nodes[0].ID)
+	utils.AssertEqual(t, false, connection.PageInfo.HasNextPage)
+}
@@ -1,9 +1,18 @@
 package graph
 
 import (
+	"crypto/ed25519"
 	"sync"
+	"time"
 
+	"github.com/bananocoin/boompow/apps/server/src/alerts"
+	"github.com/bananocoin/boompow/apps/server/src/challenge"
+	"github.com/bananocoin/boompow/apps/server/src/geo"
+	"github.com/bananocoin/boompow/apps/server/src/incidents"
+	"github.com/bananocoin/boompow/apps/server/src/maintenance"
 	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/apps/server/src/sampling"
+	"github.com/bananocoin/boompow/libs/utils/clock"
 )
 
 // This file will not be regenerated automatically.
@@ -11,8 +20,63 @@ import (
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	UserRepo    repository.UserRepo
-	WorkRepo    repository.WorkRepo
-	PaymentRepo repository.PaymentRepo
-	PrecacheMap *sync.Map
+	UserRepo        repository.UserRepo
+	WorkRepo        repository.WorkRepo
+	PaymentRepo     repository.PaymentRepo
+	TenantRepo      repository.TenantRepo
+	EventRepo       repository.EventRepo
+	RollupRepo      repository.RollupRepo
+	StatsStore      repository.StatsStore
+	AwardRepo       repository.AwardRateRepo
+	PayoutRepo      repository.PayoutAddressRepo
+	PayoutCycleRepo repository.PayoutCycleRepo
+	// Signs payout reports, they're unsigned if it's nil
+	PayoutReportKey ed25519.PrivateKey
+	// Opt-in hardware benchmarks from clients
+	BenchmarkRepo   repository.BenchmarkRepo
+	AlertRepo       repository.AlertRepo
+	IncidentRepo    repository.IncidentRepo
+	Incidents       *incidents.Manager
+	MaintenanceRepo repository.MaintenanceRepo
+	Maintenance     *maintenance.Schedule
+	UsageRepo       repository.UsageRepo
+	ActivityRepo    repository.ActivityRepo
+	HubPolicyRepo   repository.HubPolicyRepo
+	TwoFactorRepo   repository.TwoFactorRepo
+	// Security events are posted to the webhooks users register, nothing is posted if either is nil
+	SecurityWebhookRepo repository.SecurityWebhookRepo
+	SecurityNotifier    alerts.SecurityNotifier
+	// Security-sensitive events for admins to review
+	AuditLogRepo repository.AuditLogRepo
+	RoleRepo     repository.RoleRepo
+	// Accounts that share a mailbox, for moderators to review
+	CollisionRepo repository.EmailCollisionRepo
+	APIKeyRepo    repository.APIKeyRepo
+	// Prepaid credit and the priority boosts bought with it
+	BoostRepo repository.BoostRepo
+	// Named deployments of requesters with their own quotas and usage
+	WorkSourceRepo repository.WorkSourceRepo
+	// Reports of the cleanup of accounts that never verified their email
+	StaleAccountRepo    repository.StaleAccountRepo
+	CounterSnapshotRepo repository.CounterSnapshotRepo
+	// Top providers per window, refreshed by a background job
+	LeaderboardRepo repository.LeaderboardRepo
+	// Admin edited emails, the built-in ones are sent until a template is saved
+	EmailTemplateRepo repository.EmailTemplateRepo
+	Sampler           *sampling.Sampler
+	// Both nil when challenges are disabled
+	ChallengeVerifier challenge.Verifier
+	PowChallenges     *challenge.PowVerifier
+	PrecacheMap       *sync.Map
+	// Nil when geolocation is off
+	GeoLocator geo.Locator
+	// Wall clock if nil
+	Clock clock.Clock
+}
+
+func (r *Resolver) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+	return r.Clock.Now()
 }
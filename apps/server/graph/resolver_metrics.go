@@ -0,0 +1,44 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/src/metrics"
+)
+
+// Times the top level resolvers for the boompow_graphql_resolver_seconds histogram
+type ResolverMetrics struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = ResolverMetrics{}
+
+func (ResolverMetrics) ExtensionName() string {
+	return "ResolverMetrics"
+}
+
+func (ResolverMetrics) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (ResolverMetrics) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !isRootObject(fc.Object) {
+		return next(ctx)
+	}
+	started := time.Now()
+	res, err := next(ctx)
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	metrics.ResolverSeconds.WithLabelValues(fc.Object, fc.Field.Name, status).Observe(time.Since(started).Seconds())
+	return res, err
+}
+
+func isRootObject(object string) bool {
+	return object == "Query" || object == "Mutation" || object == "Subscription"
+}
@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+)
+
+func userRolesToModel(user *models.User) *model.UserRoles {
+	ret := &model.UserRoles{
+		Email:       user.Email,
+		Roles:       make([]model.AccountRole, len(user.Roles)),
+		Permissions: []model.Permission{},
+	}
+	for i, role := range user.Roles {
+		ret.Roles[i] = model.AccountRole(role.Role)
+	}
+	for _, permission := range models.AllPermissions {
+		if middleware.UserHasPermission(user, permission) {
+			ret.Permissions = append(ret.Permissions, model.Permission(permission))
+		}
+	}
+	return ret
+}
+
+// Moderators and admins, whose roles decide who can ban them
+func privileged(user *models.User) bool {
+	for _, permission := range models.AllPermissions {
+		if middleware.UserHasPermission(user, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// The user whose roles are changed
+func (r *Resolver) roleTarget(email string) (*models.User, error) {
+	lower := strings.ToLower(strings.TrimSpace(email))
+	user, err := r.UserRepo.GetUser(nil, &lower)
+	if err != nil {
+		return nil, errors.New("bad_request:no user with this email")
+	}
+	return user, nil
+}
+
+// Bans go through banUser, so sessions are revoked with them
+func grantableRole(role model.AccountRole) (models.RoleName, error) {
+	if role == model.AccountRoleBanned {
+		return "", errors.New("bad_request:use banUser and unbanUser")
+	}
+	return models.RoleName(role), nil
+}
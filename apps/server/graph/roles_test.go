@@ -0,0 +1,30 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestUserRolesToModel(t *testing.T) {
+	user := &models.User{Email: "mod@example.com", EmailVerified: true, Roles: []models.UserRole{{Role: models.RoleModerator}}}
+	roles := userRolesToModel(user)
+	utils.AssertEqual(t, []model.AccountRole{model.AccountRoleModerator}, roles.Roles)
+	utils.AssertEqual(t, []model.Permission{model.PermissionBanUsers, model.PermissionModerateWorkers}, roles.Permissions)
+	utils.AssertEqual(t, true, privileged(user))
+
+	user = &models.User{Email: "joe@example.com", EmailVerified: true}
+	utils.AssertEqual(t, 0, len(userRolesToModel(user).Permissions))
+	utils.AssertEqual(t, false, privileged(user))
+}
+
+func TestGrantableRole(t *testing.T) {
+	role, err := grantableRole(model.AccountRoleModerator)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, models.RoleModerator, role)
+	// Banning has to revoke sessions too
+	_, err = grantableRole(model.AccountRoleBanned)
+	utils.AssertNotEqual(t, nil, err)
+}
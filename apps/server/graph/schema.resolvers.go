@@ -6,32 +6,102 @@ package graph
 import (
 	"context"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/bananocoin/boompow/apps/server/graph/generated"
 	"github.com/bananocoin/boompow/apps/server/graph/model"
 	"github.com/bananocoin/boompow/apps/server/src/config"
 	"github.com/bananocoin/boompow/apps/server/src/controller"
 	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/email"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
 	"github.com/bananocoin/boompow/apps/server/src/middleware"
 	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/bananocoin/boompow/apps/server/src/payouts"
+	"github.com/bananocoin/boompow/apps/server/src/privacy"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/apps/server/src/sampling"
 	serializableModels "github.com/bananocoin/boompow/libs/models"
 	env "github.com/bananocoin/boompow/libs/utils"
 	"github.com/bananocoin/boompow/libs/utils/auth"
 	utils "github.com/bananocoin/boompow/libs/utils/format"
+	"github.com/bananocoin/boompow/libs/utils/number"
 	"github.com/bananocoin/boompow/libs/utils/validation"
 	"github.com/google/uuid"
 	"golang.org/x/exp/slices"
 	"gorm.io/gorm"
+	klog "k8s.io/klog/v2"
 )
 
+// TotalCount is the resolver for the totalCount field.
+func (r *activityConnectionResolver) TotalCount(ctx context.Context, obj *model.ActivityConnection) (int, error) {
+	return totalCount(obj.Count)
+}
+
+// TotalCount is the resolver for the totalCount field.
+func (r *adminUserConnectionResolver) TotalCount(ctx context.Context, obj *model.AdminUserConnection) (int, error) {
+	return totalCount(obj.Count)
+}
+
+// TotalCount is the resolver for the totalCount field.
+func (r *auditLogConnectionResolver) TotalCount(ctx context.Context, obj *model.AuditLogConnection) (int, error) {
+	return totalCount(obj.Count)
+}
+
+// UnpaidWork is the resolver for the unpaidWork field.
+func (r *getUserResponseResolver) UnpaidWork(ctx context.Context, obj *model.GetUserResponse) (*int, error) {
+	provider := middleware.AuthorizedProvider(ctx)
+	if provider == nil {
+		return nil, nil
+	}
+	unpaid, err := r.unpaidWorkFor(provider)
+	if err != nil {
+		return nil, errors.New("error retrieving unpaid work")
+	}
+	return &unpaid, nil
+}
+
+// PayoutAddresses is the resolver for the payoutAddresses field.
+func (r *getUserResponseResolver) PayoutAddresses(ctx context.Context, obj *model.GetUserResponse) ([]*model.PayoutAddress, error) {
+	provider := middleware.AuthorizedProvider(ctx)
+	if provider == nil {
+		return nil, nil
+	}
+	addresses, err := r.payoutAddressesFor(provider)
+	if err != nil {
+		return nil, errors.New("error retrieving payout addresses")
+	}
+	return payoutAddressesToModel(addresses), nil
+}
+
+// DifficultyRange is the resolver for the difficultyRange field.
+func (r *getUserResponseResolver) DifficultyRange(ctx context.Context, obj *model.GetUserResponse) (*model.DifficultyRange, error) {
+	requester := middleware.AuthorizedRequester(ctx)
+	if requester == nil || !requester.User.HasDifficultyRange() {
+		return nil, nil
+	}
+	tenant, err := r.TenantRepo.GetTenant(requester.User.TenantID)
+	if err != nil {
+		return nil, errors.New("unknown tenant")
+	}
+	return difficultyRangeToModel(requester.User, tenant), nil
+}
+
 // CreateUser is the resolver for the createUser field.
 func (r *mutationResolver) CreateUser(ctx context.Context, input model.UserInput) (*model.User, error) {
 	return nil, errors.New("Registrations disabled")
-	user, err := r.UserRepo.CreateUser(&input, true)
+	if err := r.requireChallenge(ctx); err != nil {
+		return nil, err
+	}
+	user, err := r.UserRepo.CreateUser(&input, middleware.RequestTenant(ctx), true)
 	if err != nil {
 		return nil, err
 	}
@@ -48,50 +118,188 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input model.UserInput
 
 // Login is the resolver for the login field.
 func (r *mutationResolver) Login(ctx context.Context, input model.LoginInput) (*model.LoginResponse, error) {
+	if err := r.requireChallenge(ctx); err != nil {
+		return nil, err
+	}
 	input.Email = strings.ToLower(input.Email)
 	if !slices.Contains(env.GetAllowedEmails(), input.Email) {
 		return nil, errors.New("access denied")
 	}
 
 	user := r.UserRepo.Authenticate(&input)
-	if user == nil {
+	if user == nil || user.TenantID != middleware.RequestTenant(ctx) {
+		r.recordLoginFailure(ctx, input.Email, "invalid email or password")
 		return nil, errors.New("invalid email or password")
 	}
-	token, err := auth.GenerateToken(strings.ToLower(input.Email), time.Now)
+	if user.Banned() {
+		r.recordLoginFailure(ctx, input.Email, "account_banned")
+		return nil, errors.New("account_banned")
+	}
+	if user.Disabled() {
+		return nil, errors.New("account_disabled")
+	}
+	if user.TwoFactorEnabled {
+		if input.TwoFactorCode == nil || *input.TwoFactorCode == "" {
+			return nil, errors.New("two_factor_required")
+		}
+		if !r.TwoFactorRepo.VerifyTwoFactor(user, *input.TwoFactorCode, r.now()) {
+			r.recordLoginFailure(ctx, input.Email, "invalid two factor code")
+			return nil, errors.New("invalid two factor code")
+		}
+	}
+	// The account's own email, it may have logged in with an alias
+	tokens, err := r.issueTokens(user.Email, "", user.TwoFactorEnabled)
 	if err != nil {
 		return nil, err
 	}
-	return &model.LoginResponse{
-		Token:          token,
-		Type:           model.UserType(user.Type),
-		BanAddress:     user.BanAddress,
-		ServiceName:    user.ServiceName,
-		ServiceWebsite: user.ServiceWebsite,
-		EmailVerified:  user.EmailVerified,
-		Email:          user.Email,
-	}, nil
+	r.recordAccountEvent(ctx, user.ID, models.AccountEventLogin, "")
+	r.recordLogin(ctx, user)
+	return loginResponse(user, tokens), nil
 }
 
 // RefreshToken is the resolver for the refreshToken field.
-func (r *mutationResolver) RefreshToken(ctx context.Context, input model.RefreshTokenInput) (string, error) {
-	email, err := auth.ParseToken(input.Token)
+func (r *mutationResolver) RefreshToken(ctx context.Context, input model.RefreshTokenInput) (*model.TokenPair, error) {
+	session, err := database.GetRedisDB().ConsumeRefreshToken(input.RefreshToken)
 	if err != nil {
-		return "", fmt.Errorf("access denied")
+		if errors.Is(err, database.ErrRefreshTokenReused) {
+			logging.Warningf(logging.Auth, "Refresh token reused, revoked its session")
+		} else if !errors.Is(err, database.ErrRefreshTokenInvalid) {
+			logging.Errorf(logging.Auth, "Error consuming refresh token %v", err)
+		}
+		return nil, fmt.Errorf("access denied")
+	}
+	// Revoked sessions can't be extended
+	user, err := r.UserRepo.GetUser(nil, &session.Email)
+	if err != nil || user.Banned() || user.SessionRevoked(session.IssuedAt) {
+		return nil, fmt.Errorf("access denied")
 	}
-	token, err := auth.GenerateToken(strings.ToLower(email), time.Now)
+	return r.issueTokens(session.Email, session.Family, session.TwoFactor)
+}
+
+// EnrollTwoFactor is the resolver for the enrollTwoFactor field.
+func (r *mutationResolver) EnrollTwoFactor(ctx context.Context) (*model.TwoFactorEnrollment, error) {
+	user := middleware.AuthorizedUser(ctx)
+	secret, err := r.TwoFactorRepo.StartTwoFactorEnrollment(user.User)
 	if err != nil {
-		return "", err
+		return nil, twoFactorError(err)
 	}
-	return token, nil
+	return &model.TwoFactorEnrollment{
+		Secret:     secret,
+		OtpauthURL: auth.TOTPURL(config.TOTP_ISSUER, user.User.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor is the resolver for the confirmTwoFactor field.
+func (r *mutationResolver) ConfirmTwoFactor(ctx context.Context, code string) ([]string, error) {
+	user := middleware.AuthorizedUser(ctx)
+	codes, err := r.TwoFactorRepo.ConfirmTwoFactorEnrollment(user.User, code, r.now())
+	if err != nil {
+		return nil, twoFactorError(err)
+	}
+	r.recordAccountEvent(ctx, user.User.ID, models.AccountEventTwoFactorEnabled, "")
+	return codes, nil
+}
+
+// DisableTwoFactor is the resolver for the disableTwoFactor field.
+func (r *mutationResolver) DisableTwoFactor(ctx context.Context, code string) (bool, error) {
+	user := middleware.AuthorizedUser(ctx)
+	if err := r.TwoFactorRepo.DisableTwoFactor(user.User, code, r.now()); err != nil {
+		return false, twoFactorError(err)
+	}
+	r.recordAccountEvent(ctx, user.User.ID, models.AccountEventTwoFactorDisabled, "")
+	return true, nil
+}
+
+// SetSecurityWebhook is the resolver for the setSecurityWebhook field.
+func (r *mutationResolver) SetSecurityWebhook(ctx context.Context, url string) (*model.SecurityWebhookRegistration, error) {
+	user := middleware.AuthorizedUser(ctx)
+	if err := middleware.RequireTwoFactor(ctx); err != nil {
+		return nil, err
+	}
+	webhook, err := r.SecurityWebhookRepo.SetSecurityWebhook(user.User.ID, url)
+	if err != nil {
+		return nil, err
+	}
+	r.recordAccountEvent(ctx, user.User.ID, models.AccountEventSecurityWebhookChanged, webhook.URL)
+	return &model.SecurityWebhookRegistration{URL: webhook.URL, Secret: webhook.Secret}, nil
+}
+
+// DisableSecurityWebhook is the resolver for the disableSecurityWebhook field.
+func (r *mutationResolver) DisableSecurityWebhook(ctx context.Context) (bool, error) {
+	user := middleware.AuthorizedUser(ctx)
+	if err := middleware.RequireTwoFactor(ctx); err != nil {
+		return false, err
+	}
+	// Recorded first, so the webhook learns it's being turned off
+	r.recordAccountEvent(ctx, user.User.ID, models.AccountEventSecurityWebhookChanged, "disabled")
+	if err := r.SecurityWebhookRepo.DeleteSecurityWebhook(user.User.ID); err != nil {
+		return false, errors.New("error disabling security webhook")
+	}
+	return true, nil
+}
+
+// RecoverAccount is the resolver for the recoverAccount field.
+func (r *mutationResolver) RecoverAccount(ctx context.Context, input model.RecoverAccountInput) (*model.LoginResponse, error) {
+	if err := r.requireChallenge(ctx); err != nil {
+		return nil, err
+	}
+	input.Email = strings.ToLower(input.Email)
+	if !slices.Contains(env.GetAllowedEmails(), input.Email) {
+		return nil, errors.New("access denied")
+	}
+
+	user := r.UserRepo.Authenticate(&model.LoginInput{Email: input.Email, Password: input.Password})
+	if user == nil || user.TenantID != middleware.RequestTenant(ctx) {
+		return nil, errors.New("invalid email or password")
+	}
+	// Recovering signs in, so it's refused like Login
+	if user.Banned() {
+		r.recordLoginFailure(ctx, input.Email, "account_banned")
+		return nil, errors.New("account_banned")
+	}
+	if user.Disabled() {
+		return nil, errors.New("account_disabled")
+	}
+	if err := r.TwoFactorRepo.RecoverWithBackupCode(user, input.BackupCode, r.now()); err != nil {
+		return nil, twoFactorError(err)
+	}
+	tokens, err := r.issueTokens(user.Email, "", false)
+	if err != nil {
+		return nil, err
+	}
+	r.recordAccountEvent(ctx, user.ID, models.AccountEventAccountRecovered, "")
+	return loginResponse(user, tokens), nil
+}
+
+// GenerateWebsocketToken is the resolver for the generateWebsocketToken field.
+func (r *mutationResolver) GenerateWebsocketToken(ctx context.Context) (string, error) {
+	user := middleware.AuthorizedUser(ctx)
+	return auth.GenerateScopedToken(strings.ToLower(user.User.Email), auth.WebsocketPurpose, config.WS_TOKEN_VALID_SECONDS*time.Second, r.now)
+}
+
+// SetIncludeWorkTimings is the resolver for the setIncludeWorkTimings field.
+func (r *mutationResolver) SetIncludeWorkTimings(ctx context.Context, enabled bool) (bool, error) {
+	requester := middleware.AuthorizedRequester(ctx)
+	if err := r.UserRepo.SetIncludeWorkTimings(requester.User.ID, enabled); err != nil {
+		return false, err
+	}
+	r.recordAccountEvent(ctx, requester.User.ID, models.AccountEventSettingsChanged, fmt.Sprintf("includeWorkTimings=%t", enabled))
+	return enabled, nil
+}
+
+// SetPayoutStatementEmails is the resolver for the setPayoutStatementEmails field.
+func (r *mutationResolver) SetPayoutStatementEmails(ctx context.Context, enabled bool) (bool, error) {
+	provider := middleware.AuthorizedProvider(ctx)
+	if err := r.UserRepo.SetPayoutStatementEmails(provider.User.ID, enabled); err != nil {
+		return false, err
+	}
+	r.recordAccountEvent(ctx, provider.User.ID, models.AccountEventSettingsChanged, fmt.Sprintf("payoutStatementEmails=%t", enabled))
+	return enabled, nil
 }
 
 // WorkGenerate is the resolver for the workGenerate field.
 func (r *mutationResolver) WorkGenerate(ctx context.Context, input model.WorkGenerateInput) (string, error) {
-	// Require authentication for service
 	requester := middleware.AuthorizedServiceToken(ctx)
-	if requester == nil {
-		return "", fmt.Errorf("access denied")
-	}
 
 	// Check that this request is valid
 	_, err := hex.DecodeString(input.Hash)
@@ -99,49 +307,98 @@ func (r *mutationResolver) WorkGenerate(ctx context.Context, input model.WorkGen
 		return "", errors.New("bad_request:invalid hash")
 	}
 
-	// Alter our difficulty to be in a valid range if it isn't
-	if input.DifficultyMultiplier < 1 {
-		// 1 is NANO receive and banano base difficulty
-		input.DifficultyMultiplier = 1
-	} else if input.DifficultyMultiplier > config.MAX_WORK_DIFFICULTY_MULTIPLIER {
-		input.DifficultyMultiplier = config.MAX_WORK_DIFFICULTY_MULTIPLIER
+	tenant, err := r.TenantRepo.GetTenant(requester.User.TenantID)
+	if err != nil {
+		return "", errors.New("unknown tenant")
 	}
 
-	// First try to retrieve from cache
-	// We only want cached results that meet the required difficulty
-	workResult, err := r.WorkRepo.RetrieveWorkFromCache(input.Hash, input.DifficultyMultiplier)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+	difficultyMultiplier, err := requestedDifficultyMultiplier(input.DifficultyMultiplier, input.Preset, input.Difficulty)
+	if err != nil {
 		return "", err
 	}
-	if workResult != "" {
-		return workResult, nil
+	// Alter our difficulty to be in a valid range if it isn't
+	if difficultyMultiplier < 1 {
+		// 1 is NANO receive and banano base difficulty
+		difficultyMultiplier = 1
+	} else if difficultyMultiplier > tenant.GetMaxDifficultyMultiplier() {
+		difficultyMultiplier = tenant.GetMaxDifficultyMultiplier()
 	}
-
-	workRequest := serializableModels.ClientMessage{
-		RequesterEmail:       requester.User.Email,
-		BlockAward:           input.BlockAward == nil || *input.BlockAward,
-		MessageType:          serializableModels.WorkGenerate,
-		RequestID:            uuid.NewString(),
-		Hash:                 input.Hash,
-		DifficultyMultiplier: input.DifficultyMultiplier,
+	if err := checkDifficultyRange(ctx, requester.User, tenant, difficultyMultiplier); err != nil {
+		return "", err
 	}
+	difficultyMultiplier = r.checkExcessDifficulty(ctx, requester.User.ID, tenant, difficultyMultiplier)
 
-	resp, err := controller.BroadcastWorkRequestAndWait(workRequest)
-	if err != nil {
-		return "", err
+	now := r.now()
+	if window := r.Maintenance.Next(now, config.MAINTENANCE_WARNING_HOURS*time.Hour); window != nil {
+		graphql.RegisterExtension(ctx, "maintenance", maintenanceWindowToModel(window, now))
 	}
 
-	r.PrecacheMap.Store(strings.ToUpper(input.Hash), resp.Result)
+	fingerprint := fmt.Sprintf("%s:%d", strings.ToUpper(input.Hash), difficultyMultiplier)
+	return withIdempotency(ctx, requester.User.ID, fingerprint, func() (string, error) {
+		r.recordRequestCountry(ctx, tenant.ID)
+		if source := middleware.RequestSource(ctx); source != nil {
+			if err := checkSourceQuota(ctx, source, now); err != nil {
+				return "", err
+			}
+		}
+		// First try to retrieve from cache
+		// We only want cached results that meet the required difficulty
+		workResult, err := r.WorkRepo.RetrieveWorkFromCache(tenant.ID, input.Hash, difficultyMultiplier)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+		if workResult != "" {
+			controller.Requests.Count(true, false, r.now())
+			if err := r.UsageRepo.RecordUsage(requester.User.ID, tenant.ID, difficultyMultiplier, true, r.now()); err != nil {
+				logging.Errorf(logging.Stats, "Error recording usage %v", err)
+			}
+			r.recordSourceUsage(ctx, true)
+			return workResult, nil
+		}
+
+		workRequest := serializableModels.ClientMessage{
+			RequesterEmail:       requester.User.Email,
+			BlockAward:           input.BlockAward == nil || *input.BlockAward,
+			MessageType:          serializableModels.WorkGenerate,
+			RequestID:            uuid.NewString(),
+			Hash:                 input.Hash,
+			DifficultyMultiplier: difficultyMultiplier,
+			TenantID:             tenant.ID,
+		}
+
+		broadcast := controller.BroadcastWorkRequestAndWait
+		if database.GetRedisDB().HasPriorityBoost(requester.User.ID) {
+			broadcast = controller.BroadcastBoostedWorkRequestAndWait
+		}
+		resp, timings, err := broadcast(workRequest)
+		var retryLater *controller.RetryLaterError
+		if errors.As(err, &retryLater) {
+			return "", retryLaterError(ctx, retryLater, r.now())
+		}
+		controller.Requests.Count(false, err != nil, r.now())
+		if err != nil {
+			return "", err
+		}
+		r.recordSourceUsage(ctx, false)
+		if requester.User.IncludeWorkTimings {
+			timings.QueueWaitMs = middleware.RateLimitQueueWait(ctx).Milliseconds()
+			graphql.RegisterExtension(ctx, "workTimings", timings)
+		}
+
+		// Precaching is only done by the default pool, which follows the nodes
+		if tenant.ID == config.DEFAULT_TENANT_ID {
+			r.PrecacheMap.Store(strings.ToUpper(input.Hash), resp.Result)
+		}
 
-	return resp.Result, nil
+		return resp.Result, nil
+	})
 }
 
 // GenerateOrGetServiceToken is the resolver for the generateOrGetServiceToken field.
 func (r *mutationResolver) GenerateOrGetServiceToken(ctx context.Context) (string, error) {
-	// Require authentication
 	requester := middleware.AuthorizedRequester(ctx)
-	if requester == nil {
-		return "", fmt.Errorf("access denied")
+	if err := middleware.RequireTwoFactor(ctx); err != nil {
+		return "", err
 	}
 
 	// Get token
@@ -153,14 +410,169 @@ func (r *mutationResolver) GenerateOrGetServiceToken(ctx context.Context) (strin
 		if err := database.GetRedisDB().AddServiceToken(requester.User.ID, token); err != nil {
 			return "", fmt.Errorf("error generating token")
 		}
+		r.recordAccountEvent(ctx, requester.User.ID, models.AccountEventServiceTokenCreated, "")
 	}
 
 	return token, nil
 }
 
+// GenerateAPIKey is the resolver for the generateApiKey field.
+func (r *mutationResolver) GenerateAPIKey(ctx context.Context, input model.GenerateAPIKeyInput) (*model.GeneratedAPIKey, error) {
+	requester := middleware.AuthorizedRequester(ctx)
+	if err := middleware.RequireTwoFactor(ctx); err != nil {
+		return nil, err
+	}
+	name, err := validateAPIKeyInput(input)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, key, err := r.APIKeyRepo.CreateAPIKey(requester.User.ID, name, input.RateLimitPerMinute)
+	if err != nil {
+		return nil, apiKeyError(err)
+	}
+	r.recordAccountEvent(ctx, requester.User.ID, models.AccountEventServiceTokenCreated, name)
+	return &model.GeneratedAPIKey{Key: key, APIKey: apiKeyToModel(apiKey)}, nil
+}
+
+// RevokeAPIKey is the resolver for the revokeApiKey field.
+func (r *mutationResolver) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	requester := middleware.AuthorizedRequester(ctx)
+	keyID, err := uuid.Parse(id)
+	if err != nil {
+		return false, errors.New("bad_request:invalid id")
+	}
+	revoked, err := r.APIKeyRepo.RevokeAPIKey(requester.User.ID, keyID)
+	if err != nil {
+		return false, errors.New("error revoking api key")
+	}
+	if revoked {
+		r.recordAccountEvent(ctx, requester.User.ID, models.AccountEventAPIKeyRevoked, id)
+	}
+	return revoked, nil
+}
+
+// CreateWorkSource is the resolver for the createWorkSource field.
+func (r *mutationResolver) CreateWorkSource(ctx context.Context, name string, dailyQuota *int) (*model.WorkSource, error) {
+	requester := middleware.AuthorizedRequester(ctx)
+	name, err := validateWorkSourceInput(name, dailyQuota)
+	if err != nil {
+		return nil, err
+	}
+	source, err := r.WorkSourceRepo.CreateWorkSource(requester.User.ID, name, dailyQuota)
+	if err != nil {
+		return nil, workSourceError(err)
+	}
+	return workSourceToModel(source, 0), nil
+}
+
+// SetWorkSourceQuota is the resolver for the setWorkSourceQuota field.
+func (r *mutationResolver) SetWorkSourceQuota(ctx context.Context, name string, dailyQuota *int) (*model.WorkSource, error) {
+	requester := middleware.AuthorizedRequester(ctx)
+	name, err := validateWorkSourceInput(name, dailyQuota)
+	if err != nil {
+		return nil, err
+	}
+	source, err := r.WorkSourceRepo.SetWorkSourceQuota(requester.User.ID, name, dailyQuota)
+	if err != nil {
+		return nil, errors.New("error updating work source")
+	}
+	if source == nil {
+		return nil, errors.New("bad_request:no such work source")
+	}
+	return r.workSourceToModel(source), nil
+}
+
+// DeleteWorkSource is the resolver for the deleteWorkSource field.
+func (r *mutationResolver) DeleteWorkSource(ctx context.Context, name string) (bool, error) {
+	requester := middleware.AuthorizedRequester(ctx)
+	deleted, err := r.WorkSourceRepo.DeleteWorkSource(requester.User.ID, strings.TrimSpace(name))
+	if err != nil {
+		return false, errors.New("error deleting work source")
+	}
+	return deleted, nil
+}
+
+// PurchasePriorityBoost is the resolver for the purchasePriorityBoost field.
+func (r *mutationResolver) PurchasePriorityBoost(ctx context.Context, minutes int) (*model.PriorityBoost, error) {
+	requester := middleware.AuthorizedRequester(ctx)
+	pricing := boostPricing()
+	if err := validateBoostMinutes(minutes, pricing); err != nil {
+		return nil, err
+	}
+	boost, err := r.BoostRepo.PurchaseBoost(requester.User, minutes, pricing, r.now())
+	if err != nil {
+		return nil, boostError(err, pricing)
+	}
+	// Boosts run back to back, so the requester is boosted until the last one ends
+	if err := database.GetRedisDB().SetPriorityBoost(requester.User.ID, boost.EndsAt); err != nil {
+		logging.Errorf(logging.Redis, "Error setting priority boost of %s %v", requester.User.Email, err)
+	}
+	r.recordAccountEvent(ctx, requester.User.ID, models.AccountEventSettingsChanged, fmt.Sprintf("priorityBoost=%dm", minutes))
+	return priorityBoostToModel(boost), nil
+}
+
+// SubmitWork is the resolver for the submitWork field.
+func (r *mutationResolver) SubmitWork(ctx context.Context, input model.SubmitWorkInput) (bool, error) {
+	requester := middleware.AuthorizedServiceToken(ctx)
+	if !slices.Contains(env.GetWorkSubmitters(), strings.ToLower(requester.User.Email)) {
+		return false, errors.New("access denied")
+	}
+
+	if err := checkWorkInput(input.Hash, input.Work, input.DifficultyMultiplier); err != nil {
+		return false, err
+	}
+	if !validation.IsWorkValid(input.Hash, input.DifficultyMultiplier, input.Work) {
+		return false, errors.New("bad_request:work is not valid for this hash and difficulty")
+	}
+
+	return r.WorkRepo.SubmitWork(requester.User, input.Hash, input.Work, input.DifficultyMultiplier)
+}
+
+// RegisterFrontiers is the resolver for the registerFrontiers field.
+func (r *mutationResolver) RegisterFrontiers(ctx context.Context, input model.RegisterFrontiersInput) (int, error) {
+	requester := middleware.AuthorizedServiceToken(ctx)
+
+	if len(input.Hashes) == 0 || len(input.Hashes) > config.FRONTIER_REGISTRATION_MAX {
+		return 0, fmt.Errorf("bad_request:between 1 and %d hashes can be registered at once", config.FRONTIER_REGISTRATION_MAX)
+	}
+	tenant, err := r.TenantRepo.GetTenant(requester.User.TenantID)
+	if err != nil {
+		return 0, errors.New("unknown tenant")
+	}
+	difficultyMultiplier, err := requestedDifficultyMultiplier(input.DifficultyMultiplier, input.Preset, input.Difficulty)
+	if err != nil {
+		return 0, err
+	}
+	if input.DifficultyMultiplier == nil && input.Preset == nil {
+		difficultyMultiplier = 1
+	}
+	if difficultyMultiplier < 1 || difficultyMultiplier > tenant.GetMaxDifficultyMultiplier() {
+		return 0, fmt.Errorf("bad_request:difficultyMultiplier must be between 1 and %d", tenant.GetMaxDifficultyMultiplier())
+	}
+	if err := checkDifficultyRange(ctx, requester.User, tenant, difficultyMultiplier); err != nil {
+		return 0, err
+	}
+
+	tasks := make([]database.FrontierTask, len(input.Hashes))
+	for i, hash := range input.Hashes {
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != 64 {
+			return 0, fmt.Errorf("bad_request:invalid hash %s", hash)
+		}
+		tasks[i] = database.FrontierTask{Hash: strings.ToUpper(hash), DifficultyMultiplier: difficultyMultiplier, RequesterEmail: requester.User.Email}
+	}
+	size, err := database.GetRedisDB().AddFrontiers(tenant.ID, tasks, r.now())
+	if err != nil {
+		return 0, err
+	}
+	return int(size), nil
+}
+
 // ResetPassword is the resolver for the resetPassword field.
 func (r *mutationResolver) ResetPassword(ctx context.Context, input model.ResetPasswordInput) (bool, error) {
 	return false, errors.New("Password reset disabled")
+	if err := r.requireChallenge(ctx); err != nil {
+		return false, err
+	}
 	r.UserRepo.GenerateResetPasswordRequest(&input, true)
 
 	return true, nil
@@ -169,6 +581,9 @@ func (r *mutationResolver) ResetPassword(ctx context.Context, input model.ResetP
 // ResendConfirmationEmail is the resolver for the resendConfirmationEmail field.
 func (r *mutationResolver) ResendConfirmationEmail(ctx context.Context, input model.ResendConfirmationEmailInput) (bool, error) {
 	return false, errors.New("Email confirmation disabled")
+	if err := r.requireChallenge(ctx); err != nil {
+		return false, err
+	}
 	u, err := r.UserRepo.GetUser(nil, &input.Email)
 	if err != nil {
 		return false, errors.New("User does not exist")
@@ -186,11 +601,7 @@ func (r *mutationResolver) ResendConfirmationEmail(ctx context.Context, input mo
 // SendConfirmationEmail is the resolver for the sendConfirmationEmail field.
 func (r *mutationResolver) SendConfirmationEmail(ctx context.Context) (bool, error) {
 	return false, errors.New("Email confirmation disabled")
-	// Require authentication
 	user := middleware.AuthorizedUser(ctx)
-	if user == nil {
-		return false, fmt.Errorf("access denied")
-	}
 
 	if user.User.EmailVerified {
 		return false, fmt.Errorf("already verified")
@@ -206,11 +617,7 @@ func (r *mutationResolver) SendConfirmationEmail(ctx context.Context) (bool, err
 // ChangePassword is the resolver for the changePassword field.
 func (r *mutationResolver) ChangePassword(ctx context.Context, input model.ChangePasswordInput) (bool, error) {
 	return false, errors.New("Password reset disabled")
-	// Require authentication for service
 	requester := middleware.AuthorizedChangePassword(ctx)
-	if requester == nil {
-		return false, fmt.Errorf("access denied")
-	}
 
 	// Check that the password is valid
 	err := validation.ValidatePassword(input.NewPassword)
@@ -218,56 +625,1476 @@ func (r *mutationResolver) ChangePassword(ctx context.Context, input model.Chang
 		return false, err
 	}
 
-	// Is valid so update it
-	if err := r.UserRepo.ChangePassword(requester.User.Email, &input); err == nil {
-		return true, nil
+	// The link is used up before the change so concurrent requests with it can't both change the password, it's put back if the change fails
+	email := strings.ToLower(requester.User.Email)
+	ttl, err := database.GetRedisDB().ConsumeResetPasswordToken(email, requester.ResetPasswordToken)
+	if err != nil {
+		return false, err
+	}
+	if err := r.UserRepo.ChangePassword(requester.User.Email, &input); err != nil {
+		if err := database.GetRedisDB().RestoreResetPasswordToken(email, requester.ResetPasswordToken, ttl); err != nil {
+			logging.Errorf(logging.Auth, "Error restoring reset password link %v", err)
+		}
+		return false, err
+	}
+	r.recordAccountEvent(ctx, requester.User.ID, models.AccountEventPasswordChanged, "")
+	return true, nil
+}
+
+// SetPayoutAddresses is the resolver for the setPayoutAddresses field.
+func (r *mutationResolver) SetPayoutAddresses(ctx context.Context, input []*model.PayoutAddressInput) ([]*model.PayoutAddress, error) {
+	provider := middleware.AuthorizedProvider(ctx)
+	if err := middleware.RequireTwoFactor(ctx); err != nil {
+		return nil, err
 	}
 
-	return false, err
+	splits := make([]repository.PayoutSplit, len(input))
+	for i, address := range input {
+		splits[i] = repository.PayoutSplit{
+			BanAddress: address.BanAddress,
+			Percent:    address.Percent,
+		}
+	}
+	addresses, err := r.PayoutRepo.SetPayoutAddresses(provider.User.ID, splits)
+	if err != nil {
+		return nil, err
+	}
+	r.recordAccountEvent(ctx, provider.User.ID, models.AccountEventPayoutAddressesChanged, fmt.Sprintf("%d addresses", len(addresses)))
+	return payoutAddressesToModel(addresses), nil
 }
 
-// VerifyEmail is the resolver for the verifyEmail field.
-func (r *queryResolver) VerifyEmail(ctx context.Context, input model.VerifyEmailInput) (bool, error) {
-	return false, errors.New("Email confirmation disabled")
-	return r.UserRepo.VerifyEmailToken(&input)
+// SubmitBenchmark is the resolver for the submitBenchmark field.
+func (r *mutationResolver) SubmitBenchmark(ctx context.Context, input model.BenchmarkInput) (bool, error) {
+	provider := middleware.AuthorizedProvider(ctx)
+
+	submission := repository.BenchmarkSubmission{
+		Hardware:             input.Hardware,
+		Backend:              input.Backend.String(),
+		DifficultyMultiplier: input.DifficultyMultiplier,
+		Runs:                 input.Runs,
+		AverageSeconds:       input.AverageSeconds,
+	}
+	if input.ClientVersion != nil {
+		submission.ClientVersion = *input.ClientVersion
+	}
+	if _, err := r.BenchmarkRepo.SubmitBenchmark(provider.User.ID, submission); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// VerifyService is the resolver for the verifyService field.
-func (r *queryResolver) VerifyService(ctx context.Context, input model.VerifyServiceInput) (bool, error) {
-	return false, errors.New("Service verification disabled")
-	return r.UserRepo.VerifyService(&input)
+// SetOfflineAlert is the resolver for the setOfflineAlert field.
+func (r *mutationResolver) SetOfflineAlert(ctx context.Context, input model.OfflineAlertInput) (*model.OfflineAlert, error) {
+	provider := middleware.AuthorizedProvider(ctx)
+
+	target := ""
+	if input.Target != nil {
+		target = *input.Target
+	}
+	alert, err := r.AlertRepo.SetOfflineAlert(provider.User.ID, models.AlertChannel(input.Channel), target, input.AfterMinutes)
+	if err != nil {
+		return nil, err
+	}
+	r.recordAccountEvent(ctx, provider.User.ID, models.AccountEventOfflineAlertChanged, fmt.Sprintf("%s after %d minutes", alert.Channel, alert.AfterMinutes))
+	return offlineAlertToModel(alert), nil
 }
 
-// GetUser is the resolver for the getUser field.
-func (r *queryResolver) GetUser(ctx context.Context) (*model.GetUserResponse, error) {
-	// Require authentication
-	user := middleware.AuthorizedUser(ctx)
-	if user == nil {
-		return nil, fmt.Errorf("access denied")
+// DisableOfflineAlert is the resolver for the disableOfflineAlert field.
+func (r *mutationResolver) DisableOfflineAlert(ctx context.Context) (bool, error) {
+	provider := middleware.AuthorizedProvider(ctx)
+
+	if err := r.AlertRepo.DeleteOfflineAlert(provider.User.ID); err != nil {
+		return false, errors.New("error disabling offline alert")
 	}
-	return &model.GetUserResponse{
-		Type:           model.UserType(user.User.Type),
-		BanAddress:     user.User.BanAddress,
-		ServiceName:    user.User.ServiceName,
-		ServiceWebsite: user.User.ServiceWebsite,
-		EmailVerified:  user.User.EmailVerified,
-		Email:          user.User.Email,
-		CanRequestWork: user.User.CanRequestWork,
-	}, nil
+	r.recordAccountEvent(ctx, provider.User.ID, models.AccountEventOfflineAlertChanged, "disabled")
+	return true, nil
 }
 
-// Stats is the resolver for the stats field.
-func (r *subscriptionResolver) Stats(ctx context.Context) (<-chan *model.Stats, error) {
-	msgs := make(chan *model.Stats, 1)
+// ScheduleAwardRate is the resolver for the scheduleAwardRate field.
+func (r *mutationResolver) ScheduleAwardRate(ctx context.Context, input model.ScheduleAwardRateInput) (*model.AwardRate, error) {
+	admin := middleware.AuthorizedUser(ctx)
 
-	// Pub stats every 10 seconds
-	go func() {
-		for {
-			stats := models.GetStatsInstance().Stats
-			if stats == nil {
-				stats = &model.Stats{
-					ConnectedWorkers:       -1,
-					TotalPaidBanano:        "N/A",
+	effectiveAt, err := time.Parse(time.RFC3339, input.EffectiveAt)
+	if err != nil {
+		return nil, errors.New("bad_request:effectiveAt must be an ISO 8601 timestamp")
+	}
+	tenantID := admin.User.TenantID
+	if input.TenantID != nil {
+		tenant, err := r.TenantRepo.GetTenant(*input.TenantID)
+		if err != nil {
+			return nil, errors.New("unknown tenant")
+		}
+		tenantID = tenant.ID
+	}
+
+	rate, err := r.AwardRepo.ScheduleAwardRate(tenantID, input.BananoPerUnit, effectiveAt, admin.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	return awardRateToModel(rate), nil
+}
+
+// RecordCreditDeposit is the resolver for the recordCreditDeposit field.
+func (r *mutationResolver) RecordCreditDeposit(ctx context.Context, email string, amountBanano float64, blockHash string) (*model.CreditEntry, error) {
+	admin := middleware.AuthorizedUser(ctx)
+	amount, hash, err := validateCreditDeposit(amountBanano, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	lower := strings.ToLower(strings.TrimSpace(email))
+	user, err := r.UserRepo.GetUser(nil, &lower)
+	if err != nil || user.Type != models.REQUESTER {
+		return nil, errors.New("bad_request:no requester with this email")
+	}
+	entry, err := r.BoostRepo.RecordDeposit(user.ID, amount.String(), hash, admin.User.ID)
+	if errors.Is(err, repository.ErrDepositRecorded) {
+		return nil, errors.New("bad_request:this block was already credited")
+	}
+	if err != nil {
+		return nil, errors.New("error recording deposit")
+	}
+	klog.Infof("Deposit %s of %s BAN credited to %s by %s", hash, number.FormatRaw(amount, 2), user.Email, admin.User.Email)
+	return creditEntryToModel(entry), nil
+}
+
+// ReconcileConnectedClients is the resolver for the reconcileConnectedClients field.
+func (r *mutationResolver) ReconcileConnectedClients(ctx context.Context) (int, error) {
+	return controller.ActiveHub.ReconcileConnectedClients()
+}
+
+// CheckStatsConsistency is the resolver for the checkStatsConsistency field.
+func (r *mutationResolver) CheckStatsConsistency(ctx context.Context, correct bool) ([]*model.StatsDrift, error) {
+	if _, ok := r.StatsStore.(*repository.PostgresStatsStore); !ok {
+		return nil, errors.New("Difficulty rollups are only kept with the postgres stats store")
+	}
+	drifts, err := repository.CheckStatsConsistency(r.RollupRepo, correct, r.now())
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*model.StatsDrift, len(drifts))
+	for i, drift := range drifts {
+		ret[i] = &model.StatsDrift{
+			Hour:                 utils.GenerateISOString(drift.Hour),
+			TenantID:             drift.TenantID,
+			DifficultyMultiplier: drift.DifficultyMultiplier,
+			Rollup:               int(drift.Rollup),
+			Actual:               int(drift.Actual),
+			Corrected:            drift.Corrected,
+		}
+	}
+	return ret, nil
+}
+
+// PreferServer is the resolver for the preferServer field.
+func (r *mutationResolver) PreferServer(ctx context.Context, url string) (bool, error) {
+	bytes, err := json.Marshal(serializableModels.ClientMessage{
+		MessageType: serializableModels.PreferServer,
+		ServerURL:   url,
+	})
+	if err != nil {
+		return false, err
+	}
+	tenants, err := r.TenantRepo.GetAllTenants()
+	if err != nil {
+		return false, err
+	}
+	for _, tenant := range tenants {
+		controller.ActiveHub.Broadcast <- controller.BroadcastMessage{TenantID: tenant.ID, Msg: bytes, Feature: serializableModels.FeaturePreferServer}
+	}
+	return true, nil
+}
+
+// DeclareIncident is the resolver for the declareIncident field.
+func (r *mutationResolver) DeclareIncident(ctx context.Context, input model.DeclareIncidentInput) (*model.Incident, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+
+	description := ""
+	if input.Description != nil {
+		description = *input.Description
+	}
+	incident, err := r.Incidents.Declare(input.Title, description, models.IncidentSeverity(input.Severity), admin.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	return incidentToModel(incident), nil
+}
+
+// ResolveIncident is the resolver for the resolveIncident field.
+func (r *mutationResolver) ResolveIncident(ctx context.Context, id string) (*model.Incident, error) {
+	incidentID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("bad_request:invalid incident ID")
+	}
+	incident, err := r.Incidents.Resolve(incidentID)
+	if err != nil {
+		return nil, err
+	}
+	return incidentToModel(incident), nil
+}
+
+// ScheduleMaintenance is the resolver for the scheduleMaintenance field.
+func (r *mutationResolver) ScheduleMaintenance(ctx context.Context, input model.MaintenanceWindowInput) (*model.MaintenanceWindow, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+
+	startsAt, err := time.Parse(time.RFC3339, input.StartsAt)
+	if err != nil {
+		return nil, errors.New("bad_request:startsAt must be an ISO 8601 timestamp")
+	}
+	endsAt, err := time.Parse(time.RFC3339, input.EndsAt)
+	if err != nil {
+		return nil, errors.New("bad_request:endsAt must be an ISO 8601 timestamp")
+	}
+	window := &models.MaintenanceWindow{
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		CreatedBy: admin.User.ID,
+	}
+	if input.Description != nil {
+		window.Description = *input.Description
+	}
+	if err := r.MaintenanceRepo.CreateMaintenanceWindow(window); err != nil {
+		return nil, err
+	}
+	now := r.now()
+	if err := r.Maintenance.Refresh(now); err != nil {
+		klog.Errorf("Error refreshing maintenance windows %v", err)
+	}
+	return maintenanceWindowToModel(window, now), nil
+}
+
+// CancelMaintenance is the resolver for the cancelMaintenance field.
+func (r *mutationResolver) CancelMaintenance(ctx context.Context, id string) (bool, error) {
+	windowID, err := uuid.Parse(id)
+	if err != nil {
+		return false, errors.New("bad_request:invalid maintenance window ID")
+	}
+	if err := r.MaintenanceRepo.CancelMaintenanceWindow(windowID); err != nil {
+		return false, err
+	}
+	if err := r.Maintenance.Refresh(r.now()); err != nil {
+		klog.Errorf("Error refreshing maintenance windows %v", err)
+	}
+	return true, nil
+}
+
+// SetLogLevel is the resolver for the setLogLevel field.
+func (r *mutationResolver) SetLogLevel(ctx context.Context, subsystem model.LogSubsystem, level model.LogLevel, minutes *int) (*model.SubsystemLogLevel, error) {
+	logLevel, err := logging.ParseLevel(level.String())
+	if err != nil {
+		return nil, errors.New("bad_request:invalid log level")
+	}
+	sub := logging.Subsystem(strings.ToLower(subsystem.String()))
+	if minutes != nil {
+		if *minutes < 1 {
+			return nil, errors.New("bad_request:minutes must be at least 1")
+		}
+		err = logging.SetLevelFor(sub, logLevel, time.Duration(*minutes)*time.Minute)
+	} else {
+		err = logging.SetLevel(sub, logLevel)
+	}
+	if err != nil {
+		return nil, errors.New("bad_request:invalid subsystem")
+	}
+	klog.Infof("Log level for %s set to %s", sub, logLevel)
+	return subsystemLogLevelToModel(sub), nil
+}
+
+// SetHubPolicy is the resolver for the setHubPolicy field.
+func (r *mutationResolver) SetHubPolicy(ctx context.Context, input model.HubPolicyInput) (*model.HubPolicy, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+
+	current := controller.Policy()
+	policy := &models.HubPolicy{
+		TimeoutSeconds:            input.TimeoutSeconds,
+		Retries:                   input.Retries,
+		MaxInFlightPerWorker:      input.MaxInFlightPerWorker,
+		OnDemandWeight:            input.OnDemandWeight,
+		PrecacheWeight:            input.PrecacheWeight,
+		ExclusionSharePercent:     input.ExclusionSharePercent,
+		ExclusionMinClients:       input.ExclusionMinClients,
+		IdlePrecacheSeconds:       current.IdlePrecacheSeconds,
+		IdlePrecacheCreditPercent: current.IdlePrecacheCreditPercent,
+		LateResultGraceSeconds:    current.LateResultGraceSeconds,
+		ProgressExtensionSeconds:  current.ProgressExtensionSeconds,
+		TargetedDifficulty:        current.TargetedDifficulty,
+		SolveSLASeconds:           current.SolveSLASeconds,
+		UpdatedBy:                 admin.User.ID,
+	}
+	if input.IdlePrecacheSeconds != nil {
+		policy.IdlePrecacheSeconds = *input.IdlePrecacheSeconds
+	}
+	if input.IdlePrecacheCreditPercent != nil {
+		policy.IdlePrecacheCreditPercent = *input.IdlePrecacheCreditPercent
+	}
+	if input.LateResultGraceSeconds != nil {
+		policy.LateResultGraceSeconds = *input.LateResultGraceSeconds
+	}
+	if input.ProgressExtensionSeconds != nil {
+		policy.ProgressExtensionSeconds = *input.ProgressExtensionSeconds
+	}
+	if input.TargetedDifficulty != nil {
+		policy.TargetedDifficulty = *input.TargetedDifficulty
+	}
+	if input.SolveSLASeconds != nil {
+		policy.SolveSLASeconds = *input.SolveSLASeconds
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("bad_request:%s", err.Error())
+	}
+	if err := r.HubPolicyRepo.SaveHubPolicy(policy); err != nil {
+		return nil, err
+	}
+	controller.SetPolicy(*policy)
+	klog.Infof("Hub policy updated by %s: %+v", admin.User.Email, *policy)
+	return hubPolicyToModel(policy), nil
+}
+
+// ReleaseWorkerQuarantine is the resolver for the releaseWorkerQuarantine field.
+func (r *mutationResolver) ReleaseWorkerQuarantine(ctx context.Context, ipAddress string) (bool, error) {
+	moderator := middleware.AuthorizedUser(ctx)
+	released := controller.Quarantine.Release(ipAddress)
+	if released {
+		klog.Infof("Worker %s released from quarantine by %s", ipAddress, moderator.User.Email)
+	}
+	return released, nil
+}
+
+// SetRequesterDifficultyRange is the resolver for the setRequesterDifficultyRange field.
+func (r *mutationResolver) SetRequesterDifficultyRange(ctx context.Context, email string, min *int, max *int) (*model.DifficultyRange, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+	lower := strings.ToLower(strings.TrimSpace(email))
+	user, err := r.UserRepo.GetUser(nil, &lower)
+	if err != nil || user.Type != models.REQUESTER {
+		return nil, errors.New("bad_request:no requester with this email")
+	}
+	tenant, err := r.TenantRepo.GetTenant(user.TenantID)
+	if err != nil {
+		return nil, errors.New("unknown tenant")
+	}
+	tenantMax := tenant.GetMaxDifficultyMultiplier()
+	for _, bound := range []*int{min, max} {
+		if bound != nil && (*bound < 1 || *bound > tenantMax) {
+			return nil, fmt.Errorf("bad_request:min and max must be between 1 and %d", tenantMax)
+		}
+	}
+	if min != nil && max != nil && *min > *max {
+		return nil, errors.New("bad_request:min can't be above max")
+	}
+	user.MinDifficultyMultiplier, user.MaxDifficultyMultiplier = 0, 0
+	if min != nil {
+		user.MinDifficultyMultiplier = *min
+	}
+	if max != nil {
+		user.MaxDifficultyMultiplier = *max
+	}
+	if err := r.UserRepo.SetDifficultyRange(user.ID, user.MinDifficultyMultiplier, user.MaxDifficultyMultiplier); err != nil {
+		return nil, err
+	}
+	klog.Infof("Difficulty range of %s set to %d-%d by %s", user.Email, user.MinDifficultyMultiplier, user.MaxDifficultyMultiplier, admin.User.Email)
+	return difficultyRangeToModel(user, tenant), nil
+}
+
+// SetUserRateLimit is the resolver for the setUserRateLimit field.
+func (r *mutationResolver) SetUserRateLimit(ctx context.Context, email string, requestsPerMinute *int) (*int, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+	if requestsPerMinute != nil && *requestsPerMinute < 0 {
+		return nil, errors.New("bad_request:requestsPerMinute can't be negative")
+	}
+	lower := strings.ToLower(strings.TrimSpace(email))
+	user, err := r.UserRepo.GetUser(nil, &lower)
+	if err != nil {
+		return nil, errors.New("bad_request:no user with this email")
+	}
+	if err := r.UserRepo.SetRateLimit(user.ID, requestsPerMinute); err != nil {
+		return nil, err
+	}
+	if requestsPerMinute == nil {
+		klog.Infof("Rate limit override of %s cleared by %s", user.Email, admin.User.Email)
+	} else {
+		klog.Infof("Rate limit of %s set to %d per minute by %s", user.Email, *requestsPerMinute, admin.User.Email)
+	}
+	return requestsPerMinute, nil
+}
+
+// SaveEmailTemplate is the resolver for the saveEmailTemplate field.
+func (r *mutationResolver) SaveEmailTemplate(ctx context.Context, input model.EmailTemplateInput) (*model.EmailTemplate, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+	language, err := emailTemplateLanguage(input.Language)
+	if err != nil {
+		return nil, err
+	}
+	// Templates that wouldn't send aren't saved
+	if _, _, err := email.Preview(input.Name, input.Subject, input.Body); err != nil {
+		return nil, fmt.Errorf("bad_request:%s", err.Error())
+	}
+	template := &models.EmailTemplate{
+		Name:      input.Name,
+		Language:  language,
+		Subject:   input.Subject,
+		Body:      input.Body,
+		CreatedBy: admin.User.ID,
+	}
+	if err := r.EmailTemplateRepo.SaveEmailTemplate(template); err != nil {
+		return nil, err
+	}
+	klog.Infof("Email template %s (%s) version %d saved by %s", template.Name, template.Language, template.Version, admin.User.Email)
+	return emailTemplateToModel(template), nil
+}
+
+// RestoreEmailTemplate is the resolver for the restoreEmailTemplate field.
+func (r *mutationResolver) RestoreEmailTemplate(ctx context.Context, name string, language string, version int) (*model.EmailTemplate, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+	old, err := r.EmailTemplateRepo.GetEmailTemplateVersion(name, language, version)
+	if err != nil {
+		return nil, err
+	}
+	template := &models.EmailTemplate{
+		Name:      old.Name,
+		Language:  old.Language,
+		Subject:   old.Subject,
+		Body:      old.Body,
+		CreatedBy: admin.User.ID,
+	}
+	if err := r.EmailTemplateRepo.SaveEmailTemplate(template); err != nil {
+		return nil, err
+	}
+	klog.Infof("Email template %s (%s) version %d restored as version %d by %s", template.Name, template.Language, version, template.Version, admin.User.Email)
+	return emailTemplateToModel(template), nil
+}
+
+// SetRequestSampling is the resolver for the setRequestSampling field.
+func (r *mutationResolver) SetRequestSampling(ctx context.Context, input model.RequestSamplingInput) (*model.RequestSampling, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+	if input.Percent <= 0 || input.Percent > 100 {
+		return nil, errors.New("bad_request:percent must be above 0 and at most 100")
+	}
+	if input.Minutes < 1 || input.Minutes > config.REQUEST_SAMPLING_MAX_MINUTES {
+		return nil, fmt.Errorf("bad_request:minutes must be between 1 and %d", config.REQUEST_SAMPLING_MAX_MINUTES)
+	}
+
+	samplingConfig := sampling.Config{
+		Percent: input.Percent,
+		Until:   r.now().Add(time.Duration(input.Minutes) * time.Minute),
+	}
+	if input.UserEmail != nil {
+		samplingConfig.UserEmail = strings.ToLower(strings.TrimSpace(*input.UserEmail))
+	}
+	if err := database.GetRedisDB().SetRequestSampling(samplingConfig); err != nil {
+		return nil, err
+	}
+	r.Sampler.Set(&samplingConfig)
+	klog.Infof("Request sampling enabled by %s: %+v", admin.User.Email, samplingConfig)
+	return requestSamplingToModel(&samplingConfig), nil
+}
+
+// DisableRequestSampling is the resolver for the disableRequestSampling field.
+func (r *mutationResolver) DisableRequestSampling(ctx context.Context) (bool, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+	if _, err := database.GetRedisDB().DeleteRequestSampling(); err != nil {
+		return false, err
+	}
+	r.Sampler.Set(nil)
+	klog.Infof("Request sampling disabled by %s", admin.User.Email)
+	return true, nil
+}
+
+// ShedLoad is the resolver for the shedLoad field.
+func (r *mutationResolver) ShedLoad(ctx context.Context, input model.LoadSheddingInput) (*model.LoadShedding, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+	sheddingConfig, err := loadSheddingFromInput(input, admin.User.Email, r.now())
+	if err != nil {
+		return nil, err
+	}
+	if err := database.GetRedisDB().SetLoadShedding(sheddingConfig); err != nil {
+		return nil, err
+	}
+	controller.Shedder.Set(&sheddingConfig)
+	klog.Infof("Load shedding started by %s: %+v", admin.User.Email, sheddingConfig)
+	return loadSheddingToModel(&sheddingConfig), nil
+}
+
+// StopLoadShedding is the resolver for the stopLoadShedding field.
+func (r *mutationResolver) StopLoadShedding(ctx context.Context) (bool, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+	if _, err := database.GetRedisDB().DeleteLoadShedding(); err != nil {
+		return false, err
+	}
+	controller.Shedder.Set(nil)
+	klog.Infof("Load shedding stopped by %s", admin.User.Email)
+	return true, nil
+}
+
+// BanUser is the resolver for the banUser field.
+func (r *mutationResolver) BanUser(ctx context.Context, email string) (bool, error) {
+	moderator := middleware.AuthorizedUser(ctx)
+	user, err := r.roleTarget(email)
+	if err != nil {
+		return false, err
+	}
+	if user.ID == moderator.User.ID {
+		return false, errors.New("bad_request:you can't ban yourself")
+	}
+	// Moderators and admins can only be banned by whoever manages their roles
+	if privileged(user) && !middleware.HasPermission(ctx, models.PermissionManageRoles) {
+		return false, errors.New("access denied")
+	}
+	banned, err := r.RoleRepo.BanUser(user.ID, &moderator.User.ID, r.now())
+	if err != nil {
+		return false, errors.New("error banning user")
+	}
+	if banned {
+		disconnected := 0
+		if controller.ActiveHub != nil {
+			disconnected = controller.ActiveHub.DisconnectUser(user.Email, serializableModels.DisconnectBanned)
+		}
+		klog.Infof("%s banned by %s, disconnected %d workers", user.Email, moderator.User.Email, disconnected)
+	}
+	return banned, nil
+}
+
+// UnbanUser is the resolver for the unbanUser field.
+func (r *mutationResolver) UnbanUser(ctx context.Context, email string) (bool, error) {
+	moderator := middleware.AuthorizedUser(ctx)
+	user, err := r.roleTarget(email)
+	if err != nil {
+		return false, err
+	}
+	unbanned, err := r.RoleRepo.RevokeRole(user.ID, models.RoleBanned)
+	if err != nil {
+		return false, errors.New("error unbanning user")
+	}
+	if unbanned {
+		klog.Infof("%s unbanned by %s", user.Email, moderator.User.Email)
+	}
+	return unbanned, nil
+}
+
+// GrantRole is the resolver for the grantRole field.
+func (r *mutationResolver) GrantRole(ctx context.Context, email string, role model.AccountRole) (*model.UserRoles, error) {
+	admin := middleware.AuthorizedUser(ctx)
+	roleName, err := grantableRole(role)
+	if err != nil {
+		return nil, err
+	}
+	user, err := r.roleTarget(email)
+	if err != nil {
+		return nil, err
+	}
+	granted, err := r.RoleRepo.GrantRole(user.ID, roleName, &admin.User.ID)
+	if err != nil {
+		return nil, errors.New("error granting role")
+	}
+	if granted {
+		klog.Infof("Role %s granted to %s by %s", roleName, user.Email, admin.User.Email)
+	}
+	if user.Roles, err = r.RoleRepo.GetRoles(user.ID); err != nil {
+		return nil, errors.New("error retrieving roles")
+	}
+	return userRolesToModel(user), nil
+}
+
+// RevokeRole is the resolver for the revokeRole field.
+func (r *mutationResolver) RevokeRole(ctx context.Context, email string, role model.AccountRole) (*model.UserRoles, error) {
+	admin := middleware.AuthorizedUser(ctx)
+	roleName, err := grantableRole(role)
+	if err != nil {
+		return nil, err
+	}
+	user, err := r.roleTarget(email)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := r.RoleRepo.RevokeRole(user.ID, roleName)
+	if err != nil {
+		return nil, errors.New("error revoking role")
+	}
+	if revoked {
+		klog.Infof("Role %s revoked from %s by %s", roleName, user.Email, admin.User.Email)
+	}
+	if user.Roles, err = r.RoleRepo.GetRoles(user.ID); err != nil {
+		return nil, errors.New("error retrieving roles")
+	}
+	return userRolesToModel(user), nil
+}
+
+// SetStaleAccountExempt is the resolver for the setStaleAccountExempt field.
+func (r *mutationResolver) SetStaleAccountExempt(ctx context.Context, email string, exempt bool) (bool, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+	user, err := r.roleTarget(email)
+	if err != nil {
+		return false, err
+	}
+	if user.AnonymizedAt != nil {
+		return false, errors.New("bad_request:the account was anonymized already")
+	}
+	if err := r.StaleAccountRepo.SetStaleExempt(user.ID, exempt); err != nil {
+		return false, errors.New("error updating account")
+	}
+	klog.Infof("Stale account exemption of %s set to %t by %s", user.Email, exempt, admin.User.Email)
+	return true, nil
+}
+
+// SetCanRequestWork is the resolver for the setCanRequestWork field.
+func (r *mutationResolver) SetCanRequestWork(ctx context.Context, email string, enabled bool) (bool, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+	user, err := r.roleTarget(email)
+	if err != nil {
+		return false, err
+	}
+	if user.Type != models.REQUESTER {
+		return false, errors.New("bad_request:only requesters can request work")
+	}
+	changed, err := r.UserRepo.SetCanRequestWork(user.ID, enabled)
+	if err != nil {
+		return false, errors.New("error updating account")
+	}
+	if changed {
+		if enabled {
+			approveService(user)
+		}
+		klog.Infof("Work requests of %s set to %t by %s", user.Email, enabled, admin.User.Email)
+	}
+	return changed, nil
+}
+
+// SetEmailVerified is the resolver for the setEmailVerified field.
+func (r *mutationResolver) SetEmailVerified(ctx context.Context, email string, verified bool) (bool, error) {
+	admin := middleware.AuthorizedAdmin(ctx)
+	user, err := r.roleTarget(email)
+	if err != nil {
+		return false, err
+	}
+	if user.EmailVerified == verified {
+		return false, nil
+	}
+	if verified {
+		database.GetRedisDB().DeleteConfirmationToken(user.Email)
+	} else {
+		if user.ID == admin.User.ID {
+			return false, errors.New("bad_request:you can't unverify yourself")
+		}
+		// The token is out before they're unverified, so they can always verify again
+		if err := r.UserRepo.SendConfirmEmailEmail(user.Email, user.Type, true); err != nil {
+			return false, errors.New("error sending confirmation email")
+		}
+	}
+	changed, err := r.UserRepo.SetEmailVerified(user.ID, verified)
+	if err != nil {
+		return false, errors.New("error updating account")
+	}
+	if changed && !verified {
+		disconnected := 0
+		if controller.ActiveHub != nil {
+			disconnected = controller.ActiveHub.DisconnectUser(user.Email, serializableModels.DisconnectEmailUnverified)
+		}
+		klog.Infof("%s has to verify their email again, set by %s, disconnected %d workers", user.Email, admin.User.Email, disconnected)
+	} else if changed {
+		klog.Infof("Email of %s verified by %s", user.Email, admin.User.Email)
+	}
+	return changed, nil
+}
+
+// ReviewEmailCollision is the resolver for the reviewEmailCollision field.
+func (r *mutationResolver) ReviewEmailCollision(ctx context.Context, normalizedEmail string) (bool, error) {
+	moderator := middleware.AuthorizedUser(ctx)
+	reviewed, err := r.CollisionRepo.ReviewEmailCollision(strings.ToLower(strings.TrimSpace(normalizedEmail)), moderator.User.ID, r.now())
+	if err != nil {
+		return false, errors.New("error reviewing email collision")
+	}
+	if reviewed {
+		klog.Infof("Email collision %s reviewed by %s", normalizedEmail, moderator.User.Email)
+	}
+	return reviewed, nil
+}
+
+// TotalCount is the resolver for the totalCount field.
+func (r *pastPayoutCycleConnectionResolver) TotalCount(ctx context.Context, obj *model.PastPayoutCycleConnection) (int, error) {
+	return totalCount(obj.Count)
+}
+
+// TotalCount is the resolver for the totalCount field.
+func (r *payoutAddressHistoryConnectionResolver) TotalCount(ctx context.Context, obj *model.PayoutAddressHistoryConnection) (int, error) {
+	return totalCount(obj.Count)
+}
+
+// VerifyEmail is the resolver for the verifyEmail field.
+func (r *queryResolver) VerifyEmail(ctx context.Context, input model.VerifyEmailInput) (bool, error) {
+	return false, errors.New("Email confirmation disabled")
+	return r.UserRepo.VerifyEmailToken(&input)
+}
+
+// VerifyService is the resolver for the verifyService field.
+func (r *queryResolver) VerifyService(ctx context.Context, input model.VerifyServiceInput) (bool, error) {
+	return false, errors.New("Service verification disabled")
+	return r.UserRepo.VerifyService(&input)
+}
+
+// ResetPasswordTokenValid is the resolver for the resetPasswordTokenValid field.
+func (r *queryResolver) ResetPasswordTokenValid(ctx context.Context, token string) (bool, error) {
+	return false, errors.New("Password reset disabled")
+	_, err := r.UserRepo.CheckResetPasswordToken(token)
+	if errors.Is(err, database.ErrResetPasswordTokenInvalid) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetUser is the resolver for the getUser field.
+func (r *queryResolver) GetUser(ctx context.Context) (*model.GetUserResponse, error) {
+	user := middleware.AuthorizedUser(ctx)
+	return &model.GetUserResponse{
+		Type:                  model.UserType(user.User.Type),
+		BanAddress:            user.User.BanAddress,
+		ServiceName:           user.User.ServiceName,
+		ServiceWebsite:        user.User.ServiceWebsite,
+		EmailVerified:         user.User.EmailVerified,
+		Email:                 user.User.Email,
+		CanRequestWork:        user.User.CanRequestWork,
+		IncludeWorkTimings:    user.User.IncludeWorkTimings,
+		PayoutStatementEmails: user.User.PayoutStatementEmails,
+		TwoFactorEnabled:      user.User.TwoFactorEnabled,
+	}, nil
+}
+
+// MyActivity is the resolver for the myActivity field.
+func (r *queryResolver) MyActivity(ctx context.Context, first *int, after *string) (*model.ActivityConnection, error) {
+	user := middleware.AuthorizedUser(ctx)
+	args, err := pagination.ParseArgs(first, after)
+	if err != nil {
+		return nil, err
+	}
+	items, err := r.ActivityRepo.GetActivity(user.User.ID, args)
+	if err != nil {
+		return nil, errors.New("error retrieving activity")
+	}
+	connection := activityToModel(pagination.NewPage(items, args, repository.ActivityItem.Cursor))
+	connection.Count = func() (int, error) {
+		return r.ActivityRepo.CountActivity(user.User.ID)
+	}
+	return connection, nil
+}
+
+// MyRoles is the resolver for the myRoles field.
+func (r *queryResolver) MyRoles(ctx context.Context) (*model.UserRoles, error) {
+	return userRolesToModel(middleware.AuthorizedUser(ctx).User), nil
+}
+
+// ListAPIKeys is the resolver for the listApiKeys field.
+func (r *queryResolver) ListAPIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	requester := middleware.AuthorizedRequester(ctx)
+	apiKeys, err := r.APIKeyRepo.GetAPIKeys(requester.User.ID)
+	if err != nil {
+		return nil, errors.New("error retrieving api keys")
+	}
+	ret := make([]*model.APIKey, len(apiKeys))
+	for i := range apiKeys {
+		ret[i] = apiKeyToModel(&apiKeys[i])
+	}
+	return ret, nil
+}
+
+// WorkSources is the resolver for the workSources field.
+func (r *queryResolver) WorkSources(ctx context.Context) ([]*model.WorkSource, error) {
+	requester := middleware.AuthorizedRequester(ctx)
+	sources, err := r.WorkSourceRepo.GetWorkSources(requester.User.ID)
+	if err != nil {
+		return nil, errors.New("error retrieving work sources")
+	}
+	ret := make([]*model.WorkSource, len(sources))
+	for i := range sources {
+		ret[i] = r.workSourceToModel(&sources[i])
+	}
+	return ret, nil
+}
+
+// SourceUsage is the resolver for the sourceUsage field.
+func (r *queryResolver) SourceUsage(ctx context.Context, rangeArg model.StatsRange) ([]*model.SourceUsage, error) {
+	requester := middleware.AuthorizedRequester(ctx)
+	usage, err := r.WorkSourceRepo.GetSourceUsage(requester.User.ID, statsRangeSince(rangeArg, r.now()))
+	if err != nil {
+		return nil, errors.New("error retrieving source usage")
+	}
+	return sourceUsageToModel(usage), nil
+}
+
+// PowChallenge is the resolver for the powChallenge field.
+func (r *queryResolver) PowChallenge(ctx context.Context) (*model.PowChallenge, error) {
+	if r.PowChallenges == nil {
+		return nil, errors.New("Proof of work challenges are disabled")
+	}
+	powChallenge, err := r.PowChallenges.Issue(r.now())
+	if err != nil {
+		return nil, err
+	}
+	return &model.PowChallenge{
+		Hash:       powChallenge.Hash,
+		Difficulty: powChallenge.Difficulty,
+		ExpiresAt:  utils.GenerateISOString(powChallenge.ExpiresAt),
+	}, nil
+}
+
+// ValidateWork is the resolver for the validateWork field.
+func (r *queryResolver) ValidateWork(ctx context.Context, input model.ValidateWorkInput) (bool, error) {
+	if err := checkWorkInput(input.Hash, input.Work, input.DifficultyMultiplier); err != nil {
+		return false, err
+	}
+	return validation.IsWorkValid(input.Hash, input.DifficultyMultiplier, input.Work), nil
+}
+
+// GetPayoutAddresses is the resolver for the getPayoutAddresses field.
+func (r *queryResolver) GetPayoutAddresses(ctx context.Context) ([]*model.PayoutAddress, error) {
+	provider := middleware.AuthorizedProvider(ctx)
+
+	addresses, err := r.payoutAddressesFor(provider)
+	if err != nil {
+		return nil, errors.New("error retrieving payout addresses")
+	}
+	return payoutAddressesToModel(addresses), nil
+}
+
+// GetPayoutHistory is the resolver for the getPayoutHistory field.
+func (r *queryResolver) GetPayoutHistory(ctx context.Context, first *int, after *string) (*model.PayoutAddressHistoryConnection, error) {
+	provider := middleware.AuthorizedProvider(ctx)
+	args, err := pagination.ParseArgs(first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := r.PayoutRepo.GetPayoutHistory(provider.User.ID, args)
+	if err != nil {
+		return nil, errors.New("error retrieving payout history")
+	}
+	page := pagination.NewPage(history, args, repository.PayoutAddressHistory.Cursor)
+	connection := &model.PayoutAddressHistoryConnection{
+		Nodes:    make([]*model.PayoutAddressHistory, len(page.Items)),
+		PageInfo: pageInfoToModel(page),
+		Count: func() (int, error) {
+			return r.PayoutRepo.CountPayoutHistory(provider.User.ID)
+		},
+	}
+	for i, h := range page.Items {
+		connection.Nodes[i] = &model.PayoutAddressHistory{
+			BanAddress:      h.BanAddress,
+			TotalPaidBanano: h.TotalBanano(),
+			PaymentCount:    h.PaymentCount,
+			LastPaidAt:      utils.GenerateISOString(h.LastPaidAt),
+		}
+	}
+	return connection, nil
+}
+
+// GetOfflineAlert is the resolver for the getOfflineAlert field.
+func (r *queryResolver) GetOfflineAlert(ctx context.Context) (*model.OfflineAlert, error) {
+	provider := middleware.AuthorizedProvider(ctx)
+
+	alert, err := r.AlertRepo.GetOfflineAlert(provider.User.ID)
+	if err != nil {
+		return nil, errors.New("error retrieving offline alert")
+	}
+	if alert == nil {
+		return nil, nil
+	}
+	return offlineAlertToModel(alert), nil
+}
+
+// GetSecurityWebhook is the resolver for the getSecurityWebhook field.
+func (r *queryResolver) GetSecurityWebhook(ctx context.Context) (*model.SecurityWebhook, error) {
+	user := middleware.AuthorizedUser(ctx)
+
+	webhook, err := r.SecurityWebhookRepo.GetSecurityWebhook(user.User.ID)
+	if err != nil {
+		return nil, errors.New("error retrieving security webhook")
+	}
+	if webhook == nil {
+		return nil, nil
+	}
+	return &model.SecurityWebhook{URL: webhook.URL, UpdatedAt: webhook.UpdatedAt.UTC().Format(time.RFC3339)}, nil
+}
+
+// MyPayoutProjection is the resolver for the myPayoutProjection field.
+func (r *queryResolver) MyPayoutProjection(ctx context.Context) (*model.PayoutProjection, error) {
+	provider := middleware.AuthorizedProvider(ctx)
+	projection, _, err := r.payoutProjection(provider.User)
+	return projection, err
+}
+
+// UsageStatements is the resolver for the usageStatements field.
+func (r *queryResolver) UsageStatements(ctx context.Context) ([]*model.UsageStatement, error) {
+	requester := middleware.AuthorizedRequester(ctx)
+	now := r.now()
+	usage, err := r.UsageRepo.GetUsage(requester.User.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	ret := []*model.UsageStatement{usageToModel(models.StartOfMonth(now), false, usage)}
+
+	statements, err := r.UsageRepo.GetUsageStatements(requester.User.ID, config.USAGE_STATEMENT_HISTORY_MONTHS)
+	if err != nil {
+		return nil, err
+	}
+	for _, statement := range statements {
+		usage, err := r.UsageRepo.GetUsage(requester.User.ID, statement.Month)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, usageToModel(statement.Month, true, usage))
+	}
+	return ret, nil
+}
+
+// CreditAccount is the resolver for the creditAccount field.
+func (r *queryResolver) CreditAccount(ctx context.Context) (*model.CreditAccount, error) {
+	requester := middleware.AuthorizedRequester(ctx)
+	now := r.now()
+	balance, err := r.BoostRepo.GetCreditBalance(requester.User.ID)
+	if err != nil {
+		return nil, errors.New("error retrieving credit")
+	}
+	boughtToday, err := r.BoostRepo.GetBoostMinutesSince(requester.User.ID, models.StartOfDay(now))
+	if err != nil {
+		return nil, errors.New("error retrieving credit")
+	}
+	active, err := r.BoostRepo.GetActiveBoost(requester.User.ID, now)
+	if err != nil {
+		return nil, errors.New("error retrieving credit")
+	}
+	entries, err := r.BoostRepo.GetCreditEntries(requester.User.ID, pagination.Args{First: config.CREDIT_HISTORY_LENGTH})
+	if err != nil {
+		return nil, errors.New("error retrieving credit")
+	}
+	return creditAccountToModel(balance, boostPricing(), boughtToday, active, entries), nil
+}
+
+// DifficultyDistribution is the resolver for the difficultyDistribution field.
+func (r *queryResolver) DifficultyDistribution(ctx context.Context, rangeArg model.StatsRange) ([]*model.DifficultyBucket, error) {
+	buckets, err := r.StatsStore.GetDifficultyDistribution(middleware.RequestTenant(ctx), statsRangeSince(rangeArg, r.now()))
+	if err != nil {
+		return nil, errors.New("error retrieving difficulty distribution")
+	}
+
+	ret := make([]*model.DifficultyBucket, len(buckets))
+	for i, bucket := range buckets {
+		ret[i] = &model.DifficultyBucket{
+			DifficultyMultiplier: bucket.DifficultyMultiplier,
+			Count:                int(bucket.Count),
+		}
+	}
+	return ret, nil
+}
+
+// AwardRateHistory is the resolver for the awardRateHistory field.
+func (r *queryResolver) AwardRateHistory(ctx context.Context) ([]*model.AwardRate, error) {
+	rates, err := r.AwardRepo.GetAwardRateHistory(middleware.RequestTenant(ctx))
+	if err != nil {
+		return nil, errors.New("error retrieving award rates")
+	}
+
+	ret := make([]*model.AwardRate, len(rates))
+	for i := range rates {
+		ret[i] = awardRateToModel(&rates[i])
+	}
+	return ret, nil
+}
+
+// Status is the resolver for the status field.
+func (r *queryResolver) Status(ctx context.Context) (*model.PoolStatusResponse, error) {
+	open, err := r.IncidentRepo.GetOpenIncidents()
+	if err != nil {
+		return nil, errors.New("error retrieving incidents")
+	}
+	connected, err := database.GetRedisDB().GetNumberConnectedClients()
+	if err != nil {
+		return nil, errors.New("error retrieving connected workers")
+	}
+	return &model.PoolStatusResponse{
+		Status:           poolStatus(open),
+		ConnectedWorkers: int(connected),
+		Incidents:        incidentsToModel(open),
+	}, nil
+}
+
+// IncidentHistory is the resolver for the incidentHistory field.
+func (r *queryResolver) IncidentHistory(ctx context.Context) ([]*model.Incident, error) {
+	incidents, err := r.IncidentRepo.GetIncidentHistory()
+	if err != nil {
+		return nil, errors.New("error retrieving incidents")
+	}
+	return incidentsToModel(incidents), nil
+}
+
+// Leaderboard is the resolver for the leaderboard field.
+func (r *queryResolver) Leaderboard(ctx context.Context, window model.LeaderboardWindow, limit *int) (*model.Leaderboard, error) {
+	entries, err := r.LeaderboardRepo.GetLeaderboard(middleware.RequestTenant(ctx), models.LeaderboardWindow(window), leaderboardLimit(limit))
+	if err != nil {
+		return nil, errors.New("error retrieving leaderboard")
+	}
+	return leaderboardToModel(window, entries, privacy.NewBlurrer(env.GetPublicStatsNoisePercent()), r.now()), nil
+}
+
+// HardwareLeaderboard is the resolver for the hardwareLeaderboard field.
+func (r *queryResolver) HardwareLeaderboard(ctx context.Context, difficultyMultiplier *int) ([]*model.HardwareBenchmark, error) {
+	leaderboard, err := r.BenchmarkRepo.GetHardwareLeaderboard(difficultyMultiplier)
+	if err != nil {
+		return nil, errors.New("error retrieving hardware leaderboard")
+	}
+	return hardwareBenchmarksToModel(leaderboard, privacy.NewBlurrer(env.GetPublicStatsNoisePercent()), r.now()), nil
+}
+
+// MaintenanceWindows is the resolver for the maintenanceWindows field.
+func (r *queryResolver) MaintenanceWindows(ctx context.Context) ([]*model.MaintenanceWindow, error) {
+	now := r.now()
+	windows, err := r.MaintenanceRepo.GetMaintenanceWindows(now)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*model.MaintenanceWindow, len(windows))
+	for i := range windows {
+		ret[i] = maintenanceWindowToModel(&windows[i], now)
+	}
+	return ret, nil
+}
+
+// HubPolicy is the resolver for the hubPolicy field.
+func (r *queryResolver) HubPolicy(ctx context.Context) (*model.HubPolicy, error) {
+	policy := controller.Policy()
+	return hubPolicyToModel(&policy), nil
+}
+
+// PayoutCalendar is the resolver for the payoutCalendar field.
+func (r *queryResolver) PayoutCalendar(ctx context.Context) (*model.PayoutCalendar, error) {
+	tenant, err := r.TenantRepo.GetTenant(middleware.RequestTenant(ctx))
+	if err != nil {
+		return nil, errors.New("unknown tenant")
+	}
+	_, amounts, err := r.WorkRepo.EstimatePayouts(tenant.ID, tenant.GetPrizePool())
+	if err != nil {
+		return nil, errors.New("error estimating payouts")
+	}
+	owed := new(big.Int)
+	for _, amount := range amounts {
+		owed.Add(owed, amount)
+	}
+	balance, err := database.GetRedisDB().GetPrizePoolBalance(tenant.ID)
+	if err != nil {
+		balance = nil
+	}
+	cycles := payouts.Calendar(r.now(), env.GetPayoutHourUTC(), config.PAYOUT_CALENDAR_CYCLES, number.BananoIntToRaw(tenant.GetPrizePool()), owed, balanceRaw(balance))
+	return payoutCalendarToModel(tenant.GetPrizePool(), cycles, balance), nil
+}
+
+// PastPayoutCycles is the resolver for the pastPayoutCycles field.
+func (r *queryResolver) PastPayoutCycles(ctx context.Context, first *int, after *string) (*model.PastPayoutCycleConnection, error) {
+	args, err := pagination.ParseArgs(first, after)
+	if err != nil {
+		return nil, err
+	}
+	tenantID := middleware.RequestTenant(ctx)
+	cycles, err := r.PayoutCycleRepo.GetPayoutCycles(tenantID, args)
+	if err != nil {
+		return nil, errors.New("error retrieving payout cycles")
+	}
+	page := pagination.NewPage(cycles, args, repository.PayoutCycleCursor)
+	connection := &model.PastPayoutCycleConnection{
+		Nodes:    make([]*model.PastPayoutCycle, len(page.Items)),
+		PageInfo: pageInfoToModel(page),
+		Count: func() (int, error) {
+			return r.PayoutCycleRepo.CountPayoutCycles(tenantID)
+		},
+	}
+	for i, cycle := range page.Items {
+		connection.Nodes[i] = pastPayoutCycleToModel(cycle)
+	}
+	return connection, nil
+}
+
+// PayoutReport is the resolver for the payoutReport field.
+func (r *queryResolver) PayoutReport(ctx context.Context, cycleID string) (*model.PayoutReport, error) {
+	id, err := uuid.Parse(cycleID)
+	if err != nil {
+		return nil, errors.New("bad_request:invalid cycleId")
+	}
+	report, err := payouts.LoadReport(r.PayoutCycleRepo, middleware.RequestTenant(ctx), id)
+	if err != nil {
+		return nil, errors.New("error retrieving payout report")
+	}
+	if report == nil {
+		return nil, nil
+	}
+	signed, err := payouts.SignReport(*report, r.PayoutReportKey)
+	if err != nil {
+		return nil, errors.New("error signing payout report")
+	}
+	return payoutReportToModel(*report, signed), nil
+}
+
+// BoostPricing is the resolver for the boostPricing field.
+func (r *queryResolver) BoostPricing(ctx context.Context) (*model.BoostPricing, error) {
+	return boostPricingToModel(boostPricing()), nil
+}
+
+// DifficultyPresets is the resolver for the difficultyPresets field.
+func (r *queryResolver) DifficultyPresets(ctx context.Context) ([]*model.DifficultyPreset, error) {
+	return difficultyPresetsToModel(validation.DifficultyPresets), nil
+}
+
+// BoostEconomics is the resolver for the boostEconomics field.
+func (r *queryResolver) BoostEconomics(ctx context.Context, rangeArg model.StatsRange) (*model.BoostEconomics, error) {
+	economics, err := r.BoostRepo.GetBoostEconomics(middleware.RequestTenant(ctx), statsRangeSince(rangeArg, r.now()))
+	if err != nil {
+		return nil, errors.New("error retrieving boost economics")
+	}
+	return boostEconomicsToModel(economics), nil
+}
+
+// NetworkMap is the resolver for the networkMap field.
+func (r *queryResolver) NetworkMap(ctx context.Context, rangeArg model.StatsRange) ([]*model.CountryStats, error) {
+	return r.countryStats(ctx, rangeArg, true)
+}
+
+// ConnectedWorkersHistory is the resolver for the connectedWorkersHistory field.
+func (r *queryResolver) ConnectedWorkersHistory(ctx context.Context, rangeArg model.StatsRange) ([]*model.ConnectedWorkersPoint, error) {
+	snapshots, err := r.CounterSnapshotRepo.GetConnectedWorkersHistory(middleware.RequestTenant(ctx), statsRangeSince(rangeArg, r.now()))
+	if err != nil {
+		return nil, errors.New("error retrieving connected workers")
+	}
+	return connectedWorkersToModel(snapshots), nil
+}
+
+// HubEvents is the resolver for the hubEvents field.
+func (r *queryResolver) HubEvents(ctx context.Context, requestID string) ([]*model.HubEvent, error) {
+	events := controller.HubEvents.ForRequest(requestID)
+	if env.PersistHubEvents() {
+		// The buffer only covers recent history, older events come from the database
+		persisted, err := r.EventRepo.GetHubEvents(requestID)
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[uuid.UUID]bool)
+		for _, event := range events {
+			seen[event.ID] = true
+		}
+		for _, event := range persisted {
+			if !seen[event.ID] {
+				events = append(events, event)
+			}
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].Timestamp.Before(events[j].Timestamp)
+		})
+	}
+
+	ret := make([]*model.HubEvent, len(events))
+	for i, event := range events {
+		ret[i] = &model.HubEvent{
+			ID:          event.ID.String(),
+			Type:        string(event.Type),
+			RequestID:   event.RequestID,
+			Hash:        event.Hash,
+			ClientIP:    event.ClientIP,
+			ClientEmail: event.ClientEmail,
+			TenantID:    event.TenantID,
+			Detail:      event.Detail,
+			Timestamp:   utils.GenerateISOString(event.Timestamp),
+		}
+	}
+	return ret, nil
+}
+
+// AdminMetrics is the resolver for the adminMetrics field.
+func (r *queryResolver) AdminMetrics(ctx context.Context) (*model.AdminMetrics, error) {
+	now := r.now()
+	metrics := adminMetricsToModel(controller.Requests.Rates(now), controller.HubEvents.CountSince(now.Add(-config.ADMIN_METRICS_WINDOW_MINUTES*time.Minute)), controller.ActiveHub.Snapshot(), len(controller.Quarantine.Stats(now).Quarantined))
+	connected, err := database.GetRedisDB().GetNumberConnectedClients()
+	if err != nil {
+		return nil, errors.New("error retrieving connected workers")
+	}
+	metrics.ConnectedWorkers = int(connected)
+	metrics.NextPayoutAt = utils.GenerateISOString(payouts.NextPayout(now, env.GetPayoutHourUTC()))
+	metrics.RedisMemory = redisMemoryToModel(database.GetRedisDB().LastMemoryReport())
+
+	tenantID := middleware.RequestTenant(ctx)
+	cycles, err := r.PayoutCycleRepo.GetPayoutCycles(tenantID, pagination.Args{First: 1})
+	if err != nil {
+		return nil, errors.New("error retrieving payout cycles")
+	}
+	if len(cycles) > 0 {
+		metrics.LastPayoutCycle = pastPayoutCycleToModel(cycles[0])
+		report, err := payouts.LoadReport(r.PayoutCycleRepo, tenantID, cycles[0].ID)
+		if err != nil {
+			return nil, errors.New("error retrieving payout report")
+		}
+		if report != nil {
+			complete := report.Complete()
+			metrics.LastPayoutComplete = &complete
+		}
+	}
+	return metrics, nil
+}
+
+// WorkerSolveTimes is the resolver for the workerSolveTimes field.
+func (r *queryResolver) WorkerSolveTimes(ctx context.Context) ([]*model.WorkerSolveTimes, error) {
+	workers := controller.ActiveHub.SolveTimes()
+	ret := make([]*model.WorkerSolveTimes, len(workers))
+	for i, worker := range workers {
+		ret[i] = workerSolveTimesToModel(worker)
+	}
+	return ret, nil
+}
+
+// WorkerAbuseStats is the resolver for the workerAbuseStats field.
+func (r *queryResolver) WorkerAbuseStats(ctx context.Context) (*model.WorkerAbuseStats, error) {
+	return workerAbuseStatsToModel(controller.Quarantine.Stats(r.now())), nil
+}
+
+// ValidationCrossCheck is the resolver for the validationCrossCheck field.
+func (r *queryResolver) ValidationCrossCheck(ctx context.Context) (*model.ValidationCrossCheck, error) {
+	if controller.CrossCheck == nil {
+		return nil, nil
+	}
+	return crossCheckToModel(controller.CrossCheck.Stats()), nil
+}
+
+// UserRoles is the resolver for the userRoles field.
+func (r *queryResolver) UserRoles(ctx context.Context, email string) (*model.UserRoles, error) {
+	lower := strings.ToLower(strings.TrimSpace(email))
+	user, err := r.UserRepo.GetUser(nil, &lower)
+	if err != nil {
+		return nil, nil
+	}
+	return userRolesToModel(user), nil
+}
+
+// EmailCollisions is the resolver for the emailCollisions field.
+func (r *queryResolver) EmailCollisions(ctx context.Context, includeReviewed *bool) ([]*model.EmailCollision, error) {
+	collisions, err := r.CollisionRepo.GetEmailCollisions(includeReviewed != nil && *includeReviewed)
+	if err != nil {
+		return nil, errors.New("error retrieving email collisions")
+	}
+	ret := make([]*model.EmailCollision, len(collisions))
+	for i, collision := range collisions {
+		ret[i] = emailCollisionToModel(collision)
+	}
+	return ret, nil
+}
+
+// EmailTemplates is the resolver for the emailTemplates field.
+func (r *queryResolver) EmailTemplates(ctx context.Context) ([]*model.EmailTemplate, error) {
+	ret, err := builtinEmailTemplates()
+	if err != nil {
+		return nil, err
+	}
+	templates, err := r.EmailTemplateRepo.GetEmailTemplates()
+	if err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		ret = append(ret, emailTemplateToModel(&templates[i]))
+	}
+	return ret, nil
+}
+
+// EmailTemplateVersions is the resolver for the emailTemplateVersions field.
+func (r *queryResolver) EmailTemplateVersions(ctx context.Context, name string, language string) ([]*model.EmailTemplate, error) {
+	templates, err := r.EmailTemplateRepo.GetEmailTemplateVersions(name, language)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*model.EmailTemplate, len(templates))
+	for i := range templates {
+		ret[i] = emailTemplateToModel(&templates[i])
+	}
+	return ret, nil
+}
+
+// PreviewEmailTemplate is the resolver for the previewEmailTemplate field.
+func (r *queryResolver) PreviewEmailTemplate(ctx context.Context, input model.EmailTemplateInput) (*model.EmailPreview, error) {
+	subject, html, err := email.Preview(input.Name, input.Subject, input.Body)
+	if err != nil {
+		return nil, fmt.Errorf("bad_request:%s", err.Error())
+	}
+	return &model.EmailPreview{Subject: subject, HTML: html}, nil
+}
+
+// LogLevels is the resolver for the logLevels field.
+func (r *queryResolver) LogLevels(ctx context.Context) ([]*model.SubsystemLogLevel, error) {
+	ret := make([]*model.SubsystemLogLevel, len(logging.Subsystems))
+	for i, subsystem := range logging.Subsystems {
+		ret[i] = subsystemLogLevelToModel(subsystem)
+	}
+	return ret, nil
+}
+
+// RequestSampling is the resolver for the requestSampling field.
+func (r *queryResolver) RequestSampling(ctx context.Context) (*model.RequestSampling, error) {
+	samplingConfig := r.Sampler.Config(r.now())
+	if samplingConfig == nil {
+		return nil, nil
+	}
+	return requestSamplingToModel(samplingConfig), nil
+}
+
+// RequestSamples is the resolver for the requestSamples field.
+func (r *queryResolver) RequestSamples(ctx context.Context, userEmail *string, first *int, after *string) (*model.RequestSampleConnection, error) {
+	args, err := pagination.ParseArgs(first, after)
+	if err != nil {
+		return nil, err
+	}
+	email := ""
+	if userEmail != nil {
+		email = *userEmail
+	}
+	samples, err := database.GetRedisDB().GetRequestSamples(email)
+	if err != nil {
+		return nil, err
+	}
+	return requestSamplesToModel(samples, args), nil
+}
+
+// LoadShedding is the resolver for the loadShedding field.
+func (r *queryResolver) LoadShedding(ctx context.Context) (*model.LoadShedding, error) {
+	sheddingConfig := controller.Shedder.Config(r.now())
+	if sheddingConfig == nil {
+		return nil, nil
+	}
+	return loadSheddingToModel(sheddingConfig), nil
+}
+
+// StaleAccountReports is the resolver for the staleAccountReports field.
+func (r *queryResolver) StaleAccountReports(ctx context.Context, first *int, after *string) (*model.StaleAccountReportConnection, error) {
+	args, err := pagination.ParseArgs(first, after)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := r.StaleAccountRepo.GetStaleAccountReports(args)
+	if err != nil {
+		return nil, errors.New("error retrieving stale account reports")
+	}
+	page := pagination.NewPage(reports, args, repository.StaleAccountReportCursor)
+	connection := &model.StaleAccountReportConnection{
+		Nodes:    make([]*model.StaleAccountReport, len(page.Items)),
+		PageInfo: pageInfoToModel(page),
+		Count:    r.StaleAccountRepo.CountStaleAccountReports,
+	}
+	for i, report := range page.Items {
+		connection.Nodes[i] = staleAccountReportToModel(report)
+	}
+	return connection, nil
+}
+
+// GeoAnalytics is the resolver for the geoAnalytics field.
+func (r *queryResolver) GeoAnalytics(ctx context.Context, rangeArg model.StatsRange) ([]*model.CountryStats, error) {
+	return r.countryStats(ctx, rangeArg, false)
+}
+
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context, filter []*model.UserFilter, sort *model.UserSort, first *int, after *string) (*model.AdminUserConnection, error) {
+	args, err := pagination.ParseArgs(first, after)
+	if err != nil {
+		return nil, err
+	}
+	query, err := userListQuery(filter, sort)
+	if err != nil {
+		return nil, err
+	}
+	users, err := r.UserRepo.ListUsers(query, args)
+	if err != nil {
+		return nil, errors.New("error retrieving users")
+	}
+	page := pagination.NewPage(users, args, repository.UserCursor(query.Sort))
+	connection := &model.AdminUserConnection{
+		Nodes:    make([]*model.AdminUser, len(page.Items)),
+		PageInfo: pageInfoToModel(page),
+		Count: func() (int, error) {
+			return r.UserRepo.CountUsers(query)
+		},
+	}
+	for i := range page.Items {
+		connection.Nodes[i] = adminUserToModel(&page.Items[i])
+	}
+	return connection, nil
+}
+
+// WorkResults is the resolver for the workResults field.
+func (r *queryResolver) WorkResults(ctx context.Context, filter []*model.WorkResultFilter, sort *model.WorkResultSort, first *int, after *string) (*model.WorkResultConnection, error) {
+	args, err := pagination.ParseArgs(first, after)
+	if err != nil {
+		return nil, err
+	}
+	query, err := workResultListQuery(filter, sort)
+	if err != nil {
+		return nil, err
+	}
+	return r.workResultConnection(query, args)
+}
+
+// AuditLog is the resolver for the auditLog field.
+func (r *queryResolver) AuditLog(ctx context.Context, filter []*model.AuditLogFilter, sort *model.AuditLogSort, first *int, after *string) (*model.AuditLogConnection, error) {
+	args, err := pagination.ParseArgs(first, after)
+	if err != nil {
+		return nil, err
+	}
+	query, err := auditLogListQuery(filter, sort)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := r.AuditLogRepo.ListAuditLog(query, args)
+	if err != nil {
+		return nil, errors.New("error retrieving audit log")
+	}
+	page := pagination.NewPage(entries, args, repository.AuditLogCursor)
+	connection := &model.AuditLogConnection{
+		Nodes:    make([]*model.AuditLogEntry, len(page.Items)),
+		PageInfo: pageInfoToModel(page),
+		Count: func() (int, error) {
+			return r.AuditLogRepo.CountAuditLog(query)
+		},
+	}
+	for i := range page.Items {
+		connection.Nodes[i] = auditLogEntryToModel(&page.Items[i])
+	}
+	return connection, nil
+}
+
+// UserWorkHistory is the resolver for the userWorkHistory field.
+func (r *queryResolver) UserWorkHistory(ctx context.Context, email string, first *int, after *string) (*model.WorkResultConnection, error) {
+	args, err := pagination.ParseArgs(first, after)
+	if err != nil {
+		return nil, err
+	}
+	user, err := r.roleTarget(email)
+	if err != nil {
+		return nil, err
+	}
+	query, err := userWorkHistoryQuery(user)
+	if err != nil {
+		return nil, err
+	}
+	return r.workResultConnection(query, args)
+}
+
+// TotalCount is the resolver for the totalCount field.
+func (r *requestSampleConnectionResolver) TotalCount(ctx context.Context, obj *model.RequestSampleConnection) (int, error) {
+	return totalCount(obj.Count)
+}
+
+// TotalCount is the resolver for the totalCount field.
+func (r *staleAccountReportConnectionResolver) TotalCount(ctx context.Context, obj *model.StaleAccountReportConnection) (int, error) {
+	return totalCount(obj.Count)
+}
+
+// Stats is the resolver for the stats field.
+func (r *subscriptionResolver) Stats(ctx context.Context) (<-chan *model.Stats, error) {
+	msgs := make(chan *model.Stats, 1)
+	tenantID := middleware.RequestTenant(ctx)
+
+	// Pub stats every 10 seconds
+	go func() {
+		for {
+			stats := models.GetStatsInstance().Get(tenantID)
+			if stats == nil {
+				stats = &model.Stats{
+					ConnectedWorkers:       -1,
+					TotalPaidBanano:        "N/A",
 					RegisteredServiceCount: -1,
 					Top10:                  nil,
 					Services:               nil,
@@ -282,15 +2109,173 @@ func (r *subscriptionResolver) Stats(ctx context.Context) (<-chan *model.Stats,
 	return msgs, nil
 }
 
+// UserEvents is the resolver for the userEvents field.
+func (r *subscriptionResolver) UserEvents(ctx context.Context) (<-chan *model.UserEvent, error) {
+	user := middleware.AuthorizedUser(ctx)
+
+	events, unsubscribe := controller.UserEvents.Subscribe(user.User.ID)
+	msgs := make(chan *model.UserEvent, 1)
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-events:
+				msg := &model.UserEvent{Type: event.Type}
+				if event.BlockHash != "" {
+					msg.BlockHash = &event.BlockHash
+				}
+				if event.AmountRaw != "" {
+					if amount, err := number.RawToBanano(event.AmountRaw, true); err == nil {
+						formatted := fmt.Sprintf("%.2f", amount)
+						msg.AmountBanano = &formatted
+					}
+				}
+				select {
+				case msgs <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return msgs, nil
+}
+
+// NetworkMap is the resolver for the networkMap field.
+func (r *subscriptionResolver) NetworkMap(ctx context.Context, rangeArg model.StatsRange) (<-chan []*model.CountryStats, error) {
+	msgs := make(chan []*model.CountryStats, 1)
+	go func() {
+		ticker := time.NewTicker(config.NETWORK_MAP_PUSH_SECONDS * time.Second)
+		defer ticker.Stop()
+		for {
+			stats, err := r.countryStats(ctx, rangeArg, true)
+			if err != nil {
+				klog.Errorf("Error getting network map %v", err)
+			} else {
+				select {
+				case msgs <- stats:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return msgs, nil
+}
+
+// StatsUpdated is the resolver for the statsUpdated field.
+func (r *subscriptionResolver) StatsUpdated(ctx context.Context) (<-chan *model.ProviderStats, error) {
+	provider := middleware.AuthorizedProvider(ctx)
+
+	activity, unsubscribe := controller.ProviderActivity.Subscribe(provider.User.Email)
+	msgs := make(chan *model.ProviderStats, 1)
+	go func() {
+		defer unsubscribe()
+		ticker := time.NewTicker(config.PROVIDER_STATS_PUSH_SECONDS * time.Second)
+		defer ticker.Stop()
+		for {
+			stats, err := r.providerStats(provider.User)
+			if err != nil {
+				klog.Errorf("Error getting provider stats %v", err)
+			} else {
+				select {
+				case msgs <- stats:
+				case <-ctx.Done():
+					return
+				}
+			}
+			// Activity meanwhile is pushed once this is over
+			select {
+			case <-time.After(config.PROVIDER_STATS_MIN_INTERVAL_SECONDS * time.Second):
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case <-activity:
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return msgs, nil
+}
+
+// TotalCount is the resolver for the totalCount field.
+func (r *workResultConnectionResolver) TotalCount(ctx context.Context, obj *model.WorkResultConnection) (int, error) {
+	return totalCount(obj.Count)
+}
+
+// ActivityConnection returns generated.ActivityConnectionResolver implementation.
+func (r *Resolver) ActivityConnection() generated.ActivityConnectionResolver {
+	return &activityConnectionResolver{r}
+}
+
+// AdminUserConnection returns generated.AdminUserConnectionResolver implementation.
+func (r *Resolver) AdminUserConnection() generated.AdminUserConnectionResolver {
+	return &adminUserConnectionResolver{r}
+}
+
+// AuditLogConnection returns generated.AuditLogConnectionResolver implementation.
+func (r *Resolver) AuditLogConnection() generated.AuditLogConnectionResolver {
+	return &auditLogConnectionResolver{r}
+}
+
+// GetUserResponse returns generated.GetUserResponseResolver implementation.
+func (r *Resolver) GetUserResponse() generated.GetUserResponseResolver {
+	return &getUserResponseResolver{r}
+}
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
+// PastPayoutCycleConnection returns generated.PastPayoutCycleConnectionResolver implementation.
+func (r *Resolver) PastPayoutCycleConnection() generated.PastPayoutCycleConnectionResolver {
+	return &pastPayoutCycleConnectionResolver{r}
+}
+
+// PayoutAddressHistoryConnection returns generated.PayoutAddressHistoryConnectionResolver implementation.
+func (r *Resolver) PayoutAddressHistoryConnection() generated.PayoutAddressHistoryConnectionResolver {
+	return &payoutAddressHistoryConnectionResolver{r}
+}
+
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+// RequestSampleConnection returns generated.RequestSampleConnectionResolver implementation.
+func (r *Resolver) RequestSampleConnection() generated.RequestSampleConnectionResolver {
+	return &requestSampleConnectionResolver{r}
+}
+
+// StaleAccountReportConnection returns generated.StaleAccountReportConnectionResolver implementation.
+func (r *Resolver) StaleAccountReportConnection() generated.StaleAccountReportConnectionResolver {
+	return &staleAccountReportConnectionResolver{r}
+}
+
 // Subscription returns generated.SubscriptionResolver implementation.
 func (r *Resolver) Subscription() generated.SubscriptionResolver { return &subscriptionResolver{r} }
 
+// WorkResultConnection returns generated.WorkResultConnectionResolver implementation.
+func (r *Resolver) WorkResultConnection() generated.WorkResultConnectionResolver {
+	return &workResultConnectionResolver{r}
+}
+
+type activityConnectionResolver struct{ *Resolver }
+type adminUserConnectionResolver struct{ *Resolver }
+type auditLogConnectionResolver struct{ *Resolver }
+type getUserResponseResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type pastPayoutCycleConnectionResolver struct{ *Resolver }
+type payoutAddressHistoryConnectionResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type requestSampleConnectionResolver struct{ *Resolver }
+type staleAccountReportConnectionResolver struct{ *Resolver }
 type subscriptionResolver struct{ *Resolver }
+type workResultConnectionResolver struct{ *Resolver }
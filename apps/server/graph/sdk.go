@@ -0,0 +1,144 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+// Example requester operations the SDKs are generated from, one per file named after the operation
+//
+//go:embed operations/*.graphql
+var operationFiles embed.FS
+
+// An operation clients can run by its hash alone, see https://github.com/apollographql/apollo-link-persisted-queries
+type PersistedOperation struct {
+	Name       string `json:"name"`
+	Sha256Hash string `json:"sha256Hash"`
+	Query      string `json:"query"`
+}
+
+// The example operations sorted by name, the query is the file's trimmed content
+func PersistedOperations() []PersistedOperation {
+	files, err := fs.Glob(operationFiles, "operations/*.graphql")
+	if err != nil {
+		panic(err)
+	}
+	operations := make([]PersistedOperation, len(files))
+	for i, file := range files {
+		b, err := operationFiles.ReadFile(file)
+		if err != nil {
+			panic(err)
+		}
+		query := strings.TrimSpace(string(b))
+		hash := sha256.Sum256([]byte(query))
+		operations[i] = PersistedOperation{
+			Name:       strings.TrimSuffix(path.Base(file), ".graphql"),
+			Sha256Hash: hex.EncodeToString(hash[:]),
+			Query:      query,
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].Name < operations[j].Name
+	})
+	return operations
+}
+
+// Cache of automatic persisted queries that always has the example operations
+// Other queries clients register are kept until size more push them out
+type PersistedQueryCache struct {
+	persisted map[string]string
+	cache     *lru.LRU
+}
+
+var _ graphql.Cache = &PersistedQueryCache{}
+
+func NewPersistedQueryCache(size int) *PersistedQueryCache {
+	persisted := make(map[string]string)
+	for _, operation := range PersistedOperations() {
+		persisted[operation.Sha256Hash] = operation.Query
+	}
+	return &PersistedQueryCache{persisted: persisted, cache: lru.New(size)}
+}
+
+func (c *PersistedQueryCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	if query, ok := c.persisted[key]; ok {
+		return query, true
+	}
+	return c.cache.Get(ctx, key)
+}
+
+func (c *PersistedQueryCache) Add(ctx context.Context, key string, value interface{}) {
+	if _, ok := c.persisted[key]; ok {
+		return
+	}
+	c.cache.Add(ctx, key, value)
+}
+
+// The schema as SDL for client tooling, with the federation directives and types gqlgen adds
+// so it can be used on its own. Descriptions are comments in our schema and aren't kept
+func SchemaSDL(schema *ast.Schema) string {
+	var b strings.Builder
+	f := formatter.NewFormatter(&b, formatter.WithIndent("  "))
+	f.FormatSchema(schema)
+
+	// Built into gqlgen, the formatter leaves them out
+	builtIn := &ast.SchemaDocument{}
+	for _, directive := range schema.Directives {
+		if directive.Position != nil && directive.Position.Src.BuiltIn && directive.Position.Src.Name != "prelude.graphql" {
+			copied := *directive
+			copied.Position = &ast.Position{Src: &ast.Source{}}
+			builtIn.Directives = append(builtIn.Directives, &copied)
+		}
+	}
+	for name, def := range schema.Types {
+		if def.BuiltIn && strings.HasPrefix(name, "_") && !strings.HasPrefix(name, "__") {
+			copied := *def
+			copied.BuiltIn = false
+			builtIn.Definitions = append(builtIn.Definitions, &copied)
+		}
+	}
+	sort.Slice(builtIn.Directives, func(i, j int) bool {
+		return builtIn.Directives[i].Name < builtIn.Directives[j].Name
+	})
+	sort.Slice(builtIn.Definitions, func(i, j int) bool {
+		return builtIn.Definitions[i].Name < builtIn.Definitions[j].Name
+	})
+	f.FormatSchemaDocument(builtIn)
+	return b.String()
+}
+
+// Serves the SDL at /graphql/schema.graphql, introspection is off in production
+func SchemaHandler(schema *ast.Schema) http.HandlerFunc {
+	sdl := SchemaSDL(schema)
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/graphql; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Write([]byte(sdl))
+	}
+}
+
+// Serves the example operations with their hashes at /graphql/operations.json
+func OperationsHandler() http.HandlerFunc {
+	b, err := json.Marshal(PersistedOperations())
+	if err != nil {
+		panic(err)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Write(b)
+	}
+}
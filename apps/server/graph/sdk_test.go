@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/bananocoin/boompow/apps/server/graph/generated"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestSchemaSDL(t *testing.T) {
+	sdl := SchemaSDL(generated.NewExecutableSchema(generated.Config{}).Schema())
+	// SDK generators only get the SDL, it has to stand on its own
+	schema, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: sdl})
+	utils.AssertEqual(t, true, err == nil)
+	utils.AssertEqual(t, true, schema.Types["_Any"] != nil)
+	utils.AssertEqual(t, true, schema.Directives["key"] != nil)
+
+	operations := PersistedOperations()
+	utils.AssertEqual(t, true, len(operations) > 0)
+	for _, operation := range operations {
+		doc, errs := gqlparser.LoadQuery(schema, operation.Query)
+		utils.AssertEqual(t, 0, len(errs))
+		// The file is named after its only operation
+		utils.AssertEqual(t, 1, len(doc.Operations))
+		utils.AssertEqual(t, operation.Name, doc.Operations[0].Name)
+	}
+}
+
+func TestSDKSchemaUpToDate(t *testing.T) {
+	// The SDKs are generated from the committed copy, make sdk-schema updates it
+	committed, err := os.ReadFile("../../../libs/sdk/schema.graphql")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, SchemaSDL(generated.NewExecutableSchema(generated.Config{}).Schema()), string(committed))
+}
+
+func TestPersistedQueryCache(t *testing.T) {
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: &Resolver{}, Directives: generated.DirectiveRoot{Auth: Auth, HasPermission: HasPermission}}))
+	srv.AddTransport(transport.POST{})
+	cache := NewPersistedQueryCache(1)
+	srv.Use(extension.AutomaticPersistedQuery{Cache: cache})
+	post := func(body string) string {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	var presets PersistedOperation
+	for _, operation := range PersistedOperations() {
+		if operation.Name == "DifficultyPresets" {
+			presets = operation
+		}
+	}
+	// The example operations run by their hash without being sent first
+	body := post(`{"extensions": {"persistedQuery": {"version": 1, "sha256Hash": "` + presets.Sha256Hash + `"}}}`)
+	utils.AssertEqual(t, true, strings.Contains(body, `"difficultyPresets":[`))
+	body = post(`{"extensions": {"persistedQuery": {"version": 1, "sha256Hash": "unknown"}}}`)
+	utils.AssertEqual(t, true, strings.Contains(body, "PERSISTED_QUERY_NOT_FOUND"))
+
+	// Registered queries don't push them out
+	cache.Add(context.Background(), "a", "{ a }")
+	cache.Add(context.Background(), "b", "{ b }")
+	_, ok := cache.Get(context.Background(), "a")
+	utils.AssertEqual(t, false, ok)
+	_, ok = cache.Get(context.Background(), "b")
+	utils.AssertEqual(t, true, ok)
+	query, ok := cache.Get(context.Background(), presets.Sha256Hash)
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, presets.Query, query)
+}
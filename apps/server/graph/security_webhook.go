@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/alerts"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
+)
+
+// Account events posted to security webhooks and their event names
+// Logins are only posted when the account never logged in from the IP before
+var securityEvents = map[models.AccountEventType]string{
+	models.AccountEventLogin:                  "new_device_login",
+	models.AccountEventPasswordChanged:        "password_changed",
+	models.AccountEventTwoFactorEnabled:       "two_factor_enabled",
+	models.AccountEventTwoFactorDisabled:      "two_factor_disabled",
+	models.AccountEventAccountRecovered:       "account_recovered",
+	models.AccountEventServiceTokenCreated:    "token_created",
+	models.AccountEventSecurityWebhookChanged: "security_webhook_changed",
+}
+
+// The event to post for an account event, if the user registered a webhook
+// Has to run before the event is recorded to tell whether a login is from a new IP
+func (r *Resolver) securityEvent(userID uuid.UUID, eventType models.AccountEventType, clientIP string) (string, bool) {
+	name, ok := securityEvents[eventType]
+	if !ok || r.SecurityWebhookRepo == nil || r.SecurityNotifier == nil {
+		return "", false
+	}
+	if eventType == models.AccountEventLogin {
+		if clientIP == "" {
+			return "", false
+		}
+		seen, err := r.ActivityRepo.HasLoggedInFrom(userID, clientIP)
+		if err != nil {
+			klog.Errorf("Error checking previous logins %v", err)
+			return "", false
+		}
+		if seen {
+			return "", false
+		}
+	}
+	return name, true
+}
+
+func (r *Resolver) postSecurityEvent(userID uuid.UUID, name string, detail string, clientIP string) {
+	webhook, err := r.SecurityWebhookRepo.GetSecurityWebhook(userID)
+	if err != nil {
+		klog.Errorf("Error retrieving security webhook %v", err)
+		return
+	}
+	if webhook == nil {
+		return
+	}
+	user, err := r.UserRepo.GetUser(&userID, nil)
+	if err != nil {
+		klog.Errorf("Error retrieving user for security webhook %v", err)
+		return
+	}
+	now := r.now()
+	event := alerts.SecurityEvent{
+		Event:      name,
+		Email:      user.Email,
+		Detail:     detail,
+		ClientIP:   clientIP,
+		OccurredAt: now.UTC().Format(time.RFC3339),
+	}
+	if err := r.SecurityNotifier.NotifySecurityEvent(webhook, event, now); err != nil {
+		klog.Errorf("Error sending %s security webhook %v", name, err)
+	}
+}
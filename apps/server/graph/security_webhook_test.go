@@ -0,0 +1,48 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/alerts"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+type seenLoginsRepo struct {
+	repository.ActivityRepo
+	ips map[string]bool
+}
+
+func (r *seenLoginsRepo) HasLoggedInFrom(userID uuid.UUID, clientIP string) (bool, error) {
+	return r.ips[clientIP], nil
+}
+
+func TestSecurityEvent(t *testing.T) {
+	userID := uuid.New()
+	r := &Resolver{}
+	// Nothing without a webhook repo and notifier
+	_, ok := r.securityEvent(userID, models.AccountEventPasswordChanged, "10.0.0.1")
+	utils.AssertEqual(t, false, ok)
+
+	r = &Resolver{
+		ActivityRepo:        &seenLoginsRepo{ips: map[string]bool{"10.0.0.1": true}},
+		SecurityWebhookRepo: &repository.SecurityWebhookService{},
+		SecurityNotifier:    alerts.NewWebhookNotifier(),
+	}
+	name, ok := r.securityEvent(userID, models.AccountEventPasswordChanged, "10.0.0.1")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, "password_changed", name)
+	name, _ = r.securityEvent(userID, models.AccountEventServiceTokenCreated, "")
+	utils.AssertEqual(t, "token_created", name)
+	_, ok = r.securityEvent(userID, models.AccountEventSettingsChanged, "10.0.0.1")
+	utils.AssertEqual(t, false, ok)
+
+	// Only logins from an IP the account didn't log in from before
+	_, ok = r.securityEvent(userID, models.AccountEventLogin, "10.0.0.1")
+	utils.AssertEqual(t, false, ok)
+	name, ok = r.securityEvent(userID, models.AccountEventLogin, "10.0.0.2")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, "new_device_login", name)
+}
@@ -0,0 +1,18 @@
+package graph
+
+import (
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+func staleAccountReportToModel(report models.StaleAccountReport) *model.StaleAccountReport {
+	return &model.StaleAccountReport{
+		ID:         report.ID.String(),
+		CreatedAt:  utils.GenerateISOString(report.CreatedAt),
+		Reminded:   report.Reminded,
+		Disabled:   report.Disabled,
+		Anonymized: report.Anonymized,
+		Failed:     report.Failed,
+	}
+}
@@ -0,0 +1,19 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+)
+
+// Start of the period covered by a stats range
+func statsRangeSince(statsRange model.StatsRange, now time.Time) time.Time {
+	switch statsRange {
+	case model.StatsRangeWeek:
+		return now.Add(-7 * 24 * time.Hour)
+	case model.StatsRangeMonth:
+		return now.Add(-30 * 24 * time.Hour)
+	default:
+		return now.Add(-24 * time.Hour)
+	}
+}
@@ -0,0 +1,30 @@
+package graph
+
+import (
+	"errors"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+)
+
+func loginResponse(user *models.User, tokens *model.TokenPair) *model.LoginResponse {
+	return &model.LoginResponse{
+		Token:          tokens.Token,
+		RefreshToken:   tokens.RefreshToken,
+		Type:           model.UserType(user.Type),
+		BanAddress:     user.BanAddress,
+		ServiceName:    user.ServiceName,
+		ServiceWebsite: user.ServiceWebsite,
+		EmailVerified:  user.EmailVerified,
+		Email:          user.Email,
+	}
+}
+
+// The errors users can act on are passed on, anything else stays generic
+func twoFactorError(err error) error {
+	if errors.Is(err, repository.ErrTwoFactorEnabled) || errors.Is(err, repository.ErrTwoFactorNotEnabled) || errors.Is(err, repository.ErrInvalidTwoFactor) {
+		return errors.New("bad_request:" + err.Error())
+	}
+	return errors.New("error updating two factor authentication")
+}
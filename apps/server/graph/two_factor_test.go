@@ -0,0 +1,16 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestTwoFactorError(t *testing.T) {
+	utils.AssertEqual(t, "bad_request:invalid two factor code", twoFactorError(repository.ErrInvalidTwoFactor).Error())
+	utils.AssertEqual(t, "bad_request:two factor authentication is already enabled", twoFactorError(repository.ErrTwoFactorEnabled).Error())
+	// Database errors aren't shown to users
+	utils.AssertEqual(t, "error updating two factor authentication", twoFactorError(errors.New("connection refused")).Error())
+}
@@ -0,0 +1,26 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+)
+
+func usageToModel(month time.Time, closed bool, usage []models.UsageRollup) *model.UsageStatement {
+	statement := &model.UsageStatement{
+		Month:  month.Format("2006-01-02"),
+		Closed: closed,
+		Lines:  make([]*model.UsageLine, len(usage)),
+	}
+	for i, u := range usage {
+		statement.Requests += int(u.Requests)
+		statement.Cached += int(u.Cached)
+		statement.Lines[i] = &model.UsageLine{
+			DifficultyMultiplier: u.DifficultyMultiplier,
+			Requests:             int(u.Requests),
+			Cached:               int(u.Cached),
+		}
+	}
+	return statement
+}
@@ -0,0 +1,16 @@
+package graph
+
+import (
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/email"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"k8s.io/klog/v2"
+)
+
+// Tells a requester they can request work, like when their service is approved with the emailed token
+func approveService(user *models.User) {
+	database.GetRedisDB().DeleteApproveServiceToken(user.Email)
+	if err := email.SendServiceApprovedEmail(user.Email); err != nil {
+		klog.Errorf("Error sending service approved email to %s %v", user.Email, err)
+	}
+}
@@ -0,0 +1,20 @@
+package graph
+
+import (
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+)
+
+// Loaders for the user's associations, shared by every resolver of the request
+
+func (r *Resolver) payoutAddressesFor(user *middleware.UserContextValue) ([]models.PayoutAddress, error) {
+	return middleware.LoadForUser(user, "payout_addresses", func() ([]models.PayoutAddress, error) {
+		return r.PayoutRepo.GetPayoutAddresses(user.User.ID)
+	})
+}
+
+func (r *Resolver) unpaidWorkFor(user *middleware.UserContextValue) (int, error) {
+	return middleware.LoadForUser(user, "unpaid_work", func() (int, error) {
+		return r.WorkRepo.GetUnpaidWorkSumForUser(user.User.Email)
+	})
+}
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Error code of work requests of a source that used up its daily quota
+const SourceQuotaExceededCode = "SOURCE_QUOTA_EXCEEDED"
+
+var errSourceQuotaExceeded = errors.New("bad_request:daily quota of this work source reached")
+
+// Returns the trimmed name
+func validateWorkSourceInput(name string, dailyQuota *int) (string, error) {
+	name = strings.TrimSpace(name)
+	if !models.ValidWorkSourceName(name) {
+		return "", errors.New("bad_request:name must be 1 to 32 lower case letters, digits, dashes or underscores")
+	}
+	if dailyQuota != nil && *dailyQuota < 1 {
+		return "", errors.New("bad_request:dailyQuota must be positive")
+	}
+	return name, nil
+}
+
+func workSourceError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrWorkSourceExists):
+		return errors.New("bad_request:a work source with this name already exists")
+	case errors.Is(err, repository.ErrTooManyWorkSources):
+		return fmt.Errorf("bad_request:at most %d work sources, delete one first", config.MAX_WORK_SOURCES_PER_USER)
+	}
+	return errors.New("error creating work source")
+}
+
+// Counts the request against the source's quota, sources without a quota are counted too so requestsToday stays right
+func checkSourceQuota(ctx context.Context, source *models.WorkSource, now time.Time) error {
+	requests, err := database.GetRedisDB().IncrementSourceRequests(source.ID, now)
+	if err != nil {
+		// Not worth failing the request over
+		logging.Errorf(logging.Stats, "Error counting requests of work source %s %v", source.ID, err)
+		return nil
+	}
+	if source.DailyQuota == nil || requests <= int64(*source.DailyQuota) {
+		return nil
+	}
+	gqlErr := gqlerror.WrapPath(graphql.GetPath(ctx), errSourceQuotaExceeded)
+	gqlErr.Extensions = map[string]interface{}{
+		"code":       SourceQuotaExceededCode,
+		"dailyQuota": *source.DailyQuota,
+	}
+	return gqlErr
+}
+
+func workSourceToModel(source *models.WorkSource, requestsToday int64) *model.WorkSource {
+	return &model.WorkSource{
+		Name:          source.Name,
+		DailyQuota:    source.DailyQuota,
+		RequestsToday: int(requestsToday),
+		CreatedAt:     utils.GenerateISOString(source.CreatedAt),
+	}
+}
+
+func (r *Resolver) workSourceToModel(source *models.WorkSource) *model.WorkSource {
+	requests, err := database.GetRedisDB().GetSourceRequests(source.ID, r.now())
+	if err != nil {
+		logging.Errorf(logging.Stats, "Error getting requests of work source %s %v", source.ID, err)
+	}
+	return workSourceToModel(source, requests)
+}
+
+func sourceUsageToModel(usage []repository.SourceUsage) []*model.SourceUsage {
+	ret := make([]*model.SourceUsage, len(usage))
+	for i, u := range usage {
+		ret[i] = &model.SourceUsage{Source: u.Source, Requests: int(u.Requests), Cached: int(u.Cached)}
+	}
+	return ret
+}
+
+// Errors are only logged, the work request itself succeeded
+func (r *Resolver) recordSourceUsage(ctx context.Context, cached bool) {
+	source := middleware.RequestSource(ctx)
+	if source == nil || r.WorkSourceRepo == nil {
+		return
+	}
+	if err := r.WorkSourceRepo.RecordSourceUsage(source, cached, r.now()); err != nil {
+		logging.Errorf(logging.Stats, "Error recording usage of work source %s %v", source.ID, err)
+	}
+}
@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/utils/clock"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestValidateWorkSourceInput(t *testing.T) {
+	name, err := validateWorkSourceInput(" staging ", nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "staging", name)
+
+	_, err = validateWorkSourceInput("Staging", nil)
+	utils.AssertNotEqual(t, nil, err)
+	_, err = validateWorkSourceInput("", nil)
+	utils.AssertNotEqual(t, nil, err)
+	zero := 0
+	_, err = validateWorkSourceInput("faucet", &zero)
+	utils.AssertNotEqual(t, nil, err)
+	quota := 1000
+	_, err = validateWorkSourceInput("faucet", &quota)
+	utils.AssertEqual(t, nil, err)
+}
+
+func TestWorkSourceError(t *testing.T) {
+	utils.AssertEqual(t, "bad_request:a work source with this name already exists", workSourceError(repository.ErrWorkSourceExists).Error())
+	utils.AssertEqual(t, "bad_request:at most 20 work sources, delete one first", workSourceError(repository.ErrTooManyWorkSources).Error())
+	utils.AssertEqual(t, "error creating work source", workSourceError(errors.New("connection refused")).Error())
+}
+
+func TestCheckSourceQuota(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	now := time.Date(2022, 10, 3, 23, 0, 0, 0, time.UTC)
+	quota := 2
+	source := &models.WorkSource{Base: models.Base{ID: uuid.New()}, Name: "faucet", DailyQuota: &quota}
+
+	utils.AssertEqual(t, nil, checkSourceQuota(context.Background(), source, now))
+	utils.AssertEqual(t, nil, checkSourceQuota(context.Background(), source, now))
+	err := checkSourceQuota(context.Background(), source, now)
+	var gqlErr *gqlerror.Error
+	utils.AssertEqual(t, true, errors.As(err, &gqlErr))
+	utils.AssertEqual(t, SourceQuotaExceededCode, gqlErr.Extensions["code"])
+
+	// The quota starts over the next UTC day
+	utils.AssertEqual(t, nil, checkSourceQuota(context.Background(), source, now.Add(2*time.Hour)))
+
+	// Sources without a quota are only counted
+	source.DailyQuota = nil
+	utils.AssertEqual(t, nil, checkSourceQuota(context.Background(), source, now))
+	r := &Resolver{Clock: clock.NewFake(now)}
+	utils.AssertEqual(t, 4, r.workSourceToModel(source).RequestsToday)
+}
@@ -0,0 +1,24 @@
+package graph
+
+import (
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/controller"
+	utils "github.com/bananocoin/boompow/libs/utils/format"
+)
+
+func workerAbuseStatsToModel(stats controller.AbuseStats) *model.WorkerAbuseStats {
+	quarantined := make([]*model.QuarantinedWorker, len(stats.Quarantined))
+	for i, worker := range stats.Quarantined {
+		quarantined[i] = &model.QuarantinedWorker{
+			IPAddress: worker.IPAddress,
+			Email:     worker.Email,
+			Until:     utils.GenerateISOString(worker.Until),
+		}
+	}
+	return &model.WorkerAbuseStats{
+		MalformedFrames: stats.MalformedFrames,
+		OversizedFrames: stats.OversizedFrames,
+		Quarantines:     stats.Quarantines,
+		Quarantined:     quarantined,
+	}
+}
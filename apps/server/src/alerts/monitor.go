@@ -0,0 +1,173 @@
+// Package alerts notifies providers who opted in when all of their workers went offline
+package alerts
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"k8s.io/klog/v2"
+)
+
+type Notifier interface {
+	NotifyOffline(alert *models.OfflineAlert, email string, offlineSince time.Time) error
+}
+
+// Time around maintenance doesn't count as offline
+type MaintenanceSchedule interface {
+	DiscountMaintenance(since time.Time, now time.Time) time.Time
+}
+
+// Workers whose websocket died can keep reporting over HTTP, they aren't offline while they do
+type Heartbeats interface {
+	GetWorkerHeartbeat(email string) (time.Time, error)
+}
+
+type providerState struct {
+	connected int
+	// Zero while any worker is connected
+	offlineSince time.Time
+	// Whether the current offline period was alerted already
+	alerted     bool
+	lastAlertAt time.Time
+}
+
+// Tracks the connected workers of every provider from hub events
+type OfflineMonitor struct {
+	mu        sync.Mutex
+	providers map[string]*providerState
+	repo      repository.AlertRepo
+	notifier  Notifier
+	// Optional
+	maintenance MaintenanceSchedule
+	// Optional
+	heartbeats Heartbeats
+}
+
+func NewOfflineMonitor(repo repository.AlertRepo, notifier Notifier, maintenance MaintenanceSchedule, heartbeats Heartbeats) *OfflineMonitor {
+	return &OfflineMonitor{
+		providers:   make(map[string]*providerState),
+		repo:        repo,
+		notifier:    notifier,
+		maintenance: maintenance,
+		heartbeats:  heartbeats,
+	}
+}
+
+// Whether the provider reported over HTTP recently
+func (m *OfflineMonitor) heartbeating(email string, now time.Time) bool {
+	if m.heartbeats == nil {
+		return false
+	}
+	last, err := m.heartbeats.GetWorkerHeartbeat(email)
+	if err != nil {
+		klog.Errorf("Error getting heartbeat for %s %v", email, err)
+		return false
+	}
+	return !last.IsZero() && now.Sub(last) < config.WORKER_HEARTBEAT_TTL_SECONDS*time.Second
+}
+
+// How long the provider counts as offline, which excludes maintenance
+func (m *OfflineMonitor) countedOffline(offlineSince time.Time, now time.Time) time.Duration {
+	if m.maintenance != nil {
+		offlineSince = m.maintenance.DiscountMaintenance(offlineSince, now)
+	}
+	return now.Sub(offlineSince)
+}
+
+// Hub event listener, it only updates state so it never blocks the hub
+func (m *OfflineMonitor) HandleEvent(event models.HubEvent) {
+	if event.ClientEmail == "" || (event.Type != models.HubEventConnect && event.Type != models.HubEventDisconnect) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.providers[event.ClientEmail]
+	if !ok {
+		state = &providerState{}
+		m.providers[event.ClientEmail] = state
+	}
+	if event.Type == models.HubEventConnect {
+		state.connected++
+		state.offlineSince = time.Time{}
+		state.alerted = false
+		return
+	}
+	if state.connected > 0 {
+		state.connected--
+	}
+	if state.connected == 0 && state.offlineSince.IsZero() {
+		state.offlineSince = event.Timestamp
+	}
+}
+
+// Alerts providers whose workers have all been offline for longer than they asked for
+// Workers have to stay offline for the whole period and alerts are at most once per cooldown, so flapping connections don't spam anyone
+func (m *OfflineMonitor) Check(now time.Time) {
+	candidates := make(map[string]time.Time)
+	func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for email, state := range m.providers {
+			if state.connected > 0 {
+				continue
+			}
+			offline := m.countedOffline(state.offlineSince, now)
+			if offline > (config.OFFLINE_ALERT_MAX_MINUTES+config.OFFLINE_ALERT_COOLDOWN_MINUTES)*time.Minute {
+				// Past any alert delay, forget about them until they connect again
+				delete(m.providers, email)
+				continue
+			}
+			if state.alerted || offline < config.OFFLINE_ALERT_MIN_MINUTES*time.Minute || now.Sub(state.lastAlertAt) < config.OFFLINE_ALERT_COOLDOWN_MINUTES*time.Minute {
+				continue
+			}
+			candidates[email] = state.offlineSince
+		}
+	}()
+
+	for email, offlineSince := range candidates {
+		if m.heartbeating(email, now) {
+			continue
+		}
+		alert, err := m.repo.GetOfflineAlertForEmail(email)
+		if err != nil {
+			klog.Errorf("Error getting offline alert for %s %v", email, err)
+			continue
+		}
+		if alert == nil {
+			// Not opted in, no need to keep checking until they connect again
+			m.forget(email, offlineSince)
+			continue
+		}
+		if m.countedOffline(offlineSince, now) < time.Duration(alert.AfterMinutes)*time.Minute {
+			continue
+		}
+		if err := m.notifier.NotifyOffline(alert, email, offlineSince); err != nil {
+			klog.Errorf("Error sending offline alert to %s %v", email, err)
+			continue
+		}
+		m.markAlerted(email, offlineSince, now)
+	}
+}
+
+// Only if nothing happened since the check started
+func (m *OfflineMonitor) forget(email string, offlineSince time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if state, ok := m.providers[email]; ok && state.connected == 0 && state.offlineSince.Equal(offlineSince) {
+		delete(m.providers, email)
+	}
+}
+
+func (m *OfflineMonitor) markAlerted(email string, offlineSince time.Time, at time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if state, ok := m.providers[email]; ok {
+		state.lastAlertAt = at
+		if state.offlineSince.Equal(offlineSince) {
+			state.alerted = true
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+type fakeAlertRepo struct {
+	alerts map[string]*models.OfflineAlert
+}
+
+func (r *fakeAlertRepo) SetOfflineAlert(userID uuid.UUID, channel models.AlertChannel, target string, afterMinutes int) (*models.OfflineAlert, error) {
+	return nil, nil
+}
+
+func (r *fakeAlertRepo) DeleteOfflineAlert(userID uuid.UUID) error {
+	return nil
+}
+
+func (r *fakeAlertRepo) GetOfflineAlert(userID uuid.UUID) (*models.OfflineAlert, error) {
+	return nil, nil
+}
+
+func (r *fakeAlertRepo) GetOfflineAlertForEmail(email string) (*models.OfflineAlert, error) {
+	return r.alerts[email], nil
+}
+
+type fakeNotifier struct {
+	sent []string
+}
+
+func (n *fakeNotifier) NotifyOffline(alert *models.OfflineAlert, email string, offlineSince time.Time) error {
+	n.sent = append(n.sent, email)
+	return nil
+}
+
+func TestOfflineMonitor(t *testing.T) {
+	repo := &fakeAlertRepo{alerts: map[string]*models.OfflineAlert{
+		"alice@example.com": {Channel: models.AlertChannelEmail, AfterMinutes: 10},
+	}}
+	notifier := &fakeNotifier{}
+	monitor := NewOfflineMonitor(repo, notifier, nil, nil)
+	start := time.Now()
+	event := func(eventType models.HubEventType, email string, at time.Time) {
+		monitor.HandleEvent(models.HubEvent{Type: eventType, ClientEmail: email, Timestamp: at})
+	}
+
+	// Two workers, one disconnecting doesn't make the provider offline
+	event(models.HubEventConnect, "alice@example.com", start)
+	event(models.HubEventConnect, "alice@example.com", start)
+	event(models.HubEventConnect, "bob@example.com", start)
+	event(models.HubEventDisconnect, "alice@example.com", start)
+	event(models.HubEventDisconnect, "bob@example.com", start)
+	monitor.Check(start.Add(20 * time.Minute))
+	utils.AssertEqual(t, 0, len(notifier.sent))
+
+	// Offline, but not long enough yet
+	event(models.HubEventDisconnect, "alice@example.com", start.Add(20*time.Minute))
+	monitor.Check(start.Add(25 * time.Minute))
+	utils.AssertEqual(t, 0, len(notifier.sent))
+
+	// Flapping resets the timer
+	event(models.HubEventConnect, "alice@example.com", start.Add(26*time.Minute))
+	event(models.HubEventDisconnect, "alice@example.com", start.Add(27*time.Minute))
+	monitor.Check(start.Add(32 * time.Minute))
+	utils.AssertEqual(t, 0, len(notifier.sent))
+
+	monitor.Check(start.Add(37 * time.Minute))
+	utils.AssertEqual(t, []string{"alice@example.com"}, notifier.sent)
+	// Only once per offline period
+	monitor.Check(start.Add(50 * time.Minute))
+	utils.AssertEqual(t, 1, len(notifier.sent))
+
+	// Back and gone again within the cooldown
+	event(models.HubEventConnect, "alice@example.com", start.Add(51*time.Minute))
+	event(models.HubEventDisconnect, "alice@example.com", start.Add(52*time.Minute))
+	monitor.Check(start.Add(70 * time.Minute))
+	utils.AssertEqual(t, 1, len(notifier.sent))
+	// After the cooldown
+	monitor.Check(start.Add(98 * time.Minute))
+	utils.AssertEqual(t, 2, len(notifier.sent))
+
+	// Bob never opted in
+	_, tracked := monitor.providers["bob@example.com"]
+	utils.AssertEqual(t, false, tracked)
+}
+
+type fakeMaintenance struct {
+	startsAt time.Time
+	endsAt   time.Time
+}
+
+func (m *fakeMaintenance) DiscountMaintenance(since time.Time, now time.Time) time.Time {
+	if !m.startsAt.After(now) && m.endsAt.After(since) {
+		return m.endsAt
+	}
+	return since
+}
+
+func TestOfflineMonitorMaintenance(t *testing.T) {
+	repo := &fakeAlertRepo{alerts: map[string]*models.OfflineAlert{
+		"alice@example.com": {Channel: models.AlertChannelEmail, AfterMinutes: 10},
+	}}
+	notifier := &fakeNotifier{}
+	start := time.Now()
+	monitor := NewOfflineMonitor(repo, notifier, &fakeMaintenance{startsAt: start, endsAt: start.Add(30 * time.Minute)}, nil)
+
+	monitor.HandleEvent(models.HubEvent{Type: models.HubEventConnect, ClientEmail: "alice@example.com", Timestamp: start})
+	monitor.HandleEvent(models.HubEvent{Type: models.HubEventDisconnect, ClientEmail: "alice@example.com", Timestamp: start.Add(time.Minute)})
+	// Offline during maintenance doesn't count
+	monitor.Check(start.Add(25 * time.Minute))
+	utils.AssertEqual(t, 0, len(notifier.sent))
+	monitor.Check(start.Add(35 * time.Minute))
+	utils.AssertEqual(t, 0, len(notifier.sent))
+	// Counted from the end of the window
+	monitor.Check(start.Add(41 * time.Minute))
+	utils.AssertEqual(t, 1, len(notifier.sent))
+}
+
+type fakeHeartbeats struct {
+	last map[string]time.Time
+}
+
+func (h *fakeHeartbeats) GetWorkerHeartbeat(email string) (time.Time, error) {
+	return h.last[email], nil
+}
+
+func TestOfflineMonitorHeartbeats(t *testing.T) {
+	repo := &fakeAlertRepo{alerts: map[string]*models.OfflineAlert{
+		"alice@example.com": {Channel: models.AlertChannelEmail, AfterMinutes: 10},
+	}}
+	notifier := &fakeNotifier{}
+	start := time.Now()
+	heartbeats := &fakeHeartbeats{last: map[string]time.Time{"alice@example.com": start.Add(20 * time.Minute)}}
+	monitor := NewOfflineMonitor(repo, notifier, nil, heartbeats)
+
+	monitor.HandleEvent(models.HubEvent{Type: models.HubEventConnect, ClientEmail: "alice@example.com", Timestamp: start})
+	monitor.HandleEvent(models.HubEvent{Type: models.HubEventDisconnect, ClientEmail: "alice@example.com", Timestamp: start.Add(time.Minute)})
+	// Still reporting over HTTP
+	monitor.Check(start.Add(21 * time.Minute))
+	utils.AssertEqual(t, 0, len(notifier.sent))
+	// Stopped
+	monitor.Check(start.Add(30 * time.Minute))
+	utils.AssertEqual(t, 1, len(notifier.sent))
+}
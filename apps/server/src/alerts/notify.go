@@ -0,0 +1,66 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/email"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+)
+
+// Sends alerts by email or to the provider's webhook
+type WebhookNotifier struct {
+	Client *http.Client
+}
+
+var _ Notifier = &WebhookNotifier{}
+
+func NewWebhookNotifier() *WebhookNotifier {
+	return &WebhookNotifier{
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Body of webhook alerts
+type OfflineWebhook struct {
+	Event        string `json:"event"`
+	Email        string `json:"email"`
+	OfflineSince string `json:"offline_since"`
+}
+
+func (n *WebhookNotifier) NotifyOffline(alert *models.OfflineAlert, providerEmail string, offlineSince time.Time) error {
+	switch alert.Channel {
+	case models.AlertChannelEmail:
+		return email.SendOfflineAlertEmail(providerEmail, offlineSince)
+	case models.AlertChannelWebhook:
+		return n.post(alert.Target, OfflineWebhook{
+			Event:        "provider_offline",
+			Email:        providerEmail,
+			OfflineSince: offlineSince.UTC().Format(time.RFC3339),
+		})
+	case models.AlertChannelDiscord:
+		return n.post(alert.Target, map[string]string{
+			"content": fmt.Sprintf("⚠️ None of the BoomPoW workers of %s have been connected since %s", providerEmail, offlineSince.UTC().Format("2006-01-02 15:04 UTC")),
+		})
+	}
+	return fmt.Errorf("unknown alert channel %s", alert.Channel)
+}
+
+func (n *WebhookNotifier) post(url string, body interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := n.Client.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %d", resp.StatusCode)
+	}
+	return nil
+}
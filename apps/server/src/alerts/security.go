@@ -0,0 +1,64 @@
+package alerts
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+)
+
+// Header with the signature of security webhooks, t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">
+const SecuritySignatureHeader = "X-BoomPow-Signature"
+
+type SecurityNotifier interface {
+	NotifySecurityEvent(webhook *models.SecurityWebhook, event SecurityEvent, now time.Time) error
+}
+
+var _ SecurityNotifier = &WebhookNotifier{}
+
+// Body of security webhooks
+type SecurityEvent struct {
+	Event      string `json:"event"`
+	Email      string `json:"email"`
+	Detail     string `json:"detail,omitempty"`
+	ClientIP   string `json:"client_ip,omitempty"`
+	OccurredAt string `json:"occurred_at"`
+}
+
+// The timestamp is signed too, so receivers can refuse replayed events
+func SignSecurityEvent(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%s,v1=%s", t, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func (n *WebhookNotifier) NotifySecurityEvent(webhook *models.SecurityWebhook, event SecurityEvent, now time.Time) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SecuritySignatureHeader, SignSecurityEvent(webhook.Secret, now, b))
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("security webhook responded with %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package alerts
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestNotifySecurityEvent(t *testing.T) {
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SecuritySignatureHeader)
+	}))
+	defer server.Close()
+
+	now := time.Unix(1664614800, 0)
+	webhook := &models.SecurityWebhook{URL: server.URL, Secret: "secret"}
+	err := NewWebhookNotifier().NotifySecurityEvent(webhook, SecurityEvent{Event: "password_changed", Email: "joe@example.com", OccurredAt: now.UTC().Format(time.RFC3339)}, now)
+	utils.AssertEqual(t, nil, err)
+
+	var event SecurityEvent
+	utils.AssertEqual(t, nil, json.Unmarshal(body, &event))
+	utils.AssertEqual(t, "password_changed", event.Event)
+	// Receivers sign the timestamp and the body they got with their secret
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1664614800."))
+	mac.Write(body)
+	utils.AssertEqual(t, "t=1664614800,v1="+hex.EncodeToString(mac.Sum(nil)), signature)
+	utils.AssertNotEqual(t, signature, SignSecurityEvent("other", now, body))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	webhook.URL = failing.URL
+	utils.AssertNotEqual(t, nil, NewWebhookNotifier().NotifySecurityEvent(webhook, SecurityEvent{Event: "password_changed"}, now))
+}
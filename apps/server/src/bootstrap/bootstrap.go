@@ -0,0 +1,110 @@
+// Package bootstrap creates the initial admin and services of a fresh deployment from configuration, it's safe to run on every start
+package bootstrap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/utils"
+	"k8s.io/klog/v2"
+)
+
+type Service struct {
+	Name    string `json:"name"`
+	Website string `json:"website"`
+	Tenant  string `json:"tenant"`
+	// Optional, read from tokenFile if that's set instead, a token is generated when there is neither
+	Token     string `json:"token"`
+	TokenFile string `json:"tokenFile"`
+}
+
+type Config struct {
+	AdminEmail    string
+	AdminPassword string
+	Services      []Service
+}
+
+func (c *Config) Empty() bool {
+	return c.AdminEmail == "" && len(c.Services) == 0
+}
+
+// Reads the configuration from the environment, secrets can be mounted as files with the _FILE variables
+func LoadConfig() (*Config, error) {
+	password, err := utils.GetSecret("BPOW_BOOTSTRAP_ADMIN_PASSWORD")
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{
+		AdminEmail:    utils.GetBootstrapAdminEmail(),
+		AdminPassword: password,
+	}
+	if path := utils.GetBootstrapServicesFile(); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Services, err = ParseServices(b); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+func ParseServices(b []byte) ([]Service, error) {
+	var services []Service
+	if err := json.Unmarshal(b, &services); err != nil {
+		return nil, fmt.Errorf("invalid bootstrap services file %v", err)
+	}
+	for i := range services {
+		if services[i].Name == "" || services[i].Website == "" {
+			return nil, errors.New("bootstrap services need a name and website")
+		}
+		if services[i].Tenant == "" {
+			services[i].Tenant = config.DEFAULT_TENANT_ID
+		}
+		if services[i].Token == "" && services[i].TokenFile != "" {
+			b, err := os.ReadFile(services[i].TokenFile)
+			if err != nil {
+				return nil, err
+			}
+			services[i].Token = strings.TrimSpace(string(b))
+		}
+	}
+	return services, nil
+}
+
+// Creates what doesn't exist yet, returns the token of every service by name
+func Run(cfg *Config, userRepo repository.UserRepo, tenantRepo repository.TenantRepo) (map[string]string, error) {
+	if cfg.AdminEmail != "" {
+		created, err := userRepo.EnsureAdminUser(cfg.AdminEmail, cfg.AdminPassword, config.DEFAULT_TENANT_ID)
+		if err != nil {
+			return nil, fmt.Errorf("error bootstrapping admin %s: %v", cfg.AdminEmail, err)
+		}
+		if created {
+			klog.Infof("Bootstrapped admin %s", cfg.AdminEmail)
+		}
+	}
+
+	tokens := make(map[string]string, len(cfg.Services))
+	for _, service := range cfg.Services {
+		tenant, err := tenantRepo.GetTenant(service.Tenant)
+		if err != nil {
+			return nil, fmt.Errorf("unknown tenant %s for service %s", service.Tenant, service.Name)
+		}
+		// Same email as services added with -addService
+		created, token, err := userRepo.EnsureService(fmt.Sprintf("%s@banano.cc", service.Name), service.Name, service.Website, tenant.ID, service.Token)
+		if err != nil {
+			return nil, fmt.Errorf("error bootstrapping service %s: %v", service.Name, err)
+		}
+		if created {
+			klog.Infof("Bootstrapped service %s", service.Name)
+		}
+		tokens[service.Name] = token
+	}
+	return tokens, nil
+}
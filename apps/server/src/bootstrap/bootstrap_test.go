@@ -0,0 +1,50 @@
+package bootstrap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestParseServices(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	utils.AssertEqual(t, nil, os.WriteFile(tokenFile, []byte("service:fromfile\n"), 0600))
+
+	services, err := ParseServices([]byte(`[
+		{"name": "kalium", "website": "https://kalium.banano.cc", "token": "service:inline"},
+		{"name": "natrium", "website": "https://natrium.io", "tenant": "nano", "tokenFile": "` + tokenFile + `"},
+		{"name": "other", "website": "https://example.com"}
+	]`))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 3, len(services))
+	utils.AssertEqual(t, "default", services[0].Tenant)
+	utils.AssertEqual(t, "service:inline", services[0].Token)
+	utils.AssertEqual(t, "nano", services[1].Tenant)
+	utils.AssertEqual(t, "service:fromfile", services[1].Token)
+	utils.AssertEqual(t, "", services[2].Token)
+
+	_, err = ParseServices([]byte(`[{"name": "kalium"}]`))
+	utils.AssertNotEqual(t, nil, err)
+	_, err = ParseServices([]byte(`{}`))
+	utils.AssertNotEqual(t, nil, err)
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, cfg.Empty())
+
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	utils.AssertEqual(t, nil, os.WriteFile(passwordFile, []byte("hunter22hunter22"), 0600))
+	os.Setenv("BPOW_BOOTSTRAP_ADMIN_EMAIL", "Admin@example.com")
+	os.Setenv("BPOW_BOOTSTRAP_ADMIN_PASSWORD_FILE", passwordFile)
+	defer os.Unsetenv("BPOW_BOOTSTRAP_ADMIN_EMAIL")
+	defer os.Unsetenv("BPOW_BOOTSTRAP_ADMIN_PASSWORD_FILE")
+	cfg, err = LoadConfig()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "admin@example.com", cfg.AdminEmail)
+	utils.AssertEqual(t, "hunter22hunter22", cfg.AdminPassword)
+	utils.AssertEqual(t, false, cfg.Empty())
+}
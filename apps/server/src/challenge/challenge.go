@@ -0,0 +1,14 @@
+package challenge
+
+import "time"
+
+// What the client sent to prove it's not abusing an anonymous endpoint
+type Response struct {
+	Challenge string
+	Solution  string
+}
+
+// Anything that can vouch for an anonymous request, so another mechanism can replace proof of work
+type Verifier interface {
+	Verify(response Response, now time.Time) error
+}
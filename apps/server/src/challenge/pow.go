@@ -0,0 +1,84 @@
+package challenge
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/libs/utils/validation"
+)
+
+// A proof of work challenge is shaped like a block hash, so any nano/banano work generator can solve it
+// 16 random bytes, 8 bytes expiry (unix seconds), 8 bytes signature - the server doesn't need to remember what it issued
+const (
+	nonceLength     = 16
+	expiryLength    = 8
+	signatureLength = 8
+)
+
+type PowChallenge struct {
+	Hash string
+	// Minimum work value as hex, like the difficulty of a work_generate request
+	Difficulty string
+	ExpiresAt  time.Time
+}
+
+type PowVerifier struct {
+	key        []byte
+	difficulty uint64
+	ttl        time.Duration
+}
+
+var _ Verifier = &PowVerifier{}
+
+func NewPowVerifier(key []byte, difficulty uint64, ttl time.Duration) *PowVerifier {
+	return &PowVerifier{
+		key:        key,
+		difficulty: difficulty,
+		ttl:        ttl,
+	}
+}
+
+func (v *PowVerifier) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, v.key)
+	mac.Write(payload)
+	return mac.Sum(nil)[:signatureLength]
+}
+
+func (v *PowVerifier) Issue(now time.Time) (*PowChallenge, error) {
+	payload := make([]byte, nonceLength+expiryLength)
+	if _, err := rand.Read(payload[:nonceLength]); err != nil {
+		return nil, err
+	}
+	expiresAt := now.Add(v.ttl).Truncate(time.Second)
+	binary.BigEndian.PutUint64(payload[nonceLength:], uint64(expiresAt.Unix()))
+	return &PowChallenge{
+		Hash:       strings.ToUpper(hex.EncodeToString(append(payload, v.sign(payload)...))),
+		Difficulty: hex.EncodeToString(binary.BigEndian.AppendUint64(nil, v.difficulty)),
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// Check the work for a challenge this server issued
+func (v *PowVerifier) Verify(response Response, now time.Time) error {
+	raw, err := hex.DecodeString(response.Challenge)
+	if err != nil || len(raw) != nonceLength+expiryLength+signatureLength {
+		return errors.New("invalid challenge")
+	}
+	payload, signature := raw[:nonceLength+expiryLength], raw[nonceLength+expiryLength:]
+	if !hmac.Equal(signature, v.sign(payload)) {
+		return errors.New("invalid challenge")
+	}
+	if now.Unix() > int64(binary.BigEndian.Uint64(payload[nonceLength:])) {
+		return errors.New("challenge expired")
+	}
+	if len(response.Solution) != 16 || !validation.IsWorkValidForThreshold(response.Challenge, v.difficulty, response.Solution) {
+		return errors.New("invalid challenge solution")
+	}
+	return nil
+}
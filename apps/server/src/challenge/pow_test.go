@@ -0,0 +1,49 @@
+package challenge
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/bananocoin/boompow/libs/utils/validation"
+)
+
+// Brute force a solution, fine for the tiny difficulty used in tests
+func solve(hash string, difficulty uint64) string {
+	for i := uint64(0); ; i++ {
+		work := hex.EncodeToString(binary.BigEndian.AppendUint64(nil, i))
+		if validation.IsWorkValidForThreshold(hash, difficulty, work) {
+			return work
+		}
+	}
+}
+
+func TestPowVerifier(t *testing.T) {
+	difficulty := uint64(0xf000000000000000)
+	verifier := NewPowVerifier([]byte("key"), difficulty, time.Minute)
+	now := time.Now()
+
+	challenge, err := verifier.Issue(now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 64, len(challenge.Hash))
+	utils.AssertEqual(t, "f000000000000000", challenge.Difficulty)
+
+	solution := solve(challenge.Hash, difficulty)
+	utils.AssertEqual(t, nil, verifier.Verify(Response{Challenge: challenge.Hash, Solution: solution}, now))
+
+	// Expired
+	utils.AssertNotEqual(t, nil, verifier.Verify(Response{Challenge: challenge.Hash, Solution: solution}, now.Add(2*time.Minute)))
+
+	// Issued by someone else
+	other := NewPowVerifier([]byte("other"), difficulty, time.Minute)
+	utils.AssertNotEqual(t, nil, other.Verify(Response{Challenge: challenge.Hash, Solution: solution}, now))
+
+	// Tampered expiry
+	tampered := challenge.Hash[:32] + "FFFFFFFFFFFFFFFF" + challenge.Hash[48:]
+	utils.AssertNotEqual(t, nil, verifier.Verify(Response{Challenge: tampered, Solution: solve(tampered, difficulty)}, now))
+
+	// Wrong solution
+	utils.AssertNotEqual(t, nil, verifier.Verify(Response{Challenge: challenge.Hash, Solution: "zz"}, now))
+}
@@ -5,3 +5,290 @@ const EMAIL_CONFIRMATION_TOKEN_VALID_MINUTES = 180
 
 // The nano send difficulty multiplier is x64, receive is x1 (banano is x1)
 const MAX_WORK_DIFFICULTY_MULTIPLIER = 64
+
+// Tenant that existing data and unlabeled requests belong to
+const DEFAULT_TENANT_ID = "default"
+
+// How long a work result is remembered for a given Idempotency-Key
+const IDEMPOTENCY_KEY_TTL_HOURS = 24
+
+// How many hub events are kept in memory for debugging
+const HUB_EVENT_LOG_SIZE = 10000
+
+// The admin metrics snapshot covers this many minutes
+const ADMIN_METRICS_WINDOW_MINUTES = 5
+
+// Clients whose websocket is down can send this many results at once over HTTP
+const WORKER_FALLBACK_MAX_RESULTS = 100
+
+// A provider with a heartbeat this recent is still working, even without a websocket
+const WORKER_HEARTBEAT_TTL_SECONDS = 120
+
+// Workers that send this many malformed frames within the window are quarantined
+const MALFORMED_FRAME_STRIKES = 5
+const MALFORMED_FRAME_WINDOW_MINUTES = 10
+
+// How long quarantined workers are disconnected and can't reconnect
+const QUARANTINE_MINUTES = 30
+
+// Requests are remembered this long after they were answered or timed out, results for them after that are unknown
+const CLOSED_REQUEST_RETENTION_SECONDS = 300
+
+// Postgres channel that database triggers NOTIFY on
+const DB_NOTIFY_CHANNEL = "boompow_events"
+
+// How long a proof of work challenge can be solved for
+const POW_CHALLENGE_VALID_MINUTES = 5
+
+// Websocket tokens only need to live long enough to open the connection
+const WS_TOKEN_VALID_SECONDS = 60
+
+// Sessions end when their refresh token isn't used for this long
+const REFRESH_TOKEN_VALID_DAYS = 30
+
+// How often the connected clients in redis are rebuilt from the hub's connections
+const RECONCILE_CLIENTS_INTERVAL_MINUTES = 5
+
+// Rollups that drift less than this fraction from work_results are corrected automatically
+const STATS_CHECK_TOLERANCE = 0.05
+
+// How far back the stats consistency check looks
+const STATS_CHECK_WINDOW_HOURS = 24
+
+// Number of hardware entries in the benchmark leaderboard
+const BENCHMARK_LEADERBOARD_SIZE = 100
+
+// Block awarded messages are redelivered on reconnect until the client acknowledges them or they're this old
+const PENDING_AWARD_TTL_HOURS = 24
+
+// Offline alerts can be set to fire after this many minutes, shorter would alert on every restart
+const OFFLINE_ALERT_MIN_MINUTES = 5
+const OFFLINE_ALERT_MAX_MINUTES = 1440
+
+// A provider isn't alerted again within this long of the last alert, even if their workers flap
+const OFFLINE_ALERT_COOLDOWN_MINUTES = 60
+
+// The anomaly detector looks at hub events this far back
+const INCIDENT_DETECTOR_WINDOW_MINUTES = 10
+
+// Work requests timing out more than this fraction of the time opens an incident
+const INCIDENT_TIMEOUT_RATIO = 0.5
+
+// Fewer requests than this in the window are too few to call it an incident
+const INCIDENT_MIN_REQUESTS = 10
+
+// Workers need some time to reconnect after a restart before the detector runs
+const INCIDENT_STARTUP_GRACE_MINUTES = 5
+
+// Incidents kept in the public history
+const INCIDENT_HISTORY_SIZE = 50
+
+// Requesters are warned about maintenance windows starting within this many hours
+const MAINTENANCE_WARNING_HOURS = 24
+
+// Longest maintenance window admins can schedule
+const MAINTENANCE_MAX_HOURS = 24
+
+// Requesters asking for more than the network difficulty this many times in a row get a difficulty advisory
+const DIFFICULTY_ADVISORY_STREAK = 10
+
+// The streak is forgotten when a requester hasn't asked for excess difficulty for this long
+const DIFFICULTY_ADVISORY_TTL_HOURS = 24
+
+// Monthly usage statements requesters can query, besides the current month
+const USAGE_STATEMENT_HISTORY_MONTHS = 12
+
+// Page size of list queries when first isn't given
+const PAGE_SIZE = 20
+
+// Largest page of a list query anyone can ask for
+const MAX_PAGE_SIZE = 100
+
+// Conditions a filtered list query can have, and values an IN condition can have
+const MAX_FILTER_CONDITIONS = 20
+const MAX_FILTER_VALUES = 100
+
+// Operations a batched GraphQL request can have
+const GRAPHQL_MAX_BATCH_SIZE = 10
+
+// Queries clients registered as automatic persisted queries that are kept, the example operations don't count
+const PERSISTED_QUERY_CACHE_SIZE = 1000
+
+// How long each preflight check may take
+const PREFLIGHT_CHECK_TIMEOUT_SECONDS = 10
+
+// First retry delay while waiting for postgres and redis at startup, it doubles up to DEPENDENCY_MAX_BACKOFF_SECONDS
+const DEPENDENCY_MIN_BACKOFF_SECONDS = 1
+
+const DEPENDENCY_MAX_BACKOFF_SECONDS = 30
+
+// How often the redis connection is checked once running
+const REDIS_WATCH_INTERVAL_SECONDS = 5
+
+// Upcoming payout cycles in the payout calendar
+const PAYOUT_CALENDAR_CYCLES = 7
+
+// Backup codes generated when enrolling in two factor authentication
+const BACKUP_CODE_COUNT = 10
+
+// Issuer shown in authenticator apps
+const TOTP_ISSUER = "BoomPow"
+
+// How long sampled GraphQL requests are kept
+const REQUEST_SAMPLE_RETENTION_HOURS = 24
+
+// Only the newest sampled requests are kept
+const REQUEST_SAMPLES_MAX = 1000
+
+// Request sampling turns itself off after at most this long
+const REQUEST_SAMPLING_MAX_MINUTES = 240
+
+// Load shedding stops by itself after at most this long
+const LOAD_SHEDDING_MAX_MINUTES = 240
+
+// How often servers pick up load shedding started or stopped on another server
+const LOAD_SHEDDING_REFRESH_SECONDS = 10
+
+// Frontiers registered for idle precaching are dropped after this long, the account has likely moved on
+const FRONTIER_POOL_TTL_HOURS = 24
+
+// Frontiers a tenant's pool holds at most, the oldest are dropped first
+const FRONTIER_POOL_MAX = 10000
+
+// Frontiers a requester can register at once
+const FRONTIER_REGISTRATION_MAX = 1000
+
+// How often the hub looks for idle workers to give precache tasks
+const IDLE_PRECACHE_CHECK_SECONDS = 5
+
+// Daily per-country request counts are kept this long
+const GEO_REQUESTS_RETENTION_DAYS = 31
+
+// Countries with fewer workers or requests than this are grouped together on the public network map, so single providers can't be picked out
+const GEO_PUBLIC_MIN_COUNT = 3
+
+// How often the live network map is pushed to subscribers
+const NETWORK_MAP_PUSH_SECONDS = 30
+
+// Provider stats are pushed to subscribers at least this often, earnings change with the work of other providers too
+const PROVIDER_STATS_PUSH_SECONDS = 30
+
+// Activity of a provider's workers is pushed at most this often, a burst of results is pushed at once
+const PROVIDER_STATS_MIN_INTERVAL_SECONDS = 5
+
+// The payout estimate sent with block awarded messages is recomputed at most this often, unless the provider isn't in it yet
+const AWARD_ESTIMATE_REFRESH_SECONDS = 30
+
+// API keys a requester can have at once
+const MAX_API_KEYS_PER_USER = 20
+
+// Rate limits of API keys are at most this many requests per minute
+const MAX_API_KEY_RATE_LIMIT = 10000
+
+// Accounts the stale account cleanup takes a step further per run, the rest wait for the next run
+const STALE_ACCOUNT_BATCH_SIZE = 500
+
+// Work sources a requester can have at once
+const MAX_WORK_SOURCES_PER_USER = 20
+
+// When an API key was last used is only updated this often, so busy keys don't write on every request
+const API_KEY_LAST_USED_RESOLUTION_SECONDS = 60
+
+// Results being cross-checked with validation peers at once, samples are skipped while they're all busy
+const CROSSCHECK_MAX_CONCURRENT = 8
+
+// Validation peers that don't answer within this are counted as failed checks
+const CROSSCHECK_TIMEOUT_SECONDS = 10
+
+// Disagreements of a peer are only alerted about this often, they're still logged and counted in between
+const CROSSCHECK_ALERT_INTERVAL_MINUTES = 10
+
+// Recent disagreements kept for admins
+const CROSSCHECK_RECENT_DISAGREEMENTS = 20
+
+// Users the rate limiter looked up are cached this long, so rate limit changes take at most this long to apply
+const RATE_LIMIT_USER_CACHE_SECONDS = 60
+
+// Service tokens and users the rate limiter found no user for are cached this long, so guessing the same token doesn't reach the database
+const RATE_LIMIT_UNKNOWN_USER_CACHE_SECONDS = 10
+
+// Most users and service tokens the rate limiter keeps cached, the least recently used are dropped
+const RATE_LIMIT_USER_CACHE_SIZE = 10000
+
+// Timed out work requests are only extended while a worker reported progress on them within this
+const WORK_PROGRESS_STALE_SECONDS = 10
+
+// Timed out work requests that are still progressing are extended by this much at a time, up to the policy's progress extension
+const WORK_PROGRESS_EXTENSION_SECONDS = 10
+
+// The oldest worker protocol the hub serves, workers that don't say hello speak version 1
+const MIN_WORKER_PROTOCOL_VERSION = 1
+
+// Workers need this many solves before their solve times count towards targeted dispatch
+const SOLVE_TIMES_MIN_SAMPLES = 3
+
+// Weight of the latest solve in a worker's average solve time
+const SOLVE_TIMES_SMOOTHING = 0.3
+
+// Workers that just said hello solve this many calibration requests before they're counted on for work, enough for a solve time estimate
+const CALIBRATION_REQUESTS = SOLVE_TIMES_MIN_SAMPLES
+
+// The difficulty calibration requests are sent at, if the worker accepts it
+const CALIBRATION_DIFFICULTY_MULTIPLIER = 1
+
+// Workers that haven't solved their calibration requests within this get work like everyone else
+const CALIBRATION_TIMEOUT_SECONDS = 30
+
+// Credit entries listed in a requester's credit account
+const CREDIT_HISTORY_LENGTH = 20
+
+// How long shutdown waits for in-flight work requests before workers are disconnected
+const SHUTDOWN_TIMEOUT_SECONDS = 30
+
+// Work requests of other replicas are remembered this long after they were sent to this replica's clients, results for them after that are dropped
+const REMOTE_REQUEST_RETENTION_SECONDS = 600
+
+// How often replicas in distributed mode tell the others they're alive, they're considered gone after 3 missed heartbeats
+const REPLICA_HEARTBEAT_SECONDS = 10
+
+// How often volatile redis counters are copied into postgres, losing redis costs at most this much of them
+const COUNTER_SNAPSHOT_INTERVAL_MINUTES = 5
+
+// How often the memory redis uses is checked, by key prefix
+const REDIS_MEMORY_CHECK_MINUTES = 5
+
+// Share of redis' maxmemory at which we warn
+const REDIS_MEMORY_WARN_PERCENT = 80
+
+// Key prefixes reported separately in the memory usage, the rest are counted as other
+const REDIS_MEMORY_TOP_PREFIXES = 20
+
+// Snapshots of the connected workers are kept this long, long enough for the monthly chart
+const CONNECTED_WORKERS_HISTORY_DAYS = 31
+
+// Results waiting for a validation worker, the hub validates them itself once it's full
+const VALIDATION_QUEUE_SIZE = 1000
+
+// Messages waiting for the hub to send them, once it's full the least urgent are dropped
+const BROADCAST_QUEUE_SIZE = 10000
+
+// Postgres advisory lock payout jobs hold while they compute a cycle
+const PAYOUT_ADVISORY_LOCK_ID = 8_022_022
+
+// How often closed payout cycles are checked for statements to send
+const PAYOUT_STATEMENT_INTERVAL_MINUTES = 15
+
+// Cycles closed longer ago than this don't get statements, so old cycles aren't mailed when statements are first deployed
+const PAYOUT_STATEMENT_MAX_AGE_HOURS = 72
+
+// Next cycles listed in payout statements
+const PAYOUT_STATEMENT_NEXT_CYCLES = 3
+
+// Unsubscribe links in emails keep working this long
+const UNSUBSCRIBE_TOKEN_VALID_DAYS = 365
+
+// How often the provider leaderboards are recomputed from work results
+const LEADERBOARD_REFRESH_MINUTES = 10
+
+// Providers kept on each leaderboard
+const LEADERBOARD_SIZE = 100
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	"github.com/bananocoin/boompow/libs/utils/validation"
+	"github.com/google/uuid"
+)
+
+// Calibration requests aren't anybody's work, their results are only timed
+const calibrationRequestPrefix = "calibration:"
+
+// A calibration request sent to a worker that hasn't answered yet
+type calibration struct {
+	client               *Client
+	hash                 string
+	difficultyMultiplier int
+	sentAt               time.Time
+}
+
+// Guarded by the hub's mutex
+// Workers are held back from work requests until they solved their calibration requests or ran out of time for them
+func (c *Client) calibrating(now time.Time) bool {
+	return c.calibrationsLeft > 0 && now.Before(c.calibrationDeadline)
+}
+
+// The difficulty calibration requests are solved at, within the range the worker asked for
+func (c *Client) calibrationDifficulty() int {
+	difficulty := config.CALIBRATION_DIFFICULTY_MULTIPLIER
+	if !c.supports(serializableModels.FeatureDifficultyRange) {
+		return difficulty
+	}
+	if c.hello.MinDifficulty > difficulty {
+		difficulty = c.hello.MinDifficulty
+	}
+	if c.hello.MaxDifficulty > 0 && c.hello.MaxDifficulty < difficulty {
+		difficulty = c.hello.MaxDifficulty
+	}
+	return difficulty
+}
+
+// Times a worker that just said hello on a few requests at a known difficulty, one after the other, so its capacity is known before it's counted on
+func (h *Hub) calibrate(client *Client, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if config.CALIBRATION_REQUESTS == 0 {
+		return
+	}
+	client.calibrationsLeft = config.CALIBRATION_REQUESTS
+	client.calibrationDeadline = now.Add(config.CALIBRATION_TIMEOUT_SECONDS * time.Second)
+	h.sendCalibration(client, now)
+}
+
+// Must hold the hub's mutex
+func (h *Hub) sendCalibration(client *Client, now time.Time) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		logging.Errorf(logging.Hub, "Error generating a calibration hash %v", err)
+		client.calibrationsLeft = 0
+		return
+	}
+	request := calibration{client: client, hash: strings.ToUpper(hex.EncodeToString(bytes)), difficultyMultiplier: client.calibrationDifficulty(), sentAt: now}
+	requestID := calibrationRequestPrefix + uuid.NewString()
+	msg, err := json.Marshal(serializableModels.ClientMessage{MessageType: serializableModels.WorkGenerate, RequestID: requestID, Hash: request.hash, DifficultyMultiplier: request.difficultyMultiplier})
+	if err != nil {
+		logging.Errorf(logging.Hub, "Error marshalling calibration request %v", err)
+		client.calibrationsLeft = 0
+		return
+	}
+	// Its send channel is closed once it's removed
+	if _, ok := h.Clients[client]; !ok {
+		return
+	}
+	select {
+	case client.Send <- msg:
+		h.calibrations[requestID] = request
+	default:
+		// Too busy to be timed, it's routed like before
+		client.calibrationsLeft = 0
+	}
+}
+
+// Handles a result for a calibration request, false if it's for another request
+func (h *Hub) calibrationResult(message ClientWSMessage, response serializableModels.ClientWorkResponse, receivedAt time.Time) bool {
+	if !strings.HasPrefix(response.RequestID, calibrationRequestPrefix) {
+		return false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	request, ok := h.calibrations[response.RequestID]
+	if !ok || request.client != message.client || response.Progress != nil {
+		return true
+	}
+	delete(h.calibrations, response.RequestID)
+	client := request.client
+	if response.Rejected != "" {
+		logging.Warningf(logging.Hub, "%s rejected its calibration request: %s", client.IPAddress, response.Rejected)
+		client.calibrationsLeft = 0
+		return true
+	}
+	if !validation.IsWorkValid(request.hash, request.difficultyMultiplier, response.Result) {
+		logging.Warningf(logging.Hub, "Received invalid calibration work from %s", client.IPAddress)
+		client.calibrationsLeft = 0
+		HubEvents.Record(models.HubEvent{Type: models.HubEventCalibrated, RequestID: response.RequestID, Hash: request.hash, ClientIP: client.IPAddress, ClientEmail: client.Email, TenantID: client.TenantID, DifficultyMultiplier: request.difficultyMultiplier, Detail: "invalid work, not calibrated"})
+		return true
+	}
+	if client.solveTimes == nil {
+		client.solveTimes = make(solveTimes)
+	}
+	took := receivedAt.Sub(request.sentAt)
+	client.solveTimes.record(request.difficultyMultiplier, took)
+	client.calibrationsLeft--
+	if client.calibrationsLeft > 0 && receivedAt.Before(client.calibrationDeadline) {
+		h.sendCalibration(client, receivedAt)
+		return true
+	}
+	client.calibrationsLeft = 0
+	estimate, _ := client.solveTimes.estimate(request.difficultyMultiplier)
+	HubEvents.Record(models.HubEvent{Type: models.HubEventCalibrated, RequestID: response.RequestID, Hash: request.hash, ClientIP: client.IPAddress, ClientEmail: client.Email, TenantID: client.TenantID, DifficultyMultiplier: request.difficultyMultiplier, Detail: fmt.Sprintf("solves %dx in %s", request.difficultyMultiplier, estimate.Round(time.Millisecond))})
+	return true
+}
+
+// Must hold the hub's mutex
+func (h *Hub) forgetCalibrations(client *Client) {
+	for requestID, request := range h.calibrations {
+		if request.client == client {
+			delete(h.calibrations, requestID)
+		}
+	}
+}
+
+// Leaves workers that are still being calibrated out of a work request, unless nobody else can take it
+func warmRecipients(recipients []*Client, now time.Time) []*Client {
+	warm := []*Client{}
+	for _, client := range recipients {
+		if !client.calibrating(now) {
+			warm = append(warm, client)
+		}
+	}
+	if len(warm) == 0 {
+		return recipients
+	}
+	return warm
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestCalibration(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	hub := NewHub(nil)
+	fresh := &Client{IPAddress: "1.1.1.1", TenantID: "default", Send: make(chan []byte, 10), protocol: 2, features: []string{serializableModels.FeatureDifficultyRange}, hello: serializableModels.WorkerHello{MinDifficulty: 2}}
+	hub.Clients[fresh] = true
+	now := time.Now()
+
+	sent := func() serializableModels.ClientMessage {
+		var msg serializableModels.ClientMessage
+		utils.AssertEqual(t, nil, json.Unmarshal(<-fresh.Send, &msg))
+		return msg
+	}
+
+	hub.calibrate(fresh, now)
+	utils.AssertEqual(t, true, fresh.calibrating(now))
+	msg := sent()
+	utils.AssertEqual(t, true, strings.HasPrefix(msg.RequestID, calibrationRequestPrefix))
+	// Within the range the worker asked for
+	utils.AssertEqual(t, 2, msg.DifficultyMultiplier)
+
+	// Nobody else can take it, so it still goes to the worker being calibrated
+	hub.broadcast(BroadcastMessage{TenantID: "default", Msg: []byte("1"), Event: models.HubEventAssigned, RequestID: "warmup-1", DifficultyMultiplier: 2})
+	utils.AssertEqual(t, 1, len(fresh.Send))
+	<-fresh.Send
+	warm := &Client{IPAddress: "2.2.2.2", TenantID: "default", Send: make(chan []byte, 10)}
+	hub.Clients[warm] = true
+	hub.broadcast(BroadcastMessage{TenantID: "default", Msg: []byte("2"), Event: models.HubEventAssigned, RequestID: "warmup-2", DifficultyMultiplier: 2})
+	utils.AssertEqual(t, 0, len(fresh.Send))
+	utils.AssertEqual(t, 1, len(warm.Send))
+
+	// Solved with a known hash, results from other connections and other requests aren't calibration results
+	hash := "3F93C5CD2E314FA16702189041E68E68C07B27961BF37F0B7705145BEFBA3AA3"
+	solve := func(requestID string, at time.Duration) {
+		request := hub.calibrations[requestID]
+		request.hash, request.difficultyMultiplier = hash, 1
+		hub.calibrations[requestID] = request
+		response := serializableModels.ClientWorkResponse{RequestID: requestID, Hash: hash, Result: "205452237a9b01f4"}
+		utils.AssertEqual(t, true, hub.calibrationResult(ClientWSMessage{client: warm}, response, now.Add(at)))
+		utils.AssertEqual(t, true, hub.calibrationResult(ClientWSMessage{client: fresh}, response, now.Add(at)))
+	}
+	utils.AssertEqual(t, false, hub.calibrationResult(ClientWSMessage{client: fresh}, serializableModels.ClientWorkResponse{RequestID: "warmup-2"}, now))
+	for i := 0; i < config.CALIBRATION_REQUESTS; i++ {
+		if i > 0 {
+			msg = sent()
+		}
+		// Each one is sent once the one before was solved
+		solve(msg.RequestID, time.Duration(i+1)*time.Second)
+	}
+	utils.AssertEqual(t, 0, len(fresh.Send))
+	utils.AssertEqual(t, 0, len(hub.calibrations))
+	utils.AssertEqual(t, false, fresh.calibrating(now))
+	utils.AssertEqual(t, config.CALIBRATION_REQUESTS, fresh.solveTimes[1].Solves)
+	events := HubEvents.ForRequest(msg.RequestID)
+	utils.AssertEqual(t, models.HubEventCalibrated, events[len(events)-1].Type)
+	utils.AssertEqual(t, "solves 1x in 1s", events[len(events)-1].Detail)
+
+	// Workers that don't finish in time get work like everyone else, and disconnecting forgets their calibration
+	hub.calibrate(warm, now)
+	utils.AssertEqual(t, true, warm.calibrating(now))
+	utils.AssertEqual(t, false, warm.calibrating(now.Add(config.CALIBRATION_TIMEOUT_SECONDS*time.Second)))
+	hub.remove(warm)
+	utils.AssertEqual(t, 0, len(hub.calibrations))
+}
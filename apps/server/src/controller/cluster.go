@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	"github.com/bananocoin/boompow/libs/utils/validation"
+)
+
+// What replicas tell each other in distributed mode
+const (
+	clusterBroadcast  = "broadcast"
+	clusterRelease    = "release"
+	clusterResult     = "result"
+	clusterDisconnect = "disconnect"
+)
+
+// Every replica listens on this channel, and on its own for the results of its work requests
+const clusterChannel = "broadcast"
+
+func replicaChannel(replicaID string) string {
+	return "replica:" + replicaID
+}
+
+type clusterMessage struct {
+	Kind      string            `json:"kind"`
+	Origin    string            `json:"origin"`
+	Broadcast *BroadcastMessage `json:"broadcast,omitempty"`
+	RequestID string            `json:"requestId,omitempty"`
+	// The provider of a result, or the user whose workers are disconnected
+	ClientEmail string `json:"clientEmail,omitempty"`
+	TenantID    string `json:"tenantId,omitempty"`
+	Msg         []byte `json:"msg,omitempty"`
+	// Why the user's workers are disconnected
+	Reason *serializableModels.DisconnectReason `json:"reason,omitempty"`
+}
+
+type outgoingMessage struct {
+	channel string
+	msg     clusterMessage
+}
+
+// A work request of another replica that was sent to this replica's clients
+type remoteRequest struct {
+	owner                string
+	tenantID             string
+	hash                 string
+	difficultyMultiplier int
+	sentAt               time.Time
+}
+
+// Shares the hub with the other replicas in distributed mode
+// Work requests go out to the clients of every replica and results go back to the replica waiting on them, which validates and credits them
+// Each replica only targets, rate limits and precaches for its own clients
+type Cluster struct {
+	ReplicaID string
+	hub       *Hub
+	publish   func(channel string, msg []byte) error
+	// Published in order in the background, messages are dropped rather than stalling the hub if redis can't keep up
+	outbox chan outgoingMessage
+
+	mu     sync.Mutex
+	order  []string
+	remote map[string]remoteRequest
+}
+
+// Must be called before the hub runs
+func NewCluster(hub *Hub, replicaID string, publish func(channel string, msg []byte) error) *Cluster {
+	cluster := &Cluster{
+		ReplicaID: replicaID,
+		hub:       hub,
+		publish:   publish,
+		outbox:    make(chan outgoingMessage, 1000),
+		remote:    make(map[string]remoteRequest),
+	}
+	hub.cluster = cluster
+	return cluster
+}
+
+// Publishes this replica's messages and handles the others' until the subscription is closed
+func (c *Cluster) Run() {
+	go c.publishOutbox()
+	go c.heartbeat()
+	subscription := database.GetRedisDB().SubscribeHub(clusterChannel, replicaChannel(c.ReplicaID))
+	defer subscription.Close()
+	for message := range subscription.Channel() {
+		c.Handle([]byte(message.Payload))
+	}
+}
+
+func (c *Cluster) heartbeat() {
+	interval := config.REPLICA_HEARTBEAT_SECONDS * time.Second
+	for {
+		if err := database.GetRedisDB().RecordReplicaHeartbeat(c.ReplicaID, 3*interval); err != nil {
+			logging.Errorf(logging.Hub, "Error recording the heartbeat of replica %s %v", c.ReplicaID, err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func (c *Cluster) publishOutbox() {
+	for outgoing := range c.outbox {
+		bytes, err := json.Marshal(outgoing.msg)
+		if err != nil {
+			logging.Errorf(logging.Hub, "Error marshalling %s message for the cluster %v", outgoing.msg.Kind, err)
+			continue
+		}
+		if err := c.publish(outgoing.channel, bytes); err != nil {
+			logging.Errorf(logging.Hub, "Error publishing %s message to the cluster %v", outgoing.msg.Kind, err)
+		}
+	}
+}
+
+func (c *Cluster) send(channel string, msg clusterMessage) {
+	msg.Origin = c.ReplicaID
+	select {
+	case c.outbox <- outgoingMessage{channel: channel, msg: msg}:
+	default:
+		logging.Warningf(logging.Hub, "Cluster outbox is full, dropping %s message", msg.Kind)
+	}
+}
+
+// Idle precache tasks stay on the replica whose clients are idle
+func (c *Cluster) shareBroadcast(message BroadcastMessage) {
+	if message.Origin != "" || message.IdleFor > 0 {
+		return
+	}
+	c.send(clusterChannel, clusterMessage{Kind: clusterBroadcast, Broadcast: &message})
+}
+
+func (c *Cluster) shareRelease(requestID string) {
+	c.send(clusterChannel, clusterMessage{Kind: clusterRelease, RequestID: requestID})
+}
+
+func (c *Cluster) shareDisconnect(email string, reason serializableModels.DisconnectReason) {
+	c.send(clusterChannel, clusterMessage{Kind: clusterDisconnect, ClientEmail: email, Reason: &reason})
+}
+
+// Sends a result for another replica's work request to that replica, false if the request isn't another replica's
+// Invalid results and progress stay here, so only valid results travel and count towards this replica's solve times
+func (c *Cluster) forwardResult(message ClientWSMessage, response serializableModels.ClientWorkResponse, now time.Time) bool {
+	request, ok := c.remoteRequest(response.RequestID, now)
+	if !ok {
+		return false
+	}
+	if request.tenantID != message.TenantID {
+		logging.Errorf(logging.Hub, "Received work response for %s from a client of tenant %s, but it was requested by tenant %s", request.hash, message.TenantID, request.tenantID)
+		return true
+	}
+	if response.Progress != nil {
+		return true
+	}
+	if !validation.IsWorkValid(request.hash, request.difficultyMultiplier, response.Result) {
+		logging.Errorf(logging.Hub, "Received invalid work for %s", request.hash)
+		HubEvents.Record(models.HubEvent{Type: models.HubEventResult, RequestID: response.RequestID, Hash: request.hash, ClientEmail: message.ClientEmail, TenantID: request.tenantID, DifficultyMultiplier: request.difficultyMultiplier, Detail: "invalid work"})
+		return true
+	}
+	c.hub.recordSolve(message.client, response.RequestID, request.difficultyMultiplier, now)
+	c.send(replicaChannel(request.owner), clusterMessage{Kind: clusterResult, RequestID: response.RequestID, ClientEmail: message.ClientEmail, TenantID: message.TenantID, Msg: message.msg})
+	return true
+}
+
+// Handles a message of another replica
+func (c *Cluster) Handle(payload []byte) {
+	var msg clusterMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		logging.Errorf(logging.Hub, "Error unmarshalling cluster message %v", err)
+		return
+	}
+	if msg.Origin == c.ReplicaID {
+		return
+	}
+	switch msg.Kind {
+	case clusterBroadcast:
+		if msg.Broadcast == nil {
+			return
+		}
+		message := *msg.Broadcast
+		message.Origin = msg.Origin
+		if message.Event == models.HubEventAssigned {
+			c.rememberRequest(message, time.Now())
+		}
+		c.hub.Broadcast <- message
+	case clusterRelease:
+		c.hub.release(msg.RequestID)
+	case clusterResult:
+		c.hub.Response <- ClientWSMessage{ClientEmail: msg.ClientEmail, TenantID: msg.TenantID, msg: msg.Msg}
+	case clusterDisconnect:
+		// Replicas before reasons only disconnected users that can't log in anymore
+		reason := serializableModels.DisconnectAuthFailed
+		if msg.Reason != nil {
+			reason = *msg.Reason
+		}
+		c.hub.disconnectUser(msg.ClientEmail, reason)
+	}
+}
+
+// Retries keep the request's first send time
+func (c *Cluster) rememberRequest(message BroadcastMessage, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sweep(now)
+	if _, ok := c.remote[message.RequestID]; ok {
+		return
+	}
+	c.remote[message.RequestID] = remoteRequest{owner: message.Origin, tenantID: message.TenantID, hash: message.Hash, difficultyMultiplier: message.DifficultyMultiplier, sentAt: now}
+	c.order = append(c.order, message.RequestID)
+}
+
+func (c *Cluster) remoteRequest(requestID string, now time.Time) (remoteRequest, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sweep(now)
+	request, ok := c.remote[requestID]
+	return request, ok
+}
+
+func (c *Cluster) sweep(now time.Time) {
+	cutoff := now.Add(-config.REMOTE_REQUEST_RETENTION_SECONDS * time.Second)
+	expired := 0
+	for _, requestID := range c.order {
+		if c.remote[requestID].sentAt.After(cutoff) {
+			break
+		}
+		delete(c.remote, requestID)
+		expired++
+	}
+	c.order = c.order[expired:]
+}
+
+// Whether the client is connected to another replica that's still alive
+func (c *Cluster) connectedElsewhere(ip string) bool {
+	replicaID, err := database.GetRedisDB().GetClientReplica(ip)
+	if err != nil {
+		logging.Errorf(logging.Hub, "Error getting the replica of %s %v", ip, err)
+		return false
+	}
+	return replicaID != "" && replicaID != c.ReplicaID && database.GetRedisDB().ReplicaAlive(replicaID)
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+// The next message the replica published and the channel it went to
+func published(t *testing.T, from *Cluster) ([]byte, string) {
+	select {
+	case outgoing := <-from.outbox:
+		bytes, err := json.Marshal(outgoing.msg)
+		utils.AssertEqual(t, nil, err)
+		return bytes, outgoing.channel
+	default:
+		t.Fatal("nothing was published")
+		return nil, ""
+	}
+}
+
+func TestClusterRelaysWorkRequests(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	policy := models.DefaultHubPolicy()
+	policy.MaxInFlightPerWorker = 1
+	SetPolicy(policy)
+	defer SetPolicy(models.DefaultHubPolicy())
+	hubA, hubB := NewHub(nil), NewHub(nil)
+	a := NewCluster(hubA, "a", nil)
+	b := NewCluster(hubB, "b", nil)
+	hash := "3F93C5CD2E314FA16702189041E68E68C07B27961BF37F0B7705145BEFBA3AA3"
+
+	a.shareBroadcast(BroadcastMessage{TenantID: "default", Msg: []byte("work"), Event: models.HubEventAssigned, RequestID: "cluster-1", Hash: hash, DifficultyMultiplier: 1, Priority: PriorityOnDemand})
+	msg, channel := published(t, a)
+	utils.AssertEqual(t, clusterChannel, channel)
+	b.Handle(msg)
+	relayed := <-hubB.Broadcast
+	utils.AssertEqual(t, "a", relayed.Origin)
+	utils.AssertEqual(t, "cluster-1", relayed.RequestID)
+	utils.AssertEqual(t, []byte("work"), relayed.Msg)
+	utils.AssertEqual(t, PriorityOnDemand, relayed.Priority)
+
+	// Relayed messages aren't relayed again, idle precache tasks aren't relayed at all
+	b.shareBroadcast(relayed)
+	a.shareBroadcast(BroadcastMessage{TenantID: "default", Event: models.HubEventAssigned, RequestID: "idle-1", IdleFor: time.Minute})
+	utils.AssertEqual(t, 0, len(a.outbox)+len(b.outbox))
+
+	// Replicas ignore their own messages
+	a.shareBroadcast(BroadcastMessage{TenantID: "default", Msg: []byte("announcement")})
+	msg, _ = published(t, a)
+	a.Handle(msg)
+	utils.AssertEqual(t, 0, len(hubA.Broadcast))
+
+	// Releases free the slots of the other replica's clients
+	worker := &Client{IPAddress: "1.1.1.1", Email: "worker@example.com", TenantID: "default", Send: make(chan []byte, 10)}
+	hubB.Clients[worker] = true
+	hubB.broadcast(relayed)
+	utils.AssertEqual(t, 1, worker.inFlight)
+	a.shareRelease("cluster-1")
+	msg, _ = published(t, a)
+	b.Handle(msg)
+	utils.AssertEqual(t, 0, worker.inFlight)
+
+	// Only valid results go back to the replica that's waiting on them
+	now := time.Now()
+	invalid := ClientWSMessage{ClientEmail: worker.Email, TenantID: "default", client: worker, msg: []byte(`{"request_id":"cluster-1"}`)}
+	utils.AssertEqual(t, true, b.forwardResult(invalid, serializableModels.ClientWorkResponse{RequestID: "cluster-1", Hash: hash, Result: "0000000000000000"}, now))
+	utils.AssertEqual(t, 0, len(b.outbox))
+	utils.AssertEqual(t, false, b.forwardResult(invalid, serializableModels.ClientWorkResponse{RequestID: "unknown", Hash: hash, Result: "205452237a9b01f4"}, now))
+	valid := ClientWSMessage{ClientEmail: worker.Email, TenantID: "default", client: worker, msg: []byte(`{"request_id":"cluster-1","result":"205452237a9b01f4"}`)}
+	utils.AssertEqual(t, true, b.forwardResult(valid, serializableModels.ClientWorkResponse{RequestID: "cluster-1", Hash: hash, Result: "205452237a9b01f4"}, now))
+	msg, channel = published(t, b)
+	utils.AssertEqual(t, "replica:a", channel)
+	go a.Handle(msg)
+	result := <-hubA.Response
+	utils.AssertEqual(t, "worker@example.com", result.ClientEmail)
+	utils.AssertEqual(t, "default", result.TenantID)
+	utils.AssertEqual(t, valid.msg, result.msg)
+	utils.AssertEqual(t, true, result.client == nil)
+
+	// Forgotten once the retention is over
+	_, ok := b.remoteRequest("cluster-1", now.Add(20*time.Minute))
+	utils.AssertEqual(t, false, ok)
+}
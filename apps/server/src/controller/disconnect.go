@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	"github.com/gorilla/websocket"
+)
+
+// Also sent to quarantined workers that try to connect
+var quarantineDetail = fmt.Sprintf("too many malformed frames, try again in up to %d minutes", config.QUARANTINE_MINUTES)
+
+// Sent to the worker in the close frame and recorded with its disconnect, must hold the hub's mutex
+// The write pump sends the close frame once the hub closes Send, closeConn sends it right away
+func (c *Client) setDisconnectReason(reason serializableModels.DisconnectReason, detail string) {
+	c.disconnectReason = reason.Text(detail)
+	c.closeFrame = websocket.FormatCloseMessage(reason.CloseCode, c.disconnectReason)
+}
+
+// Sends the close frame and closes the connection, the read pump unregisters the client
+func (c *Client) closeConn() {
+	c.Hub.mu.Lock()
+	frame := c.closeFrame
+	c.Hub.mu.Unlock()
+	if frame != nil {
+		c.Conn.WriteControl(websocket.CloseMessage, frame, time.Now().Add(WriteWait))
+	}
+	c.Conn.Close()
+}
+
+// Closes the connection for reason from the read pump
+func (c *Client) disconnect(reason serializableModels.DisconnectReason, detail string) {
+	c.Hub.mu.Lock()
+	c.setDisconnectReason(reason, detail)
+	c.Hub.mu.Unlock()
+	c.closeConn()
+}
+
+// Turns a worker away before it's registered
+// Websocket requests are upgraded so the reason reaches the worker in a close frame, anything else gets status and body
+func refuseWorker(w http.ResponseWriter, r *http.Request, reason serializableModels.DisconnectReason, detail string, status int, body string) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+		return
+	}
+	conn, err := Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logging.Errorf(logging.Hub, "%v", err)
+		return
+	}
+	defer conn.Close()
+	logging.Debugf(logging.Hub, "Refused worker %s: %s", r.RemoteAddr, reason.Text(detail))
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(reason.CloseCode, reason.Text(detail)), time.Now().Add(WriteWait))
+}
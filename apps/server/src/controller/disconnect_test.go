@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/gorilla/websocket"
+)
+
+func readCloseError(t *testing.T, conn *websocket.Conn) *websocket.CloseError {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	utils.AssertEqual(t, true, errors.As(err, &closeErr))
+	return closeErr
+}
+
+func TestRefuseWorker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refuseWorker(w, r, serializableModels.DisconnectAuthFailed, "log in again", http.StatusUnauthorized, "401 - Unauthorized")
+	}))
+	defer server.Close()
+
+	// Workers get the reason in a close frame
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	utils.AssertEqual(t, nil, err)
+	defer conn.Close()
+	closeErr := readCloseError(t, conn)
+	utils.AssertEqual(t, 4002, closeErr.Code)
+	utils.AssertEqual(t, "auth_failed: log in again", closeErr.Text)
+
+	// Anything else gets the status
+	resp, err := http.Get(server.URL)
+	utils.AssertEqual(t, nil, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	utils.AssertEqual(t, http.StatusUnauthorized, resp.StatusCode)
+	utils.AssertEqual(t, "401 - Unauthorized", string(body))
+}
+
+func TestDisconnectUserSendsReason(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	hub := NewHub(nil)
+	go hub.Run()
+	defer hub.Stop()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := &Client{Hub: hub, Conn: conn, Send: make(chan []byte, 256), IPAddress: "5.5.5.5", Email: "banned@example.com", TenantID: "default"}
+		hub.Register <- client
+		go client.writePump()
+		go client.readPump()
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	utils.AssertEqual(t, nil, err)
+	defer conn.Close()
+	for hub.Snapshot().Workers == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	utils.AssertEqual(t, 1, hub.DisconnectUser("BANNED@example.com", serializableModels.DisconnectBanned))
+	closeErr := readCloseError(t, conn)
+	utils.AssertEqual(t, 4003, closeErr.Code)
+	utils.AssertEqual(t, "banned", closeErr.Text)
+
+	// Recorded with the disconnect, so support can tell what happened
+	for hub.Snapshot().Workers > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	var disconnect *models.HubEvent
+	for _, event := range HubEvents.All() {
+		if event.Type == models.HubEventDisconnect && event.ClientIP == "5.5.5.5" {
+			event := event
+			disconnect = &event
+		}
+	}
+	utils.AssertEqual(t, true, disconnect != nil)
+	utils.AssertEqual(t, "banned", disconnect.Detail)
+}
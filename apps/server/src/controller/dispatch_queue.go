@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+)
+
+// Which messages the hub sends out first when they pile up, lower goes first
+type Priority int
+
+const (
+	// Cancels and everything else that isn't a work request, they free workers up
+	PriorityControl Priority = iota
+	// On-demand work of requesters that bought a priority boost
+	PriorityBoosted
+	// Requesters are waiting on these
+	PriorityOnDemand
+	PriorityPrecache
+	// Only go to idle workers anyway
+	PriorityIdlePrecache
+)
+
+var Priorities = []Priority{PriorityControl, PriorityBoosted, PriorityOnDemand, PriorityPrecache, PriorityIdlePrecache}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityControl:
+		return "control"
+	case PriorityBoosted:
+		return "boosted"
+	case PriorityOnDemand:
+		return "on_demand"
+	case PriorityPrecache:
+		return "precache"
+	default:
+		return "idle_precache"
+	}
+}
+
+// On-demand work goes out before precache work, boosted on-demand work before the rest of it
+func workPriority(precache bool, boosted bool, idleFor time.Duration) Priority {
+	if idleFor > 0 {
+		return PriorityIdlePrecache
+	}
+	if precache {
+		return PriorityPrecache
+	}
+	if boosted {
+		return PriorityBoosted
+	}
+	return PriorityOnDemand
+}
+
+type queuedMessage struct {
+	message BroadcastMessage
+	// Arrival order, messages of the same priority go out first come first served
+	seq uint64
+}
+
+type messageHeap []queuedMessage
+
+func (h messageHeap) Len() int { return len(h) }
+func (h messageHeap) Less(i, j int) bool {
+	if h[i].message.Priority != h[j].message.Priority {
+		return h[i].message.Priority < h[j].message.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h messageHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *messageHeap) Push(x interface{}) { *h = append(*h, x.(queuedMessage)) }
+func (h *messageHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// Messages waiting for the hub to send them, the hub pops them while anyone can read the depths
+type dispatchQueue struct {
+	mu       sync.Mutex
+	messages messageHeap
+	seq      uint64
+	depths   map[Priority]int
+	// Most messages waiting at once
+	size int
+}
+
+func newDispatchQueue(size int) *dispatchQueue {
+	return &dispatchQueue{depths: make(map[Priority]int), size: size}
+}
+
+// A full queue drops its least urgent message to make room, or message itself when nothing waiting is less urgent
+// Returns the dropped message, false if there was room
+func (q *dispatchQueue) Push(message BroadcastMessage) (BroadcastMessage, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	if len(q.messages) >= q.size {
+		// Only scanned while the hub can't keep up
+		worst := 0
+		for i := range q.messages {
+			if q.messages.Less(worst, i) {
+				worst = i
+			}
+		}
+		if message.Priority >= q.messages[worst].message.Priority {
+			return message, true
+		}
+		dropped := heap.Remove(&q.messages, worst).(queuedMessage)
+		q.depths[dropped.message.Priority]--
+		heap.Push(&q.messages, queuedMessage{message: message, seq: q.seq})
+		q.depths[message.Priority]++
+		return dropped.message, true
+	}
+	heap.Push(&q.messages, queuedMessage{message: message, seq: q.seq})
+	q.depths[message.Priority]++
+	return BroadcastMessage{}, false
+}
+
+// Drops the work requests of a request that was closed, its cancel still goes out
+func (q *dispatchQueue) RemoveRequest(requestID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	kept := q.messages[:0]
+	for _, item := range q.messages {
+		if item.message.RequestID == requestID && item.message.Event == models.HubEventAssigned {
+			q.depths[item.message.Priority]--
+			continue
+		}
+		kept = append(kept, item)
+	}
+	if len(kept) < len(q.messages) {
+		q.messages = kept
+		heap.Init(&q.messages)
+	}
+}
+
+// The most urgent message, false if there's none
+func (q *dispatchQueue) Pop() (BroadcastMessage, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.messages) == 0 {
+		return BroadcastMessage{}, false
+	}
+	item := heap.Pop(&q.messages).(queuedMessage)
+	q.depths[item.message.Priority]--
+	return item.message, true
+}
+
+func (q *dispatchQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.messages)
+}
+
+// Waiting messages of each priority
+func (q *dispatchQueue) Depths() map[Priority]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	depths := make(map[Priority]int, len(Priorities))
+	for _, priority := range Priorities {
+		depths[priority] = q.depths[priority]
+	}
+	return depths
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestWorkPriority(t *testing.T) {
+	utils.AssertEqual(t, PriorityOnDemand, workPriority(false, false, 0))
+	utils.AssertEqual(t, PriorityBoosted, workPriority(false, true, 0))
+	utils.AssertEqual(t, PriorityPrecache, workPriority(true, false, 0))
+	utils.AssertEqual(t, PriorityIdlePrecache, workPriority(true, false, time.Minute))
+}
+
+func TestDispatchQueue(t *testing.T) {
+	hub := NewHub(nil)
+	hub.Broadcast <- BroadcastMessage{RequestID: "precache-1", Priority: PriorityPrecache}
+	hub.Broadcast <- BroadcastMessage{RequestID: "idle-1", Priority: PriorityIdlePrecache}
+	hub.Broadcast <- BroadcastMessage{RequestID: "precache-2", Priority: PriorityPrecache}
+	hub.Broadcast <- BroadcastMessage{RequestID: "send-1", Priority: PriorityOnDemand}
+	hub.Broadcast <- BroadcastMessage{RequestID: "cancel-1"}
+	hub.Broadcast <- BroadcastMessage{RequestID: "boosted-1", Priority: PriorityBoosted}
+	hub.drainBroadcasts()
+	utils.AssertEqual(t, 0, len(hub.Broadcast))
+	utils.AssertEqual(t, map[Priority]int{PriorityControl: 1, PriorityBoosted: 1, PriorityOnDemand: 1, PriorityPrecache: 2, PriorityIdlePrecache: 1}, hub.Snapshot().BroadcastQueue)
+
+	// Most urgent first, first come first served within a priority
+	order := []string{}
+	for {
+		message, ok := hub.queue.Pop()
+		if !ok {
+			break
+		}
+		order = append(order, message.RequestID)
+	}
+	utils.AssertEqual(t, []string{"cancel-1", "boosted-1", "send-1", "precache-1", "precache-2", "idle-1"}, order)
+	utils.AssertEqual(t, 0, hub.queue.Depths()[PriorityPrecache])
+}
+
+func TestDispatchQueueBounded(t *testing.T) {
+	queue := newDispatchQueue(2)
+	_, dropped := queue.Push(BroadcastMessage{RequestID: "precache-1", Priority: PriorityPrecache})
+	utils.AssertEqual(t, false, dropped)
+	queue.Push(BroadcastMessage{RequestID: "send-1", Priority: PriorityOnDemand})
+	// Nothing waiting is less urgent
+	message, dropped := queue.Push(BroadcastMessage{RequestID: "precache-2", Priority: PriorityPrecache})
+	utils.AssertEqual(t, true, dropped)
+	utils.AssertEqual(t, "precache-2", message.RequestID)
+	// The least urgent makes room
+	message, dropped = queue.Push(BroadcastMessage{RequestID: "cancel-1"})
+	utils.AssertEqual(t, true, dropped)
+	utils.AssertEqual(t, "precache-1", message.RequestID)
+	utils.AssertEqual(t, 2, queue.Len())
+	utils.AssertEqual(t, 0, queue.Depths()[PriorityPrecache])
+}
+
+func TestDispatchQueueRemoveRequest(t *testing.T) {
+	queue := newDispatchQueue(10)
+	queue.Push(BroadcastMessage{RequestID: "a", Event: models.HubEventAssigned, Priority: PriorityOnDemand})
+	queue.Push(BroadcastMessage{RequestID: "b", Event: models.HubEventAssigned, Priority: PriorityOnDemand})
+	queue.Push(BroadcastMessage{RequestID: "a", Event: models.HubEventAssigned, Priority: PriorityOnDemand, Attempt: 1})
+	queue.Push(BroadcastMessage{RequestID: "a", Event: models.HubEventCancel})
+	queue.RemoveRequest("a")
+	utils.AssertEqual(t, 2, queue.Len())
+	utils.AssertEqual(t, 1, queue.Depths()[PriorityOnDemand])
+	// The cancel still goes out
+	message, _ := queue.Pop()
+	utils.AssertEqual(t, models.HubEventCancel, message.Event)
+	message, _ = queue.Pop()
+	utils.AssertEqual(t, "b", message.RequestID)
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/google/uuid"
+)
+
+// Keeps the most recent hub events in a ring buffer and notifies listeners of every new one
+type EventLog struct {
+	mu        sync.RWMutex
+	events    []models.HubEvent
+	next      int
+	full      bool
+	listeners []func(models.HubEvent)
+}
+
+func NewEventLog(size int) *EventLog {
+	return &EventLog{
+		events: make([]models.HubEvent, size),
+	}
+}
+
+// Global log of the active hub
+var HubEvents = NewEventLog(config.HUB_EVENT_LOG_SIZE)
+
+// Listeners are called synchronously from the hub, they must not block
+func (l *EventLog) AddListener(listener func(models.HubEvent)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listeners = append(l.listeners, listener)
+}
+
+func (l *EventLog) Record(event models.HubEvent) {
+	event.ID = uuid.New()
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	l.mu.Lock()
+	l.events[l.next] = event
+	l.next = (l.next + 1) % len(l.events)
+	if l.next == 0 {
+		l.full = true
+	}
+	listeners := l.listeners
+	l.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// All buffered events, oldest first
+func (l *EventLog) All() []models.HubEvent {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if !l.full {
+		return append([]models.HubEvent{}, l.events[:l.next]...)
+	}
+	return append(append([]models.HubEvent{}, l.events[l.next:]...), l.events[:l.next]...)
+}
+
+// The buffered timeline of a single work request, oldest first
+func (l *EventLog) ForRequest(requestID string) []models.HubEvent {
+	ret := []models.HubEvent{}
+	for _, event := range l.All() {
+		if event.RequestID == requestID {
+			ret = append(ret, event)
+		}
+	}
+	return ret
+}
+
+// How many buffered events of each type were recorded since then
+func (l *EventLog) CountSince(since time.Time) map[models.HubEventType]int {
+	counts := make(map[models.HubEventType]int)
+	for _, event := range l.All() {
+		if !event.Timestamp.Before(since) {
+			counts[event.Type]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestEventLog(t *testing.T) {
+	log := NewEventLog(3)
+	notified := 0
+	log.AddListener(func(event models.HubEvent) {
+		notified++
+	})
+
+	log.Record(models.HubEvent{Type: models.HubEventAssigned, RequestID: "1"})
+	log.Record(models.HubEvent{Type: models.HubEventAssigned, RequestID: "2"})
+	utils.AssertEqual(t, 2, len(log.All()))
+	utils.AssertEqual(t, 2, notified)
+
+	log.Record(models.HubEvent{Type: models.HubEventResult, RequestID: "1"})
+	log.Record(models.HubEvent{Type: models.HubEventCancel, RequestID: "1"})
+
+	// Oldest event was overwritten
+	all := log.All()
+	utils.AssertEqual(t, 3, len(all))
+	utils.AssertEqual(t, "2", all[0].RequestID)
+	utils.AssertEqual(t, models.HubEventCancel, all[2].Type)
+
+	timeline := log.ForRequest("1")
+	utils.AssertEqual(t, 2, len(timeline))
+	utils.AssertEqual(t, models.HubEventResult, timeline[0].Type)
+	utils.AssertEqual(t, false, timeline[0].Timestamp.IsZero())
+	utils.AssertNotEqual(t, timeline[0].ID, timeline[1].ID)
+}
+
+func TestEventLogCountSince(t *testing.T) {
+	log := NewEventLog(10)
+	now := time.Unix(1000, 0)
+	log.Record(models.HubEvent{Type: models.HubEventTimeout, Timestamp: now.Add(-10 * time.Minute)})
+	log.Record(models.HubEvent{Type: models.HubEventTimeout, Timestamp: now.Add(-time.Minute)})
+	log.Record(models.HubEvent{Type: models.HubEventRejected, Timestamp: now})
+
+	counts := log.CountSince(now.Add(-5 * time.Minute))
+	utils.AssertEqual(t, 1, counts[models.HubEventTimeout])
+	utils.AssertEqual(t, 1, counts[models.HubEventRejected])
+	utils.AssertEqual(t, 0, counts[models.HubEventResult])
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/shedding"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	"github.com/google/uuid"
+)
+
+// Hands frontiers requesters registered to workers that have nothing to do, so their capacity isn't wasted
+type IdlePrecacher struct {
+	hub *Hub
+	// Whether the tenant's cache already has work for the hash
+	cached func(tenantID string, hash string, difficultyMultiplier int) bool
+	// Tenants with an idle precache task out, one at a time per tenant
+	busy map[string]bool
+	mu   sync.Mutex
+}
+
+func NewIdlePrecacher(hub *Hub, cached func(tenantID string, hash string, difficultyMultiplier int) bool) *IdlePrecacher {
+	return &IdlePrecacher{
+		hub:    hub,
+		cached: cached,
+		busy:   make(map[string]bool),
+	}
+}
+
+func (p *IdlePrecacher) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		p.Assign(now)
+	}
+}
+
+// Assign sends a frontier to the idle clients of every tenant that has some, if the policy enables idle precaching
+func (p *IdlePrecacher) Assign(now time.Time) {
+	policy := Policy()
+	idleFor := policy.IdlePrecacheAfter()
+	if idleFor == 0 {
+		return
+	}
+	// Frontiers wait in their pools until precache requests aren't shed anymore
+	if Shedder.Sheds(shedding.TierNone, shedding.ClassPrecache, now) != nil {
+		return
+	}
+	for _, tenantID := range p.hub.IdleTenants(idleFor, now) {
+		if !p.claim(tenantID) {
+			continue
+		}
+		task := p.nextTask(tenantID, now)
+		if task == nil {
+			p.release(tenantID)
+			continue
+		}
+		workRequest := serializableModels.ClientMessage{
+			RequesterEmail:       task.RequesterEmail,
+			BlockAward:           true,
+			MessageType:          serializableModels.WorkGenerate,
+			RequestID:            uuid.NewString(),
+			Hash:                 task.Hash,
+			DifficultyMultiplier: task.DifficultyMultiplier,
+			Precache:             true,
+			TenantID:             tenantID,
+		}
+		go func(tenantID string) {
+			defer p.release(tenantID)
+			if _, _, err := broadcastWorkRequestAndWait(workRequest, false, idleFor, policy.IdlePrecacheCreditPercent); err != nil {
+				logging.Debugf(logging.Hub, "Idle precache of %s for tenant %s failed %v", workRequest.Hash, tenantID, err)
+			}
+		}(tenantID)
+	}
+}
+
+// The oldest registered frontier that isn't cached yet, nil if there's none
+func (p *IdlePrecacher) nextTask(tenantID string, now time.Time) *database.FrontierTask {
+	for {
+		task, err := database.GetRedisDB().PopFrontier(tenantID, now)
+		if err != nil {
+			logging.Errorf(logging.Hub, "Error getting a frontier to precache for tenant %s %v", tenantID, err)
+			return nil
+		}
+		if task == nil || !p.cached(tenantID, task.Hash, task.DifficultyMultiplier) {
+			return task
+		}
+	}
+}
+
+func (p *IdlePrecacher) claim(tenantID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.busy[tenantID] {
+		return false
+	}
+	p.busy[tenantID] = true
+	return true
+}
+
+func (p *IdlePrecacher) release(tenantID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.busy, tenantID)
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"golang.org/x/exp/slices"
+)
+
+// Why a request stopped taking results
+const (
+	closedAnswered = "answered"
+	closedTimedOut = "timed out"
+)
+
+// A request that stopped taking results, kept around to account for results that show up late
+type closedRequest struct {
+	channel  models.ActiveChannelObject
+	reason   string
+	closedAt time.Time
+	// Providers credited for it, nobody is credited twice
+	credited []string
+}
+
+// Recently answered or timed out requests, oldest first
+type closedRequests struct {
+	mu       sync.Mutex
+	order    []string
+	requests map[string]*closedRequest
+}
+
+func newClosedRequests() *closedRequests {
+	return &closedRequests{
+		requests: make(map[string]*closedRequest),
+	}
+}
+
+var ClosedRequests = newClosedRequests()
+
+// Only the first close counts, a request that was answered can't time out afterwards
+func (c *closedRequests) Close(channel *models.ActiveChannelObject, reason string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sweep(now)
+	if _, ok := c.requests[channel.RequestID]; ok {
+		return
+	}
+	closed := &closedRequest{channel: *channel, reason: reason, closedAt: now}
+	// The waiter closes the channel once it returns
+	closed.channel.Chan = nil
+	c.requests[channel.RequestID] = closed
+	c.order = append(c.order, channel.RequestID)
+}
+
+// Nil if the request isn't closed or was closed too long ago to remember
+func (c *closedRequests) Get(requestID string, now time.Time) *closedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sweep(now)
+	return c.requests[requestID]
+}
+
+// Records that the provider was credited for the request, false if they already were
+func (c *closedRequests) MarkCredited(requestID string, providerEmail string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	closed, ok := c.requests[requestID]
+	if !ok || slices.Contains(closed.credited, providerEmail) {
+		return false
+	}
+	closed.credited = append(closed.credited, providerEmail)
+	return true
+}
+
+func (c *closedRequests) sweep(now time.Time) {
+	cutoff := now.Add(-config.CLOSED_REQUEST_RETENTION_SECONDS * time.Second)
+	expired := 0
+	for _, requestID := range c.order {
+		if c.requests[requestID].closedAt.After(cutoff) {
+			break
+		}
+		delete(c.requests, requestID)
+		expired++
+	}
+	c.order = c.order[expired:]
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestLateResult(t *testing.T) {
+	statsChan := make(chan repository.WorkMessage, 10)
+	hub := NewHub(&statsChan)
+	now := time.Now()
+	channel := &models.ActiveChannelObject{RequestID: "late-1", TenantID: "default", Hash: "3F93C5CD2E314FA16702189041E68E68C07B27961BF37F0B7705145BEFBA3AA3", DifficultyMultiplier: 1, RequestedAt: now.Add(-time.Second)}
+	ClosedRequests.Close(channel, closedAnswered, now)
+	ClosedRequests.MarkCredited("late-1", "first@example.com")
+	result := func(email string, work string, at time.Time) models.HubEvent {
+		hub.lateResult(ClientWSMessage{ClientEmail: email, TenantID: "default"}, serializableModels.ClientWorkResponse{RequestID: "late-1", Result: work}, at, nil)
+		events := HubEvents.ForRequest("late-1")
+		return events[len(events)-1]
+	}
+
+	// Within the grace period valid work is credited once
+	event := result("second@example.com", "205452237a9b01f4", now.Add(time.Second))
+	utils.AssertEqual(t, models.HubEventLate, event.Type)
+	utils.AssertEqual(t, "1s after it was answered, within the 5s grace period, credited", event.Detail)
+	utils.AssertEqual(t, 1, len(statsChan))
+	utils.AssertEqual(t, "second@example.com", (<-statsChan).ProvidedByEmail)
+	event = result("second@example.com", "205452237a9b01f4", now.Add(2*time.Second))
+	utils.AssertEqual(t, "2s after it was answered, already credited", event.Detail)
+	event = result("first@example.com", "205452237a9b01f4", now.Add(2*time.Second))
+	utils.AssertEqual(t, "2s after it was answered, already credited", event.Detail)
+	event = result("third@example.com", "0000000000000000", now.Add(2*time.Second))
+	utils.AssertEqual(t, "2s after it was answered, invalid work, not credited", event.Detail)
+
+	// Past it nothing is validated or credited
+	event = result("third@example.com", "0000000000000000", now.Add(10*time.Second))
+	utils.AssertEqual(t, models.HubEventLate, event.Type)
+	utils.AssertEqual(t, "10s after it was answered, past the 5s grace period, not credited", event.Detail)
+	utils.AssertEqual(t, 0, len(statsChan))
+
+	// Closing again doesn't change why it closed, and it's forgotten after a while
+	ClosedRequests.Close(channel, closedTimedOut, now.Add(time.Minute))
+	utils.AssertEqual(t, closedAnswered, ClosedRequests.Get("late-1", now).reason)
+	utils.AssertEqual(t, (*closedRequest)(nil), ClosedRequests.Get("late-1", now.Add(config.CLOSED_REQUEST_RETENTION_SECONDS*time.Second)))
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/metrics"
+	"github.com/bananocoin/boompow/apps/server/src/shedding"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+)
+
+// Load an admin sheds, set where it's changed and refreshed from redis on the other servers
+var Shedder = shedding.NewShedder()
+
+// A work request that was shed instead of broadcast, it can be sent again once shedding stops
+type RetryLaterError struct {
+	Until time.Time
+}
+
+func (e *RetryLaterError) Error() string {
+	return "load is being shed, retry later"
+}
+
+// RefreshShedding picks up shedding set on other servers, and stops it once it's expired
+func RefreshShedding() error {
+	sheddingConfig, err := database.GetRedisDB().GetLoadShedding()
+	if err != nil {
+		return err
+	}
+	Shedder.Set(sheddingConfig)
+	return nil
+}
+
+// Nil if the work request can go out
+func shed(workRequest serializableModels.ClientMessage, boosted bool, now time.Time) error {
+	tier := shedding.RequestTier(workRequest.Precache, boosted)
+	class := shedding.Classify(workRequest.Precache, workRequest.DifficultyMultiplier)
+	sheddingConfig := Shedder.Sheds(tier, class, now)
+	if sheddingConfig == nil {
+		return nil
+	}
+	metrics.CountShed(string(tier), string(class))
+	return &RetryLaterError{Until: sheddingConfig.Until}
+}
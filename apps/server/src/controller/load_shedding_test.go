@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/metrics"
+	"github.com/bananocoin/boompow/apps/server/src/shedding"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestLoadShedding(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	now := time.Now()
+	until := now.Add(time.Hour).Truncate(time.Second).UTC()
+	utils.AssertEqual(t, nil, database.GetRedisDB().SetLoadShedding(shedding.Config{Tiers: []shedding.Tier{shedding.TierStandard}, DifficultyClasses: []shedding.DifficultyClass{shedding.ClassPrecache}, Until: until}))
+	defer database.GetRedisDB().DeleteLoadShedding()
+	utils.AssertEqual(t, nil, RefreshShedding())
+	defer Shedder.Set(nil)
+
+	// Shed before they reach the hub
+	shedBefore := testutil.ToFloat64(metrics.ShedRequests.WithLabelValues("standard", "elevated"))
+	_, _, err := BroadcastWorkRequestAndWait(serializableModels.ClientMessage{RequestID: "shed-1", Hash: "ABCD", DifficultyMultiplier: 8})
+	var retryLater *RetryLaterError
+	utils.AssertEqual(t, true, errors.As(err, &retryLater))
+	utils.AssertEqual(t, until, retryLater.Until.UTC())
+	utils.AssertEqual(t, shedBefore+1, testutil.ToFloat64(metrics.ShedRequests.WithLabelValues("standard", "elevated")))
+	_, _, err = BroadcastWorkRequestAndWait(serializableModels.ClientMessage{RequestID: "shed-2", Hash: "ABCD", DifficultyMultiplier: 64, Precache: true})
+	utils.AssertEqual(t, true, errors.As(err, &retryLater))
+	utils.AssertEqual(t, nil, shed(serializableModels.ClientMessage{DifficultyMultiplier: 8}, true, now))
+
+	// Stopped on another server
+	database.GetRedisDB().DeleteLoadShedding()
+	utils.AssertEqual(t, nil, RefreshShedding())
+	utils.AssertEqual(t, nil, shed(serializableModels.ClientMessage{DifficultyMultiplier: 8}, false, now))
+}
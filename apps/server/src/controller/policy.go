@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"sync/atomic"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+)
+
+var activePolicy atomic.Pointer[models.HubPolicy]
+
+func init() {
+	SetPolicy(models.DefaultHubPolicy())
+}
+
+// SetPolicy hot-applies a hub policy, work requests that are already waiting keep the policy they started with
+func SetPolicy(policy models.HubPolicy) {
+	activePolicy.Store(&policy)
+}
+
+// Policy returns the hub policy that's currently applied
+func Policy() models.HubPolicy {
+	return *activePolicy.Load()
+}
+
+// LoadPolicy applies the saved policy, so changes made on another replica get picked up
+func LoadPolicy(repo repository.HubPolicyRepo) error {
+	policy, err := repo.GetHubPolicy()
+	if err != nil {
+		return err
+	}
+	SetPolicy(*policy)
+	return nil
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+)
+
+// A provider's worker connected to this server
+type ProviderWorker struct {
+	IPAddress   string
+	ConnectedAt time.Time
+	// From the worker's hello, empty if it didn't send one
+	ClientVersion string
+	GPUs          []string
+	CPUThreads    int
+	// Work requests it's solving
+	InFlight int
+}
+
+// ProviderWorkers lists the workers of a provider connected to this server, longest connected first
+func (h *Hub) ProviderWorkers(email string) []ProviderWorker {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	workers := []ProviderWorker{}
+	for c := range h.Clients {
+		if !strings.EqualFold(c.Email, email) {
+			continue
+		}
+		workers = append(workers, ProviderWorker{
+			IPAddress:     c.IPAddress,
+			ConnectedAt:   c.connectedAt,
+			ClientVersion: c.hello.ClientVersion,
+			GPUs:          c.hello.GPUs,
+			CPUThreads:    c.hello.CPUThreads,
+			InFlight:      c.inFlight,
+		})
+	}
+	sort.Slice(workers, func(i, j int) bool {
+		return workers[i].ConnectedAt.Before(workers[j].ConnectedAt)
+	})
+	return workers
+}
+
+// Wakes the stats subscriptions of a provider when its workers connect, disconnect or have a result accepted
+type ProviderActivityHub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan struct{}]bool
+}
+
+func NewProviderActivityHub() *ProviderActivityHub {
+	return &ProviderActivityHub{
+		subscribers: make(map[string]map[chan struct{}]bool),
+	}
+}
+
+var ProviderActivity = NewProviderActivityHub()
+
+// Subscribe to the activity of a provider, the returned function must be called once the subscriber is gone
+// Activity while the subscriber is busy is coalesced into one wake up
+func (h *ProviderActivityHub) Subscribe(email string) (<-chan struct{}, func()) {
+	key := strings.ToLower(email)
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers[key] == nil {
+		h.subscribers[key] = make(map[chan struct{}]bool)
+	}
+	h.subscribers[key][ch] = true
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers[key], ch)
+		if len(h.subscribers[key]) == 0 {
+			delete(h.subscribers, key)
+		}
+	}
+}
+
+// Listener for the hub events, it doesn't block
+func (h *ProviderActivityHub) HandleEvent(event models.HubEvent) {
+	switch event.Type {
+	case models.HubEventConnect, models.HubEventDisconnect:
+	case models.HubEventResult:
+		// Invalid results change nothing
+		if event.Detail != "" {
+			return
+		}
+	default:
+		return
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subscribers[strings.ToLower(event.ClientEmail)] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestProviderWorkers(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	hub := NewHub(nil)
+	now := time.Now()
+	hub.Clients[&Client{IPAddress: "2.2.2.2", Email: "provider@example.com", connectedAt: now, inFlight: 1}] = true
+	hub.Clients[&Client{IPAddress: "1.1.1.1", Email: "Provider@Example.com", connectedAt: now.Add(-time.Minute), hello: serializableModels.WorkerHello{ClientVersion: "1.0.0", GPUs: []string{"RTX 3080"}, CPUThreads: 8}}] = true
+	hub.Clients[&Client{IPAddress: "3.3.3.3", Email: "other@example.com", connectedAt: now}] = true
+
+	workers := hub.ProviderWorkers("provider@example.com")
+	utils.AssertEqual(t, 2, len(workers))
+	utils.AssertEqual(t, ProviderWorker{IPAddress: "1.1.1.1", ConnectedAt: now.Add(-time.Minute), ClientVersion: "1.0.0", GPUs: []string{"RTX 3080"}, CPUThreads: 8}, workers[0])
+	utils.AssertEqual(t, "2.2.2.2", workers[1].IPAddress)
+	utils.AssertEqual(t, 1, workers[1].InFlight)
+
+	utils.AssertEqual(t, 0, len(hub.ProviderWorkers("nobody@example.com")))
+}
+
+func TestProviderActivity(t *testing.T) {
+	activity := NewProviderActivityHub()
+	wakeUps, unsubscribe := activity.Subscribe("Provider@example.com")
+
+	// Invalid results, other event types and other providers don't wake it
+	activity.HandleEvent(models.HubEvent{Type: models.HubEventResult, ClientEmail: "provider@example.com", Detail: "invalid work"})
+	activity.HandleEvent(models.HubEvent{Type: models.HubEventAssigned, ClientEmail: "provider@example.com"})
+	activity.HandleEvent(models.HubEvent{Type: models.HubEventConnect, ClientEmail: "other@example.com"})
+	utils.AssertEqual(t, 0, len(wakeUps))
+
+	// Coalesced while nobody reads
+	activity.HandleEvent(models.HubEvent{Type: models.HubEventConnect, ClientEmail: "provider@example.com"})
+	activity.HandleEvent(models.HubEvent{Type: models.HubEventResult, ClientEmail: "provider@example.com"})
+	activity.HandleEvent(models.HubEvent{Type: models.HubEventDisconnect, ClientEmail: "PROVIDER@example.com"})
+	utils.AssertEqual(t, 1, len(wakeUps))
+	<-wakeUps
+
+	unsubscribe()
+	activity.HandleEvent(models.HubEvent{Type: models.HubEventConnect, ClientEmail: "provider@example.com"})
+	utils.AssertEqual(t, 0, len(wakeUps))
+	utils.AssertEqual(t, 0, len(activity.subscribers))
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+)
+
+// Why a frame from a worker was thrown away
+const (
+	frameMalformed = "malformed"
+	frameOversized = "oversized"
+)
+
+// A worker that keeps sending frames the hub can't use
+type QuarantinedWorker struct {
+	IPAddress string
+	Email     string
+	Until     time.Time
+}
+
+// Counted since the server started
+type AbuseStats struct {
+	MalformedFrames int
+	OversizedFrames int
+	Quarantines     int
+	Quarantined     []QuarantinedWorker
+}
+
+// Strikes and quarantines by IP, like the hub only allows one connection per IP
+type quarantine struct {
+	mu          sync.Mutex
+	strikes     map[string][]time.Time
+	quarantined map[string]QuarantinedWorker
+	stats       AbuseStats
+}
+
+func newQuarantine() *quarantine {
+	return &quarantine{
+		strikes:     make(map[string][]time.Time),
+		quarantined: make(map[string]QuarantinedWorker),
+	}
+}
+
+var Quarantine = newQuarantine()
+
+// Counts a thrown away frame, true if it got the worker quarantined
+func (q *quarantine) Strike(ip string, email string, reason string, now time.Time) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.sweep(now)
+	if reason == frameOversized {
+		q.stats.OversizedFrames++
+	} else {
+		q.stats.MalformedFrames++
+	}
+	if _, ok := q.quarantined[ip]; ok {
+		return false
+	}
+	q.strikes[ip] = append(q.strikes[ip], now)
+	if len(q.strikes[ip]) < config.MALFORMED_FRAME_STRIKES {
+		return false
+	}
+	delete(q.strikes, ip)
+	q.quarantined[ip] = QuarantinedWorker{IPAddress: ip, Email: email, Until: now.Add(config.QUARANTINE_MINUTES * time.Minute)}
+	q.stats.Quarantines++
+	return true
+}
+
+func (q *quarantine) Quarantined(ip string, now time.Time) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.sweep(now)
+	_, ok := q.quarantined[ip]
+	return ok
+}
+
+// Quarantined workers are sorted by when they're let back in
+func (q *quarantine) Stats(now time.Time) AbuseStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.sweep(now)
+	stats := q.stats
+	stats.Quarantined = make([]QuarantinedWorker, 0, len(q.quarantined))
+	for _, worker := range q.quarantined {
+		stats.Quarantined = append(stats.Quarantined, worker)
+	}
+	sort.Slice(stats.Quarantined, func(i, j int) bool {
+		return stats.Quarantined[i].Until.Before(stats.Quarantined[j].Until)
+	})
+	return stats
+}
+
+func (q *quarantine) sweep(now time.Time) {
+	cutoff := now.Add(-config.MALFORMED_FRAME_WINDOW_MINUTES * time.Minute)
+	for ip, strikes := range q.strikes {
+		recent := strikes[:0]
+		for _, strike := range strikes {
+			if strike.After(cutoff) {
+				recent = append(recent, strike)
+			}
+		}
+		if len(recent) == 0 {
+			delete(q.strikes, ip)
+		} else {
+			q.strikes[ip] = recent
+		}
+	}
+	for ip, worker := range q.quarantined {
+		if !now.Before(worker.Until) {
+			delete(q.quarantined, ip)
+		}
+	}
+}
+
+// Lifts a quarantine early, false if the worker wasn't quarantined
+func (q *quarantine) Release(ip string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, ok := q.quarantined[ip]
+	delete(q.quarantined, ip)
+	delete(q.strikes, ip)
+	return ok
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestQuarantine(t *testing.T) {
+	q := newQuarantine()
+	now := time.Date(2022, 10, 3, 12, 0, 0, 0, time.UTC)
+
+	// Strikes outside the window are forgotten
+	q.Strike("1.1.1.1", "joe@example.com", frameMalformed, now)
+	now = now.Add(config.MALFORMED_FRAME_WINDOW_MINUTES * time.Minute)
+	for i := 1; i < config.MALFORMED_FRAME_STRIKES; i++ {
+		utils.AssertEqual(t, false, q.Strike("1.1.1.1", "joe@example.com", frameMalformed, now))
+	}
+	utils.AssertEqual(t, false, q.Quarantined("1.1.1.1", now))
+
+	utils.AssertEqual(t, true, q.Strike("1.1.1.1", "joe@example.com", frameOversized, now))
+	utils.AssertEqual(t, true, q.Quarantined("1.1.1.1", now))
+	utils.AssertEqual(t, false, q.Quarantined("2.2.2.2", now))
+	stats := q.Stats(now)
+	utils.AssertEqual(t, config.MALFORMED_FRAME_STRIKES, stats.MalformedFrames)
+	utils.AssertEqual(t, 1, stats.OversizedFrames)
+	utils.AssertEqual(t, 1, stats.Quarantines)
+	utils.AssertEqual(t, []QuarantinedWorker{{IPAddress: "1.1.1.1", Email: "joe@example.com", Until: now.Add(config.QUARANTINE_MINUTES * time.Minute)}}, stats.Quarantined)
+
+	// Let back in once it's over
+	now = now.Add(config.QUARANTINE_MINUTES * time.Minute)
+	utils.AssertEqual(t, false, q.Quarantined("1.1.1.1", now))
+	utils.AssertEqual(t, 0, len(q.Stats(now).Quarantined))
+
+	for i := 0; i < config.MALFORMED_FRAME_STRIKES; i++ {
+		q.Strike("1.1.1.1", "joe@example.com", frameMalformed, now)
+	}
+	utils.AssertEqual(t, true, q.Release("1.1.1.1"))
+	utils.AssertEqual(t, false, q.Quarantined("1.1.1.1", now))
+	utils.AssertEqual(t, false, q.Release("1.1.1.1"))
+}
+
+func TestValidateWorkerFrame(t *testing.T) {
+	hash := "3F93C5CD2E314FA16702189041E68E68C07B27961BF37F0B7705145BEFBA3AA3"
+	utils.AssertEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{RequestID: "1", Hash: hash, Result: "205452237a9b01f4"}))
+	utils.AssertEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{AckMessageID: "1"}))
+	utils.AssertEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{RequestID: "1", Hash: "bad", Rejected: serializableModels.RejectInvalidHash}))
+	utils.AssertEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{Rejected: serializableModels.RejectMissingRequestID}))
+	utils.AssertEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{RequestID: "1", Progress: &serializableModels.WorkProgress{Iterations: 1000, ElapsedMs: 5000}}))
+	utils.AssertEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{Hello: &serializableModels.WorkerHello{ProtocolVersion: 2, MinDifficulty: 1, MaxDifficulty: 64}}))
+
+	utils.AssertNotEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{}))
+	utils.AssertNotEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{RequestID: "1", Rejected: "bored"}))
+	utils.AssertNotEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{Hash: hash, Result: "205452237a9b01f4"}))
+	utils.AssertNotEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{RequestID: "1", Hash: "abc", Result: "205452237a9b01f4"}))
+	utils.AssertNotEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{RequestID: "1", Hash: hash, Result: "xyz"}))
+	utils.AssertNotEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{Progress: &serializableModels.WorkProgress{ElapsedMs: 5000}}))
+	utils.AssertNotEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{RequestID: "1", Progress: &serializableModels.WorkProgress{ElapsedMs: -1}}))
+	utils.AssertNotEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{Hello: &serializableModels.WorkerHello{}}))
+	utils.AssertNotEqual(t, nil, validateWorkerFrame(serializableModels.ClientWorkResponse{Hello: &serializableModels.WorkerHello{ProtocolVersion: 2, MinDifficulty: 64, MaxDifficulty: 1}}))
+
+	_, err := parseWorkerFrame([]byte("{not json"))
+	utils.AssertNotEqual(t, nil, err)
+}
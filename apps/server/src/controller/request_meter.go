@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/metrics"
+)
+
+// Work requests answered within a minute
+type requestBucket struct {
+	minute   int64
+	requests int
+	cached   int
+	failed   int
+}
+
+// Counted by this server over the window
+type RequestRates struct {
+	Window   time.Duration
+	Requests int
+	Cached   int
+	Failed   int
+}
+
+// Per minute counts of work requests, so the admin metrics don't need a metrics backend
+type requestMeter struct {
+	mu      sync.Mutex
+	buckets []requestBucket
+}
+
+func newRequestMeter(minutes int) *requestMeter {
+	return &requestMeter{buckets: make([]requestBucket, minutes)}
+}
+
+var Requests = newRequestMeter(config.ADMIN_METRICS_WINDOW_MINUTES)
+
+// Counts an answered work request, cached ones didn't reach the workers
+func (m *requestMeter) Count(cached bool, failed bool, now time.Time) {
+	metrics.WorkRequests.WithLabelValues(requestOutcome(cached, failed)).Inc()
+	minute := now.Unix() / 60
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := &m.buckets[minute%int64(len(m.buckets))]
+	if bucket.minute != minute {
+		*bucket = requestBucket{minute: minute}
+	}
+	bucket.requests++
+	if cached {
+		bucket.cached++
+	}
+	if failed {
+		bucket.failed++
+	}
+}
+
+func requestOutcome(cached bool, failed bool) string {
+	switch {
+	case cached:
+		return "cached"
+	case failed:
+		return "failed"
+	}
+	return "solved"
+}
+
+// The counts of the whole window, including the current minute
+func (m *requestMeter) Rates(now time.Time) RequestRates {
+	minute := now.Unix() / 60
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rates := RequestRates{Window: time.Duration(len(m.buckets)) * time.Minute}
+	for _, bucket := range m.buckets {
+		if minute-bucket.minute >= int64(len(m.buckets)) {
+			continue
+		}
+		rates.Requests += bucket.requests
+		rates.Cached += bucket.cached
+		rates.Failed += bucket.failed
+	}
+	return rates
+}
+
+// Share of the requests that failed, 0 without requests
+func (r RequestRates) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Failed) / float64(r.Requests)
+}
+
+func (r RequestRates) PerMinute() float64 {
+	return float64(r.Requests) / r.Window.Minutes()
+}
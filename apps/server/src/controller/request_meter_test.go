@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestRequestMeter(t *testing.T) {
+	meter := newRequestMeter(5)
+	now := time.Unix(6000, 0)
+	utils.AssertEqual(t, 0.0, meter.Rates(now).ErrorRate())
+
+	meter.Count(false, false, now.Add(-10*time.Minute))
+	meter.Count(false, false, now.Add(-4*time.Minute))
+	meter.Count(true, false, now.Add(-time.Minute))
+	meter.Count(false, true, now)
+	meter.Count(false, false, now)
+
+	// The one 10 minutes ago is out of the window
+	rates := meter.Rates(now)
+	utils.AssertEqual(t, 4, rates.Requests)
+	utils.AssertEqual(t, 1, rates.Cached)
+	utils.AssertEqual(t, 1, rates.Failed)
+	utils.AssertEqual(t, 0.25, rates.ErrorRate())
+	utils.AssertEqual(t, 0.8, rates.PerMinute())
+
+	// Buckets are reused once they fall out of the window
+	later := now.Add(5 * time.Minute)
+	meter.Count(false, false, later)
+	utils.AssertEqual(t, 1, meter.Rates(later).Requests)
+}
+
+func TestRequestOutcome(t *testing.T) {
+	utils.AssertEqual(t, "cached", requestOutcome(true, false))
+	utils.AssertEqual(t, "failed", requestOutcome(false, true))
+	utils.AssertEqual(t, "solved", requestOutcome(false, false))
+}
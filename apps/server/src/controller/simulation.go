@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	"github.com/bananocoin/boompow/libs/utils/validation"
+)
+
+// How simulated workers behave, they stand in for client binaries in local development
+type SimulationSettings struct {
+	TenantID string
+	// Mean time a worker takes to answer, each answer takes from half to one and a half times it
+	Latency time.Duration
+	// Share of answers that are invalid work, from 0 to 100
+	ErrorPercent float64
+}
+
+// Simulated workers answer work requests with simulatedWork instead of solving them, the hub only accepts it from them
+// Requesters get work nodes won't accept, so it's only for exercising the request to award flow
+type Simulation struct {
+	settings SimulationSettings
+	mu       sync.Mutex
+	rand     *rand.Rand
+}
+
+// A stand-in result for hash that the hub accepts from simulated workers
+func simulatedWork(hash string) string {
+	sum := sha256.Sum256([]byte("boompow-simulated-" + strings.ToUpper(hash)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Whether a result answers the work request, simulated workers' results are checked against simulatedWork instead
+func resultValid(client *Client, hash string, difficultyMultiplier int, result string) bool {
+	if client != nil && client.simulated {
+		return result == simulatedWork(hash)
+	}
+	return validation.IsWorkValid(hash, difficultyMultiplier, result)
+}
+
+func NewSimulation(settings SimulationSettings, seed int64) *Simulation {
+	return &Simulation{
+		settings: settings,
+		rand:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// How long the next answer takes and whether it's invalid work
+func (s *Simulation) next() (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delay := s.settings.Latency/2 + time.Duration(s.rand.Int63n(int64(s.settings.Latency)+1))
+	return delay, s.rand.Float64()*100 < s.settings.ErrorPercent
+}
+
+func (s *Simulation) invalidWork() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := make([]byte, 8)
+	s.rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Connects a simulated worker for each provider email, they work until the hub closes them
+// The hub has to be running
+func (s *Simulation) Start(hub *Hub, emails []string) {
+	// Speak the current protocol without a hello, so they skip calibration
+	version, features, _ := negotiate(serializableModels.WorkerHello{
+		ProtocolVersion: serializableModels.ProtocolVersion,
+		Features:        []string{serializableModels.FeatureAck, serializableModels.FeatureReject},
+	})
+	for i, email := range emails {
+		worker := &simulatedWorker{
+			simulation: s,
+			client: &Client{
+				Hub:  hub,
+				Send: make(chan []byte, 256),
+				// Documentation range, so they're never mistaken for real workers
+				IPAddress: fmt.Sprintf("198.51.100.%d", i+1),
+				Email:     email,
+				TenantID:  s.settings.TenantID,
+				protocol:  version,
+				features:  features,
+				simulated: true,
+			},
+			pending: make(map[string]*pendingSolve),
+		}
+		hub.Register <- worker.client
+		go worker.run()
+	}
+	logging.Infof(logging.Hub, "Started %d simulated workers answering in about %s, %.1f%% with invalid work", len(emails), s.settings.Latency, s.settings.ErrorPercent)
+}
+
+type pendingSolve struct {
+	hash  string
+	timer *time.Timer
+}
+
+type simulatedWorker struct {
+	simulation *Simulation
+	client     *Client
+	mu         sync.Mutex
+	// Answers that are still to come by request ID, nil once the worker was closed
+	pending map[string]*pendingSolve
+}
+
+// Handles what the hub sends the worker until it closes Send
+func (w *simulatedWorker) run() {
+	for msg := range w.client.Send {
+		var message serializableModels.ClientMessage
+		if err := json.Unmarshal(msg, &message); err != nil {
+			continue
+		}
+		switch message.MessageType {
+		case serializableModels.WorkGenerate:
+			w.solve(message)
+		case serializableModels.WorkCancel:
+			w.cancel(message.Hash)
+		case serializableModels.BlockAwarded:
+			if message.MessageID != "" {
+				w.respond(serializableModels.ClientWorkResponse{AckMessageID: message.MessageID})
+			}
+		}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, solve := range w.pending {
+		solve.timer.Stop()
+	}
+	w.pending = nil
+}
+
+func (w *simulatedWorker) solve(message serializableModels.ClientMessage) {
+	if reason := message.Malformed(); reason != "" {
+		w.respond(serializableModels.ClientWorkResponse{RequestID: message.RequestID, Hash: message.Hash, Rejected: reason, RejectedCounts: map[string]int{reason: 1}})
+		return
+	}
+	delay, invalid := w.simulation.next()
+	result := simulatedWork(message.Hash)
+	if invalid {
+		result = w.simulation.invalidWork()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.pending == nil {
+		return
+	}
+	if solve, ok := w.pending[message.RequestID]; ok {
+		// A retry of a request it's already working on
+		solve.timer.Stop()
+	}
+	solve := &pendingSolve{hash: message.Hash}
+	solve.timer = time.AfterFunc(delay, func() {
+		w.mu.Lock()
+		current := w.pending[message.RequestID] == solve
+		if current {
+			delete(w.pending, message.RequestID)
+		}
+		w.mu.Unlock()
+		if current {
+			w.respond(serializableModels.ClientWorkResponse{RequestID: message.RequestID, Hash: message.Hash, Result: result})
+		}
+	})
+	w.pending[message.RequestID] = solve
+}
+
+// Another worker answered, so the solves of hash stop like they do on clients
+func (w *simulatedWorker) cancel(hash string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for requestID, solve := range w.pending {
+		if strings.EqualFold(solve.hash, hash) {
+			solve.timer.Stop()
+			delete(w.pending, requestID)
+		}
+	}
+}
+
+func (w *simulatedWorker) respond(response serializableModels.ClientWorkResponse) {
+	msg, err := json.Marshal(response)
+	if err != nil {
+		logging.Errorf(logging.Hub, "Error marshalling simulated response %v", err)
+		return
+	}
+	w.client.Hub.Response <- ClientWSMessage{ClientEmail: w.client.Email, TenantID: w.client.TenantID, msg: msg, client: w.client}
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestResultValid(t *testing.T) {
+	hash := "3F93C5CD2E314FA16702189041E68E68C07B27961BF37F0B7705145BEFBA3AA3"
+	simulated := &Client{simulated: true}
+	utils.AssertEqual(t, true, resultValid(simulated, hash, 1, simulatedWork(hash)))
+	utils.AssertEqual(t, true, resultValid(simulated, hash, 1, simulatedWork("3f93c5cd2e314fa16702189041e68e68c07b27961bf37f0b7705145befba3aa3")))
+	utils.AssertEqual(t, false, resultValid(simulated, hash, 1, "205452237a9b01f4"))
+	// Only simulated workers get away with it
+	utils.AssertEqual(t, false, resultValid(&Client{}, hash, 1, simulatedWork(hash)))
+	utils.AssertEqual(t, false, resultValid(nil, hash, 1, simulatedWork(hash)))
+	utils.AssertEqual(t, true, resultValid(&Client{}, hash, 1, "205452237a9b01f4"))
+}
+
+func TestSimulationNext(t *testing.T) {
+	simulation := NewSimulation(SimulationSettings{Latency: 100 * time.Millisecond, ErrorPercent: 25}, 1)
+	invalid := 0
+	for i := 0; i < 1000; i++ {
+		delay, failed := simulation.next()
+		utils.AssertEqual(t, true, delay >= 50*time.Millisecond && delay <= 150*time.Millisecond)
+		if failed {
+			invalid++
+		}
+	}
+	utils.AssertEqual(t, true, invalid > 150 && invalid < 350)
+}
+
+func TestSimulation(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	statsChan := make(chan repository.WorkMessage, 10)
+	hub := NewHub(&statsChan)
+	previous := ActiveHub
+	ActiveHub = hub
+	defer func() { ActiveHub = previous }()
+	go hub.Run()
+	defer hub.Stop()
+	NewSimulation(SimulationSettings{TenantID: "default", Latency: 10 * time.Millisecond}, 1).Start(hub, []string{"sim-worker-1@dev.boompow.local"})
+
+	hash := "4F93C5CD2E314FA16702189041E68E68C07B27961BF37F0B7705145BEFBA3AA3"
+	// Closed requests are remembered across runs
+	requestID := fmt.Sprintf("simulated-%d", time.Now().UnixNano())
+	channel := &models.ActiveChannelObject{RequestID: requestID, TenantID: "default", Hash: hash, DifficultyMultiplier: 1, Chan: make(chan []byte, 1), RequestedAt: time.Now()}
+	ActiveChannels.Put(channel)
+	defer ActiveChannels.Delete(requestID)
+	msg, err := json.Marshal(serializableModels.ClientMessage{MessageType: serializableModels.WorkGenerate, RequestID: requestID, Hash: hash, DifficultyMultiplier: 1})
+	utils.AssertEqual(t, nil, err)
+	hub.Broadcast <- BroadcastMessage{TenantID: "default", Msg: msg, Event: models.HubEventAssigned, RequestID: requestID, Hash: hash, DifficultyMultiplier: 1}
+
+	// The whole way to the requester and the provider's credit
+	select {
+	case result := <-channel.Chan:
+		var response serializableModels.ClientWorkResponse
+		utils.AssertEqual(t, nil, json.Unmarshal(result, &response))
+		utils.AssertEqual(t, simulatedWork(hash), response.Result)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the simulated worker didn't answer")
+	}
+	utils.AssertEqual(t, "sim-worker-1@dev.boompow.local", (<-statsChan).ProvidedByEmail)
+
+	// Disconnecting its provider closes it like a real worker
+	utils.AssertEqual(t, 1, hub.DisconnectUser("sim-worker-1@dev.boompow.local", serializableModels.DisconnectBanned))
+	utils.AssertEqual(t, 0, hub.Snapshot().Workers)
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+)
+
+// A worker's solves at one difficulty
+type SolveStats struct {
+	Solves int
+	// Exponentially weighted, recent solves count more
+	Average time.Duration
+}
+
+// Observed solve times of a connection by difficulty multiplier, from when it was sent the request until its valid result arrived
+type solveTimes map[int]*SolveStats
+
+func (s solveTimes) record(difficultyMultiplier int, took time.Duration) {
+	stats, ok := s[difficultyMultiplier]
+	if !ok {
+		s[difficultyMultiplier] = &SolveStats{Solves: 1, Average: took}
+		return
+	}
+	stats.Solves++
+	stats.Average += time.Duration(config.SOLVE_TIMES_SMOOTHING * float64(took-stats.Average))
+}
+
+// How long the worker is expected to take at the difficulty, false without enough solves to tell
+// Work grows linearly with the multiplier, so solves at other difficulties are scaled when there aren't enough at this one
+func (s solveTimes) estimate(difficultyMultiplier int) (time.Duration, bool) {
+	if stats, ok := s[difficultyMultiplier]; ok && stats.Solves >= config.SOLVE_TIMES_MIN_SAMPLES {
+		return stats.Average, true
+	}
+	solves := 0
+	perUnit := 0.0
+	for difficulty, stats := range s {
+		solves += stats.Solves
+		perUnit += float64(stats.Average) / float64(difficulty) * float64(stats.Solves)
+	}
+	if solves < config.SOLVE_TIMES_MIN_SAMPLES {
+		return 0, false
+	}
+	return time.Duration(perUnit / float64(solves) * float64(difficultyMultiplier)), true
+}
+
+// Whether the worker is known to solve the difficulty within the SLA
+func (s solveTimes) meets(difficultyMultiplier int, sla time.Duration) bool {
+	estimate, ok := s.estimate(difficultyMultiplier)
+	return ok && estimate <= sla
+}
+
+// Narrows the recipients of a work request down to the clients that meet the policy's SLA at its difficulty
+// Everyone gets it if nobody does, the detail says which it was for the hub event
+func targetRecipients(recipients []*Client, difficultyMultiplier int, policy models.HubPolicy) ([]*Client, string) {
+	sla, ok := policy.TargetedSLA(difficultyMultiplier)
+	if !ok {
+		return recipients, ""
+	}
+	targeted := []*Client{}
+	for _, client := range recipients {
+		if client.solveTimes.meets(difficultyMultiplier, sla) {
+			targeted = append(targeted, client)
+		}
+	}
+	if len(targeted) == 0 {
+		return recipients, fmt.Sprintf(", none meet the %s SLA", sla)
+	}
+	return targeted, fmt.Sprintf(" meeting the %s SLA", sla)
+}
+
+// Remembers when a work request first went out to the client, guarded by the hub's mutex
+func (h *Hub) markSent(requestID string, client *Client, now time.Time) {
+	sent, ok := h.sentAt[requestID]
+	if !ok {
+		sent = make(map[*Client]time.Time)
+		h.sentAt[requestID] = sent
+	}
+	if _, ok := sent[client]; !ok {
+		sent[client] = now
+	}
+}
+
+// Records how long the client took for a valid result, results over HTTP have no connection to attribute them to
+func (h *Hub) recordSolve(client *Client, requestID string, difficultyMultiplier int, receivedAt time.Time) {
+	if client == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sentAt, ok := h.sentAt[requestID][client]
+	if !ok {
+		return
+	}
+	if client.solveTimes == nil {
+		client.solveTimes = make(solveTimes)
+	}
+	client.solveTimes.record(difficultyMultiplier, receivedAt.Sub(sentAt))
+}
+
+// Solve times of a connected worker
+type WorkerSolveTimes struct {
+	IPAddress string
+	Email     string
+	// By difficulty multiplier
+	Difficulties map[int]SolveStats
+}
+
+// Connected workers that solved anything, by IP
+func (h *Hub) SolveTimes() []WorkerSolveTimes {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ret := []WorkerSolveTimes{}
+	for client := range h.Clients {
+		if len(client.solveTimes) == 0 {
+			continue
+		}
+		worker := WorkerSolveTimes{IPAddress: client.IPAddress, Email: client.Email, Difficulties: make(map[int]SolveStats, len(client.solveTimes))}
+		for difficulty, stats := range client.solveTimes {
+			worker.Difficulties[difficulty] = *stats
+		}
+		ret = append(ret, worker)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].IPAddress < ret[j].IPAddress
+	})
+	return ret
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestSolveTimesEstimate(t *testing.T) {
+	times := make(solveTimes)
+	_, ok := times.estimate(1)
+	utils.AssertEqual(t, false, ok)
+
+	times.record(1, time.Second)
+	times.record(1, time.Second)
+	_, ok = times.estimate(1)
+	utils.AssertEqual(t, false, ok)
+	times.record(1, 2*time.Second)
+	estimate, ok := times.estimate(1)
+	utils.AssertEqual(t, true, ok)
+	// Recent solves count more
+	utils.AssertEqual(t, 1300*time.Millisecond, estimate)
+
+	// Scaled from the other difficulties until there are enough solves at this one
+	estimate, ok = times.estimate(8)
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, 10400*time.Millisecond, estimate)
+	utils.AssertEqual(t, true, times.meets(8, 11*time.Second))
+	utils.AssertEqual(t, false, times.meets(8, 10*time.Second))
+	utils.AssertEqual(t, false, solveTimes(nil).meets(1, time.Hour))
+}
+
+func TestTargetedDispatch(t *testing.T) {
+	policy := models.DefaultHubPolicy()
+	policy.TargetedDifficulty = 8
+	policy.SolveSLASeconds = 10
+	SetPolicy(policy)
+	defer SetPolicy(models.DefaultHubPolicy())
+
+	hub := NewHub(nil)
+	fast := &Client{IPAddress: "1.1.1.1", TenantID: "default", Send: make(chan []byte, 10), solveTimes: make(solveTimes)}
+	slow := &Client{IPAddress: "2.2.2.2", TenantID: "default", Send: make(chan []byte, 10)}
+	hub.Clients[fast] = true
+	hub.Clients[slow] = true
+
+	// Nobody has solved anything yet, so everyone gets it
+	hub.broadcast(BroadcastMessage{TenantID: "default", Msg: []byte("1"), Event: models.HubEventAssigned, RequestID: "targeted-1", DifficultyMultiplier: 8})
+	utils.AssertEqual(t, 1, len(fast.Send))
+	utils.AssertEqual(t, 1, len(slow.Send))
+	sentAt := hub.sentAt["targeted-1"][fast]
+	utils.AssertEqual(t, false, sentAt.IsZero())
+
+	for i := 0; i < 3; i++ {
+		fast.solveTimes.record(8, 5*time.Second)
+	}
+	hub.recordSolve(slow, "targeted-1", 8, sentAt.Add(20*time.Second))
+	hub.recordSolve(slow, "targeted-1", 8, sentAt.Add(20*time.Second))
+	hub.recordSolve(slow, "targeted-1", 8, sentAt.Add(20*time.Second))
+	utils.AssertEqual(t, 3, slow.solveTimes[8].Solves)
+	hub.Release("targeted-1")
+	utils.AssertEqual(t, 0, len(hub.sentAt))
+
+	hub.broadcast(BroadcastMessage{TenantID: "default", Msg: []byte("2"), Event: models.HubEventAssigned, RequestID: "targeted-2", DifficultyMultiplier: 8})
+	utils.AssertEqual(t, 2, len(fast.Send))
+	utils.AssertEqual(t, 1, len(slow.Send))
+	events := HubEvents.ForRequest("targeted-2")
+	utils.AssertEqual(t, "sent to 1 clients meeting the 10s SLA", events[len(events)-1].Detail)
+
+	// Lower difficulties and retries go to everyone
+	hub.broadcast(BroadcastMessage{TenantID: "default", Msg: []byte("3"), Event: models.HubEventAssigned, RequestID: "targeted-3", DifficultyMultiplier: 1})
+	hub.broadcast(BroadcastMessage{TenantID: "default", Msg: []byte("2"), Event: models.HubEventAssigned, RequestID: "targeted-2", DifficultyMultiplier: 8, Attempt: 1})
+	utils.AssertEqual(t, 4, len(fast.Send))
+	utils.AssertEqual(t, 3, len(slow.Send))
+
+	solveTimes := hub.SolveTimes()
+	utils.AssertEqual(t, 2, len(solveTimes))
+	utils.AssertEqual(t, "2.2.2.2", solveTimes[1].IPAddress)
+	utils.AssertEqual(t, 20*time.Second, solveTimes[1].Difficulties[8].Average)
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
+)
+
+// A change relevant to a single user, published by the database through NOTIFY
+type UserEvent struct {
+	Type      string    `json:"type"`
+	UserID    uuid.UUID `json:"user_id"`
+	BlockHash string    `json:"block_hash,omitempty"`
+	AmountRaw string    `json:"amount_raw,omitempty"`
+}
+
+// Fans user events out to the subscriptions of that user
+type UserEventHub struct {
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[chan UserEvent]bool
+	listeners   []func(UserEvent)
+}
+
+func NewUserEventHub() *UserEventHub {
+	return &UserEventHub{
+		subscribers: make(map[uuid.UUID]map[chan UserEvent]bool),
+	}
+}
+
+var UserEvents = NewUserEventHub()
+
+// Subscribe to events of a user, the returned function must be called once the subscriber is gone
+func (h *UserEventHub) Subscribe(userID uuid.UUID) (<-chan UserEvent, func()) {
+	ch := make(chan UserEvent, 10)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan UserEvent]bool)
+	}
+	h.subscribers[userID][ch] = true
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers[userID], ch)
+		if len(h.subscribers[userID]) == 0 {
+			delete(h.subscribers, userID)
+		}
+	}
+}
+
+// Listeners get the events of every user, they must not block
+func (h *UserEventHub) AddListener(listener func(UserEvent)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.listeners = append(h.listeners, listener)
+}
+
+// Slow subscribers miss events rather than blocking everyone else
+func (h *UserEventHub) Publish(event UserEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, listener := range h.listeners {
+		listener(event)
+	}
+	for ch := range h.subscribers[event.UserID] {
+		select {
+		case ch <- event:
+		default:
+			klog.Warningf("Dropping %s event for slow subscriber of %s", event.Type, event.UserID)
+		}
+	}
+}
+
+// Handle a NOTIFY payload from the database
+func (h *UserEventHub) PublishNotification(payload string) {
+	var event UserEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		klog.Errorf("Error parsing database notification %s: %v", payload, err)
+		return
+	}
+	h.Publish(event)
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+func TestUserEventHub(t *testing.T) {
+	hub := NewUserEventHub()
+	userID := uuid.New()
+	events, unsubscribe := hub.Subscribe(userID)
+
+	// Events of other users aren't delivered
+	hub.Publish(UserEvent{Type: "user_verified", UserID: uuid.New()})
+	hub.PublishNotification(fmt.Sprintf(`{"type": "payout_sent", "user_id": "%s", "block_hash": "ABC", "amount_raw": "100"}`, userID))
+	event := <-events
+	utils.AssertEqual(t, "payout_sent", event.Type)
+	utils.AssertEqual(t, "ABC", event.BlockHash)
+	utils.AssertEqual(t, 0, len(events))
+
+	unsubscribe()
+	hub.Publish(UserEvent{Type: "user_verified", UserID: userID})
+	utils.AssertEqual(t, 0, len(events))
+}
+
+func TestUserEventHubListener(t *testing.T) {
+	hub := NewUserEventHub()
+	var heard []string
+	hub.AddListener(func(event UserEvent) {
+		heard = append(heard, event.Type)
+	})
+	// Listeners hear every user, subscribed or not
+	hub.Publish(UserEvent{Type: "user_verified", UserID: uuid.New()})
+	hub.Publish(UserEvent{Type: "payout_sent", UserID: uuid.New()})
+	utils.AssertEqual(t, []string{"user_verified", "payout_sent"}, heard)
+}
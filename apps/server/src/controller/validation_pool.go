@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+)
+
+// A result waiting to be validated, the hub handles it again once it's done
+type validationJob struct {
+	message    ClientWSMessage
+	response   serializableModels.ClientWorkResponse
+	channel    *models.ActiveChannelObject
+	receivedAt time.Time
+
+	valid       bool
+	validatedAt time.Time
+}
+
+func (j *validationJob) validate() {
+	j.valid = resultValid(j.message.client, j.channel.Hash, j.channel.DifficultyMultiplier, j.response.Result)
+	j.validatedAt = time.Now()
+}
+
+// Validates results on a fixed number of goroutines, so the hub keeps dispatching while they're checked
+type ValidationPool struct {
+	jobs chan *validationJob
+	// Read by the hub's run loop
+	done chan *validationJob
+	// Results queued or being validated
+	backlog atomic.Int64
+}
+
+// Must be called before the hub runs, a hub without a pool validates on its run loop
+func NewValidationPool(hub *Hub, workers int, queueSize int) *ValidationPool {
+	pool := &ValidationPool{
+		jobs: make(chan *validationJob, queueSize),
+		done: make(chan *validationJob, queueSize+workers),
+	}
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+	hub.validator = pool
+	return pool
+}
+
+func (p *ValidationPool) work() {
+	for job := range p.jobs {
+		job.validate()
+		p.backlog.Add(-1)
+		p.done <- job
+	}
+}
+
+// False if the queue is full, the caller validates it then
+func (p *ValidationPool) submit(job *validationJob) bool {
+	p.backlog.Add(1)
+	select {
+	case p.jobs <- job:
+		return true
+	default:
+		p.backlog.Add(-1)
+		return false
+	}
+}
+
+// Results queued or being validated
+func (p *ValidationPool) Backlog() int {
+	return int(p.backlog.Load())
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestValidationPool(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	statsChan := make(chan repository.WorkMessage, 10)
+	hub := NewHub(&statsChan)
+	pool := NewValidationPool(hub, 2, 10)
+	previous := ActiveHub
+	ActiveHub = hub
+	defer func() { ActiveHub = previous }()
+	go hub.Run()
+	defer hub.Stop()
+
+	hash := "3F93C5CD2E314FA16702189041E68E68C07B27961BF37F0B7705145BEFBA3AA3"
+	respond := func(requestID string, result string) *models.ActiveChannelObject {
+		channel := &models.ActiveChannelObject{RequestID: requestID, TenantID: "default", Hash: hash, DifficultyMultiplier: 1, Chan: make(chan []byte, 1), RequestedAt: time.Now()}
+		ActiveChannels.Put(channel)
+		bytes, err := json.Marshal(serializableModels.ClientWorkResponse{RequestID: requestID, Hash: hash, Result: result})
+		utils.AssertEqual(t, nil, err)
+		hub.Response <- ClientWSMessage{ClientEmail: "worker@example.com", TenantID: "default", msg: bytes}
+		return channel
+	}
+
+	// Valid results reach the requester and are credited once they're validated
+	channel := respond("validate-1", "205452237a9b01f4")
+	defer ActiveChannels.Delete("validate-1")
+	select {
+	case <-channel.Chan:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the result wasn't sent to the requester")
+	}
+	utils.AssertEqual(t, "worker@example.com", (<-statsChan).ProvidedByEmail)
+	utils.AssertEqual(t, closedAnswered, ClosedRequests.Get("validate-1", time.Now()).reason)
+
+	// Invalid ones aren't
+	channel = respond("validate-2", "0000000000000000")
+	defer ActiveChannels.Delete("validate-2")
+	deadline := time.Now().Add(5 * time.Second)
+	for len(HubEvents.ForRequest("validate-2")) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	events := HubEvents.ForRequest("validate-2")
+	utils.AssertEqual(t, 1, len(events))
+	utils.AssertEqual(t, "invalid work", events[0].Detail)
+	utils.AssertEqual(t, 0, len(channel.Chan))
+	utils.AssertEqual(t, 0, len(statsChan))
+	utils.AssertEqual(t, 0, pool.Backlog())
+}
+
+func TestValidationPoolFull(t *testing.T) {
+	// No workers and no room, the hub validates on its run loop then
+	pool := NewValidationPool(NewHub(nil), 0, 0)
+	utils.AssertEqual(t, false, pool.submit(&validationJob{}))
+	utils.AssertEqual(t, 0, pool.Backlog())
+}
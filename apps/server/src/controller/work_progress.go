@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+)
+
+// Latest report of each worker on a work request
+type requestProgress struct {
+	workers map[*Client]serializableModels.WorkProgress
+	lastAt  time.Time
+}
+
+// Only clients of the tenant that requested the work can report progress on it, returns whether the report was kept
+func (h *Hub) recordProgress(message ClientWSMessage, response serializableModels.ClientWorkResponse, now time.Time) bool {
+	activeChannel := ActiveChannels.Get(response.RequestID)
+	if activeChannel == nil || activeChannel.TenantID != message.TenantID || message.client == nil {
+		logging.Debugf(logging.Hub, "Ignoring progress on %s from %s, it isn't open for them", response.RequestID, message.ClientEmail)
+		return false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	progress, ok := h.progress[response.RequestID]
+	if !ok {
+		progress = &requestProgress{workers: make(map[*Client]serializableModels.WorkProgress)}
+		h.progress[response.RequestID] = progress
+	}
+	progress.workers[message.client] = *response.Progress
+	progress.lastAt = now
+	return true
+}
+
+// When progress was last reported on the request, the iterations reported so far and by how many workers
+func (h *Hub) progressOf(requestID string) (time.Time, uint64, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	progress, ok := h.progress[requestID]
+	if !ok {
+		return time.Time{}, 0, 0
+	}
+	var iterations uint64
+	for _, reported := range progress.workers {
+		iterations += reported.Iterations
+	}
+	return progress.lastAt, iterations, len(progress.workers)
+}
+
+// How much longer to wait on a request that timed out, 0 if its workers stalled or it was extended by maxExtension already
+func progressExtension(lastProgress time.Time, extended time.Duration, maxExtension time.Duration, now time.Time) time.Duration {
+	if lastProgress.IsZero() || now.Sub(lastProgress) > config.WORK_PROGRESS_STALE_SECONDS*time.Second {
+		return 0
+	}
+	extension := config.WORK_PROGRESS_EXTENSION_SECONDS * time.Second
+	if remaining := maxExtension - extended; remaining < extension {
+		extension = remaining
+	}
+	if extension < 0 {
+		return 0
+	}
+	return extension
+}
+
+// Waits for a result of one attempt, extending the timeout while workers report they're still solving it
+// Nil if the attempt timed out
+func awaitResponse(channel *models.ActiveChannelObject, policy models.HubPolicy, attempt int) []byte {
+	timer := time.NewTimer(policy.Timeout())
+	defer timer.Stop()
+	extended := time.Duration(0)
+	for {
+		select {
+		case response := <-channel.Chan:
+			return response
+		case <-timer.C:
+			lastProgress, iterations, workers := ActiveHub.progressOf(channel.RequestID)
+			if extension := progressExtension(lastProgress, extended, policy.ProgressExtension(), time.Now()); extension > 0 {
+				extended += extension
+				logging.Infof(logging.Hub, "Work request %s is still being solved, waiting %s longer", channel.Hash, extension)
+				HubEvents.Record(models.HubEvent{Type: models.HubEventExtended, RequestID: channel.RequestID, Hash: channel.Hash, TenantID: channel.TenantID, DifficultyMultiplier: channel.DifficultyMultiplier, Detail: fmt.Sprintf("%d iterations reported by %d workers, extended by %s", iterations, workers, extension)})
+				timer.Reset(extension)
+				continue
+			}
+			waited := policy.Timeout() + extended
+			logging.Errorf(logging.Hub, "Work request timed out %s (attempt %d of %d)", channel.Hash, attempt+1, policy.Retries+1)
+			HubEvents.Record(models.HubEvent{Type: models.HubEventTimeout, RequestID: channel.RequestID, Hash: channel.Hash, TenantID: channel.TenantID, DifficultyMultiplier: channel.DifficultyMultiplier, Detail: fmt.Sprintf("no valid result after %s (attempt %d of %d)", waited, attempt+1, policy.Retries+1)})
+			return nil
+		}
+	}
+}
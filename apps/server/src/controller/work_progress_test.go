@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestRecordProgress(t *testing.T) {
+	hub := NewHub(nil)
+	first := &Client{IPAddress: "1.1.1.1", TenantID: "default"}
+	second := &Client{IPAddress: "2.2.2.2", TenantID: "default"}
+	outsider := &Client{IPAddress: "3.3.3.3", TenantID: "other"}
+	ActiveChannels.Put(&models.ActiveChannelObject{RequestID: "progress-1", TenantID: "default"})
+	defer ActiveChannels.Delete("progress-1")
+
+	now := time.Unix(1000, 0)
+	report := func(client *Client, iterations uint64, at time.Time) bool {
+		return hub.recordProgress(ClientWSMessage{TenantID: client.TenantID, client: client}, serializableModels.ClientWorkResponse{RequestID: "progress-1", Progress: &serializableModels.WorkProgress{Iterations: iterations, ElapsedMs: 5000}}, at)
+	}
+	utils.AssertEqual(t, true, report(first, 100, now))
+	utils.AssertEqual(t, true, report(second, 50, now.Add(time.Second)))
+	// Later reports replace earlier ones
+	utils.AssertEqual(t, true, report(first, 300, now.Add(2*time.Second)))
+	utils.AssertEqual(t, false, report(outsider, 1000, now.Add(3*time.Second)))
+
+	// Not open anymore
+	utils.AssertEqual(t, false, hub.recordProgress(ClientWSMessage{TenantID: "default", client: first}, serializableModels.ClientWorkResponse{RequestID: "progress-2", Progress: &serializableModels.WorkProgress{}}, now))
+
+	lastAt, iterations, workers := hub.progressOf("progress-1")
+	utils.AssertEqual(t, now.Add(2*time.Second), lastAt)
+	utils.AssertEqual(t, uint64(350), iterations)
+	utils.AssertEqual(t, 2, workers)
+
+	hub.Release("progress-1")
+	lastAt, _, workers = hub.progressOf("progress-1")
+	utils.AssertEqual(t, true, lastAt.IsZero())
+	utils.AssertEqual(t, 0, workers)
+}
+
+func TestProgressExtension(t *testing.T) {
+	now := time.Unix(1000, 0)
+	max := time.Minute
+	// No progress, or none lately
+	utils.AssertEqual(t, time.Duration(0), progressExtension(time.Time{}, 0, max, now))
+	utils.AssertEqual(t, time.Duration(0), progressExtension(now.Add(-time.Minute), 0, max, now))
+
+	utils.AssertEqual(t, 10*time.Second, progressExtension(now.Add(-time.Second), 0, max, now))
+	// Capped at the policy's extension
+	utils.AssertEqual(t, 5*time.Second, progressExtension(now.Add(-time.Second), 55*time.Second, max, now))
+	utils.AssertEqual(t, time.Duration(0), progressExtension(now.Add(-time.Second), max, max, now))
+	utils.AssertEqual(t, time.Duration(0), progressExtension(now, 0, 0, now))
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	"golang.org/x/exp/slices"
+)
+
+var rejectReasons = []string{serializableModels.RejectInvalidHash, serializableModels.RejectInvalidDifficulty, serializableModels.RejectMissingRequestID}
+
+// A frame is a hello, an acknowledgement, a rejection, a progress report or a result, anything else can't be acted on
+func validateWorkerFrame(response serializableModels.ClientWorkResponse) error {
+	switch {
+	case response.Hello != nil:
+		hello := response.Hello
+		if hello.ProtocolVersion < 1 {
+			return errors.New("hello without a protocol_version")
+		}
+		if hello.MinDifficulty < 0 || hello.MaxDifficulty < 0 || hello.MaxDifficulty > serializableModels.MaxSaneDifficultyMultiplier {
+			return errors.New("hello with an invalid difficulty range")
+		}
+		if hello.MaxDifficulty > 0 && hello.MinDifficulty > hello.MaxDifficulty {
+			return errors.New("hello with min_difficulty above max_difficulty")
+		}
+		return nil
+	case response.AckMessageID != "":
+		return nil
+	case response.Progress != nil:
+		if response.RequestID == "" {
+			return errors.New("progress without a request_id")
+		}
+		if response.Progress.ElapsedMs < 0 {
+			return errors.New("negative elapsed_ms")
+		}
+		return nil
+	case response.Rejected != "":
+		// Requests are rejected for having a bad hash, so it isn't checked
+		if !slices.Contains(rejectReasons, response.Rejected) {
+			return errors.New("unknown rejection reason")
+		}
+		if response.RequestID == "" && response.Rejected != serializableModels.RejectMissingRequestID {
+			return errors.New("rejection without a request_id")
+		}
+		return nil
+	}
+	if response.RequestID == "" {
+		return errors.New("result without a request_id")
+	}
+	if hash, err := hex.DecodeString(response.Hash); err != nil || len(hash) != 32 {
+		return errors.New("hash is not 64 hex characters")
+	}
+	if result, err := hex.DecodeString(response.Result); err != nil || len(result) != 8 {
+		return errors.New("result is not 16 hex characters")
+	}
+	return nil
+}
+
+func parseWorkerFrame(frame []byte) (serializableModels.ClientWorkResponse, error) {
+	var response serializableModels.ClientWorkResponse
+	if err := json.Unmarshal(frame, &response); err != nil {
+		return response, err
+	}
+	return response, validateWorkerFrame(response)
+}
+
+// Counts a frame that was thrown away, true if the worker is now quarantined and should be disconnected
+func strikeWorker(ip string, email string, tenantID string, reason string, err error) bool {
+	logging.Warningf(logging.Hub, "Dropped %s frame from %s (%s) %v", reason, email, ip, err)
+	if !Quarantine.Strike(ip, email, reason, time.Now()) {
+		return false
+	}
+	logging.Warningf(logging.Hub, "Quarantined %s (%s) for sending malformed frames", email, ip)
+	HubEvents.Record(models.HubEvent{Type: models.HubEventQuarantined, ClientIP: ip, ClientEmail: email, TenantID: tenantID, Detail: reason})
+	return true
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	"github.com/bananocoin/boompow/libs/utils/net"
+)
+
+// Takes results and heartbeats from clients whose websocket died mid-solve
+// Results go through the hub like the ones sent over the websocket, so they're validated and credited the same way
+func WorkerFallback(hub *Hub, w http.ResponseWriter, r *http.Request) {
+	provider := middleware.AuthorizedProvider(r.Context())
+	// Only PROVIDER type users can provide work
+	if provider == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("401 - Unauthorized"))
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte("405 - Method Not Allowed"))
+		return
+	}
+
+	clientIP := net.GetIPAddress(r)
+	if Quarantine.Quarantined(clientIP, time.Now()) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("403 - Quarantined"))
+		return
+	}
+
+	var request serializableModels.WorkerFallbackRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxMessageSize*config.WORKER_FALLBACK_MAX_RESULTS)).Decode(&request); err != nil {
+		reason := frameMalformed
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			reason = frameOversized
+		}
+		strikeWorker(clientIP, provider.User.Email, provider.User.TenantID, reason, err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("400 - Bad Request"))
+		return
+	}
+	if len(request.Results) > config.WORKER_FALLBACK_MAX_RESULTS {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf("400 - At most %d results at once", config.WORKER_FALLBACK_MAX_RESULTS)))
+		return
+	}
+
+	// The whole batch is refused, so clients notice they're sending something broken
+	for _, result := range request.Results {
+		if err := validateWorkerFrame(result); err != nil {
+			strikeWorker(clientIP, provider.User.Email, provider.User.TenantID, frameMalformed, err)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(fmt.Sprintf("400 - %v", err)))
+			return
+		}
+		if result.Hello != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - Hellos are only sent over the websocket"))
+			return
+		}
+	}
+
+	if err := database.GetRedisDB().RecordWorkerHeartbeat(provider.User.Email, time.Now()); err != nil {
+		logging.Errorf(logging.Hub, "Error recording heartbeat of %s %v", provider.User.Email, err)
+	}
+	for _, result := range request.Results {
+		msg, err := json.Marshal(result)
+		if err != nil {
+			continue
+		}
+		hub.Response <- ClientWSMessage{ClientEmail: provider.User.Email, TenantID: provider.User.TenantID, msg: msg}
+	}
+	if len(request.Results) > 0 {
+		logging.Infof(logging.Hub, "Received %d results from %s over HTTP", len(request.Results), provider.User.Email)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]int{"accepted": len(request.Results)})
+}
@@ -0,0 +1,18 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestWorkerFallbackRequiresProvider(t *testing.T) {
+	hub := NewHub(nil)
+	rec := httptest.NewRecorder()
+	WorkerFallback(hub, rec, httptest.NewRequest(http.MethodPost, "/worker/results", strings.NewReader(`{"results":[]}`)))
+	utils.AssertEqual(t, http.StatusUnauthorized, rec.Code)
+	utils.AssertEqual(t, 0, len(hub.Response))
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	"golang.org/x/exp/slices"
+)
+
+// Spoken by workers that don't say hello
+const legacyProtocolVersion = 1
+
+// What workers did before they said hello, everything but filtering work by difficulty themselves
+var legacyFeatures = []string{serializableModels.FeatureAck, serializableModels.FeatureReject, serializableModels.FeatureProgress, serializableModels.FeaturePreferServer}
+
+// The version and features both the worker and the hub speak, an error if the hub doesn't serve the worker's version anymore
+func negotiate(hello serializableModels.WorkerHello) (int, []string, error) {
+	if hello.ProtocolVersion < config.MIN_WORKER_PROTOCOL_VERSION {
+		return 0, nil, fmt.Errorf("protocol version %d isn't served anymore, upgrade to a client that speaks version %d to %d", hello.ProtocolVersion, config.MIN_WORKER_PROTOCOL_VERSION, serializableModels.ProtocolVersion)
+	}
+	version := hello.ProtocolVersion
+	if version > serializableModels.ProtocolVersion {
+		version = serializableModels.ProtocolVersion
+	}
+	features := []string{}
+	for _, feature := range serializableModels.ProtocolFeatures {
+		if slices.Contains(hello.Features, feature) {
+			features = append(features, feature)
+		}
+	}
+	return version, features, nil
+}
+
+// Guarded by the hub's mutex
+func (c *Client) protocolVersion() int {
+	if c.protocol == 0 {
+		return legacyProtocolVersion
+	}
+	return c.protocol
+}
+
+// Guarded by the hub's mutex
+func (c *Client) supports(feature string) bool {
+	if c.protocol == 0 {
+		return slices.Contains(legacyFeatures, feature)
+	}
+	return slices.Contains(c.features, feature)
+}
+
+// Clients are only sent what their protocol understands and work they asked for, guarded by the hub's mutex
+func (c *Client) accepts(message BroadcastMessage) bool {
+	if message.Feature != "" && !c.supports(message.Feature) {
+		return false
+	}
+	if message.Event != models.HubEventAssigned {
+		return true
+	}
+	if c.protocolVersion() < config.MIN_WORKER_PROTOCOL_VERSION {
+		return false
+	}
+	return !c.supports(serializableModels.FeatureDifficultyRange) || c.hello.Wants(message.DifficultyMultiplier, message.Precache)
+}
+
+// Answers the worker's hello, false if the hub doesn't serve its protocol version and it should be disconnected
+func (c *Client) greet(hello serializableModels.WorkerHello) bool {
+	version, features, err := negotiate(hello)
+	if err != nil {
+		c.refuse(err)
+		return false
+	}
+	c.Hub.mu.Lock()
+	c.protocol, c.features, c.hello = version, features, hello
+	c.Hub.mu.Unlock()
+
+	ack, err := json.Marshal(serializableModels.ClientMessage{MessageType: serializableModels.HelloAck, ProtocolVersion: version, Features: features})
+	if err != nil {
+		logging.Errorf(logging.Hub, "Error marshalling hello ack %v", err)
+		return true
+	}
+	if err := WriteChannelSafe(c.Send, ack); err != nil {
+		return false
+	}
+	HubEvents.Record(models.HubEvent{Type: models.HubEventHello, ClientIP: c.IPAddress, ClientEmail: c.Email, TenantID: c.TenantID, Detail: describeHello(version, features, hello)})
+	c.Hub.calibrate(c, time.Now())
+	return true
+}
+
+// Tells the worker why with the close frame, so it can stop reconnecting
+func (c *Client) refuse(reason error) {
+	logging.Warningf(logging.Hub, "Refusing worker %s (%s): %v", c.IPAddress, c.Email, reason)
+	HubEvents.Record(models.HubEvent{Type: models.HubEventHello, ClientIP: c.IPAddress, ClientEmail: c.Email, TenantID: c.TenantID, Detail: fmt.Sprintf("refused, %v", reason)})
+	c.disconnect(serializableModels.DisconnectProtocolRejected, reason.Error())
+}
+
+// e.g. "protocol 2, client 1.4.0, features ack progress, difficulty 1-64, gpus: RTX 3080, 8 cpu threads"
+func describeHello(version int, features []string, hello serializableModels.WorkerHello) string {
+	parts := []string{fmt.Sprintf("protocol %d", version)}
+	if hello.ClientVersion != "" {
+		parts = append(parts, "client "+hello.ClientVersion)
+	}
+	parts = append(parts, "features "+strings.Join(features, " "))
+	if hello.MinDifficulty > 0 || hello.MaxDifficulty > 0 {
+		parts = append(parts, fmt.Sprintf("difficulty %d-%d", hello.MinDifficulty, hello.MaxDifficulty))
+	}
+	if hello.SkipPrecache {
+		parts = append(parts, "no precache")
+	}
+	if len(hello.GPUs) > 0 {
+		parts = append(parts, "gpus: "+strings.Join(hello.GPUs, ", "))
+	}
+	if hello.CPUThreads > 0 {
+		parts = append(parts, fmt.Sprintf("%d cpu threads", hello.CPUThreads))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	version, features, err := negotiate(serializableModels.WorkerHello{ProtocolVersion: 2, Features: []string{serializableModels.FeatureProgress, "teleport", serializableModels.FeatureAck}})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 2, version)
+	// Unknown features are dropped, known ones keep the hub's order
+	utils.AssertEqual(t, []string{serializableModels.FeatureAck, serializableModels.FeatureProgress}, features)
+
+	// Newer workers speak the hub's version
+	version, _, err = negotiate(serializableModels.WorkerHello{ProtocolVersion: serializableModels.ProtocolVersion + 3})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, serializableModels.ProtocolVersion, version)
+
+	_, _, err = negotiate(serializableModels.WorkerHello{ProtocolVersion: 0})
+	utils.AssertNotEqual(t, nil, err)
+}
+
+func TestClientAccepts(t *testing.T) {
+	legacy := &Client{}
+	ranged := &Client{protocol: 2, features: []string{serializableModels.FeatureDifficultyRange}, hello: serializableModels.WorkerHello{MinDifficulty: 2, MaxDifficulty: 16, SkipPrecache: true}}
+	unranged := &Client{protocol: 2, hello: serializableModels.WorkerHello{MinDifficulty: 2, MaxDifficulty: 16}}
+
+	work := BroadcastMessage{Event: models.HubEventAssigned, DifficultyMultiplier: 64}
+	utils.AssertEqual(t, true, legacy.accepts(work))
+	utils.AssertEqual(t, false, ranged.accepts(work))
+	// Filters work itself
+	utils.AssertEqual(t, true, unranged.accepts(work))
+	utils.AssertEqual(t, true, ranged.accepts(BroadcastMessage{Event: models.HubEventAssigned, DifficultyMultiplier: 8}))
+	utils.AssertEqual(t, false, ranged.accepts(BroadcastMessage{Event: models.HubEventAssigned, DifficultyMultiplier: 8, Precache: true}))
+
+	preferServer := BroadcastMessage{Feature: serializableModels.FeaturePreferServer}
+	utils.AssertEqual(t, true, legacy.accepts(preferServer))
+	utils.AssertEqual(t, false, ranged.accepts(preferServer))
+	utils.AssertEqual(t, true, ranged.accepts(BroadcastMessage{}))
+}
@@ -2,13 +2,17 @@ package controller
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"time"
 
+	"github.com/bananocoin/boompow/apps/server/src/logging"
 	"github.com/bananocoin/boompow/apps/server/src/middleware"
-	"github.com/bananocoin/boompow/libs/utils/net"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	netutils "github.com/bananocoin/boompow/libs/utils/net"
 	"github.com/gorilla/websocket"
-	"k8s.io/klog/v2"
 )
 
 var (
@@ -18,7 +22,10 @@ var (
 
 type ClientWSMessage struct {
 	ClientEmail string `json:"email"`
+	TenantID    string `json:"tenant_id"`
 	msg         []byte
+	// The connection the message came from
+	client *Client
 }
 
 // readPump pumps messages from the websocket connection to the hub.
@@ -34,16 +41,54 @@ func (c *Client) readPump() {
 	c.Conn.SetReadLimit(MaxMessageSize)
 	c.Conn.SetReadDeadline(time.Now().Add(PongWait))
 	c.Conn.SetPongHandler(func(string) error { c.Conn.SetReadDeadline(time.Now().Add(PongWait)); return nil })
+	// Workers say hello first, those that don't speak the legacy protocol
+	greeted := false
 	for {
 		_, message, err := c.Conn.ReadMessage()
+		if errors.Is(err, websocket.ErrReadLimit) {
+			// The connection can't be read from anymore, it already sent the worker a close frame
+			strikeWorker(c.IPAddress, c.Email, c.TenantID, frameOversized, err)
+			break
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			c.disconnect(serializableModels.DisconnectIdleTimeout, fmt.Sprintf("no pong for %s", PongWait))
+			break
+		}
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				klog.Errorf("error: %v", err)
+				logging.Errorf(logging.Hub, "error: %v", err)
 			}
 			break
 		}
 		message = bytes.TrimSpace(bytes.Replace(message, newline, space, -1))
-		msgObj := ClientWSMessage{ClientEmail: c.Email, msg: message}
+		frame, err := parseWorkerFrame(message)
+		if err != nil {
+			if strikeWorker(c.IPAddress, c.Email, c.TenantID, frameMalformed, err) {
+				c.disconnect(serializableModels.DisconnectQuarantined, quarantineDetail)
+				break
+			}
+			continue
+		}
+		if frame.Hello != nil {
+			if greeted {
+				logging.Debugf(logging.Hub, "Ignoring repeated hello from %s", c.IPAddress)
+				continue
+			}
+			greeted = true
+			if !c.greet(*frame.Hello) {
+				break
+			}
+			continue
+		}
+		if !greeted {
+			greeted = true
+			if _, _, err := negotiate(serializableModels.WorkerHello{ProtocolVersion: legacyProtocolVersion}); err != nil {
+				c.refuse(err)
+				break
+			}
+		}
+		msgObj := ClientWSMessage{ClientEmail: c.Email, TenantID: c.TenantID, msg: message, client: c}
 		c.Hub.Response <- msgObj
 	}
 }
@@ -65,7 +110,7 @@ func (c *Client) writePump() {
 			c.Conn.SetWriteDeadline(time.Now().Add(WriteWait))
 			if !ok {
 				// The hub closed the channel.
-				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				c.Conn.WriteMessage(websocket.CloseMessage, c.closeFrame)
 				return
 			}
 
@@ -92,33 +137,36 @@ func WorkerChl(hub *Hub, w http.ResponseWriter, r *http.Request) {
 	provider := middleware.AuthorizedProvider(r.Context())
 	// Only PROVIDER type users can provide work
 	if provider == nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		w.Write([]byte("401 - Unauthorized"))
+		refuseWorker(w, r, serializableModels.DisconnectAuthFailed, "log in again", http.StatusUnauthorized, "401 - Unauthorized")
 		return
 	}
 
-	clientIP := net.GetIPAddress(r)
+	clientIP := netutils.GetIPAddress(r)
 
 	// Block hetzner datacenters
-	if net.IsIPInHetznerRange(clientIP) {
-		w.WriteHeader(http.StatusForbidden)
-		w.Write([]byte("403 - Forbidden"))
+	if netutils.IsIPInHetznerRange(clientIP) {
+		refuseWorker(w, r, serializableModels.DisconnectBlockedNetwork, "", http.StatusForbidden, "403 - Forbidden")
+		return
+	}
+
+	// Block workers that kept sending malformed frames, until their quarantine is over
+	if Quarantine.Quarantined(clientIP, time.Now()) {
+		refuseWorker(w, r, serializableModels.DisconnectQuarantined, quarantineDetail, http.StatusForbidden, "403 - Quarantined")
 		return
 	}
 
 	// Block IPs already connected
 	if hub.AlreadyConnected(clientIP) {
-		w.WriteHeader(http.StatusForbidden)
-		w.Write([]byte("403 - Forbidden"))
+		refuseWorker(w, r, serializableModels.DisconnectAlreadyConnected, "", http.StatusForbidden, "403 - Forbidden")
 		return
 	}
 
 	conn, err := Upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		klog.Error(err)
+		logging.Errorf(logging.Hub, "%v", err)
 		return
 	}
-	client := &Client{Hub: hub, Conn: conn, Send: make(chan []byte, 256), IPAddress: clientIP, Email: provider.User.Email}
+	client := &Client{Hub: hub, Conn: conn, Send: make(chan []byte, 256), IPAddress: clientIP, Email: provider.User.Email, TenantID: provider.User.TenantID}
 	client.Hub.Register <- client
 
 	// Allow collection of memory referenced by the caller by doing all work in
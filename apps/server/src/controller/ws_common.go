@@ -4,22 +4,29 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/crosscheck"
 	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/metrics"
 	"github.com/bananocoin/boompow/apps/server/src/models"
 	"github.com/bananocoin/boompow/apps/server/src/repository"
 	serializableModels "github.com/bananocoin/boompow/libs/models"
 	"github.com/bananocoin/boompow/libs/utils"
-	"github.com/bananocoin/boompow/libs/utils/validation"
 	"github.com/gorilla/websocket"
 	"golang.org/x/exp/slices"
-	"k8s.io/klog/v2"
 )
 
 var ActiveHub *Hub
 
+// Cross-checks a sample of results with validation peers, nil if none are configured
+var CrossCheck *crosscheck.Checker
+
 const (
 	// Time allowed to write a message to the peer.
 	WriteWait = 10 * time.Second
@@ -48,10 +55,76 @@ type Client struct {
 	IPAddress string
 
 	Email string
+
+	// The pool this client works for, it only receives work requested within the same tenant
+	TenantID string
+
+	// Work requests sent to this client that haven't been solved or timed out yet, guarded by the hub's mutex
+	inFlight int
+
+	// When the client was last sent a work request other than an idle precache task, or connected, guarded by the hub's mutex
+	lastWorkAt time.Time
+
+	// Set when the hub registers the client, guarded by the hub's mutex
+	connectedAt time.Time
+
+	// Negotiated in the client's hello, zero for clients that didn't send one, guarded by the hub's mutex
+	protocol int
+	features []string
+	hello    serializableModels.WorkerHello
+
+	// How long the client took for its valid results, guarded by the hub's mutex
+	solveTimes solveTimes
+
+	// Calibration requests the client still has to solve before it's counted on for work, and until when, guarded by the hub's mutex
+	calibrationsLeft    int
+	calibrationDeadline time.Time
+
+	// Sent by the write pump once the hub closes Send, set with setDisconnectReason before it's closed
+	closeFrame []byte
+	// Recorded with the disconnect, empty if the worker went away by itself
+	disconnectReason string
+
+	// Simulated workers have no connection, see Simulation
+	simulated bool
+}
+
+// Idle clients had no work for idleFor and aren't working on anything
+func (c *Client) idle(idleFor time.Duration, now time.Time) bool {
+	return c.inFlight == 0 && now.Sub(c.lastWorkAt) >= idleFor
+}
+
+// A message for every client of a tenant
+type BroadcastMessage struct {
+	TenantID string
+	Msg      []byte
+	// Recorded in the event log once the message went out
+	Event                models.HubEventType
+	RequestID            string
+	Hash                 string
+	DifficultyMultiplier int
+	// Work requests only, precache requests get fewer in-flight slots
+	Precache bool
+	// Idle precache tasks only go to clients that had no work for this long
+	IdleFor time.Duration
+	// Only sent to clients that negotiated this feature
+	Feature string
+	// Messages that pile up go out by priority, work requests set it with workPriority
+	Priority Priority
+	// Work requests only, retries of targeted requests go to every client
+	Attempt int
+	// Replica the message came from in distributed mode, empty for this replica's own
+	Origin string
 }
 
 var Upgrader = websocket.Upgrader{}
 
+type stopRequest struct {
+	reason serializableModels.DisconnectReason
+	detail string
+	done   chan struct{}
+}
+
 // Hub maintains the set of active clients and broadcasts messages to the
 // clients.
 type Hub struct {
@@ -59,7 +132,7 @@ type Hub struct {
 	Clients map[*Client]bool
 
 	// Outbound messages to the client
-	Broadcast chan []byte
+	Broadcast chan BroadcastMessage
 
 	// Inbound messages from client
 	Response chan ClientWSMessage
@@ -73,44 +146,408 @@ type Hub struct {
 	// Channel to broadcast stats to
 	StatsChan *chan repository.WorkMessage
 
+	// Clients each work request was sent to, to free their in-flight slots once it's done
+	assigned map[string][]*Client
+
+	// Progress the assigned clients reported on each work request
+	progress map[string]*requestProgress
+
+	// When each work request first went out to each client, to time their solves
+	sentAt map[string]map[*Client]time.Time
+
+	// Calibration requests sent to workers that just connected, by request ID
+	calibrations map[string]calibration
+
+	// Broadcasts waiting to be sent, most urgent first
+	queue *dispatchQueue
+
+	// Stops the run loop, which closes the request's done channel once every client is closed
+	stop chan stopRequest
+
+	// Shares the hub with the other replicas, nil in local mode
+	cluster *Cluster
+
+	// Validates results off the run loop, nil validates them on it
+	validator *ValidationPool
+	// Results handed to the validator that the run loop didn't handle yet, only used by the run loop
+	validating int
+
 	mu sync.Mutex
 }
 
+// In distributed mode clients connected to other replicas count as well
 func (h *Hub) AlreadyConnected(ip string) bool {
 	h.mu.Lock()
-	defer h.mu.Unlock()
 	for c := range h.Clients {
 		if c.IPAddress == ip {
+			h.mu.Unlock()
 			return true
 		}
 	}
-	return false
+	h.mu.Unlock()
+	return h.cluster != nil && h.cluster.connectedElsewhere(ip)
+}
+
+// Closes the connections of a user's workers with reason, their read pumps unregister them
+// In distributed mode the other replicas close theirs too, only this replica's are counted
+func (h *Hub) DisconnectUser(email string, reason serializableModels.DisconnectReason) int {
+	if h.cluster != nil {
+		h.cluster.shareDisconnect(email, reason)
+	}
+	return h.disconnectUser(email, reason)
+}
+
+func (h *Hub) disconnectUser(email string, reason serializableModels.DisconnectReason) int {
+	h.mu.Lock()
+	disconnected := 0
+	connected := []*Client{}
+	for c := range h.Clients {
+		if strings.EqualFold(c.Email, email) {
+			c.setDisconnectReason(reason, "")
+			if c.simulated {
+				h.remove(c)
+			} else {
+				connected = append(connected, c)
+			}
+			disconnected++
+		}
+	}
+	h.mu.Unlock()
+	// Sending the close frame takes the hub's mutex
+	for _, c := range connected {
+		c.closeConn()
+	}
+	return disconnected
+}
+
+// Tenants with at least one client that had no work for idleFor
+func (h *Hub) IdleTenants(idleFor time.Duration, now time.Time) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	tenants := []string{}
+	for c := range h.Clients {
+		if c.idle(idleFor, now) && !slices.Contains(tenants, c.TenantID) {
+			tenants = append(tenants, c.TenantID)
+		}
+	}
+	return tenants
+}
+
+// What the hub is holding at the moment
+type HubSnapshot struct {
+	Workers int
+	// Work requests waiting on a result
+	OpenRequests int
+	// Messages waiting to be sent to workers by priority, and results waiting to be counted in the stats
+	BroadcastQueue map[Priority]int
+	StatsQueue     int
+	// Results waiting to be validated
+	ValidationBacklog int
+}
+
+func (h *Hub) Snapshot() HubSnapshot {
+	h.mu.Lock()
+	workers := len(h.Clients)
+	h.mu.Unlock()
+	snapshot := HubSnapshot{
+		Workers:        workers,
+		OpenRequests:   ActiveChannels.Len(),
+		BroadcastQueue: h.queue.Depths(),
+	}
+	if h.StatsChan != nil {
+		snapshot.StatsQueue = len(*h.StatsChan)
+	}
+	if h.validator != nil {
+		snapshot.ValidationBacklog = h.validator.Backlog()
+	}
+	return snapshot
+}
+
+// IPs of the connected clients
+func (h *Hub) ConnectedIPs() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ips := make([]string, 0, len(h.Clients))
+	for c := range h.Clients {
+		ips = append(ips, c.IPAddress)
+	}
+	return ips
+}
+
+// IPs of the clients connected for tenantID
+func (h *Hub) TenantIPs(tenantID string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ips := []string{}
+	for c := range h.Clients {
+		if c.TenantID == tenantID {
+			ips = append(ips, c.IPAddress)
+		}
+	}
+	return ips
+}
+
+// Rebuild the connected clients in redis from the actual connections, in case redis lost them or kept stale ones
+// In distributed mode only this replica's clients and those of replicas that are gone are replaced
+// Returns the number of connected clients
+func (h *Hub) ReconcileConnectedClients() (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	clients := make(map[string]string, len(h.Clients))
+	for c := range h.Clients {
+		clients[c.IPAddress] = c.TenantID
+	}
+	replace := database.GetRedisDB().ReplaceConnectedClients
+	if h.cluster != nil {
+		replace = func(clients map[string]string) error {
+			return database.GetRedisDB().ReplaceReplicaClients(h.cluster.ReplicaID, clients)
+		}
+	}
+	if err := replace(clients); err != nil {
+		return 0, err
+	}
+	return len(clients), nil
 }
 
 func NewHub(statsChan *chan repository.WorkMessage) *Hub {
 	return &Hub{
-		Broadcast:  make(chan []byte, 100),
-		Response:   make(chan ClientWSMessage),
-		Register:   make(chan *Client),
-		Unregister: make(chan *Client),
-		Clients:    make(map[*Client]bool),
-		StatsChan:  statsChan,
+		Broadcast:    make(chan BroadcastMessage, 100),
+		Response:     make(chan ClientWSMessage),
+		Register:     make(chan *Client),
+		Unregister:   make(chan *Client),
+		Clients:      make(map[*Client]bool),
+		StatsChan:    statsChan,
+		assigned:     make(map[string][]*Client),
+		progress:     make(map[string]*requestProgress),
+		queue:        newDispatchQueue(config.BROADCAST_QUEUE_SIZE),
+		sentAt:       make(map[string]map[*Client]time.Time),
+		calibrations: make(map[string]calibration),
+		stop:         make(chan stopRequest),
+	}
+}
+
+// Closes every worker connection with a server shutdown frame and stops the run loop, results that arrive afterwards are dropped
+// The stats channel has no producers left once it returns, so it can be closed
+func (h *Hub) Stop() {
+	h.StopFor(serializableModels.DisconnectShutdown, "")
+}
+
+// Same as Stop, with the reason workers are told
+func (h *Hub) StopFor(reason serializableModels.DisconnectReason, detail string) {
+	done := make(chan struct{})
+	h.stop <- stopRequest{reason: reason, detail: detail, done: done}
+	<-done
+}
+
+// Must hold the hub's mutex
+func (h *Hub) remove(client *Client) {
+	if _, ok := h.Clients[client]; !ok {
+		return
+	}
+	delete(h.Clients, client)
+	close(client.Send)
+	h.forgetCalibrations(client)
+	// Keep global state of connected clients
+	if h.cluster != nil {
+		database.GetRedisDB().RemoveReplicaClient(client.IPAddress, h.cluster.ReplicaID)
+	} else {
+		database.GetRedisDB().RemoveConnectedClient(client.IPAddress)
+	}
+	HubEvents.Record(models.HubEvent{Type: models.HubEventDisconnect, ClientIP: client.IPAddress, ClientEmail: client.Email, TenantID: client.TenantID, Detail: client.disconnectReason})
+}
+
+// Workers reconnect to another instance or once this one is back
+func (h *Hub) closeClients(reason serializableModels.DisconnectReason, detail string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	closed := 0
+	for client := range h.Clients {
+		client.setDisconnectReason(reason, detail)
+		h.remove(client)
+		closed++
+	}
+	return closed
+}
+
+// Frees the in-flight slots of the clients a work request was sent to and drops its queued broadcasts, on every replica in distributed mode
+func (h *Hub) Release(requestID string) {
+	if h.cluster != nil {
+		h.cluster.shareRelease(requestID)
+	}
+	h.release(requestID)
+}
+
+func (h *Hub) release(requestID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, client := range h.assigned[requestID] {
+		client.inFlight--
+	}
+	delete(h.assigned, requestID)
+	delete(h.progress, requestID)
+	delete(h.sentAt, requestID)
+	h.queue.RemoveRequest(requestID)
+}
+
+// Credits the provider of a valid result towards their stats and payouts
+func (h *Hub) credit(activeChannel *models.ActiveChannelObject, providerEmail string, result string) {
+	// Except for some services people can abuse, like BananoVault
+	blockAward := activeChannel.BlockAward
+	if slices.Contains(utils.GetBannedRewards(), activeChannel.RequesterEmail) {
+		blockAward = false
+	}
+	*h.StatsChan <- repository.WorkMessage{
+		BlockAward:           blockAward,
+		ProvidedByEmail:      providerEmail,
+		RequestedByEmail:     activeChannel.RequesterEmail,
+		Hash:                 activeChannel.Hash,
+		Result:               result,
+		DifficultyMultiplier: activeChannel.DifficultyMultiplier,
+		Precache:             activeChannel.Precache,
+		SolveLatencyMs:       time.Since(activeChannel.RequestedAt).Milliseconds(),
+		TenantID:             activeChannel.TenantID,
+		CreditPercent:        activeChannel.CreditPercent,
+	}
+}
+
+// Results for requests that were already answered or timed out never reach the requester
+// Within the policy's grace period valid ones are still credited, later ones aren't even validated, so slow but honest workers don't look like they sent invalid work
+// valid is nil unless the result was already validated
+func (h *Hub) lateResult(message ClientWSMessage, response serializableModels.ClientWorkResponse, now time.Time, valid *bool) {
+	closed := ClosedRequests.Get(response.RequestID, now)
+	if closed == nil || closed.channel.TenantID != message.TenantID {
+		logging.Debugf(logging.Hub, "Received work response for hash %s, but no channel exists", response.Hash)
+		return
+	}
+	channel := closed.channel
+	after := now.Sub(closed.closedAt).Round(time.Millisecond)
+	policy := Policy()
+	grace := policy.LateResultGrace()
+	event := models.HubEvent{Type: models.HubEventLate, RequestID: channel.RequestID, Hash: channel.Hash, ClientEmail: message.ClientEmail, TenantID: channel.TenantID, DifficultyMultiplier: channel.DifficultyMultiplier}
+	if message.client != nil {
+		event.ClientIP = message.client.IPAddress
+	}
+	switch {
+	case after > grace:
+		event.Detail = fmt.Sprintf("%s after it was %s, past the %s grace period, not credited", after, closed.reason, grace)
+	case valid != nil && !*valid, valid == nil && !resultValid(message.client, channel.Hash, channel.DifficultyMultiplier, response.Result):
+		event.Detail = fmt.Sprintf("%s after it was %s, invalid work, not credited", after, closed.reason)
+	case !ClosedRequests.MarkCredited(channel.RequestID, message.ClientEmail):
+		event.Detail = fmt.Sprintf("%s after it was %s, already credited", after, closed.reason)
+	default:
+		event.Detail = fmt.Sprintf("%s after it was %s, within the %s grace period, credited", after, closed.reason, grace)
+		h.credit(&channel, message.ClientEmail, response.Result)
+	}
+	logging.Debugf(logging.Hub, "Late result for %s from %s: %s", channel.Hash, message.ClientEmail, event.Detail)
+	HubEvents.Record(event)
+}
+
+// Hands the result to the validator, or validates it right away without one or when its queue is full
+func (h *Hub) validate(job *validationJob) {
+	if h.validator != nil && h.validator.submit(job) {
+		h.validating++
+		return
+	}
+	job.validate()
+	h.resultValidated(job)
+}
+
+// Requests that were answered or timed out while the result was validated treat it as a late result
+func (h *Hub) resultValidated(job *validationJob) {
+	activeChannel, message, workResponse, receivedAt, valid := job.channel, job.message, job.response, job.receivedAt, job.valid
+	if ActiveChannels.Get(workResponse.RequestID) != activeChannel || ClosedRequests.Get(workResponse.RequestID, time.Now()) != nil {
+		h.lateResult(message, workResponse, receivedAt, &valid)
+		return
+	}
+	activeChannel.ValidationTime += job.validatedAt.Sub(receivedAt)
+	if CrossCheck != nil {
+
+		CrossCheck.Sample(activeChannel.Hash, activeChannel.DifficultyMultiplier, workResponse.Result, valid)
 	}
+	if !valid {
+		logging.Errorf(logging.Hub, "Received invalid work for %s", activeChannel.Hash)
+		HubEvents.Record(models.HubEvent{Type: models.HubEventResult, RequestID: activeChannel.RequestID, Hash: activeChannel.Hash, ClientEmail: message.ClientEmail, TenantID: activeChannel.TenantID, DifficultyMultiplier: activeChannel.DifficultyMultiplier, Detail: "invalid work"})
+		// ! TODO - penalize this bad client
+		return
+	}
+	activeChannel.ResultAt = receivedAt
+	h.recordSolve(message.client, activeChannel.RequestID, activeChannel.DifficultyMultiplier, receivedAt)
+	ClosedRequests.Close(activeChannel, closedAnswered, receivedAt)
+	ClosedRequests.MarkCredited(activeChannel.RequestID, message.ClientEmail)
+	HubEvents.Record(models.HubEvent{Type: models.HubEventResult, RequestID: activeChannel.RequestID, Hash: activeChannel.Hash, ClientEmail: message.ClientEmail, TenantID: activeChannel.TenantID, DifficultyMultiplier: activeChannel.DifficultyMultiplier})
+	// Send work cancel command to all clients
+	workCancel := &serializableModels.ClientMessage{
+		MessageType: serializableModels.WorkCancel,
+		Hash:        activeChannel.Hash,
+	}
+	bytes, err := json.Marshal(workCancel)
+	if err != nil {
+		logging.Errorf(logging.Hub, "Failed to marshal work cancel command: %v", err)
+	} else {
+		ActiveHub.Broadcast <- BroadcastMessage{TenantID: activeChannel.TenantID, Msg: bytes, Event: models.HubEventCancel, RequestID: activeChannel.RequestID, Hash: activeChannel.Hash}
+	}
+	h.credit(activeChannel, message.ClientEmail, workResponse.Result)
+	WriteChannelSafe(activeChannel.Chan, message.msg)
+}
+
+// Frees the slot of a client that rejected a malformed work request, other clients keep working on it
+func (h *Hub) reject(message ClientWSMessage, response serializableModels.ClientWorkResponse) {
+	h.mu.Lock()
+	clients := h.assigned[response.RequestID]
+	for i, client := range clients {
+		if client == message.client {
+			client.inFlight--
+			clients = append(clients[:i], clients[i+1:]...)
+			break
+		}
+	}
+	if len(clients) == 0 {
+		delete(h.assigned, response.RequestID)
+	} else {
+		h.assigned[response.RequestID] = clients
+	}
+	h.mu.Unlock()
+
+	detail := fmt.Sprintf("%s, rejected by this client so far: %s", response.Rejected, formatRejectedCounts(response.RejectedCounts))
+	logging.Warningf(logging.Hub, "Client %s rejected work request %s: %s", message.ClientEmail, response.RequestID, detail)
+	event := models.HubEvent{Type: models.HubEventRejected, RequestID: response.RequestID, Hash: response.Hash, ClientEmail: message.ClientEmail, TenantID: message.TenantID, Detail: detail}
+	if message.client != nil {
+		event.ClientIP = message.client.IPAddress
+	}
+	HubEvents.Record(event)
+}
+
+// Sorted by reason, e.g. "invalid_difficulty=1 invalid_hash=3"
+func formatRejectedCounts(counts map[string]int) string {
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%s=%d", reason, counts[reason])
+	}
+	return strings.Join(parts, " ")
 }
 
+// Block awarded messages are kept in redis until a client of the provider acknowledges them, so they survive disconnects
 func (h *Hub) BlockAwardedWorker(blockAwardedChan <-chan serializableModels.ClientMessage) {
 	for ba := range blockAwardedChan {
+		bytes, err := json.Marshal(ba)
+		if err != nil {
+			logging.Errorf(logging.Hub, "Error marshalling block awarded message %s", err)
+			continue
+		}
+		if err := database.GetRedisDB().AddPendingAward(ba.ProviderEmail, ba.MessageID, string(bytes)); err != nil {
+			logging.Errorf(logging.Hub, "Error storing block awarded message for %s, it won't be redelivered %v", ba.ProviderEmail, err)
+		}
 		func() {
 			h.mu.Lock()
 			defer h.mu.Unlock()
 			for c := range h.Clients {
 				if c.Email == ba.ProviderEmail {
-					bytes, err := json.Marshal(ba)
-					if err != nil {
-						klog.Errorf("Error marshalling block awarded message %s", err)
-						break
-					}
-					fmt.Printf("Awarding to %s", c.IPAddress)
+					logging.Debugf(logging.Hub, "Awarding to %s", c.IPAddress)
 					database.GetRedisDB().UpdateClientScore(c.IPAddress, int(ba.DifficultyMultiplier))
 					WriteChannelSafe(c.Send, bytes)
 				}
@@ -119,27 +556,63 @@ func (h *Hub) BlockAwardedWorker(blockAwardedChan <-chan serializableModels.Clie
 	}
 }
 
+// Sends the block awarded messages the client's provider hasn't acknowledged yet
+func (h *Hub) redeliverPendingAwards(client *Client) {
+	pending, err := database.GetRedisDB().GetPendingAwards(client.Email)
+	if err != nil {
+		logging.Errorf(logging.Hub, "Error getting pending awards for %s %v", client.Email, err)
+		return
+	}
+	for _, msg := range pending {
+		if err := WriteChannelSafe(client.Send, []byte(msg)); err != nil {
+			// Disconnected again, they'll be sent on the next connect
+			return
+		}
+	}
+}
+
 func (h *Hub) Run() {
+	var validatorDone <-chan *validationJob
+	if h.validator != nil {
+		validatorDone = h.validator.done
+	}
 	for {
+		var dispatch <-chan struct{}
+		if h.queue.Len() > 0 {
+			dispatch = dispatchReady
+		}
 		select {
+		case stop := <-h.stop:
+			// Connections still being read from block on the hub until the process exits
+			logging.Infof(logging.Hub, "Closed %d worker connections", h.closeClients(stop.reason, stop.detail))
+			// Results that are being validated are still credited
+			for h.validating > 0 {
+				h.validating--
+				h.resultValidated(<-h.validator.done)
+			}
+			close(stop.done)
+			return
 		case client := <-h.Register:
 			func() {
 				h.mu.Lock()
 				defer h.mu.Unlock()
 				h.Clients[client] = true
+				// New clients aren't idle until they had no work for a while
+				client.connectedAt = time.Now()
+				client.lastWorkAt = client.connectedAt
 				// Keep global state of connected clients
-				database.GetRedisDB().AddConnectedClient(client.IPAddress)
+				database.GetRedisDB().AddConnectedClient(client.IPAddress, client.TenantID)
+				if h.cluster != nil {
+					database.GetRedisDB().SetClientReplica(client.IPAddress, h.cluster.ReplicaID)
+				}
+				HubEvents.Record(models.HubEvent{Type: models.HubEventConnect, ClientIP: client.IPAddress, ClientEmail: client.Email, TenantID: client.TenantID})
+				go h.redeliverPendingAwards(client)
 			}()
 		case client := <-h.Unregister:
 			func() {
 				h.mu.Lock()
 				defer h.mu.Unlock()
-				if _, ok := h.Clients[client]; ok {
-					delete(h.Clients, client)
-					close(client.Send)
-					// Keep global state of connected clients
-					database.GetRedisDB().RemoveConnectedClient(client.IPAddress)
-				}
+				h.remove(client)
 			}()
 		case message := <-h.Response:
 			// Try to unmarshal as ClientWorkResponse
@@ -147,75 +620,160 @@ func (h *Hub) Run() {
 			err := json.Unmarshal(message.msg, &workResponse)
 			// Error de-serializing
 			if err != nil {
-				klog.Errorf("Error unmarshalling work response: %s", err)
+				logging.Errorf(logging.Hub, "Error unmarshalling work response: %s", err)
+				continue
+			}
+			// Acknowledgements only ever remove the provider's own messages
+			if workResponse.AckMessageID != "" {
+				if err := database.GetRedisDB().AckPendingAward(message.ClientEmail, workResponse.AckMessageID); err != nil {
+					logging.Errorf(logging.Hub, "Error acknowledging message %s %v", workResponse.AckMessageID, err)
+				}
+				continue
+			}
+			if h.calibrationResult(message, workResponse, time.Now()) {
+				continue
+			}
+			if workResponse.Rejected != "" {
+				h.reject(message, workResponse)
+				continue
+			}
+			if h.cluster != nil && ActiveChannels.Get(workResponse.RequestID) == nil && h.cluster.forwardResult(message, workResponse, time.Now()) {
+				continue
+			}
+			if workResponse.Progress != nil {
+				h.recordProgress(message, workResponse, time.Now())
 				continue
 			}
 			// If this channel exists, send response
 			activeChannel := ActiveChannels.Get(workResponse.RequestID)
-			if activeChannel != nil {
-				// Validate this work
-				if !validation.IsWorkValid(activeChannel.Hash, activeChannel.DifficultyMultiplier, workResponse.Result) {
-					klog.Errorf("Received invalid work for %s", activeChannel.Hash)
-					// ! TODO - penalize this bad client
-					continue
-				}
-				// Send work cancel command to all clients
-				workCancel := &serializableModels.ClientMessage{
-					MessageType: serializableModels.WorkCancel,
-					Hash:        activeChannel.Hash,
-				}
-				bytes, err := json.Marshal(workCancel)
-				if err != nil {
-					klog.Errorf("Failed to marshal work cancel command: %v", err)
-				} else {
-					ActiveHub.Broadcast <- bytes
-				}
-				// Credit this client for this work
-				// Except for some services people can abuse, like BananoVault
-				if slices.Contains(utils.GetBannedRewards(), activeChannel.RequesterEmail) {
-					activeChannel.BlockAward = false
-				}
-				statsMessage := repository.WorkMessage{
-					BlockAward:           activeChannel.BlockAward,
-					ProvidedByEmail:      message.ClientEmail,
-					RequestedByEmail:     activeChannel.RequesterEmail,
-					Hash:                 activeChannel.Hash,
-					Result:               workResponse.Result,
-					DifficultyMultiplier: activeChannel.DifficultyMultiplier,
-					Precache:             activeChannel.Precache,
-				}
-				*h.StatsChan <- statsMessage
-				WriteChannelSafe(activeChannel.Chan, message.msg)
-			} else {
-				klog.V(3).Infof("Received work response for hash %s, but no channel exists", workResponse.Hash)
+			if activeChannel != nil && activeChannel.TenantID != message.TenantID {
+				logging.Errorf(logging.Hub, "Received work response for %s from a client of tenant %s, but it was requested by tenant %s", activeChannel.Hash, message.TenantID, activeChannel.TenantID)
+				continue
+			}
+			receivedAt := time.Now()
+			if activeChannel == nil || ClosedRequests.Get(workResponse.RequestID, receivedAt) != nil {
+				h.lateResult(message, workResponse, receivedAt, nil)
+				continue
 			}
+			h.validate(&validationJob{message: message, response: workResponse, channel: activeChannel, receivedAt: receivedAt})
+		case job := <-validatorDone:
+			h.validating--
+			h.resultValidated(job)
 		case message := <-h.Broadcast:
-			func() {
-				h.mu.Lock()
-				defer h.mu.Unlock()
-				toExclude, err := database.GetRedisDB().FilterOverperformingClients()
-				if err != nil {
-					klog.Errorf("Error filtering overperforming clients: %v", err)
-					toExclude = []string{}
-				}
-				if len(h.Clients) < 5 {
-					toExclude = []string{}
-					klog.V(3).Infof("Not enough clients to exclude any")
-				}
-				for client := range h.Clients {
-					if len(toExclude) > 0 && slices.Contains(toExclude, client.IPAddress) {
-						continue
-					}
-					select {
-					case client.Send <- message:
-					default:
-						close(client.Send)
-						delete(h.Clients, client)
-					}
+			h.enqueue(message)
+		case <-dispatch:
+			// Broadcasts that arrived meanwhile may be more urgent
+			h.drainBroadcasts()
+			if message, ok := h.queue.Pop(); ok {
+				h.broadcast(message)
+			}
+		}
+	}
+}
+
+// Always ready, so the hub sends a queued broadcast whenever it isn't busy otherwise
+var dispatchReady = func() chan struct{} {
+	ready := make(chan struct{})
+	close(ready)
+	return ready
+}()
+
+// Broadcasts go out to the other replicas right away, each replica queues them for its own clients
+func (h *Hub) enqueue(message BroadcastMessage) {
+	if h.cluster != nil {
+		h.cluster.shareBroadcast(message)
+	}
+	if dropped, ok := h.queue.Push(message); ok {
+		metrics.CountDroppedBroadcast(dropped.Priority.String())
+	}
+}
+
+func (h *Hub) drainBroadcasts() {
+	for {
+		select {
+		case message := <-h.Broadcast:
+			h.enqueue(message)
+		default:
+			return
+		}
+	}
+}
+
+func (h *Hub) broadcast(message BroadcastMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	policy := Policy()
+	toExclude, err := database.GetRedisDB().FilterOverperformingClients(float64(policy.ExclusionSharePercent) / 100)
+	if err != nil {
+		logging.Errorf(logging.Hub, "Error filtering overperforming clients: %v", err)
+		toExclude = []string{}
+	}
+	if len(h.Clients) < policy.ExclusionMinClients {
+		toExclude = []string{}
+		logging.Debugf(logging.Hub, "Not enough clients to exclude any")
+	}
+	// Only work requests take up a slot, cancels and other messages go to everyone
+	slots := 0
+	idlePrecache := message.Event == models.HubEventAssigned && message.IdleFor > 0
+	if message.Event == models.HubEventAssigned {
+		slots = policy.InFlightSlots(message.Precache)
+	}
+	if idlePrecache {
+		// Tracked even without an in-flight limit, so idle clients get one task at a time
+		slots = 1
+	}
+	now := time.Now()
+	recipients := []*Client{}
+	for client := range h.Clients {
+		if client.TenantID != message.TenantID {
+			continue
+		}
+		if !client.accepts(message) {
+			continue
+		}
+		if idlePrecache && !client.idle(message.IdleFor, now) {
+			continue
+		}
+		if len(toExclude) > 0 && slices.Contains(toExclude, client.IPAddress) {
+			continue
+		}
+		if slots > 0 && client.inFlight >= slots {
+			continue
+		}
+		recipients = append(recipients, client)
+	}
+	targeted := ""
+	if message.Event == models.HubEventAssigned && !idlePrecache && message.Attempt == 0 {
+		recipients, targeted = targetRecipients(warmRecipients(recipients, now), message.DifficultyMultiplier, policy)
+	}
+	sent := 0
+	for _, client := range recipients {
+		select {
+		case client.Send <- message.Msg:
+			sent++
+			if slots > 0 {
+				client.inFlight++
+				h.assigned[message.RequestID] = append(h.assigned[message.RequestID], client)
+			}
+			if message.Event == models.HubEventAssigned {
+				h.markSent(message.RequestID, client, now)
+				if !idlePrecache {
+					client.lastWorkAt = now
 				}
-			}()
+			}
+		default:
+			client.setDisconnectReason(serializableModels.DisconnectSlowConsumer, fmt.Sprintf("more than %d messages behind", cap(client.Send)))
+			h.remove(client)
+		}
+	}
+	if message.Event == models.HubEventAssigned && sent > 0 {
+		if activeChannel := ActiveChannels.Get(message.RequestID); activeChannel != nil && activeChannel.DispatchedAt.IsZero() {
+			activeChannel.DispatchedAt = time.Now()
 		}
 	}
+	if message.Event != "" {
+		HubEvents.Record(models.HubEvent{Type: message.Event, RequestID: message.RequestID, Hash: message.Hash, TenantID: message.TenantID, DifficultyMultiplier: message.DifficultyMultiplier, Detail: fmt.Sprintf("sent to %d clients%s", sent, targeted)})
+	}
 }
 
 // Recover from panic if the channel is closed
@@ -234,21 +792,57 @@ func WriteChannelSafe(out chan []byte, msg []byte) (err error) {
 	return err
 }
 
+// Where the time of a work request went, so requesters can tell pool latency apart from their own network
+type WorkTimings struct {
+	// Held back by the rate limiter
+	QueueWaitMs int64 `json:"queueWaitMs"`
+	// From the request being broadcast until it went out to the first worker
+	DispatchMs int64 `json:"dispatchMs"`
+	// From the first worker receiving it until a result came back
+	SolveMs int64 `json:"solveMs"`
+	// Spent validating results, including invalid ones
+	ValidationUs int64 `json:"validationUs"`
+}
+
+func workTimings(activeChannel *models.ActiveChannelObject) *WorkTimings {
+	timings := &WorkTimings{
+		ValidationUs: activeChannel.ValidationTime.Microseconds(),
+	}
+	if !activeChannel.DispatchedAt.IsZero() {
+		timings.DispatchMs = activeChannel.DispatchedAt.Sub(activeChannel.RequestedAt).Milliseconds()
+		timings.SolveMs = activeChannel.ResultAt.Sub(activeChannel.DispatchedAt).Milliseconds()
+	}
+	return timings
+}
+
 // Channels for reach specific work request
 var ActiveChannels = models.NewSyncArray()
 
-// Timeout waiting for work response from client
-const WORK_TIMEOUT_S = time.Second * 30
-
 // Method to handle a work request response
 // 1) Broadcast to every client
 // 2) Create a channel for the response
-// 3) Wait for response on the channel until timeout
-func BroadcastWorkRequestAndWait(workRequest serializableModels.ClientMessage) (*serializableModels.ClientWorkResponse, error) {
+// 3) Wait for response on the channel until timeout, broadcasting again as often as the policy allows
+func BroadcastWorkRequestAndWait(workRequest serializableModels.ClientMessage) (*serializableModels.ClientWorkResponse, *WorkTimings, error) {
+	return broadcastWorkRequestAndWait(workRequest, false, 0, 0)
+}
+
+// For requesters with a priority boost running, their work goes out before other on-demand work
+func BroadcastBoostedWorkRequestAndWait(workRequest serializableModels.ClientMessage) (*serializableModels.ClientWorkResponse, *WorkTimings, error) {
+	return broadcastWorkRequestAndWait(workRequest, true, 0, 0)
+}
+
+// Idle precache tasks only go to clients that had no work for idleFor, and their solves are credited with creditPercent of the difficulty
+func broadcastWorkRequestAndWait(workRequest serializableModels.ClientMessage, boosted bool, idleFor time.Duration, creditPercent int) (*serializableModels.ClientWorkResponse, *WorkTimings, error) {
+	if workRequest.TenantID == "" {
+		workRequest.TenantID = config.DEFAULT_TENANT_ID
+	}
+	if err := shed(workRequest, boosted, time.Now()); err != nil {
+		return nil, nil, err
+	}
 	// Serialize
 	bytes, err := json.Marshal(workRequest)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// Create channel for this hash
 	responseChan := make(chan []byte)
@@ -256,26 +850,42 @@ func BroadcastWorkRequestAndWait(workRequest serializableModels.ClientMessage) (
 	activeChannelObj := models.ActiveChannelObject{
 		BlockAward:           workRequest.BlockAward,
 		RequesterEmail:       workRequest.RequesterEmail,
+		TenantID:             workRequest.TenantID,
 		RequestID:            workRequest.RequestID,
 		Hash:                 workRequest.Hash,
 		DifficultyMultiplier: workRequest.DifficultyMultiplier,
 		Chan:                 responseChan,
 		Precache:             workRequest.Precache,
+		CreditPercent:        creditPercent,
+		RequestedAt:          time.Now(),
 	}
 	ActiveChannels.Put(&activeChannelObj)
 	defer ActiveChannels.Delete(workRequest.RequestID)
-	ActiveHub.Broadcast <- bytes
-	select {
-	case response := <-activeChannelObj.Chan:
+	defer ActiveHub.Release(workRequest.RequestID)
+	// The policy of when the request came in applies to all of its attempts
+	policy := Policy()
+	priority := workPriority(workRequest.Precache, boosted, idleFor)
+	for attempt := 0; attempt <= policy.Retries; attempt++ {
+		if attempt > 0 {
+			// Workers that were busy may have a free slot by now
+			ActiveHub.Release(workRequest.RequestID)
+		}
+		ActiveHub.Broadcast <- BroadcastMessage{TenantID: workRequest.TenantID, Msg: bytes, Event: models.HubEventAssigned, RequestID: workRequest.RequestID, Hash: workRequest.Hash, DifficultyMultiplier: workRequest.DifficultyMultiplier, Precache: workRequest.Precache, IdleFor: idleFor, Priority: priority, Attempt: attempt}
+		response := awaitResponse(&activeChannelObj, policy, attempt)
+		if response == nil {
+			continue
+		}
 		var workResponse serializableModels.ClientWorkResponse
 		err := json.Unmarshal(response, &workResponse)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		timings := workTimings(&activeChannelObj)
+		if !activeChannelObj.DispatchedAt.IsZero() {
+			metrics.ObserveSolve(priority.String(), activeChannelObj.ResultAt.Sub(activeChannelObj.DispatchedAt))
 		}
-		return &workResponse, nil
-	// 30
-	case <-time.After(WORK_TIMEOUT_S):
-		klog.Errorf("Work request timed out %s", workRequest.Hash)
-		return nil, errors.New("timeout")
+		return &workResponse, timings, nil
 	}
+	ClosedRequests.Close(&activeChannelObj, closedTimedOut, time.Now())
+	return nil, nil, errors.New("timeout")
 }
@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/gorilla/websocket"
+)
+
+func TestWorkTimings(t *testing.T) {
+	requestedAt := time.Unix(1000, 0)
+	timings := workTimings(&models.ActiveChannelObject{
+		RequestedAt:    requestedAt,
+		DispatchedAt:   requestedAt.Add(5 * time.Millisecond),
+		ResultAt:       requestedAt.Add(1500 * time.Millisecond),
+		ValidationTime: 40 * time.Microsecond,
+	})
+	utils.AssertEqual(t, int64(5), timings.DispatchMs)
+	utils.AssertEqual(t, int64(1495), timings.SolveMs)
+	utils.AssertEqual(t, int64(40), timings.ValidationUs)
+
+	// Never dispatched
+	timings = workTimings(&models.ActiveChannelObject{RequestedAt: requestedAt})
+	utils.AssertEqual(t, int64(0), timings.DispatchMs)
+	utils.AssertEqual(t, int64(0), timings.SolveMs)
+}
+
+func TestReconcileConnectedClients(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	redis := database.GetRedisDB()
+	// Redis has a stale client and lost a connected one
+	redis.AddConnectedClient("1.1.1.1", "default")
+	redis.AddConnectedClient("3.3.3.3", "default")
+
+	hub := NewHub(nil)
+	hub.Clients[&Client{IPAddress: "1.1.1.1", TenantID: "default"}] = true
+	hub.Clients[&Client{IPAddress: "2.2.2.2", TenantID: "mypool"}] = true
+
+	count, err := hub.ReconcileConnectedClients()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 2, count)
+	clients, _ := redis.Hgetall("clients")
+	utils.AssertEqual(t, map[string]string{"1.1.1.1": "default", "2.2.2.2": "mypool"}, clients)
+
+	// No connections leaves no clients
+	count, err = NewHub(nil).ReconcileConnectedClients()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, count)
+	connected, _ := redis.GetNumberConnectedClients()
+	utils.AssertEqual(t, int64(0), connected)
+}
+
+func TestRedeliverPendingAwards(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	redis := database.GetRedisDB()
+	redis.AddPendingAward("provider@example.com", "1", `{"request_type":"block_awarded","message_id":"1"}`)
+	redis.AddPendingAward("other@example.com", "2", `{"request_type":"block_awarded","message_id":"2"}`)
+
+	hub := NewHub(nil)
+	client := &Client{Email: "provider@example.com", Send: make(chan []byte, 10)}
+	hub.redeliverPendingAwards(client)
+	utils.AssertEqual(t, 1, len(client.Send))
+	utils.AssertEqual(t, `{"request_type":"block_awarded","message_id":"1"}`, string(<-client.Send))
+
+	// Acknowledged messages aren't sent again
+	utils.AssertEqual(t, nil, redis.AckPendingAward("provider@example.com", "1"))
+	hub.redeliverPendingAwards(client)
+	utils.AssertEqual(t, 0, len(client.Send))
+	redis.AckPendingAward("other@example.com", "2")
+}
+
+func TestBroadcastInFlightLimit(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	policy := models.DefaultHubPolicy()
+	policy.MaxInFlightPerWorker = 2
+	policy.PrecacheWeight = 1
+	policy.OnDemandWeight = 1
+	SetPolicy(policy)
+	defer SetPolicy(models.DefaultHubPolicy())
+
+	hub := NewHub(nil)
+	client := &Client{IPAddress: "1.1.1.1", TenantID: "default", Send: make(chan []byte, 10)}
+	hub.Clients[client] = true
+	send := func(requestID string, precache bool) {
+		hub.broadcast(BroadcastMessage{TenantID: "default", Msg: []byte(requestID), Event: models.HubEventAssigned, RequestID: requestID, Precache: precache})
+	}
+
+	// Precache only gets half of the slots
+	send("1", true)
+	send("2", true)
+	utils.AssertEqual(t, 1, len(client.Send))
+	send("3", false)
+	send("4", false)
+	utils.AssertEqual(t, 2, len(client.Send))
+	utils.AssertEqual(t, 2, client.inFlight)
+
+	// Finished requests free their slot, other messages aren't limited
+	hub.Release("1")
+	utils.AssertEqual(t, 1, client.inFlight)
+	send("5", false)
+	hub.broadcast(BroadcastMessage{TenantID: "default", Msg: []byte("cancel")})
+	utils.AssertEqual(t, 4, len(client.Send))
+	hub.Release("unknown")
+	utils.AssertEqual(t, 2, client.inFlight)
+}
+
+func TestBroadcastIdlePrecache(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	now := time.Now()
+	hub := NewHub(nil)
+	idle := &Client{IPAddress: "1.1.1.1", TenantID: "default", Send: make(chan []byte, 10), lastWorkAt: now.Add(-time.Hour)}
+	busy := &Client{IPAddress: "2.2.2.2", TenantID: "default", Send: make(chan []byte, 10), lastWorkAt: now}
+	other := &Client{IPAddress: "3.3.3.3", TenantID: "mypool", Send: make(chan []byte, 10), lastWorkAt: now}
+	hub.Clients[idle] = true
+	hub.Clients[busy] = true
+	hub.Clients[other] = true
+	utils.AssertEqual(t, []string{"default"}, hub.IdleTenants(time.Minute, now))
+
+	hub.broadcast(BroadcastMessage{TenantID: "default", Msg: []byte("1"), Event: models.HubEventAssigned, RequestID: "1", Precache: true, IdleFor: time.Minute})
+	utils.AssertEqual(t, 1, len(idle.Send))
+	utils.AssertEqual(t, 0, len(busy.Send))
+	// One task at a time, and it doesn't count as work
+	utils.AssertEqual(t, 1, idle.inFlight)
+	utils.AssertEqual(t, 0, len(hub.IdleTenants(time.Minute, now)))
+	hub.Release("1")
+	utils.AssertEqual(t, []string{"default"}, hub.IdleTenants(time.Minute, now))
+
+	// On-demand work ends the idle time
+	hub.broadcast(BroadcastMessage{TenantID: "default", Msg: []byte("2"), Event: models.HubEventAssigned, RequestID: "2"})
+	utils.AssertEqual(t, 0, len(hub.IdleTenants(time.Minute, time.Now())))
+}
+
+func TestIdlePrecacherAssign(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	policy := models.DefaultHubPolicy()
+	policy.IdlePrecacheSeconds = 60
+	SetPolicy(policy)
+	defer SetPolicy(models.DefaultHubPolicy())
+
+	hub := NewHub(nil)
+	previousHub := ActiveHub
+	ActiveHub = hub
+	defer func() { ActiveHub = previousHub }()
+	now := time.Now()
+	hub.Clients[&Client{IPAddress: "1.1.1.1", TenantID: "idlepool", lastWorkAt: now.Add(-time.Hour)}] = true
+
+	redis := database.GetRedisDB()
+	redis.AddFrontiers("idlepool", []database.FrontierTask{{Hash: "CACHED", DifficultyMultiplier: 1}}, now.Add(-time.Minute))
+	redis.AddFrontiers("idlepool", []database.FrontierTask{{Hash: "FRONTIER", DifficultyMultiplier: 1, RequesterEmail: "requester@example.com"}}, now)
+	precacher := NewIdlePrecacher(hub, func(tenantID string, hash string, difficultyMultiplier int) bool {
+		return hash == "CACHED"
+	})
+
+	// Cached frontiers are skipped
+	precacher.Assign(now)
+	message := <-hub.Broadcast
+	utils.AssertEqual(t, "idlepool", message.TenantID)
+	utils.AssertEqual(t, time.Minute, message.IdleFor)
+	utils.AssertEqual(t, true, message.Precache)
+	utils.AssertEqual(t, PriorityIdlePrecache, message.Priority)
+	activeChannel := ActiveChannels.Get(message.RequestID)
+	utils.AssertEqual(t, "FRONTIER", activeChannel.Hash)
+	utils.AssertEqual(t, 50, activeChannel.CreditPercent)
+
+	// The tenant has a task out already
+	redis.AddFrontiers("idlepool", []database.FrontierTask{{Hash: "NEXT", DifficultyMultiplier: 1}}, now)
+	precacher.Assign(now)
+	utils.AssertEqual(t, 0, len(hub.Broadcast))
+	redis.Del("frontierpool:idlepool")
+}
+
+func TestRejectFreesSlot(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	policy := models.DefaultHubPolicy()
+	policy.MaxInFlightPerWorker = 1
+	SetPolicy(policy)
+	defer SetPolicy(models.DefaultHubPolicy())
+
+	hub := NewHub(nil)
+	rejecting := &Client{IPAddress: "1.1.1.1", Email: "a@example.com", TenantID: "default", Send: make(chan []byte, 10)}
+	working := &Client{IPAddress: "2.2.2.2", TenantID: "default", Send: make(chan []byte, 10)}
+	hub.Clients[rejecting] = true
+	hub.Clients[working] = true
+	hub.broadcast(BroadcastMessage{TenantID: "default", Msg: []byte("1"), Event: models.HubEventAssigned, RequestID: "rejected-1"})
+	utils.AssertEqual(t, 1, rejecting.inFlight)
+
+	hub.reject(ClientWSMessage{ClientEmail: rejecting.Email, TenantID: "default", client: rejecting}, serializableModels.ClientWorkResponse{RequestID: "rejected-1", Rejected: serializableModels.RejectInvalidHash, RejectedCounts: map[string]int{"invalid_hash": 3, "invalid_difficulty": 1}})
+	utils.AssertEqual(t, 0, rejecting.inFlight)
+	utils.AssertEqual(t, 1, working.inFlight)
+	events := HubEvents.ForRequest("rejected-1")
+	rejected := events[len(events)-1]
+	utils.AssertEqual(t, models.HubEventRejected, rejected.Type)
+	utils.AssertEqual(t, "1.1.1.1", rejected.ClientIP)
+	utils.AssertEqual(t, "invalid_hash, rejected by this client so far: invalid_difficulty=1 invalid_hash=3", rejected.Detail)
+
+	// The other client's result still frees its own slot
+	hub.Release("rejected-1")
+	utils.AssertEqual(t, 0, working.inFlight)
+}
+
+func TestStopClosesWorkersGoingAway(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	hub := NewHub(nil)
+	go hub.Run()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := &Client{Hub: hub, Conn: conn, Send: make(chan []byte, 256), IPAddress: "4.4.4.4", TenantID: "default"}
+		hub.Register <- client
+		go client.writePump()
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	utils.AssertEqual(t, nil, err)
+	defer conn.Close()
+	for hub.Snapshot().Workers == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	hub.Stop()
+	utils.AssertEqual(t, 0, len(hub.Clients))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	utils.AssertEqual(t, true, errors.As(err, &closeErr))
+	utils.AssertEqual(t, websocket.CloseGoingAway, closeErr.Code)
+	utils.AssertEqual(t, "server_shutdown", closeErr.Text)
+	connected, _ := database.GetRedisDB().GetNumberConnectedClients()
+	utils.AssertEqual(t, int64(0), connected)
+}
@@ -0,0 +1,198 @@
+package crosscheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"k8s.io/klog/v2"
+)
+
+// A peer that doesn't agree with our verdict on a result
+type Disagreement struct {
+	Peer                 string `json:"peer"`
+	Hash                 string `json:"hash"`
+	Work                 string `json:"work"`
+	DifficultyMultiplier int    `json:"difficulty_multiplier"`
+	// Our verdict, the peer's is the opposite
+	OursValid bool      `json:"ours_valid"`
+	At        time.Time `json:"at"`
+}
+
+// Body of disagreement alert webhooks
+type AlertWebhook struct {
+	Event        string        `json:"event"`
+	Disagreement *Disagreement `json:"disagreement"`
+}
+
+const DisagreementEvent = "validation_disagreement"
+
+// Counted since the server started
+type PeerStats struct {
+	Peer          string
+	Checked       int
+	Disagreements int
+	Errors        int
+	LastError     string
+}
+
+type Stats struct {
+	SamplePercent float64
+	// Sampled results that weren't checked because too many checks were running
+	Skipped             int
+	Peers               []PeerStats
+	RecentDisagreements []Disagreement
+}
+
+// Cross-checks a sample of results with the peers, alerting when they disagree with us
+type Checker struct {
+	peers         []Peer
+	samplePercent float64
+	webhookURL    string
+	client        *http.Client
+	slots         chan struct{}
+	random        func() float64
+	now           func() time.Time
+	mu            sync.Mutex
+	peerStats     []PeerStats
+	skipped       int
+	recent        []Disagreement
+	lastAlert     map[string]time.Time
+}
+
+func NewChecker(peers []Peer, samplePercent float64, webhookURL string) *Checker {
+	peerStats := make([]PeerStats, len(peers))
+	for i, peer := range peers {
+		peerStats[i].Peer = peer.Name()
+	}
+	return &Checker{
+		peers:         peers,
+		samplePercent: samplePercent,
+		webhookURL:    webhookURL,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		slots:         make(chan struct{}, config.CROSSCHECK_MAX_CONCURRENT),
+		random:        rand.Float64,
+		now:           time.Now,
+		peerStats:     peerStats,
+		lastAlert:     make(map[string]time.Time),
+	}
+}
+
+// Cross-checks the result in the background if it's sampled, never blocks the hub
+func (c *Checker) Sample(hash string, difficultyMultiplier int, work string, valid bool) {
+	if len(c.peers) == 0 || c.random()*100 >= c.samplePercent {
+		return
+	}
+	select {
+	case c.slots <- struct{}{}:
+	default:
+		c.mu.Lock()
+		c.skipped++
+		c.mu.Unlock()
+		return
+	}
+	go func() {
+		defer func() { <-c.slots }()
+		c.Check(hash, difficultyMultiplier, work, valid)
+	}()
+}
+
+// Asks every peer about the result, returns the ones that disagree with our verdict
+func (c *Checker) Check(hash string, difficultyMultiplier int, work string, valid bool) []Disagreement {
+	disagreements := []Disagreement{}
+	for i, peer := range c.peers {
+		ctx, cancel := context.WithTimeout(context.Background(), config.CROSSCHECK_TIMEOUT_SECONDS*time.Second)
+		peerValid, err := peer.Validate(ctx, hash, difficultyMultiplier, work)
+		cancel()
+		if err != nil {
+			logging.Warningf(logging.Hub, "Validation peer %s couldn't check %s: %v", peer.Name(), hash, err)
+			c.mu.Lock()
+			c.peerStats[i].Errors++
+			c.peerStats[i].LastError = err.Error()
+			c.mu.Unlock()
+			continue
+		}
+		c.mu.Lock()
+		c.peerStats[i].Checked++
+		if peerValid == valid {
+			c.mu.Unlock()
+			continue
+		}
+		disagreement := Disagreement{
+			Peer:                 peer.Name(),
+			Hash:                 hash,
+			Work:                 work,
+			DifficultyMultiplier: difficultyMultiplier,
+			OursValid:            valid,
+			At:                   c.now(),
+		}
+		c.peerStats[i].Disagreements++
+		c.recent = append(c.recent, disagreement)
+		if len(c.recent) > config.CROSSCHECK_RECENT_DISAGREEMENTS {
+			c.recent = c.recent[1:]
+		}
+		alert := disagreement.At.Sub(c.lastAlert[disagreement.Peer]) >= config.CROSSCHECK_ALERT_INTERVAL_MINUTES*time.Minute
+		if alert {
+			c.lastAlert[disagreement.Peer] = disagreement.At
+		}
+		c.mu.Unlock()
+		logging.Errorf(logging.Hub, "Validation peer %s disagrees on work %s for %s at difficulty %dx, we said valid=%t", disagreement.Peer, work, hash, difficultyMultiplier, valid)
+		if alert {
+			c.announce(&disagreement)
+		}
+		disagreements = append(disagreements, disagreement)
+	}
+	return disagreements
+}
+
+// Recent disagreements are newest first
+func (c *Checker) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := Stats{
+		SamplePercent:       c.samplePercent,
+		Skipped:             c.skipped,
+		Peers:               make([]PeerStats, len(c.peerStats)),
+		RecentDisagreements: make([]Disagreement, len(c.recent)),
+	}
+	copy(stats.Peers, c.peerStats)
+	for i, disagreement := range c.recent {
+		stats.RecentDisagreements[len(c.recent)-1-i] = disagreement
+	}
+	return stats
+}
+
+func (c *Checker) announce(disagreement *Disagreement) {
+	if c.webhookURL == "" {
+		return
+	}
+	b, err := json.Marshal(AlertWebhook{Event: DisagreementEvent, Disagreement: disagreement})
+	if err != nil {
+		klog.Errorf("Error marshalling validation alert webhook %v", err)
+		return
+	}
+	go func() {
+		if err := c.post(b); err != nil {
+			klog.Errorf("Error sending validation alert webhook %v", err)
+		}
+	}()
+}
+
+func (c *Checker) post(body []byte) error {
+	resp, err := c.client.Post(c.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("validation alert webhook responded with %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package crosscheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+type fakePeer struct {
+	name  string
+	valid bool
+	err   error
+}
+
+func (p *fakePeer) Name() string {
+	return p.name
+}
+
+func (p *fakePeer) Validate(ctx context.Context, hash string, difficultyMultiplier int, work string) (bool, error) {
+	return p.valid, p.err
+}
+
+func TestCheck(t *testing.T) {
+	alerts := make(chan AlertWebhook, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert AlertWebhook
+		json.NewDecoder(r.Body).Decode(&alert)
+		alerts <- alert
+	}))
+	defer server.Close()
+
+	agrees := &fakePeer{name: "agrees", valid: true}
+	disagrees := &fakePeer{name: "disagrees", valid: false}
+	broken := &fakePeer{name: "broken", err: errors.New("connection refused")}
+	checker := NewChecker([]Peer{agrees, disagrees, broken}, 100, server.URL)
+	now := time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC)
+	checker.now = func() time.Time { return now }
+
+	disagreements := checker.Check("AB", 1, "CD", true)
+	utils.AssertEqual(t, 1, len(disagreements))
+	utils.AssertEqual(t, "disagrees", disagreements[0].Peer)
+	utils.AssertEqual(t, true, disagreements[0].OursValid)
+	alert := <-alerts
+	utils.AssertEqual(t, DisagreementEvent, alert.Event)
+	utils.AssertEqual(t, "AB", alert.Disagreement.Hash)
+
+	// Alerts are throttled per peer, disagreements are still counted
+	now = now.Add(time.Minute)
+	checker.Check("EF", 1, "CD", true)
+	stats := checker.Stats()
+	utils.AssertEqual(t, 2, stats.Peers[0].Checked)
+	utils.AssertEqual(t, 0, stats.Peers[0].Disagreements)
+	utils.AssertEqual(t, 2, stats.Peers[1].Disagreements)
+	utils.AssertEqual(t, 2, stats.Peers[2].Errors)
+	utils.AssertEqual(t, "connection refused", stats.Peers[2].LastError)
+	utils.AssertEqual(t, "EF", stats.RecentDisagreements[0].Hash)
+	utils.AssertEqual(t, 0, len(alerts))
+
+	// Invalid results are checked too
+	utils.AssertEqual(t, "agrees", checker.Check("AB", 1, "CD", false)[0].Peer)
+}
+
+func TestSample(t *testing.T) {
+	checker := NewChecker([]Peer{&fakePeer{name: "agrees", valid: true}}, 50, "")
+
+	checker.random = func() float64 { return 0.6 }
+	checker.Sample("AB", 1, "CD", true)
+	checker.random = func() float64 { return 0.4 }
+	checker.Sample("AB", 1, "CD", true)
+	// Slots are taken before the check starts and freed after it's counted
+	for len(checker.slots) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	utils.AssertEqual(t, 1, checker.Stats().Peers[0].Checked)
+}
@@ -0,0 +1,143 @@
+// Package crosscheck validates a sample of results again with independent validators, a safety net for bugs in our own validation
+package crosscheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bananocoin/boompow/libs/banano"
+	"github.com/bananocoin/boompow/libs/utils/validation"
+)
+
+// Something that can tell whether work is valid, independently of us
+type Peer interface {
+	Name() string
+	Validate(ctx context.Context, hash string, difficultyMultiplier int, work string) (bool, error)
+}
+
+// Peer kinds in BPOW_VALIDATION_PEERS
+const (
+	NodePeerKind    = "node"
+	BoomPowPeerKind = "boompow"
+)
+
+// Parses peers like "node:http://[::1]:7072" or "boompow:https://boompow.banano.cc/graphql"
+func ParsePeers(raw []string, client *http.Client) ([]Peer, error) {
+	peers := make([]Peer, 0, len(raw))
+	for _, entry := range raw {
+		kind, peerURL, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("validation peer %q has no kind, expected %s:<url> or %s:<url>", entry, NodePeerKind, BoomPowPeerKind)
+		}
+		if parsed, err := url.Parse(peerURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("validation peer %q doesn't have an http(s) URL", entry)
+		}
+		switch kind {
+		case NodePeerKind:
+			peers = append(peers, &NodePeer{URL: peerURL, Client: client})
+		case BoomPowPeerKind:
+			peers = append(peers, &BoomPowPeer{URL: peerURL, Client: client})
+		default:
+			return nil, fmt.Errorf("validation peer %q has unknown kind %q", entry, kind)
+		}
+	}
+	return peers, nil
+}
+
+// A node RPC, validates with work_validate at our exact threshold
+type NodePeer struct {
+	URL    string
+	Client *http.Client
+}
+
+func (p *NodePeer) Name() string {
+	return NodePeerKind + ":" + p.URL
+}
+
+// Not retried, a sample that can't be checked is counted as a peer error
+func (p *NodePeer) Validate(ctx context.Context, hash string, difficultyMultiplier int, work string) (bool, error) {
+	node := &banano.Client{URL: p.URL, HTTPClient: p.Client}
+	valid, err := node.WorkValidate(ctx, hash, work, validation.CalculateDifficulty(int64(difficultyMultiplier)))
+	var rpcErr *banano.RPCError
+	if errors.As(err, &rpcErr) {
+		return false, errors.New(rpcErr.Message)
+	}
+	return valid, err
+}
+
+// Another BoomPow instance, validates with its public validateWork query
+type BoomPowPeer struct {
+	URL    string
+	Client *http.Client
+}
+
+const validateWorkQuery = `query($input: ValidateWorkInput!) { validateWork(input: $input) }`
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type validateWorkResponse struct {
+	Data *struct {
+		ValidateWork bool `json:"validateWork"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (p *BoomPowPeer) Name() string {
+	return BoomPowPeerKind + ":" + p.URL
+}
+
+func (p *BoomPowPeer) Validate(ctx context.Context, hash string, difficultyMultiplier int, work string) (bool, error) {
+	var resp validateWorkResponse
+	err := postJSON(ctx, p.Client, p.URL, graphQLRequest{
+		Query: validateWorkQuery,
+		Variables: map[string]interface{}{
+			"input": map[string]interface{}{
+				"hash":                 hash,
+				"work":                 work,
+				"difficultyMultiplier": difficultyMultiplier,
+			},
+		},
+	}, &resp)
+	if err != nil {
+		return false, err
+	}
+	if len(resp.Errors) > 0 {
+		return false, errors.New(resp.Errors[0].Message)
+	}
+	if resp.Data == nil {
+		return false, errors.New("validateWork response has no data")
+	}
+	return resp.Data.ValidateWork, nil
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}, out interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("peer responded with %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
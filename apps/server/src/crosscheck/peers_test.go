@@ -0,0 +1,72 @@
+package crosscheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/bananocoin/boompow/libs/utils/validation"
+)
+
+func TestParsePeers(t *testing.T) {
+	peers, err := ParsePeers([]string{"node:http://[::1]:7072", "boompow:https://boompow.banano.cc/graphql"}, http.DefaultClient)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "node:http://[::1]:7072", peers[0].Name())
+	utils.AssertEqual(t, "boompow:https://boompow.banano.cc/graphql", peers[1].Name())
+
+	for _, raw := range []string{"http://[::1]:7072", "rpc:http://[::1]:7072", "node:[::1]:7072", "node:"} {
+		_, err := ParsePeers([]string{raw}, http.DefaultClient)
+		utils.AssertNotEqual(t, nil, err)
+	}
+}
+
+func TestNodePeer(t *testing.T) {
+	var received map[string]string
+	response := `{"valid": "1", "valid_all": "1", "valid_receive": "1"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		fmt.Fprint(w, response)
+	}))
+	defer server.Close()
+	peer := &NodePeer{URL: server.URL, Client: server.Client()}
+
+	valid, err := peer.Validate(context.Background(), "AB", 8, "CD")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, valid)
+	utils.AssertEqual(t, "work_validate", received["action"])
+	// Our threshold, not the node's defaults
+	utils.AssertEqual(t, fmt.Sprintf("%016x", validation.CalculateDifficulty(8)), received["difficulty"])
+
+	response = `{"valid": "0"}`
+	valid, err = peer.Validate(context.Background(), "AB", 8, "CD")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, valid)
+
+	response = `{"error": "Bad block hash number"}`
+	_, err = peer.Validate(context.Background(), "AB", 8, "CD")
+	utils.AssertEqual(t, "Bad block hash number", err.Error())
+}
+
+func TestBoomPowPeer(t *testing.T) {
+	var received graphQLRequest
+	response := `{"data": {"validateWork": true}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		fmt.Fprint(w, response)
+	}))
+	defer server.Close()
+	peer := &BoomPowPeer{URL: server.URL, Client: server.Client()}
+
+	valid, err := peer.Validate(context.Background(), "AB", 8, "CD")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, valid)
+	utils.AssertEqual(t, validateWorkQuery, received.Query)
+
+	response = `{"errors": [{"message": "bad_request:invalid hash"}], "data": null}`
+	_, err = peer.Validate(context.Background(), "AB", 8, "CD")
+	utils.AssertEqual(t, "bad_request:invalid hash", err.Error())
+}
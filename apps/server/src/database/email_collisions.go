@@ -0,0 +1,38 @@
+package database
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"gorm.io/gorm"
+	"k8s.io/klog/v2"
+)
+
+// Fills in the normalized emails of accounts from before emails were normalized, or from before BPOW_EMAIL_ALIASES changed
+// Accounts that turn out to share a mailbox are flagged for admins to review, they keep working
+func normalizeEmails(db *gorm.DB) error {
+	var users []models.User
+	err := db.Select("id", "email", "normalized_email").FindInBatches(&users, 1000, func(tx *gorm.DB, batch int) error {
+		for _, user := range users {
+			normalized := models.NormalizeEmail(user.Email)
+			if normalized == user.NormalizedEmail {
+				continue
+			}
+			if err := db.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumn("normalized_email", normalized).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return err
+	}
+	res := db.Exec("INSERT INTO email_collisions (normalized_email, flagged_at) SELECT normalized_email, ? FROM users GROUP BY normalized_email HAVING count(*) > 1 ON CONFLICT DO NOTHING", time.Now().UTC())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		klog.Warningf("Flagged %d emails shared by several accounts for review", res.RowsAffected)
+	}
+	return nil
+}
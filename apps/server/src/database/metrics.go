@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/bananocoin/boompow/apps/server/src/metrics"
+	"github.com/go-redis/redis/v9"
+	"gorm.io/gorm"
+)
+
+// Counts failed redis commands, keys that don't exist aren't failures
+type redisErrorHook struct{}
+
+var _ redis.Hook = redisErrorHook{}
+
+func (redisErrorHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			metrics.CountStoreError("redis", "dial")
+		}
+		return conn, err
+	}
+}
+
+func (redisErrorHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			metrics.CountStoreError("redis", cmd.Name())
+		}
+		return err
+	}
+}
+
+func (redisErrorHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			metrics.CountStoreError("redis", "pipeline")
+		}
+		return err
+	}
+}
+
+// Counts failed postgres statements after gorm ran them, records that weren't found aren't failures
+func registerErrorCallbacks(db *gorm.DB) error {
+	count := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+				metrics.CountStoreError("postgres", operation)
+			}
+		}
+	}
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().After("gorm:create").Register("metrics:create", count("create")),
+		callbacks.Query().After("gorm:query").Register("metrics:query", count("query")),
+		callbacks.Update().After("gorm:update").Register("metrics:update", count("update")),
+		callbacks.Delete().After("gorm:delete").Register("metrics:delete", count("delete")),
+		callbacks.Row().After("gorm:row").Register("metrics:row", count("row")),
+		callbacks.Raw().After("gorm:raw").Register("metrics:raw", count("raw")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
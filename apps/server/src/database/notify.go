@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/jackc/pgconn"
+	"gorm.io/gorm"
+	"k8s.io/klog/v2"
+)
+
+// Triggers that NOTIFY interesting changes as JSON, so the server can push them to subscribers instead of clients polling
+var notifyTriggers = []struct {
+	table     string
+	name      string
+	condition string
+	payload   string
+}{
+	{
+		table:     "users",
+		name:      "user_verified",
+		condition: "NEW.email_verified AND NOT OLD.email_verified",
+		payload:   "json_build_object('type', 'user_verified', 'user_id', NEW.id)",
+	},
+	{
+		table:     "payments",
+		name:      "payout_sent",
+		condition: "OLD.block_hash IS NULL AND NEW.block_hash IS NOT NULL",
+		payload:   "json_build_object('type', 'payout_sent', 'user_id', NEW.paid_to, 'block_hash', NEW.block_hash, 'amount_raw', NEW.send_json->>'amount')",
+	},
+}
+
+func createNotifyTriggers(db *gorm.DB) error {
+	for _, trigger := range notifyTriggers {
+		function := fmt.Sprintf("notify_%s", trigger.name)
+		if err := db.Exec(fmt.Sprintf(`CREATE OR REPLACE FUNCTION %s() RETURNS trigger AS $$
+BEGIN
+	PERFORM pg_notify('%s', %s::text);
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;`, function, config.DB_NOTIFY_CHANNEL, trigger.payload)).Error; err != nil {
+			return err
+		}
+		if err := db.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", function, trigger.table)).Error; err != nil {
+			return err
+		}
+		if err := db.Exec(fmt.Sprintf("CREATE TRIGGER %s AFTER UPDATE ON %s FOR EACH ROW WHEN (%s) EXECUTE FUNCTION %s()", function, trigger.table, trigger.condition, function)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Listen for notifications on the channel and pass their payload to the handler, reconnecting if the connection drops
+// Blocks forever, so it should be run in its own goroutine
+func ListenForNotifications(dbConfig *Config, channel string, handler func(payload string)) {
+	for {
+		if err := listen(dbConfig, channel, handler); err != nil {
+			klog.Errorf("Lost postgres notification listener on %s, reconnecting: %v", channel, err)
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+func listen(dbConfig *Config, channel string, handler func(payload string)) error {
+	ctx := context.Background()
+	pgConfig, err := pgconn.ParseConfig(dbConfig.dsn())
+	if err != nil {
+		return err
+	}
+	pgConfig.OnNotification = func(_ *pgconn.PgConn, n *pgconn.Notification) {
+		handler(n.Payload)
+	}
+	conn, err := pgconn.ConnectConfig(ctx, pgConfig)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	if _, err := conn.Exec(ctx, fmt.Sprintf("LISTEN %s", channel)).ReadAll(); err != nil {
+		return err
+	}
+	for {
+		if err := conn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+	}
+}
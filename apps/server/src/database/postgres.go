@@ -3,7 +3,9 @@ package database
 import (
 	"fmt"
 
+	"github.com/bananocoin/boompow/apps/server/src/config"
 	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/libs/utils/number"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -17,20 +19,26 @@ type Config struct {
 	SSLMode  string
 }
 
-func NewConnection(config *Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
+func (config *Config) dsn() string {
+	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
 	)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+}
+
+func NewConnection(config *Config) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(config.dsn()), &gorm.Config{})
 	if err != nil {
 		return db, err
 	}
+	if err := registerErrorCallbacks(db); err != nil {
+		return db, err
+	}
 	return db, nil
 }
 
 func DropAndCreateTables(db *gorm.DB) error {
-	err := db.Migrator().DropTable(&models.User{}, &models.WorkResult{}, &models.Payment{})
+	err := db.Migrator().DropTable(&models.User{}, &models.WorkResult{}, &models.Payment{}, &models.Tenant{}, &models.HubEvent{}, &models.DifficultyRollup{}, &models.AwardRate{}, &models.PayoutAddress{}, &models.BenchmarkProfile{}, &models.OfflineAlert{}, &models.Incident{}, &models.MaintenanceWindow{}, &models.UsageRollup{}, &models.UsageStatement{}, &models.AccountEvent{}, &models.HubPolicy{}, &models.SubmittedWork{}, &models.BackupCode{}, &models.EmailTemplate{}, &models.PayoutCycle{}, &models.UserRole{}, &models.APIKey{}, &models.EmailCollision{}, &models.CreditEntry{}, &models.PriorityBoost{}, &models.WorkSource{}, &models.WorkSourceUsage{}, &models.StaleAccountReport{}, &models.ConnectedWorkersSnapshot{}, &models.CounterValue{}, &models.PayoutStatement{}, &models.LeaderboardEntry{}, &models.SecurityWebhook{}, &models.AuditLogEntry{})
 	if err != nil {
 		return err
 	}
@@ -42,13 +50,56 @@ func DropAndCreateTables(db *gorm.DB) error {
 	if err != nil {
 		return err
 	}
-	err = db.Migrator().CreateTable(&models.User{}, &models.WorkResult{}, &models.Payment{})
-	return err
+	err = db.Migrator().CreateTable(&models.User{}, &models.WorkResult{}, &models.Payment{}, &models.Tenant{}, &models.HubEvent{}, &models.DifficultyRollup{}, &models.AwardRate{}, &models.PayoutAddress{}, &models.BenchmarkProfile{}, &models.OfflineAlert{}, &models.Incident{}, &models.MaintenanceWindow{}, &models.UsageRollup{}, &models.UsageStatement{}, &models.AccountEvent{}, &models.HubPolicy{}, &models.SubmittedWork{}, &models.BackupCode{}, &models.EmailTemplate{}, &models.PayoutCycle{}, &models.UserRole{}, &models.APIKey{}, &models.EmailCollision{}, &models.CreditEntry{}, &models.PriorityBoost{}, &models.WorkSource{}, &models.WorkSourceUsage{}, &models.StaleAccountReport{}, &models.ConnectedWorkersSnapshot{}, &models.CounterValue{}, &models.PayoutStatement{}, &models.LeaderboardEntry{}, &models.SecurityWebhook{}, &models.AuditLogEntry{})
+	if err != nil {
+		return err
+	}
+	if err := createNotifyTriggers(db); err != nil {
+		return err
+	}
+	return createDefaultTenant(db)
 }
 
 func Migrate(db *gorm.DB) error {
 	createTypes(db)
-	return db.AutoMigrate(&models.User{}, &models.WorkResult{}, &models.Payment{})
+	if err := migrateAwardRatesToRaw(db); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.WorkResult{}, &models.Payment{}, &models.Tenant{}, &models.HubEvent{}, &models.DifficultyRollup{}, &models.AwardRate{}, &models.PayoutAddress{}, &models.BenchmarkProfile{}, &models.OfflineAlert{}, &models.Incident{}, &models.MaintenanceWindow{}, &models.UsageRollup{}, &models.UsageStatement{}, &models.AccountEvent{}, &models.HubPolicy{}, &models.SubmittedWork{}, &models.BackupCode{}, &models.EmailTemplate{}, &models.PayoutCycle{}, &models.UserRole{}, &models.APIKey{}, &models.EmailCollision{}, &models.CreditEntry{}, &models.PriorityBoost{}, &models.WorkSource{}, &models.WorkSourceUsage{}, &models.StaleAccountReport{}, &models.ConnectedWorkersSnapshot{}, &models.CounterValue{}, &models.PayoutStatement{}, &models.LeaderboardEntry{}, &models.SecurityWebhook{}, &models.AuditLogEntry{}); err != nil {
+		return err
+	}
+	if err := normalizeEmails(db); err != nil {
+		return err
+	}
+	if err := createNotifyTriggers(db); err != nil {
+		return err
+	}
+	return createDefaultTenant(db)
+}
+
+// Award rates were BANANO in a float column, they're raw in a numeric one now so payouts don't round
+// The float's shortest decimal is converted, so 0.01 becomes exactly 10^27 raw
+func migrateAwardRatesToRaw(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.AwardRate{}) || !db.Migrator().HasColumn(&models.AwardRate{}, "banano_per_unit") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("ALTER TABLE award_rates ADD COLUMN IF NOT EXISTS raw_per_unit numeric NOT NULL DEFAULT 0").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE award_rates SET raw_per_unit = round(cast(banano_per_unit as numeric) * ?)", number.RawPerBananoInt.String()).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("ALTER TABLE award_rates ALTER COLUMN raw_per_unit DROP DEFAULT").Error; err != nil {
+			return err
+		}
+		return tx.Exec("ALTER TABLE award_rates DROP COLUMN banano_per_unit").Error
+	})
+}
+
+// Existing data belongs to the default tenant, so it must always exist
+func createDefaultTenant(db *gorm.DB) error {
+	return db.Where(models.Tenant{ID: config.DEFAULT_TENANT_ID}).FirstOrCreate(&models.Tenant{ID: config.DEFAULT_TENANT_ID, Name: "BoomPoW"}).Error
 }
 
 // Create types in postgres
@@ -2,18 +2,26 @@ package database
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/sampling"
+	"github.com/bananocoin/boompow/apps/server/src/shedding"
 	"github.com/bananocoin/boompow/libs/utils"
+	"github.com/bananocoin/boompow/libs/utils/number"
 	"github.com/go-redis/redis/v9"
 	"github.com/google/uuid"
-	"k8s.io/klog/v2"
 )
 
 var ctx = context.Background()
@@ -25,6 +33,10 @@ const keyPrefix = "boompow"
 type redisManager struct {
 	Client *redis.Client
 	Mock   bool
+	// Result of the last ping of WatchConnection
+	healthy atomic.Bool
+	// Result of the last CheckMemory
+	memory atomic.Pointer[RedisMemoryReport]
 }
 
 var singleton *redisManager
@@ -33,7 +45,7 @@ var once sync.Once
 func GetRedisDB() *redisManager {
 	once.Do(func() {
 		if utils.GetEnv("MOCK_REDIS", "false") == "true" {
-			klog.Infof("Using mock redis client because MOCK_REDIS=true is set in environment")
+			logging.Infof(logging.Redis, "Using mock redis client because MOCK_REDIS=true is set in environment")
 			mr, _ := miniredis.Run()
 			client := redis.NewClient(&redis.Options{
 				Addr: mr.Addr(),
@@ -51,19 +63,56 @@ func GetRedisDB() *redisManager {
 			if err != nil {
 				panic("Invalid REDIS_DB specified")
 			}
+			// The client reconnects on its own, commands are retried through short outages
 			client := redis.NewClient(&redis.Options{
-				Addr: fmt.Sprintf("%s:%d", utils.GetEnv("REDIS_HOST", "localhost"), redis_port),
-				DB:   redis_db,
+				Addr:            fmt.Sprintf("%s:%d", utils.GetEnv("REDIS_HOST", "localhost"), redis_port),
+				DB:              redis_db,
+				DialTimeout:     5 * time.Second,
+				MaxRetries:      5,
+				MinRetryBackoff: 100 * time.Millisecond,
+				MaxRetryBackoff: 2 * time.Second,
 			})
 			singleton = &redisManager{
 				Client: client,
 				Mock:   false,
 			}
 		}
+		singleton.Client.AddHook(redisErrorHook{})
 	})
 	return singleton
 }
 
+func (r *redisManager) Ping() error {
+	return r.Client.Ping(ctx).Err()
+}
+
+// Whether redis answered the last ping of WatchConnection
+func (r *redisManager) Healthy() bool {
+	return r.healthy.Load()
+}
+
+// WatchConnection pings redis every interval, logging when the connection is lost and calling onReconnect once it's back
+// Redis may have restarted empty in the meantime, onReconnect can restore what it needs
+func (r *redisManager) WatchConnection(interval time.Duration, onReconnect func()) {
+	r.healthy.Store(r.Ping() == nil)
+	for range time.Tick(interval) {
+		r.checkConnection(onReconnect)
+	}
+}
+
+func (r *redisManager) checkConnection(onReconnect func()) {
+	err := r.Ping()
+	wasHealthy := r.healthy.Swap(err == nil)
+	if err != nil && wasHealthy {
+		logging.Errorf(logging.Redis, "Lost connection to redis %v", err)
+	} else if err == nil && !wasHealthy {
+		logging.Infof(logging.Redis, "Reconnected to redis")
+		if onReconnect != nil {
+			onReconnect()
+		}
+	}
+}
+
 // del - Redis DEL
 func (r *redisManager) Del(key string) (int64, error) {
 	val, err := r.Client.Del(ctx, key).Result()
@@ -82,6 +131,20 @@ func (r *redisManager) Set(key string, value string, expiry time.Duration) error
 	return err
 }
 
+// Deletes every key matching pattern
+func (r *redisManager) DeleteMatching(pattern string) (int64, error) {
+	var deleted int64
+	iter := r.Client.Scan(ctx, 0, pattern, 1000).Iterator()
+	for iter.Next(ctx) {
+		n, err := r.Del(iter.Val())
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	return deleted, iter.Err()
+}
+
 // hlen - Redis HLEN
 func (r *redisManager) Hlen(key string) (int64, error) {
 	val, err := r.Client.HLen(ctx, key).Result()
@@ -144,6 +207,52 @@ func (r *redisManager) DeleteResetPasswordToken(email string) (int64, error) {
 	return r.Del(fmt.Sprintf("passwordreset:%s", email))
 }
 
+// The reset password token isn't the latest one sent to the email, or was already used
+var ErrResetPasswordTokenInvalid = errors.New("invalid reset password token")
+
+// Whether token is the email's latest reset password token and still unused
+func (r *redisManager) ResetPasswordTokenValid(email string, token string) (bool, error) {
+	stored, err := r.GetResetPasswordToken(email)
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return stored == token, nil
+}
+
+// ConsumeResetPasswordToken invalidates the email's reset password token so it can only be used once, returning how long it had left
+// Of concurrent uses only one gets it, the token is gone even if it wasn't the latest one
+func (r *redisManager) ConsumeResetPasswordToken(email string, token string) (time.Duration, error) {
+	key := fmt.Sprintf("passwordreset:%s", email)
+	var ttl *redis.DurationCmd
+	var stored *redis.StringCmd
+	_, err := r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		ttl = pipe.PTTL(ctx, key)
+		stored = pipe.GetDel(ctx, key)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrResetPasswordTokenInvalid
+	}
+	if err != nil {
+		return 0, err
+	}
+	if stored.Val() != token {
+		return 0, ErrResetPasswordTokenInvalid
+	}
+	return ttl.Val(), nil
+}
+
+// Puts back a reset password token consumed for a change that failed, unless a newer link was sent since
+func (r *redisManager) RestoreResetPasswordToken(email string, token string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	return r.Client.SetNX(ctx, fmt.Sprintf("passwordreset:%s", email), token, ttl).Err()
+}
+
 // Set token
 func (r *redisManager) SetApproveServiceToken(email string, token string) error {
 	// Expire in 2 weeks
@@ -161,8 +270,9 @@ func (r *redisManager) DeleteApproveServiceToken(email string) (int64, error) {
 }
 
 // Functions for keeping track of connected clients
-func (r *redisManager) AddConnectedClient(clientID string) error {
-	return r.Hset("clients", clientID, "1")
+// The tenant the client works for is stored as the value
+func (r *redisManager) AddConnectedClient(clientID string, tenantID string) error {
+	return r.Hset("clients", clientID, tenantID)
 }
 
 func (r *redisManager) RemoveConnectedClient(clientID string) error {
@@ -173,16 +283,144 @@ func (r *redisManager) GetNumberConnectedClients() (int64, error) {
 	return r.Hlen("clients")
 }
 
+func (r *redisManager) GetNumberConnectedClientsForTenant(tenantID string) (int64, error) {
+	clients, err := r.Hgetall("clients")
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	for _, clientTenant := range clients {
+		if clientTenant == tenantID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Replaces the connected clients with clients (IP -> tenant) in one transaction
+func (r *redisManager) ReplaceConnectedClients(clients map[string]string) error {
+	_, err := r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, "clients")
+		if len(clients) > 0 {
+			pipe.HSet(ctx, "clients", clients)
+		}
+		return nil
+	})
+	return err
+}
+
 func (r *redisManager) WipeAllConnectedClients() (int64, error) {
 	return r.Del("clients")
 }
 
+// Replica each client (IP) is connected to in distributed mode
+const clientReplicasKey = "client_replicas"
+
+func replicaHeartbeatKey(replicaID string) string {
+	return fmt.Sprintf("%s:replica:%s", keyPrefix, replicaID)
+}
+
+func hubChannel(name string) string {
+	return fmt.Sprintf("%s:hub:%s", keyPrefix, name)
+}
+
+func (r *redisManager) SetClientReplica(clientID string, replicaID string) error {
+	return r.Hset(clientReplicasKey, clientID, replicaID)
+}
+
+// Empty if the client isn't connected to any replica
+func (r *redisManager) GetClientReplica(clientID string) (string, error) {
+	replicaID, err := r.Hget(clientReplicasKey, clientID)
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return replicaID, err
+}
+
+var removeReplicaClientScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], ARGV[1]) == ARGV[2] then
+	redis.call("HDEL", KEYS[1], ARGV[1])
+	redis.call("HDEL", KEYS[2], ARGV[1])
+	return 1
+end
+return 0`)
+
+// Only removes the connected client if it's still connected to replicaID, it may have reconnected to another replica meanwhile
+func (r *redisManager) RemoveReplicaClient(clientID string, replicaID string) error {
+	return removeReplicaClientScript.Run(ctx, r.Client, []string{clientReplicasKey, "clients"}, clientID, replicaID).Err()
+}
+
+// Replicas that stop sending heartbeats are considered gone once ttl passed
+func (r *redisManager) RecordReplicaHeartbeat(replicaID string, ttl time.Duration) error {
+	return r.Set(replicaHeartbeatKey(replicaID), "1", ttl)
+}
+
+// Replicas are considered alive when redis can't tell, so their clients aren't dropped during an outage
+func (r *redisManager) ReplicaAlive(replicaID string) bool {
+	n, err := r.Client.Exists(ctx, replicaHeartbeatKey(replicaID)).Result()
+	return err != nil || n > 0
+}
+
+// Replaces the connected clients of replicaID, and those of replicas that are gone, with clients (IP -> tenant)
+// Clients of the other replicas are left alone
+func (r *redisManager) ReplaceReplicaClients(replicaID string, clients map[string]string) error {
+	owners, err := r.Hgetall(clientReplicasKey)
+	if err != nil {
+		return err
+	}
+	alive := map[string]bool{}
+	stale := []string{}
+	for clientID, owner := range owners {
+		if _, ok := clients[clientID]; ok {
+			continue
+		}
+		if owner != replicaID {
+			if _, checked := alive[owner]; !checked {
+				alive[owner] = r.ReplicaAlive(owner)
+			}
+			if alive[owner] {
+				continue
+			}
+		}
+		stale = append(stale, clientID)
+	}
+	_, err = r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(stale) > 0 {
+			pipe.HDel(ctx, "clients", stale...)
+			pipe.HDel(ctx, clientReplicasKey, stale...)
+		}
+		for clientID, tenantID := range clients {
+			pipe.HSet(ctx, "clients", clientID, tenantID)
+			pipe.HSet(ctx, clientReplicasKey, clientID, replicaID)
+		}
+		return nil
+	})
+	return err
+}
+
+// Hub traffic between replicas in distributed mode
+func (r *redisManager) PublishHubMessage(channel string, msg []byte) error {
+	return r.Client.Publish(ctx, hubChannel(channel), msg).Err()
+}
+
+func (r *redisManager) SubscribeHub(channels ...string) *redis.PubSub {
+	names := make([]string, len(channels))
+	for i, channel := range channels {
+		names[i] = hubChannel(channel)
+	}
+	return r.Client.Subscribe(ctx, names...)
+}
+
 // For service tokens
 func (r *redisManager) AddServiceToken(userID uuid.UUID, token string) error {
 	userIdStr := userID.String()
 	return r.Hset("servicetokens", token, userIdStr)
 }
 
+func (r *redisManager) RemoveServiceToken(token string) error {
+	return r.Hdel("servicetokens", token)
+}
+
 func (r *redisManager) GetServiceTokenUser(serviceToken string) (string, error) {
 	user, err := r.Hget("servicetokens", serviceToken)
 	if err != nil {
@@ -206,14 +444,279 @@ func (r *redisManager) GetServiceTokenForUser(userID uuid.UUID) (string, error)
 	return "", errors.New("No Token")
 }
 
-// For caching work
-func (r *redisManager) CacheWork(hash string, result string) error {
+// For caching work, scoped by tenant so pools can't observe each other's requests
+func (r *redisManager) CacheWork(tenantID string, hash string, result string) error {
 	// 5 minute cache
-	return r.Set(fmt.Sprintf("cache:%s", hash), result, 5*time.Minute)
+	return r.Set(fmt.Sprintf("cache:%s:%s", tenantID, hash), result, 5*time.Minute)
+}
+
+func (r *redisManager) GetCachedWork(tenantID string, hash string) (string, error) {
+	return r.Get(fmt.Sprintf("cache:%s:%s", tenantID, hash))
+}
+
+// For idempotent work requests, keys are scoped by user so they can't collide between services
+// The value is the request fingerprint and, once the work is done, its result
+func idempotencyRedisKey(userID uuid.UUID, key string) string {
+	return fmt.Sprintf("idempotency:%s:%s", userID.String(), key)
+}
+
+// Claim an idempotency key, returns false if the key was already claimed
+func (r *redisManager) ReserveIdempotencyKey(userID uuid.UUID, key string, fingerprint string) (bool, error) {
+	return r.Client.SetNX(ctx, idempotencyRedisKey(userID, key), fingerprint+"|", config.IDEMPOTENCY_KEY_TTL_HOURS*time.Hour).Result()
+}
+
+// Returns the fingerprint of the original request and its result, the result is empty while it's still in progress
+func (r *redisManager) GetIdempotentResult(userID uuid.UUID, key string) (string, string, error) {
+	val, err := r.Get(idempotencyRedisKey(userID, key))
+	if err != nil {
+		return "", "", err
+	}
+	fingerprint, result, _ := strings.Cut(val, "|")
+	return fingerprint, result, nil
+}
+
+func (r *redisManager) SetIdempotentResult(userID uuid.UUID, key string, fingerprint string, result string) error {
+	return r.Set(idempotencyRedisKey(userID, key), fmt.Sprintf("%s|%s", fingerprint, result), config.IDEMPOTENCY_KEY_TTL_HOURS*time.Hour)
+}
+
+// Release a key whose request failed, so it can be retried
+func (r *redisManager) ReleaseIdempotencyKey(userID uuid.UUID, key string) (int64, error) {
+	return r.Del(idempotencyRedisKey(userID, key))
+}
+
+// Marks a solved challenge as used, returns false if it was already used
+func (r *redisManager) ConsumeChallenge(challenge string) (bool, error) {
+	return r.Client.SetNX(ctx, fmt.Sprintf("challenge:%s", strings.ToUpper(challenge)), "1", config.POW_CHALLENGE_VALID_MINUTES*time.Minute).Result()
+}
+
+// Block awarded messages that the provider's clients haven't acknowledged yet, message ID -> message
+func pendingAwardsKey(email string) string {
+	return fmt.Sprintf("pendingawards:%s", email)
+}
+
+// The TTL is refreshed with every award, so only providers that stopped acknowledging lose messages
+func (r *redisManager) AddPendingAward(email string, messageID string, msg string) error {
+	_, err := r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, pendingAwardsKey(email), messageID, msg)
+		pipe.Expire(ctx, pendingAwardsKey(email), config.PENDING_AWARD_TTL_HOURS*time.Hour)
+		return nil
+	})
+	return err
+}
+
+func (r *redisManager) GetPendingAwards(email string) (map[string]string, error) {
+	return r.Hgetall(pendingAwardsKey(email))
+}
+
+func (r *redisManager) AckPendingAward(email string, messageID string) error {
+	return r.Hdel(pendingAwardsKey(email), messageID)
+}
+
+// Sent over HTTP by clients whose websocket is down
+func workerHeartbeatKey(email string) string {
+	return fmt.Sprintf("heartbeat:%s", strings.ToLower(email))
+}
+
+func (r *redisManager) RecordWorkerHeartbeat(email string, now time.Time) error {
+	return r.Set(workerHeartbeatKey(email), strconv.FormatInt(now.Unix(), 10), config.WORKER_HEARTBEAT_TTL_SECONDS*time.Second)
+}
+
+// Zero if the provider sent none within WORKER_HEARTBEAT_TTL_SECONDS
+func (r *redisManager) GetWorkerHeartbeat(email string) (time.Time, error) {
+	val, err := r.Get(workerHeartbeatKey(email))
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	unix, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(unix, 0), nil
+}
+
+// Refresh tokens are stored by their hash, so a leaked keyspace can't be used to log in
+func refreshTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// A session, every refresh token issued by rotating its predecessor is in the same family
+type RefreshToken struct {
+	Email    string    `json:"email"`
+	Family   string    `json:"family"`
+	IssuedAt time.Time `json:"issuedAt"`
+	// Whether the session was signed in with a second factor, its access tokens say so too
+	TwoFactor bool `json:"twoFactor,omitempty"`
+}
+
+var (
+	ErrRefreshTokenInvalid = errors.New("invalid refresh token")
+	// A rotated token was used again, so it may have been stolen and its whole family is revoked
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// The family key points at the current token, so the family can be revoked without knowing it
+func (r *redisManager) SetRefreshToken(token string, refreshToken RefreshToken) error {
+	b, err := json.Marshal(refreshToken)
+	if err != nil {
+		return err
+	}
+	hash := refreshTokenHash(token)
+	ttl := config.REFRESH_TOKEN_VALID_DAYS * 24 * time.Hour
+	_, err = r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, fmt.Sprintf("refreshtoken:%s", hash), string(b), ttl)
+		pipe.Set(ctx, fmt.Sprintf("refreshfamily:%s", refreshToken.Family), hash, ttl)
+		return nil
+	})
+	return err
+}
+
+// ConsumeRefreshToken returns the session of a token and invalidates it, the caller issues its successor with SetRefreshToken
+func (r *redisManager) ConsumeRefreshToken(token string) (*RefreshToken, error) {
+	hash := refreshTokenHash(token)
+	raw, err := r.Client.GetDel(ctx, fmt.Sprintf("refreshtoken:%s", hash)).Result()
+	if errors.Is(err, redis.Nil) {
+		family, err := r.Get(fmt.Sprintf("refreshused:%s", hash))
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrRefreshTokenInvalid
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := r.RevokeRefreshTokenFamily(family); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, err
+	}
+	var refreshToken RefreshToken
+	if err := json.Unmarshal([]byte(raw), &refreshToken); err != nil {
+		return nil, err
+	}
+	if err := r.Set(fmt.Sprintf("refreshused:%s", hash), refreshToken.Family, config.REFRESH_TOKEN_VALID_DAYS*24*time.Hour); err != nil {
+		return nil, err
+	}
+	return &refreshToken, nil
+}
+
+// Ends a session, its access tokens stay valid until they expire
+func (r *redisManager) RevokeRefreshTokenFamily(family string) error {
+	hash, err := r.Get(fmt.Sprintf("refreshfamily:%s", family))
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = r.Client.Del(ctx, fmt.Sprintf("refreshtoken:%s", hash), fmt.Sprintf("refreshfamily:%s", family)).Result()
+	return err
+}
+
+// Consecutive requests above the network difficulty
+func excessDifficultyKey(userID uuid.UUID) string {
+	return fmt.Sprintf("excessdifficulty:%s", userID.String())
+}
+
+// The streak is forgotten DIFFICULTY_ADVISORY_TTL_HOURS after the request at now
+func (r *redisManager) IncrementExcessDifficulty(userID uuid.UUID, now time.Time) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, excessDifficultyKey(userID))
+		pipe.ExpireAt(ctx, excessDifficultyKey(userID), now.Add(config.DIFFICULTY_ADVISORY_TTL_HOURS*time.Hour))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (r *redisManager) ResetExcessDifficulty(userID uuid.UUID) (int64, error) {
+	return r.Del(excessDifficultyKey(userID))
+}
+
+// Requests of a work source on a UTC day, for its daily quota
+func sourceRequestsKey(sourceID uuid.UUID, day time.Time) string {
+	return fmt.Sprintf("sourcerequests:%s:%s", sourceID.String(), day.Format("2006-01-02"))
+}
+
+// Counts a request of the source, returns the requests of the day so far including it
+func (r *redisManager) IncrementSourceRequests(sourceID uuid.UUID, now time.Time) (int64, error) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	var incr *redis.IntCmd
+	_, err := r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, sourceRequestsKey(sourceID, day))
+		// Kept a little past the end of the day, for requests that straddle midnight
+		pipe.Expire(ctx, sourceRequestsKey(sourceID, day), day.Add(25*time.Hour).Sub(now))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// Zero if the source made no requests on the UTC day of now
+func (r *redisManager) GetSourceRequests(sourceID uuid.UUID, now time.Time) (int64, error) {
+	val, err := r.Get(sourceRequestsKey(sourceID, now.UTC().Truncate(24*time.Hour)))
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+
+// Set while a requester's priority boost runs, so work requests don't have to look it up in postgres
+func priorityBoostKey(userID uuid.UUID) string {
+	return fmt.Sprintf("priorityboost:%s", userID.String())
 }
 
-func (r *redisManager) GetCachedWork(hash string) (string, error) {
-	return r.Get(fmt.Sprintf("cache:%s", hash))
+func (r *redisManager) SetPriorityBoost(userID uuid.UUID, until time.Time) error {
+	return r.Client.Set(ctx, priorityBoostKey(userID), until.UTC().Format(time.RFC3339), time.Until(until)).Err()
+}
+
+// False when the requester has no boost running or redis is unreachable
+func (r *redisManager) HasPriorityBoost(userID uuid.UUID) bool {
+	n, err := r.Client.Exists(ctx, priorityBoostKey(userID)).Result()
+	return err == nil && n > 0
+}
+
+// Balance of a tenant's payout wallet, recorded by moneybags after sending payments
+type PrizePoolBalance struct {
+	// In raw, as the node reports it
+	Raw       string    `json:"raw"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+func (r *redisManager) SetPrizePoolBalance(tenantID string, balance PrizePoolBalance) error {
+	b, err := json.Marshal(balance)
+	if err != nil {
+		return err
+	}
+	return r.Hset("prizepoolbalances", tenantID, string(b))
+}
+
+func (r *redisManager) GetPrizePoolBalance(tenantID string) (*PrizePoolBalance, error) {
+	raw, err := r.Hget("prizepoolbalances", tenantID)
+	if err != nil {
+		return nil, err
+	}
+	var balance PrizePoolBalance
+	if err := json.Unmarshal([]byte(raw), &balance); err != nil {
+		return nil, err
+	}
+	// Balances recorded before they were kept in raw are treated as unknown until the next payout run
+	if _, err := number.RawToBigInt(balance.Raw); err != nil {
+		return nil, err
+	}
+	return &balance, nil
 }
 
 // Client scoring
@@ -233,7 +736,8 @@ func (r *redisManager) GetClientScore(ip string) int {
 	return scoreInt
 }
 
-func (r *redisManager) FilterOverperformingClients() ([]string, error) {
+// Clients whose score makes up at least share (0-1) of the total
+func (r *redisManager) FilterOverperformingClients(share float64) ([]string, error) {
 	ret, err := r.Hgetall("clientscores")
 	if err != nil {
 		return nil, err
@@ -247,14 +751,14 @@ func (r *redisManager) FilterOverperformingClients() ([]string, error) {
 		totalScore += scoreInt
 	}
 
-	// Remove clients whos score makes up 15% or more of total
+	// Remove clients whos score makes up share or more of total
 	var toBroadcast []string
 	for ip, score := range ret {
 		score, err := strconv.Atoi(score)
 		if err != nil {
 			score = 0
 		}
-		if float64(score)/float64(totalScore) >= 0.15 {
+		if float64(score)/float64(totalScore) >= share {
 			toBroadcast = append(toBroadcast, ip)
 		}
 	}
@@ -264,3 +768,246 @@ func (r *redisManager) FilterOverperformingClients() ([]string, error) {
 func (r *redisManager) WipeClientScores() (int64, error) {
 	return r.Del("clientscores")
 }
+
+// Request sampling, shared by all servers so it applies whichever one handles the request
+const requestSamplingKey = "requestsampling"
+const requestSamplesKey = "requestsamples"
+
+func (r *redisManager) SetRequestSampling(samplingConfig sampling.Config) error {
+	b, err := json.Marshal(samplingConfig)
+	if err != nil {
+		return err
+	}
+	return r.Set(requestSamplingKey, string(b), time.Until(samplingConfig.Until))
+}
+
+// GetRequestSampling returns nil if sampling is off
+func (r *redisManager) GetRequestSampling() (*sampling.Config, error) {
+	raw, err := r.Get(requestSamplingKey)
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var samplingConfig sampling.Config
+	if err := json.Unmarshal([]byte(raw), &samplingConfig); err != nil {
+		return nil, err
+	}
+	return &samplingConfig, nil
+}
+
+func (r *redisManager) DeleteRequestSampling() (int64, error) {
+	return r.Del(requestSamplingKey)
+}
+
+// Load shedding, shared by all servers so requests are shed whichever one handles them
+const loadSheddingKey = "loadshedding"
+
+func (r *redisManager) SetLoadShedding(sheddingConfig shedding.Config) error {
+	b, err := json.Marshal(sheddingConfig)
+	if err != nil {
+		return err
+	}
+	return r.Set(loadSheddingKey, string(b), time.Until(sheddingConfig.Until))
+}
+
+// GetLoadShedding returns nil if no load is shed
+func (r *redisManager) GetLoadShedding() (*shedding.Config, error) {
+	raw, err := r.Get(loadSheddingKey)
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sheddingConfig shedding.Config
+	if err := json.Unmarshal([]byte(raw), &sheddingConfig); err != nil {
+		return nil, err
+	}
+	return &sheddingConfig, nil
+}
+
+func (r *redisManager) DeleteLoadShedding() (int64, error) {
+	return r.Del(loadSheddingKey)
+}
+
+// Samples are scored by time, older ones than the retention window and the oldest above the maximum are dropped
+func (r *redisManager) AddRequestSample(sample sampling.Sample) error {
+	b, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	retention := config.REQUEST_SAMPLE_RETENTION_HOURS * time.Hour
+	_, err = r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, requestSamplesKey, redis.Z{Score: float64(sample.CreatedAt.UnixMilli()), Member: string(b)})
+		pipe.ZRemRangeByScore(ctx, requestSamplesKey, "-inf", fmt.Sprintf("(%d", sample.CreatedAt.Add(-retention).UnixMilli()))
+		pipe.ZRemRangeByRank(ctx, requestSamplesKey, 0, -config.REQUEST_SAMPLES_MAX-1)
+		pipe.Expire(ctx, requestSamplesKey, retention)
+		return nil
+	})
+	return err
+}
+
+// GetRequestSamples returns the newest samples first, of one user if userEmail isn't empty
+// There are at most REQUEST_SAMPLES_MAX, so they're paged in memory
+func (r *redisManager) GetRequestSamples(userEmail string) ([]sampling.Sample, error) {
+	raw, err := r.Client.ZRevRange(ctx, requestSamplesKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	ret := []sampling.Sample{}
+	for _, member := range raw {
+		var sample sampling.Sample
+		if err := json.Unmarshal([]byte(member), &sample); err != nil {
+			logging.Warningf(logging.Redis, "Skipping malformed request sample %v", err)
+			continue
+		}
+		if userEmail != "" && !strings.EqualFold(sample.UserEmail, userEmail) {
+			continue
+		}
+		ret = append(ret, sample)
+	}
+	return ret, nil
+}
+
+// A frontier a requester wants precached by idle workers
+type FrontierTask struct {
+	Hash                 string `json:"hash"`
+	DifficultyMultiplier int    `json:"difficulty_multiplier"`
+	RequesterEmail       string `json:"requester_email"`
+}
+
+// Frontier pools are scored by when the frontier was registered, so the oldest are precached first
+func frontierPoolKey(tenantID string) string {
+	return fmt.Sprintf("frontierpool:%s", tenantID)
+}
+
+// AddFrontiers registers frontiers for idle precaching and returns the size of the pool
+func (r *redisManager) AddFrontiers(tenantID string, tasks []FrontierTask, now time.Time) (int64, error) {
+	members := make([]redis.Z, len(tasks))
+	for i, task := range tasks {
+		b, err := json.Marshal(task)
+		if err != nil {
+			return 0, err
+		}
+		members[i] = redis.Z{Score: float64(now.Unix()), Member: string(b)}
+	}
+	var size *redis.IntCmd
+	_, err := r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, frontierPoolKey(tenantID), members...)
+		// Keep the newest, older frontiers are the likeliest to be stale
+		pipe.ZRemRangeByRank(ctx, frontierPoolKey(tenantID), 0, -config.FRONTIER_POOL_MAX-1)
+		pipe.Expire(ctx, frontierPoolKey(tenantID), config.FRONTIER_POOL_TTL_HOURS*time.Hour)
+		size = pipe.ZCard(ctx, frontierPoolKey(tenantID))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return size.Val(), nil
+}
+
+// PopFrontier takes the oldest frontier that isn't stale out of the pool, nil if the pool is empty
+func (r *redisManager) PopFrontier(tenantID string, now time.Time) (*FrontierTask, error) {
+	staleBefore := now.Add(-config.FRONTIER_POOL_TTL_HOURS * time.Hour).Unix()
+	for {
+		popped, err := r.Client.ZPopMin(ctx, frontierPoolKey(tenantID), 1).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(popped) == 0 {
+			return nil, nil
+		}
+		if int64(popped[0].Score) < staleBefore {
+			continue
+		}
+		var task FrontierTask
+		if err := json.Unmarshal([]byte(popped[0].Member.(string)), &task); err != nil {
+			logging.Warningf(logging.Redis, "Skipping malformed frontier %v", err)
+			continue
+		}
+		return &task, nil
+	}
+}
+
+// Requests per location for a UTC day
+func geoRequestsKey(tenantID string, day time.Time) string {
+	return fmt.Sprintf("georequests:%s:%s", tenantID, day.UTC().Format("2006-01-02"))
+}
+
+// IncrementGeoRequests counts a work request from location (a geo.Location key) on the day of now
+func (r *redisManager) IncrementGeoRequests(tenantID string, location string, now time.Time) error {
+	key := geoRequestsKey(tenantID, now)
+	_, err := r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, key, location, 1)
+		pipe.Expire(ctx, key, config.GEO_REQUESTS_RETENTION_DAYS*24*time.Hour)
+		return nil
+	})
+	return err
+}
+
+// GetGeoRequests sums the requests per location of the days from since through now
+func (r *redisManager) GetGeoRequests(tenantID string, since time.Time, now time.Time) (map[string]int64, error) {
+	counts := map[string]int64{}
+	for day := since.UTC().Truncate(24 * time.Hour); !day.After(now); day = day.Add(24 * time.Hour) {
+		daily, err := r.Client.HGetAll(ctx, geoRequestsKey(tenantID, day)).Result()
+		if err != nil {
+			return nil, err
+		}
+		for country, count := range daily {
+			n, err := strconv.ParseInt(count, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			counts[country] += n
+		}
+	}
+	return counts, nil
+}
+
+// Sliding window of the requests of a rate limited client, scored by when they were made
+func rateLimitWindowKey(key string) string {
+	return fmt.Sprintf("ratelimit:%s", key)
+}
+
+// A request counted against a sliding window
+type RateLimitWindow struct {
+	Allowed bool
+	// Requests in the window, including this one if it was allowed
+	Count int64
+	// When the oldest request in the window leaves it
+	Reset time.Time
+}
+
+// RecordRequest counts a request at now against the limit of key's window
+// Rejected requests aren't counted, so clients that keep retrying aren't locked out for longer
+func (r *redisManager) RecordRequest(key string, limit int, window time.Duration, now time.Time) (*RateLimitWindow, error) {
+	redisKey := rateLimitWindowKey(key)
+	member := fmt.Sprintf("%d:%s", now.UnixMicro(), uuid.NewString())
+	var count *redis.IntCmd
+	var oldest *redis.ZSliceCmd
+	_, err := r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, redisKey, "-inf", strconv.FormatInt(now.Add(-window).UnixMicro(), 10))
+		pipe.ZAdd(ctx, redisKey, redis.Z{Score: float64(now.UnixMicro()), Member: member})
+		count = pipe.ZCard(ctx, redisKey)
+		oldest = pipe.ZRangeWithScores(ctx, redisKey, 0, 0)
+		pipe.PExpire(ctx, redisKey, window)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := &RateLimitWindow{Allowed: true, Count: count.Val(), Reset: now.Add(window)}
+	if len(oldest.Val()) > 0 {
+		ret.Reset = time.UnixMicro(int64(oldest.Val()[0].Score)).UTC().Add(window)
+	}
+	if ret.Count > int64(limit) {
+		if err := r.Client.ZRem(ctx, redisKey, member).Err(); err != nil {
+			return nil, err
+		}
+		ret.Allowed = false
+		ret.Count--
+	}
+	return ret, nil
+}
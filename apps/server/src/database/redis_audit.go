@@ -0,0 +1,117 @@
+package database
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"golang.org/x/exp/slices"
+)
+
+// How long keys under each prefix are supposed to live, anything under these prefixes without a TTL leaked
+var expectedKeyTTLs = map[string]time.Duration{
+	"emailconfirmation:":       config.EMAIL_CONFIRMATION_TOKEN_VALID_MINUTES * time.Minute,
+	"passwordreset:":           config.EMAIL_CONFIRMATION_TOKEN_VALID_MINUTES * time.Minute,
+	"approveservice:":          336 * time.Hour,
+	"cache:":                   5 * time.Minute,
+	"idempotency:":             config.IDEMPOTENCY_KEY_TTL_HOURS * time.Hour,
+	"challenge:":               config.POW_CHALLENGE_VALID_MINUTES * time.Minute,
+	"service_stats:":           time.Minute,
+	"top10_result:":            time.Hour,
+	"difficulty_distribution:": 5 * time.Minute,
+	"pendingawards:":           config.PENDING_AWARD_TTL_HOURS * time.Hour,
+	"excessdifficulty:":        config.DIFFICULTY_ADVISORY_TTL_HOURS * time.Hour,
+	"requestsampling":          config.REQUEST_SAMPLING_MAX_MINUTES * time.Minute,
+	"requestsamples":           config.REQUEST_SAMPLE_RETENTION_HOURS * time.Hour,
+	"frontierpool:":            config.FRONTIER_POOL_TTL_HOURS * time.Hour,
+	"georequests:":             config.GEO_REQUESTS_RETENTION_DAYS * 24 * time.Hour,
+	"heartbeat:":               config.WORKER_HEARTBEAT_TTL_SECONDS * time.Second,
+	"refreshtoken:":            config.REFRESH_TOKEN_VALID_DAYS * 24 * time.Hour,
+	"refreshused:":             config.REFRESH_TOKEN_VALID_DAYS * 24 * time.Hour,
+	"refreshfamily:":           config.REFRESH_TOKEN_VALID_DAYS * 24 * time.Hour,
+}
+
+// Keys that are meant to live forever
+var persistentKeys = []string{"clients", "servicetokens", "clientscores", "prizepoolbalances", "client_replicas", countersIntactKey}
+
+type RedisAuditReport struct {
+	Scanned int
+	// Keys without a TTL that should have one
+	MissingTTL []string
+	// Keys without a TTL that we don't know about
+	Unknown []string
+	// Connected client entries for clients that aren't connected
+	OrphanedClients []string
+}
+
+func expectedKeyTTL(key string) (time.Duration, bool) {
+	for prefix, ttl := range expectedKeyTTLs {
+		if strings.HasPrefix(key, prefix) {
+			return ttl, true
+		}
+	}
+	return 0, false
+}
+
+// AuditKeys scans the keyspace for keys that leaked, and expires them if fix is set
+// connectedClients are the IPs of clients connected to the hub, nil skips the check for orphaned client entries
+func (r *redisManager) AuditKeys(connectedClients []string, fix bool) (*RedisAuditReport, error) {
+	report := &RedisAuditReport{}
+	iter := r.Client.Scan(ctx, 0, "*", 1000).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		report.Scanned++
+		ttl, err := r.Client.TTL(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		// -1 means the key exists without an expiry, -2 that it's gone since we scanned it
+		if ttl != -1 {
+			continue
+		}
+		expected, known := expectedKeyTTL(key)
+		if !known {
+			if !slices.Contains(persistentKeys, key) {
+				report.Unknown = append(report.Unknown, key)
+			}
+			continue
+		}
+		report.MissingTTL = append(report.MissingTTL, key)
+		if fix {
+			if err := r.Client.Expire(ctx, key, expected).Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	if connectedClients != nil {
+		clients, err := r.Hgetall("clients")
+		if err != nil {
+			return nil, err
+		}
+		connected := make(map[string]bool, len(connectedClients))
+		for _, ip := range connectedClients {
+			connected[ip] = true
+		}
+		for ip := range clients {
+			if connected[ip] {
+				continue
+			}
+			report.OrphanedClients = append(report.OrphanedClients, ip)
+			if fix {
+				if err := r.RemoveConnectedClient(ip); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if len(report.MissingTTL) > 0 || len(report.Unknown) > 0 || len(report.OrphanedClients) > 0 {
+		logging.Warningf(logging.Redis, "Redis audit: scanned %d keys, %d missing a TTL, %d unknown without a TTL, %d orphaned clients", report.Scanned, len(report.MissingTTL), len(report.Unknown), len(report.OrphanedClients))
+	}
+	return report, nil
+}
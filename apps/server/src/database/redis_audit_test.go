@@ -0,0 +1,44 @@
+package database
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestAuditKeys(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+
+	redis := GetRedisDB()
+	redis.Client.FlushAll(ctx)
+	defer redis.Client.FlushAll(ctx)
+
+	// A token that should expire, one that does and a key we don't know
+	redis.Set("emailconfirmation:leaked@gmail.com", "token", 0)
+	redis.SetConfirmationToken("fine@gmail.com", "token")
+	redis.Set("mystery", "value", 0)
+	redis.AddConnectedClient("1.1.1.1", "default")
+	redis.AddConnectedClient("2.2.2.2", "default")
+
+	report, err := redis.AuditKeys([]string{"1.1.1.1"}, false)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 4, report.Scanned)
+	utils.AssertEqual(t, []string{"emailconfirmation:leaked@gmail.com"}, report.MissingTTL)
+	utils.AssertEqual(t, []string{"mystery"}, report.Unknown)
+	utils.AssertEqual(t, []string{"2.2.2.2"}, report.OrphanedClients)
+
+	// Fixing gives leaked keys their TTL and drops orphaned clients
+	_, err = redis.AuditKeys([]string{"1.1.1.1"}, true)
+	utils.AssertEqual(t, nil, err)
+	ttl, _ := redis.Client.TTL(ctx, "emailconfirmation:leaked@gmail.com").Result()
+	utils.AssertEqual(t, true, ttl > 0 && ttl <= 180*time.Minute)
+	count, _ := redis.GetNumberConnectedClients()
+	utils.AssertEqual(t, int64(1), count)
+
+	report, err = redis.AuditKeys(nil, false)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(report.MissingTTL))
+	utils.AssertEqual(t, 0, len(report.OrphanedClients))
+}
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/metrics"
+	"github.com/go-redis/redis/v9"
+)
+
+// Payout points and prize pool balances, losing them to eviction changes what workers are paid
+var payoutCounterKeys = []string{"clientscores", "prizepoolbalances"}
+
+// Prefixes past the top ones are added up under this
+const otherMemoryPrefix = "other"
+
+// How much memory redis uses and what for
+type RedisMemoryReport struct {
+	CheckedAt time.Time
+	UsedBytes int64
+	// maxmemory, 0 without a limit
+	MaxBytes       int64
+	EvictionPolicy string
+	// Largest first
+	Prefixes []RedisPrefixMemory
+}
+
+type RedisPrefixMemory struct {
+	Prefix string
+	Keys   int
+	Bytes  int64
+}
+
+// Share of maxmemory in use, 0 without a limit
+func (m *RedisMemoryReport) UsedPercent() float64 {
+	if m.MaxBytes <= 0 {
+		return 0
+	}
+	return float64(m.UsedBytes) / float64(m.MaxBytes) * 100
+}
+
+// Whether redis may evict keys without a TTL once it's full, like the payout counters
+// volatile-* policies only evict keys with a TTL and noeviction fails writes instead
+func (m *RedisMemoryReport) CountersEvictable() bool {
+	return m.MaxBytes > 0 && strings.HasPrefix(m.EvictionPolicy, "allkeys-")
+}
+
+// What an admin should fix, empty if nothing
+func (m *RedisMemoryReport) Warnings() []string {
+	warnings := []string{}
+	if percent := m.UsedPercent(); percent >= config.REDIS_MEMORY_WARN_PERCENT {
+		warnings = append(warnings, fmt.Sprintf("redis uses %.0f%% of its %d MB maxmemory", percent, m.MaxBytes>>20))
+	}
+	if m.CountersEvictable() {
+		warnings = append(warnings, fmt.Sprintf("maxmemory-policy %s can evict the payout counters %s, use noeviction or a volatile-* policy", m.EvictionPolicy, strings.Join(payoutCounterKeys, " and ")))
+	}
+	return warnings
+}
+
+// The part of a key up to its first colon, the whole key if it has none
+func memoryPrefix(key string) string {
+	if i := strings.Index(key, ":"); i >= 0 {
+		return key[:i+1]
+	}
+	return key
+}
+
+// The fields of an INFO reply
+func parseRedisInfo(info string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// The largest top prefixes, and the rest added up as other
+func topPrefixes(usage map[string]*RedisPrefixMemory, top int) []RedisPrefixMemory {
+	prefixes := make([]RedisPrefixMemory, 0, len(usage))
+	for _, prefix := range usage {
+		prefixes = append(prefixes, *prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		if prefixes[i].Bytes != prefixes[j].Bytes {
+			return prefixes[i].Bytes > prefixes[j].Bytes
+		}
+		return prefixes[i].Prefix < prefixes[j].Prefix
+	})
+	if len(prefixes) <= top {
+		return prefixes
+	}
+	other := RedisPrefixMemory{Prefix: otherMemoryPrefix}
+	for _, prefix := range prefixes[top:] {
+		other.Keys += prefix.Keys
+		other.Bytes += prefix.Bytes
+	}
+	return append(prefixes[:top], other)
+}
+
+// The report of the last CheckMemory, nil before the first one
+func (r *redisManager) LastMemoryReport() *RedisMemoryReport {
+	return r.memory.Load()
+}
+
+// CheckMemory measures the memory of every key by prefix, publishes it as metrics and logs what needs fixing
+func (r *redisManager) CheckMemory(now time.Time) (*RedisMemoryReport, error) {
+	info, err := r.Client.Info(ctx, "memory").Result()
+	if err != nil {
+		return nil, err
+	}
+	fields := parseRedisInfo(info)
+	report := &RedisMemoryReport{CheckedAt: now, EvictionPolicy: fields["maxmemory_policy"]}
+	if report.UsedBytes, err = strconv.ParseInt(fields["used_memory"], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid used_memory %q", fields["used_memory"])
+	}
+	// Missing when redis has no limit
+	report.MaxBytes, _ = strconv.ParseInt(fields["maxmemory"], 10, 64)
+
+	usage := make(map[string]*RedisPrefixMemory)
+	measure := func(keys []string) error {
+		if len(keys) == 0 {
+			return nil
+		}
+		pipe := r.Client.Pipeline()
+		cmds := make([]*redis.IntCmd, len(keys))
+		for i, key := range keys {
+			cmds[i] = pipe.MemoryUsage(ctx, key)
+		}
+		// Keys that expired since they were scanned are nil
+		if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		for i, key := range keys {
+			bytes, err := cmds[i].Result()
+			if err != nil {
+				continue
+			}
+			prefix := memoryPrefix(key)
+			if usage[prefix] == nil {
+				usage[prefix] = &RedisPrefixMemory{Prefix: prefix}
+			}
+			usage[prefix].Keys++
+			usage[prefix].Bytes += bytes
+		}
+		return nil
+	}
+	var keys []string
+	iter := r.Client.Scan(ctx, 0, "*", 1000).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == 1000 {
+			if err := measure(keys); err != nil {
+				return nil, err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	if err := measure(keys); err != nil {
+		return nil, err
+	}
+	report.Prefixes = topPrefixes(usage, config.REDIS_MEMORY_TOP_PREFIXES)
+	r.memory.Store(report)
+
+	prefixes := make(map[string]metrics.RedisPrefixUsage, len(report.Prefixes))
+	for _, prefix := range report.Prefixes {
+		prefixes[prefix.Prefix] = metrics.RedisPrefixUsage{Keys: prefix.Keys, Bytes: prefix.Bytes}
+	}
+	metrics.SetRedisMemory(report.UsedBytes, report.MaxBytes, report.CountersEvictable(), prefixes)
+	for _, warning := range report.Warnings() {
+		logging.Errorf(logging.Redis, "🚨 %s", warning)
+	}
+	return report, nil
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestParseRedisInfo(t *testing.T) {
+	fields := parseRedisInfo("# Memory\r\nused_memory:1048576\r\nused_memory_human:1.00M\r\nmaxmemory:0\r\nmaxmemory_policy:noeviction\r\n")
+	utils.AssertEqual(t, "1048576", fields["used_memory"])
+	utils.AssertEqual(t, "0", fields["maxmemory"])
+	utils.AssertEqual(t, "noeviction", fields["maxmemory_policy"])
+	utils.AssertEqual(t, 4, len(fields))
+}
+
+func TestMemoryPrefixes(t *testing.T) {
+	utils.AssertEqual(t, "cache:", memoryPrefix("cache:default:abc"))
+	utils.AssertEqual(t, "clientscores", memoryPrefix("clientscores"))
+
+	usage := map[string]*RedisPrefixMemory{
+		"cache:":       {Prefix: "cache:", Keys: 10, Bytes: 1000},
+		"clientscores": {Prefix: "clientscores", Keys: 1, Bytes: 5000},
+		"heartbeat:":   {Prefix: "heartbeat:", Keys: 4, Bytes: 200},
+		"challenge:":   {Prefix: "challenge:", Keys: 2, Bytes: 100},
+	}
+	utils.AssertEqual(t, []RedisPrefixMemory{
+		{Prefix: "clientscores", Keys: 1, Bytes: 5000},
+		{Prefix: "cache:", Keys: 10, Bytes: 1000},
+		{Prefix: "other", Keys: 6, Bytes: 300},
+	}, topPrefixes(usage, 2))
+	utils.AssertEqual(t, 4, len(topPrefixes(usage, 4)))
+}
+
+func TestRedisMemoryWarnings(t *testing.T) {
+	// Without a limit nothing is evicted
+	report := &RedisMemoryReport{UsedBytes: 900 << 20, EvictionPolicy: "allkeys-lru"}
+	utils.AssertEqual(t, 0.0, report.UsedPercent())
+	utils.AssertEqual(t, false, report.CountersEvictable())
+	utils.AssertEqual(t, 0, len(report.Warnings()))
+
+	report.MaxBytes = 1000 << 20
+	utils.AssertEqual(t, true, report.CountersEvictable())
+	utils.AssertEqual(t, []string{
+		"redis uses 90% of its 1000 MB maxmemory",
+		"maxmemory-policy allkeys-lru can evict the payout counters clientscores and prizepoolbalances, use noeviction or a volatile-* policy",
+	}, report.Warnings())
+
+	// The counters don't expire, so volatile policies never evict them
+	report.EvictionPolicy = "volatile-lru"
+	report.UsedBytes = 500 << 20
+	utils.AssertEqual(t, 0, len(report.Warnings()))
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/go-redis/redis/v9"
+)
+
+// Volatile counters that are copied into postgres, so losing redis costs at most one snapshot interval of them
+var snapshotKeyPatterns = []string{"clientscores", "prizepoolbalances", "georequests:*", "sourcerequests:*"}
+
+// Set once the counters in redis are complete, it's gone after redis lost its data
+const countersIntactKey = "countersintact"
+
+// False if redis lost its data since the counters were last restored
+func (r *redisManager) CountersIntact() (bool, error) {
+	n, err := r.Client.Exists(ctx, countersIntactKey).Result()
+	return n > 0, err
+}
+
+func (r *redisManager) MarkCountersIntact() error {
+	return r.Set(countersIntactKey, "1", 0)
+}
+
+func (r *redisManager) snapshotKeys() ([]string, error) {
+	keys := []string{}
+	for _, pattern := range snapshotKeyPatterns {
+		if !strings.Contains(pattern, "*") {
+			keys = append(keys, pattern)
+			continue
+		}
+		iter := r.Client.Scan(ctx, 0, pattern, 1000).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// ReadCounters copies the volatile counters, with when they expire as of now
+func (r *redisManager) ReadCounters(now time.Time) ([]models.CounterValue, error) {
+	keys, err := r.snapshotKeys()
+	if err != nil {
+		return nil, err
+	}
+	values := []models.CounterValue{}
+	for _, key := range keys {
+		keyType, err := r.Client.Type(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		ttl, err := r.Client.PTTL(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		var expiresAt *time.Time
+		if ttl > 0 {
+			at := now.Add(ttl)
+			expiresAt = &at
+		}
+		switch keyType {
+		case "hash":
+			fields, err := r.Client.HGetAll(ctx, key).Result()
+			if err != nil {
+				return nil, err
+			}
+			for field, value := range fields {
+				values = append(values, models.CounterValue{Key: key, Field: field, Value: value, ExpiresAt: expiresAt})
+			}
+		case "string":
+			value, err := r.Client.Get(ctx, key).Result()
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, models.CounterValue{Key: key, Value: value, ExpiresAt: expiresAt})
+		}
+	}
+	return values, nil
+}
+
+// RestoreCounters puts back the counters redis doesn't have, counters it still has are newer and left alone
+// Returns the number of keys restored
+func (r *redisManager) RestoreCounters(values []models.CounterValue, now time.Time) (int, error) {
+	byKey := map[string][]models.CounterValue{}
+	order := []string{}
+	for _, value := range values {
+		if value.ExpiresAt != nil && !value.ExpiresAt.After(now) {
+			continue
+		}
+		if _, ok := byKey[value.Key]; !ok {
+			order = append(order, value.Key)
+		}
+		byKey[value.Key] = append(byKey[value.Key], value)
+	}
+	restored := 0
+	for _, key := range order {
+		n, err := r.Client.Exists(ctx, key).Result()
+		if err != nil {
+			return restored, err
+		}
+		if n > 0 {
+			continue
+		}
+		_, err = r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, value := range byKey[key] {
+				if value.Field == "" {
+					pipe.Set(ctx, key, value.Value, 0)
+				} else {
+					pipe.HSet(ctx, key, value.Field, value.Value)
+				}
+			}
+			// Relative, so it works with any clock
+			if expiresAt := byKey[key][0].ExpiresAt; expiresAt != nil {
+				pipe.Expire(ctx, key, expiresAt.Sub(now))
+			}
+			return nil
+		})
+		if err != nil {
+			return restored, err
+		}
+		restored++
+	}
+	return restored, nil
+}
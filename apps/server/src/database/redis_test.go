@@ -3,8 +3,15 @@ package database
 import (
 	"os"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/sampling"
+	"github.com/bananocoin/boompow/apps/server/src/shedding"
 	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/go-redis/redis/v9"
 	"github.com/google/uuid"
 )
 
@@ -34,7 +41,7 @@ func TestRedis(t *testing.T) {
 	utils.AssertEqual(t, int64(1), ret)
 
 	// Connected clients bits
-	if err := redis.AddConnectedClient("1"); err != nil {
+	if err := redis.AddConnectedClient("1", "default"); err != nil {
 		t.Errorf("Error adding client: %s", err)
 	}
 	ret, err = redis.GetNumberConnectedClients()
@@ -47,23 +54,48 @@ func TestRedis(t *testing.T) {
 	utils.AssertEqual(t, nil, err)
 	utils.AssertEqual(t, int64(0), ret)
 	// Add a couple clients
-	if err := redis.AddConnectedClient("1"); err != nil {
+	if err := redis.AddConnectedClient("1", "default"); err != nil {
 		t.Errorf("Error adding client: %s", err)
 	}
-	if err := redis.AddConnectedClient("2"); err != nil {
+	if err := redis.AddConnectedClient("2", "other"); err != nil {
 		t.Errorf("Error adding client: %s", err)
 	}
 	ret, err = redis.GetNumberConnectedClients()
 	utils.AssertEqual(t, nil, err)
 	utils.AssertEqual(t, int64(2), ret)
+	ret, err = redis.GetNumberConnectedClientsForTenant("other")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int64(1), ret)
 	ret, err = redis.WipeAllConnectedClients()
 	utils.AssertEqual(t, nil, err)
 	ret, err = redis.GetNumberConnectedClients()
 	utils.AssertEqual(t, nil, err)
 	utils.AssertEqual(t, int64(0), ret)
 
-	// Service token bits
 	uid := uuid.New()
+
+	// Idempotency key bits
+	reserved, err := redis.ReserveIdempotencyKey(uid, "key", "hash:1")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, reserved)
+	reserved, err = redis.ReserveIdempotencyKey(uid, "key", "hash:1")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, reserved)
+	fingerprint, result, err := redis.GetIdempotentResult(uid, "key")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "hash:1", fingerprint)
+	utils.AssertEqual(t, "", result)
+	if err := redis.SetIdempotentResult(uid, "key", "hash:1", "work"); err != nil {
+		t.Errorf("Error setting idempotent result: %s", err)
+	}
+	_, result, err = redis.GetIdempotentResult(uid, "key")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "work", result)
+	ret, err = redis.ReleaseIdempotencyKey(uid, "key")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int64(1), ret)
+
+	// Service token bits
 	if err := redis.AddServiceToken(uid, "token"); err != nil {
 		t.Errorf("Error adding service token: %s", err)
 	}
@@ -76,4 +108,370 @@ func TestRedis(t *testing.T) {
 	tokenStr, err := redis.GetServiceTokenForUser(uid)
 	utils.AssertEqual(t, nil, err)
 	utils.AssertEqual(t, "token", tokenStr)
+	utils.AssertEqual(t, nil, redis.RemoveServiceToken("token"))
+	_, err = redis.GetServiceTokenForUser(uid)
+	utils.AssertEqual(t, true, err != nil)
+
+	// Excess difficulty bits
+	now := time.Now()
+	streak, err := redis.IncrementExcessDifficulty(uid, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int64(1), streak)
+	streak, err = redis.IncrementExcessDifficulty(uid, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int64(2), streak)
+	ret, err = redis.ResetExcessDifficulty(uid)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int64(1), ret)
+	streak, err = redis.IncrementExcessDifficulty(uid, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int64(1), streak)
+	// A request stamped a full TTL ago leaves a streak that is already forgotten
+	_, err = redis.IncrementExcessDifficulty(uid, now.Add(-24*time.Hour))
+	utils.AssertEqual(t, nil, err)
+	streak, err = redis.IncrementExcessDifficulty(uid, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int64(1), streak)
+}
+
+func TestPrizePoolBalance(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	redis := GetRedisDB()
+
+	_, err := redis.GetPrizePoolBalance("mypool")
+	utils.AssertNotEqual(t, nil, err)
+
+	checkedAt := time.Date(2022, 10, 1, 9, 0, 0, 0, time.UTC)
+	utils.AssertEqual(t, nil, redis.SetPrizePoolBalance("mypool", PrizePoolBalance{Raw: "123450000000000000000000000000000", CheckedAt: checkedAt}))
+	balance, err := redis.GetPrizePoolBalance("mypool")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "123450000000000000000000000000000", balance.Raw)
+	utils.AssertEqual(t, checkedAt, balance.CheckedAt)
+
+	// Recorded in banano by an older moneybags
+	redis.Hset("prizepoolbalances", "mypool", `{"banano":1234.5,"checked_at":"2022-10-01T09:00:00Z"}`)
+	_, err = redis.GetPrizePoolBalance("mypool")
+	utils.AssertNotEqual(t, nil, err)
+	redis.Hdel("prizepoolbalances", "mypool")
+}
+
+func TestRequestSampling(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	redis := GetRedisDB()
+
+	samplingConfig, err := redis.GetRequestSampling()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, (*sampling.Config)(nil), samplingConfig)
+
+	until := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+	utils.AssertEqual(t, nil, redis.SetRequestSampling(sampling.Config{UserEmail: "joe@example.com", Percent: 50, Until: until}))
+	samplingConfig, err = redis.GetRequestSampling()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, sampling.Config{UserEmail: "joe@example.com", Percent: 50, Until: until}, *samplingConfig)
+	redis.DeleteRequestSampling()
+
+	now := time.Now()
+	// Past the retention window, dropped with the next sample
+	utils.AssertEqual(t, nil, redis.AddRequestSample(sampling.Sample{ID: "old", UserEmail: "joe@example.com", CreatedAt: now.Add(-25 * time.Hour)}))
+	utils.AssertEqual(t, nil, redis.AddRequestSample(sampling.Sample{ID: "1", UserEmail: "joe@example.com", CreatedAt: now.Add(-time.Minute)}))
+	utils.AssertEqual(t, nil, redis.AddRequestSample(sampling.Sample{ID: "2", UserEmail: "jane@example.com", CreatedAt: now}))
+
+	samples, err := redis.GetRequestSamples("")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 2, len(samples))
+	utils.AssertEqual(t, "2", samples[0].ID)
+	samples, err = redis.GetRequestSamples("Joe@example.com")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(samples))
+	utils.AssertEqual(t, "1", samples[0].ID)
+	redis.Del(requestSamplesKey)
+}
+
+func TestLoadShedding(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	redis := GetRedisDB()
+
+	sheddingConfig, err := redis.GetLoadShedding()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, (*shedding.Config)(nil), sheddingConfig)
+
+	until := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+	shed := shedding.Config{Tiers: []shedding.Tier{shedding.TierStandard}, DifficultyClasses: []shedding.DifficultyClass{shedding.ClassPrecache}, Reason: "node outage", StartedBy: "admin@example.com", Until: until}
+	utils.AssertEqual(t, nil, redis.SetLoadShedding(shed))
+	sheddingConfig, err = redis.GetLoadShedding()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, shed, *sheddingConfig)
+
+	redis.DeleteLoadShedding()
+	sheddingConfig, _ = redis.GetLoadShedding()
+	utils.AssertEqual(t, (*shedding.Config)(nil), sheddingConfig)
+}
+
+func TestFrontierPool(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	redis := GetRedisDB()
+	now := time.Now()
+
+	task, err := redis.PopFrontier("mypool", now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, (*FrontierTask)(nil), task)
+
+	size, err := redis.AddFrontiers("mypool", []FrontierTask{{Hash: "OLD", DifficultyMultiplier: 1}}, now.Add(-25*time.Hour))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int64(1), size)
+	size, err = redis.AddFrontiers("mypool", []FrontierTask{{Hash: "A", DifficultyMultiplier: 1, RequesterEmail: "requester@example.com"}, {Hash: "B", DifficultyMultiplier: 64}}, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int64(3), size)
+
+	// Stale frontiers are skipped
+	task, err = redis.PopFrontier("mypool", now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "A", task.Hash)
+	utils.AssertEqual(t, "requester@example.com", task.RequesterEmail)
+	task, _ = redis.PopFrontier("mypool", now)
+	utils.AssertEqual(t, 64, task.DifficultyMultiplier)
+	task, _ = redis.PopFrontier("mypool", now)
+	utils.AssertEqual(t, (*FrontierTask)(nil), task)
+}
+
+func TestGeoRequests(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	redis := GetRedisDB()
+	now := time.Date(2022, 10, 3, 12, 0, 0, 0, time.UTC)
+
+	utils.AssertEqual(t, nil, redis.IncrementGeoRequests("geopool", "US", now))
+	utils.AssertEqual(t, nil, redis.IncrementGeoRequests("geopool", "US", now.Add(-24*time.Hour)))
+	utils.AssertEqual(t, nil, redis.IncrementGeoRequests("geopool", "DE", now.Add(-48*time.Hour)))
+	utils.AssertEqual(t, nil, redis.IncrementGeoRequests("otherpool", "US", now))
+
+	counts, err := redis.GetGeoRequests("geopool", now.Add(-24*time.Hour), now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, map[string]int64{"US": 2}, counts)
+	counts, err = redis.GetGeoRequests("geopool", now.Add(-48*time.Hour), now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, map[string]int64{"US": 2, "DE": 1}, counts)
+}
+
+func TestWorkerHeartbeat(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	redis := GetRedisDB()
+	now := time.Date(2022, 10, 3, 12, 0, 0, 0, time.UTC)
+
+	heartbeat, err := redis.GetWorkerHeartbeat("worker@example.com")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, heartbeat.IsZero())
+
+	utils.AssertEqual(t, nil, redis.RecordWorkerHeartbeat("Worker@example.com", now))
+	heartbeat, err = redis.GetWorkerHeartbeat("worker@example.com")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, now.Unix(), heartbeat.Unix())
+	ttl, _ := redis.Client.TTL(ctx, "heartbeat:worker@example.com").Result()
+	utils.AssertEqual(t, 120*time.Second, ttl)
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	redis := GetRedisDB()
+	session := RefreshToken{Email: "joe@example.com", Family: "family", IssuedAt: time.Date(2022, 10, 3, 12, 0, 0, 0, time.UTC)}
+
+	_, err := redis.ConsumeRefreshToken("unknown")
+	utils.AssertEqual(t, ErrRefreshTokenInvalid, err)
+
+	utils.AssertEqual(t, nil, redis.SetRefreshToken("first", session))
+	consumed, err := redis.ConsumeRefreshToken("first")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, session.Email, consumed.Email)
+	utils.AssertEqual(t, session.Family, consumed.Family)
+	utils.AssertEqual(t, true, session.IssuedAt.Equal(consumed.IssuedAt))
+	utils.AssertEqual(t, nil, redis.SetRefreshToken("second", session))
+
+	// Using a rotated token again revokes the token that replaced it
+	_, err = redis.ConsumeRefreshToken("first")
+	utils.AssertEqual(t, ErrRefreshTokenReused, err)
+	_, err = redis.ConsumeRefreshToken("second")
+	utils.AssertEqual(t, ErrRefreshTokenInvalid, err)
+}
+
+func TestResetPasswordTokenSingleUse(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	redis := GetRedisDB()
+
+	valid, err := redis.ResetPasswordTokenValid("reset@example.com", "resetpassword:first")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, valid)
+
+	// Only the latest link works
+	utils.AssertEqual(t, nil, redis.SetResetPasswordToken("reset@example.com", "resetpassword:first"))
+	utils.AssertEqual(t, nil, redis.SetResetPasswordToken("reset@example.com", "resetpassword:second"))
+	valid, _ = redis.ResetPasswordTokenValid("reset@example.com", "resetpassword:first")
+	utils.AssertEqual(t, false, valid)
+	valid, _ = redis.ResetPasswordTokenValid("reset@example.com", "resetpassword:second")
+	utils.AssertEqual(t, true, valid)
+
+	ttl, err := redis.ConsumeResetPasswordToken("reset@example.com", "resetpassword:second")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, ttl > 0)
+	_, err = redis.ConsumeResetPasswordToken("reset@example.com", "resetpassword:second")
+	utils.AssertEqual(t, ErrResetPasswordTokenInvalid, err)
+	valid, _ = redis.ResetPasswordTokenValid("reset@example.com", "resetpassword:second")
+	utils.AssertEqual(t, false, valid)
+
+	// Put back after a failed change, but never over a newer link
+	utils.AssertEqual(t, nil, redis.RestoreResetPasswordToken("reset@example.com", "resetpassword:second", ttl))
+	valid, _ = redis.ResetPasswordTokenValid("reset@example.com", "resetpassword:second")
+	utils.AssertEqual(t, true, valid)
+	utils.AssertEqual(t, nil, redis.SetResetPasswordToken("reset@example.com", "resetpassword:third"))
+	utils.AssertEqual(t, nil, redis.RestoreResetPasswordToken("reset@example.com", "resetpassword:second", ttl))
+	valid, _ = redis.ResetPasswordTokenValid("reset@example.com", "resetpassword:third")
+	utils.AssertEqual(t, true, valid)
+}
+
+func TestDeleteMatching(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+
+	redis := GetRedisDB()
+	redis.Set("difficulty_distribution:default:1", "a", 0)
+	redis.Set("difficulty_distribution:default:2", "b", 0)
+	redis.Set("difficulty_distribution:other:1", "c", 0)
+
+	deleted, err := redis.DeleteMatching("difficulty_distribution:default:*")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int64(2), deleted)
+	_, err = redis.Get("difficulty_distribution:other:1")
+	utils.AssertEqual(t, nil, err)
+	redis.Del("difficulty_distribution:other:1")
+}
+
+func TestCheckConnection(t *testing.T) {
+	mr := miniredis.RunT(t)
+	r := &redisManager{Client: redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})}
+	reconnects := 0
+	onReconnect := func() {
+		reconnects++
+	}
+
+	r.healthy.Store(true)
+	r.checkConnection(onReconnect)
+	utils.AssertEqual(t, true, r.Healthy())
+	// Staying connected isn't a reconnect
+	utils.AssertEqual(t, 0, reconnects)
+
+	mr.Close()
+	r.checkConnection(onReconnect)
+	utils.AssertEqual(t, false, r.Healthy())
+
+	utils.AssertEqual(t, nil, mr.Restart())
+	r.checkConnection(onReconnect)
+	utils.AssertEqual(t, true, r.Healthy())
+	utils.AssertEqual(t, 1, reconnects)
+}
+
+func TestRecordRequest(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	redis := GetRedisDB()
+	now := time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		window, err := redis.RecordRequest("user:alice@example.com", 2, time.Minute, now.Add(time.Duration(i)*time.Second))
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, true, window.Allowed)
+		utils.AssertEqual(t, int64(i+1), window.Count)
+		utils.AssertEqual(t, now.Add(time.Minute), window.Reset)
+	}
+	window, err := redis.RecordRequest("user:alice@example.com", 2, time.Minute, now.Add(30*time.Second))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, window.Allowed)
+	utils.AssertEqual(t, int64(2), window.Count)
+	// Other clients have their own window
+	window, _ = redis.RecordRequest("ip:127.0.0.1", 2, time.Minute, now.Add(30*time.Second))
+	utils.AssertEqual(t, true, window.Allowed)
+
+	// The first request left the window, the rejected one was never in it
+	window, _ = redis.RecordRequest("user:alice@example.com", 2, time.Minute, now.Add(60500*time.Millisecond))
+	utils.AssertEqual(t, true, window.Allowed)
+	utils.AssertEqual(t, int64(2), window.Count)
+	utils.AssertEqual(t, now.Add(61*time.Second), window.Reset)
+}
+
+func TestReplicaClients(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	redis := GetRedisDB()
+	redis.WipeAllConnectedClients()
+	redis.Del(clientReplicasKey)
+	defer redis.Del(clientReplicasKey)
+
+	utils.AssertEqual(t, nil, redis.RecordReplicaHeartbeat("a", time.Minute))
+	utils.AssertEqual(t, true, redis.ReplicaAlive("a"))
+	utils.AssertEqual(t, false, redis.ReplicaAlive("gone"))
+	for ip, replicaID := range map[string]string{"1.1.1.1": "a", "2.2.2.2": "b", "3.3.3.3": "gone"} {
+		redis.AddConnectedClient(ip, "default")
+		redis.SetClientReplica(ip, replicaID)
+	}
+	replicaID, err := redis.GetClientReplica("1.1.1.1")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "a", replicaID)
+	replicaID, err = redis.GetClientReplica("4.4.4.4")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "", replicaID)
+
+	// The client reconnected to another replica, so the old one can't remove it
+	utils.AssertEqual(t, nil, redis.RemoveReplicaClient("1.1.1.1", "b"))
+	connected, _ := redis.GetNumberConnectedClients()
+	utils.AssertEqual(t, int64(3), connected)
+	utils.AssertEqual(t, nil, redis.RemoveReplicaClient("1.1.1.1", "a"))
+	connected, _ = redis.GetNumberConnectedClients()
+	utils.AssertEqual(t, int64(2), connected)
+
+	// b is gone since it has no heartbeat, its clients are replaced along with our own
+	redis.RecordReplicaHeartbeat("b", time.Minute)
+	utils.AssertEqual(t, nil, redis.ReplaceReplicaClients("a", map[string]string{"5.5.5.5": "mypool"}))
+	clients, _ := redis.Hgetall("clients")
+	utils.AssertEqual(t, map[string]string{"2.2.2.2": "default", "5.5.5.5": "mypool"}, clients)
+	owners, _ := redis.Hgetall(clientReplicasKey)
+	utils.AssertEqual(t, map[string]string{"2.2.2.2": "b", "5.5.5.5": "a"}, owners)
+}
+
+func TestCounterSnapshots(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	redis := GetRedisDB()
+	now := time.Date(2022, 10, 3, 12, 0, 0, 0, time.UTC)
+	redis.Del("clientscores")
+	redis.Del(countersIntactKey)
+	defer redis.Del("clientscores")
+
+	intact, err := redis.CountersIntact()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, intact)
+	utils.AssertEqual(t, nil, redis.MarkCountersIntact())
+	intact, _ = redis.CountersIntact()
+	utils.AssertEqual(t, true, intact)
+
+	redis.UpdateClientScore("1.1.1.1", 5)
+	redis.IncrementGeoRequests("snapshots", "EU:DE", now)
+	values, err := redis.ReadCounters(now)
+	utils.AssertEqual(t, nil, err)
+	byKey := map[string]models.CounterValue{}
+	for _, value := range values {
+		byKey[value.Key+"/"+value.Field] = value
+	}
+	utils.AssertEqual(t, "5", byKey["clientscores/1.1.1.1"].Value)
+	utils.AssertEqual(t, true, byKey["clientscores/1.1.1.1"].ExpiresAt == nil)
+	geoKey := geoRequestsKey("snapshots", now)
+	utils.AssertEqual(t, "1", byKey[geoKey+"/EU:DE"].Value)
+	utils.AssertEqual(t, now.Add(config.GEO_REQUESTS_RETENTION_DAYS*24*time.Hour), *byKey[geoKey+"/EU:DE"].ExpiresAt)
+
+	// Redis lost the client scores, the geo counts it still has are newer and kept
+	redis.Del("clientscores")
+	redis.IncrementGeoRequests("snapshots", "EU:DE", now)
+	expired := now.Add(-time.Minute)
+	values = append(values, models.CounterValue{Key: "sourcerequests:gone", Value: "3", ExpiresAt: &expired})
+	restored, err := redis.RestoreCounters(values, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, restored)
+	utils.AssertEqual(t, 5, redis.GetClientScore("1.1.1.1"))
+	geo, _ := redis.GetGeoRequests("snapshots", now, now)
+	utils.AssertEqual(t, int64(2), geo["EU:DE"])
+	_, err = redis.Get("sourcerequests:gone")
+	utils.AssertEqual(t, true, err != nil)
+	redis.Del(geoKey)
 }
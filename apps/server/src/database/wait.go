@@ -0,0 +1,59 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"gorm.io/gorm"
+	"k8s.io/klog/v2"
+)
+
+// WaitFor retries check with exponential backoff until it succeeds or timeout passes, so the server can start before its dependencies
+func WaitFor(name string, check func() error, timeout time.Duration) error {
+	return waitFor(name, check, timeout, config.DEPENDENCY_MIN_BACKOFF_SECONDS*time.Second, config.DEPENDENCY_MAX_BACKOFF_SECONDS*time.Second)
+}
+
+func waitFor(name string, check func() error, timeout time.Duration, minBackoff time.Duration, maxBackoff time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	backoff := minBackoff
+	for attempt := 1; ; attempt++ {
+		err := check()
+		if err == nil {
+			if attempt > 1 {
+				klog.Infof("%s is ready after %d attempts", name, attempt)
+			}
+			return nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("%s is not ready after %s: %w", name, timeout, err)
+		}
+		klog.Warningf("Waiting for %s, retrying in %s: %v", name, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func Ping(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Ping()
+}
+
+// NewConnectionWhenReady waits up to timeout for postgres to accept connections
+func NewConnectionWhenReady(dbConfig *Config, timeout time.Duration) (*gorm.DB, error) {
+	var db *gorm.DB
+	err := WaitFor("postgres", func() error {
+		var err error
+		if db, err = NewConnection(dbConfig); err != nil {
+			return err
+		}
+		return Ping(db)
+	}, timeout)
+	return db, err
+}
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestWaitFor(t *testing.T) {
+	attempts := 0
+	err := waitFor("flaky", func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	}, time.Second, time.Millisecond, 2*time.Millisecond)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 3, attempts)
+
+	down := errors.New("down")
+	err = waitFor("down", func() error {
+		return down
+	}, 20*time.Millisecond, time.Millisecond, 4*time.Millisecond)
+	utils.AssertEqual(t, true, errors.Is(err, down))
+}
@@ -0,0 +1,165 @@
+// Package devtools makes local resolver testing easier, it's only served when dev login is enabled in development
+package devtools
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/utils"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	"k8s.io/klog/v2"
+)
+
+// Every dev account has this password, so they can also log in through the login mutation
+const Password = "boompow-dev"
+
+type Account struct {
+	Email       string
+	Description string
+	user        func() *models.User
+}
+
+var Accounts = []Account{
+	{
+		Email:       utils.DevAdminEmail,
+		Description: "Admin",
+		user: func() *models.User {
+			return &models.User{Type: models.REQUESTER, EmailVerified: true}
+		},
+	},
+	{
+		Email:       "provider@dev.boompow.local",
+		Description: "Provider with a payout address",
+		user: func() *models.User {
+			banAddress := "ban_3bsnis6ha3m9cepuaywskn9jykdggxcu8mxsp76yc3oinrt3n7gi77xiggtm"
+			return &models.User{Type: models.PROVIDER, EmailVerified: true, BanAddress: &banAddress}
+		},
+	},
+	{
+		Email:       "requester@dev.boompow.local",
+		Description: "Requester that can request work",
+		user: func() *models.User {
+			serviceName := "Dev Service"
+			serviceWebsite := "https://example.com"
+			return &models.User{Type: models.REQUESTER, EmailVerified: true, CanRequestWork: true, ServiceName: &serviceName, ServiceWebsite: &serviceWebsite}
+		},
+	},
+}
+
+func account(email string) *Account {
+	for i := range Accounts {
+		if Accounts[i].Email == strings.ToLower(email) {
+			return &Accounts[i]
+		}
+	}
+	return nil
+}
+
+// Creates the dev accounts that don't exist yet
+func Seed(userRepo repository.UserRepo, tenantID string) error {
+	for _, account := range Accounts {
+		user := account.user()
+		user.Email = account.Email
+		user.TenantID = tenantID
+		created, err := userRepo.EnsureUser(user, Password)
+		if err != nil {
+			return err
+		}
+		if created {
+			klog.Infof("Seeded dev account %s", account.Email)
+		}
+	}
+	return nil
+}
+
+// Providers the simulated workers work for, one per worker, they're paid to the dev provider's address
+func SeedSimulatedWorkers(userRepo repository.UserRepo, tenantID string, workers int) ([]string, error) {
+	banAddress := *account("provider@dev.boompow.local").user().BanAddress
+	emails := make([]string, workers)
+	for i := range emails {
+		emails[i] = fmt.Sprintf("sim-worker-%d@dev.boompow.local", i+1)
+		user := &models.User{Type: models.PROVIDER, EmailVerified: true, BanAddress: &banAddress, Email: emails[i], TenantID: tenantID}
+		if _, err := userRepo.EnsureUser(user, Password); err != nil {
+			return nil, err
+		}
+	}
+	return emails, nil
+}
+
+type LoginResponse struct {
+	Email   string            `json:"email"`
+	Token   string            `json:"token"`
+	Headers map[string]string `json:"headers"`
+}
+
+// GraphiQL keeps its headers editor in local storage, so logging in fills it in and goes back to the playground
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>BoomPoW dev login</title>
+  </head>
+  <body>
+{{- if .Headers}}
+    <script>
+      localStorage.setItem("graphiql:headers", {{.Headers}});
+      location.replace({{.Playground}});
+    </script>
+{{- else}}
+    <h1>Log in to the playground as</h1>
+    <ul>
+{{- range .Accounts}}
+      <li><a href="?email={{.Email}}">{{.Email}}</a> {{.Description}}</li>
+{{- end}}
+    </ul>
+    <p>The password of every account is <code>{{.Password}}</code>.</p>
+{{- end}}
+  </body>
+</html>
+`))
+
+// LoginHandler mints a JWT for a dev account, as JSON for scripts or straight into the playground's headers for browsers
+func LoginHandler(playgroundPath string, now func() time.Time) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		email := r.URL.Query().Get("email")
+		if email == "" {
+			w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+			loginPage.Execute(w, map[string]interface{}{"Accounts": Accounts, "Password": Password})
+			return
+		}
+		// Only the dev accounts, anybody else logs in with their password
+		account := account(email)
+		if account == nil {
+			http.Error(w, "not a dev account", http.StatusNotFound)
+			return
+		}
+		token, err := auth.GenerateToken(account.Email, now)
+		if err != nil {
+			http.Error(w, "error generating token", http.StatusInternalServerError)
+			return
+		}
+		resp := LoginResponse{Email: account.Email, Token: token, Headers: map[string]string{"Authorization": token}}
+		if strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+		headers, err := json.MarshalIndent(resp.Headers, "", "  ")
+		if err != nil {
+			http.Error(w, "error encoding headers", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		loginPage.Execute(w, map[string]interface{}{"Headers": string(headers), "Playground": playgroundPath})
+	})
+}
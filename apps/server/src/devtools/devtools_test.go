@@ -0,0 +1,82 @@
+package devtools
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+// Only EnsureUser is used
+type fakeUserRepo struct {
+	repository.UserRepo
+	users map[string]*models.User
+}
+
+func (f *fakeUserRepo) EnsureUser(user *models.User, password string) (bool, error) {
+	if _, ok := f.users[user.Email]; ok {
+		return false, nil
+	}
+	f.users[user.Email] = user
+	return true, nil
+}
+
+func TestSeed(t *testing.T) {
+	repo := &fakeUserRepo{users: map[string]*models.User{}}
+	utils.AssertEqual(t, nil, Seed(repo, "default"))
+	utils.AssertEqual(t, len(Accounts), len(repo.users))
+	provider := repo.users["provider@dev.boompow.local"]
+	utils.AssertEqual(t, models.PROVIDER, provider.Type)
+	utils.AssertEqual(t, "default", provider.TenantID)
+	utils.AssertEqual(t, true, repo.users["requester@dev.boompow.local"].CanRequestWork)
+	// Again is a no-op
+	utils.AssertEqual(t, nil, Seed(repo, "default"))
+}
+
+func TestSeedSimulatedWorkers(t *testing.T) {
+	repo := &fakeUserRepo{users: map[string]*models.User{}}
+	emails, err := SeedSimulatedWorkers(repo, "default", 2)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, []string{"sim-worker-1@dev.boompow.local", "sim-worker-2@dev.boompow.local"}, emails)
+	worker := repo.users["sim-worker-2@dev.boompow.local"]
+	utils.AssertEqual(t, models.PROVIDER, worker.Type)
+	utils.AssertEqual(t, true, worker.BanAddress != nil)
+}
+
+func TestLoginHandler(t *testing.T) {
+	handler := LoginHandler("/", time.Now)
+	login := func(email string, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/dev/login?email="+email, nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := login("provider@dev.boompow.local", "application/json")
+	utils.AssertEqual(t, http.StatusOK, rec.Code)
+	var resp LoginResponse
+	utils.AssertEqual(t, nil, json.NewDecoder(rec.Body).Decode(&resp))
+	utils.AssertEqual(t, resp.Token, resp.Headers["Authorization"])
+	email, err := auth.ParseToken(resp.Token, time.Now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "provider@dev.boompow.local", email)
+
+	// Browsers get the headers stored for the playground
+	rec = login("provider@dev.boompow.local", "text/html")
+	utils.AssertEqual(t, true, strings.Contains(rec.Body.String(), "graphiql:headers"))
+
+	// Nobody else
+	rec = login("someone@example.com", "application/json")
+	utils.AssertEqual(t, http.StatusNotFound, rec.Code)
+
+	rec = login("", "text/html")
+	utils.AssertEqual(t, true, strings.Contains(rec.Body.String(), "requester@dev.boompow.local"))
+}
@@ -2,17 +2,20 @@ package email
 
 import (
 	"bytes"
-	"encoding/base64"
+	"context"
+	"encoding/csv"
 	"fmt"
 	"html/template"
 	"net/mail"
-	"net/smtp"
 	"net/url"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"time"
 
 	"github.com/bananocoin/boompow/apps/server/src/config"
 	"github.com/bananocoin/boompow/apps/server/src/models"
+	emaillib "github.com/bananocoin/boompow/libs/email"
 	"github.com/bananocoin/boompow/libs/utils"
 	"k8s.io/klog/v2"
 )
@@ -24,57 +27,63 @@ func getTemplatePath(name string) string {
 	return basepath
 }
 
-// Send an email with given parameters
-func sendEmail(destination string, subject string, t *template.Template, templateData interface{}) error {
-	// Get credentials
-	smtpCredentials := utils.GetSmtpConnInformation()
-	if smtpCredentials == nil {
-		errMsg := "SMTP Credentials misconfigured, not sending email"
-		klog.Errorf(errMsg)
-		return fmt.Errorf("%s", errMsg)
-	}
+// A file sent along with an email
+type attachment struct {
+	filename    string
+	contentType string
+	content     []byte
+}
 
-	// Send email
-	auth := smtp.PlainAuth("", smtpCredentials.Username, smtpCredentials.Password, smtpCredentials.Server)
-	from := mail.Address{
-		Name:    "BoomPoW (Banano)",
-		Address: "noreply@mail.banano.cc",
+// Who emails are sent from
+var fromAddress = mail.Address{
+	Name:    "BoomPoW (Banano)",
+	Address: "noreply@mail.banano.cc",
+}
+
+// The sender of the provider in BPOW_EMAIL_PROVIDER, errors if it isn't configured
+func NewSender() (emaillib.Sender, error) {
+	cfg := emaillib.Config{
+		Provider:       utils.GetEmailProvider(),
+		MailgunDomain:  utils.GetMailgunDomain(),
+		MailgunBaseURL: utils.GetMailgunAPIBase(),
+		DryRunDir:      utils.GetEmailDryRunDir(),
 	}
-	to := mail.Address{
-		Address: destination,
+	if smtpCredentials := utils.GetSmtpConnInformation(); smtpCredentials != nil {
+		cfg.SMTPServer = smtpCredentials.Server
+		cfg.SMTPPort = smtpCredentials.Port
+		cfg.SMTPUsername = smtpCredentials.Username
+		cfg.SMTPPassword = smtpCredentials.Password
 	}
+	var err error
+	if cfg.SendGridAPIKey, err = utils.GetSecret("SENDGRID_API_KEY"); err != nil {
+		return nil, err
+	}
+	if cfg.MailgunAPIKey, err = utils.GetSecret("MAILGUN_API_KEY"); err != nil {
+		return nil, err
+	}
+	return emaillib.NewSender(cfg)
+}
 
-	title := subject
-
-	var body bytes.Buffer
-
-	if err := t.ExecuteTemplate(&body, "base", templateData); err != nil {
-		klog.Errorf("Error creating email template  %s", err)
+// Send an email with given parameters
+func sendEmail(destination string, subject string, body []byte, attachments ...attachment) error {
+	s, err := NewSender()
+	if err != nil {
+		klog.Errorf("Email misconfigured, not sending email %v", err)
 		return err
 	}
 
-	header := make(map[string]string)
-	header["From"] = from.String()
-	header["To"] = to.String()
-	header["Subject"] = title
-	header["MIME-Version"] = "1.0"
-	header["Content-Type"] = "text/html; charset=\"utf-8\""
-	header["Content-Transfer-Encoding"] = "base64"
-
-	message := ""
-	for k, v := range header {
-		message += fmt.Sprintf("%s: %s\r\n", k, v)
+	message := emaillib.Message{
+		From:    fromAddress,
+		To:      destination,
+		Subject: subject,
+		HTML:    body,
 	}
-	message += "\r\n" + base64.StdEncoding.EncodeToString(body.Bytes())
-
-	err := smtp.SendMail(
-		fmt.Sprintf("%s:%d", smtpCredentials.Server, smtpCredentials.Port),
-		auth,
-		from.Address,
-		[]string{to.Address},
-		[]byte(message),
-	)
-	if err != nil {
+	for _, a := range attachments {
+		message.Attachments = append(message.Attachments, emaillib.Attachment{Filename: a.filename, ContentType: a.contentType, Content: a.content})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), emaillib.DefaultTimeout)
+	defer cancel()
+	if err := s.Send(ctx, message); err != nil {
 		klog.Errorf("Error sending email  %s", err)
 		return err
 	}
@@ -95,12 +104,6 @@ func loadEmailTemplate(templateName string) (*template.Template, error) {
 
 // Send email with link to verify user's email address
 func SendConfirmationEmail(destination string, userType models.UserType, token string) error {
-	// Load template
-	t, err := loadEmailTemplate("confirmemail.html")
-	if err != nil {
-		return err
-	}
-
 	// Populate template
 	templateData := ConfirmationEmailData{
 		ConfirmationLink:              fmt.Sprintf("https://boompow.banano.cc/verify_email/%s/%s", destination, token),
@@ -108,41 +111,29 @@ func SendConfirmationEmail(destination string, userType models.UserType, token s
 		IsProvider:                    userType == models.PROVIDER,
 	}
 
-	return sendEmail(
-		destination,
-		"Confirm your email address for your BoomPOW Account",
-		t, templateData,
-	)
-}
-
-// Send email with link to reset user's password
-func SendResetPasswordEmail(destination string, token string) error {
-	// Load template
-	t, err := loadEmailTemplate("resetpassword.html")
+	subject, body, err := render("confirmemail", templateData)
 	if err != nil {
 		return err
 	}
+	return sendEmail(destination, subject, body)
+}
 
+// Send email with link to reset user's password
+func SendResetPasswordEmail(destination string, token string) error {
 	// Populate template
 	templateData := ResetPasswordEmailData{
 		ResetPasswordLink: fmt.Sprintf("https://boompow.banano.cc/reset_password/%s", token),
 	}
 
-	return sendEmail(
-		destination,
-		"Reset the password for your BoomPoW Account",
-		t, templateData,
-	)
-}
-
-// Send email with link to authorize service
-func SendAuthorizeServiceEmail(email string, name string, website string, token string) error {
-	// Load template
-	t, err := loadEmailTemplate("confirmservice.html")
+	subject, body, err := render("resetpassword", templateData)
 	if err != nil {
 		return err
 	}
+	return sendEmail(destination, subject, body)
+}
 
+// Send email with link to authorize service
+func SendAuthorizeServiceEmail(email string, name string, website string, token string) error {
 	// Encode URL params
 	urlParam := url.QueryEscape(fmt.Sprintf(`query verifyService{
 		verifyService(input:{email:"%s", token:"%s"})
@@ -156,26 +147,115 @@ func SendAuthorizeServiceEmail(email string, name string, website string, token
 		ApproveServiceLink: fmt.Sprintf("https://boompow.banano.cc/graphql?query=%s", urlParam),
 	}
 
-	return sendEmail(
-		"hello@appditto.com",
-		"A service has requested access to BoomPoW",
-		t, templateData,
-	)
+	subject, body, err := render("confirmservice", templateData)
+	if err != nil {
+		return err
+	}
+	return sendEmail("hello@appditto.com", subject, body)
 }
 
 // Send email letting service know they are approved
 func SendServiceApprovedEmail(email string) error {
-	// Load template
-	t, err := loadEmailTemplate("serviceapproved.html")
+	// Populate template
+	templateData := map[string]string{}
+	subject, body, err := render("serviceapproved", templateData)
 	if err != nil {
 		return err
 	}
+	return sendEmail(email, subject, body)
+}
 
+// Send email letting a provider know their workers are offline
+func SendOfflineAlertEmail(email string, offlineSince time.Time) error {
 	// Populate template
-	templateData := map[string]string{}
+	templateData := OfflineAlertEmailData{
+		OfflineSince:   offlineSince.UTC().Format("2006-01-02 15:04 UTC"),
+		OfflineMinutes: int(time.Since(offlineSince).Minutes()),
+	}
+	subject, body, err := render("offlinealert", templateData)
+	if err != nil {
+		return err
+	}
+	return sendEmail(email, subject, body)
+}
+
+// Send a reminder to verify the email of an account before the stale account cleanup disables it
+func SendStaleAccountEmail(email string, signedUpAt time.Time, disableOn time.Time, anonymizeDays int) error {
+	// Populate template
+	templateData := StaleAccountEmailData{
+		SignedUpOn:    signedUpAt.UTC().Format("2006-01-02"),
+		DisableOn:     disableOn.UTC().Format("2006-01-02"),
+		AnonymizeDays: anonymizeDays,
+	}
+	subject, body, err := render("staleaccount", templateData)
+	if err != nil {
+		return err
+	}
+	return sendEmail(email, subject, body)
+}
+
+// Where a payment's block can be looked up
+func TransactionLink(blockHash string) string {
+	return fmt.Sprintf("https://creeper.banano.cc/hash/%s", blockHash)
+}
+
+// Turns off the emails the token was generated for, see auth.UnsubscribePurpose
+func UnsubscribeLink(token string) string {
+	return fmt.Sprintf("https://boompow.banano.cc/email/unsubscribe/%s", token)
+}
+
+// Send a provider what they solved and were paid in a payout cycle that closed
+func SendPayoutStatementEmail(destination string, templateData PayoutStatementEmailData) error {
+	subject, body, err := render("payoutstatement", templateData)
+	if err != nil {
+		return err
+	}
+	return sendEmail(destination, subject, body)
+}
+
+// Send a plain email to check that email is configured correctly
+func SendTestEmail(destination string) error {
+	body := fmt.Sprintf("<p>This is a test email from the BoomPoW preflight check, sent at %s.</p>", time.Now().UTC().Format(time.RFC3339))
+	return sendEmail(destination, "BoomPoW preflight test", []byte(body))
+}
+
+// Usage per difficulty as CSV, one row per difficulty multiplier
+func usageCSV(usage []models.UsageRollup) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write([]string{"month", "difficulty_multiplier", "requests", "cached"})
+	for _, u := range usage {
+		w.Write([]string{u.Month.Format("2006-01"), strconv.Itoa(u.DifficultyMultiplier), strconv.FormatInt(u.Requests, 10), strconv.FormatInt(u.Cached, 10)})
+	}
+	w.Flush()
+	return b.Bytes(), w.Error()
+}
+
+// Send a requester their usage of a month that ended, with the usage per difficulty attached as CSV
+func SendUsageStatementEmail(destination string, statement *models.UsageStatement, usage []models.UsageRollup) error {
+	csv, err := usageCSV(usage)
+	if err != nil {
+		return err
+	}
+
+	// Populate template
+	month := statement.Month.Format("January 2006")
+	templateData := UsageStatementEmailData{
+		Month:    month,
+		Requests: statement.Requests,
+		Cached:   statement.Cached,
+		Usage:    usage,
+	}
+	subject, body, err := render("usagestatement", templateData)
+	if err != nil {
+		return err
+	}
 	return sendEmail(
-		email,
-		"You have been authorized to use BoomPoW!",
-		t, templateData,
+		destination, subject, body,
+		attachment{
+			filename:    fmt.Sprintf("boompow-usage-%s.csv", statement.Month.Format("2006-01")),
+			contentType: "text/csv",
+			content:     csv,
+		},
 	)
 }
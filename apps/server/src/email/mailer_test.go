@@ -0,0 +1,31 @@
+package email
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestUsageStatement(t *testing.T) {
+	month := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+	usage := []models.UsageRollup{
+		{Month: month, DifficultyMultiplier: 1, Requests: 10, Cached: 2},
+		{Month: month, DifficultyMultiplier: 64, Requests: 3},
+	}
+
+	csv, err := usageCSV(usage)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "month,difficulty_multiplier,requests,cached\n2022-09,1,10,2\n2022-09,64,3,0\n", string(csv))
+
+	tmpl, err := loadEmailTemplate("usagestatement.html")
+	utils.AssertEqual(t, nil, err)
+	var body bytes.Buffer
+	err = tmpl.ExecuteTemplate(&body, "base", UsageStatementEmailData{Month: "September 2022", Requests: 13, Cached: 2, Usage: usage})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, strings.Contains(body.String(), "Providers solved 13 of your work requests in September 2022"))
+	utils.AssertEqual(t, true, strings.Contains(body.String(), "<td>64x</td>"))
+}
@@ -1,5 +1,7 @@
 package email
 
+import "github.com/bananocoin/boompow/apps/server/src/models"
+
 type ConfirmationEmailData struct {
 	ConfirmationLink              string
 	ConfirmCodeExpirationDuration int
@@ -16,3 +18,33 @@ type ConfirmServiceEmailData struct {
 	ServiceWebsite     string
 	ApproveServiceLink string
 }
+
+type OfflineAlertEmailData struct {
+	OfflineSince   string
+	OfflineMinutes int
+}
+
+type StaleAccountEmailData struct {
+	SignedUpOn    string
+	DisableOn     string
+	AnonymizeDays int
+}
+
+type UsageStatementEmailData struct {
+	Month    string
+	Requests int64
+	Cached   int64
+	Usage    []models.UsageRollup
+}
+
+type PayoutStatementEmailData struct {
+	CycleDate   string
+	UnitsSolved int
+	Rank        int
+	Providers   int
+	// In BAN
+	Paid             string
+	TransactionLinks []string
+	NextPayouts      []string
+	UnsubscribeLink  string
+}
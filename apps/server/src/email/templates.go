@@ -0,0 +1,192 @@
+package email
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+	textTemplate "text/template"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/libs/utils"
+	"k8s.io/klog/v2"
+)
+
+// An email that ships with the server, admins can replace its subject and body per language
+type builtinTemplate struct {
+	file    string
+	subject string
+	// What previews render, and what edited templates have to render with before they're saved
+	sample interface{}
+}
+
+var builtinTemplates = map[string]builtinTemplate{
+	"confirmemail": {
+		file:    "confirmemail.html",
+		subject: "Confirm your email address for your BoomPOW Account",
+		sample:  ConfirmationEmailData{ConfirmationLink: "https://boompow.banano.cc/verify_email/user@example.com/token", ConfirmCodeExpirationDuration: 60, IsProvider: true},
+	},
+	"resetpassword": {
+		file:    "resetpassword.html",
+		subject: "Reset the password for your BoomPoW Account",
+		sample:  ResetPasswordEmailData{ResetPasswordLink: "https://boompow.banano.cc/reset_password/token"},
+	},
+	"confirmservice": {
+		file:    "confirmservice.html",
+		subject: "A service has requested access to BoomPoW",
+		sample:  ConfirmServiceEmailData{EmailAddress: "service@example.com", ServiceName: "Example Wallet", ServiceWebsite: "https://example.com", ApproveServiceLink: "https://boompow.banano.cc/graphql"},
+	},
+	"serviceapproved": {
+		file:    "serviceapproved.html",
+		subject: "You have been authorized to use BoomPoW!",
+		sample:  map[string]string{},
+	},
+	"offlinealert": {
+		file:    "offlinealert.html",
+		subject: "Your BoomPoW workers are offline",
+		sample:  OfflineAlertEmailData{OfflineSince: "2022-09-01 12:00 UTC", OfflineMinutes: 30},
+	},
+	"staleaccount": {
+		file:    "staleaccount.html",
+		subject: "Verify your email to keep your BoomPoW account",
+		sample:  StaleAccountEmailData{SignedUpOn: "2022-09-01", DisableOn: "2022-10-15", AnonymizeDays: 30},
+	},
+	"usagestatement": {
+		file:    "usagestatement.html",
+		subject: "Your BoomPoW usage for {{.Month}}",
+		sample: UsageStatementEmailData{Month: "September 2022", Requests: 13, Cached: 2, Usage: []models.UsageRollup{
+			{Month: time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC), DifficultyMultiplier: 1, Requests: 10, Cached: 2},
+			{Month: time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC), DifficultyMultiplier: 64, Requests: 3},
+		}},
+	},
+	"payoutstatement": {
+		file:    "payoutstatement.html",
+		subject: "Your BoomPoW payout for {{.CycleDate}}",
+		sample: PayoutStatementEmailData{
+			CycleDate: "2022-09-01", UnitsSolved: 120, Rank: 2, Providers: 14, Paid: "42.50",
+			TransactionLinks: []string{TransactionLink("0000000000000000000000000000000000000000000000000000000000000000")},
+			NextPayouts:      []string{"2022-09-02 08:00 UTC", "2022-09-03 08:00 UTC"},
+			UnsubscribeLink:  UnsubscribeLink("token"),
+		},
+	},
+}
+
+// Where admin edited templates come from, only built-in ones are sent while it's nil
+type TemplateSource interface {
+	GetEmailTemplate(name string, language string) (*models.EmailTemplate, error)
+}
+
+var Templates TemplateSource
+
+// Names of the emails that can be edited
+func TemplateNames() []string {
+	names := make([]string, 0, len(builtinTemplates))
+	for name := range builtinTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Subject and body of a built-in email, the starting point for edits
+func BuiltinTemplate(name string) (string, string, error) {
+	builtin, ok := builtinTemplates[name]
+	if !ok {
+		return "", "", errors.New("Unknown email template")
+	}
+	file, err := os.ReadFile(getTemplatePath(builtin.file))
+	if err != nil {
+		return "", "", err
+	}
+	body := strings.TrimSpace(string(file))
+	body = strings.TrimPrefix(body, `{{define "body"}}`)
+	body = strings.TrimSuffix(body, `{{end}}`)
+	return builtin.subject, strings.TrimSpace(body), nil
+}
+
+// Renders a subject and body against the sample data of their email, templates that don't render aren't saved
+func Preview(name string, subject string, body string) (string, string, error) {
+	builtin, ok := builtinTemplates[name]
+	if !ok {
+		return "", "", errors.New("Unknown email template")
+	}
+	renderedSubject, renderedBody, err := renderTemplate(subject, body, builtin.sample)
+	if err != nil {
+		return "", "", err
+	}
+	return renderedSubject, string(renderedBody), nil
+}
+
+// Renders an email from its latest edited template in the configured language, falling back to the default language and then the built-in one
+func render(name string, data interface{}) (string, []byte, error) {
+	builtin, ok := builtinTemplates[name]
+	if !ok {
+		return "", nil, errors.New("Unknown email template")
+	}
+	if Templates != nil {
+		languages := []string{utils.GetEmailLanguage()}
+		if languages[0] != models.DefaultEmailLanguage {
+			languages = append(languages, models.DefaultEmailLanguage)
+		}
+		for _, language := range languages {
+			stored, err := Templates.GetEmailTemplate(name, language)
+			if err != nil {
+				// Better the built-in email than none
+				klog.Errorf("Error loading email template %s (%s), sending the built-in one %v", name, language, err)
+				break
+			}
+			if stored != nil {
+				return renderTemplate(stored.Subject, stored.Body, data)
+			}
+		}
+	}
+	subject, err := renderSubject(builtin.subject, data)
+	if err != nil {
+		return "", nil, err
+	}
+	t, err := loadEmailTemplate(builtin.file)
+	if err != nil {
+		return "", nil, err
+	}
+	var body bytes.Buffer
+	if err := t.ExecuteTemplate(&body, "base", data); err != nil {
+		klog.Errorf("Error creating email template  %s", err)
+		return "", nil, err
+	}
+	return subject, body.Bytes(), nil
+}
+
+func renderTemplate(subject string, body string, data interface{}) (string, []byte, error) {
+	renderedSubject, err := renderSubject(subject, data)
+	if err != nil {
+		return "", nil, err
+	}
+	t, err := template.New("").ParseFiles(getTemplatePath("base.html"))
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := t.New("body").Parse(body); err != nil {
+		return "", nil, err
+	}
+	var rendered bytes.Buffer
+	if err := t.ExecuteTemplate(&rendered, "base", data); err != nil {
+		return "", nil, err
+	}
+	return renderedSubject, rendered.Bytes(), nil
+}
+
+func renderSubject(subject string, data interface{}) (string, error) {
+	t, err := textTemplate.New("subject").Parse(subject)
+	if err != nil {
+		return "", err
+	}
+	var rendered bytes.Buffer
+	if err := t.Execute(&rendered, data); err != nil {
+		return "", err
+	}
+	// Headers can't span lines
+	return strings.Join(strings.Fields(rendered.String()), " "), nil
+}
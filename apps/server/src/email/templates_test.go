@@ -0,0 +1,68 @@
+package email
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+type fakeTemplateSource map[string]*models.EmailTemplate
+
+func (f fakeTemplateSource) GetEmailTemplate(name string, language string) (*models.EmailTemplate, error) {
+	return f[name+"/"+language], nil
+}
+
+func TestBuiltinTemplatesRender(t *testing.T) {
+	for _, name := range TemplateNames() {
+		subject, body, err := BuiltinTemplate(name)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, false, strings.Contains(body, `{{define "body"}}`))
+		// Editing starts from the built-in email, so it has to render as an edit too
+		_, _, err = Preview(name, subject, body)
+		utils.AssertEqual(t, nil, err)
+	}
+
+	subject, _, err := Preview("usagestatement", "Your BoomPoW usage for {{.Month}}", "<p>{{.Requests}}</p>")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "Your BoomPoW usage for September 2022", subject)
+	_, _, err = Preview("usagestatement", "Usage", "<p>{{.Missing}}</p>")
+	utils.AssertNotEqual(t, nil, err)
+	_, _, err = Preview("unknown", "Usage", "")
+	utils.AssertNotEqual(t, nil, err)
+}
+
+func TestRenderEditedTemplate(t *testing.T) {
+	defer func() { Templates = nil }()
+	data := OfflineAlertEmailData{OfflineSince: "2022-09-01 12:00 UTC", OfflineMinutes: 45}
+
+	// Built-in until edited
+	Templates = fakeTemplateSource{}
+	subject, body, err := render("offlinealert", data)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "Your BoomPoW workers are offline", subject)
+	utils.AssertEqual(t, true, strings.Contains(string(body), "2022-09-01 12:00 UTC"))
+
+	Templates = fakeTemplateSource{
+		"offlinealert/en": {Subject: "Offline for\n{{.OfflineMinutes}} minutes", Body: "<p>Since {{.OfflineSince}}</p>"},
+		"offlinealert/es": {Subject: "Desconectado {{.OfflineMinutes}} minutos", Body: "<p>Desde {{.OfflineSince}}</p>"},
+	}
+	subject, body, err = render("offlinealert", data)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "Offline for 45 minutes", subject)
+	utils.AssertEqual(t, true, strings.Contains(string(body), "<p>Since 2022-09-01 12:00 UTC</p>"))
+
+	os.Setenv("BPOW_EMAIL_LANGUAGE", "es")
+	defer os.Unsetenv("BPOW_EMAIL_LANGUAGE")
+	subject, _, err = render("offlinealert", data)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "Desconectado 45 minutos", subject)
+
+	// Languages without an edit fall back to the default one
+	os.Setenv("BPOW_EMAIL_LANGUAGE", "de")
+	subject, _, err = render("offlinealert", data)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "Offline for 45 minutes", subject)
+}
@@ -0,0 +1,110 @@
+// Package eventbus publishes pool events to a message bus for downstream consumers
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/controller"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
+)
+
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// Publishes events in the background, events are dropped rather than slowing down the pool when the bus can't keep up
+type Bus struct {
+	publisher Publisher
+	prefix    string
+	events    chan Event
+}
+
+func NewBus(publisher Publisher, prefix string, bufferSize int) *Bus {
+	return &Bus{
+		publisher: publisher,
+		prefix:    prefix,
+		events:    make(chan Event, bufferSize),
+	}
+}
+
+func Subject(prefix string, eventType EventType) string {
+	return fmt.Sprintf("%s.%s.v%d", prefix, eventType, SchemaVersions[eventType])
+}
+
+func (b *Bus) Emit(eventType EventType, tenantID string, data interface{}) {
+	event := Event{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		Version:   SchemaVersions[eventType],
+		Timestamp: time.Now().UTC(),
+		TenantID:  tenantID,
+		Data:      data,
+	}
+	select {
+	case b.events <- event:
+	default:
+		klog.Warningf("Event bus is full, dropping %s event", eventType)
+	}
+}
+
+func (b *Bus) Run() {
+	for event := range b.events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			klog.Errorf("Error marshalling %s event %v", event.Type, err)
+			continue
+		}
+		if err := b.publisher.Publish(Subject(b.prefix, event.Type), payload); err != nil {
+			klog.Errorf("Error publishing %s event %v", event.Type, err)
+		}
+	}
+}
+
+// Hub event listener for the life of work requests
+func (b *Bus) HandleHubEvent(event models.HubEvent) {
+	data := WorkEventV1{
+		RequestID:            event.RequestID,
+		Hash:                 event.Hash,
+		DifficultyMultiplier: event.DifficultyMultiplier,
+	}
+	switch event.Type {
+	case models.HubEventAssigned:
+		b.Emit(WorkRequested, event.TenantID, data)
+	case models.HubEventResult:
+		// Invalid results are left out, the request is still open
+		if event.Detail != "" {
+			return
+		}
+		data.ProviderEmail = event.ClientEmail
+		b.Emit(WorkSolved, event.TenantID, data)
+	case models.HubEventTimeout:
+		data.Detail = event.Detail
+		b.Emit(WorkTimedOut, event.TenantID, data)
+	}
+}
+
+func (b *Bus) HandleBlockAwarded(msg serializableModels.ClientMessage) {
+	b.Emit(WorkAwarded, msg.TenantID, WorkAwardedV1{
+		Hash:                 msg.Hash,
+		ProviderEmail:        msg.ProviderEmail,
+		DifficultyMultiplier: msg.DifficultyMultiplier,
+		PercentOfPool:        msg.PercentOfPool,
+		EstimatedAward:       msg.EstimatedAward,
+	})
+}
+
+func (b *Bus) HandleUserEvent(event controller.UserEvent) {
+	if event.Type != "payout_sent" {
+		return
+	}
+	b.Emit(PayoutSent, "", PayoutSentV1{
+		UserID:    event.UserID.String(),
+		BlockHash: event.BlockHash,
+		AmountRaw: event.AmountRaw,
+	})
+}
@@ -0,0 +1,60 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+type published struct {
+	subject string
+	data    []byte
+}
+
+type fakePublisher struct {
+	published chan published
+}
+
+func (p *fakePublisher) Publish(subject string, data []byte) error {
+	p.published <- published{subject: subject, data: data}
+	return nil
+}
+
+func TestBus(t *testing.T) {
+	publisher := &fakePublisher{published: make(chan published, 10)}
+	bus := NewBus(publisher, "boompow", 10)
+	go bus.Run()
+
+	bus.HandleHubEvent(models.HubEvent{Type: models.HubEventAssigned, RequestID: "1", Hash: "ABC", TenantID: "default", DifficultyMultiplier: 64})
+	msg := <-publisher.published
+	utils.AssertEqual(t, "boompow.work.requested.v1", msg.subject)
+	var event struct {
+		Type     EventType   `json:"type"`
+		Version  int         `json:"version"`
+		TenantID string      `json:"tenant_id"`
+		Data     WorkEventV1 `json:"data"`
+	}
+	utils.AssertEqual(t, nil, json.Unmarshal(msg.data, &event))
+	utils.AssertEqual(t, WorkRequested, event.Type)
+	utils.AssertEqual(t, 1, event.Version)
+	utils.AssertEqual(t, "default", event.TenantID)
+	utils.AssertEqual(t, 64, event.Data.DifficultyMultiplier)
+
+	// Invalid results and connects aren't published
+	bus.HandleHubEvent(models.HubEvent{Type: models.HubEventResult, RequestID: "1", Detail: "invalid work"})
+	bus.HandleHubEvent(models.HubEvent{Type: models.HubEventConnect})
+	bus.HandleHubEvent(models.HubEvent{Type: models.HubEventResult, RequestID: "1", ClientEmail: "provider@example.com"})
+	msg = <-publisher.published
+	utils.AssertEqual(t, "boompow.work.solved.v1", msg.subject)
+	utils.AssertEqual(t, 0, len(publisher.published))
+}
+
+func TestBusDropsWhenFull(t *testing.T) {
+	bus := NewBus(&fakePublisher{}, "boompow", 1)
+	// Not running, so the second event has nowhere to go
+	bus.Emit(WorkRequested, "default", WorkEventV1{})
+	bus.Emit(WorkRequested, "default", WorkEventV1{})
+	utils.AssertEqual(t, 1, len(bus.events))
+}
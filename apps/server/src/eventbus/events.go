@@ -0,0 +1,58 @@
+package eventbus
+
+import "time"
+
+type EventType string
+
+const (
+	WorkRequested EventType = "work.requested"
+	WorkSolved    EventType = "work.solved"
+	WorkTimedOut  EventType = "work.timed_out"
+	WorkAwarded   EventType = "work.awarded"
+	PayoutSent    EventType = "payout.sent"
+)
+
+// Schema version of every event's data, bumped on breaking changes so consumers can tell them apart
+// The version is part of the subject, e.g. boompow.work.requested.v1
+var SchemaVersions = map[EventType]int{
+	WorkRequested: 1,
+	WorkSolved:    1,
+	WorkTimedOut:  1,
+	WorkAwarded:   1,
+	PayoutSent:    1,
+}
+
+// Envelope of every published event
+type Event struct {
+	ID        string      `json:"id"`
+	Type      EventType   `json:"type"`
+	Version   int         `json:"version"`
+	Timestamp time.Time   `json:"timestamp"`
+	TenantID  string      `json:"tenant_id,omitempty"`
+	Data      interface{} `json:"data"`
+}
+
+// Data of work.requested, work.solved and work.timed_out
+type WorkEventV1 struct {
+	RequestID            string `json:"request_id"`
+	Hash                 string `json:"hash"`
+	DifficultyMultiplier int    `json:"difficulty_multiplier"`
+	// Worker that solved it, only on work.solved
+	ProviderEmail string `json:"provider_email,omitempty"`
+	// Set when a worker returned invalid work
+	Detail string `json:"detail,omitempty"`
+}
+
+type WorkAwardedV1 struct {
+	Hash                 string  `json:"hash"`
+	ProviderEmail        string  `json:"provider_email"`
+	DifficultyMultiplier int     `json:"difficulty_multiplier"`
+	PercentOfPool        float64 `json:"percent_of_pool"`
+	EstimatedAward       float64 `json:"estimated_award"`
+}
+
+type PayoutSentV1 struct {
+	UserID    string `json:"user_id"`
+	BlockHash string `json:"block_hash"`
+	AmountRaw string `json:"amount_raw"`
+}
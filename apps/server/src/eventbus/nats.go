@@ -0,0 +1,157 @@
+package eventbus
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Publishes with the NATS text protocol, connecting on first use and reconnecting after errors
+// Only plain TCP connections are supported, credentials can be given in the URL as user:pass or a token
+type NatsPublisher struct {
+	url *url.URL
+
+	mu   sync.Mutex
+	conn net.Conn
+	w    *bufio.Writer
+}
+
+var _ Publisher = &NatsPublisher{}
+
+func NewNatsPublisher(rawURL string) (*NatsPublisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "nats" {
+		return nil, fmt.Errorf("unsupported event bus URL scheme %s", u.Scheme)
+	}
+	if u.Port() == "" {
+		u.Host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	return &NatsPublisher{url: u}, nil
+}
+
+type natsConnect struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	Name      string `json:"name"`
+	Lang      string `json:"lang"`
+	Version   string `json:"version"`
+	User      string `json:"user,omitempty"`
+	Pass      string `json:"pass,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+}
+
+// Must be called with mu held
+func (p *NatsPublisher) connect() error {
+	conn, err := net.DialTimeout("tcp", p.url.Host, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	r := bufio.NewReader(conn)
+	info, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(info, "INFO") {
+		conn.Close()
+		return fmt.Errorf("expected INFO from NATS server, got %q %v", info, err)
+	}
+	opts := natsConnect{Name: "boompow", Lang: "go", Version: "1"}
+	if p.url.User != nil {
+		if pass, ok := p.url.User.Password(); ok {
+			opts.User = p.url.User.Username()
+			opts.Pass = pass
+		} else {
+			opts.AuthToken = p.url.User.Username()
+		}
+	}
+	connect, err := json.Marshal(opts)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	// The PONG confirms the server accepted the connection
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connect); err != nil {
+		conn.Close()
+		return err
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		if strings.HasPrefix(line, "-ERR") {
+			conn.Close()
+			return errors.New(strings.TrimSpace(line))
+		}
+		if strings.HasPrefix(line, "PONG") {
+			break
+		}
+	}
+	conn.SetDeadline(time.Time{})
+	p.conn = conn
+	p.w = bufio.NewWriter(conn)
+	go p.readLoop(conn, r)
+	return nil
+}
+
+// Answers the server's pings, the connection is dropped once it fails
+func (p *NatsPublisher) readLoop(conn net.Conn, r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			p.drop(conn)
+			return
+		}
+		switch {
+		case strings.HasPrefix(line, "PING"):
+			p.mu.Lock()
+			if p.conn == conn {
+				p.w.WriteString("PONG\r\n")
+				p.w.Flush()
+			}
+			p.mu.Unlock()
+		case strings.HasPrefix(line, "-ERR"):
+			klog.Errorf("NATS server error %s", strings.TrimSpace(line))
+		}
+	}
+}
+
+func (p *NatsPublisher) drop(conn net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	conn.Close()
+	if p.conn == conn {
+		p.conn = nil
+		p.w = nil
+	}
+}
+
+func (p *NatsPublisher) Publish(subject string, data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		if err := p.connect(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(p.w, "PUB %s %d\r\n", subject, len(data))
+	p.w.Write(data)
+	p.w.WriteString("\r\n")
+	if err := p.w.Flush(); err != nil {
+		p.conn.Close()
+		p.conn = nil
+		p.w = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package eventbus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+// Speaks just enough of the NATS protocol to accept publishes
+func fakeNatsServer(t *testing.T, received chan string) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	utils.AssertEqual(t, nil, err)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		fmt.Fprintf(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "CONNECT"):
+				received <- strings.TrimSpace(line)
+			case strings.HasPrefix(line, "PING"):
+				fmt.Fprintf(conn, "PONG\r\n")
+			case strings.HasPrefix(line, "PUB"):
+				var subject string
+				var size int
+				fmt.Sscanf(line, "PUB %s %d", &subject, &size)
+				payload := make([]byte, size+2)
+				if _, err := io.ReadFull(r, payload); err != nil {
+					return
+				}
+				received <- fmt.Sprintf("%s %s", subject, payload[:size])
+			}
+		}
+	}()
+	return listener
+}
+
+func TestNatsPublisher(t *testing.T) {
+	received := make(chan string, 10)
+	listener := fakeNatsServer(t, received)
+	defer listener.Close()
+
+	publisher, err := NewNatsPublisher(fmt.Sprintf("nats://user:secret@%s", listener.Addr().String()))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, nil, publisher.Publish("boompow.work.requested.v1", []byte(`{"id":"1"}`)))
+	connect := <-received
+	utils.AssertEqual(t, true, strings.Contains(connect, `"user":"user","pass":"secret"`))
+	utils.AssertEqual(t, `boompow.work.requested.v1 {"id":"1"}`, <-received)
+	utils.AssertEqual(t, nil, publisher.Publish("boompow.work.solved.v1", []byte(`{}`)))
+	utils.AssertEqual(t, `boompow.work.solved.v1 {}`, <-received)
+
+	_, err = NewNatsPublisher("kafka://localhost:9092")
+	utils.AssertNotEqual(t, nil, err)
+}
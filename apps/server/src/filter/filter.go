@@ -0,0 +1,228 @@
+// Package filter compiles the filters and sorts of admin list queries into parameterized SQL
+// Only the fields a list declares can be filtered and sorted on, columns never come from the query and values are always bound
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+	"gorm.io/gorm"
+)
+
+// How the values of a field are parsed and compared
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Bool
+	Time
+	UUID
+	// One of the field's Values
+	Enum
+)
+
+type Field struct {
+	Column string
+	Type   Type
+	// Only nullable fields can be compared with IsNull
+	Nullable bool
+	// Lists can be sorted by non-null time fields, so pages have a cursor
+	Sortable bool
+	// What an Enum field can be
+	Values []string
+}
+
+type Op string
+
+const (
+	Eq  Op = "eq"
+	Ne  Op = "ne"
+	Lt  Op = "lt"
+	Lte Op = "lte"
+	Gt  Op = "gt"
+	Gte Op = "gte"
+	In  Op = "in"
+	// Case insensitive substring
+	Contains Op = "contains"
+	// Takes true or false
+	IsNull Op = "is_null"
+)
+
+var comparisons = map[Op]string{Eq: "=", Ne: "<>", Lt: "<", Lte: "<=", Gt: ">", Gte: ">="}
+
+// What each type can be compared with, IsNull is up to the field
+var typeOps = map[Type][]Op{
+	String: {Eq, Ne, In, Contains},
+	Int:    {Eq, Ne, Lt, Lte, Gt, Gte, In},
+	Bool:   {Eq, Ne},
+	Time:   {Eq, Ne, Lt, Lte, Gt, Gte},
+	UUID:   {Eq, Ne, In},
+	Enum:   {Eq, Ne, In},
+}
+
+// A filter of a list query, values are strings parsed as the field's type
+type Condition struct {
+	Field  string
+	Op     Op
+	Values []string
+}
+
+type Sort struct {
+	Field      string
+	Descending bool
+}
+
+// The fields of a list, by the names queries use
+type List struct {
+	Fields      map[string]Field
+	IDColumn    string
+	DefaultSort Sort
+}
+
+// A condition as SQL with its values bound
+type Clause struct {
+	SQL  string
+	Args []interface{}
+}
+
+// A compiled list query, the conditions are all required
+type Query struct {
+	Clauses    []Clause
+	Sort       Sort
+	sortColumn string
+	idColumn   string
+}
+
+// Compile checks the conditions and sort against the list, the default sort is used without one
+func (l List) Compile(conditions []Condition, sort *Sort) (*Query, error) {
+	if len(conditions) > config.MAX_FILTER_CONDITIONS {
+		return nil, fmt.Errorf("bad_request:at most %d filters can be given", config.MAX_FILTER_CONDITIONS)
+	}
+	query := &Query{Clauses: make([]Clause, len(conditions)), Sort: l.DefaultSort, idColumn: l.IDColumn}
+	for i, condition := range conditions {
+		field, ok := l.Fields[condition.Field]
+		if !ok {
+			return nil, fmt.Errorf("bad_request:%s can't be filtered on", condition.Field)
+		}
+		clause, err := compileCondition(condition, field)
+		if err != nil {
+			return nil, err
+		}
+		query.Clauses[i] = clause
+	}
+	if sort != nil {
+		query.Sort = *sort
+	}
+	field, ok := l.Fields[query.Sort.Field]
+	if !ok || !field.Sortable {
+		return nil, fmt.Errorf("bad_request:%s can't be sorted on", query.Sort.Field)
+	}
+	query.sortColumn = field.Column
+	return query, nil
+}
+
+func compileCondition(condition Condition, field Field) (Clause, error) {
+	if condition.Op == IsNull {
+		if !field.Nullable {
+			return Clause{}, fmt.Errorf("bad_request:%s is never null", condition.Field)
+		}
+		if len(condition.Values) != 1 {
+			return Clause{}, fmt.Errorf("bad_request:%s takes one value", condition.Op)
+		}
+		isNull, err := strconv.ParseBool(condition.Values[0])
+		if err != nil {
+			return Clause{}, fmt.Errorf("bad_request:%s takes true or false", condition.Op)
+		}
+		if isNull {
+			return Clause{SQL: fmt.Sprintf("%s IS NULL", field.Column)}, nil
+		}
+		return Clause{SQL: fmt.Sprintf("%s IS NOT NULL", field.Column)}, nil
+	}
+	if !slices.Contains(typeOps[field.Type], condition.Op) {
+		return Clause{}, fmt.Errorf("bad_request:%s can't be compared with %s", condition.Field, condition.Op)
+	}
+
+	if condition.Op == In {
+		if len(condition.Values) < 1 || len(condition.Values) > config.MAX_FILTER_VALUES {
+			return Clause{}, fmt.Errorf("bad_request:%s takes between 1 and %d values", condition.Op, config.MAX_FILTER_VALUES)
+		}
+		values := make([]interface{}, len(condition.Values))
+		for i, raw := range condition.Values {
+			value, err := parseValue(condition.Field, field, raw)
+			if err != nil {
+				return Clause{}, err
+			}
+			values[i] = value
+		}
+		return Clause{SQL: fmt.Sprintf("%s IN ?", field.Column), Args: []interface{}{values}}, nil
+	}
+
+	if len(condition.Values) != 1 {
+		return Clause{}, fmt.Errorf("bad_request:%s takes one value", condition.Op)
+	}
+	value, err := parseValue(condition.Field, field, condition.Values[0])
+	if err != nil {
+		return Clause{}, err
+	}
+	switch {
+	case condition.Op == Contains:
+		return Clause{SQL: fmt.Sprintf("%s ILIKE ?", field.Column), Args: []interface{}{"%" + escapeLike(value.(string)) + "%"}}, nil
+	case condition.Op == Ne && field.Nullable:
+		// Rows where the field is null aren't equal either
+		return Clause{SQL: fmt.Sprintf("%s IS DISTINCT FROM ?", field.Column), Args: []interface{}{value}}, nil
+	}
+	return Clause{SQL: fmt.Sprintf("%s %s ?", field.Column, comparisons[condition.Op]), Args: []interface{}{value}}, nil
+}
+
+func parseValue(name string, field Field, raw string) (interface{}, error) {
+	var value interface{}
+	var err error
+	switch field.Type {
+	case Int:
+		value, err = strconv.Atoi(raw)
+	case Bool:
+		value, err = strconv.ParseBool(raw)
+	case Time:
+		value, err = time.Parse(time.RFC3339Nano, raw)
+	case UUID:
+		value, err = uuid.Parse(raw)
+	case Enum:
+		value = raw
+		if !slices.Contains(field.Values, raw) {
+			return nil, fmt.Errorf("bad_request:%s must be one of %s", name, strings.Join(field.Values, ", "))
+		}
+	default:
+		value = raw
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bad_request:invalid value for %s", name)
+	}
+	return value, nil
+}
+
+// Wildcards in the value are matched literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// Where applies only the conditions, for counting the list
+func (q *Query) Where(db *gorm.DB) *gorm.DB {
+	for _, clause := range q.Clauses {
+		db = db.Where(clause.SQL, clause.Args...)
+	}
+	return db
+}
+
+// Scope applies the conditions and sorts and pages the list
+func (q *Query) Scope(args pagination.Args) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return q.Where(db).Scopes(args.SortedScope(q.sortColumn, q.idColumn, q.Sort.Descending))
+	}
+}
@@ -0,0 +1,92 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+var testList = List{
+	Fields: map[string]Field{
+		"email":       {Column: "email", Type: String},
+		"type":        {Column: "type", Type: Enum, Values: []string{"PROVIDER", "REQUESTER"}},
+		"count":       {Column: "invalid_result_count", Type: Int},
+		"verified":    {Column: "email_verified", Type: Bool},
+		"owner":       {Column: "owner_id", Type: UUID},
+		"created_at":  {Column: "created_at", Type: Time, Sortable: true},
+		"disabled_at": {Column: "disabled_at", Type: Time, Nullable: true},
+	},
+	IDColumn:    "id",
+	DefaultSort: Sort{Field: "created_at", Descending: true},
+}
+
+func TestCompile(t *testing.T) {
+	owner := uuid.New()
+	query, err := testList.Compile([]Condition{
+		{Field: "email", Op: Contains, Values: []string{"50%_off\\"}},
+		{Field: "type", Op: In, Values: []string{"PROVIDER", "REQUESTER"}},
+		{Field: "count", Op: Gte, Values: []string{"3"}},
+		{Field: "verified", Op: Eq, Values: []string{"false"}},
+		{Field: "owner", Op: Eq, Values: []string{owner.String()}},
+		{Field: "created_at", Op: Lt, Values: []string{"2022-09-01T12:00:00Z"}},
+		{Field: "disabled_at", Op: IsNull, Values: []string{"false"}},
+		{Field: "disabled_at", Op: Ne, Values: []string{"2022-09-01T12:00:00Z"}},
+	}, nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, []Clause{
+		// Wildcards are escaped
+		{SQL: "email ILIKE ?", Args: []interface{}{`%50\%\_off\\%`}},
+		{SQL: "type IN ?", Args: []interface{}{[]interface{}{"PROVIDER", "REQUESTER"}}},
+		{SQL: "invalid_result_count >= ?", Args: []interface{}{3}},
+		{SQL: "email_verified = ?", Args: []interface{}{false}},
+		{SQL: "owner_id = ?", Args: []interface{}{owner}},
+		{SQL: "created_at < ?", Args: []interface{}{time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)}},
+		{SQL: "disabled_at IS NOT NULL"},
+		{SQL: "disabled_at IS DISTINCT FROM ?", Args: []interface{}{time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)}},
+	}, query.Clauses)
+	utils.AssertEqual(t, Sort{Field: "created_at", Descending: true}, query.Sort)
+
+	query, err = testList.Compile(nil, &Sort{Field: "created_at"})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(query.Clauses))
+	utils.AssertEqual(t, false, query.Sort.Descending)
+}
+
+func TestCompileRejects(t *testing.T) {
+	for _, condition := range []Condition{
+		// Not declared, so never put in the SQL
+		{Field: "password", Op: Eq, Values: []string{"x"}},
+		{Field: "email; DROP TABLE users", Op: Eq, Values: []string{"x"}},
+		{Field: "email", Op: "= '' OR 1=1 --", Values: []string{"x"}},
+		// Ops the type doesn't have
+		{Field: "email", Op: Lt, Values: []string{"x"}},
+		{Field: "count", Op: Contains, Values: []string{"1"}},
+		{Field: "email", Op: IsNull, Values: []string{"true"}},
+		// Values that don't parse or aren't one value
+		{Field: "count", Op: Eq, Values: []string{"1 OR 1=1"}},
+		{Field: "type", Op: Eq, Values: []string{"ADMIN"}},
+		{Field: "owner", Op: Eq, Values: []string{"nobody"}},
+		{Field: "created_at", Op: Gt, Values: []string{"yesterday"}},
+		{Field: "email", Op: Eq, Values: []string{"a", "b"}},
+		{Field: "email", Op: In, Values: []string{}},
+		{Field: "disabled_at", Op: IsNull, Values: []string{"maybe"}},
+	} {
+		_, err := testList.Compile([]Condition{condition}, nil)
+		utils.AssertNotEqual(t, nil, err)
+	}
+
+	// Only sortable fields
+	_, err := testList.Compile(nil, &Sort{Field: "disabled_at"})
+	utils.AssertNotEqual(t, nil, err)
+	_, err = testList.Compile(nil, &Sort{Field: "name"})
+	utils.AssertNotEqual(t, nil, err)
+
+	tooMany := make([]Condition, 21)
+	for i := range tooMany {
+		tooMany[i] = Condition{Field: "verified", Op: Eq, Values: []string{"true"}}
+	}
+	_, err = testList.Compile(tooMany, nil)
+	utils.AssertNotEqual(t, nil, err)
+}
@@ -0,0 +1,142 @@
+// Package geo turns IPs into coarse country-level aggregates, the IPs themselves are never kept
+package geo
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// Code of IPs that can't be located and of grouped countries, it's reserved for private use in ISO 3166
+const Unknown = "ZZ"
+
+// Where an IP is, as coarse as it gets
+type Location struct {
+	// Two letter continent code as MaxMind has it, e.g. EU
+	Continent string
+	// ISO 3166 country code
+	Country string
+}
+
+var UnknownLocation = Location{Continent: Unknown, Country: Unknown}
+
+// Key identifies the location in stored aggregates
+func (l Location) Key() string {
+	return l.Continent + "/" + l.Country
+}
+
+// ParseLocation reverses Key, bare country codes have an unknown continent
+func ParseLocation(key string) Location {
+	continent, country, found := strings.Cut(key, "/")
+	if !found {
+		return Location{Continent: Unknown, Country: key}
+	}
+	return Location{Continent: continent, Country: country}
+}
+
+type Locator interface {
+	// Locate returns where ip is, UnknownLocation if it can't be located
+	Locate(ip string) Location
+}
+
+// Locates IPs with a MaxMind country (or city) database
+type MaxMindLocator struct {
+	db *geoip2.Reader
+}
+
+var _ Locator = &MaxMindLocator{}
+
+func NewMaxMindLocator(path string) (*MaxMindLocator, error) {
+	db, err := geoip2.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &MaxMindLocator{db: db}, nil
+}
+
+func (l *MaxMindLocator) Locate(ip string) Location {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return UnknownLocation
+	}
+	record, err := l.db.Country(parsed)
+	if err != nil || record.Country.IsoCode == "" {
+		return UnknownLocation
+	}
+	location := Location{Continent: record.Continent.Code, Country: record.Country.IsoCode}
+	if location.Continent == "" {
+		location.Continent = Unknown
+	}
+	return location
+}
+
+func (l *MaxMindLocator) Close() error {
+	return l.db.Close()
+}
+
+// CountLocations counts ips per location
+func CountLocations(locator Locator, ips []string) map[Location]int64 {
+	counts := map[Location]int64{}
+	for _, ip := range ips {
+		counts[locator.Locate(ip)]++
+	}
+	return counts
+}
+
+// Workers and requests of a location
+type CountryStats struct {
+	Location
+	ConnectedWorkers int64
+	Requests         int64
+}
+
+// Merge combines worker and request counts per location, sorted by continent then country
+func Merge(workers map[Location]int64, requests map[Location]int64) []CountryStats {
+	byLocation := map[Location]*CountryStats{}
+	get := func(location Location) *CountryStats {
+		if _, ok := byLocation[location]; !ok {
+			byLocation[location] = &CountryStats{Location: location}
+		}
+		return byLocation[location]
+	}
+	for location, count := range workers {
+		get(location).ConnectedWorkers += count
+	}
+	for location, count := range requests {
+		get(location).Requests += count
+	}
+	stats := make([]CountryStats, 0, len(byLocation))
+	for _, s := range byLocation {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Continent != stats[j].Continent {
+			return stats[i].Continent < stats[j].Continent
+		}
+		return stats[i].Country < stats[j].Country
+	})
+	return stats
+}
+
+// GroupSmall merges countries with counts below min into their continent, and continents that are still below min into UnknownLocation
+// so a country or continent with a single provider doesn't give them away
+func GroupSmall(counts map[Location]int64, min int64) map[Location]int64 {
+	byContinent := map[Location]int64{}
+	grouped := map[Location]int64{}
+	for location, count := range counts {
+		if count < min || location.Country == Unknown {
+			byContinent[Location{Continent: location.Continent, Country: Unknown}] += count
+		} else {
+			grouped[location] += count
+		}
+	}
+	for location, count := range byContinent {
+		if count < min {
+			location = UnknownLocation
+		}
+		grouped[location] += count
+	}
+	return grouped
+}
@@ -0,0 +1,55 @@
+package geo
+
+import (
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+type fakeLocator map[string]Location
+
+func (f fakeLocator) Locate(ip string) Location {
+	if location, ok := f[ip]; ok {
+		return location
+	}
+	return UnknownLocation
+}
+
+var (
+	au = Location{Continent: "OC", Country: "AU"}
+	nz = Location{Continent: "OC", Country: "NZ"}
+	us = Location{Continent: "NA", Country: "US"}
+	de = Location{Continent: "EU", Country: "DE"}
+)
+
+func TestLocationKey(t *testing.T) {
+	utils.AssertEqual(t, "OC/AU", au.Key())
+	utils.AssertEqual(t, au, ParseLocation(au.Key()))
+	utils.AssertEqual(t, Location{Continent: Unknown, Country: "AU"}, ParseLocation("AU"))
+}
+
+func TestCountLocations(t *testing.T) {
+	locator := fakeLocator{"1.1.1.1": au, "1.0.0.1": au, "8.8.8.8": us}
+	counts := CountLocations(locator, []string{"1.1.1.1", "1.0.0.1", "8.8.8.8", "10.0.0.1"})
+	utils.AssertEqual(t, map[Location]int64{au: 2, us: 1, UnknownLocation: 1}, counts)
+}
+
+func TestGroupSmall(t *testing.T) {
+	grouped := GroupSmall(map[Location]int64{au: 5, nz: 2, {Continent: "OC", Country: "FJ"}: 1, us: 2, UnknownLocation: 1}, 3)
+	utils.AssertEqual(t, map[Location]int64{
+		au: 5,
+		// NZ and FJ together are enough to show the continent
+		{Continent: "OC", Country: Unknown}: 3,
+		// The US alone isn't
+		UnknownLocation: 3,
+	}, grouped)
+}
+
+func TestMerge(t *testing.T) {
+	stats := Merge(map[Location]int64{us: 3, au: 1}, map[Location]int64{us: 10, de: 4})
+	utils.AssertEqual(t, []CountryStats{
+		{Location: de, Requests: 4},
+		{Location: us, ConnectedWorkers: 3, Requests: 10},
+		{Location: au, ConnectedWorkers: 1},
+	}, stats)
+}
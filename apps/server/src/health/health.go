@@ -0,0 +1,46 @@
+// Package health reports whether the server and its dependencies are up, for container orchestration
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Returns nil when the dependency is reachable
+type Check func() error
+
+type Status struct {
+	Ready bool `json:"ready"`
+	// "ok" or the error of every dependency
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+func check(checks map[string]Check) Status {
+	status := Status{Ready: true, Dependencies: make(map[string]string, len(checks))}
+	for name, check := range checks {
+		if err := check(); err != nil {
+			status.Ready = false
+			status.Dependencies[name] = err.Error()
+		} else {
+			status.Dependencies[name] = "ok"
+		}
+	}
+	return status
+}
+
+// LiveHandler answers as long as the server is running
+func LiveHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+// ReadyHandler answers 503 while any of the checks fails, so traffic is only routed to servers that can handle it
+func ReadyHandler(checks map[string]Check) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := check(checks)
+		w.Header().Set("Content-Type", "application/json")
+		if !status.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(status)
+	}
+}
@@ -0,0 +1,33 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestReadyHandler(t *testing.T) {
+	redisErr := errors.New("connection refused")
+	checks := map[string]Check{
+		"postgres": func() error { return nil },
+		"redis":    func() error { return redisErr },
+	}
+
+	rec := httptest.NewRecorder()
+	ReadyHandler(checks)(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	utils.AssertEqual(t, http.StatusServiceUnavailable, rec.Code)
+	var status Status
+	utils.AssertEqual(t, nil, json.Unmarshal(rec.Body.Bytes(), &status))
+	utils.AssertEqual(t, false, status.Ready)
+	utils.AssertEqual(t, "ok", status.Dependencies["postgres"])
+	utils.AssertEqual(t, "connection refused", status.Dependencies["redis"])
+
+	checks["redis"] = func() error { return nil }
+	rec = httptest.NewRecorder()
+	ReadyHandler(checks)(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	utils.AssertEqual(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,63 @@
+// Package incidents keeps track of pool-wide incidents and announces them to a status page
+package incidents
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+)
+
+// Detector keys, an incident is open for each while the anomaly lasts
+const (
+	NoWorkersKey    = "no_workers"
+	WorkTimeoutsKey = "work_timeouts"
+)
+
+// An anomaly the detector found
+type Finding struct {
+	Key         string
+	Title       string
+	Description string
+	Severity    models.IncidentSeverity
+}
+
+// Looks for anomalies in the connected workers and the recent hub events
+func Detect(connectedWorkers int, events []models.HubEvent, now time.Time) []Finding {
+	findings := []Finding{}
+	if connectedWorkers == 0 {
+		findings = append(findings, Finding{
+			Key:         NoWorkersKey,
+			Title:       "No workers connected",
+			Description: "No workers are connected, work requests can't be served.",
+			Severity:    models.IncidentOutage,
+		})
+	}
+
+	since := now.Add(-config.INCIDENT_DETECTOR_WINDOW_MINUTES * time.Minute)
+	results, timeouts := 0, 0
+	for _, event := range events {
+		if event.Timestamp.Before(since) {
+			continue
+		}
+		switch event.Type {
+		case models.HubEventResult:
+			// Invalid results don't complete a request
+			if event.Detail == "" {
+				results++
+			}
+		case models.HubEventTimeout:
+			timeouts++
+		}
+	}
+	if total := results + timeouts; total >= config.INCIDENT_MIN_REQUESTS && float64(timeouts)/float64(total) > config.INCIDENT_TIMEOUT_RATIO {
+		findings = append(findings, Finding{
+			Key:         WorkTimeoutsKey,
+			Title:       "Work requests are timing out",
+			Description: fmt.Sprintf("%d of the last %d work requests timed out.", timeouts, total),
+			Severity:    models.IncidentDegraded,
+		})
+	}
+	return findings
+}
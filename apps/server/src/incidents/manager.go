@@ -0,0 +1,151 @@
+package incidents
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
+)
+
+type StatusEvent string
+
+const (
+	IncidentOpened   StatusEvent = "incident_opened"
+	IncidentResolved StatusEvent = "incident_resolved"
+)
+
+// Body of status page webhooks
+type StatusWebhook struct {
+	Event    StatusEvent      `json:"event"`
+	Incident *models.Incident `json:"incident"`
+}
+
+// Opens and resolves incidents, announcing every change to the status page webhook if one is set
+type Manager struct {
+	repo       repository.IncidentRepo
+	webhookURL string
+	client     *http.Client
+	startedAt  time.Time
+	// Serializes detector runs with admin changes
+	mu sync.Mutex
+}
+
+func NewManager(repo repository.IncidentRepo, webhookURL string) *Manager {
+	return &Manager{
+		repo:       repo,
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		startedAt:  time.Now(),
+	}
+}
+
+func (m *Manager) Declare(title string, description string, severity models.IncidentSeverity, declaredBy uuid.UUID) (*models.Incident, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	incident := &models.Incident{
+		Title:       title,
+		Description: description,
+		Severity:    severity,
+		DeclaredBy:  &declaredBy,
+	}
+	if err := m.repo.OpenIncident(incident); err != nil {
+		return nil, err
+	}
+	m.announce(IncidentOpened, incident)
+	return incident, nil
+}
+
+func (m *Manager) Resolve(id uuid.UUID) (*models.Incident, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	incident, err := m.repo.ResolveIncident(id)
+	if err != nil {
+		return nil, err
+	}
+	m.announce(IncidentResolved, incident)
+	return incident, nil
+}
+
+// Opens an incident for every new finding and resolves detector incidents whose anomaly is gone
+// Incidents declared by admins are left alone
+func (m *Manager) RunDetector(connectedWorkers int, events []models.HubEvent, now time.Time) error {
+	if now.Sub(m.startedAt) < config.INCIDENT_STARTUP_GRACE_MINUTES*time.Minute {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	open, err := m.repo.GetOpenIncidents()
+	if err != nil {
+		return err
+	}
+	openByKey := make(map[string]models.Incident)
+	for _, incident := range open {
+		if incident.DetectorKey != "" {
+			openByKey[incident.DetectorKey] = incident
+		}
+	}
+
+	for _, finding := range Detect(connectedWorkers, events, now) {
+		if _, ok := openByKey[finding.Key]; ok {
+			delete(openByKey, finding.Key)
+			continue
+		}
+		incident := &models.Incident{
+			Title:       finding.Title,
+			Description: finding.Description,
+			Severity:    finding.Severity,
+			DetectorKey: finding.Key,
+		}
+		if err := m.repo.OpenIncident(incident); err != nil {
+			return err
+		}
+		klog.Warningf("Opened incident: %s", incident.Title)
+		m.announce(IncidentOpened, incident)
+	}
+	// What's left is no longer detected
+	for _, incident := range openByKey {
+		resolved, err := m.repo.ResolveIncident(incident.ID)
+		if err != nil {
+			return err
+		}
+		klog.Infof("Resolved incident: %s", resolved.Title)
+		m.announce(IncidentResolved, resolved)
+	}
+	return nil
+}
+
+func (m *Manager) announce(event StatusEvent, incident *models.Incident) {
+	if m.webhookURL == "" {
+		return
+	}
+	b, err := json.Marshal(StatusWebhook{Event: event, Incident: incident})
+	if err != nil {
+		klog.Errorf("Error marshalling status webhook %v", err)
+		return
+	}
+	go func() {
+		if err := m.post(b); err != nil {
+			klog.Errorf("Error sending status webhook %v", err)
+		}
+	}()
+}
+
+func (m *Manager) post(body []byte) error {
+	resp, err := m.client.Post(m.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status webhook responded with %d", resp.StatusCode)
+	}
+	return nil
+}
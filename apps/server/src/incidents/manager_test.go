@@ -0,0 +1,115 @@
+package incidents
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+type fakeIncidentRepo struct {
+	incidents []*models.Incident
+}
+
+func (r *fakeIncidentRepo) OpenIncident(incident *models.Incident) error {
+	incident.ID = uuid.New()
+	r.incidents = append(r.incidents, incident)
+	return nil
+}
+
+func (r *fakeIncidentRepo) ResolveIncident(id uuid.UUID) (*models.Incident, error) {
+	for _, incident := range r.incidents {
+		if incident.ID == id && incident.ResolvedAt == nil {
+			now := time.Now()
+			incident.ResolvedAt = &now
+			return incident, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (r *fakeIncidentRepo) GetOpenIncidents() ([]models.Incident, error) {
+	ret := []models.Incident{}
+	for _, incident := range r.incidents {
+		if incident.ResolvedAt == nil {
+			ret = append(ret, *incident)
+		}
+	}
+	return ret, nil
+}
+
+func (r *fakeIncidentRepo) GetIncidentHistory() ([]models.Incident, error) {
+	return nil, nil
+}
+
+func TestDetect(t *testing.T) {
+	now := time.Now()
+	utils.AssertEqual(t, 0, len(Detect(1, nil, now)))
+	findings := Detect(0, nil, now)
+	utils.AssertEqual(t, 1, len(findings))
+	utils.AssertEqual(t, NoWorkersKey, findings[0].Key)
+
+	events := []models.HubEvent{}
+	for i := 0; i < 4; i++ {
+		events = append(events, models.HubEvent{Type: models.HubEventResult, Timestamp: now})
+	}
+	// Old timeouts don't count
+	for i := 0; i < 10; i++ {
+		events = append(events, models.HubEvent{Type: models.HubEventTimeout, Timestamp: now.Add(-time.Hour)})
+	}
+	utils.AssertEqual(t, 0, len(Detect(1, events, now)))
+	for i := 0; i < 6; i++ {
+		events = append(events, models.HubEvent{Type: models.HubEventTimeout, Timestamp: now})
+	}
+	findings = Detect(1, events, now)
+	utils.AssertEqual(t, 1, len(findings))
+	utils.AssertEqual(t, WorkTimeoutsKey, findings[0].Key)
+}
+
+func TestRunDetector(t *testing.T) {
+	announced := make(chan StatusWebhook, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body StatusWebhook
+		json.NewDecoder(r.Body).Decode(&body)
+		announced <- body
+	}))
+	defer server.Close()
+
+	repo := &fakeIncidentRepo{}
+	manager := NewManager(repo, server.URL)
+	now := time.Now()
+
+	// Nothing happens right after starting
+	utils.AssertEqual(t, nil, manager.RunDetector(0, nil, now))
+	utils.AssertEqual(t, 0, len(repo.incidents))
+
+	later := now.Add(10 * time.Minute)
+	utils.AssertEqual(t, nil, manager.RunDetector(0, nil, later))
+	utils.AssertEqual(t, 1, len(repo.incidents))
+	opened := <-announced
+	utils.AssertEqual(t, IncidentOpened, opened.Event)
+	utils.AssertEqual(t, NoWorkersKey, opened.Incident.DetectorKey)
+
+	// Still going, not opened twice
+	utils.AssertEqual(t, nil, manager.RunDetector(0, nil, later))
+	utils.AssertEqual(t, 1, len(repo.incidents))
+
+	// Admin incidents aren't resolved by the detector
+	_, err := manager.Declare("Payouts delayed", "", models.IncidentDegraded, uuid.New())
+	utils.AssertEqual(t, nil, err)
+	<-announced
+
+	utils.AssertEqual(t, nil, manager.RunDetector(5, nil, later))
+	resolved := <-announced
+	utils.AssertEqual(t, IncidentResolved, resolved.Event)
+	utils.AssertEqual(t, NoWorkersKey, resolved.Incident.DetectorKey)
+	open, _ := repo.GetOpenIncidents()
+	utils.AssertEqual(t, 1, len(open))
+	utils.AssertEqual(t, "Payouts delayed", open[0].Title)
+}
@@ -0,0 +1,135 @@
+// Package introspection lets sibling services (faucet, wallet backend) validate BoomPoW tokens in batches
+package introspection
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	"github.com/google/uuid"
+)
+
+// Most tokens introspected in one request
+const MaxTokens = 100
+
+type Request struct {
+	Tokens []string `json:"tokens"`
+}
+
+type Result struct {
+	Token    string   `json:"token"`
+	Valid    bool     `json:"valid"`
+	Type     string   `json:"type,omitempty"`
+	UserID   string   `json:"userId,omitempty"`
+	Owner    string   `json:"owner,omitempty"`
+	TenantID string   `json:"tenantId,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+}
+
+type Response struct {
+	Results []Result `json:"results"`
+}
+
+// Checks a token the same way the API does, service tokens start with service: and everything else is a JWT
+// Tokens of banned and disabled accounts aren't valid
+func Introspect(userRepo repository.UserRepo, apiKeyRepo repository.APIKeyRepo, token string) Result {
+	result := Result{Token: token}
+	user, authType := tokenUser(userRepo, apiKeyRepo, token)
+	if user == nil || user.Banned() || user.Disabled() {
+		return result
+	}
+	result.Valid = true
+	result.Type = "user"
+	if authType == "token" {
+		result.Type = "service"
+	}
+	result.UserID = user.ID.String()
+	result.Owner = user.Email
+	result.TenantID = user.TenantID
+	result.Scopes = middleware.Scopes(user, authType)
+	return result
+}
+
+// The user a token belongs to and the type of the token, nil if it isn't valid or its session was revoked
+func tokenUser(userRepo repository.UserRepo, apiKeyRepo repository.APIKeyRepo, token string) (*models.User, string) {
+	if strings.HasPrefix(token, "service:") {
+		if !middleware.IsServiceToken(token) {
+			return apiKeyUser(userRepo, apiKeyRepo, token)
+		}
+		userID, err := database.GetRedisDB().GetServiceTokenUser(token)
+		if err != nil {
+			return nil, ""
+		}
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			return nil, ""
+		}
+		user, err := userRepo.GetUser(&userUUID, nil)
+		if err != nil {
+			return nil, ""
+		}
+		return user, "token"
+	}
+	session, err := auth.ParseSessionToken(token, time.Now)
+	if err != nil {
+		return nil, ""
+	}
+	user, err := userRepo.GetUser(nil, &session.Email)
+	if err != nil || user.SessionRevoked(session.IssuedAt) {
+		return nil, ""
+	}
+	return user, "jwt"
+}
+
+// API keys are service tokens requesters generated themselves
+func apiKeyUser(userRepo repository.UserRepo, apiKeyRepo repository.APIKeyRepo, token string) (*models.User, string) {
+	if apiKeyRepo == nil {
+		return nil, ""
+	}
+	apiKey, err := apiKeyRepo.GetAPIKey(token)
+	if err != nil || apiKey == nil {
+		return nil, ""
+	}
+	user, err := userRepo.GetUser(&apiKey.UserID, nil)
+	if err != nil {
+		return nil, ""
+	}
+	return user, "token"
+}
+
+// Handler serves POST requests with a Request body, authenticated with the internal API key as a bearer token
+func Handler(userRepo repository.UserRepo, apiKeyRepo repository.APIKeyRepo, apiKey string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if apiKey == "" || subtle.ConstantTimeCompare([]byte(given), []byte(apiKey)) != 1 {
+			http.Error(w, "access denied", http.StatusUnauthorized)
+			return
+		}
+		var req Request
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		if len(req.Tokens) > MaxTokens {
+			http.Error(w, "too many tokens", http.StatusBadRequest)
+			return
+		}
+		resp := Response{Results: make([]Result, len(req.Tokens))}
+		for i, token := range req.Tokens {
+			resp.Results[i] = Introspect(userRepo, apiKeyRepo, token)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+}
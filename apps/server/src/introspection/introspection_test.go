@@ -0,0 +1,101 @@
+package introspection
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+// Only GetUser is used
+type fakeUserRepo struct {
+	repository.UserRepo
+	users []*models.User
+}
+
+func (f *fakeUserRepo) GetUser(id *uuid.UUID, email *string) (*models.User, error) {
+	for _, user := range f.users {
+		if (id != nil && user.ID == *id) || (email != nil && user.Email == *email) {
+			return user, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+// Only GetAPIKey is used
+type fakeAPIKeyRepo struct {
+	repository.APIKeyRepo
+	keys map[string]*models.APIKey
+}
+
+func (f *fakeAPIKeyRepo) GetAPIKey(key string) (*models.APIKey, error) {
+	return f.keys[key], nil
+}
+
+func introspect(t *testing.T, handler http.Handler, key string, tokens []string) (int, Response) {
+	body, _ := json.Marshal(Request{Tokens: tokens})
+	req := httptest.NewRequest(http.MethodPost, "/tokens/introspect", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+key)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	var resp Response
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec.Code, resp
+}
+
+func TestIntrospection(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	defer os.Unsetenv("MOCK_REDIS")
+	provider := &models.User{Base: models.Base{ID: uuid.New()}, Email: "provider@example.com", Type: models.PROVIDER, EmailVerified: true, TenantID: "default"}
+	service := &models.User{Base: models.Base{ID: uuid.New()}, Email: "service@example.com", Type: models.REQUESTER, EmailVerified: true, CanRequestWork: true, TenantID: "default"}
+	apiKey, _ := auth.GenerateAPIKey()
+	handler := Handler(&fakeUserRepo{users: []*models.User{provider, service}}, &fakeAPIKeyRepo{keys: map[string]*models.APIKey{apiKey: {UserID: service.ID}}}, "internal")
+
+	serviceToken := "service:" + uuid.NewString()
+	utils.AssertEqual(t, nil, database.GetRedisDB().AddServiceToken(service.ID, serviceToken))
+	jwt, err := auth.GenerateToken(provider.Email, time.Now)
+	utils.AssertEqual(t, nil, err)
+
+	code, _ := introspect(t, handler, "wrong", []string{jwt})
+	utils.AssertEqual(t, http.StatusUnauthorized, code)
+	code, _ = introspect(t, handler, "internal", make([]string, MaxTokens+1))
+	utils.AssertEqual(t, http.StatusBadRequest, code)
+
+	code, resp := introspect(t, handler, "internal", []string{jwt, serviceToken, "service:unknown", "garbage", apiKey})
+	utils.AssertEqual(t, http.StatusOK, code)
+	utils.AssertEqual(t, 5, len(resp.Results))
+	utils.AssertEqual(t, true, resp.Results[0].Valid)
+	utils.AssertEqual(t, "user", resp.Results[0].Type)
+	utils.AssertEqual(t, provider.Email, resp.Results[0].Owner)
+	utils.AssertEqual(t, []string{"user", "provider"}, resp.Results[0].Scopes)
+	utils.AssertEqual(t, true, resp.Results[1].Valid)
+	utils.AssertEqual(t, "service", resp.Results[1].Type)
+	utils.AssertEqual(t, service.ID.String(), resp.Results[1].UserID)
+	utils.AssertEqual(t, []string{"work_generate"}, resp.Results[1].Scopes)
+	utils.AssertEqual(t, false, resp.Results[2].Valid)
+	utils.AssertEqual(t, false, resp.Results[3].Valid)
+	// API keys are checked like service tokens
+	utils.AssertEqual(t, true, resp.Results[4].Valid)
+	utils.AssertEqual(t, "service", resp.Results[4].Type)
+	utils.AssertEqual(t, service.ID.String(), resp.Results[4].UserID)
+
+	// Revoked sessions and disabled accounts aren't valid
+	revokedAt := time.Now().Add(time.Hour)
+	provider.SessionsRevokedAt = &revokedAt
+	disabledAt := time.Now()
+	service.DisabledAt = &disabledAt
+	_, resp = introspect(t, handler, "internal", []string{jwt, serviceToken})
+	utils.AssertEqual(t, false, resp.Results[0].Valid)
+	utils.AssertEqual(t, false, resp.Results[1].Valid)
+}
@@ -0,0 +1,196 @@
+// Package logging adds log levels per subsystem on top of klog, they can be changed at runtime
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+type Subsystem string
+
+const (
+	Hub     Subsystem = "hub"
+	Auth    Subsystem = "auth"
+	Stats   Subsystem = "stats"
+	Payouts Subsystem = "payouts"
+	Redis   Subsystem = "redis"
+)
+
+var Subsystems = []Subsystem{Hub, Auth, Stats, Payouts, Redis}
+
+type Level int32
+
+const (
+	LevelError Level = iota
+	LevelWarning
+	LevelInfo
+	LevelDebug
+)
+
+// Every subsystem starts at this level
+const DefaultLevel = LevelInfo
+
+var levelNames = map[Level]string{
+	LevelError:   "error",
+	LevelWarning: "warning",
+	LevelInfo:    "info",
+	LevelDebug:   "debug",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+func ParseLevel(name string) (Level, error) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %s", name)
+}
+
+type subsystemLevel struct {
+	level atomic.Int32
+	// Bumped on every change, so a temporary level only reverts if nothing changed it since
+	generation atomic.Int64
+}
+
+var levels = func() map[Subsystem]*subsystemLevel {
+	ret := make(map[Subsystem]*subsystemLevel, len(Subsystems))
+	for _, subsystem := range Subsystems {
+		ret[subsystem] = &subsystemLevel{}
+		ret[subsystem].level.Store(int32(DefaultLevel))
+	}
+	return ret
+}()
+
+// Serializes changes, reads are lock free
+var setMu sync.Mutex
+
+func GetLevel(subsystem Subsystem) Level {
+	l, ok := levels[subsystem]
+	if !ok {
+		return DefaultLevel
+	}
+	return Level(l.level.Load())
+}
+
+func SetLevel(subsystem Subsystem, level Level) error {
+	_, err := setLevel(subsystem, level)
+	return err
+}
+
+func setLevel(subsystem Subsystem, level Level) (int64, error) {
+	l, ok := levels[subsystem]
+	if !ok {
+		return 0, fmt.Errorf("unknown subsystem %s", subsystem)
+	}
+	if _, ok := levelNames[level]; !ok {
+		return 0, fmt.Errorf("unknown log level %d", level)
+	}
+	setMu.Lock()
+	defer setMu.Unlock()
+	l.level.Store(int32(level))
+	return l.generation.Add(1), nil
+}
+
+// Sets the level for a while and goes back to the previous one, unless the level was changed again in the meantime
+func SetLevelFor(subsystem Subsystem, level Level, d time.Duration) error {
+	previous := GetLevel(subsystem)
+	generation, err := setLevel(subsystem, level)
+	if err != nil {
+		return err
+	}
+	time.AfterFunc(d, func() {
+		setMu.Lock()
+		defer setMu.Unlock()
+		l := levels[subsystem]
+		if l.generation.Load() == generation {
+			l.level.Store(int32(previous))
+			l.generation.Add(1)
+		}
+	})
+	return nil
+}
+
+// Applies a spec like "hub=debug,auth=warning", subsystems that aren't in it go back to the default level
+func ApplyLevels(spec string) error {
+	parsed := make(map[Subsystem]Level, len(Subsystems))
+	for _, subsystem := range Subsystems {
+		parsed[subsystem] = DefaultLevel
+	}
+	for _, part := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '\n' }) {
+		part = strings.TrimSpace(part)
+		if part == "" || strings.HasPrefix(part, "#") {
+			continue
+		}
+		name, levelName, ok := strings.Cut(part, "=")
+		if !ok {
+			return fmt.Errorf("expected subsystem=level, got %s", part)
+		}
+		subsystem := Subsystem(strings.ToLower(strings.TrimSpace(name)))
+		if _, ok := levels[subsystem]; !ok {
+			return fmt.Errorf("unknown subsystem %s", subsystem)
+		}
+		level, err := ParseLevel(strings.TrimSpace(levelName))
+		if err != nil {
+			return err
+		}
+		parsed[subsystem] = level
+	}
+	for subsystem, level := range parsed {
+		SetLevel(subsystem, level)
+	}
+	return nil
+}
+
+// Applies the levels in a file, one subsystem=level per line
+func LoadFile(path string) error {
+	if path == "" {
+		return errors.New("no log levels file configured")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return ApplyLevels(string(b))
+}
+
+func Enabled(subsystem Subsystem, level Level) bool {
+	return level <= GetLevel(subsystem)
+}
+
+func Debugf(subsystem Subsystem, format string, args ...interface{}) {
+	if Enabled(subsystem, LevelDebug) {
+		klog.InfoDepth(1, prefix(subsystem, format, args...))
+	}
+}
+
+func Infof(subsystem Subsystem, format string, args ...interface{}) {
+	if Enabled(subsystem, LevelInfo) {
+		klog.InfoDepth(1, prefix(subsystem, format, args...))
+	}
+}
+
+func Warningf(subsystem Subsystem, format string, args ...interface{}) {
+	if Enabled(subsystem, LevelWarning) {
+		klog.WarningDepth(1, prefix(subsystem, format, args...))
+	}
+}
+
+// Errors are always logged
+func Errorf(subsystem Subsystem, format string, args ...interface{}) {
+	klog.ErrorDepth(1, prefix(subsystem, format, args...))
+}
+
+func prefix(subsystem Subsystem, format string, args ...interface{}) string {
+	return fmt.Sprintf("[%s] %s", subsystem, fmt.Sprintf(format, args...))
+}
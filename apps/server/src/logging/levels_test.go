@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestApplyLevels(t *testing.T) {
+	utils.AssertEqual(t, nil, ApplyLevels("hub=debug, auth=WARNING"))
+	utils.AssertEqual(t, LevelDebug, GetLevel(Hub))
+	utils.AssertEqual(t, LevelWarning, GetLevel(Auth))
+	utils.AssertEqual(t, LevelInfo, GetLevel(Stats))
+	utils.AssertEqual(t, true, Enabled(Hub, LevelDebug))
+	utils.AssertEqual(t, false, Enabled(Auth, LevelInfo))
+	utils.AssertEqual(t, true, Enabled(Auth, LevelError))
+
+	// Subsystems left out go back to the default
+	utils.AssertEqual(t, nil, ApplyLevels("stats=error"))
+	utils.AssertEqual(t, LevelInfo, GetLevel(Hub))
+	utils.AssertEqual(t, LevelError, GetLevel(Stats))
+
+	utils.AssertNotEqual(t, nil, ApplyLevels("nope=debug"))
+	utils.AssertNotEqual(t, nil, ApplyLevels("hub=loud"))
+	utils.AssertNotEqual(t, nil, ApplyLevels("hub"))
+	// A bad spec changes nothing
+	utils.AssertEqual(t, LevelError, GetLevel(Stats))
+	utils.AssertEqual(t, nil, ApplyLevels(""))
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "levels")
+	utils.AssertEqual(t, nil, os.WriteFile(path, []byte("# comment\nredis=debug\npayouts=warning\n"), 0644))
+	utils.AssertEqual(t, nil, LoadFile(path))
+	utils.AssertEqual(t, LevelDebug, GetLevel(Redis))
+	utils.AssertEqual(t, LevelWarning, GetLevel(Payouts))
+	utils.AssertNotEqual(t, nil, LoadFile(""))
+	utils.AssertEqual(t, nil, ApplyLevels(""))
+}
+
+func TestSetLevelFor(t *testing.T) {
+	utils.AssertEqual(t, nil, SetLevelFor(Hub, LevelDebug, 10*time.Millisecond))
+	utils.AssertEqual(t, LevelDebug, GetLevel(Hub))
+	time.Sleep(50 * time.Millisecond)
+	utils.AssertEqual(t, LevelInfo, GetLevel(Hub))
+
+	// Changed again in the meantime, so it isn't reverted
+	utils.AssertEqual(t, nil, SetLevelFor(Auth, LevelDebug, 10*time.Millisecond))
+	utils.AssertEqual(t, nil, SetLevel(Auth, LevelError))
+	time.Sleep(50 * time.Millisecond)
+	utils.AssertEqual(t, LevelError, GetLevel(Auth))
+
+	utils.AssertNotEqual(t, nil, SetLevel("nope", LevelDebug))
+	utils.AssertEqual(t, nil, ApplyLevels(""))
+}
@@ -0,0 +1,85 @@
+// Package maintenance keeps the scheduled maintenance windows in memory, they're checked on every request
+package maintenance
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+)
+
+type Schedule struct {
+	repo repository.MaintenanceRepo
+	mu   sync.RWMutex
+	// Soonest first, including windows that ended recently
+	windows []models.MaintenanceWindow
+}
+
+func NewSchedule(repo repository.MaintenanceRepo) *Schedule {
+	return &Schedule{
+		repo: repo,
+	}
+}
+
+// Reloads the windows from the database, ended windows are kept as long as an offline alert could still be affected by them
+func (s *Schedule) Refresh(now time.Time) error {
+	windows, err := s.repo.GetMaintenanceWindows(now.Add(-config.OFFLINE_ALERT_MAX_MINUTES * time.Minute))
+	if err != nil {
+		return err
+	}
+	s.set(windows)
+	return nil
+}
+
+func (s *Schedule) set(windows []models.MaintenanceWindow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.windows = windows
+}
+
+// The window in progress at now, nil if there isn't one
+func (s *Schedule) Active(now time.Time) *models.MaintenanceWindow {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.windows {
+		if s.windows[i].ActiveAt(now) {
+			window := s.windows[i]
+			return &window
+		}
+	}
+	return nil
+}
+
+// The window in progress, or else the first one starting within the given duration
+func (s *Schedule) Next(now time.Time, within time.Duration) *models.MaintenanceWindow {
+	if active := s.Active(now); active != nil {
+		return active
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.windows {
+		if s.windows[i].StartsAt.After(now) && s.windows[i].StartsAt.Sub(now) <= within {
+			window := s.windows[i]
+			return &window
+		}
+	}
+	return nil
+}
+
+// Moves since to the end of any window that overlaps since..now, so time around maintenance doesn't count against workers
+// The result is after now while maintenance is still in progress
+func (s *Schedule) DiscountMaintenance(since time.Time, now time.Time) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, window := range s.windows {
+		if window.StartsAt.After(now) {
+			break
+		}
+		if window.EndsAt.After(since) {
+			since = window.EndsAt
+		}
+	}
+	return since
+}
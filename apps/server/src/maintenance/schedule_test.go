@@ -0,0 +1,35 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestSchedule(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	schedule := NewSchedule(nil)
+	utils.AssertEqual(t, true, schedule.Active(now) == nil)
+	utils.AssertEqual(t, true, schedule.Next(now, time.Hour) == nil)
+	utils.AssertEqual(t, now.Add(-time.Hour), schedule.DiscountMaintenance(now.Add(-time.Hour), now))
+
+	schedule.set([]models.MaintenanceWindow{
+		{Description: "ended", StartsAt: now.Add(-3 * time.Hour), EndsAt: now.Add(-2 * time.Hour)},
+		{Description: "upcoming", StartsAt: now.Add(2 * time.Hour), EndsAt: now.Add(3 * time.Hour)},
+	})
+	utils.AssertEqual(t, true, schedule.Active(now) == nil)
+	utils.AssertEqual(t, true, schedule.Next(now, time.Hour) == nil)
+	utils.AssertEqual(t, "upcoming", schedule.Next(now, 2*time.Hour).Description)
+	// Offline since before the ended window counts from its end
+	utils.AssertEqual(t, now.Add(-2*time.Hour), schedule.DiscountMaintenance(now.Add(-4*time.Hour), now))
+	utils.AssertEqual(t, now.Add(-time.Hour), schedule.DiscountMaintenance(now.Add(-time.Hour), now))
+
+	// Start and end are inclusive and exclusive
+	utils.AssertEqual(t, "upcoming", schedule.Active(now.Add(2*time.Hour)).Description)
+	utils.AssertEqual(t, true, schedule.Active(now.Add(3*time.Hour)) == nil)
+	utils.AssertEqual(t, "upcoming", schedule.Next(now.Add(150*time.Minute), 0).Description)
+	// Still in maintenance
+	utils.AssertEqual(t, now.Add(3*time.Hour), schedule.DiscountMaintenance(now, now.Add(150*time.Minute)))
+}
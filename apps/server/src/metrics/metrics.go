@@ -0,0 +1,176 @@
+// Package metrics collects the server's Prometheus metrics, they're scraped from /metrics on the internal port
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Everything /metrics serves, along with the Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// Outcome is cached, solved or failed
+	WorkRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "boompow",
+		Name:      "work_requests_total",
+		Help:      "Work requests by outcome.",
+	}, []string{"outcome"})
+	// From the request going out to a worker until its result came back, by dispatch priority
+	SolveSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "boompow",
+		Name:      "work_solve_seconds",
+		Help:      "Time workers took to solve work requests.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 11),
+	}, []string{"priority"})
+	// Store is redis or postgres
+	StoreErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "boompow",
+		Name:      "store_errors_total",
+		Help:      "Failed redis commands and postgres queries, misses aren't counted.",
+	}, []string{"store", "operation"})
+	// Tier and difficulty class of the requests an admin's load shedding refused
+	ShedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "boompow",
+		Name:      "shed_requests_total",
+		Help:      "Work requests refused while load is shed.",
+	}, []string{"tier", "class"})
+	// Messages the hub's full dispatch queue dropped, by priority
+	DroppedBroadcasts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "boompow",
+		Name:      "broadcasts_dropped_total",
+		Help:      "Messages dropped because the hub's dispatch queue was full.",
+	}, []string{"priority"})
+	// Only top level fields, nested fields are mostly plain struct fields
+	ResolverSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "boompow",
+		Name:      "graphql_resolver_seconds",
+		Help:      "Time spent in GraphQL query, mutation and subscription resolvers.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"object", "field", "status"})
+	RedisUsedMemory = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "boompow",
+		Name:      "redis_used_memory_bytes",
+		Help:      "Memory redis uses.",
+	})
+	// 0 without a limit
+	RedisMaxMemory = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "boompow",
+		Name:      "redis_max_memory_bytes",
+		Help:      "The maxmemory of redis.",
+	})
+	// 1 when the eviction policy may evict the payout counters
+	RedisCountersEvictable = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "boompow",
+		Name:      "redis_counters_evictable",
+		Help:      "Whether redis may evict the payout counters once it's full.",
+	})
+	// Prefix is the part of the key up to its first colon, or other for the smaller ones
+	RedisPrefixMemory = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "boompow",
+		Name:      "redis_prefix_memory_bytes",
+		Help:      "Memory of the redis keys with a prefix.",
+	}, []string{"prefix"})
+	RedisPrefixKeys = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "boompow",
+		Name:      "redis_prefix_keys",
+		Help:      "Redis keys with a prefix.",
+	}, []string{"prefix"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		WorkRequests,
+		SolveSeconds,
+		StoreErrors,
+		ShedRequests,
+		DroppedBroadcasts,
+		ResolverSeconds,
+		RedisUsedMemory,
+		RedisMaxMemory,
+		RedisCountersEvictable,
+		RedisPrefixMemory,
+		RedisPrefixKeys,
+	)
+}
+
+// Hub state is read when it's scraped, the server registers it once the hub is running
+func RegisterHub(registry prometheus.Registerer, workers func() int, statsQueue func() int, broadcastQueue func() int, validationBacklog func() int) error {
+	gauges := []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "boompow",
+			Name:      "connected_workers",
+			Help:      "Workers connected to this server.",
+		}, func() float64 { return float64(workers()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "boompow",
+			Name:      "stats_queue_depth",
+			Help:      "Results waiting to be recorded by the stats job.",
+		}, func() float64 { return float64(statsQueue()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "boompow",
+			Name:      "broadcast_queue_depth",
+			Help:      "Messages waiting for the hub to send them to workers.",
+		}, func() float64 { return float64(broadcastQueue()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "boompow",
+			Name:      "validation_backlog",
+			Help:      "Work results waiting to be validated.",
+		}, func() float64 { return float64(validationBacklog()) }),
+	}
+	for _, gauge := range gauges {
+		if err := registry.Register(gauge); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ObserveSolve(priority string, solve time.Duration) {
+	SolveSeconds.WithLabelValues(priority).Observe(solve.Seconds())
+}
+
+func CountStoreError(store string, operation string) {
+	StoreErrors.WithLabelValues(store, operation).Inc()
+}
+
+func CountShed(tier string, class string) {
+	ShedRequests.WithLabelValues(tier, class).Inc()
+}
+
+func CountDroppedBroadcast(priority string) {
+	DroppedBroadcasts.WithLabelValues(priority).Inc()
+}
+
+type RedisPrefixUsage struct {
+	Keys  int
+	Bytes int64
+}
+
+// Replaces the redis memory gauges, prefixes that are gone are dropped
+func SetRedisMemory(used int64, max int64, countersEvictable bool, prefixes map[string]RedisPrefixUsage) {
+	RedisUsedMemory.Set(float64(used))
+	RedisMaxMemory.Set(float64(max))
+	evictable := 0.0
+	if countersEvictable {
+		evictable = 1
+	}
+	RedisCountersEvictable.Set(evictable)
+	RedisPrefixMemory.Reset()
+	RedisPrefixKeys.Reset()
+	for prefix, usage := range prefixes {
+		RedisPrefixMemory.WithLabelValues(prefix).Set(float64(usage.Bytes))
+		RedisPrefixKeys.WithLabelValues(prefix).Set(float64(usage.Keys))
+	}
+}
+
+// Serves the registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
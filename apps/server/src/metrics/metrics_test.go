@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegisterHub(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	workers := 3
+	err := RegisterHub(registry, func() int { return workers }, func() int { return 7 }, func() int { return 2 }, func() int { return 5 })
+	utils.AssertEqual(t, nil, err)
+	// The hub is read when it's scraped
+	workers = 4
+	count, err := testutil.GatherAndCount(registry, "boompow_connected_workers", "boompow_stats_queue_depth", "boompow_broadcast_queue_depth", "boompow_validation_backlog")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 4, count)
+	err = testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP boompow_connected_workers Workers connected to this server.
+# TYPE boompow_connected_workers gauge
+boompow_connected_workers 4
+`), "boompow_connected_workers")
+	utils.AssertEqual(t, nil, err)
+
+	// Only once per registry
+	utils.AssertNotEqual(t, nil, RegisterHub(registry, func() int { return 0 }, func() int { return 0 }, func() int { return 0 }, func() int { return 0 }))
+}
+
+func TestHandler(t *testing.T) {
+	CountStoreError("redis", "get")
+	ObserveSolve("on_demand", 300*time.Millisecond)
+	CountShed("standard", "base")
+	utils.AssertEqual(t, 1.0, testutil.ToFloat64(StoreErrors.WithLabelValues("redis", "get")))
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	utils.AssertEqual(t, 200, recorder.Code)
+	body, _ := io.ReadAll(recorder.Body)
+	utils.AssertEqual(t, true, strings.Contains(string(body), `boompow_store_errors_total{operation="get",store="redis"} 1`))
+	utils.AssertEqual(t, true, strings.Contains(string(body), `boompow_work_solve_seconds_count{priority="on_demand"} 1`))
+	utils.AssertEqual(t, true, strings.Contains(string(body), `boompow_shed_requests_total{class="base",tier="standard"} 1`))
+	utils.AssertEqual(t, true, strings.Contains(string(body), "go_goroutines"))
+}
+
+func TestSetRedisMemory(t *testing.T) {
+	SetRedisMemory(900, 1000, true, map[string]RedisPrefixUsage{"cache:": {Keys: 3, Bytes: 300}, "gone:": {Keys: 1, Bytes: 10}})
+	SetRedisMemory(800, 1000, false, map[string]RedisPrefixUsage{"cache:": {Keys: 2, Bytes: 200}})
+	utils.AssertEqual(t, 800.0, testutil.ToFloat64(RedisUsedMemory))
+	utils.AssertEqual(t, 1000.0, testutil.ToFloat64(RedisMaxMemory))
+	utils.AssertEqual(t, 0.0, testutil.ToFloat64(RedisCountersEvictable))
+	utils.AssertEqual(t, 200.0, testutil.ToFloat64(RedisPrefixMemory.WithLabelValues("cache:")))
+	utils.AssertEqual(t, 2.0, testutil.ToFloat64(RedisPrefixKeys.WithLabelValues("cache:")))
+	// Prefixes that are gone aren't reported anymore
+	utils.AssertEqual(t, 1, testutil.CollectAndCount(RedisPrefixKeys))
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/go-chi/httprate"
+)
+
+var errInvalidAPIKey = errors.New("invalid api key")
+
+// Service tokens are the ones redis knows, bootstrapped ones included, other tokens starting with service: are API keys
+func IsServiceToken(token string) bool {
+	_, err := database.GetRedisDB().GetServiceTokenUser(token)
+	return err == nil
+}
+
+// Puts the owner of an API key in the context, API keys act like service tokens
+func withAPIKeyUser(ctx context.Context, key string, userRepo *repository.UserService, apiKeyRepo repository.APIKeyRepo) (context.Context, error) {
+	if apiKeyRepo == nil {
+		return ctx, errInvalidAPIKey
+	}
+	apiKey, err := apiKeyRepo.GetAPIKey(key)
+	if err != nil || apiKey == nil {
+		return ctx, errInvalidAPIKey
+	}
+	user, err := userRepo.GetUser(&apiKey.UserID, nil)
+	if err != nil {
+		return ctx, errInvalidAPIKey
+	}
+	if err := apiKeyRepo.TouchAPIKey(apiKey, time.Now()); err != nil {
+		logging.Errorf(logging.Auth, "Error recording use of api key %s %v", apiKey.ID, err)
+	}
+	return context.WithValue(ctx, userCtxKey, &UserContextValue{User: user, AuthType: "token", APIKey: apiKey}), nil
+}
+
+// RequestAPIKey returns the API key the request was made with, nil for any other kind of token
+func RequestAPIKey(ctx context.Context) *models.APIKey {
+	contextValue := forContext(ctx)
+	if contextValue == nil {
+		return nil
+	}
+	return contextValue.APIKey
+}
+
+// APIKeyRateLimit limits requests made with an API key to the key's own rate limit, keys without one aren't limited
+// Like the other rate limiters the counts are kept by each server
+func APIKeyRateLimit() func(http.Handler) http.Handler {
+	var mu sync.Mutex
+	// One limiter per rate, keyed by the API key
+	limiters := make(map[int]func(http.Handler) http.Handler)
+	limiter := func(perMinute int) func(http.Handler) http.Handler {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := limiters[perMinute]; !ok {
+			limiters[perMinute] = httprate.Limit(perMinute, time.Minute, httprate.WithKeyFuncs(func(r *http.Request) (string, error) {
+				return RequestAPIKey(r.Context()).ID.String(), nil
+			}))
+		}
+		return limiters[perMinute]
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey := RequestAPIKey(r.Context())
+			if apiKey == nil || apiKey.RateLimitPerMinute == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limiter(*apiKey.RateLimitPerMinute)(next).ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+func TestAPIKeyRateLimit(t *testing.T) {
+	handler := APIKeyRateLimit()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	request := func(apiKey *models.APIKey) int {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		if apiKey != nil {
+			req = req.WithContext(context.WithValue(req.Context(), userCtxKey, &UserContextValue{User: &models.User{}, AuthType: "token", APIKey: apiKey}))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	limit := 2
+	limited := &models.APIKey{Base: models.Base{ID: uuid.New()}, RateLimitPerMinute: &limit}
+	utils.AssertEqual(t, http.StatusOK, request(limited))
+	utils.AssertEqual(t, http.StatusOK, request(limited))
+	utils.AssertEqual(t, http.StatusTooManyRequests, request(limited))
+
+	// Every key has its own quota, and keys without a limit aren't limited
+	other := &models.APIKey{Base: models.Base{ID: uuid.New()}, RateLimitPerMinute: &limit}
+	utils.AssertEqual(t, http.StatusOK, request(other))
+	unlimited := &models.APIKey{Base: models.Base{ID: uuid.New()}}
+	for i := 0; i < 5; i++ {
+		utils.AssertEqual(t, http.StatusOK, request(unlimited))
+	}
+	utils.AssertEqual(t, http.StatusOK, request(nil))
+}
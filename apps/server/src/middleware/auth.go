@@ -3,11 +3,15 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
 	"github.com/bananocoin/boompow/apps/server/src/models"
 	"github.com/bananocoin/boompow/apps/server/src/repository"
 	"github.com/bananocoin/boompow/libs/utils"
@@ -15,13 +19,21 @@ import (
 	"github.com/bananocoin/boompow/libs/utils/net"
 	"github.com/google/uuid"
 	"golang.org/x/exp/slices"
-	"k8s.io/klog/v2"
 )
 
 // We distinguish the type of authentication so we can restrict service tokens to only be used for work requests
 type UserContextValue struct {
 	User     *models.User
 	AuthType string
+	// Set when the request was made with an API key
+	APIKey *models.APIKey
+	// Set when the token carried a .<source> suffix
+	Source *models.WorkSource
+	// Set when the request was made with a reset password link, the change password mutation consumes it
+	ResetPasswordToken string
+	// Whether the JWT's session was signed in with a second factor
+	TwoFactor bool
+	cache     userCache
 }
 
 var userCtxKey = &contextKey{"user"}
@@ -38,7 +50,26 @@ func formatGraphqlError(ctx context.Context, msg string) string {
 	return string(marshalled)
 }
 
-func AuthMiddleware(userRepo *repository.UserService) func(http.Handler) http.Handler {
+// Clients refresh their access token when a request fails with this code, instead of logging in again
+const TokenExpiredCode = "TOKEN_EXPIRED"
+
+// Requests of banned users fail with this code, refreshing or logging in again won't help
+const BannedCode = "BANNED"
+
+var ErrBanned = errors.New("account banned")
+
+func formatGraphqlErrorWithCode(ctx context.Context, msg string, code string) string {
+	resp := graphql.ErrorResponse(ctx, msg)
+	resp.Errors[0].Extensions = map[string]interface{}{"code": code}
+	marshalled, err := json.Marshal(resp)
+	if err != nil {
+		return "\"errors\": [{\"message\": \"Unknown\"}]"
+	}
+	return string(marshalled)
+}
+
+// Rejected tokens are recorded in the audit log if auditRepo isn't nil
+func AuthMiddleware(userRepo *repository.UserService, apiKeyRepo repository.APIKeyRepo, sourceRepo repository.WorkSourceRepo, auditRepo repository.AuditLogRepo) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// There are two types of tokens
@@ -53,18 +84,18 @@ func AuthMiddleware(userRepo *repository.UserService) func(http.Handler) http.Ha
 			}
 
 			var ctx context.Context
+			// Service tokens and API keys may name the work source they're used by
+			var source string
+			if strings.HasPrefix(header, "service:") {
+				header, source = models.SplitWorkSource(header)
+			}
 
 			// Determine token type
 			if strings.HasPrefix(header, "resetpassword:") {
-				token := header[len("resetpassword:"):]
-				email, err := auth.ParseToken(token)
-				if err != nil {
-					http.Error(w, formatGraphqlError(r.Context(), "Invalid Token"), http.StatusForbidden)
-					return
-				}
-				// Get from redis
-				_, err = database.GetRedisDB().GetResetPasswordToken(email)
+				// Only the latest link sent to the email works, and only until it's used
+				email, err := userRepo.CheckResetPasswordToken(header)
 				if err != nil {
+					recordRejectedToken(auditRepo, r, "reset password link")
 					http.Error(w, formatGraphqlError(r.Context(), "Invalid Token"), http.StatusForbidden)
 					return
 				}
@@ -74,18 +105,24 @@ func AuthMiddleware(userRepo *repository.UserService) func(http.Handler) http.Ha
 					next.ServeHTTP(w, r)
 					return
 				}
-				// put it in context
-				ctx = context.WithValue(r.Context(), userCtxKey, &UserContextValue{User: user, AuthType: "token"})
-			} else if strings.HasPrefix(header, "service:") {
-				// Service token
-				if !slices.Contains(utils.GetServiceTokens(), header) {
-					klog.Errorf("INVALID TOKEN ATTEMPT 1 %s:%s", header, net.GetIPAddress(r))
+				// put it in context, it only authorizes changing the password
+				ctx = context.WithValue(r.Context(), userCtxKey, &UserContextValue{User: user, AuthType: "resetpassword", ResetPasswordToken: header})
+			} else if strings.HasPrefix(header, "service:") && !IsServiceToken(header) {
+				// API keys requesters generated themselves
+				var err error
+				ctx, err = withAPIKeyUser(r.Context(), header, userRepo, apiKeyRepo)
+				if err != nil {
+					logging.Errorf(logging.Auth, "INVALID TOKEN ATTEMPT 1 %s:%s", auth.APIKeyHint(header), net.GetIPAddress(r))
+					recordRejectedToken(auditRepo, r, "API key "+auth.APIKeyHint(header))
 					http.Error(w, formatGraphqlError(r.Context(), "Invalid Token"), http.StatusForbidden)
 					return
 				}
+			} else if strings.HasPrefix(header, "service:") {
+				// Service token
 				userID, err := database.GetRedisDB().GetServiceTokenUser(header)
 				if err != nil {
-					klog.Errorf("INVALID TOKEN ATTEMPT %s:%s", header, net.GetIPAddress(r))
+					logging.Errorf(logging.Auth, "INVALID TOKEN ATTEMPT %s:%s", header, net.GetIPAddress(r))
+					recordRejectedToken(auditRepo, r, "service token "+auth.APIKeyHint(header))
 					http.Error(w, formatGraphqlError(r.Context(), "Invalid Token"), http.StatusForbidden)
 					return
 				}
@@ -103,21 +140,46 @@ func AuthMiddleware(userRepo *repository.UserService) func(http.Handler) http.Ha
 				// put it in context
 				ctx = context.WithValue(r.Context(), userCtxKey, &UserContextValue{User: user, AuthType: "token"})
 			} else {
-				tokenStr := header
-				email, err := auth.ParseToken(tokenStr)
+				var err error
+				ctx, err = WithJWTUser(r.Context(), header, userRepo)
+				if errors.Is(err, auth.ErrTokenExpired) {
+					http.Error(w, formatGraphqlErrorWithCode(r.Context(), "Token expired", TokenExpiredCode), http.StatusUnauthorized)
+					return
+				}
+				if errors.Is(err, ErrBanned) {
+					http.Error(w, formatGraphqlErrorWithCode(r.Context(), "Account banned", BannedCode), http.StatusForbidden)
+					return
+				}
 				if err != nil {
+					recordRejectedToken(auditRepo, r, "JWT")
 					http.Error(w, formatGraphqlError(r.Context(), "Invalid Token"), http.StatusForbidden)
 					return
 				}
-				// create user and check if user exists in db
-				user, err := userRepo.GetUser(nil, &email)
-				if err != nil {
+				if forContext(ctx) == nil {
 					next.ServeHTTP(w, r)
 					return
 				}
-				// put it in context
-				ctx = context.WithValue(r.Context(), userCtxKey, &UserContextValue{User: user, AuthType: "jwt"})
+			}
+
+			if source != "" {
+				var err error
+				ctx, err = withWorkSource(ctx, source, sourceRepo)
+				if err != nil {
+					http.Error(w, formatGraphqlError(r.Context(), "Invalid Token"), http.StatusForbidden)
+					return
+				}
+			}
 
+			// Service and password reset tokens of banned users don't work either
+			if contextValue := forContext(ctx); contextValue != nil && contextValue.User.Banned() {
+				http.Error(w, formatGraphqlErrorWithCode(r.Context(), "Account banned", BannedCode), http.StatusForbidden)
+				return
+			}
+
+			// Users can only act within their own pool
+			if contextValue := forContext(ctx); contextValue != nil && tenantMismatch(ctx, contextValue.User.TenantID) {
+				http.Error(w, formatGraphqlError(r.Context(), "Invalid Token"), http.StatusForbidden)
+				return
 			}
 
 			// and call the next with our new context
@@ -127,6 +189,93 @@ func AuthMiddleware(userRepo *repository.UserService) func(http.Handler) http.Ha
 	}
 }
 
+// WithJWTUser puts the user of a JWT token in the context, unknown users are left unauthenticated
+// Tokens of revoked sessions and banned users are rejected
+func WithJWTUser(ctx context.Context, tokenStr string, userRepo *repository.UserService) (context.Context, error) {
+	session, err := auth.ParseSessionToken(tokenStr, time.Now)
+	if err != nil {
+		return ctx, err
+	}
+	return withSession(ctx, session, userRepo)
+}
+
+// Puts the user of a session in the context, unless they're banned or the session was revoked
+func withSession(ctx context.Context, session auth.Session, userRepo *repository.UserService) (context.Context, error) {
+	ctx = withUser(ctx, session.Email, userRepo)
+	if contextValue := forContext(ctx); contextValue != nil {
+		if contextValue.User.Banned() {
+			return ctx, ErrBanned
+		}
+		if contextValue.User.SessionRevoked(session.IssuedAt) {
+			return ctx, errSessionRevoked
+		}
+		contextValue.TwoFactor = session.TwoFactor
+	}
+	return ctx, nil
+}
+
+// Expired tokens aren't recorded, clients refresh them all the time
+// Only a hint of the token is kept, never one that would work
+func recordRejectedToken(auditRepo repository.AuditLogRepo, r *http.Request, token string) {
+	if auditRepo == nil {
+		return
+	}
+	err := auditRepo.RecordAuditEvent(&models.AuditLogEntry{
+		Action:   models.AuditTokenRejected,
+		Detail:   token,
+		ClientIP: net.GetIPAddress(r),
+	})
+	if err != nil {
+		logging.Errorf(logging.Auth, "Error recording rejected token %v", err)
+	}
+}
+
+// Sensitive operations fail with this for users with two factor authentication whose session was signed in without it
+var ErrTwoFactorRequired = errors.New("two_factor_required")
+
+// RequireTwoFactor guards sensitive operations, users with two factor authentication have to be signed in with it
+// Sessions from before it was enabled have to sign in again
+func RequireTwoFactor(ctx context.Context) error {
+	contextValue := forContext(ctx)
+	if contextValue == nil || contextValue.User == nil || !contextValue.User.TwoFactorEnabled || contextValue.TwoFactor {
+		return nil
+	}
+	return ErrTwoFactorRequired
+}
+
+var errSessionRevoked = errors.New("session revoked")
+
+func withUser(ctx context.Context, email string, userRepo *repository.UserService) context.Context {
+	// create user and check if user exists in db
+	user, err := userRepo.GetUser(nil, &email)
+	if err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, userCtxKey, &UserContextValue{User: user, AuthType: "jwt"})
+}
+
+// Init payload field for short lived websocket tokens from the generateWebsocketToken mutation
+const WebsocketTokenField = "wsToken"
+
+// WebsocketInit authenticates subscriptions, browsers can't set headers on websockets so the token comes in the init payload
+// Browsers should send a websocket token, so the long lived JWT never leaves the page
+func WebsocketInit(userRepo *repository.UserService) func(ctx context.Context, initPayload transport.InitPayload) (context.Context, error) {
+	return func(ctx context.Context, initPayload transport.InitPayload) (context.Context, error) {
+		if wsToken := initPayload.GetString(WebsocketTokenField); wsToken != "" {
+			session, err := auth.ParseScopedSessionToken(wsToken, auth.WebsocketPurpose, time.Now)
+			if err != nil {
+				return ctx, err
+			}
+			return withSession(ctx, session, userRepo)
+		}
+		token := initPayload.Authorization()
+		if token == "" {
+			return ctx, nil
+		}
+		return WithJWTUser(ctx, token, userRepo)
+	}
+}
+
 // forContext finds the user from the context. REQUIRES Middleware to have run.
 func forContext(ctx context.Context) *UserContextValue {
 	raw, _ := ctx.Value(userCtxKey).(*UserContextValue)
@@ -169,11 +318,68 @@ func AuthorizedServiceToken(ctx context.Context) *UserContextValue {
 	return contextValue
 }
 
+// AuthorizedAdmin returns user from context if they are logged in and listed as an admin or have the admin role
+func AuthorizedAdmin(ctx context.Context) *UserContextValue {
+	contextValue := AuthorizedUser(ctx)
+	if contextValue == nil || !isAdmin(contextValue.User) {
+		return nil
+	}
+	return contextValue
+}
+
+func isAdmin(user *models.User) bool {
+	if !user.EmailVerified || user.Banned() {
+		return false
+	}
+	return slices.Contains(utils.GetAdminEmails(), strings.ToLower(user.Email)) || user.HasRole(models.RoleAdmin)
+}
+
+// HasPermission is whether the logged in user may do what permission allows, admins may do everything
+func HasPermission(ctx context.Context, permission models.Permission) bool {
+	contextValue := AuthorizedUser(ctx)
+	return contextValue != nil && UserHasPermission(contextValue.User, permission)
+}
+
+// UserHasPermission is HasPermission for a user that isn't necessarily the one making the request
+func UserHasPermission(user *models.User, permission models.Permission) bool {
+	return isAdmin(user) || (user.EmailVerified && user.HasPermission(permission))
+}
+
+// Scopes lists what a token of authType ("jwt" or "token") for user is allowed to do, for sibling services introspecting tokens
+func Scopes(user *models.User, authType string) []string {
+	ctx := context.WithValue(context.Background(), userCtxKey, &UserContextValue{User: user, AuthType: authType})
+	scopes := []string{}
+	for _, scope := range []struct {
+		name    string
+		checker func(context.Context) *UserContextValue
+	}{
+		{"user", AuthorizedUser},
+		{"provider", AuthorizedProvider},
+		{"requester", AuthorizedRequester},
+		{"work_generate", AuthorizedServiceToken},
+		{"admin", AuthorizedAdmin},
+	} {
+		if scope.checker(ctx) != nil {
+			scopes = append(scopes, scope.name)
+		}
+	}
+	return scopes
+}
+
 // AuthorizedChangePassword getsuser from context if they are authorized to change their password
 func AuthorizedChangePassword(ctx context.Context) *UserContextValue {
 	contextValue := forContext(ctx)
-	if contextValue == nil || contextValue.User == nil || contextValue.AuthType != "token" {
+	if contextValue == nil || contextValue.User == nil || contextValue.AuthType != "resetpassword" {
 		return nil
 	}
 	return contextValue
 }
+
+// RequestUser returns the user of any kind of token, nil for anonymous requests
+func RequestUser(ctx context.Context) *models.User {
+	contextValue := forContext(ctx)
+	if contextValue == nil {
+		return nil
+	}
+	return contextValue.User
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestAuthMiddlewareExpiredToken(t *testing.T) {
+	os.Setenv("PRIV_KEY", "value")
+	defer os.Unsetenv("PRIV_KEY")
+	handler := AuthMiddleware(nil, nil, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expired tokens shouldn't get through")
+	}))
+
+	expired, _ := auth.GenerateToken("joe@example.com", func() time.Time { return time.Now().Add(-auth.TokenTTL - time.Minute) })
+	req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	req.Header.Set("Authorization", expired)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	utils.AssertEqual(t, http.StatusUnauthorized, rec.Code)
+	var resp struct {
+		Errors []struct {
+			Extensions map[string]string `json:"extensions"`
+		} `json:"errors"`
+	}
+	utils.AssertEqual(t, nil, json.Unmarshal(rec.Body.Bytes(), &resp))
+	utils.AssertEqual(t, TokenExpiredCode, resp.Errors[0].Extensions["code"])
+
+	// Tokens that were never valid aren't worth refreshing
+	req = httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	req.Header.Set("Authorization", "garbage")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	utils.AssertEqual(t, http.StatusForbidden, rec.Code)
+}
+
+func TestAuthMiddlewareResetPasswordToken(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	os.Setenv("PRIV_KEY", "value")
+	defer os.Unsetenv("PRIV_KEY")
+	handler := AuthMiddleware(nil, nil, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("stale reset links shouldn't get through")
+	}))
+
+	stale, _ := auth.GenerateToken("reset@example.com", time.Now)
+	latest, _ := auth.GenerateToken("reset@example.com", func() time.Time { return time.Now().Add(time.Second) })
+	utils.AssertEqual(t, nil, database.GetRedisDB().SetResetPasswordToken("reset@example.com", "resetpassword:"+latest))
+	defer database.GetRedisDB().DeleteResetPasswordToken("reset@example.com")
+
+	// Links sent before the latest one, and used ones, are refused
+	for _, token := range []string{stale, latest} {
+		if token == latest {
+			_, err := database.GetRedisDB().ConsumeResetPasswordToken("reset@example.com", "resetpassword:"+latest)
+			utils.AssertEqual(t, nil, err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		req.Header.Set("Authorization", "resetpassword:"+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		utils.AssertEqual(t, http.StatusForbidden, rec.Code)
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	os.Setenv("BPOW_ADMIN_EMAILS", "admin@example.com")
+	defer os.Unsetenv("BPOW_ADMIN_EMAILS")
+	withUser := func(user *models.User) context.Context {
+		return context.WithValue(context.Background(), userCtxKey, &UserContextValue{User: user, AuthType: "jwt"})
+	}
+
+	utils.AssertEqual(t, false, HasPermission(context.Background(), models.PermissionBanUsers))
+	utils.AssertEqual(t, false, HasPermission(withUser(&models.User{Email: "joe@example.com", EmailVerified: true}), models.PermissionBanUsers))
+
+	moderator := withUser(&models.User{Email: "mod@example.com", EmailVerified: true, Roles: []models.UserRole{{Role: models.RoleModerator}}})
+	utils.AssertEqual(t, true, HasPermission(moderator, models.PermissionBanUsers))
+	utils.AssertEqual(t, false, HasPermission(moderator, models.PermissionManageRoles))
+	utils.AssertEqual(t, true, AuthorizedAdmin(moderator) == nil)
+
+	// Admins listed in BPOW_ADMIN_EMAILS and admins by role can do everything
+	utils.AssertEqual(t, true, HasPermission(withUser(&models.User{Email: "admin@example.com", EmailVerified: true}), models.PermissionManageRoles))
+	roleAdmin := withUser(&models.User{Email: "other@example.com", EmailVerified: true, Roles: []models.UserRole{{Role: models.RoleAdmin}}})
+	utils.AssertEqual(t, true, HasPermission(roleAdmin, models.PermissionManageRoles))
+	utils.AssertEqual(t, false, AuthorizedAdmin(roleAdmin) == nil)
+
+	// Even listed admins lose their permissions when banned
+	banned := withUser(&models.User{Email: "admin@example.com", EmailVerified: true, Roles: []models.UserRole{{Role: models.RoleBanned}}})
+	utils.AssertEqual(t, false, HasPermission(banned, models.PermissionBanUsers))
+}
+
+func TestRequireTwoFactor(t *testing.T) {
+	withSession := func(user *models.User, twoFactor bool) context.Context {
+		return context.WithValue(context.Background(), userCtxKey, &UserContextValue{User: user, AuthType: "jwt", TwoFactor: twoFactor})
+	}
+
+	utils.AssertEqual(t, nil, RequireTwoFactor(context.Background()))
+	utils.AssertEqual(t, nil, RequireTwoFactor(withSession(&models.User{Email: "joe@example.com"}, false)))
+	enabled := &models.User{Email: "joe@example.com", TwoFactorEnabled: true}
+	utils.AssertEqual(t, nil, RequireTwoFactor(withSession(enabled, true)))
+	// Sessions from before it was enabled and service tokens weren't signed in with a code
+	utils.AssertEqual(t, ErrTwoFactorRequired, RequireTwoFactor(withSession(enabled, false)))
+	utils.AssertEqual(t, ErrTwoFactorRequired, RequireTwoFactor(context.WithValue(context.Background(), userCtxKey, &UserContextValue{User: enabled, AuthType: "token"})))
+}
+
+type recordingAuditRepo struct {
+	repository.AuditLogRepo
+	entries []models.AuditLogEntry
+}
+
+func (r *recordingAuditRepo) RecordAuditEvent(entry *models.AuditLogEntry) error {
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func TestAuthMiddlewareRecordsRejectedTokens(t *testing.T) {
+	os.Setenv("PRIV_KEY", "value")
+	defer os.Unsetenv("PRIV_KEY")
+	audit := &recordingAuditRepo{}
+	handler := AuthMiddleware(nil, nil, nil, audit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("invalid tokens shouldn't get through")
+	}))
+	serve := func(token string) {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		req.Header.Set("Authorization", token)
+		req.Header.Set("X-Real-Ip", "10.0.0.1")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve("garbage")
+	// Expired tokens are refreshed, not attacks
+	expired, _ := auth.GenerateToken("joe@example.com", func() time.Time { return time.Now().Add(-auth.TokenTTL - time.Minute) })
+	serve(expired)
+
+	utils.AssertEqual(t, 1, len(audit.entries))
+	utils.AssertEqual(t, models.AuditTokenRejected, audit.entries[0].Action)
+	utils.AssertEqual(t, "JWT", audit.entries[0].Detail)
+	utils.AssertEqual(t, "10.0.0.1", audit.entries[0].ClientIP)
+	utils.AssertEqual(t, false, audit.entries[0].Success)
+}
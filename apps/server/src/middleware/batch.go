@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+)
+
+// Whether the request POSTs a batch, a JSON array of GraphQL operations instead of a single one
+// The body is left for the handlers after
+func IsGraphQLBatch(r *http.Request) bool {
+	if r.Method != http.MethodPost || r.Header.Get("Upgrade") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return false
+	}
+	body, err := peekBody(r)
+	if err != nil {
+		return false
+	}
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// The number of operations the request counts as against rate limits
+// Batches that will be refused for their size or body count as one request
+func graphQLOperations(r *http.Request) int {
+	if !IsGraphQLBatch(r) {
+		return 1
+	}
+	body, _ := peekBody(r)
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil || len(items) == 0 || len(items) > config.GRAPHQL_MAX_BATCH_SIZE {
+		return 1
+	}
+	return len(items)
+}
+
+// Reads the body and puts it back for the next reader
+func peekBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, err
+}
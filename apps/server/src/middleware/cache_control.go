@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var cachePolicyCtxKey = &contextKey{"cachePolicy"}
+
+// Zero unless everything the request asked for is public and cacheable
+type cachePolicy struct {
+	maxAge time.Duration
+}
+
+// Buffers the response so its ETag can be computed before anything is sent
+type cacheRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *cacheRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *cacheRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *cacheRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+// SetCacheMaxAge lets CDNs cache the response of the current request for maxAge, it's a no-op for requests that can't be cached
+func SetCacheMaxAge(ctx context.Context, maxAge time.Duration) {
+	if policy, ok := ctx.Value(cachePolicyCtxKey).(*cachePolicy); ok {
+		policy.maxAge = maxAge
+	}
+}
+
+// CacheMaxAge returns how long the response of the current request can be cached, zero if it can't be
+func CacheMaxAge(ctx context.Context) time.Duration {
+	if policy, ok := ctx.Value(cachePolicyCtxKey).(*cachePolicy); ok {
+		return policy.maxAge
+	}
+	return 0
+}
+
+// CacheControlMiddleware adds ETag and Cache-Control headers to anonymous GET requests marked cacheable with SetCacheMaxAge
+// The ETag is a hash of the response, so it changes exactly when the data does and clients revalidating get a 304
+func CacheControlMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Websocket upgrades are GET requests too
+			if r.Method != http.MethodGet || r.Header.Get("Authorization") != "" || r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			policy := &cachePolicy{}
+			rec := &cacheRecorder{header: w.Header(), status: http.StatusOK}
+			next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), cachePolicyCtxKey, policy)))
+
+			body := rec.body.Bytes()
+			// Errors are often temporary, they shouldn't stick around in a CDN
+			if policy.maxAge <= 0 || rec.status != http.StatusOK || bytes.Contains(body, []byte(`"errors"`)) {
+				w.WriteHeader(rec.status)
+				w.Write(body)
+				return
+			}
+			sum := sha256.Sum256(body)
+			etag := fmt.Sprintf(`"%x"`, sum[:16])
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(policy.maxAge.Seconds())))
+			// The quota of whoever filled the cache means nothing to the clients it's served to
+			w.Header().Del("X-RateLimit-Remaining")
+			w.Header().Del("X-RateLimit-Reset")
+			// Stats are per tenant
+			w.Header().Add("Vary", TenantHeader)
+			if etagMatches(r.Header.Get("If-None-Match"), etag) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.WriteHeader(rec.status)
+			w.Write(body)
+		})
+	}
+}
+
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
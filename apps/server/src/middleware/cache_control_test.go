@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestCacheControlMiddleware(t *testing.T) {
+	body := `{"data":{"status":{"status":"OPERATIONAL"}}}`
+	handler := CacheControlMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cache") == "true" {
+			SetCacheMaxAge(r.Context(), 30*time.Second)
+		}
+		w.Header().Set("X-RateLimit-Remaining", "5")
+		w.Write([]byte(body))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?cache=true", nil))
+	utils.AssertEqual(t, http.StatusOK, rec.Code)
+	utils.AssertEqual(t, body, rec.Body.String())
+	utils.AssertEqual(t, "public, max-age=30", rec.Header().Get("Cache-Control"))
+	etag := rec.Header().Get("ETag")
+	utils.AssertNotEqual(t, "", etag)
+	utils.AssertEqual(t, "", rec.Header().Get("X-RateLimit-Remaining"))
+
+	// Revalidating with the same ETag
+	req := httptest.NewRequest(http.MethodGet, "/graphql?cache=true", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	utils.AssertEqual(t, http.StatusNotModified, rec.Code)
+	utils.AssertEqual(t, "", rec.Body.String())
+
+	// Not marked cacheable
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql", nil))
+	utils.AssertEqual(t, body, rec.Body.String())
+	utils.AssertEqual(t, "", rec.Header().Get("ETag"))
+	utils.AssertEqual(t, "5", rec.Header().Get("X-RateLimit-Remaining"))
+
+	// Authenticated
+	req = httptest.NewRequest(http.MethodGet, "/graphql?cache=true", nil)
+	req.Header.Set("Authorization", "token")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	utils.AssertEqual(t, "", rec.Header().Get("Cache-Control"))
+}
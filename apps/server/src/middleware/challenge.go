@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/bananocoin/boompow/apps/server/src/challenge"
+)
+
+// Headers that carry the response to a challenge on anonymous requests
+const (
+	ChallengeHeader         = "X-BoomPow-Challenge"
+	ChallengeSolutionHeader = "X-BoomPow-Challenge-Solution"
+)
+
+var challengeCtxKey = &contextKey{"challenge"}
+
+// ChallengeMiddleware puts the challenge response sent by the client into the context, if there is one
+func ChallengeMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response := challenge.Response{
+				Challenge: strings.TrimSpace(r.Header.Get(ChallengeHeader)),
+				Solution:  strings.TrimSpace(r.Header.Get(ChallengeSolutionHeader)),
+			}
+			if response.Challenge == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := context.WithValue(r.Context(), challengeCtxKey, &response)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ChallengeResponse returns the challenge response of the request, nil if there isn't one
+func ChallengeResponse(ctx context.Context) *challenge.Response {
+	response, _ := ctx.Value(challengeCtxKey).(*challenge.Response)
+	return response
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bananocoin/boompow/libs/utils/net"
+)
+
+var clientIPCtxKey = &contextKey{"clientIP"}
+
+// ClientIPMiddleware puts the client's IP into the context, for resolvers that record it
+func ClientIPMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), clientIPCtxKey, net.GetIPAddress(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ClientIP returns the IP of the client that made the request, or an empty string if it isn't known
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPCtxKey).(string)
+	return ip
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Header that integrators use to make retried work requests safe
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Longer keys are ignored, they'd only bloat redis
+const maxIdempotencyKeyLength = 255
+
+var idempotencyCtxKey = &contextKey{"idempotency"}
+
+// IdempotencyMiddleware puts the idempotency key sent by the client into the context, if there is one
+func IdempotencyMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := strings.TrimSpace(r.Header.Get(IdempotencyKeyHeader))
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				http.Error(w, formatGraphqlError(r.Context(), "Idempotency-Key is too long"), http.StatusBadRequest)
+				return
+			}
+			ctx := context.WithValue(r.Context(), idempotencyCtxKey, key)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// IdempotencyKey returns the idempotency key of the request, or an empty string if there isn't one
+func IdempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyCtxKey).(string)
+	return key
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Checks the Origin of CORS requests and websocket upgrades against the allowed origins
+// An origin is a scheme and host like https://banano.cc, *.banano.cc matches any subdomain of banano.cc but not banano.cc itself, and * matches everything
+type OriginValidator struct {
+	allowAll  bool
+	origins   map[string]bool
+	wildcards []wildcardOrigin
+}
+
+// Matches hosts ending in suffix, which starts with a dot
+type wildcardOrigin struct {
+	scheme string
+	suffix string
+}
+
+func NewOriginValidator(patterns []string) (*OriginValidator, error) {
+	v := &OriginValidator{origins: make(map[string]bool)}
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			v.allowAll = true
+			continue
+		}
+		u, err := url.Parse(strings.ToLower(pattern))
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return nil, fmt.Errorf("%q is not an origin like https://banano.cc or https://*.banano.cc", pattern)
+		}
+		if strings.HasPrefix(u.Host, "*.") {
+			// The suffix keeps its dot so https://*.banano.cc doesn't match https://evilbanano.cc
+			v.wildcards = append(v.wildcards, wildcardOrigin{scheme: u.Scheme, suffix: u.Host[1:]})
+		} else if strings.Contains(u.Host, "*") {
+			return nil, fmt.Errorf("%q can only have a wildcard as its first label", pattern)
+		} else {
+			v.origins[u.Scheme+"://"+u.Host] = true
+		}
+	}
+	return v, nil
+}
+
+// For cors.Options.AllowOriginFunc, the server's own origin is always allowed
+func (v *OriginValidator) AllowOrigin(r *http.Request, origin string) bool {
+	if v.allowAll {
+		return true
+	}
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	if v.origins[u.Scheme+"://"+u.Host] {
+		return true
+	}
+	for _, wildcard := range v.wildcards {
+		if u.Scheme == wildcard.scheme && strings.HasSuffix(u.Host, wildcard.suffix) && len(u.Host) > len(wildcard.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// For websocket.Upgrader, workers and other clients outside of a browser don't send an Origin
+func (v *OriginValidator) CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	return v.AllowOrigin(r, origin)
+}
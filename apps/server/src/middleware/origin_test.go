@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestOriginValidator(t *testing.T) {
+	v, err := NewOriginValidator([]string{"https://banano.cc", "https://*.banano.cc", "http://localhost:3000"})
+	utils.AssertEqual(t, nil, err)
+	r := httptest.NewRequest(http.MethodGet, "https://api.example.com/graphql", nil)
+
+	utils.AssertEqual(t, true, v.AllowOrigin(r, "https://banano.cc"))
+	utils.AssertEqual(t, true, v.AllowOrigin(r, "https://BoomPoW.banano.cc"))
+	utils.AssertEqual(t, true, v.AllowOrigin(r, "https://a.b.banano.cc"))
+	utils.AssertEqual(t, true, v.AllowOrigin(r, "http://localhost:3000"))
+	// Its own origin
+	utils.AssertEqual(t, true, v.AllowOrigin(r, "https://api.example.com"))
+	utils.AssertEqual(t, false, v.AllowOrigin(r, "https://evilbanano.cc"))
+	utils.AssertEqual(t, false, v.AllowOrigin(r, "https://banano.cc.evil.com"))
+	utils.AssertEqual(t, false, v.AllowOrigin(r, "http://boompow.banano.cc"))
+	utils.AssertEqual(t, false, v.AllowOrigin(r, "https://boompow.banano.cc:8443"))
+	utils.AssertEqual(t, false, v.AllowOrigin(r, "http://localhost:8080"))
+	utils.AssertEqual(t, false, v.AllowOrigin(r, "null"))
+
+	// Workers don't send an Origin
+	utils.AssertEqual(t, true, v.CheckOrigin(r))
+	r.Header.Set("Origin", "https://evil.com")
+	utils.AssertEqual(t, false, v.CheckOrigin(r))
+	r.Header.Set("Origin", "https://boompow.banano.cc")
+	utils.AssertEqual(t, true, v.CheckOrigin(r))
+
+	v, err = NewOriginValidator([]string{"*"})
+	utils.AssertEqual(t, nil, err)
+	r.Header.Set("Origin", "https://evil.com")
+	utils.AssertEqual(t, true, v.CheckOrigin(r))
+
+	// Nothing cross-origin
+	v, err = NewOriginValidator(nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, v.CheckOrigin(r))
+
+	for _, invalid := range []string{"banano.cc", "https://banano.cc/path", "https://boompow.*.cc", "https://*banano.cc"} {
+		_, err = NewOriginValidator([]string{invalid})
+		utils.AssertNotEqual(t, nil, err)
+	}
+}
@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+)
+
+// Smooths bursts instead of rejecting them, over-quota requests wait in a bounded queue until there is capacity
+// Capacity refills continuously at limit/window, so a queued request is admitted as soon as its slot is free
+type QueuedRateLimiter struct {
+	limit    int
+	window   time.Duration
+	maxQueue int
+	maxWait  time.Duration
+	keyFunc  func(r *http.Request) (string, error)
+	// Optional, over quota requests aren't queued while it returns true
+	maintenance func(now time.Time) bool
+	// Optional, clients it returns ok for are counted under their own key with their own limit
+	override func(r *http.Request) (key string, limit int, ok bool)
+
+	mu        sync.Mutex
+	buckets   map[string]*rateBucket
+	lastSweep time.Time
+}
+
+var queueWaitCtxKey = &contextKey{"queueWait"}
+
+// RateLimitQueueWait returns how long the request was held back by the rate limiter
+func RateLimitQueueWait(ctx context.Context) time.Duration {
+	wait, _ := ctx.Value(queueWaitCtxKey).(time.Duration)
+	return wait
+}
+
+var rateLimitStatusCtxKey = &contextKey{"rateLimitStatus"}
+
+// The quota the client has left after the current request, so clients can throttle themselves before hitting the limit
+type RateLimitStatus struct {
+	Limit     int
+	Remaining int
+	// When the client has its full limit again
+	Reset time.Time
+	// Headers of GET responses, which are written after the query ran, nil otherwise
+	header http.Header
+}
+
+func withRateLimitStatus(r *http.Request, w http.ResponseWriter, status *RateLimitStatus) *http.Request {
+	if r.Method == http.MethodGet {
+		status.header = w.Header()
+	}
+	return r.WithContext(context.WithValue(r.Context(), rateLimitStatusCtxKey, status))
+}
+
+// GetRateLimitStatus returns the quota left for the client, nil for requests that didn't go through a rate limiter
+func GetRateLimitStatus(ctx context.Context) *RateLimitStatus {
+	status, _ := ctx.Value(rateLimitStatusCtxKey).(*RateLimitStatus)
+	return status
+}
+
+var batchCostCtxKey = &contextKey{"batchCost"}
+
+// WithBatchCost adds up the costs reported for the operations of a batch, total returns the sum so far
+func WithBatchCost(ctx context.Context) (withCost context.Context, total func() int) {
+	sum := new(int)
+	return context.WithValue(ctx, batchCostCtxKey, sum), func() int { return *sum }
+}
+
+// ReportQueryCost adds the computed cost of the query to the headers of GET responses, and to the total of its batch
+func ReportQueryCost(ctx context.Context, cost int) {
+	if sum, ok := ctx.Value(batchCostCtxKey).(*int); ok {
+		*sum += cost
+	}
+	if status := GetRateLimitStatus(ctx); status != nil && status.header != nil {
+		status.header.Set("X-Query-Cost", strconv.Itoa(cost))
+	}
+}
+
+type rateBucket struct {
+	// Goes negative when requests are queued, -tokens is the length of the queue
+	tokens float64
+	last   time.Time
+	limit  int
+}
+
+func NewQueuedRateLimiter(limit int, window time.Duration, maxQueue int, maxWait time.Duration, keyFunc func(r *http.Request) (string, error)) *QueuedRateLimiter {
+	return &QueuedRateLimiter{
+		limit:    limit,
+		window:   window,
+		maxQueue: maxQueue,
+		maxWait:  maxWait,
+		keyFunc:  keyFunc,
+		buckets:  make(map[string]*rateBucket),
+	}
+}
+
+// During maintenance there's less capacity to serve queued requests, so they're rejected right away and the queue drains
+func (l *QueuedRateLimiter) WithMaintenance(active func(now time.Time) bool) *QueuedRateLimiter {
+	l.maintenance = active
+	return l
+}
+
+// Users with a rate limit of their own get it instead of the limit, 0 doesn't limit them
+func (l *QueuedRateLimiter) WithOverrides(override func(r *http.Request) (key string, limit int, ok bool)) *QueuedRateLimiter {
+	l.override = override
+	return l
+}
+
+// For WithOverrides, users and services with a per minute rate limit of their own are counted per user wherever they connect from
+func UserRateLimitOverride(r *http.Request) (key string, limit int, ok bool) {
+	contextValue := forContext(r.Context())
+	if contextValue == nil || contextValue.User == nil || contextValue.User.RateLimitPerMinute == nil || contextValue.AuthType == "resetpassword" {
+		return "", 0, false
+	}
+	return "user:" + contextValue.User.ID.String(), *contextValue.User.RateLimitPerMinute, true
+}
+
+func (l *QueuedRateLimiter) rate(limit int) float64 {
+	return float64(limit) / l.window.Seconds()
+}
+
+func (l *QueuedRateLimiter) refill(b *rateBucket, now time.Time) {
+	b.tokens = math.Min(float64(b.limit), b.tokens+now.Sub(b.last).Seconds()*l.rate(b.limit))
+	b.last = now
+}
+
+// Drop buckets that are back at full capacity, they hold no state worth keeping
+func (l *QueuedRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		l.refill(b, now)
+		if b.tokens >= float64(b.limit) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Take n slots for key with limit, returns how long to wait for them and the position in the queue (0 if not queued)
+// If the queue is full or the wait would be too long the slots are not taken and ok is false, wait is then the estimated time until retrying makes sense
+func (l *QueuedRateLimiter) reserve(key string, limit int, n int, now time.Time) (wait time.Duration, position int, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &rateBucket{tokens: float64(limit), last: now, limit: limit}
+		l.buckets[key] = b
+	}
+	l.refill(b, now)
+	// The user's limit changed
+	b.limit = limit
+
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0, 0, true
+	}
+	deficit := -b.tokens
+	position = int(math.Ceil(deficit))
+	wait = time.Duration(deficit / l.rate(limit) * float64(time.Second))
+	maxQueue := l.maxQueue
+	if l.maintenance != nil && l.maintenance(now) {
+		maxQueue = 0
+	}
+	if position > maxQueue || wait > l.maxWait {
+		b.tokens += float64(n)
+		return time.Duration((float64(n) - b.tokens) / l.rate(limit) * float64(time.Second)), position, false
+	}
+	return wait, position, true
+}
+
+// The quota left for key, queued requests leave nothing
+func (l *QueuedRateLimiter) status(key string, limit int, now time.Time) *RateLimitStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	status := &RateLimitStatus{Limit: limit, Reset: now}
+	if b, exists := l.buckets[key]; exists {
+		l.refill(b, now)
+		status.Remaining = int(math.Max(0, math.Floor(b.tokens)))
+		status.Reset = now.Add(time.Duration((float64(b.limit) - b.tokens) / l.rate(b.limit) * float64(time.Second)))
+	}
+	return status
+}
+
+// Give back n slots that were reserved but not used
+func (l *QueuedRateLimiter) cancel(key string, n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b, exists := l.buckets[key]; exists {
+		b.tokens = math.Min(float64(b.limit), b.tokens+float64(n))
+	}
+}
+
+func (l *QueuedRateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, err := l.keyFunc(r)
+		if err != nil {
+			logging.Errorf(logging.Auth, "Error getting rate limit key %v", err)
+			http.Error(w, http.StatusText(http.StatusPreconditionRequired), http.StatusPreconditionRequired)
+			return
+		}
+
+		limit := l.limit
+		if l.override != nil {
+			if overrideKey, overrideLimit, ok := l.override(r); ok {
+				if overrideLimit <= 0 {
+					next.ServeHTTP(w, r)
+					return
+				}
+				key, limit = overrideKey, overrideLimit
+			}
+		}
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+		now := time.Now()
+		// Each operation of a batch takes a slot
+		operations := graphQLOperations(r)
+		wait, position, ok := l.reserve(key, limit, operations, now)
+		status := l.status(key, limit, now)
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(status.Reset.Unix(), 10))
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			w.Header().Set("X-RateLimit-Queue-Position", strconv.Itoa(position))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+
+		if position > 0 {
+			w.Header().Set("X-RateLimit-Queue-Position", strconv.Itoa(position))
+			w.Header().Set("X-RateLimit-Queue-Wait-Ms", strconv.FormatInt(wait.Milliseconds(), 10))
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				// Client gave up, free the slot for the next one in line
+				timer.Stop()
+				l.cancel(key, operations)
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), queueWaitCtxKey, wait))
+		}
+
+		next.ServeHTTP(w, withRateLimitStatus(r, w, status))
+	})
+}
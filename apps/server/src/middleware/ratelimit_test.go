@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestQueuedRateLimiter(t *testing.T) {
+	limiter := NewQueuedRateLimiter(2, time.Minute, 2, time.Minute, func(r *http.Request) (string, error) {
+		return "key", nil
+	})
+	now := time.Now()
+
+	// Within quota
+	wait, position, ok := limiter.reserve("key", limiter.limit, 1, now)
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, 0, position)
+	utils.AssertEqual(t, time.Duration(0), wait)
+	_, _, ok = limiter.reserve("key", limiter.limit, 1, now)
+	utils.AssertEqual(t, true, ok)
+
+	// Over quota, queued behind each other
+	wait, position, ok = limiter.reserve("key", limiter.limit, 1, now)
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, 1, position)
+	utils.AssertEqual(t, 30*time.Second, wait)
+	wait, position, ok = limiter.reserve("key", limiter.limit, 1, now)
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, 2, position)
+	utils.AssertEqual(t, time.Minute, wait)
+
+	// Queue is full
+	wait, position, ok = limiter.reserve("key", limiter.limit, 1, now)
+	utils.AssertEqual(t, false, ok)
+	utils.AssertEqual(t, 3, position)
+	utils.AssertEqual(t, 90*time.Second, wait)
+
+	// Other clients aren't affected
+	_, position, ok = limiter.reserve("other", limiter.limit, 1, now)
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, 0, position)
+
+	// A cancelled request frees its slot
+	limiter.cancel("key", 1)
+	_, position, ok = limiter.reserve("key", limiter.limit, 1, now)
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, 2, position)
+
+	// Capacity refills over time
+	_, position, ok = limiter.reserve("key", limiter.limit, 1, now.Add(time.Minute))
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, 1, position)
+}
+
+func TestQueuedRateLimiterMaintenance(t *testing.T) {
+	now := time.Now()
+	limiter := NewQueuedRateLimiter(1, time.Minute, 2, time.Minute, func(r *http.Request) (string, error) {
+		return "key", nil
+	}).WithMaintenance(func(at time.Time) bool {
+		return at.Before(now.Add(time.Minute))
+	})
+
+	_, _, ok := limiter.reserve("key", limiter.limit, 1, now)
+	utils.AssertEqual(t, true, ok)
+	// Not queued during maintenance
+	_, _, ok = limiter.reserve("key", limiter.limit, 1, now)
+	utils.AssertEqual(t, false, ok)
+	// Queued again afterwards
+	_, _, ok = limiter.reserve("key", limiter.limit, 1, now.Add(time.Minute))
+	utils.AssertEqual(t, true, ok)
+	_, position, ok := limiter.reserve("key", limiter.limit, 1, now.Add(time.Minute))
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, 1, position)
+}
+
+func TestQueuedRateLimiterStatus(t *testing.T) {
+	limiter := NewQueuedRateLimiter(2, time.Minute, 2, time.Minute, func(r *http.Request) (string, error) {
+		return "key", nil
+	})
+	var status *RateLimitStatus
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status = GetRateLimitStatus(r.Context())
+		ReportQueryCost(r.Context(), 7)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql", nil))
+	utils.AssertEqual(t, 2, status.Limit)
+	utils.AssertEqual(t, 1, status.Remaining)
+	utils.AssertEqual(t, "1", rec.Header().Get("X-RateLimit-Remaining"))
+	utils.AssertEqual(t, "7", rec.Header().Get("X-Query-Cost"))
+	// A used slot takes half the window to refill
+	utils.AssertEqual(t, true, status.Reset.Sub(time.Now()) > 29*time.Second)
+
+	// Cost only goes in the headers of GET requests
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", nil))
+	utils.AssertEqual(t, 0, status.Remaining)
+	utils.AssertEqual(t, "", rec.Header().Get("X-Query-Cost"))
+}
+
+func TestQueuedRateLimiterOverrides(t *testing.T) {
+	limiter := NewQueuedRateLimiter(1, time.Minute, 0, 0, func(r *http.Request) (string, error) {
+		return "ip", nil
+	}).WithOverrides(func(r *http.Request) (string, int, bool) {
+		switch r.Header.Get("Authorization") {
+		case "trusted":
+			return "user:trusted", 3, true
+		case "unlimited":
+			return "user:unlimited", 0, true
+		}
+		return "", 0, false
+	})
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	request := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		req.Header.Set("Authorization", header)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	utils.AssertEqual(t, http.StatusOK, request("").Code)
+	utils.AssertEqual(t, http.StatusTooManyRequests, request("").Code)
+	// Overrides get their own bucket with their own limit
+	for i := 0; i < 3; i++ {
+		rec := request("trusted")
+		utils.AssertEqual(t, http.StatusOK, rec.Code)
+		utils.AssertEqual(t, "3", rec.Header().Get("X-RateLimit-Limit"))
+	}
+	utils.AssertEqual(t, http.StatusTooManyRequests, request("trusted").Code)
+	for i := 0; i < 5; i++ {
+		utils.AssertEqual(t, http.StatusOK, request("unlimited").Code)
+	}
+}
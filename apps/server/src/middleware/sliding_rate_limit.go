@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	"github.com/bananocoin/boompow/libs/utils/net"
+	"github.com/google/uuid"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// Requests over their rate limit fail with this code
+const RateLimitedCode = "RATE_LIMITED"
+
+// Kinds of clients, each has its own limit
+type RateLimitClass string
+
+const (
+	RateLimitAnonymous RateLimitClass = "anonymous"
+	RateLimitUser      RateLimitClass = "user"
+	RateLimitService   RateLimitClass = "service"
+)
+
+// Who a request is counted against
+type RateLimitIdentity struct {
+	Class RateLimitClass
+	// Window the request is counted in
+	Key string
+	// Email of JWT users, the token of services
+	Subject string
+}
+
+// Identifies the client from the Authorization header, before AuthMiddleware
+// JWTs are checked with the signing key, tokens that don't parse are counted against the IP like anonymous requests
+func rateLimitIdentity(r *http.Request) RateLimitIdentity {
+	header := r.Header.Get("Authorization")
+	if strings.HasPrefix(header, "service:") {
+		// Sources share the limit of their token
+		header, _ = models.SplitWorkSource(header)
+		return RateLimitIdentity{Class: RateLimitService, Key: "service:" + auth.HashAPIKey(header), Subject: header}
+	}
+	if header != "" && !strings.HasPrefix(header, "resetpassword:") {
+		if email, err := auth.ParseToken(header, time.Now); err == nil {
+			email = strings.ToLower(email)
+			return RateLimitIdentity{Class: RateLimitUser, Key: "user:" + email, Subject: email}
+		}
+	}
+	return RateLimitIdentity{Class: RateLimitAnonymous, Key: "ip:" + net.GetIPAddress(r)}
+}
+
+type RateLimitStore interface {
+	RecordRequest(key string, limit int, window time.Duration, now time.Time) (*database.RateLimitWindow, error)
+}
+
+// Finds the user a JWT or service token belongs to, nil if it doesn't belong to anyone
+type RateLimitUserLookup func(identity RateLimitIdentity) (*models.User, error)
+
+// Looks users up by email and services by their service token or API key
+func NewRateLimitUserLookup(userRepo *repository.UserService, apiKeyRepo repository.APIKeyRepo) RateLimitUserLookup {
+	return func(identity RateLimitIdentity) (*models.User, error) {
+		var user *models.User
+		var err error
+		switch {
+		case identity.Class == RateLimitUser:
+			user, err = userRepo.GetUser(nil, &identity.Subject)
+		case IsServiceToken(identity.Subject):
+			userID, redisErr := database.GetRedisDB().GetServiceTokenUser(identity.Subject)
+			if redisErr != nil {
+				return nil, nil
+			}
+			userUUID, parseErr := uuid.Parse(userID)
+			if parseErr != nil {
+				return nil, nil
+			}
+			user, err = userRepo.GetUser(&userUUID, nil)
+		default:
+			apiKey, keyErr := apiKeyRepo.GetAPIKey(identity.Subject)
+			if keyErr != nil || apiKey == nil {
+				return nil, keyErr
+			}
+			user, err = userRepo.GetUser(&apiKey.UserID, nil)
+		}
+		if err != nil {
+			// GetUser fails for users that don't exist
+			return nil, nil
+		}
+		return user, nil
+	}
+}
+
+// Per minute limits of each class, 0 doesn't limit the class
+type RateLimits map[RateLimitClass]int
+
+// Limits requests per client over a sliding one minute window kept in redis, so the limits hold across servers
+// Users with a rate limit of their own get it instead of the limit of their class
+type SlidingRateLimiter struct {
+	limits RateLimits
+	store  RateLimitStore
+	lookup RateLimitUserLookup
+	now    func() time.Time
+	// rateLimitUser by identity key, bounded since service tokens are whatever clients send
+	users *lru.Cache
+}
+
+// Users are cached, the limiter runs on every request
+type rateLimitUser struct {
+	user    *models.User
+	expires time.Time
+}
+
+func NewSlidingRateLimiter(limits RateLimits, store RateLimitStore, lookup RateLimitUserLookup) *SlidingRateLimiter {
+	users, _ := lru.New(config.RATE_LIMIT_USER_CACHE_SIZE)
+	return &SlidingRateLimiter{
+		limits: limits,
+		store:  store,
+		lookup: lookup,
+		now:    time.Now,
+		users:  users,
+	}
+}
+
+func (l *SlidingRateLimiter) cachedUser(identity RateLimitIdentity, now time.Time) (*models.User, bool) {
+	if cached, ok := l.users.Get(identity.Key); ok && now.Before(cached.(rateLimitUser).expires) {
+		return cached.(rateLimitUser).user, true
+	}
+	return nil, false
+}
+
+// Errors aren't cached, the next request looks the user up again
+func (l *SlidingRateLimiter) lookupUser(identity RateLimitIdentity, now time.Time) *models.User {
+	user, err := l.lookup(identity)
+	if err != nil {
+		logging.Errorf(logging.Auth, "Error looking up rate limit of %s %v", identity.Class, err)
+		return nil
+	}
+	ttl := config.RATE_LIMIT_USER_CACHE_SECONDS * time.Second
+	if user == nil {
+		ttl = config.RATE_LIMIT_UNKNOWN_USER_CACHE_SECONDS * time.Second
+	}
+	l.users.Add(identity.Key, rateLimitUser{user: user, expires: now.Add(ttl)})
+	return user
+}
+
+// Who the request is counted against and their limit
+// Service tokens that aren't cached are counted against the IP before they're looked up, so guessing tokens gets the anonymous
+// limit and can't flood the database. Tokens that don't belong to anyone stay counted there, window is then that count
+func (l *SlidingRateLimiter) resolve(r *http.Request, now time.Time) (identity RateLimitIdentity, limit int, window *database.RateLimitWindow) {
+	identity = rateLimitIdentity(r)
+	if identity.Class == RateLimitAnonymous {
+		return identity, l.limits[RateLimitAnonymous], nil
+	}
+	anonymous := RateLimitIdentity{Class: RateLimitAnonymous, Key: "ip:" + net.GetIPAddress(r)}
+	user, cached := l.cachedUser(identity, now)
+	if !cached {
+		if identity.Class == RateLimitService && l.limits[RateLimitAnonymous] > 0 {
+			var err error
+			window, err = l.store.RecordRequest(anonymous.Key, l.limits[RateLimitAnonymous], time.Minute, now)
+			if err != nil {
+				logging.Warningf(logging.Redis, "Error recording request for rate limit, not limiting %v", err)
+				window = nil
+			} else if !window.Allowed {
+				return anonymous, l.limits[RateLimitAnonymous], window
+			}
+		}
+		user = l.lookupUser(identity, now)
+	}
+	if user == nil && identity.Class == RateLimitService {
+		return anonymous, l.limits[RateLimitAnonymous], window
+	}
+	if user != nil && user.RateLimitPerMinute != nil {
+		return identity, *user.RateLimitPerMinute, nil
+	}
+	return identity, l.limits[identity.Class], nil
+}
+
+func (l *SlidingRateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := l.now()
+		identity, limit, window := l.resolve(r, now)
+		if limit <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// Each operation of a batch counts, the batch is refused once one of them doesn't fit
+		counted := 0
+		if window != nil {
+			counted = 1
+		}
+		var err error
+		for i := counted; i < graphQLOperations(r) && (window == nil || window.Allowed); i++ {
+			window, err = l.store.RecordRequest(identity.Key, limit, time.Minute, now)
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			// Redis being down shouldn't take the API down with it
+			logging.Warningf(logging.Redis, "Error recording request for rate limit, not limiting %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		status := &RateLimitStatus{
+			Limit:     limit,
+			Remaining: int(math.Max(0, float64(int64(limit)-window.Count))),
+			Reset:     window.Reset,
+		}
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(status.Reset.Unix(), 10))
+		if !window.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(window.Reset.Sub(now).Seconds()))))
+			http.Error(w, formatGraphqlErrorWithCode(r.Context(), "Rate limit exceeded", RateLimitedCode), http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, withRateLimitStatus(r, w, status))
+	})
+}
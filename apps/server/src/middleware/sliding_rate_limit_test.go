@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+// Counts requests per key without a window, the tests don't outlast a minute
+type fakeRateLimitStore struct {
+	counts map[string]int64
+}
+
+func (s *fakeRateLimitStore) RecordRequest(key string, limit int, window time.Duration, now time.Time) (*database.RateLimitWindow, error) {
+	if s.counts[key] >= int64(limit) {
+		return &database.RateLimitWindow{Allowed: false, Count: s.counts[key], Reset: now.Add(window)}, nil
+	}
+	s.counts[key]++
+	return &database.RateLimitWindow{Allowed: true, Count: s.counts[key], Reset: now.Add(window)}, nil
+}
+
+func TestSlidingRateLimiter(t *testing.T) {
+	os.Setenv("PRIV_KEY", "value")
+	defer os.Unsetenv("PRIV_KEY")
+	store := &fakeRateLimitStore{counts: map[string]int64{}}
+	override := 3
+	lookups := 0
+	users := map[string]*models.User{
+		"limited@example.com": {},
+		"trusted@example.com": {RateLimitPerMinute: &override},
+		"service:valid":       {},
+	}
+	limiter := NewSlidingRateLimiter(RateLimits{RateLimitAnonymous: 1, RateLimitUser: 2, RateLimitService: 0}, store, func(identity RateLimitIdentity) (*models.User, error) {
+		lookups++
+		return users[identity.Subject], nil
+	})
+	var status *RateLimitStatus
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status = GetRateLimitStatus(r.Context())
+	}))
+	request := func(header string, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		req.RemoteAddr = ip + ":1234"
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	token := func(email string) string {
+		token, _ := auth.GenerateToken(email, time.Now)
+		return token
+	}
+
+	utils.AssertEqual(t, http.StatusOK, request("", "10.0.0.1").Code)
+	rec := request("", "10.0.0.1")
+	utils.AssertEqual(t, http.StatusTooManyRequests, rec.Code)
+	utils.AssertEqual(t, "60", rec.Header().Get("Retry-After"))
+	var resp struct {
+		Errors []struct {
+			Extensions map[string]string `json:"extensions"`
+		} `json:"errors"`
+	}
+	utils.AssertEqual(t, nil, json.Unmarshal(rec.Body.Bytes(), &resp))
+	utils.AssertEqual(t, RateLimitedCode, resp.Errors[0].Extensions["code"])
+
+	// Users have their own window wherever they connect from
+	limited := token("limited@example.com")
+	utils.AssertEqual(t, http.StatusOK, request(limited, "10.0.0.1").Code)
+	utils.AssertEqual(t, 2, status.Limit)
+	utils.AssertEqual(t, 1, status.Remaining)
+	utils.AssertEqual(t, http.StatusOK, request(limited, "10.0.0.2").Code)
+	utils.AssertEqual(t, http.StatusTooManyRequests, request(limited, "10.0.0.2").Code)
+	// Looked up once
+	utils.AssertEqual(t, 1, lookups)
+
+	// Overrides replace the limit of the class
+	trusted := token("trusted@example.com")
+	for i := 0; i < 3; i++ {
+		utils.AssertEqual(t, http.StatusOK, request(trusted, "10.0.0.3").Code)
+	}
+	utils.AssertEqual(t, http.StatusTooManyRequests, request(trusted, "10.0.0.3").Code)
+
+	// Services aren't limited, unknown service tokens are counted against the IP
+	for i := 0; i < 3; i++ {
+		utils.AssertEqual(t, http.StatusOK, request("service:valid", "10.0.0.4").Code)
+	}
+	// Sources of a token are looked up as the token itself, not counted against the IP
+	for i := 0; i < 3; i++ {
+		utils.AssertEqual(t, http.StatusOK, request("service:valid.staging", "10.0.0.5").Code)
+	}
+	utils.AssertEqual(t, http.StatusOK, request("service:guess", "10.0.0.5").Code)
+	// Over the IP's limit before the token is looked up
+	lookups = 0
+	utils.AssertEqual(t, http.StatusTooManyRequests, request("service:guess2", "10.0.0.5").Code)
+	utils.AssertEqual(t, 0, lookups)
+	// Tokens that weren't found are cached too
+	utils.AssertEqual(t, http.StatusOK, request("service:guess", "10.0.0.6").Code)
+	utils.AssertEqual(t, http.StatusTooManyRequests, request("service:guess", "10.0.0.6").Code)
+	utils.AssertEqual(t, 0, lookups)
+
+	// Tokens that don't parse are anonymous
+	utils.AssertEqual(t, http.StatusTooManyRequests, request("garbage", "10.0.0.1").Code)
+}
+
+func TestSlidingRateLimiterBatch(t *testing.T) {
+	store := &fakeRateLimitStore{counts: map[string]int64{}}
+	limiter := NewSlidingRateLimiter(RateLimits{RateLimitAnonymous: 5}, store, nil)
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The body is still there for the transport
+		var batch []json.RawMessage
+		utils.AssertEqual(t, nil, json.NewDecoder(r.Body).Decode(&batch))
+	}))
+	request := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = "10.0.0.2:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(` [{"query": "{ status { status } }"}, {"query": "{ status { status } }"}, {"query": "{ status { status } }"}]`)
+	utils.AssertEqual(t, http.StatusOK, rec.Code)
+	utils.AssertEqual(t, "2", rec.Header().Get("X-RateLimit-Remaining"))
+	// A batch is refused when its operations don't all fit
+	rec = request(`[{"query": "{ status { status } }"}, {"query": "{ status { status } }"}, {"query": "{ status { status } }"}]`)
+	utils.AssertEqual(t, http.StatusTooManyRequests, rec.Code)
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+)
+
+// Header that unauthenticated clients use to select the pool they are talking to
+const TenantHeader = "X-BoomPow-Tenant"
+
+var tenantCtxKey = &contextKey{"tenant"}
+
+// TenantMiddleware puts the tenant requested by the client into the context, if there is one
+func TenantMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID := strings.ToLower(strings.TrimSpace(r.Header.Get(TenantHeader)))
+			if tenantID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := context.WithValue(r.Context(), tenantCtxKey, tenantID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequestTenant returns the tenant a request belongs to
+// Authenticated users always act within their own tenant, regardless of what the header says
+func RequestTenant(ctx context.Context) string {
+	if contextValue := forContext(ctx); contextValue != nil && contextValue.User != nil && contextValue.User.TenantID != "" {
+		return contextValue.User.TenantID
+	}
+	if tenantID, ok := ctx.Value(tenantCtxKey).(string); ok && tenantID != "" {
+		return tenantID
+	}
+	return config.DEFAULT_TENANT_ID
+}
+
+// Whether the tenant explicitly requested in the header differs from the authenticated user's tenant
+func tenantMismatch(ctx context.Context, userTenantID string) bool {
+	tenantID, ok := ctx.Value(tenantCtxKey).(string)
+	return ok && tenantID != "" && tenantID != userTenantID
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import "sync"
+
+// Things loaded for the authenticated user, kept for the lifetime of the request (or websocket connection)
+// so resolvers of the same operation load them at most once
+type userCache struct {
+	mu      sync.Mutex
+	entries map[string]*userCacheEntry
+}
+
+type userCacheEntry struct {
+	once  sync.Once
+	value interface{}
+	err   error
+}
+
+func (c *userCache) entry(key string) *userCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]*userCacheEntry)
+	}
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &userCacheEntry{}
+		c.entries[key] = entry
+	}
+	return entry
+}
+
+// Calls load the first time key is requested for this user, later calls get the same result
+// Concurrent resolvers wait for the first load instead of loading again
+func LoadForUser[T any](user *UserContextValue, key string, load func() (T, error)) (T, error) {
+	entry := user.cache.entry(key)
+	entry.once.Do(func() {
+		entry.value, entry.err = load()
+	})
+	value, _ := entry.value.(T)
+	return value, entry.err
+}
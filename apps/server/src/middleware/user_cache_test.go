@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestLoadForUser(t *testing.T) {
+	user := &UserContextValue{}
+	loads := 0
+	load := func() (int, error) {
+		loads++
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := LoadForUser(user, "answer", load)
+			utils.AssertEqual(t, nil, err)
+			utils.AssertEqual(t, 42, value)
+		}()
+	}
+	wg.Wait()
+	utils.AssertEqual(t, 1, loads)
+
+	// Errors are cached too, keys are separate
+	_, err := LoadForUser(user, "broken", func() (string, error) { return "", errors.New("failed") })
+	utils.AssertNotEqual(t, nil, err)
+	_, err = LoadForUser(user, "broken", func() (string, error) { return "ok", nil })
+	utils.AssertNotEqual(t, nil, err)
+
+	// Every request has its own cache
+	value, _ := LoadForUser(&UserContextValue{}, "answer", func() (int, error) { return 7, nil })
+	utils.AssertEqual(t, 7, value)
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+)
+
+var errUnknownWorkSource = errors.New("unknown work source")
+
+// Attributes the request to a source of the authenticated user, sources they never created are rejected
+func withWorkSource(ctx context.Context, name string, sourceRepo repository.WorkSourceRepo) (context.Context, error) {
+	contextValue := forContext(ctx)
+	if contextValue == nil || sourceRepo == nil {
+		return ctx, errUnknownWorkSource
+	}
+	source, err := sourceRepo.GetWorkSource(contextValue.User.ID, name)
+	if err != nil || source == nil {
+		return ctx, errUnknownWorkSource
+	}
+	contextValue.Source = source
+	return ctx, nil
+}
+
+// RequestSource returns the work source the request was attributed to, nil if the token named none
+func RequestSource(ctx context.Context) *models.WorkSource {
+	contextValue := forContext(ctx)
+	if contextValue == nil {
+		return nil
+	}
+	return contextValue.Source
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+// Only looks sources up
+type fakeSourceRepo struct {
+	repository.WorkSourceRepo
+	sources map[string]*models.WorkSource
+}
+
+func (f fakeSourceRepo) GetWorkSource(userID uuid.UUID, name string) (*models.WorkSource, error) {
+	source, ok := f.sources[name]
+	if !ok || source.UserID != userID {
+		return nil, nil
+	}
+	return source, nil
+}
+
+func TestWithWorkSource(t *testing.T) {
+	user := &models.User{Base: models.Base{ID: uuid.New()}}
+	staging := &models.WorkSource{UserID: user.ID, Name: "staging"}
+	otherUsers := &models.WorkSource{UserID: uuid.New(), Name: "faucet"}
+	repo := fakeSourceRepo{sources: map[string]*models.WorkSource{"staging": staging, "faucet": otherUsers}}
+	authenticated := func() context.Context {
+		return context.WithValue(context.Background(), userCtxKey, &UserContextValue{User: user, AuthType: "token"})
+	}
+
+	ctx, err := withWorkSource(authenticated(), "staging", repo)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, staging, RequestSource(ctx))
+	utils.AssertEqual(t, user, RequestUser(ctx))
+
+	// Sources of other requesters and sources nobody created don't authenticate
+	_, err = withWorkSource(authenticated(), "faucet", repo)
+	utils.AssertEqual(t, errUnknownWorkSource, err)
+	_, err = withWorkSource(authenticated(), "prod", repo)
+	utils.AssertEqual(t, errUnknownWorkSource, err)
+	_, err = withWorkSource(context.Background(), "staging", repo)
+	utils.AssertEqual(t, errUnknownWorkSource, err)
+
+	utils.AssertEqual(t, true, RequestSource(authenticated()) == nil)
+}
@@ -0,0 +1,28 @@
+package models
+
+import "github.com/google/uuid"
+
+type AccountEventType string
+
+const (
+	AccountEventLogin                  AccountEventType = "login"
+	AccountEventServiceTokenCreated    AccountEventType = "service_token_created"
+	AccountEventPasswordChanged        AccountEventType = "password_changed"
+	AccountEventPayoutAddressesChanged AccountEventType = "payout_addresses_changed"
+	AccountEventOfflineAlertChanged    AccountEventType = "offline_alert_changed"
+	AccountEventSettingsChanged        AccountEventType = "settings_changed"
+	AccountEventTwoFactorEnabled       AccountEventType = "two_factor_enabled"
+	AccountEventTwoFactorDisabled      AccountEventType = "two_factor_disabled"
+	AccountEventAccountRecovered       AccountEventType = "account_recovered"
+	AccountEventAPIKeyRevoked          AccountEventType = "api_key_revoked"
+	AccountEventSecurityWebhookChanged AccountEventType = "security_webhook_changed"
+)
+
+// Something notable a user did to their account, shown in their activity timeline
+type AccountEvent struct {
+	Base
+	UserID   uuid.UUID        `json:"user_id" gorm:"type:uuid;not null;index"`
+	Type     AccountEventType `json:"type" gorm:"not null"`
+	Detail   string           `json:"detail"`
+	ClientIP string           `json:"client_ip"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A key a requester generated to request work with, there can be several per requester
+type APIKey struct {
+	Base
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name   string    `json:"name" gorm:"not null"`
+	// The key itself is only shown once, when it's generated
+	KeyHash string `json:"-" gorm:"not null;uniqueIndex"`
+	Hint    string `json:"hint" gorm:"not null"`
+	// Requests per minute, null leaves the key unlimited like service tokens
+	RateLimitPerMinute *int       `json:"rate_limit_per_minute"`
+	LastUsedAt         *time.Time `json:"last_used_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "github.com/google/uuid"
+
+type AuditAction string
+
+const (
+	AuditLogin                  AuditAction = "login"
+	AuditLoginFailed            AuditAction = "login_failed"
+	AuditTokenRejected          AuditAction = "token_rejected"
+	AuditPasswordChanged        AuditAction = "password_changed"
+	AuditPayoutAddressesChanged AuditAction = "payout_addresses_changed"
+	AuditAdminAction            AuditAction = "admin_action"
+)
+
+var AuditActions = []AuditAction{AuditLogin, AuditLoginFailed, AuditTokenRejected, AuditPasswordChanged, AuditPayoutAddressesChanged, AuditAdminAction}
+
+// A security-sensitive event for admins to review, unlike account events users don't see them
+type AuditLogEntry struct {
+	Base
+	Action AuditAction `json:"action" gorm:"not null;index"`
+	// Nil when nobody could be identified, like for rejected tokens
+	ActorID    *uuid.UUID `json:"actor_id" gorm:"type:uuid;index"`
+	ActorEmail string     `json:"actor_email"`
+	// What the action was about, the mutation of admin actions or the email a login was tried with
+	Target   string `json:"target"`
+	Detail   string `json:"detail"`
+	ClientIP string `json:"client_ip"`
+	Success  bool   `json:"success" gorm:"not null"`
+}
+
+func (AuditLogEntry) TableName() string {
+	return "audit_log"
+}
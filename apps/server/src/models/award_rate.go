@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The award per unit of difficulty, effective from EffectiveAt until the next rate of the tenant
+// A rate of 0 means work is paid as a share of the prize pool instead
+type AwardRate struct {
+	Base
+	TenantID string `json:"tenant_id" gorm:"default:'default';not null;index"`
+	// In raw, so payouts don't depend on float rounding
+	RawPerUnit  string    `json:"raw_per_unit" gorm:"type:numeric;not null"`
+	EffectiveAt time.Time `json:"effective_at" gorm:"not null;index"`
+	CreatedBy   uuid.UUID `json:"created_by" gorm:"not null"`
+}
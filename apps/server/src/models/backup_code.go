@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// One-time code to recover an account with two factor authentication, generated when enrolling
+type BackupCode struct {
+	Base
+	UserID   uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	UsedAt   *time.Time `json:"used_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "github.com/google/uuid"
+
+// Benchmark results a provider chose to share, one per user, hardware and difficulty
+type BenchmarkProfile struct {
+	Base
+	UserID   uuid.UUID `json:"user_id" gorm:"not null;uniqueIndex:idx_benchmark_profile"`
+	Hardware string    `json:"hardware" gorm:"not null;uniqueIndex:idx_benchmark_profile"`
+	// GPU or CPU
+	Backend              string  `json:"backend" gorm:"not null;uniqueIndex:idx_benchmark_profile"`
+	DifficultyMultiplier int     `json:"difficulty_multiplier" gorm:"not null;uniqueIndex:idx_benchmark_profile"`
+	Runs                 int     `json:"runs" gorm:"not null"`
+	AverageSeconds       float64 `json:"average_seconds" gorm:"not null"`
+	ClientVersion        string  `json:"client_version"`
+}
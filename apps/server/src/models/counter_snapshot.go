@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Workers connected to a tenant when the redis counters were snapshotted, so their history survives redis resets
+type ConnectedWorkersSnapshot struct {
+	TakenAt  time.Time `json:"taken_at" gorm:"primaryKey"`
+	TenantID string    `json:"tenant_id" gorm:"primaryKey"`
+	Workers  int64     `json:"workers" gorm:"not null"`
+}
+
+// Copy of a volatile redis counter as of the last snapshot, restored if redis loses it
+type CounterValue struct {
+	Key string `json:"key" gorm:"primaryKey"`
+	// Empty for counters that aren't hashes
+	Field string `json:"field" gorm:"primaryKey"`
+	Value string `json:"value" gorm:"not null"`
+	// Nil for counters that don't expire
+	ExpiresAt *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Number of completed work requests per hour and difficulty, kept because work_results only holds the latest request per hash
+type DifficultyRollup struct {
+	Hour                 time.Time `json:"hour" gorm:"primaryKey"`
+	TenantID             string    `json:"tenant_id" gorm:"primaryKey"`
+	DifficultyMultiplier int       `json:"difficulty_multiplier" gorm:"primaryKey"`
+	Count                int64     `json:"count" gorm:"default:0;not null"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Accounts that were registered with aliases of the same mailbox before emails were normalized, flagged for admins to review
+type EmailCollision struct {
+	NormalizedEmail string    `json:"normalized_email" gorm:"primaryKey"`
+	FlaggedAt       time.Time `json:"flagged_at" gorm:"not null"`
+	// Null until reviewed
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	ReviewedBy *uuid.UUID `json:"reviewed_by" gorm:"type:uuid"`
+	// The colliding accounts, oldest first, loaded separately
+	Users []User `json:"-" gorm:"-"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "github.com/google/uuid"
+
+// Emails are sent in this language unless configured otherwise, and fall back to it
+const DefaultEmailLanguage = "en"
+
+// An admin edited version of an email, the highest version of a name and language is the one sent
+type EmailTemplate struct {
+	Base
+	// Name of the built-in email it replaces, e.g. confirmemail
+	Name     string `json:"name" gorm:"not null;uniqueIndex:idx_email_template_version"`
+	Language string `json:"language" gorm:"not null;uniqueIndex:idx_email_template_version"`
+	Version  int    `json:"version" gorm:"not null;uniqueIndex:idx_email_template_version"`
+	// Go templates, the body is the HTML inside the common layout
+	Subject   string    `json:"subject" gorm:"not null"`
+	Body      string    `json:"body" gorm:"not null"`
+	CreatedBy uuid.UUID `json:"created_by" gorm:"type:uuid;not null"`
+}
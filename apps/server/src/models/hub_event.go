@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HubEventType string
+
+const (
+	HubEventConnect    HubEventType = "connect"
+	HubEventDisconnect HubEventType = "disconnect"
+	HubEventAssigned   HubEventType = "assigned"
+	HubEventResult     HubEventType = "result"
+	HubEventCancel     HubEventType = "cancel"
+	HubEventTimeout    HubEventType = "timeout"
+	// A client refused a malformed work request
+	HubEventRejected HubEventType = "rejected"
+	// A result for a request that was already answered or timed out
+	HubEventLate HubEventType = "late"
+	// A client was disconnected for sending malformed frames
+	HubEventQuarantined HubEventType = "quarantined"
+	// A request timed out while its workers were still reporting progress, so it was given longer
+	HubEventExtended HubEventType = "extended"
+	// A client said which protocol version and features it speaks
+	HubEventHello HubEventType = "hello"
+	// A client that just connected was timed on its calibration requests
+	HubEventCalibrated HubEventType = "calibrated"
+)
+
+// Something significant that happened in the worker hub, used to debug the life of a work request
+type HubEvent struct {
+	// Assigned when the event is recorded, so in-memory and persisted copies can be matched
+	ID          uuid.UUID    `json:"id" gorm:"primaryKey"`
+	Type        HubEventType `json:"type" gorm:"not null"`
+	RequestID   string       `json:"request_id" gorm:"index"`
+	Hash        string       `json:"hash"`
+	ClientIP    string       `json:"client_ip"`
+	ClientEmail string       `json:"client_email"`
+	TenantID    string       `json:"tenant_id"`
+	// Set on work request events
+	DifficultyMultiplier int       `json:"difficulty_multiplier"`
+	Detail               string    `json:"detail"`
+	Timestamp            time.Time `json:"timestamp" gorm:"index;not null"`
+}
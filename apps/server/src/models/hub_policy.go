@@ -0,0 +1,143 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Id of the only policy row, there's one policy for the whole hub
+const HubPolicyID = 1
+
+// Tunables of how the hub hands out work, edited by admins and applied without a restart
+type HubPolicy struct {
+	ID int `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	// How long to wait for a result before retrying or giving up
+	TimeoutSeconds int `json:"timeout_seconds" gorm:"not null"`
+	// Timed out requests are broadcast again this many times
+	Retries int `json:"retries" gorm:"not null"`
+	// Requests a worker can be working on at once, 0 is unlimited
+	MaxInFlightPerWorker int `json:"max_in_flight_per_worker" gorm:"not null"`
+	// Share of a worker's in-flight slots on-demand and precache requests can use, relative to each other
+	OnDemandWeight int `json:"on_demand_weight" gorm:"not null"`
+	PrecacheWeight int `json:"precache_weight" gorm:"not null"`
+	// Workers that earned at least this percentage of the recent rewards are skipped, so others get a chance
+	ExclusionSharePercent int `json:"exclusion_share_percent" gorm:"not null"`
+	// With fewer connected workers than this nobody is skipped
+	ExclusionMinClients int `json:"exclusion_min_clients" gorm:"not null"`
+	// Workers that had no work for this long are given precache tasks from the frontier pool, 0 disables it
+	IdlePrecacheSeconds int `json:"idle_precache_seconds" gorm:"default:0;not null"`
+	// Share of the difficulty those precache solves are credited with towards payouts
+	IdlePrecacheCreditPercent int `json:"idle_precache_credit_percent" gorm:"default:50;not null"`
+	// Results arriving this long after a request was answered or timed out are still credited, later ones aren't
+	LateResultGraceSeconds int `json:"late_result_grace_seconds" gorm:"default:5;not null"`
+	// Requests whose workers are still reporting progress wait up to this much longer before they time out, 0 disables it
+	ProgressExtensionSeconds int `json:"progress_extension_seconds" gorm:"default:60;not null"`
+	// Requests of at least this difficulty multiplier first go only to workers that solved them within SolveSLASeconds so far, 0 disables it
+	TargetedDifficulty int       `json:"targeted_difficulty" gorm:"default:0;not null"`
+	SolveSLASeconds    int       `json:"solve_sla_seconds" gorm:"default:0;not null"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	UpdatedBy          uuid.UUID `json:"updated_by" gorm:"type:uuid"`
+}
+
+// How the hub behaved before the policy was configurable
+func DefaultHubPolicy() HubPolicy {
+	return HubPolicy{
+		ID:                    HubPolicyID,
+		TimeoutSeconds:        30,
+		Retries:               0,
+		MaxInFlightPerWorker:  0,
+		OnDemandWeight:        1,
+		PrecacheWeight:        1,
+		ExclusionSharePercent: 15,
+		ExclusionMinClients:   5,
+		// Idle precaching is opt-in
+		IdlePrecacheSeconds:       0,
+		IdlePrecacheCreditPercent: 50,
+		LateResultGraceSeconds:    5,
+		ProgressExtensionSeconds:  60,
+	}
+}
+
+func (p *HubPolicy) Validate() error {
+	if p.TimeoutSeconds < 1 || p.TimeoutSeconds > 300 {
+		return errors.New("timeoutSeconds must be between 1 and 300")
+	}
+	if p.Retries < 0 || p.Retries > 5 {
+		return errors.New("retries must be between 0 and 5")
+	}
+	if p.MaxInFlightPerWorker < 0 {
+		return errors.New("maxInFlightPerWorker can't be negative")
+	}
+	if p.OnDemandWeight < 1 || p.PrecacheWeight < 1 {
+		return errors.New("onDemandWeight and precacheWeight must be at least 1")
+	}
+	if p.ExclusionSharePercent < 1 || p.ExclusionSharePercent > 100 {
+		return errors.New("exclusionSharePercent must be between 1 and 100")
+	}
+	if p.ExclusionMinClients < 0 {
+		return errors.New("exclusionMinClients can't be negative")
+	}
+	// A worker's last on-demand request has to have timed out before it counts as idle
+	if p.IdlePrecacheSeconds != 0 && (p.IdlePrecacheSeconds < p.TimeoutSeconds || p.IdlePrecacheSeconds > 86400) {
+		return errors.New("idlePrecacheSeconds must be 0 or between timeoutSeconds and 86400")
+	}
+	if p.IdlePrecacheCreditPercent < 1 || p.IdlePrecacheCreditPercent > 100 {
+		return errors.New("idlePrecacheCreditPercent must be between 1 and 100")
+	}
+	if p.LateResultGraceSeconds < 0 || p.LateResultGraceSeconds > 60 {
+		return errors.New("lateResultGraceSeconds must be between 0 and 60")
+	}
+	if p.ProgressExtensionSeconds < 0 || p.ProgressExtensionSeconds > 600 {
+		return errors.New("progressExtensionSeconds must be between 0 and 600")
+	}
+	if p.TargetedDifficulty < 0 {
+		return errors.New("targetedDifficulty can't be negative")
+	}
+	// Workers are expected to answer before the request times out
+	if p.TargetedDifficulty > 0 && (p.SolveSLASeconds < 1 || p.SolveSLASeconds > p.TimeoutSeconds) {
+		return errors.New("solveSlaSeconds must be between 1 and timeoutSeconds with targeted dispatch")
+	}
+	return nil
+}
+
+func (p *HubPolicy) Timeout() time.Duration {
+	return time.Duration(p.TimeoutSeconds) * time.Second
+}
+
+// How long a worker has to be without work to get idle precache tasks, 0 if they're disabled
+func (p *HubPolicy) IdlePrecacheAfter() time.Duration {
+	return time.Duration(p.IdlePrecacheSeconds) * time.Second
+}
+
+// The longest a request is kept waiting past its timeout for workers that are still making progress
+func (p *HubPolicy) ProgressExtension() time.Duration {
+	return time.Duration(p.ProgressExtensionSeconds) * time.Second
+}
+
+// The SLA work requests of the difficulty are targeted with, false if they go to every worker
+func (p *HubPolicy) TargetedSLA(difficultyMultiplier int) (time.Duration, bool) {
+	if p.TargetedDifficulty == 0 || difficultyMultiplier < p.TargetedDifficulty {
+		return 0, false
+	}
+	return time.Duration(p.SolveSLASeconds) * time.Second, true
+}
+
+// How long after a request closed its results are still credited
+func (p *HubPolicy) LateResultGrace() time.Duration {
+	return time.Duration(p.LateResultGraceSeconds) * time.Second
+}
+
+// In-flight slots of a worker a request can use, 0 is unlimited
+// On-demand requests can use all of them, precache requests only their weighted share but at least one
+func (p *HubPolicy) InFlightSlots(precache bool) int {
+	if p.MaxInFlightPerWorker == 0 || !precache {
+		return p.MaxInFlightPerWorker
+	}
+	slots := p.MaxInFlightPerWorker * p.PrecacheWeight / (p.OnDemandWeight + p.PrecacheWeight)
+	if slots < 1 {
+		return 1
+	}
+	return slots
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestHubPolicyValidate(t *testing.T) {
+	policy := DefaultHubPolicy()
+	utils.AssertEqual(t, nil, policy.Validate())
+
+	policy.TimeoutSeconds = 0
+	utils.AssertNotEqual(t, nil, policy.Validate())
+	policy = DefaultHubPolicy()
+	policy.Retries = 6
+	utils.AssertNotEqual(t, nil, policy.Validate())
+	policy = DefaultHubPolicy()
+	policy.PrecacheWeight = 0
+	utils.AssertNotEqual(t, nil, policy.Validate())
+	policy = DefaultHubPolicy()
+	policy.ExclusionSharePercent = 101
+	utils.AssertNotEqual(t, nil, policy.Validate())
+
+	// Idle means the last on-demand request timed out
+	policy = DefaultHubPolicy()
+	policy.IdlePrecacheSeconds = 10
+	utils.AssertNotEqual(t, nil, policy.Validate())
+	policy.IdlePrecacheSeconds = 60
+	utils.AssertEqual(t, nil, policy.Validate())
+	policy.IdlePrecacheCreditPercent = 0
+	utils.AssertNotEqual(t, nil, policy.Validate())
+
+	policy = DefaultHubPolicy()
+	policy.LateResultGraceSeconds = 0
+	utils.AssertEqual(t, nil, policy.Validate())
+	policy.LateResultGraceSeconds = 61
+	utils.AssertNotEqual(t, nil, policy.Validate())
+
+	policy = DefaultHubPolicy()
+	policy.ProgressExtensionSeconds = 0
+	utils.AssertEqual(t, nil, policy.Validate())
+	policy.ProgressExtensionSeconds = 601
+	utils.AssertNotEqual(t, nil, policy.Validate())
+
+	// The SLA has to be within the timeout
+	policy = DefaultHubPolicy()
+	policy.TargetedDifficulty = 8
+	utils.AssertNotEqual(t, nil, policy.Validate())
+	policy.SolveSLASeconds = 31
+	utils.AssertNotEqual(t, nil, policy.Validate())
+	policy.SolveSLASeconds = 10
+	utils.AssertEqual(t, nil, policy.Validate())
+}
+
+func TestHubPolicyTargetedSLA(t *testing.T) {
+	policy := DefaultHubPolicy()
+	_, ok := policy.TargetedSLA(64)
+	utils.AssertEqual(t, false, ok)
+
+	policy.TargetedDifficulty = 8
+	policy.SolveSLASeconds = 10
+	_, ok = policy.TargetedSLA(4)
+	utils.AssertEqual(t, false, ok)
+	sla, ok := policy.TargetedSLA(8)
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, 10*time.Second, sla)
+}
+
+func TestHubPolicyInFlightSlots(t *testing.T) {
+	policy := DefaultHubPolicy()
+	utils.AssertEqual(t, 0, policy.InFlightSlots(true))
+
+	policy.MaxInFlightPerWorker = 4
+	policy.OnDemandWeight = 3
+	policy.PrecacheWeight = 1
+	utils.AssertEqual(t, 4, policy.InFlightSlots(false))
+	utils.AssertEqual(t, 1, policy.InFlightSlots(true))
+
+	// Precache always gets at least one slot
+	policy.OnDemandWeight = 10
+	utils.AssertEqual(t, 1, policy.InFlightSlots(true))
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IncidentSeverity string
+
+const (
+	IncidentDegraded IncidentSeverity = "DEGRADED"
+	IncidentOutage   IncidentSeverity = "OUTAGE"
+)
+
+// Something affecting the whole pool, declared by an admin or opened by the anomaly detector
+type Incident struct {
+	Base
+	Title       string           `json:"title" gorm:"not null"`
+	Description string           `json:"description"`
+	Severity    IncidentSeverity `json:"severity" gorm:"not null"`
+	// The detector check that opened it, empty for incidents declared by admins
+	DetectorKey string     `json:"detector_key" gorm:"index"`
+	DeclaredBy  *uuid.UUID `json:"declared_by"`
+	ResolvedAt  *time.Time `json:"resolved_at" gorm:"index"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LeaderboardWindow string
+
+const (
+	LeaderboardDay   LeaderboardWindow = "DAY"
+	LeaderboardWeek  LeaderboardWindow = "WEEK"
+	LeaderboardMonth LeaderboardWindow = "MONTH"
+	LeaderboardAll   LeaderboardWindow = "ALL"
+)
+
+var LeaderboardWindows = []LeaderboardWindow{LeaderboardDay, LeaderboardWeek, LeaderboardMonth, LeaderboardAll}
+
+// Start of the window ending at now, nil for all time
+func (w LeaderboardWindow) Since(now time.Time) *time.Time {
+	var since time.Time
+	switch w {
+	case LeaderboardDay:
+		since = now.Add(-24 * time.Hour)
+	case LeaderboardWeek:
+		since = now.Add(-7 * 24 * time.Hour)
+	case LeaderboardMonth:
+		since = now.Add(-30 * 24 * time.Hour)
+	default:
+		return nil
+	}
+	return &since
+}
+
+// A provider's place on the leaderboard of a window, the job refreshing the leaderboard replaces all of a window's entries at once
+type LeaderboardEntry struct {
+	TenantID   string            `json:"tenant_id" gorm:"primaryKey"`
+	Window     LeaderboardWindow `json:"window" gorm:"column:time_window;primaryKey"`
+	Rank       int               `json:"rank" gorm:"primaryKey"`
+	ProviderID uuid.UUID         `json:"provider_id" gorm:"type:uuid;not null"`
+	BanAddress string            `json:"ban_address" gorm:"not null"`
+	// Results the provider solved in the window
+	WorkUnits     int64     `json:"work_units" gorm:"not null"`
+	DifficultySum int64     `json:"difficulty_sum" gorm:"not null"`
+	RefreshedAt   time.Time `json:"refreshed_at" gorm:"not null"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Planned downtime scheduled by an admin
+type MaintenanceWindow struct {
+	Base
+	Description string    `json:"description"`
+	StartsAt    time.Time `json:"starts_at" gorm:"not null;index"`
+	EndsAt      time.Time `json:"ends_at" gorm:"not null;index"`
+	CreatedBy   uuid.UUID `json:"created_by" gorm:"type:uuid;not null"`
+}
+
+func (w *MaintenanceWindow) ActiveAt(t time.Time) bool {
+	return !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+}
@@ -0,0 +1,21 @@
+package models
+
+import "github.com/google/uuid"
+
+type AlertChannel string
+
+const (
+	AlertChannelEmail   AlertChannel = "EMAIL"
+	AlertChannelWebhook AlertChannel = "WEBHOOK"
+	AlertChannelDiscord AlertChannel = "DISCORD"
+)
+
+// A provider's opt-in to be alerted when all of their workers have been disconnected for AfterMinutes
+type OfflineAlert struct {
+	Base
+	UserID  uuid.UUID    `json:"user_id" gorm:"not null;uniqueIndex"`
+	Channel AlertChannel `json:"channel" gorm:"not null"`
+	// Webhook URL, unused for email alerts which go to the account's email
+	Target       string `json:"target"`
+	AfterMinutes int    `json:"after_minutes" gorm:"not null"`
+}
@@ -13,4 +13,7 @@ type Payment struct {
 	Amount uint `json:"amount"`
 	SendJson  models.SendRequest `json:"send_json" gorm:"type:jsonb;not null"`
 	PaidTo    uuid.UUID          `json:"user_id" gorm:"not null"`
+	TenantID  string             `json:"tenant_id" gorm:"default:'default';not null;index"`
+	// Null for payments made before payout cycles were recorded
+	CycleID *uuid.UUID `json:"cycle_id" gorm:"type:uuid;index"`
 }
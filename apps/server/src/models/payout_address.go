@@ -0,0 +1,12 @@
+package models
+
+import "github.com/google/uuid"
+
+// One of the addresses a provider's payouts are split between
+type PayoutAddress struct {
+	Base
+	UserID     uuid.UUID `json:"user_id" gorm:"not null;index"`
+	BanAddress string    `json:"ban_address" gorm:"not null"`
+	// Share of each payout in whole percents, all addresses of a user add up to 100
+	Percent int `json:"percent" gorm:"not null"`
+}
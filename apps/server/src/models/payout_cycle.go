@@ -0,0 +1,35 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// What a provider's payout in a cycle was computed from, the unpaid work when the payout job ran
+type PayoutCycleInput struct {
+	ProviderID           string `json:"provider_id"`
+	BanAddress           string `json:"ban_address"`
+	UnpaidCount          int    `json:"unpaid_count"`
+	DifficultySum        int    `json:"difficulty_sum"`
+	RatedAwardRaw        string `json:"rated_award_raw"`
+	UnratedDifficultySum int    `json:"unrated_difficulty_sum"`
+}
+
+type PayoutCycleInputs []PayoutCycleInput
+
+func (j PayoutCycleInputs) Value() (driver.Value, error) {
+	valueString, err := json.Marshal(j)
+	return string(valueString), err
+}
+
+func (j *PayoutCycleInputs) Scan(value interface{}) error {
+	return json.Unmarshal(value.([]byte), j)
+}
+
+// One run of the payout job for a tenant, the payments it created reference it so the distribution can be audited
+type PayoutCycle struct {
+	Base
+	TenantID  string            `json:"tenant_id" gorm:"default:'default';not null;index"`
+	PrizePool int               `json:"prize_pool" gorm:"not null"`
+	Inputs    PayoutCycleInputs `json:"inputs" gorm:"type:jsonb;not null"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "github.com/google/uuid"
+
+// A provider's statement of a closed payout cycle, created when it's sent so each provider gets it once
+type PayoutStatement struct {
+	Base
+	CycleID uuid.UUID `json:"cycle_id" gorm:"type:uuid;not null;uniqueIndex:idx_payout_statement_cycle"`
+	UserID  uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_payout_statement_cycle"`
+	// Not emailed because the provider unsubscribed
+	Unsubscribed bool `json:"unsubscribed" gorm:"default:false;not null"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/bananocoin/boompow/libs/utils/number"
+	"github.com/google/uuid"
+)
+
+type CreditEntryKind string
+
+const (
+	// BAN a requester sent to the pool, recorded by an admin
+	CreditEntryDeposit CreditEntryKind = "deposit"
+	// Credit spent on a priority boost, negative
+	CreditEntryBoost CreditEntryKind = "boost"
+)
+
+// A change to a requester's prepaid credit, the balance is the sum of them
+type CreditEntry struct {
+	Base
+	UserID    uuid.UUID       `json:"user_id" gorm:"type:uuid;not null;index"`
+	AmountRaw string          `json:"amount_raw" gorm:"type:numeric;not null"`
+	Kind      CreditEntryKind `json:"kind" gorm:"not null"`
+	// Block hash of a deposit or ID of a boost, a deposit can't be credited twice
+	Reference string `json:"reference" gorm:"not null;uniqueIndex"`
+	// Admin who recorded a deposit
+	CreatedBy *uuid.UUID `json:"created_by" gorm:"type:uuid"`
+}
+
+// A window during which a requester's work requests go out before everyone else's on-demand work
+type PriorityBoost struct {
+	Base
+	UserID   uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	TenantID string    `json:"tenant_id" gorm:"not null;index"`
+	StartsAt time.Time `json:"starts_at" gorm:"not null"`
+	EndsAt   time.Time `json:"ends_at" gorm:"not null;index"`
+	Minutes  int       `json:"minutes" gorm:"not null"`
+	PriceRaw string    `json:"price_raw" gorm:"type:numeric;not null"`
+}
+
+// What boosts cost and how many minutes of them a requester can buy per UTC day
+type BoostPricing struct {
+	BananoPerMinute float64
+	DailyCapMinutes int
+}
+
+var ErrBoostsDisabled = errors.New("boosts disabled")
+
+// Prices are rounded to 0.01 BAN, boosts aren't given away
+func (p BoostPricing) Enabled() bool {
+	return math.Round(p.BananoPerMinute*100) >= 1
+}
+
+// Price of minutes of boost in raw
+func (p BoostPricing) PriceRaw(minutes int) (*big.Int, error) {
+	if !p.Enabled() {
+		return nil, ErrBoostsDisabled
+	}
+	perMinute, err := number.RawToBigInt(number.BananoToRaw(p.BananoPerMinute))
+	if err != nil {
+		return nil, err
+	}
+	return perMinute.Mul(perMinute, big.NewInt(int64(minutes))), nil
+}
+
+// Boosts bought while one is active start when it ends
+func BoostWindow(activeUntil *time.Time, minutes int, now time.Time) (time.Time, time.Time) {
+	start := now
+	if activeUntil != nil && activeUntil.After(now) {
+		start = *activeUntil
+	}
+	return start, start.Add(time.Duration(minutes) * time.Minute)
+}
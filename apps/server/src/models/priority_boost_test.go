@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestBoostPricing(t *testing.T) {
+	_, err := BoostPricing{DailyCapMinutes: 60}.PriceRaw(10)
+	utils.AssertEqual(t, ErrBoostsDisabled, err)
+	_, err = BoostPricing{BananoPerMinute: 0.001, DailyCapMinutes: 60}.PriceRaw(10)
+	utils.AssertEqual(t, ErrBoostsDisabled, err)
+
+	price, err := BoostPricing{BananoPerMinute: 0.5, DailyCapMinutes: 60}.PriceRaw(10)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "500000000000000000000000000000", price.String())
+}
+
+func TestBoostWindow(t *testing.T) {
+	now := time.Date(2022, 8, 1, 23, 50, 0, 0, time.UTC)
+	start, end := BoostWindow(nil, 15, now)
+	utils.AssertEqual(t, now, start)
+	utils.AssertEqual(t, now.Add(15*time.Minute), end)
+
+	// Stacks onto the active boost
+	activeUntil := now.Add(5 * time.Minute)
+	start, end = BoostWindow(&activeUntil, 15, now)
+	utils.AssertEqual(t, activeUntil, start)
+	utils.AssertEqual(t, activeUntil.Add(15*time.Minute), end)
+
+	expired := now.Add(-time.Minute)
+	start, _ = BoostWindow(&expired, 15, now)
+	utils.AssertEqual(t, now, start)
+
+	utils.AssertEqual(t, time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC), StartOfDay(now))
+}
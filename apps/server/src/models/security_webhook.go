@@ -0,0 +1,11 @@
+package models
+
+import "github.com/google/uuid"
+
+// Where a user's security events are posted, signed with Secret so their SIEM can check they came from us
+type SecurityWebhook struct {
+	Base
+	UserID uuid.UUID `json:"user_id" gorm:"not null;uniqueIndex"`
+	URL    string    `json:"url" gorm:"not null"`
+	Secret string    `json:"-" gorm:"not null"`
+}
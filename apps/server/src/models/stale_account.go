@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/libs/utils"
+)
+
+// How long accounts that never verified their email wait for each step of the cleanup
+type StaleAccountPolicy struct {
+	// Since signing up, 0 turns the cleanup off
+	RemindAfter time.Duration
+	// Since the reminder
+	DisableAfter time.Duration
+	// Since being disabled
+	AnonymizeAfter time.Duration
+}
+
+// As configured with the BPOW_STALE_ACCOUNT_*_DAYS variables
+func GetStaleAccountPolicy() StaleAccountPolicy {
+	day := 24 * time.Hour
+	return StaleAccountPolicy{
+		RemindAfter:    time.Duration(utils.GetStaleAccountRemindDays()) * day,
+		DisableAfter:   time.Duration(utils.GetStaleAccountDisableDays()) * day,
+		AnonymizeAfter: time.Duration(utils.GetStaleAccountAnonymizeDays()) * day,
+	}
+}
+
+type StaleAccountStep string
+
+const (
+	StaleAccountNone      StaleAccountStep = ""
+	StaleAccountRemind    StaleAccountStep = "remind"
+	StaleAccountDisable   StaleAccountStep = "disable"
+	StaleAccountAnonymize StaleAccountStep = "anonymize"
+)
+
+func (p StaleAccountPolicy) Enabled() bool {
+	return p.RemindAfter > 0
+}
+
+// The step that is due for the user, accounts are always reminded before they're disabled and disabled before they're anonymized
+func (p StaleAccountPolicy) NextStep(user *User, now time.Time) StaleAccountStep {
+	if !p.Enabled() || user.EmailVerified || user.StaleExempt || user.AnonymizedAt != nil {
+		return StaleAccountNone
+	}
+	switch {
+	case user.DisabledAt != nil:
+		if now.Sub(*user.DisabledAt) >= p.AnonymizeAfter {
+			return StaleAccountAnonymize
+		}
+	case user.StaleReminderSentAt != nil:
+		if now.Sub(*user.StaleReminderSentAt) >= p.DisableAfter {
+			return StaleAccountDisable
+		}
+	case now.Sub(user.CreatedAt) >= p.RemindAfter:
+		return StaleAccountRemind
+	}
+	return StaleAccountNone
+}
+
+// When an account reminded at remindedAt is disabled if it's still not verified
+func (p StaleAccountPolicy) DisableOn(remindedAt time.Time) time.Time {
+	return remindedAt.Add(p.DisableAfter)
+}
+
+// What a run of the cleanup did, for admins
+type StaleAccountReport struct {
+	Base
+	Reminded   int `json:"reminded" gorm:"not null"`
+	Disabled   int `json:"disabled" gorm:"not null"`
+	Anonymized int `json:"anonymized" gorm:"not null"`
+	// Steps that were due but failed, they're tried again on the next run
+	Failed int `json:"failed" gorm:"not null"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestStaleAccountNextStep(t *testing.T) {
+	day := 24 * time.Hour
+	policy := StaleAccountPolicy{RemindAfter: 30 * day, DisableAfter: 14 * day, AnonymizeAfter: 30 * day}
+	signedUp := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+	user := &User{Base: Base{CreatedAt: signedUp}}
+
+	utils.AssertEqual(t, StaleAccountNone, policy.NextStep(user, signedUp.Add(29*day)))
+	utils.AssertEqual(t, StaleAccountRemind, policy.NextStep(user, signedUp.Add(30*day)))
+
+	reminded := signedUp.Add(31 * day)
+	user.StaleReminderSentAt = &reminded
+	utils.AssertEqual(t, StaleAccountNone, policy.NextStep(user, reminded.Add(13*day)))
+	utils.AssertEqual(t, StaleAccountDisable, policy.NextStep(user, reminded.Add(14*day)))
+	utils.AssertEqual(t, reminded.Add(14*day), policy.DisableOn(reminded))
+
+	disabled := reminded.Add(15 * day)
+	user.DisabledAt = &disabled
+	utils.AssertEqual(t, StaleAccountNone, policy.NextStep(user, disabled.Add(29*day)))
+	utils.AssertEqual(t, StaleAccountAnonymize, policy.NextStep(user, disabled.Add(30*day)))
+
+	anonymized := disabled.Add(30 * day)
+	user.AnonymizedAt = &anonymized
+	utils.AssertEqual(t, StaleAccountNone, policy.NextStep(user, anonymized.Add(365*day)))
+
+	// Verified and exempt accounts and a policy without reminders are left alone
+	now := signedUp.Add(365 * day)
+	utils.AssertEqual(t, StaleAccountNone, policy.NextStep(&User{Base: Base{CreatedAt: signedUp}, EmailVerified: true}, now))
+	utils.AssertEqual(t, StaleAccountNone, policy.NextStep(&User{Base: Base{CreatedAt: signedUp}, StaleExempt: true}, now))
+	utils.AssertEqual(t, StaleAccountNone, StaleAccountPolicy{}.NextStep(&User{Base: Base{CreatedAt: signedUp}}, now))
+}
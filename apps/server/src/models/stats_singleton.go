@@ -9,7 +9,9 @@ import (
 var lock = &sync.Mutex{}
 
 type StatsSingleton struct {
-	Stats *model.Stats
+	mu sync.RWMutex
+	// Stats for each tenant
+	stats map[string]*model.Stats
 }
 
 var statsInstance *StatsSingleton
@@ -19,9 +21,24 @@ func GetStatsInstance() *StatsSingleton {
 		lock.Lock()
 		defer lock.Unlock()
 		if statsInstance == nil {
-			statsInstance = &StatsSingleton{}
+			statsInstance = &StatsSingleton{
+				stats: make(map[string]*model.Stats),
+			}
 		}
 	}
 
 	return statsInstance
 }
+
+// Get the latest stats for a tenant, nil if they haven't been computed yet
+func (s *StatsSingleton) Get(tenantID string) *model.Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stats[tenantID]
+}
+
+func (s *StatsSingleton) Set(tenantID string, stats *model.Stats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats[tenantID] = stats
+}
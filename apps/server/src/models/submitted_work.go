@@ -0,0 +1,14 @@
+package models
+
+import "github.com/google/uuid"
+
+// Work a trusted requester computed on their own hardware and pushed into the cache
+// Kept apart from work results, it isn't solved by providers so it doesn't count towards stats or payouts
+type SubmittedWork struct {
+	Base
+	TenantID             string    `json:"tenant_id" gorm:"not null;uniqueIndex:idx_submitted_work_hash"`
+	Hash                 string    `json:"hash" gorm:"not null;uniqueIndex:idx_submitted_work_hash"`
+	DifficultyMultiplier int       `json:"difficulty_multiplier" gorm:"not null"`
+	Result               string    `json:"result" gorm:"not null"`
+	SubmittedBy          uuid.UUID `json:"submitted_by" gorm:"type:uuid;not null;index"`
+}
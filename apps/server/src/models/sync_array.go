@@ -2,16 +2,26 @@ package models
 
 import (
 	"sync"
+	"time"
 )
 
 type ActiveChannelObject struct {
 	BlockAward           bool
 	RequesterEmail       string
+	TenantID             string
 	RequestID            string
 	Hash                 string
 	DifficultyMultiplier int
 	Precache             bool
-	Chan                 chan []byte
+	// Share of the difficulty the solve is credited with, 0 is full credit
+	CreditPercent int
+	Chan          chan []byte
+	// When the request was broadcast to workers, used to measure solve latency
+	RequestedAt time.Time
+	// Set by the hub, when the request went out to the first worker and when a result came back
+	DispatchedAt   time.Time
+	ResultAt       time.Time
+	ValidationTime time.Duration
 }
 
 // SyncArray builds an thread-safe array with some handy methods
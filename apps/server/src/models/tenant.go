@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/libs/utils"
+)
+
+// A tenant is an independent pool hosted by this deployment, users, work and payments never cross tenants
+type Tenant struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Zero values fall back to the environment/global defaults
+	PrizePool               int `json:"prize_pool" gorm:"default:0;not null"`
+	MaxDifficultyMultiplier int `json:"max_difficulty_multiplier" gorm:"default:0;not null"`
+	// What the tenant's network needs, requesters habitually asking for more get an advisory
+	NetworkDifficultyMultiplier int `json:"network_difficulty_multiplier" gorm:"default:0;not null"`
+	// Also clamp those requests to the network difficulty
+	ClampExcessDifficulty bool    `json:"clamp_excess_difficulty" gorm:"default:false;not null"`
+	WalletID              *string `json:"wallet_id"`
+	WalletAddress         *string `json:"wallet_address"`
+}
+
+func (t *Tenant) GetPrizePool() int {
+	if t.PrizePool > 0 {
+		return t.PrizePool
+	}
+	return utils.GetTotalPrizePool()
+}
+
+func (t *Tenant) GetMaxDifficultyMultiplier() int {
+	if t.MaxDifficultyMultiplier > 0 {
+		return t.MaxDifficultyMultiplier
+	}
+	return config.MAX_WORK_DIFFICULTY_MULTIPLIER
+}
+
+func (t *Tenant) GetNetworkDifficultyMultiplier() int {
+	if t.NetworkDifficultyMultiplier > 0 {
+		return t.NetworkDifficultyMultiplier
+	}
+	return utils.GetNetworkDifficultyMultiplier()
+}
+
+func (t *Tenant) GetWalletID() string {
+	if t.WalletID != nil {
+		return *t.WalletID
+	}
+	return utils.GetWalletID()
+}
+
+func (t *Tenant) GetWalletAddress() string {
+	if t.WalletAddress != nil {
+		return *t.WalletAddress
+	}
+	return utils.GetWalletAddress()
+}
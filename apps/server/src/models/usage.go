@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Number of work requests a requester made per month and difficulty, counted as they come in like the difficulty rollups
+type UsageRollup struct {
+	Month                time.Time `json:"month" gorm:"primaryKey"`
+	UserID               uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	DifficultyMultiplier int       `json:"difficulty_multiplier" gorm:"primaryKey"`
+	TenantID             string    `json:"tenant_id" gorm:"default:'default';not null"`
+	// Solved by a provider
+	Requests int64 `json:"requests" gorm:"default:0;not null"`
+	// Answered from the work cache
+	Cached int64 `json:"cached" gorm:"default:0;not null"`
+}
+
+// A requester's usage of a month that has ended, created once the month is over
+type UsageStatement struct {
+	Base
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_usage_statement_month"`
+	Month     time.Time  `json:"month" gorm:"not null;uniqueIndex:idx_usage_statement_month"`
+	TenantID  string     `json:"tenant_id" gorm:"default:'default';not null"`
+	Requests  int64      `json:"requests" gorm:"default:0;not null"`
+	Cached    int64      `json:"cached" gorm:"default:0;not null"`
+	EmailedAt *time.Time `json:"emailed_at"`
+}
+
+// First instant of the month t is in, in UTC
+func StartOfMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Start of the UTC day t is in
+func StartOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -1,10 +1,17 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/libs/utils"
+	"github.com/bananocoin/boompow/libs/utils/validation"
+	"gorm.io/gorm"
+)
 
 type User struct {
 	Base
 	Type               UserType `gorm:"type:user_type;not null"`
+	TenantID           string   `json:"tenantId" gorm:"default:'default';not null;index"`
 	Email              string   `json:"email" gorm:"uniqueIndex;not null"`
 	Password           string   `json:"password" gorm:"not null"`
 	EmailVerified      bool     `json:"emailVerfied" gorm:"default:false;not null"`
@@ -12,6 +19,28 @@ type User struct {
 	ServiceWebsite     *string  `json:"serviceWebsite"`
 	CanRequestWork     bool     `json:"canRequestWork" gorm:"default:false;not null"`
 	InvalidResultCount int      `json:"invalidResultCount" gorm:"default:0;not null"`
+	// The mailbox the email delivers to, new accounts can't have the same one as an existing account
+	NormalizedEmail string `json:"-" gorm:"index"`
+	// Requesters can opt in to timing metadata in work responses
+	IncludeWorkTimings bool `json:"includeWorkTimings" gorm:"default:false;not null"`
+	// Providers get a statement email when a payout cycle closes unless they unsubscribe
+	PayoutStatementEmails bool `json:"payoutStatementEmails" gorm:"default:true;not null"`
+	// Difficulty multipliers an admin allowed a requester, 0 leaves a bound to the tenant
+	MinDifficultyMultiplier int `json:"minDifficultyMultiplier" gorm:"default:0;not null"`
+	MaxDifficultyMultiplier int `json:"maxDifficultyMultiplier" gorm:"default:0;not null"`
+	// Authenticator app secret, only required at login once enrollment was confirmed
+	TwoFactorSecret  *string `json:"-"`
+	TwoFactorEnabled bool    `json:"twoFactorEnabled" gorm:"default:false;not null"`
+	// Set by admins instead of the limit of the user's class, 0 doesn't limit them
+	RateLimitPerMinute *int `json:"rateLimitPerMinute"`
+	// Tokens issued before this are rejected
+	SessionsRevokedAt *time.Time `json:"sessionsRevokedAt"`
+	// Cleanup of accounts that never verified their email, see StaleAccountPolicy
+	StaleReminderSentAt *time.Time `json:"staleReminderSentAt"`
+	DisabledAt          *time.Time `json:"disabledAt"`
+	AnonymizedAt        *time.Time `json:"anonymizedAt"`
+	// Set by admins to keep the cleanup away from the account
+	StaleExempt bool `json:"staleExempt" gorm:"default:false;not null"`
 	// For reward payments
 	BanAddress *string `json:"banAddress"`
 	// The work this user provider
@@ -22,4 +51,74 @@ type User struct {
 	LastRequestedWorkAt *time.Time   `json:"lastRequestedWorkAt"`
 	// Payments sent to this user
 	Payments []Payment `gorm:"foreignKey:PaidTo"`
+	// Loaded with the user, permissions come from them
+	Roles []UserRole `gorm:"foreignKey:UserID"`
+}
+
+// Aliases of a mailbox are folded as configured with BPOW_EMAIL_ALIASES
+func NormalizeEmail(email string) string {
+	return validation.NormalizeEmail(email, utils.GetEmailAliases())
+}
+
+// Keeps the normalized email in step with the email
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	if u.Email != "" {
+		u.NormalizedEmail = NormalizeEmail(u.Email)
+	}
+	return nil
+}
+
+func (u *User) HasRole(role RoleName) bool {
+	for _, r := range u.Roles {
+		if r.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *User) Banned() bool {
+	return u.HasRole(RoleBanned)
+}
+
+// Disabled by the stale account cleanup, until an admin exempts the account
+func (u *User) Disabled() bool {
+	return u.DisabledAt != nil
+}
+
+// Whether any of the user's roles grants permission, banned users have none
+func (u *User) HasPermission(permission Permission) bool {
+	if u.Banned() {
+		return false
+	}
+	for _, r := range u.Roles {
+		for _, p := range RolePermissions[r.Role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Whether a token issued at issuedAt belongs to a session that has been revoked
+func (u *User) SessionRevoked(issuedAt time.Time) bool {
+	return u.SessionsRevokedAt != nil && issuedAt.Before(*u.SessionsRevokedAt)
+}
+
+// Whether an admin limited the difficulties this requester can ask for
+func (u *User) HasDifficultyRange() bool {
+	return u.MinDifficultyMultiplier > 0 || u.MaxDifficultyMultiplier > 0
+}
+
+// Difficulty multipliers this requester can ask for within the tenant's maximum
+func (u *User) DifficultyRange(tenantMax int) (int, int) {
+	min, max := 1, tenantMax
+	if u.MinDifficultyMultiplier > 0 {
+		min = u.MinDifficultyMultiplier
+	}
+	if u.MaxDifficultyMultiplier > 0 && u.MaxDifficultyMultiplier < tenantMax {
+		max = u.MaxDifficultyMultiplier
+	}
+	return min, max
 }
@@ -0,0 +1,39 @@
+package models
+
+import "github.com/google/uuid"
+
+type RoleName string
+
+const (
+	RoleAdmin     RoleName = "ADMIN"
+	RoleModerator RoleName = "MODERATOR"
+	// Banned users can't log in or use their tokens, whatever other roles they have
+	RoleBanned RoleName = "BANNED"
+)
+
+type Permission string
+
+const (
+	PermissionBanUsers        Permission = "BAN_USERS"
+	PermissionModerateWorkers Permission = "MODERATE_WORKERS"
+	PermissionAdjustPayouts   Permission = "ADJUST_PAYOUTS"
+	PermissionManageRoles     Permission = "MANAGE_ROLES"
+)
+
+var AllPermissions = []Permission{PermissionBanUsers, PermissionModerateWorkers, PermissionAdjustPayouts, PermissionManageRoles}
+
+// What each role allows, admins can do everything
+var RolePermissions = map[RoleName][]Permission{
+	RoleAdmin:     AllPermissions,
+	RoleModerator: {PermissionBanUsers, PermissionModerateWorkers},
+	RoleBanned:    {},
+}
+
+// A role granted to a user on top of their type
+type UserRole struct {
+	Base
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_roles_user_role"`
+	Role   RoleName  `json:"role" gorm:"not null;uniqueIndex:idx_user_roles_user_role"`
+	// Null for roles granted outside of the API
+	GrantedBy *uuid.UUID `json:"granted_by" gorm:"type:uuid"`
+}
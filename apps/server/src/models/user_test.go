@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestUserDifficultyRange(t *testing.T) {
+	user := User{}
+	utils.AssertEqual(t, false, user.HasDifficultyRange())
+	min, max := user.DifficultyRange(64)
+	utils.AssertEqual(t, 1, min)
+	utils.AssertEqual(t, 64, max)
+
+	// A lower tenant maximum still wins
+	user = User{MinDifficultyMultiplier: 2, MaxDifficultyMultiplier: 128}
+	utils.AssertEqual(t, true, user.HasDifficultyRange())
+	min, max = user.DifficultyRange(64)
+	utils.AssertEqual(t, 2, min)
+	utils.AssertEqual(t, 64, max)
+}
+
+func TestUserPermissions(t *testing.T) {
+	user := User{}
+	utils.AssertEqual(t, false, user.HasPermission(PermissionBanUsers))
+
+	user = User{Roles: []UserRole{{Role: RoleModerator}}}
+	utils.AssertEqual(t, true, user.HasPermission(PermissionBanUsers))
+	utils.AssertEqual(t, false, user.HasPermission(PermissionAdjustPayouts))
+
+	user = User{Roles: []UserRole{{Role: RoleAdmin}}}
+	for _, permission := range AllPermissions {
+		utils.AssertEqual(t, true, user.HasPermission(permission))
+	}
+
+	// Being banned outweighs every other role
+	user = User{Roles: []UserRole{{Role: RoleAdmin}, {Role: RoleBanned}}}
+	utils.AssertEqual(t, true, user.Banned())
+	utils.AssertEqual(t, false, user.HasPermission(PermissionBanUsers))
+}
@@ -11,4 +11,9 @@ type WorkResult struct {
 	ProvidedBy           uuid.UUID `json:"providedBy" gorm:"not null"`
 	RequestedBy          uuid.UUID `json:"requestedBy" gorm:"not null"`
 	Precache             bool      `json:"precache" gorm:"default:false;not null"`
+	TenantID             string    `json:"tenant_id" gorm:"default:'default';not null;index"`
+	// Time between the request being broadcast and a valid result coming back
+	SolveLatencyMs int64 `json:"solve_latency_ms" gorm:"default:0;not null"`
+	// Share of the difficulty credited towards payouts, less for work idle workers precached
+	CreditPercent int `json:"credit_percent" gorm:"default:100;not null"`
 }
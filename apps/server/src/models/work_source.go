@@ -0,0 +1,46 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A named deployment of a requester, e.g. prod, staging or faucet
+// Requests are attributed to it by appending .<name> to the service token or API key
+type WorkSource struct {
+	Base
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_work_source_name"`
+	Name   string    `json:"name" gorm:"not null;uniqueIndex:idx_work_source_name"`
+	// Work requests per UTC day, null doesn't limit the source
+	DailyQuota *int `json:"daily_quota"`
+}
+
+// Work requests of a source per UTC day
+type WorkSourceUsage struct {
+	Day      time.Time `json:"day" gorm:"primaryKey"`
+	SourceID uuid.UUID `json:"source_id" gorm:"type:uuid;primaryKey"`
+	UserID   uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	// Solved by a provider
+	Requests int64 `json:"requests" gorm:"default:0;not null"`
+	// Answered from the work cache
+	Cached int64 `json:"cached" gorm:"default:0;not null"`
+}
+
+var workSourceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Lower case letters, digits, dashes and underscores, up to 32 characters
+func ValidWorkSourceName(name string) bool {
+	return workSourceNamePattern.MatchString(name)
+}
+
+// Splits the source suffix off a service token or API key, the source is empty if there's none
+func SplitWorkSource(token string) (string, string) {
+	i := strings.LastIndex(token, ".")
+	if i < 0 || !ValidWorkSourceName(token[i+1:]) {
+		return token, ""
+	}
+	return token[:i], token[i+1:]
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestValidWorkSourceName(t *testing.T) {
+	utils.AssertEqual(t, true, ValidWorkSourceName("prod"))
+	utils.AssertEqual(t, true, ValidWorkSourceName("faucet-2"))
+	utils.AssertEqual(t, false, ValidWorkSourceName(""))
+	utils.AssertEqual(t, false, ValidWorkSourceName("Prod"))
+	utils.AssertEqual(t, false, ValidWorkSourceName("-prod"))
+	utils.AssertEqual(t, false, ValidWorkSourceName("a.b"))
+	utils.AssertEqual(t, false, ValidWorkSourceName("abcdefghijklmnopqrstuvwxyz0123456"))
+}
+
+func TestSplitWorkSource(t *testing.T) {
+	token, source := SplitWorkSource("service:0b1f5b4e-8c39-4a5e-9f0e-1a2b3c4d5e6f.staging")
+	utils.AssertEqual(t, "service:0b1f5b4e-8c39-4a5e-9f0e-1a2b3c4d5e6f", token)
+	utils.AssertEqual(t, "staging", source)
+
+	token, source = SplitWorkSource("service:0b1f5b4e-8c39-4a5e-9f0e-1a2b3c4d5e6f")
+	utils.AssertEqual(t, "service:0b1f5b4e-8c39-4a5e-9f0e-1a2b3c4d5e6f", token)
+	utils.AssertEqual(t, "", source)
+
+	// Not a source name, left to fail as a token
+	token, source = SplitWorkSource("service:abc.NOPE")
+	utils.AssertEqual(t, "service:abc.NOPE", token)
+	utils.AssertEqual(t, "", source)
+}
@@ -0,0 +1,135 @@
+// Package pagination pages list queries with opaque cursors
+// Lists are ordered newest first by time and then ID, so cursors stay stable while items are added
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"gorm.io/gorm"
+)
+
+// Where an item sits in its list
+type Cursor struct {
+	Time time.Time
+	ID   string
+}
+
+func (c Cursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.Time.UTC().Format(time.RFC3339Nano) + "|" + c.ID))
+}
+
+func ParseCursor(s string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, errors.New("bad_request:invalid cursor")
+	}
+	at, id, ok := strings.Cut(string(b), "|")
+	if !ok {
+		return Cursor{}, errors.New("bad_request:invalid cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return Cursor{}, errors.New("bad_request:invalid cursor")
+	}
+	return Cursor{Time: t, ID: id}, nil
+}
+
+// Whether c comes after other in the list
+func (c Cursor) After(other Cursor) bool {
+	if !c.Time.Equal(other.Time) {
+		return c.Time.Before(other.Time)
+	}
+	return c.ID < other.ID
+}
+
+// What a list query asked for, After is nil for the first page
+type Args struct {
+	First int
+	After *Cursor
+}
+
+func ParseArgs(first *int, after *string) (Args, error) {
+	args := Args{First: config.PAGE_SIZE}
+	if first != nil {
+		if *first < 1 || *first > config.MAX_PAGE_SIZE {
+			return Args{}, fmt.Errorf("bad_request:first must be between 1 and %d", config.MAX_PAGE_SIZE)
+		}
+		args.First = *first
+	}
+	if after != nil {
+		cursor, err := ParseCursor(*after)
+		if err != nil {
+			return Args{}, err
+		}
+		args.After = &cursor
+	}
+	return args, nil
+}
+
+// Includes reports whether an item at c belongs on this page or a later one
+func (a Args) Includes(c Cursor) bool {
+	return a.After == nil || c.After(*a.After)
+}
+
+// Limit is one more than the page, the extra item tells whether there's a next page
+func (a Args) Limit() int {
+	return a.First + 1
+}
+
+// Scope pages a query over a table ordered by timeColumn and idColumn
+func (a Args) Scope(timeColumn string, idColumn string) func(*gorm.DB) *gorm.DB {
+	return a.SortedScope(timeColumn, idColumn, true)
+}
+
+// SortedScope is Scope for lists that can be ordered oldest first, cursors only work with the order they came from
+func (a Args) SortedScope(timeColumn string, idColumn string, descending bool) func(*gorm.DB) *gorm.DB {
+	comparison, direction := ">", "asc"
+	if descending {
+		comparison, direction = "<", "desc"
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		if a.After != nil {
+			db = db.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", timeColumn, idColumn, comparison), a.After.Time, a.After.ID)
+		}
+		return db.Order(fmt.Sprintf("%s %s, %s %s", timeColumn, direction, idColumn, direction)).Limit(a.Limit())
+	}
+}
+
+type Page[T any] struct {
+	Items       []T
+	HasNextPage bool
+	// Nil on an empty page
+	EndCursor *Cursor
+}
+
+// NewPage makes a page from up to Limit items in list order, as fetched with Scope
+func NewPage[T any](items []T, args Args, cursor func(T) Cursor) Page[T] {
+	page := Page[T]{Items: items}
+	if len(items) > args.First {
+		page.Items = items[:args.First]
+		page.HasNextPage = true
+	}
+	if len(page.Items) > 0 {
+		end := cursor(page.Items[len(page.Items)-1])
+		page.EndCursor = &end
+	}
+	return page
+}
+
+// Slice pages a whole list that's already in memory and in list order
+func Slice[T any](items []T, args Args, cursor func(T) Cursor) Page[T] {
+	start := 0
+	for start < len(items) && !args.Includes(cursor(items[start])) {
+		start++
+	}
+	end := start + args.Limit()
+	if end > len(items) {
+		end = len(items)
+	}
+	return NewPage(items[start:end], args, cursor)
+}
@@ -0,0 +1,51 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	args, err := ParseArgs(nil, nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 20, args.First)
+	utils.AssertEqual(t, true, args.After == nil)
+
+	tooMany := 101
+	_, err = ParseArgs(&tooMany, nil)
+	utils.AssertNotEqual(t, nil, err)
+	badCursor := "yesterday"
+	_, err = ParseArgs(nil, &badCursor)
+	utils.AssertNotEqual(t, nil, err)
+
+	cursor := Cursor{Time: time.Date(2022, 9, 1, 12, 0, 0, 123, time.UTC), ID: "b"}
+	encoded := cursor.String()
+	args, err = ParseArgs(nil, &encoded)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, args.After.Time.Equal(cursor.Time))
+	utils.AssertEqual(t, "b", args.After.ID)
+}
+
+func TestSlice(t *testing.T) {
+	at := time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)
+	// Two items at the same time are told apart by ID
+	items := []Cursor{{at.Add(time.Minute), "a"}, {at, "c"}, {at, "b"}, {at.Add(-time.Minute), "d"}}
+	cursor := func(c Cursor) Cursor { return c }
+
+	page := Slice(items, Args{First: 2}, cursor)
+	utils.AssertEqual(t, items[:2], page.Items)
+	utils.AssertEqual(t, true, page.HasNextPage)
+	page = Slice(items, Args{First: 2, After: page.EndCursor}, cursor)
+	utils.AssertEqual(t, items[2:], page.Items)
+	utils.AssertEqual(t, false, page.HasNextPage)
+	page = Slice(items, Args{First: 2, After: page.EndCursor}, cursor)
+	utils.AssertEqual(t, 0, len(page.Items))
+	utils.AssertEqual(t, true, page.EndCursor == nil)
+
+	// New items at the front don't move later pages
+	items = append([]Cursor{{at.Add(time.Hour), "e"}}, items...)
+	page = Slice(items, Args{First: 2, After: &Cursor{at, "c"}}, cursor)
+	utils.AssertEqual(t, []Cursor{{at, "b"}, {at.Add(-time.Minute), "d"}}, page.Items)
+}
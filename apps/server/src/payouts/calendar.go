@@ -0,0 +1,61 @@
+// Package payouts projects the upcoming payout cycles and reports what past ones paid, moneybags pays out once a day
+package payouts
+
+import (
+	"math/big"
+	"time"
+)
+
+type Funding string
+
+const (
+	Funded      Funding = "FUNDED"
+	Underfunded Funding = "UNDERFUNDED"
+	// Moneybags didn't record the wallet balance yet
+	FundingUnknown Funding = "UNKNOWN"
+)
+
+type Cycle struct {
+	PayoutAt time.Time
+	// What the cycle is expected to pay out, in raw
+	RequiredRaw *big.Int
+	Funding     Funding
+}
+
+// NextPayout is the first payout at hour (UTC) after now
+func NextPayout(now time.Time, hour int) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Calendar lists the next cycles, the first one needs at least what's owed so far
+// A cycle is funded if the wallet balance covers it and every cycle before it, balance is nil if it's unknown
+func Calendar(now time.Time, hour int, cycles int, prizePoolRaw *big.Int, owedRaw *big.Int, balanceRaw *big.Int) []Cycle {
+	ret := make([]Cycle, cycles)
+	next := NextPayout(now, hour)
+	needed := new(big.Int)
+	for i := range ret {
+		required := new(big.Int).Set(prizePoolRaw)
+		// Work done at an award rate can add up to more than the prize pool
+		if i == 0 && owedRaw.Cmp(required) > 0 {
+			required.Set(owedRaw)
+		}
+		needed.Add(needed, required)
+		ret[i] = Cycle{
+			PayoutAt:    next.AddDate(0, 0, i),
+			RequiredRaw: required,
+			Funding:     FundingUnknown,
+		}
+		if balanceRaw != nil {
+			ret[i].Funding = Underfunded
+			if balanceRaw.Cmp(needed) >= 0 {
+				ret[i].Funding = Funded
+			}
+		}
+	}
+	return ret
+}
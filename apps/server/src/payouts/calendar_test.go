@@ -0,0 +1,40 @@
+package payouts
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/libs/utils/number"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestNextPayout(t *testing.T) {
+	utils.AssertEqual(t, time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC), NextPayout(time.Date(2022, 10, 1, 7, 59, 0, 0, time.UTC), 8))
+	// Right at payout time the next one is tomorrow
+	utils.AssertEqual(t, time.Date(2022, 10, 2, 8, 0, 0, 0, time.UTC), NextPayout(time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC), 8))
+	utils.AssertEqual(t, time.Date(2022, 11, 1, 8, 0, 0, 0, time.UTC), NextPayout(time.Date(2022, 10, 31, 23, 0, 0, 0, time.UTC), 8))
+}
+
+func TestCalendar(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	cycles := Calendar(now, 8, 3, number.BananoIntToRaw(1000), number.BananoIntToRaw(200), nil)
+	utils.AssertEqual(t, 3, len(cycles))
+	utils.AssertEqual(t, time.Date(2022, 10, 2, 8, 0, 0, 0, time.UTC), cycles[0].PayoutAt)
+	utils.AssertEqual(t, time.Date(2022, 10, 4, 8, 0, 0, 0, time.UTC), cycles[2].PayoutAt)
+	utils.AssertEqual(t, number.BananoIntToRaw(1000), cycles[0].RequiredRaw)
+	utils.AssertEqual(t, FundingUnknown, cycles[0].Funding)
+
+	// Owing more than the prize pool raises the first cycle
+	cycles = Calendar(now, 8, 3, number.BananoIntToRaw(1000), number.BananoIntToRaw(1200), number.BananoIntToRaw(2500))
+	utils.AssertEqual(t, number.BananoIntToRaw(1200), cycles[0].RequiredRaw)
+	utils.AssertEqual(t, number.BananoIntToRaw(1000), cycles[1].RequiredRaw)
+	utils.AssertEqual(t, Funded, cycles[0].Funding)
+	utils.AssertEqual(t, Funded, cycles[1].Funding)
+	utils.AssertEqual(t, Underfunded, cycles[2].Funding)
+
+	// One raw short
+	short := new(big.Int).Sub(number.BananoIntToRaw(2200), big.NewInt(1))
+	cycles = Calendar(now, 8, 2, number.BananoIntToRaw(1000), number.BananoIntToRaw(1200), short)
+	utils.AssertEqual(t, Underfunded, cycles[1].Funding)
+}
@@ -0,0 +1,234 @@
+package payouts
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
+)
+
+// Bumped when the report format changes, so a report is always checked against the format it was signed in
+const ReportVersion = 1
+
+// A provider's unpaid work when the payout job ran
+type ReportInput struct {
+	BanAddress           string `json:"ban_address"`
+	UnpaidCount          int    `json:"unpaid_count"`
+	DifficultySum        int    `json:"difficulty_sum"`
+	RatedAwardRaw        string `json:"rated_award_raw"`
+	UnratedDifficultySum int    `json:"unrated_difficulty_sum"`
+}
+
+type ReportOutput struct {
+	// Ban address of the provider the payment is for, it's sent to one of their payout addresses
+	Provider    string `json:"provider"`
+	Destination string `json:"destination"`
+	AmountRaw   string `json:"amount_raw"`
+	// Null until the payment is broadcast
+	BlockHash *string `json:"block_hash"`
+}
+
+// Everything needed to recompute a cycle's payouts, and what was actually paid
+type Report struct {
+	Version   int    `json:"version"`
+	CycleID   string `json:"cycle_id"`
+	TenantID  string `json:"tenant_id"`
+	CreatedAt string `json:"created_at"`
+	// In BANANO, shared by unrated work
+	PrizePool int `json:"prize_pool"`
+	// In the order the payout job computed them, which decides who gets the raw left over from rounding
+	Inputs  []ReportInput  `json:"inputs"`
+	Outputs []ReportOutput `json:"outputs"`
+}
+
+// Reports of the same cycle are identical until a payment is broadcast, which fills in its block hash
+func BuildReport(cycle *models.PayoutCycle, payments []models.Payment) Report {
+	report := Report{
+		Version:   ReportVersion,
+		CycleID:   cycle.ID.String(),
+		TenantID:  cycle.TenantID,
+		CreatedAt: cycle.CreatedAt.UTC().Format(time.RFC3339),
+		PrizePool: cycle.PrizePool,
+		Inputs:    make([]ReportInput, len(cycle.Inputs)),
+		Outputs:   make([]ReportOutput, len(payments)),
+	}
+	providers := make(map[string]string)
+	for i, input := range cycle.Inputs {
+		providers[input.ProviderID] = input.BanAddress
+		report.Inputs[i] = ReportInput{
+			BanAddress:           input.BanAddress,
+			UnpaidCount:          input.UnpaidCount,
+			DifficultySum:        input.DifficultySum,
+			RatedAwardRaw:        input.RatedAwardRaw,
+			UnratedDifficultySum: input.UnratedDifficultySum,
+		}
+	}
+	for i, payment := range payments {
+		report.Outputs[i] = ReportOutput{
+			Provider:    providers[payment.PaidTo.String()],
+			Destination: payment.SendJson.Destination,
+			AmountRaw:   payment.SendJson.AmountRaw,
+			BlockHash:   payment.BlockHash,
+		}
+	}
+	sort.Slice(report.Outputs, func(i, j int) bool {
+		a, b := report.Outputs[i], report.Outputs[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Destination != b.Destination {
+			return a.Destination < b.Destination
+		}
+		return a.AmountRaw < b.AmountRaw
+	})
+	return report
+}
+
+// Complete once every payment has been broadcast
+func (r Report) Complete() bool {
+	for _, output := range r.Outputs {
+		if output.BlockHash == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Recomputes the payouts from the inputs the way moneybags does, and checks every provider was paid exactly that
+func (r Report) Verify() error {
+	results := make([]repository.UnpaidWorkResult, len(r.Inputs))
+	for i, input := range r.Inputs {
+		results[i] = repository.UnpaidWorkResult{
+			UnpaidSumResult:      repository.UnpaidSumResult{DifficultySum: input.DifficultySum},
+			UnpaidCount:          input.UnpaidCount,
+			BanAddress:           input.BanAddress,
+			RatedAwardRaw:        input.RatedAwardRaw,
+			UnratedDifficultySum: input.UnratedDifficultySum,
+		}
+	}
+	paid := make(map[string]*big.Int)
+	for _, output := range r.Outputs {
+		amount, ok := new(big.Int).SetString(output.AmountRaw, 10)
+		if !ok {
+			return fmt.Errorf("payment to %s has an invalid amount %q", output.Destination, output.AmountRaw)
+		}
+		if paid[output.Provider] == nil {
+			paid[output.Provider] = new(big.Int)
+		}
+		paid[output.Provider].Add(paid[output.Provider], amount)
+	}
+	amounts, err := repository.PayoutAmounts(results, r.PrizePool)
+	if err != nil {
+		return err
+	}
+	for i, expected := range amounts {
+		provider := r.Inputs[i].BanAddress
+		actual := paid[provider]
+		if actual == nil {
+			actual = new(big.Int)
+		}
+		if actual.Cmp(expected) != 0 {
+			return fmt.Errorf("%s was paid %s raw instead of %s", provider, actual, expected)
+		}
+		delete(paid, provider)
+	}
+	// Whoever is left wasn't in the inputs
+	for provider := range paid {
+		return fmt.Errorf("%q was paid without any work", provider)
+	}
+	return nil
+}
+
+// Nil if the tenant has no such cycle
+func LoadReport(repo repository.PayoutCycleRepo, tenantID string, cycleID uuid.UUID) (*Report, error) {
+	cycle, err := repo.GetPayoutCycle(tenantID, cycleID)
+	if err != nil || cycle == nil {
+		return nil, err
+	}
+	payments, err := repo.GetPayoutCyclePayments(cycle.ID)
+	if err != nil {
+		return nil, err
+	}
+	report := BuildReport(cycle, payments)
+	return &report, nil
+}
+
+type SignedReport struct {
+	// The exact bytes that are hashed and signed
+	Report json.RawMessage `json:"report"`
+	// Hex sha256 of report
+	SHA256 string `json:"sha256"`
+	// Hex ed25519 signature of the sha256, empty if the server has no signing key
+	Signature string `json:"signature,omitempty"`
+	PublicKey string `json:"public_key,omitempty"`
+}
+
+// A nil key leaves the report unsigned
+func SignReport(report Report, key ed25519.PrivateKey) (*SignedReport, error) {
+	b, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(b)
+	signed := &SignedReport{Report: b, SHA256: hex.EncodeToString(digest[:])}
+	if key != nil {
+		signed.Signature = hex.EncodeToString(ed25519.Sign(key, digest[:]))
+		signed.PublicKey = hex.EncodeToString(key.Public().(ed25519.PublicKey))
+	}
+	return signed, nil
+}
+
+// The key in BPOW_PAYOUT_REPORT_KEY (or BPOW_PAYOUT_REPORT_KEY_FILE) as a hex 32 byte seed, nil if it isn't set
+func SigningKey() (ed25519.PrivateKey, error) {
+	raw, err := utils.GetSecret("BPOW_PAYOUT_REPORT_KEY")
+	if err != nil || raw == "" {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(raw)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("BPOW_PAYOUT_REPORT_KEY must be %d hex characters", ed25519.SeedSize*2)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// Serves the signed report of the cycle in the URL as a file, anyone can download it
+func ReportHandler(repo repository.PayoutCycleRepo, key ed25519.PrivateKey) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cycleID, err := uuid.Parse(chi.URLParam(r, "cycleID"))
+		if err != nil {
+			http.Error(w, "invalid payout cycle", http.StatusBadRequest)
+			return
+		}
+		report, err := LoadReport(repo, middleware.RequestTenant(r.Context()), cycleID)
+		if err != nil {
+			klog.Errorf("Error loading payout report %s %v", cycleID, err)
+			http.Error(w, "error loading payout report", http.StatusInternalServerError)
+			return
+		}
+		if report == nil {
+			http.Error(w, "no such payout cycle", http.StatusNotFound)
+			return
+		}
+		signed, err := SignReport(*report, key)
+		if err != nil {
+			http.Error(w, "error signing payout report", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="payout-%s.json"`, cycleID))
+		json.NewEncoder(w).Encode(signed)
+	}
+}
@@ -0,0 +1,108 @@
+package payouts
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+func testCycle() (*models.PayoutCycle, []models.Payment) {
+	alice := uuid.New()
+	bob := uuid.New()
+	cycle := &models.PayoutCycle{
+		Base:      models.Base{ID: uuid.New(), CreatedAt: time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC)},
+		TenantID:  "default",
+		PrizePool: 10,
+		Inputs: models.PayoutCycleInputs{
+			{ProviderID: bob.String(), BanAddress: "ban_bob", UnpaidCount: 3, DifficultySum: 3, UnratedDifficultySum: 1},
+			{ProviderID: alice.String(), BanAddress: "ban_alice", UnpaidCount: 5, DifficultySum: 5, RatedAwardRaw: "100", UnratedDifficultySum: 1},
+		},
+	}
+	blockHash := "ABCD"
+	payments := []models.Payment{
+		{PaidTo: bob, SendJson: serializableModels.SendRequest{Destination: "ban_bob", AmountRaw: "500000000000000000000000000000"}, BlockHash: &blockHash},
+		// Alice splits her payout between two addresses
+		{PaidTo: alice, SendJson: serializableModels.SendRequest{Destination: "ban_alice_savings", AmountRaw: "100000000000000000000000000000"}},
+		{PaidTo: alice, SendJson: serializableModels.SendRequest{Destination: "ban_alice", AmountRaw: "400000000000000000000000000100"}},
+	}
+	return cycle, payments
+}
+
+func TestBuildReport(t *testing.T) {
+	cycle, payments := testCycle()
+	report := BuildReport(cycle, payments)
+	utils.AssertEqual(t, ReportVersion, report.Version)
+	utils.AssertEqual(t, cycle.ID.String(), report.CycleID)
+	utils.AssertEqual(t, "2022-10-01T08:00:00Z", report.CreatedAt)
+	// Inputs keep their order, outputs are sorted
+	utils.AssertEqual(t, "ban_bob", report.Inputs[0].BanAddress)
+	utils.AssertEqual(t, 3, len(report.Outputs))
+	utils.AssertEqual(t, "ban_alice", report.Outputs[0].Provider)
+	utils.AssertEqual(t, "ban_alice", report.Outputs[0].Destination)
+	utils.AssertEqual(t, "ban_alice_savings", report.Outputs[1].Destination)
+	utils.AssertEqual(t, "ban_bob", report.Outputs[2].Provider)
+	utils.AssertEqual(t, false, report.Complete())
+	utils.AssertEqual(t, nil, report.Verify())
+
+	hash := "EFGH"
+	for i := range payments {
+		payments[i].BlockHash = &hash
+	}
+	utils.AssertEqual(t, true, BuildReport(cycle, payments).Complete())
+}
+
+func TestVerifyReport(t *testing.T) {
+	cycle, payments := testCycle()
+	payments[0].SendJson.AmountRaw = "500000000000000000000000000001"
+	utils.AssertEqual(t, "ban_bob was paid 500000000000000000000000000001 raw instead of 500000000000000000000000000000", BuildReport(cycle, payments).Verify().Error())
+
+	cycle, payments = testCycle()
+	payments = append(payments, models.Payment{PaidTo: uuid.New(), SendJson: serializableModels.SendRequest{Destination: "ban_mallory", AmountRaw: "1"}})
+	utils.AssertNotEqual(t, nil, BuildReport(cycle, payments).Verify())
+
+	cycle, payments = testCycle()
+	utils.AssertNotEqual(t, nil, BuildReport(cycle, payments[:2]).Verify())
+}
+
+func TestSignReport(t *testing.T) {
+	cycle, payments := testCycle()
+	report := BuildReport(cycle, payments)
+
+	unsigned, err := SignReport(report, nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "", unsigned.Signature)
+	digest := sha256.Sum256(unsigned.Report)
+	utils.AssertEqual(t, hex.EncodeToString(digest[:]), unsigned.SHA256)
+
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	signed, err := SignReport(report, key)
+	utils.AssertEqual(t, nil, err)
+	// Signing is reproducible
+	utils.AssertEqual(t, unsigned.SHA256, signed.SHA256)
+	publicKey, _ := hex.DecodeString(signed.PublicKey)
+	signature, _ := hex.DecodeString(signed.Signature)
+	utils.AssertEqual(t, true, ed25519.Verify(publicKey, digest[:], signature))
+}
+
+func TestSigningKey(t *testing.T) {
+	t.Setenv("BPOW_PAYOUT_REPORT_KEY", "")
+	key, err := SigningKey()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(key))
+
+	t.Setenv("BPOW_PAYOUT_REPORT_KEY", "abcd")
+	_, err = SigningKey()
+	utils.AssertNotEqual(t, nil, err)
+
+	t.Setenv("BPOW_PAYOUT_REPORT_KEY", hex.EncodeToString(make([]byte, ed25519.SeedSize)))
+	key, err = SigningKey()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, ed25519.PrivateKeySize, len(key))
+}
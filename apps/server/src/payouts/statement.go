@@ -0,0 +1,173 @@
+package payouts
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/email"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	"github.com/bananocoin/boompow/libs/utils/number"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
+)
+
+// What a provider solved and was paid in a closed cycle
+type Statement struct {
+	ProviderID    uuid.UUID
+	UnpaidCount   int
+	DifficultySum int
+	// 1 for the provider with the most difficulty, providers with the same difficulty share a rank
+	Rank      int
+	Providers int
+	PaidRaw   *big.Int
+	// Of the provider's payments, in the order they were made
+	BlockHashes []string
+}
+
+// A cycle is closed once it has payments and all of them were broadcast
+func Closed(payments []models.Payment) bool {
+	for _, payment := range payments {
+		if payment.BlockHash == nil {
+			return false
+		}
+	}
+	return len(payments) > 0
+}
+
+// Statements of every provider in the cycle's inputs, in that order
+func BuildStatements(cycle *models.PayoutCycle, payments []models.Payment) []Statement {
+	statements := make([]Statement, 0, len(cycle.Inputs))
+	for _, input := range cycle.Inputs {
+		providerID, err := uuid.Parse(input.ProviderID)
+		if err != nil {
+			klog.Errorf("Payout cycle %s has an invalid provider %q", cycle.ID, input.ProviderID)
+			continue
+		}
+		statement := Statement{
+			ProviderID:    providerID,
+			UnpaidCount:   input.UnpaidCount,
+			DifficultySum: input.DifficultySum,
+			Rank:          1,
+			Providers:     len(cycle.Inputs),
+			PaidRaw:       new(big.Int),
+			BlockHashes:   []string{},
+		}
+		for _, other := range cycle.Inputs {
+			if other.DifficultySum > input.DifficultySum {
+				statement.Rank++
+			}
+		}
+		for _, payment := range payments {
+			if payment.PaidTo != providerID {
+				continue
+			}
+			if amount, ok := new(big.Int).SetString(payment.SendJson.AmountRaw, 10); ok {
+				statement.PaidRaw.Add(statement.PaidRaw, amount)
+			}
+			if payment.BlockHash != nil {
+				statement.BlockHashes = append(statement.BlockHashes, *payment.BlockHash)
+			}
+		}
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+func statementEmailData(cycle *models.PayoutCycle, statement Statement, nextPayouts []time.Time, unsubscribeToken string) email.PayoutStatementEmailData {
+	data := email.PayoutStatementEmailData{
+		CycleDate:        cycle.CreatedAt.UTC().Format("2006-01-02"),
+		UnitsSolved:      statement.UnpaidCount,
+		Rank:             statement.Rank,
+		Providers:        statement.Providers,
+		Paid:             number.FormatRaw(statement.PaidRaw, 2),
+		TransactionLinks: make([]string, len(statement.BlockHashes)),
+		NextPayouts:      make([]string, len(nextPayouts)),
+		UnsubscribeLink:  email.UnsubscribeLink(unsubscribeToken),
+	}
+	for i, hash := range statement.BlockHashes {
+		data.TransactionLinks[i] = email.TransactionLink(hash)
+	}
+	for i, next := range nextPayouts {
+		data.NextPayouts[i] = next.UTC().Format("2006-01-02 15:04 UTC")
+	}
+	return data
+}
+
+// Emails providers the statements of cycles that closed recently, payout hour is in UTC
+// A statement is claimed before it's sent so servers don't send it twice, ones that failed to send are retried on the next run
+func SendStatements(repo repository.PayoutCycleRepo, userRepo repository.UserRepo, now time.Time, hour int) error {
+	cycles, err := repo.GetClosedPayoutCycles(now.Add(-config.PAYOUT_STATEMENT_MAX_AGE_HOURS * time.Hour))
+	if err != nil {
+		return err
+	}
+	nextPayouts := make([]time.Time, config.PAYOUT_STATEMENT_NEXT_CYCLES)
+	for i := range nextPayouts {
+		nextPayouts[i] = NextPayout(now, hour).AddDate(0, 0, i)
+	}
+	for i := range cycles {
+		cycle := &cycles[i]
+		payments, err := repo.GetPayoutCyclePayments(cycle.ID)
+		if err != nil {
+			return err
+		}
+		if !Closed(payments) {
+			continue
+		}
+		for _, statement := range BuildStatements(cycle, payments) {
+			user, err := userRepo.GetUser(&statement.ProviderID, nil)
+			if err != nil {
+				klog.Errorf("Error getting user for payout statement of cycle %s %v", cycle.ID, err)
+				continue
+			}
+			unsubscribed := !user.PayoutStatementEmails || user.DisabledAt != nil
+			claimed, err := repo.ClaimPayoutStatement(cycle.ID, user.ID, unsubscribed)
+			if err != nil {
+				return err
+			}
+			if !claimed || unsubscribed {
+				continue
+			}
+			token, err := auth.GenerateScopedToken(strings.ToLower(user.Email), auth.UnsubscribePurpose, config.UNSUBSCRIBE_TOKEN_VALID_DAYS*24*time.Hour, func() time.Time { return now })
+			if err == nil {
+				err = email.SendPayoutStatementEmail(user.Email, statementEmailData(cycle, statement, nextPayouts, token))
+			}
+			if err != nil {
+				klog.Errorf("Error sending payout statement of cycle %s to %s %v", cycle.ID, user.ID, err)
+				if err := repo.ReleasePayoutStatement(cycle.ID, user.ID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Turns off the payout statements of whoever the unsubscribe token in the URL was generated for
+func UnsubscribeHandler(userRepo repository.UserRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		address, err := auth.ParseScopedToken(chi.URLParam(r, "token"), auth.UnsubscribePurpose, time.Now)
+		if err != nil {
+			http.Error(w, "invalid or expired unsubscribe link", http.StatusBadRequest)
+			return
+		}
+		user, err := userRepo.GetUser(nil, &address)
+		if err != nil {
+			http.Error(w, "invalid or expired unsubscribe link", http.StatusBadRequest)
+			return
+		}
+		if err := userRepo.SetPayoutStatementEmails(user.ID, false); err != nil {
+			klog.Errorf("Error unsubscribing %s from payout statements %v", user.ID, err)
+			http.Error(w, "error unsubscribing", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "You won't get payout statement emails anymore.")
+	}
+}
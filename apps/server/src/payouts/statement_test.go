@@ -0,0 +1,53 @@
+package payouts
+
+import (
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestBuildStatements(t *testing.T) {
+	cycle, payments := testCycle()
+	utils.AssertEqual(t, false, Closed(payments))
+	utils.AssertEqual(t, false, Closed(nil))
+
+	statements := BuildStatements(cycle, payments)
+	utils.AssertEqual(t, 2, len(statements))
+	bob, alice := statements[0], statements[1]
+	utils.AssertEqual(t, cycle.Inputs[0].ProviderID, bob.ProviderID.String())
+	utils.AssertEqual(t, 3, bob.UnpaidCount)
+	utils.AssertEqual(t, 2, bob.Rank)
+	utils.AssertEqual(t, 2, bob.Providers)
+	utils.AssertEqual(t, "500000000000000000000000000000", bob.PaidRaw.String())
+	utils.AssertEqual(t, []string{"ABCD"}, bob.BlockHashes)
+	// Both of her payments add up
+	utils.AssertEqual(t, 1, alice.Rank)
+	utils.AssertEqual(t, "500000000000000000000000000100", alice.PaidRaw.String())
+	utils.AssertEqual(t, []string{}, alice.BlockHashes)
+
+	// Same difficulty, same rank
+	cycle.Inputs[0].DifficultySum = 5
+	statements = BuildStatements(cycle, payments)
+	utils.AssertEqual(t, 1, statements[0].Rank)
+	utils.AssertEqual(t, 1, statements[1].Rank)
+}
+
+func TestStatementEmailData(t *testing.T) {
+	cycle, payments := testCycle()
+	hash := "EFGH"
+	for i := range payments {
+		payments[i].BlockHash = &hash
+	}
+	utils.AssertEqual(t, true, Closed(payments))
+
+	now := time.Date(2022, 10, 1, 9, 0, 0, 0, time.UTC)
+	next := []time.Time{NextPayout(now, 8), NextPayout(now, 8).AddDate(0, 0, 1)}
+	data := statementEmailData(cycle, BuildStatements(cycle, payments)[1], next, "token")
+	utils.AssertEqual(t, "2022-10-01", data.CycleDate)
+	utils.AssertEqual(t, 5, data.UnitsSolved)
+	utils.AssertEqual(t, "5.00", data.Paid)
+	utils.AssertEqual(t, []string{"https://creeper.banano.cc/hash/EFGH", "https://creeper.banano.cc/hash/EFGH"}, data.TransactionLinks)
+	utils.AssertEqual(t, []string{"2022-10-02 08:00 UTC", "2022-10-03 08:00 UTC"}, data.NextPayouts)
+	utils.AssertEqual(t, "https://boompow.banano.cc/email/unsubscribe/token", data.UnsubscribeLink)
+}
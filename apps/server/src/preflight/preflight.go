@@ -0,0 +1,278 @@
+// Package preflight checks the configuration and everything the server depends on before it serves, so a bad deployment fails fast with a message saying what to fix
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/crosscheck"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/email"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/apps/server/src/payouts"
+	emaillib "github.com/bananocoin/boompow/libs/email"
+	"github.com/bananocoin/boompow/libs/utils"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	"github.com/bananocoin/boompow/libs/utils/validation"
+	"github.com/gorilla/websocket"
+	"golang.org/x/exp/slices"
+)
+
+// Returns nil when everything is fine, errors say what to do about the problem
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// A problem that doesn't stop the server from working, e.g. an optional dependency that isn't configured
+type Warning string
+
+func (w Warning) Error() string {
+	return string(w)
+}
+
+type Result struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+func (r Result) Failed() bool {
+	var warning Warning
+	return r.Err != nil && !errors.As(r.Err, &warning)
+}
+
+// Runs every check even after one failed, so all problems are reported at once
+func Run(ctx context.Context, checks []Check, timeout time.Duration) []Result {
+	results := make([]Result, len(checks))
+	for i, check := range checks {
+		start := time.Now()
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		results[i] = Result{Name: check.Name, Err: run(checkCtx, check), Duration: time.Since(start)}
+		cancel()
+	}
+	return results
+}
+
+func run(ctx context.Context, check Check) (err error) {
+	// Some dependencies panic on bad configuration
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return check.Run(ctx)
+}
+
+func Failed(results []Result) bool {
+	for _, result := range results {
+		if result.Failed() {
+			return true
+		}
+	}
+	return false
+}
+
+func Report(w io.Writer, results []Result) {
+	for _, result := range results {
+		switch {
+		case result.Err == nil:
+			fmt.Fprintf(w, "✅ %s (%dms)\n", result.Name, result.Duration.Milliseconds())
+		case result.Failed():
+			fmt.Fprintf(w, "❌ %s: %s\n", result.Name, indent(result.Err.Error()))
+		default:
+			fmt.Fprintf(w, "⚠️ %s: %s\n", result.Name, indent(result.Err.Error()))
+		}
+	}
+}
+
+func indent(msg string) string {
+	return strings.ReplaceAll(msg, "\n", "\n   ")
+}
+
+// The checks of a server, a test email is only sent if emailTo is set
+func Checks(dbConfig *database.Config, emailTo string) []Check {
+	return []Check{
+		{Name: "configuration", Run: func(ctx context.Context) error { return checkConfig() }},
+		{Name: "jwt", Run: func(ctx context.Context) error { return checkJWT() }},
+		{Name: "postgres", Run: func(ctx context.Context) error { return checkPostgres(ctx, dbConfig) }},
+		{Name: "redis", Run: func(ctx context.Context) error { return database.GetRedisDB().Ping() }},
+		{Name: "email", Run: func(ctx context.Context) error { return checkEmail(emailTo) }},
+		{Name: "nano node", Run: func(ctx context.Context) error { return checkNode(ctx, "NANO_WS_URL") }},
+		{Name: "banano node", Run: func(ctx context.Context) error { return checkNode(ctx, "BANANO_WS_URL") }},
+	}
+}
+
+// The getters fall back to defaults on invalid values, so the raw values are checked here
+func checkConfig() error {
+	var problems []string
+	integer := func(key string, min int, max int) {
+		raw := utils.GetEnv(key, "")
+		if raw == "" {
+			return
+		}
+		if n, err := strconv.Atoi(raw); err != nil || n < min || n > max {
+			problems = append(problems, fmt.Sprintf("%s must be a number from %d to %d, not %q", key, min, max, raw))
+		}
+	}
+	duration := func(key string) {
+		raw := utils.GetEnv(key, "")
+		if raw == "" {
+			return
+		}
+		if d, err := time.ParseDuration(raw); err != nil || d < 0 {
+			problems = append(problems, fmt.Sprintf("%s must be a duration like 30s, not %q", key, raw))
+		}
+	}
+	oneOf := func(key string, values ...string) {
+		raw := utils.GetEnv(key, "")
+		if raw == "" {
+			return
+		}
+		for _, value := range values {
+			if strings.EqualFold(raw, value) {
+				return
+			}
+		}
+		problems = append(problems, fmt.Sprintf("%s must be one of %s, not %q", key, strings.Join(values, ", "), raw))
+	}
+
+	for _, key := range []string{"DB_HOST", "DB_PORT", "DB_USER", "DB_NAME"} {
+		if utils.GetEnv(key, "") == "" {
+			problems = append(problems, fmt.Sprintf("%s is not set", key))
+		}
+	}
+	integer("REDIS_PORT", 1, 65535)
+	integer("REDIS_DB", 0, 15)
+	integer("PORT", 1, 65535)
+	integer("BPOW_PRIZE_POOL", 0, 1<<31-1)
+	integer("BPOW_PAYOUT_HOUR_UTC", 0, 23)
+	integer("BPOW_RATE_LIMIT_QUEUE_SIZE", 0, 1<<31-1)
+	integer("BPOW_RATE_LIMIT_ANONYMOUS", 0, 1<<31-1)
+	integer("BPOW_RATE_LIMIT_USER", 0, 1<<31-1)
+	integer("BPOW_RATE_LIMIT_SERVICE", 0, 1<<31-1)
+	integer("BPOW_NETWORK_DIFFICULTY_MULTIPLIER", 1, 1<<31-1)
+	integer("BPOW_VALIDATION_WORKERS", 1, 256)
+	integer("BPOW_BOOST_DAILY_CAP_MINUTES", 1, 1440)
+	integer("BPOW_STALE_ACCOUNT_REMIND_DAYS", 0, 3650)
+	integer("BPOW_STALE_ACCOUNT_DISABLE_DAYS", 1, 3650)
+	integer("BPOW_STALE_ACCOUNT_ANONYMIZE_DAYS", 1, 3650)
+	duration("BPOW_RATE_LIMIT_MAX_WAIT")
+	duration("BPOW_STARTUP_TIMEOUT")
+	oneOf("BPOW_RATE_LIMIT_MODE", "reject", "queue")
+	oneOf("BPOW_STATS_STORE", "postgres", "clickhouse")
+	oneOf("BPOW_HUB_MODE", "local", "distributed")
+	oneOf("BPOW_EMAIL_ALIASES", validation.EmailAliasModes...)
+	if raw := utils.GetEnv("BPOW_POW_CHALLENGE_DIFFICULTY", ""); raw != "" {
+		if _, err := strconv.ParseUint(raw, 16, 64); err != nil {
+			problems = append(problems, fmt.Sprintf("BPOW_POW_CHALLENGE_DIFFICULTY must be a hex work value like fffffff800000000, not %q", raw))
+		}
+	}
+	if _, err := payouts.SigningKey(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := utils.GetTwoFactorKey(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := middleware.NewOriginValidator(utils.GetAllowedOrigins()); err != nil {
+		problems = append(problems, fmt.Sprintf("BPOW_ALLOWED_ORIGINS is invalid: %v", err))
+	}
+	if _, err := crosscheck.ParsePeers(utils.GetValidationPeers(), nil); err != nil {
+		problems = append(problems, fmt.Sprintf("BPOW_VALIDATION_PEERS is invalid: %v", err))
+	}
+	if raw := utils.GetEnv("BPOW_VALIDATION_SAMPLE_PERCENT", ""); raw != "" {
+		if percent, err := strconv.ParseFloat(raw, 64); err != nil || percent < 0 || percent > 100 {
+			problems = append(problems, fmt.Sprintf("BPOW_VALIDATION_SAMPLE_PERCENT must be a percentage from 0 to 100, not %q", raw))
+		}
+	}
+	if raw := utils.GetEnv("BPOW_BOOST_PRICE_BANANO_PER_MINUTE", ""); raw != "" {
+		if price, err := strconv.ParseFloat(raw, 64); err != nil || price < 0 {
+			problems = append(problems, fmt.Sprintf("BPOW_BOOST_PRICE_BANANO_PER_MINUTE must be a price in BAN of at least 0, not %q", raw))
+		}
+	}
+	if provider := utils.GetEmailProvider(); !slices.Contains(emaillib.Providers, provider) {
+		problems = append(problems, fmt.Sprintf("BPOW_EMAIL_PROVIDER must be one of %s, not %q", strings.Join(emaillib.Providers, ", "), provider))
+	}
+	smtpKeys := []string{"SMTP_SERVER", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD"}
+	for _, key := range smtpKeys {
+		if utils.GetEmailProvider() == emaillib.ProviderSMTP && utils.GetEnv(key, "") != "" && utils.GetSmtpConnInformation() == nil {
+			problems = append(problems, fmt.Sprintf("email is partly configured, set all of %s", strings.Join(smtpKeys, ", ")))
+			break
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// Anyone can sign tokens with the default key, so it's only allowed in development
+func checkJWT() error {
+	key := utils.GetEnv("PRIV_KEY", "")
+	development := utils.GetEnv("ENVIRONMENT", "development") == "development"
+	if key == "" || key == "badKey" {
+		if development {
+			return Warning("PRIV_KEY is not set, tokens are signed with the insecure default key")
+		}
+		return errors.New("PRIV_KEY is not set, set it to a random secret of at least 32 characters")
+	}
+	if len(key) < 32 && !development {
+		return fmt.Errorf("PRIV_KEY is only %d characters long, use a random secret of at least 32 characters", len(key))
+	}
+	token, err := auth.GenerateToken("preflight@boompow.local", time.Now)
+	if err != nil {
+		return fmt.Errorf("tokens can't be signed with PRIV_KEY %v", err)
+	}
+	if _, err := auth.ParseToken(token, time.Now); err != nil {
+		return fmt.Errorf("signed tokens don't verify with PRIV_KEY %v", err)
+	}
+	return nil
+}
+
+func checkPostgres(ctx context.Context, dbConfig *database.Config) error {
+	db, err := database.NewConnection(dbConfig)
+	if err != nil {
+		return fmt.Errorf("can't connect with DB_HOST, DB_PORT, DB_USER, DB_PASS and DB_NAME %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("connected but postgres doesn't answer %v", err)
+	}
+	return nil
+}
+
+func checkEmail(to string) error {
+	provider := utils.GetEmailProvider()
+	if _, err := email.NewSender(); err != nil {
+		return Warning(fmt.Sprintf("%s isn't configured, no emails are sent: %v", provider, err))
+	}
+	if to == "" {
+		return Warning(fmt.Sprintf("%s is configured, set BPOW_PREFLIGHT_EMAIL to send a test email", provider))
+	}
+	if err := email.SendTestEmail(to); err != nil {
+		return fmt.Errorf("sending a test email to %s through %s failed, check its settings %v", to, provider, err)
+	}
+	return nil
+}
+
+// Blocks are precached from the node's websocket, without it nothing is precached
+func checkNode(ctx context.Context, key string) error {
+	url := utils.GetEnv(key, "")
+	if url == "" {
+		return Warning(fmt.Sprintf("%s is not set, blocks aren't precached", key))
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return fmt.Errorf("can't reach %s at %s %v", key, url, err)
+	}
+	return conn.Close()
+}
@@ -0,0 +1,102 @@
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestRun(t *testing.T) {
+	results := Run(context.Background(), []Check{
+		{Name: "fine", Run: func(ctx context.Context) error { return nil }},
+		{Name: "optional", Run: func(ctx context.Context) error { return Warning("not configured") }},
+		{Name: "panics", Run: func(ctx context.Context) error { panic("Invalid REDIS_PORT specified") }},
+		{Name: "broken", Run: func(ctx context.Context) error { return errors.New("set it\nand this") }},
+	}, time.Second)
+	utils.AssertEqual(t, 4, len(results))
+	utils.AssertEqual(t, false, results[1].Failed())
+	utils.AssertEqual(t, "Invalid REDIS_PORT specified", results[2].Err.Error())
+	utils.AssertEqual(t, true, Failed(results))
+	utils.AssertEqual(t, false, Failed(results[:2]))
+
+	var out bytes.Buffer
+	Report(&out, results)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	utils.AssertEqual(t, 5, len(lines))
+	utils.AssertEqual(t, true, strings.HasPrefix(lines[0], "✅ fine"))
+	utils.AssertEqual(t, "⚠️ optional: not configured", lines[1])
+	utils.AssertEqual(t, "❌ broken: set it", lines[3])
+	utils.AssertEqual(t, "   and this", lines[4])
+}
+
+func TestCheckConfig(t *testing.T) {
+	for key, value := range map[string]string{"DB_HOST": "localhost", "DB_PORT": "5432", "DB_USER": "postgres", "DB_NAME": "boompow"} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	utils.AssertEqual(t, nil, checkConfig())
+
+	os.Setenv("BPOW_PAYOUT_HOUR_UTC", "24")
+	os.Setenv("BPOW_RATE_LIMIT_MODE", "drop")
+	os.Setenv("SMTP_SERVER", "smtp.example.com")
+	defer os.Unsetenv("BPOW_PAYOUT_HOUR_UTC")
+	defer os.Unsetenv("BPOW_RATE_LIMIT_MODE")
+	defer os.Unsetenv("SMTP_SERVER")
+	err := checkConfig()
+	utils.AssertNotEqual(t, nil, err)
+	utils.AssertEqual(t, []string{
+		`BPOW_PAYOUT_HOUR_UTC must be a number from 0 to 23, not "24"`,
+		`BPOW_RATE_LIMIT_MODE must be one of reject, queue, not "drop"`,
+		"email is partly configured, set all of SMTP_SERVER, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD",
+	}, strings.Split(err.Error(), "\n"))
+
+	os.Setenv("BPOW_EMAIL_PROVIDER", "pigeon")
+	defer os.Unsetenv("BPOW_EMAIL_PROVIDER")
+	utils.AssertEqual(t, true, strings.Contains(checkConfig().Error(), `BPOW_EMAIL_PROVIDER must be one of smtp, sendgrid, mailgun, dryrun, not "pigeon"`))
+
+	os.Setenv("BPOW_TWO_FACTOR_KEY", "secret")
+	defer os.Unsetenv("BPOW_TWO_FACTOR_KEY")
+	utils.AssertEqual(t, true, strings.Contains(checkConfig().Error(), "BPOW_TWO_FACTOR_KEY must be 64 hex characters"))
+
+	os.Setenv("BPOW_ALLOWED_ORIGINS", "banano.cc")
+	defer os.Unsetenv("BPOW_ALLOWED_ORIGINS")
+	utils.AssertEqual(t, true, strings.Contains(checkConfig().Error(), "BPOW_ALLOWED_ORIGINS is invalid"))
+}
+
+func TestCheckJWT(t *testing.T) {
+	// The default key is only fine in development
+	var warning Warning
+	utils.AssertEqual(t, true, errors.As(checkJWT(), &warning))
+	os.Setenv("ENVIRONMENT", "production")
+	defer os.Unsetenv("ENVIRONMENT")
+	utils.AssertEqual(t, false, errors.As(checkJWT(), &warning))
+
+	os.Setenv("PRIV_KEY", "short")
+	defer os.Unsetenv("PRIV_KEY")
+	utils.AssertNotEqual(t, nil, checkJWT())
+}
+
+func TestOptionalDependencies(t *testing.T) {
+	var warning Warning
+	utils.AssertEqual(t, true, errors.As(checkEmail("sink@example.com"), &warning))
+	utils.AssertEqual(t, true, errors.As(checkNode(context.Background(), "NANO_WS_URL"), &warning))
+
+	// Dry run needs no credentials, the test email is only logged
+	os.Setenv("BPOW_EMAIL_PROVIDER", "dryrun")
+	utils.AssertEqual(t, nil, checkEmail("sink@example.com"))
+	os.Setenv("BPOW_EMAIL_PROVIDER", "sendgrid")
+	utils.AssertEqual(t, true, errors.As(checkEmail("sink@example.com"), &warning))
+	os.Unsetenv("BPOW_EMAIL_PROVIDER")
+
+	os.Setenv("NANO_WS_URL", "ws://127.0.0.1:1")
+	defer os.Unsetenv("NANO_WS_URL")
+	err := checkNode(context.Background(), "NANO_WS_URL")
+	utils.AssertNotEqual(t, nil, err)
+	utils.AssertEqual(t, false, errors.As(err, &warning))
+}
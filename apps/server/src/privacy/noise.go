@@ -0,0 +1,56 @@
+// Package privacy blurs per-provider numbers before they're made public, so providers can't work out each other's exact capacity
+package privacy
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Adds Laplace noise with a scale of Percent of the value, then rounds to two significant digits
+type Blurrer struct {
+	Percent float64
+}
+
+func NewBlurrer(percent float64) Blurrer {
+	return Blurrer{Percent: percent}
+}
+
+func (b Blurrer) Enabled() bool {
+	return b.Percent > 0
+}
+
+// Blur returns value with noise that stays the same for key all day (UTC), so reading it repeatedly doesn't average the noise out
+// Values are never blurred below zero, and are returned as they are if blurring is disabled
+func (b Blurrer) Blur(key string, value float64, now time.Time) float64 {
+	if !b.Enabled() || value <= 0 {
+		return value
+	}
+	seed := fnv.New64a()
+	seed.Write([]byte(key))
+	seed.Write([]byte(now.UTC().Format("2006-01-02")))
+	rng := rand.New(rand.NewSource(int64(seed.Sum64())))
+
+	blurred := value + laplace(rng, value*b.Percent/100)
+	if blurred < 0 {
+		blurred = 0
+	}
+	return roundSignificant(blurred, 2)
+}
+
+func laplace(rng *rand.Rand, scale float64) float64 {
+	u := rng.Float64() - 0.5
+	if u < 0 {
+		return scale * math.Log(1+2*u)
+	}
+	return -scale * math.Log(1-2*u)
+}
+
+func roundSignificant(value float64, digits int) float64 {
+	if value == 0 {
+		return 0
+	}
+	step := math.Pow(10, math.Floor(math.Log10(value))-float64(digits-1))
+	return math.Round(value/step) * step
+}
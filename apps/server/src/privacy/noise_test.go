@@ -0,0 +1,36 @@
+package privacy
+
+import (
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestBlur(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	utils.AssertEqual(t, 1234.56, NewBlurrer(0).Blur("ban_1", 1234.56, now))
+
+	blurrer := NewBlurrer(5)
+	blurred := blurrer.Blur("ban_1", 1234.56, now)
+	utils.AssertNotEqual(t, 1234.56, blurred)
+	// Two significant digits
+	utils.AssertEqual(t, 0.0, float64(int64(blurred)%10))
+	// The same all day, but not for every provider or every day
+	utils.AssertEqual(t, blurred, blurrer.Blur("ban_1", 1234.56, now.Add(11*time.Hour)))
+	utils.AssertEqual(t, 0.0, blurrer.Blur("ban_1", 0, now))
+
+	different := false
+	for i := 1; i <= 10; i++ {
+		if blurrer.Blur("ban_1", 1234.56, now.AddDate(0, 0, i)) != blurred {
+			different = true
+		}
+	}
+	utils.AssertEqual(t, true, different)
+}
+
+func TestRoundSignificant(t *testing.T) {
+	utils.AssertEqual(t, 1200.0, roundSignificant(1234.56, 2))
+	utils.AssertEqual(t, 0.046, roundSignificant(0.04567, 2))
+	utils.AssertEqual(t, 99.0, roundSignificant(98.7, 2))
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/bananocoin/boompow/libs/utils/number"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Account events and payments received share one timeline
+const ActivityPayoutReceived = "payout_received"
+
+type ActivityItem struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Detail    string    `json:"detail"`
+	ClientIP  string    `json:"client_ip"`
+	BlockHash *string   `json:"block_hash"`
+	AmountRaw string    `json:"amount_raw"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Format the amount of a payout
+func (a ActivityItem) AmountBanano() string {
+	asBan, err := number.RawToBanano(a.AmountRaw, true)
+	if err != nil {
+		return "0"
+	}
+	return fmt.Sprintf("%.2f", asBan)
+}
+
+type ActivityRepo interface {
+	RecordAccountEvent(userID uuid.UUID, eventType models.AccountEventType, detail string, clientIP string) error
+	GetActivity(userID uuid.UUID, args pagination.Args) ([]ActivityItem, error)
+	CountActivity(userID uuid.UUID) (int, error)
+	HasLoggedInFrom(userID uuid.UUID, clientIP string) (bool, error)
+}
+
+type ActivityService struct {
+	Db *gorm.DB
+}
+
+var _ ActivityRepo = &ActivityService{}
+
+func NewActivityService(db *gorm.DB) *ActivityService {
+	return &ActivityService{
+		Db: db,
+	}
+}
+
+func (s *ActivityService) RecordAccountEvent(userID uuid.UUID, eventType models.AccountEventType, detail string, clientIP string) error {
+	return s.Db.Create(&models.AccountEvent{
+		UserID:   userID,
+		Type:     eventType,
+		Detail:   detail,
+		ClientIP: clientIP,
+	}).Error
+}
+
+// Whether an earlier login of the user came from the IP
+func (s *ActivityService) HasLoggedInFrom(userID uuid.UUID, clientIP string) (bool, error) {
+	var count int64
+	err := s.Db.Model(&models.AccountEvent{}).Where("user_id = ?", userID).Where("type = ?", models.AccountEventLogin).Where("client_ip = ?", clientIP).Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+func (item ActivityItem) Cursor() pagination.Cursor {
+	return pagination.Cursor{Time: item.CreatedAt, ID: item.ID}
+}
+
+// Account events and payouts merged into one page, newest first
+func (s *ActivityService) GetActivity(userID uuid.UUID, args pagination.Args) ([]ActivityItem, error) {
+	var events []models.AccountEvent
+	if err := s.Db.Where("user_id = ?", userID).Scopes(args.Scope("created_at", "id")).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	var payments []models.Payment
+	if err := s.Db.Where("paid_to = ?", userID).Scopes(args.Scope("created_at", "id")).Find(&payments).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]ActivityItem, 0, len(events)+len(payments))
+	for _, event := range events {
+		items = append(items, ActivityItem{
+			ID:        event.ID.String(),
+			Type:      string(event.Type),
+			Detail:    event.Detail,
+			ClientIP:  event.ClientIP,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	for _, payment := range payments {
+		items = append(items, ActivityItem{
+			ID:        payment.ID.String(),
+			Type:      ActivityPayoutReceived,
+			Detail:    payment.SendJson.Destination,
+			BlockHash: payment.BlockHash,
+			AmountRaw: payment.SendJson.AmountRaw,
+			CreatedAt: payment.CreatedAt,
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[j].Cursor().After(items[i].Cursor())
+	})
+	if len(items) > args.Limit() {
+		items = items[:args.Limit()]
+	}
+	return items, nil
+}
+
+func (s *ActivityService) CountActivity(userID uuid.UUID) (int, error) {
+	var events, payments int64
+	if err := s.Db.Model(&models.AccountEvent{}).Where("user_id = ?", userID).Count(&events).Error; err != nil {
+		return 0, err
+	}
+	if err := s.Db.Model(&models.Payment{}).Where("paid_to = ?", userID).Count(&payments).Error; err != nil {
+		return 0, err
+	}
+	return int(events + payments), nil
+}
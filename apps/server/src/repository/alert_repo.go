@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AlertRepo interface {
+	SetOfflineAlert(userID uuid.UUID, channel models.AlertChannel, target string, afterMinutes int) (*models.OfflineAlert, error)
+	DeleteOfflineAlert(userID uuid.UUID) error
+	GetOfflineAlert(userID uuid.UUID) (*models.OfflineAlert, error)
+	GetOfflineAlertForEmail(email string) (*models.OfflineAlert, error)
+}
+
+type AlertService struct {
+	Db *gorm.DB
+}
+
+var _ AlertRepo = &AlertService{}
+
+func NewAlertService(db *gorm.DB) *AlertService {
+	return &AlertService{
+		Db: db,
+	}
+}
+
+// Webhooks must be https, Discord webhooks must point at Discord
+func ValidateOfflineAlert(channel models.AlertChannel, target string, afterMinutes int) error {
+	if afterMinutes < config.OFFLINE_ALERT_MIN_MINUTES || afterMinutes > config.OFFLINE_ALERT_MAX_MINUTES {
+		return fmt.Errorf("Alerts can be sent after %d to %d minutes", config.OFFLINE_ALERT_MIN_MINUTES, config.OFFLINE_ALERT_MAX_MINUTES)
+	}
+	switch channel {
+	case models.AlertChannelEmail:
+		return nil
+	case models.AlertChannelWebhook, models.AlertChannelDiscord:
+		u, err := url.Parse(target)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return errors.New("Webhook must be an https URL")
+		}
+		if channel == models.AlertChannelDiscord && !((u.Host == "discord.com" || u.Host == "discordapp.com") && strings.HasPrefix(u.Path, "/api/webhooks/")) {
+			return errors.New("Not a Discord webhook URL")
+		}
+		return nil
+	}
+	return errors.New("Unknown alert channel")
+}
+
+// Replaces the user's previous alert
+func (s *AlertService) SetOfflineAlert(userID uuid.UUID, channel models.AlertChannel, target string, afterMinutes int) (*models.OfflineAlert, error) {
+	if err := ValidateOfflineAlert(channel, target, afterMinutes); err != nil {
+		return nil, err
+	}
+	if channel == models.AlertChannelEmail {
+		target = ""
+	}
+	alert := &models.OfflineAlert{
+		UserID:       userID,
+		Channel:      channel,
+		Target:       target,
+		AfterMinutes: afterMinutes,
+	}
+	err := s.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"channel", "target", "after_minutes", "updated_at"}),
+	}).Create(alert).Error
+	if err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+func (s *AlertService) DeleteOfflineAlert(userID uuid.UUID) error {
+	return s.Db.Where("user_id = ?", userID).Delete(&models.OfflineAlert{}).Error
+}
+
+// nil if the user didn't opt in
+func (s *AlertService) GetOfflineAlert(userID uuid.UUID) (*models.OfflineAlert, error) {
+	alert := &models.OfflineAlert{}
+	err := s.Db.Where("user_id = ?", userID).First(alert).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// Workers are identified by email in the hub, nil if the user didn't opt in
+func (s *AlertService) GetOfflineAlertForEmail(email string) (*models.OfflineAlert, error) {
+	alert := &models.OfflineAlert{}
+	err := s.Db.Joins("JOIN users ON users.id = offline_alerts.user_id").Where("users.email = ?", email).First(alert).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
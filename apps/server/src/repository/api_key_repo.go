@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrTooManyAPIKeys = errors.New("too many api keys")
+
+type APIKeyRepo interface {
+	CreateAPIKey(userID uuid.UUID, name string, rateLimitPerMinute *int) (*models.APIKey, string, error)
+	GetAPIKeys(userID uuid.UUID) ([]models.APIKey, error)
+	GetAPIKey(key string) (*models.APIKey, error)
+	RevokeAPIKey(userID uuid.UUID, id uuid.UUID) (bool, error)
+	TouchAPIKey(apiKey *models.APIKey, now time.Time) error
+}
+
+type APIKeyService struct {
+	Db *gorm.DB
+}
+
+var _ APIKeyRepo = &APIKeyService{}
+
+func NewAPIKeyService(db *gorm.DB) *APIKeyService {
+	return &APIKeyService{
+		Db: db,
+	}
+}
+
+// Returns the key along with it, it can't be retrieved again
+func (s *APIKeyService) CreateAPIKey(userID uuid.UUID, name string, rateLimitPerMinute *int) (*models.APIKey, string, error) {
+	key, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	apiKey := &models.APIKey{
+		UserID:             userID,
+		Name:               name,
+		KeyHash:            auth.HashAPIKey(key),
+		Hint:               auth.APIKeyHint(key),
+		RateLimitPerMinute: rateLimitPerMinute,
+	}
+	err = s.Db.Transaction(func(tx *gorm.DB) error {
+		// Concurrent requests can't both take the last slot
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", userID).First(&models.User{}).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.APIKey{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= config.MAX_API_KEYS_PER_USER {
+			return ErrTooManyAPIKeys
+		}
+		return tx.Create(apiKey).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return apiKey, key, nil
+}
+
+func (s *APIKeyService) GetAPIKeys(userID uuid.UUID) ([]models.APIKey, error) {
+	var apiKeys []models.APIKey
+	err := s.Db.Where("user_id = ?", userID).Order("created_at asc").Find(&apiKeys).Error
+	return apiKeys, err
+}
+
+// Nil if there's no such key
+func (s *APIKeyService) GetAPIKey(key string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := s.Db.Where("key_hash = ?", auth.HashAPIKey(key)).First(&apiKey).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// Only the user's own keys can be revoked, false if they have no such key
+func (s *APIKeyService) RevokeAPIKey(userID uuid.UUID, id uuid.UUID) (bool, error) {
+	res := s.Db.Where("user_id = ?", userID).Where("id = ?", id).Delete(&models.APIKey{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// Records that the key was used, at most once per API_KEY_LAST_USED_RESOLUTION_SECONDS
+func (s *APIKeyService) TouchAPIKey(apiKey *models.APIKey, now time.Time) error {
+	resolution := config.API_KEY_LAST_USED_RESOLUTION_SECONDS * time.Second
+	if apiKey.LastUsedAt != nil && now.Sub(*apiKey.LastUsedAt) < resolution {
+		return nil
+	}
+	if err := s.Db.Model(&models.APIKey{}).Where("id = ?", apiKey.ID).UpdateColumn("last_used_at", now).Error; err != nil {
+		return err
+	}
+	apiKey.LastUsedAt = &now
+	return nil
+}
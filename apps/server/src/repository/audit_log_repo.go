@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"github.com/bananocoin/boompow/apps/server/src/filter"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepo interface {
+	RecordAuditEvent(entry *models.AuditLogEntry) error
+	ListAuditLog(query *filter.Query, args pagination.Args) ([]models.AuditLogEntry, error)
+	CountAuditLog(query *filter.Query) (int, error)
+}
+
+type AuditLogService struct {
+	Db *gorm.DB
+}
+
+var _ AuditLogRepo = &AuditLogService{}
+
+func NewAuditLogService(db *gorm.DB) *AuditLogService {
+	return &AuditLogService{
+		Db: db,
+	}
+}
+
+func (s *AuditLogService) RecordAuditEvent(entry *models.AuditLogEntry) error {
+	return s.Db.Create(entry).Error
+}
+
+// What admins can filter the audit log on, newest first
+var AuditLogList = filter.List{
+	Fields: map[string]filter.Field{
+		"action":      {Column: "action", Type: filter.Enum, Values: auditActionValues()},
+		"actor_id":    {Column: "actor_id", Type: filter.UUID, Nullable: true},
+		"actor_email": {Column: "actor_email", Type: filter.String},
+		"target":      {Column: "target", Type: filter.String},
+		"client_ip":   {Column: "client_ip", Type: filter.String},
+		"success":     {Column: "success", Type: filter.Bool},
+		"created_at":  {Column: "created_at", Type: filter.Time, Sortable: true},
+	},
+	IDColumn:    "id",
+	DefaultSort: filter.Sort{Field: "created_at", Descending: true},
+}
+
+func auditActionValues() []string {
+	values := make([]string, len(models.AuditActions))
+	for i, action := range models.AuditActions {
+		values[i] = string(action)
+	}
+	return values
+}
+
+func AuditLogCursor(entry models.AuditLogEntry) pagination.Cursor {
+	return pagination.Cursor{Time: entry.CreatedAt, ID: entry.ID.String()}
+}
+
+func (s *AuditLogService) ListAuditLog(query *filter.Query, args pagination.Args) ([]models.AuditLogEntry, error) {
+	entries := []models.AuditLogEntry{}
+	err := s.Db.Scopes(query.Scope(args)).Find(&entries).Error
+	return entries, err
+}
+
+func (s *AuditLogService) CountAuditLog(query *filter.Query) (int, error) {
+	var count int64
+	err := query.Where(s.Db.Model(&models.AuditLogEntry{})).Count(&count).Error
+	return int(count), err
+}
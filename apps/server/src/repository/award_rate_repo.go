@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/libs/utils/number"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AwardRateRepo interface {
+	ScheduleAwardRate(tenantID string, bananoPerUnit float64, effectiveAt time.Time, createdBy uuid.UUID) (*models.AwardRate, error)
+	GetAwardRateHistory(tenantID string) ([]models.AwardRate, error)
+	GetAwardRateAt(tenantID string, at time.Time) (*models.AwardRate, error)
+}
+
+type AwardRateService struct {
+	Db *gorm.DB
+}
+
+var _ AwardRateRepo = &AwardRateService{}
+
+func NewAwardRateService(db *gorm.DB) *AwardRateService {
+	return &AwardRateService{
+		Db: db,
+	}
+}
+
+// Rates can only be scheduled for the future, so work that was already done is never re-priced
+func (s *AwardRateService) ScheduleAwardRate(tenantID string, bananoPerUnit float64, effectiveAt time.Time, createdBy uuid.UUID) (*models.AwardRate, error) {
+	if bananoPerUnit < 0 {
+		return nil, errors.New("Award rate can't be negative")
+	}
+	if !effectiveAt.After(time.Now()) {
+		return nil, errors.New("Award rate changes must be effective in the future")
+	}
+	rawPerUnit, err := number.BananoToRawExact(bananoPerUnit)
+	if err != nil {
+		return nil, err
+	}
+	rate := &models.AwardRate{
+		TenantID:    tenantID,
+		RawPerUnit:  rawPerUnit.String(),
+		EffectiveAt: effectiveAt.UTC(),
+		CreatedBy:   createdBy,
+	}
+	if err := s.Db.Create(rate).Error; err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// Every rate of the tenant including scheduled ones, newest first
+func (s *AwardRateService) GetAwardRateHistory(tenantID string) ([]models.AwardRate, error) {
+	rates := []models.AwardRate{}
+	err := s.Db.Where("tenant_id = ?", tenantID).Order("effective_at desc").Find(&rates).Error
+	return rates, err
+}
+
+// The rate in effect at the given time, nil if no rate was ever set
+func (s *AwardRateService) GetAwardRateAt(tenantID string, at time.Time) (*models.AwardRate, error) {
+	rate := &models.AwardRate{}
+	err := s.Db.Where("tenant_id = ?", tenantID).Where("effective_at <= ?", at).Order("effective_at desc").First(rate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
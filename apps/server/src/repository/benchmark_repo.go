@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	BenchmarkBackendGPU = "GPU"
+	BenchmarkBackendCPU = "CPU"
+)
+
+// Longer names are most likely not hardware
+const maxHardwareLength = 100
+
+// More runs than a client would reasonably do, keeps a single profile from looking more reliable than it is
+const maxBenchmarkRuns = 1000
+
+type BenchmarkSubmission struct {
+	Hardware             string  `json:"hardware"`
+	Backend              string  `json:"backend"`
+	DifficultyMultiplier int     `json:"difficulty_multiplier"`
+	Runs                 int     `json:"runs"`
+	AverageSeconds       float64 `json:"average_seconds"`
+	ClientVersion        string  `json:"client_version"`
+}
+
+// Aggregated benchmarks of one kind of hardware
+// WorkPerSecond is normalized to 1x difficulty, divide it by the multiplier for other difficulties
+type HardwareBenchmark struct {
+	Hardware      string  `json:"hardware"`
+	Backend       string  `json:"backend"`
+	Profiles      int     `json:"profiles"`
+	Providers     int     `json:"providers"`
+	WorkPerSecond float64 `json:"work_per_second"`
+}
+
+type BenchmarkRepo interface {
+	SubmitBenchmark(userID uuid.UUID, submission BenchmarkSubmission) (*models.BenchmarkProfile, error)
+	GetHardwareLeaderboard(difficultyMultiplier *int) ([]HardwareBenchmark, error)
+}
+
+type BenchmarkService struct {
+	Db *gorm.DB
+}
+
+var _ BenchmarkRepo = &BenchmarkService{}
+
+func NewBenchmarkService(db *gorm.DB) *BenchmarkService {
+	return &BenchmarkService{
+		Db: db,
+	}
+}
+
+// Collapses whitespace so "RTX  3070 " and "RTX 3070" end up in the same entry
+func NormalizeHardware(hardware string) string {
+	return strings.Join(strings.Fields(hardware), " ")
+}
+
+func ValidateBenchmarkSubmission(submission BenchmarkSubmission) error {
+	hardware := NormalizeHardware(submission.Hardware)
+	if hardware == "" || len(hardware) > maxHardwareLength {
+		return fmt.Errorf("Hardware must be between 1 and %d characters", maxHardwareLength)
+	}
+	if submission.Backend != BenchmarkBackendGPU && submission.Backend != BenchmarkBackendCPU {
+		return errors.New("Backend must be GPU or CPU")
+	}
+	if submission.DifficultyMultiplier < 1 || submission.DifficultyMultiplier > config.MAX_WORK_DIFFICULTY_MULTIPLIER {
+		return fmt.Errorf("Difficulty multiplier must be between 1 and %d", config.MAX_WORK_DIFFICULTY_MULTIPLIER)
+	}
+	if submission.Runs < 1 || submission.Runs > maxBenchmarkRuns {
+		return fmt.Errorf("Runs must be between 1 and %d", maxBenchmarkRuns)
+	}
+	if submission.AverageSeconds <= 0 {
+		return errors.New("Average seconds must be positive")
+	}
+	return nil
+}
+
+// Replaces the user's previous profile for the same hardware and difficulty, so one provider can't flood the leaderboard
+func (s *BenchmarkService) SubmitBenchmark(userID uuid.UUID, submission BenchmarkSubmission) (*models.BenchmarkProfile, error) {
+	if err := ValidateBenchmarkSubmission(submission); err != nil {
+		return nil, err
+	}
+	profile := &models.BenchmarkProfile{
+		UserID:               userID,
+		Hardware:             NormalizeHardware(submission.Hardware),
+		Backend:              submission.Backend,
+		DifficultyMultiplier: submission.DifficultyMultiplier,
+		Runs:                 submission.Runs,
+		AverageSeconds:       submission.AverageSeconds,
+		ClientVersion:        submission.ClientVersion,
+	}
+	err := s.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "hardware"}, {Name: "backend"}, {Name: "difficulty_multiplier"}},
+		DoUpdates: clause.AssignmentColumns([]string{"runs", "average_seconds", "client_version", "updated_at"}),
+	}).Create(profile).Error
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// Fastest hardware first, hardware names are compared case insensitively
+// Only profiles at the given difficulty are included if it's set
+func (s *BenchmarkService) GetHardwareLeaderboard(difficultyMultiplier *int) ([]HardwareBenchmark, error) {
+	leaderboard := []HardwareBenchmark{}
+	query := s.Db.Model(&models.BenchmarkProfile{}).Select("MIN(hardware) as hardware, backend, COUNT(*) as profiles, COUNT(DISTINCT user_id) as providers, AVG(difficulty_multiplier / average_seconds) as work_per_second")
+	if difficultyMultiplier != nil {
+		query = query.Where("difficulty_multiplier = ?", *difficultyMultiplier)
+	}
+	err := query.Group("LOWER(hardware), backend").Order("work_per_second desc").Limit(config.BENCHMARK_LEADERBOARD_SIZE).Find(&leaderboard).Error
+	return leaderboard, err
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/bananocoin/boompow/libs/utils/number"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrDepositRecorded = errors.New("deposit already recorded")
+var ErrInsufficientCredit = errors.New("insufficient credit")
+var ErrBoostCapReached = errors.New("daily boost cap reached")
+
+// Boosts sold by a tenant over a time range
+type BoostEconomics struct {
+	Boosts     int
+	Minutes    int
+	Buyers     int
+	RevenueRaw string
+}
+
+type BoostRepo interface {
+	RecordDeposit(userID uuid.UUID, amountRaw string, blockHash string, recordedBy uuid.UUID) (*models.CreditEntry, error)
+	GetCreditBalance(userID uuid.UUID) (*big.Int, error)
+	GetCreditEntries(userID uuid.UUID, args pagination.Args) ([]models.CreditEntry, error)
+	PurchaseBoost(user *models.User, minutes int, pricing models.BoostPricing, now time.Time) (*models.PriorityBoost, error)
+	GetActiveBoost(userID uuid.UUID, now time.Time) (*models.PriorityBoost, error)
+	GetBoostMinutesSince(userID uuid.UUID, since time.Time) (int, error)
+	GetBoostEconomics(tenantID string, since time.Time) (*BoostEconomics, error)
+}
+
+type BoostService struct {
+	Db *gorm.DB
+}
+
+var _ BoostRepo = &BoostService{}
+
+func NewBoostService(db *gorm.DB) *BoostService {
+	return &BoostService{
+		Db: db,
+	}
+}
+
+// A block hash can only be credited once
+func (s *BoostService) RecordDeposit(userID uuid.UUID, amountRaw string, blockHash string, recordedBy uuid.UUID) (*models.CreditEntry, error) {
+	entry := &models.CreditEntry{
+		UserID:    userID,
+		AmountRaw: amountRaw,
+		Kind:      models.CreditEntryDeposit,
+		Reference: blockHash,
+		CreatedBy: &recordedBy,
+	}
+	err := s.Db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.CreditEntry{}).Where("reference = ?", blockHash).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDepositRecorded
+		}
+		return tx.Create(entry).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func creditBalance(tx *gorm.DB, userID uuid.UUID) (*big.Int, error) {
+	var balance string
+	if err := tx.Model(&models.CreditEntry{}).Select("COALESCE(SUM(amount_raw), 0)::text").Where("user_id = ?", userID).Scan(&balance).Error; err != nil {
+		return nil, err
+	}
+	return number.RawToBigInt(balance)
+}
+
+// In raw
+func (s *BoostService) GetCreditBalance(userID uuid.UUID) (*big.Int, error) {
+	return creditBalance(s.Db, userID)
+}
+
+func CreditEntryCursor(entry models.CreditEntry) pagination.Cursor {
+	return pagination.Cursor{Time: entry.CreatedAt, ID: entry.ID.String()}
+}
+
+// Newest first
+func (s *BoostService) GetCreditEntries(userID uuid.UUID, args pagination.Args) ([]models.CreditEntry, error) {
+	entries := []models.CreditEntry{}
+	err := s.Db.Where("user_id = ?", userID).Scopes(args.Scope("created_at", "id")).Find(&entries).Error
+	return entries, err
+}
+
+func boostMinutesSince(tx *gorm.DB, userID uuid.UUID, since time.Time) (int, error) {
+	var minutes int
+	err := tx.Model(&models.PriorityBoost{}).Select("COALESCE(SUM(minutes), 0)").Where("user_id = ? AND created_at >= ?", userID, since).Scan(&minutes).Error
+	return minutes, err
+}
+
+// Boost minutes the user bought since, the daily cap is counted from the start of the UTC day
+func (s *BoostService) GetBoostMinutesSince(userID uuid.UUID, since time.Time) (int, error) {
+	return boostMinutesSince(s.Db, userID, since)
+}
+
+// Nil if the user has no boost that ends after now, the one that ends last otherwise
+func (s *BoostService) GetActiveBoost(userID uuid.UUID, now time.Time) (*models.PriorityBoost, error) {
+	return activeBoost(s.Db, userID, now)
+}
+
+func activeBoost(tx *gorm.DB, userID uuid.UUID, now time.Time) (*models.PriorityBoost, error) {
+	var boosts []models.PriorityBoost
+	if err := tx.Where("user_id = ? AND ends_at > ?", userID, now).Order("ends_at desc").Limit(1).Find(&boosts).Error; err != nil {
+		return nil, err
+	}
+	if len(boosts) == 0 {
+		return nil, nil
+	}
+	return &boosts[0], nil
+}
+
+// Pays for the boost out of the user's credit, it starts now or when their active boost ends
+func (s *BoostService) PurchaseBoost(user *models.User, minutes int, pricing models.BoostPricing, now time.Time) (*models.PriorityBoost, error) {
+	price, err := pricing.PriceRaw(minutes)
+	if err != nil {
+		return nil, err
+	}
+	var boost *models.PriorityBoost
+	err = s.Db.Transaction(func(tx *gorm.DB) error {
+		// Concurrent purchases can't both spend the same credit or minutes
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", user.ID).First(&models.User{}).Error; err != nil {
+			return err
+		}
+		bought, err := boostMinutesSince(tx, user.ID, models.StartOfDay(now))
+		if err != nil {
+			return err
+		}
+		if bought+minutes > pricing.DailyCapMinutes {
+			return ErrBoostCapReached
+		}
+		balance, err := creditBalance(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		if balance.Cmp(price) < 0 {
+			return ErrInsufficientCredit
+		}
+		active, err := activeBoost(tx, user.ID, now)
+		if err != nil {
+			return err
+		}
+		var activeUntil *time.Time
+		if active != nil {
+			activeUntil = &active.EndsAt
+		}
+		startsAt, endsAt := models.BoostWindow(activeUntil, minutes, now)
+		boost = &models.PriorityBoost{
+			UserID:   user.ID,
+			TenantID: user.TenantID,
+			StartsAt: startsAt,
+			EndsAt:   endsAt,
+			Minutes:  minutes,
+			PriceRaw: price.String(),
+		}
+		if err := tx.Create(boost).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.CreditEntry{
+			UserID:    user.ID,
+			AmountRaw: new(big.Int).Neg(price).String(),
+			Kind:      models.CreditEntryBoost,
+			Reference: fmt.Sprintf("boost:%s", boost.ID),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return boost, nil
+}
+
+// Boosts bought since, whenever they run
+func (s *BoostService) GetBoostEconomics(tenantID string, since time.Time) (*BoostEconomics, error) {
+	var row struct {
+		Boosts     int
+		Minutes    int
+		Buyers     int
+		RevenueRaw string
+	}
+	err := s.Db.Model(&models.PriorityBoost{}).
+		Select("COUNT(*) AS boosts, COALESCE(SUM(minutes), 0) AS minutes, COUNT(DISTINCT user_id) AS buyers, COALESCE(SUM(price_raw), 0)::text AS revenue_raw").
+		Where("tenant_id = ? AND created_at >= ?", tenantID, since).
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+	return &BoostEconomics{Boosts: row.Boosts, Minutes: row.Minutes, Buyers: row.Buyers, RevenueRaw: row.RevenueRaw}, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CounterSnapshotRepo interface {
+	ReplaceCounterValues(values []models.CounterValue) error
+	GetCounterValues(now time.Time) ([]models.CounterValue, error)
+	CreateConnectedWorkersSnapshots(snapshots []models.ConnectedWorkersSnapshot) error
+	GetConnectedWorkersHistory(tenantID string, since time.Time) ([]models.ConnectedWorkersSnapshot, error)
+	DeleteConnectedWorkersSnapshotsBefore(before time.Time) (int64, error)
+}
+
+type CounterSnapshotService struct {
+	Db *gorm.DB
+}
+
+var _ CounterSnapshotRepo = &CounterSnapshotService{}
+
+func NewCounterSnapshotService(db *gorm.DB) *CounterSnapshotService {
+	return &CounterSnapshotService{
+		Db: db,
+	}
+}
+
+// Counters that are gone from redis are gone from the copy too, e.g. client scores after a payout
+func (s *CounterSnapshotService) ReplaceCounterValues(values []models.CounterValue) error {
+	return s.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.CounterValue{}).Error; err != nil {
+			return err
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		// Another replica may have snapshotted meanwhile
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(values, 500).Error
+	})
+}
+
+// Leaves out the counters that expired by now
+func (s *CounterSnapshotService) GetCounterValues(now time.Time) ([]models.CounterValue, error) {
+	values := []models.CounterValue{}
+	err := s.Db.Where("expires_at IS NULL OR expires_at > ?", now).Order("key, field").Find(&values).Error
+	return values, err
+}
+
+// Snapshots other replicas already took at the same time are kept
+func (s *CounterSnapshotService) CreateConnectedWorkersSnapshots(snapshots []models.ConnectedWorkersSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return s.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&snapshots).Error
+}
+
+// Oldest first
+func (s *CounterSnapshotService) GetConnectedWorkersHistory(tenantID string, since time.Time) ([]models.ConnectedWorkersSnapshot, error) {
+	snapshots := []models.ConnectedWorkersSnapshot{}
+	err := s.Db.Where("tenant_id = ? AND taken_at >= ?", tenantID, since).Order("taken_at asc").Find(&snapshots).Error
+	return snapshots, err
+}
+
+func (s *CounterSnapshotService) DeleteConnectedWorkersSnapshotsBefore(before time.Time) (int64, error) {
+	res := s.Db.Where("taken_at < ?", before).Delete(&models.ConnectedWorkersSnapshot{})
+	return res.RowsAffected, res.Error
+}
+
+// Puts back the counters from the last snapshot if redis lost its data since they were last restored
+// Returns the number of keys restored
+func RestoreLostCounters(repo CounterSnapshotRepo, now time.Time) (int, error) {
+	redis := database.GetRedisDB()
+	intact, err := redis.CountersIntact()
+	if err != nil || intact {
+		return 0, err
+	}
+	values, err := repo.GetCounterValues(now)
+	if err != nil {
+		return 0, err
+	}
+	restored, err := redis.RestoreCounters(values, now)
+	if err != nil {
+		return restored, err
+	}
+	return restored, redis.MarkCountersIntact()
+}
+
+// Copies the volatile redis counters into postgres and records the workers connected to each tenant
+// Counters redis lost are restored first, so the loss doesn't overwrite the last snapshot
+func SnapshotCounters(repo CounterSnapshotRepo, tenantIDs []string, now time.Time) error {
+	if _, err := RestoreLostCounters(repo, now); err != nil {
+		return err
+	}
+	redis := database.GetRedisDB()
+	values, err := redis.ReadCounters(now)
+	if err != nil {
+		return err
+	}
+	if err := repo.ReplaceCounterValues(values); err != nil {
+		return err
+	}
+	// Replicas snapshotting within the same interval share one point
+	takenAt := now.UTC().Truncate(config.COUNTER_SNAPSHOT_INTERVAL_MINUTES * time.Minute)
+	snapshots := make([]models.ConnectedWorkersSnapshot, 0, len(tenantIDs))
+	for _, tenantID := range tenantIDs {
+		workers, err := redis.GetNumberConnectedClientsForTenant(tenantID)
+		if err != nil {
+			return err
+		}
+		snapshots = append(snapshots, models.ConnectedWorkersSnapshot{TakenAt: takenAt, TenantID: tenantID, Workers: workers})
+	}
+	if err := repo.CreateConnectedWorkersSnapshots(snapshots); err != nil {
+		return err
+	}
+	_, err = repo.DeleteConnectedWorkersSnapshotsBefore(now.Add(-config.CONNECTED_WORKERS_HISTORY_DAYS * 24 * time.Hour))
+	return err
+}
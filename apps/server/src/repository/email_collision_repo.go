@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EmailCollisionRepo interface {
+	GetEmailCollisions(includeReviewed bool) ([]models.EmailCollision, error)
+	ReviewEmailCollision(normalizedEmail string, reviewedBy uuid.UUID, now time.Time) (bool, error)
+}
+
+type EmailCollisionService struct {
+	Db *gorm.DB
+}
+
+var _ EmailCollisionRepo = &EmailCollisionService{}
+
+func NewEmailCollisionService(db *gorm.DB) *EmailCollisionService {
+	return &EmailCollisionService{
+		Db: db,
+	}
+}
+
+// Oldest flagged first, with the accounts that share each email
+func (s *EmailCollisionService) GetEmailCollisions(includeReviewed bool) ([]models.EmailCollision, error) {
+	var collisions []models.EmailCollision
+	query := s.Db.Order("flagged_at asc").Order("normalized_email asc")
+	if !includeReviewed {
+		query = query.Where("reviewed_at IS NULL")
+	}
+	if err := query.Find(&collisions).Error; err != nil {
+		return nil, err
+	}
+	if len(collisions) == 0 {
+		return collisions, nil
+	}
+	normalized := make([]string, len(collisions))
+	for i, collision := range collisions {
+		normalized[i] = collision.NormalizedEmail
+	}
+	var users []models.User
+	if err := s.Db.Where("normalized_email IN ?", normalized).Order("created_at asc").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for i := range collisions {
+		for _, user := range users {
+			if user.NormalizedEmail == collisions[i].NormalizedEmail {
+				collisions[i].Users = append(collisions[i].Users, user)
+			}
+		}
+	}
+	return collisions, nil
+}
+
+// False if there's no such collision or it was reviewed already
+func (s *EmailCollisionService) ReviewEmailCollision(normalizedEmail string, reviewedBy uuid.UUID, now time.Time) (bool, error) {
+	res := s.Db.Model(&models.EmailCollision{}).Where("normalized_email = ?", normalizedEmail).Where("reviewed_at IS NULL").Updates(map[string]interface{}{
+		"reviewed_at": now,
+		"reviewed_by": reviewedBy,
+	})
+	return res.RowsAffected > 0, res.Error
+}
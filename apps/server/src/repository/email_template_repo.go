@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"gorm.io/gorm"
+)
+
+type EmailTemplateRepo interface {
+	GetEmailTemplate(name string, language string) (*models.EmailTemplate, error)
+	GetEmailTemplateVersion(name string, language string, version int) (*models.EmailTemplate, error)
+	GetEmailTemplateVersions(name string, language string) ([]models.EmailTemplate, error)
+	GetEmailTemplates() ([]models.EmailTemplate, error)
+	SaveEmailTemplate(template *models.EmailTemplate) error
+}
+
+type EmailTemplateService struct {
+	Db *gorm.DB
+}
+
+var _ EmailTemplateRepo = &EmailTemplateService{}
+
+func NewEmailTemplateService(db *gorm.DB) *EmailTemplateService {
+	return &EmailTemplateService{
+		Db: db,
+	}
+}
+
+// The latest version, nil if the built-in email was never edited in this language
+func (s *EmailTemplateService) GetEmailTemplate(name string, language string) (*models.EmailTemplate, error) {
+	var template models.EmailTemplate
+	err := s.Db.Where("name = ? AND language = ?", name, language).Order("version desc").First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (s *EmailTemplateService) GetEmailTemplateVersion(name string, language string, version int) (*models.EmailTemplate, error) {
+	var template models.EmailTemplate
+	err := s.Db.Where("name = ? AND language = ? AND version = ?", name, language, version).First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("No email template with this version")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// Newest first
+func (s *EmailTemplateService) GetEmailTemplateVersions(name string, language string) ([]models.EmailTemplate, error) {
+	var templates []models.EmailTemplate
+	err := s.Db.Where("name = ? AND language = ?", name, language).Order("version desc").Find(&templates).Error
+	return templates, err
+}
+
+// The latest version of every edited name and language
+func (s *EmailTemplateService) GetEmailTemplates() ([]models.EmailTemplate, error) {
+	var templates []models.EmailTemplate
+	err := s.Db.Raw(`SELECT DISTINCT ON (name, language) * FROM email_templates ORDER BY name, language, version DESC`).Scan(&templates).Error
+	return templates, err
+}
+
+// Versions are never changed, saving adds the next one
+func (s *EmailTemplateService) SaveEmailTemplate(template *models.EmailTemplate) error {
+	return s.Db.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&models.EmailTemplate{}).Where("name = ? AND language = ?", template.Name, template.Language).Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		template.Version = latest + 1
+		return tx.Create(template).Error
+	})
+}
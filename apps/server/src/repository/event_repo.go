@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"gorm.io/gorm"
+	"k8s.io/klog/v2"
+)
+
+type EventRepo interface {
+	CreateHubEvents(events []models.HubEvent) error
+	GetHubEvents(requestID string) ([]models.HubEvent, error)
+	HubEventSink(events <-chan models.HubEvent)
+}
+
+type EventService struct {
+	Db *gorm.DB
+}
+
+var _ EventRepo = &EventService{}
+
+func NewEventService(db *gorm.DB) *EventService {
+	return &EventService{
+		Db: db,
+	}
+}
+
+func (s *EventService) CreateHubEvents(events []models.HubEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return s.Db.Create(&events).Error
+}
+
+// The persisted timeline of a work request, oldest first
+func (s *EventService) GetHubEvents(requestID string) ([]models.HubEvent, error) {
+	events := []models.HubEvent{}
+	err := s.Db.Where("request_id = ?", requestID).Order("timestamp asc").Find(&events).Error
+	return events, err
+}
+
+// Persist hub events as they come in, batching whatever is available so a busy hub doesn't mean one insert per event
+func (s *EventService) HubEventSink(events <-chan models.HubEvent) {
+	for event := range events {
+		batch := []models.HubEvent{event}
+	drain:
+		for len(batch) < 100 {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, e)
+			default:
+				break drain
+			}
+		}
+		if err := s.CreateHubEvents(batch); err != nil {
+			klog.Errorf("Error persisting %d hub events: %v", len(batch), err)
+		}
+	}
+}
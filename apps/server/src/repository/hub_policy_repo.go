@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HubPolicyRepo interface {
+	GetHubPolicy() (*models.HubPolicy, error)
+	SaveHubPolicy(policy *models.HubPolicy) error
+}
+
+type HubPolicyService struct {
+	Db *gorm.DB
+}
+
+var _ HubPolicyRepo = &HubPolicyService{}
+
+func NewHubPolicyService(db *gorm.DB) *HubPolicyService {
+	return &HubPolicyService{
+		Db: db,
+	}
+}
+
+// The default policy until an admin saved one
+func (s *HubPolicyService) GetHubPolicy() (*models.HubPolicy, error) {
+	var policy models.HubPolicy
+	err := s.Db.Where("id = ?", models.HubPolicyID).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy = models.DefaultHubPolicy()
+		return &policy, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (s *HubPolicyService) SaveHubPolicy(policy *models.HubPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	policy.ID = models.HubPolicyID
+	return s.Db.Clauses(clause.OnConflict{UpdateAll: true}).Create(policy).Error
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IncidentRepo interface {
+	OpenIncident(incident *models.Incident) error
+	ResolveIncident(id uuid.UUID) (*models.Incident, error)
+	GetOpenIncidents() ([]models.Incident, error)
+	GetIncidentHistory() ([]models.Incident, error)
+}
+
+type IncidentService struct {
+	Db *gorm.DB
+}
+
+var _ IncidentRepo = &IncidentService{}
+
+func NewIncidentService(db *gorm.DB) *IncidentService {
+	return &IncidentService{
+		Db: db,
+	}
+}
+
+func (s *IncidentService) OpenIncident(incident *models.Incident) error {
+	if incident.Title == "" {
+		return errors.New("Incidents need a title")
+	}
+	if incident.Severity != models.IncidentDegraded && incident.Severity != models.IncidentOutage {
+		return errors.New("Severity must be DEGRADED or OUTAGE")
+	}
+	incident.ResolvedAt = nil
+	return s.Db.Create(incident).Error
+}
+
+// Resolving an incident that's already resolved is an error, so it isn't announced twice
+func (s *IncidentService) ResolveIncident(id uuid.UUID) (*models.Incident, error) {
+	incident := &models.Incident{}
+	now := time.Now().UTC()
+	res := s.Db.Model(incident).Where("id = ? AND resolved_at IS NULL", id).Update("resolved_at", now)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, errors.New("No open incident with this ID")
+	}
+	if err := s.Db.Where("id = ?", id).First(incident).Error; err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+// Oldest first
+func (s *IncidentService) GetOpenIncidents() ([]models.Incident, error) {
+	incidents := []models.Incident{}
+	err := s.Db.Where("resolved_at IS NULL").Order("created_at asc").Find(&incidents).Error
+	return incidents, err
+}
+
+// Newest first, open and resolved
+func (s *IncidentService) GetIncidentHistory() ([]models.Incident, error) {
+	incidents := []models.Incident{}
+	err := s.Db.Order("created_at desc").Limit(config.INCIDENT_HISTORY_SIZE).Find(&incidents).Error
+	return incidents, err
+}
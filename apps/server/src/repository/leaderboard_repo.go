@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"k8s.io/klog/v2"
+)
+
+type LeaderboardRepo interface {
+	RefreshLeaderboard(tenantID string, window models.LeaderboardWindow, limit int, now time.Time) error
+	GetLeaderboard(tenantID string, window models.LeaderboardWindow, limit int) ([]models.LeaderboardEntry, error)
+}
+
+type LeaderboardService struct {
+	Db *gorm.DB
+}
+
+var _ LeaderboardRepo = &LeaderboardService{}
+
+func NewLeaderboardService(db *gorm.DB) *LeaderboardService {
+	return &LeaderboardService{
+		Db: db,
+	}
+}
+
+// Recomputes the top providers of the window from work_results, readers see the previous entries until it's done
+// Only providers with a ban address are ranked, it's what the leaderboard shows
+func (s *LeaderboardService) RefreshLeaderboard(tenantID string, window models.LeaderboardWindow, limit int, now time.Time) error {
+	entries := []models.LeaderboardEntry{}
+	query := s.Db.Model(&models.WorkResult{}).
+		Select("work_results.provided_by AS provider_id, users.ban_address, COUNT(*) AS work_units, SUM(work_results.difficulty_multiplier) AS difficulty_sum").
+		Joins("JOIN users ON users.id = work_results.provided_by").
+		Where("work_results.tenant_id = ?", tenantID).
+		Where("users.type = ? AND users.ban_address IS NOT NULL", models.PROVIDER)
+	if since := window.Since(now); since != nil {
+		query = query.Where("work_results.created_at >= ?", *since)
+	}
+	err := query.Group("work_results.provided_by, users.ban_address").
+		Order("work_units DESC, difficulty_sum DESC, provider_id").
+		Limit(limit).
+		Scan(&entries).Error
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		entries[i].TenantID = tenantID
+		entries[i].Window = window
+		entries[i].Rank = i + 1
+		entries[i].RefreshedAt = now
+	}
+	return s.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ? AND time_window = ?", tenantID, window).Delete(&models.LeaderboardEntry{}).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		// Another replica may have refreshed it meanwhile
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&entries).Error
+	})
+}
+
+// Best first
+func (s *LeaderboardService) GetLeaderboard(tenantID string, window models.LeaderboardWindow, limit int) ([]models.LeaderboardEntry, error) {
+	entries := []models.LeaderboardEntry{}
+	err := s.Db.Where("tenant_id = ? AND time_window = ?", tenantID, window).Order("rank").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// Refreshes every window of every tenant, a window that failed keeps its previous entries
+func RefreshLeaderboards(leaderboardRepo LeaderboardRepo, tenantRepo TenantRepo, now time.Time) error {
+	tenants, err := tenantRepo.GetAllTenants()
+	if err != nil {
+		return err
+	}
+	for _, tenant := range tenants {
+		for _, window := range models.LeaderboardWindows {
+			if err := leaderboardRepo.RefreshLeaderboard(tenant.ID, window, config.LEADERBOARD_SIZE, now); err != nil {
+				klog.Errorf("Error refreshing the %s leaderboard of tenant %s %v", window, tenant.ID, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MaintenanceRepo interface {
+	CreateMaintenanceWindow(window *models.MaintenanceWindow) error
+	CancelMaintenanceWindow(id uuid.UUID) error
+	GetMaintenanceWindows(endingAfter time.Time) ([]models.MaintenanceWindow, error)
+}
+
+type MaintenanceService struct {
+	Db *gorm.DB
+}
+
+var _ MaintenanceRepo = &MaintenanceService{}
+
+func NewMaintenanceService(db *gorm.DB) *MaintenanceService {
+	return &MaintenanceService{
+		Db: db,
+	}
+}
+
+func ValidateMaintenanceWindow(window *models.MaintenanceWindow, now time.Time) error {
+	if !window.EndsAt.After(window.StartsAt) {
+		return errors.New("Maintenance has to end after it starts")
+	}
+	if !window.EndsAt.After(now) {
+		return errors.New("Maintenance can't end in the past")
+	}
+	if window.EndsAt.Sub(window.StartsAt) > config.MAINTENANCE_MAX_HOURS*time.Hour {
+		return errors.New("Maintenance can't be longer than 24 hours")
+	}
+	return nil
+}
+
+func (s *MaintenanceService) CreateMaintenanceWindow(window *models.MaintenanceWindow) error {
+	if err := ValidateMaintenanceWindow(window, time.Now()); err != nil {
+		return err
+	}
+	window.StartsAt = window.StartsAt.UTC()
+	window.EndsAt = window.EndsAt.UTC()
+	return s.Db.Create(window).Error
+}
+
+// Windows are deleted, there's nothing to keep about maintenance that didn't happen
+func (s *MaintenanceService) CancelMaintenanceWindow(id uuid.UUID) error {
+	res := s.Db.Where("id = ?", id).Delete(&models.MaintenanceWindow{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("No maintenance window with this ID")
+	}
+	return nil
+}
+
+// Windows that end after the given time, soonest first
+func (s *MaintenanceService) GetMaintenanceWindows(endingAfter time.Time) ([]models.MaintenanceWindow, error) {
+	windows := []models.MaintenanceWindow{}
+	err := s.Db.Where("ends_at > ?", endingAfter.UTC()).Order("starts_at asc").Find(&windows).Error
+	return windows, err
+}
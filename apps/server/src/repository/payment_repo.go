@@ -1,17 +1,23 @@
 package repository
 
 import (
+	"fmt"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
 	"github.com/bananocoin/boompow/apps/server/src/models"
 	serializableModels "github.com/bananocoin/boompow/libs/models"
 	"github.com/bananocoin/boompow/libs/utils/number"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PaymentRepo interface {
-	BatchCreateSendRequests(tx *gorm.DB, sendRequests []serializableModels.SendRequest) error
+	BatchCreateSendRequests(tx *gorm.DB, tenantID string, cycleID *uuid.UUID, sendRequests []serializableModels.SendRequest) error
 	GetPendingPayments(tx *gorm.DB) ([]serializableModels.SendRequest, error)
+	ClaimPendingPayment(tx *gorm.DB, sendId string) (bool, error)
 	SetBlockHash(tx *gorm.DB, sendId string, blockHash string) error
-	GetTotalPaidBanano() (float64, error)
+	GetTotalPaidBanano(tenantID string) (float64, error)
 }
 
 type PaymentService struct {
@@ -26,8 +32,8 @@ func NewPaymentService(db *gorm.DB) *PaymentService {
 	}
 }
 
-// Create payments in database
-func (s *PaymentService) BatchCreateSendRequests(tx *gorm.DB, sendRequests []serializableModels.SendRequest) error {
+// Create payments in database, cycleID is the payout cycle they were computed in
+func (s *PaymentService) BatchCreateSendRequests(tx *gorm.DB, tenantID string, cycleID *uuid.UUID, sendRequests []serializableModels.SendRequest) error {
 	payments := make([]models.Payment, len(sendRequests))
 
 	for i, sendRequest := range sendRequests {
@@ -35,6 +41,8 @@ func (s *PaymentService) BatchCreateSendRequests(tx *gorm.DB, sendRequests []ser
 			SendId:   sendRequest.ID,
 			SendJson: sendRequest,
 			PaidTo:   sendRequest.PaidTo,
+			TenantID: tenantID,
+			CycleID:  cycleID,
 		}
 	}
 
@@ -52,15 +60,28 @@ func (s *PaymentService) GetPendingPayments(tx *gorm.DB) ([]serializableModels.S
 	return res, nil
 }
 
+// Locks a payment until tx ends so no other run sends it meanwhile, false if another run holds it or it was sent already
+func (s *PaymentService) ClaimPendingPayment(tx *gorm.DB, sendId string) (bool, error) {
+	var payments []models.Payment
+	res := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).Where("send_id = ? AND block_hash IS NULL", sendId).Limit(1).Find(&payments)
+	return res.RowsAffected > 0, res.Error
+}
+
+// The idempotency key of a payment, the same cycle, provider and address always get the same one
+// The node is sent a hash of it as the send id, so a payment is never sent twice
+func PaymentSendID(cycleID uuid.UUID, providerID uuid.UUID, destination string) string {
+	return fmt.Sprintf("%s:%s:%s", cycleID, providerID, destination)
+}
+
 // Update payment with block hash
 func (s *PaymentService) SetBlockHash(tx *gorm.DB, sendId string, blockHash string) error {
 	return tx.Model(&models.Payment{}).Where("send_id = ?", sendId).Update("block_hash", blockHash).Error
 }
 
 // Get total paid sum
-func (s *PaymentService) GetTotalPaidBanano() (float64, error) {
+func (s *PaymentService) GetTotalPaidBanano(tenantID string) (float64, error) {
 	var totalPaid string
-	if err := s.Db.Model(&models.Payment{}).Select("sum(cast(send_json->>'amount'as numeric)) as total_raw").Find(&totalPaid).Error; err != nil {
+	if err := s.Db.Model(&models.Payment{}).Select("coalesce(sum(cast(send_json->>'amount'as numeric)), 0) as total_raw").Where("tenant_id = ?", tenantID).Find(&totalPaid).Error; err != nil {
 		return -1, err
 	}
 	asBan, err := number.RawToBanano(totalPaid, true)
@@ -69,7 +90,9 @@ func (s *PaymentService) GetTotalPaidBanano() (float64, error) {
 	}
 
 	// Magic number, this is what BPoW v1 paid so add it to the total
-	asBan += 1728016
+	if tenantID == config.DEFAULT_TENANT_ID {
+		asBan += 1728016
+	}
 
 	return asBan, nil
 }
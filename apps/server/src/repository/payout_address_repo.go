@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/bananocoin/boompow/libs/utils/number"
+	"github.com/bananocoin/boompow/libs/utils/validation"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// More splits than this just means dust payments
+const maxPayoutAddresses = 10
+
+type PayoutSplit struct {
+	BanAddress string `json:"ban_address"`
+	Percent    int    `json:"percent"`
+}
+
+type PayoutShare struct {
+	BanAddress string   `json:"ban_address"`
+	AmountRaw  *big.Int `json:"amount_raw"`
+}
+
+type PayoutAddressHistory struct {
+	BanAddress   string    `json:"ban_address"`
+	TotalRaw     string    `json:"total_raw"`
+	PaymentCount int       `json:"payment_count"`
+	LastPaidAt   time.Time `json:"last_paid_at"`
+}
+
+type PayoutAddressRepo interface {
+	SetPayoutAddresses(userID uuid.UUID, splits []PayoutSplit) ([]models.PayoutAddress, error)
+	GetPayoutAddresses(userID uuid.UUID) ([]models.PayoutAddress, error)
+	GetPayoutAddressesForUsers(tx *gorm.DB, userIDs []uuid.UUID) (map[uuid.UUID][]models.PayoutAddress, error)
+	GetPayoutHistory(userID uuid.UUID, args pagination.Args) ([]PayoutAddressHistory, error)
+	CountPayoutHistory(userID uuid.UUID) (int, error)
+}
+
+type PayoutAddressService struct {
+	Db *gorm.DB
+}
+
+var _ PayoutAddressRepo = &PayoutAddressService{}
+
+func NewPayoutAddressService(db *gorm.DB) *PayoutAddressService {
+	return &PayoutAddressService{
+		Db: db,
+	}
+}
+
+// Splits must be valid addresses, without duplicates, adding up to exactly 100 percent
+func ValidatePayoutSplits(splits []PayoutSplit) error {
+	if len(splits) > maxPayoutAddresses {
+		return fmt.Errorf("At most %d payout addresses are allowed", maxPayoutAddresses)
+	}
+	seen := make(map[string]bool)
+	total := 0
+	for _, split := range splits {
+		if !validation.ValidateAddress(split.BanAddress) {
+			return fmt.Errorf("Invalid ban_ address %s", split.BanAddress)
+		}
+		if seen[split.BanAddress] {
+			return fmt.Errorf("Duplicate payout address %s", split.BanAddress)
+		}
+		seen[split.BanAddress] = true
+		if split.Percent < 1 || split.Percent > 100 {
+			return errors.New("Percentages must be between 1 and 100")
+		}
+		total += split.Percent
+	}
+	if len(splits) > 0 && total != 100 {
+		return fmt.Errorf("Percentages must add up to 100, got %d", total)
+	}
+	return nil
+}
+
+// Divide a payout in raw between the addresses, without any the whole payout goes to the default address
+// The raw left over from rounding the shares down goes to the first address, so the shares add up to the payout
+func SplitPayout(amountRaw *big.Int, defaultAddress string, addresses []models.PayoutAddress) []PayoutShare {
+	if len(addresses) == 0 {
+		return []PayoutShare{{BanAddress: defaultAddress, AmountRaw: new(big.Int).Set(amountRaw)}}
+	}
+	shares := make([]PayoutShare, len(addresses))
+	leftover := new(big.Int).Set(amountRaw)
+	for i, address := range addresses {
+		share := new(big.Int).Mul(amountRaw, big.NewInt(int64(address.Percent)))
+		share.Quo(share, big.NewInt(100))
+		leftover.Sub(leftover, share)
+		shares[i] = PayoutShare{BanAddress: address.BanAddress, AmountRaw: share}
+	}
+	shares[0].AmountRaw.Add(shares[0].AmountRaw, leftover)
+	return shares
+}
+
+// Replace all payout addresses of a user, an empty list goes back to paying the account's ban address
+func (s *PayoutAddressService) SetPayoutAddresses(userID uuid.UUID, splits []PayoutSplit) ([]models.PayoutAddress, error) {
+	if err := ValidatePayoutSplits(splits); err != nil {
+		return nil, err
+	}
+	addresses := make([]models.PayoutAddress, len(splits))
+	for i, split := range splits {
+		addresses[i] = models.PayoutAddress{
+			UserID:     userID,
+			BanAddress: split.BanAddress,
+			Percent:    split.Percent,
+		}
+	}
+	err := s.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.PayoutAddress{}).Error; err != nil {
+			return err
+		}
+		if len(addresses) == 0 {
+			return nil
+		}
+		return tx.Create(&addresses).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+func (s *PayoutAddressService) GetPayoutAddresses(userID uuid.UUID) ([]models.PayoutAddress, error) {
+	addresses := []models.PayoutAddress{}
+	err := s.Db.Where("user_id = ?", userID).Order("percent desc").Find(&addresses).Error
+	return addresses, err
+}
+
+// Payout addresses of several users at once, for the payout engine
+func (s *PayoutAddressService) GetPayoutAddressesForUsers(tx *gorm.DB, userIDs []uuid.UUID) (map[uuid.UUID][]models.PayoutAddress, error) {
+	ret := make(map[uuid.UUID][]models.PayoutAddress)
+	if len(userIDs) == 0 {
+		return ret, nil
+	}
+	addresses := []models.PayoutAddress{}
+	if err := tx.Where("user_id IN ?", userIDs).Order("percent desc").Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		ret[address.UserID] = append(ret[address.UserID], address)
+	}
+	return ret, nil
+}
+
+func (h PayoutAddressHistory) Cursor() pagination.Cursor {
+	return pagination.Cursor{Time: h.LastPaidAt, ID: h.BanAddress}
+}
+
+// What has been paid to each address of a user, most recently paid first
+func (s *PayoutAddressService) GetPayoutHistory(userID uuid.UUID, args pagination.Args) ([]PayoutAddressHistory, error) {
+	history := []PayoutAddressHistory{}
+	query := s.Db.Model(&models.Payment{}).Select("send_json->>'destination' as ban_address, coalesce(sum(cast(send_json->>'amount' as numeric)), 0) as total_raw, COUNT(*) as payment_count, max(created_at) as last_paid_at").Where("paid_to = ?", userID).Group("send_json->>'destination'")
+	// Grouped, so the cursor is compared after grouping
+	if args.After != nil {
+		query = query.Having("(max(created_at), send_json->>'destination') < (?, ?)", args.After.Time, args.After.ID)
+	}
+	err := query.Order("last_paid_at desc, ban_address desc").Limit(args.Limit()).Find(&history).Error
+	return history, err
+}
+
+// How many addresses have been paid
+func (s *PayoutAddressService) CountPayoutHistory(userID uuid.UUID) (int, error) {
+	var count int64
+	err := s.Db.Model(&models.Payment{}).Select("COUNT(DISTINCT send_json->>'destination')").Where("paid_to = ?", userID).Scan(&count).Error
+	return int(count), err
+}
+
+// Format a raw amount from the payout history
+func (h PayoutAddressHistory) TotalBanano() string {
+	asBan, err := number.RawToBanano(h.TotalRaw, true)
+	if err != nil {
+		return "0"
+	}
+	return fmt.Sprintf("%.2f", asBan)
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PayoutCycleRepo interface {
+	TryLockPayouts(tx *gorm.DB) (bool, error)
+	CreatePayoutCycle(tx *gorm.DB, tenantID string, prizePool int, results []UnpaidWorkResult) (*models.PayoutCycle, error)
+	GetPayoutCycle(tenantID string, id uuid.UUID) (*models.PayoutCycle, error)
+	GetPayoutCycles(tenantID string, args pagination.Args) ([]models.PayoutCycle, error)
+	CountPayoutCycles(tenantID string) (int, error)
+	GetPayoutCyclePayments(cycleID uuid.UUID) ([]models.Payment, error)
+	GetClosedPayoutCycles(since time.Time) ([]models.PayoutCycle, error)
+	ClaimPayoutStatement(cycleID uuid.UUID, userID uuid.UUID, unsubscribed bool) (bool, error)
+	ReleasePayoutStatement(cycleID uuid.UUID, userID uuid.UUID) error
+}
+
+type PayoutCycleService struct {
+	Db *gorm.DB
+}
+
+var _ PayoutCycleRepo = &PayoutCycleService{}
+
+func NewPayoutCycleService(db *gorm.DB) *PayoutCycleService {
+	return &PayoutCycleService{
+		Db: db,
+	}
+}
+
+// Held until tx ends, so payout jobs running at the same time don't compute a cycle from the same work
+// False if another job holds it
+func (s *PayoutCycleService) TryLockPayouts(tx *gorm.DB) (bool, error) {
+	var locked bool
+	err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", config.PAYOUT_ADVISORY_LOCK_ID).Scan(&locked).Error
+	return locked, err
+}
+
+// Records what the payouts of a cycle are computed from, in the payout job's transaction
+func (s *PayoutCycleService) CreatePayoutCycle(tx *gorm.DB, tenantID string, prizePool int, results []UnpaidWorkResult) (*models.PayoutCycle, error) {
+	inputs := make(models.PayoutCycleInputs, len(results))
+	for i, r := range results {
+		inputs[i] = models.PayoutCycleInput{
+			ProviderID:           r.ProvidedBy.String(),
+			BanAddress:           r.BanAddress,
+			UnpaidCount:          r.UnpaidCount,
+			DifficultySum:        r.DifficultySum,
+			RatedAwardRaw:        r.RatedAwardRaw,
+			UnratedDifficultySum: r.UnratedDifficultySum,
+		}
+	}
+	cycle := &models.PayoutCycle{TenantID: tenantID, PrizePool: prizePool, Inputs: inputs}
+	if err := tx.Create(cycle).Error; err != nil {
+		return nil, err
+	}
+	return cycle, nil
+}
+
+// Nil if the tenant has no such cycle
+func (s *PayoutCycleService) GetPayoutCycle(tenantID string, id uuid.UUID) (*models.PayoutCycle, error) {
+	var cycles []models.PayoutCycle
+	if err := s.Db.Where("id = ? AND tenant_id = ?", id, tenantID).Limit(1).Find(&cycles).Error; err != nil {
+		return nil, err
+	}
+	if len(cycles) == 0 {
+		return nil, nil
+	}
+	return &cycles[0], nil
+}
+
+func PayoutCycleCursor(cycle models.PayoutCycle) pagination.Cursor {
+	return pagination.Cursor{Time: cycle.CreatedAt, ID: cycle.ID.String()}
+}
+
+// Newest first
+func (s *PayoutCycleService) GetPayoutCycles(tenantID string, args pagination.Args) ([]models.PayoutCycle, error) {
+	cycles := []models.PayoutCycle{}
+	err := s.Db.Where("tenant_id = ?", tenantID).Scopes(args.Scope("created_at", "id")).Find(&cycles).Error
+	return cycles, err
+}
+
+func (s *PayoutCycleService) CountPayoutCycles(tenantID string) (int, error) {
+	var count int64
+	err := s.Db.Model(&models.PayoutCycle{}).Where("tenant_id = ?", tenantID).Count(&count).Error
+	return int(count), err
+}
+
+func (s *PayoutCycleService) GetPayoutCyclePayments(cycleID uuid.UUID) ([]models.Payment, error) {
+	payments := []models.Payment{}
+	err := s.Db.Where("cycle_id = ?", cycleID).Find(&payments).Error
+	return payments, err
+}
+
+// Cycles of every tenant created since since whose payments have all been broadcast, and some provider has no statement of yet
+func (s *PayoutCycleService) GetClosedPayoutCycles(since time.Time) ([]models.PayoutCycle, error) {
+	cycles := []models.PayoutCycle{}
+	err := s.Db.Where("created_at >= ?", since).
+		Where("EXISTS (SELECT 1 FROM payments WHERE payments.cycle_id = payout_cycles.id)").
+		Where("NOT EXISTS (SELECT 1 FROM payments WHERE payments.cycle_id = payout_cycles.id AND payments.block_hash IS NULL)").
+		Where("(SELECT COUNT(*) FROM payout_statements WHERE payout_statements.cycle_id = payout_cycles.id) < jsonb_array_length(payout_cycles.inputs)").
+		Order("created_at").Find(&cycles).Error
+	return cycles, err
+}
+
+// False if the provider's statement of the cycle was already claimed, by this server or another
+func (s *PayoutCycleService) ClaimPayoutStatement(cycleID uuid.UUID, userID uuid.UUID, unsubscribed bool) (bool, error) {
+	result := s.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.PayoutStatement{CycleID: cycleID, UserID: userID, Unsubscribed: unsubscribed})
+	return result.RowsAffected > 0, result.Error
+}
+
+// So a statement that failed to send is claimed again on the next run
+func (s *PayoutCycleService) ReleasePayoutStatement(cycleID uuid.UUID, userID uuid.UUID) error {
+	return s.Db.Where("cycle_id = ? AND user_id = ?", cycleID, userID).Delete(&models.PayoutStatement{}).Error
+}
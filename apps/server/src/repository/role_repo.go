@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleRepo interface {
+	GetRoles(userID uuid.UUID) ([]models.UserRole, error)
+	GrantRole(userID uuid.UUID, role models.RoleName, grantedBy *uuid.UUID) (bool, error)
+	RevokeRole(userID uuid.UUID, role models.RoleName) (bool, error)
+	BanUser(userID uuid.UUID, bannedBy *uuid.UUID, now time.Time) (bool, error)
+}
+
+type RoleService struct {
+	Db *gorm.DB
+}
+
+var _ RoleRepo = &RoleService{}
+
+func NewRoleService(db *gorm.DB) *RoleService {
+	return &RoleService{
+		Db: db,
+	}
+}
+
+func (s *RoleService) GetRoles(userID uuid.UUID) ([]models.UserRole, error) {
+	var roles []models.UserRole
+	err := s.Db.Where("user_id = ?", userID).Order("created_at asc").Find(&roles).Error
+	return roles, err
+}
+
+// False if the user already had the role
+func (s *RoleService) GrantRole(userID uuid.UUID, role models.RoleName, grantedBy *uuid.UUID) (bool, error) {
+	return grantRole(s.Db, userID, role, grantedBy)
+}
+
+func grantRole(db *gorm.DB, userID uuid.UUID, role models.RoleName, grantedBy *uuid.UUID) (bool, error) {
+	res := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserRole{UserID: userID, Role: role, GrantedBy: grantedBy})
+	return res.RowsAffected > 0, res.Error
+}
+
+// False if the user didn't have the role
+func (s *RoleService) RevokeRole(userID uuid.UUID, role models.RoleName) (bool, error) {
+	res := s.Db.Where("user_id = ?", userID).Where("role = ?", role).Delete(&models.UserRole{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// Bans the user and revokes their sessions, so tokens they already have stop working too
+func (s *RoleService) BanUser(userID uuid.UUID, bannedBy *uuid.UUID, now time.Time) (bool, error) {
+	banned := false
+	err := s.Db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		banned, err = grantRole(tx, userID, models.RoleBanned, bannedBy)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", userID).Update("sessions_revoked_at", now.Truncate(time.Second)).Error
+	})
+	return banned, err
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/go-redis/redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DifficultyBucket struct {
+	DifficultyMultiplier int   `json:"difficulty_multiplier"`
+	Count                int64 `json:"count"`
+}
+
+// A rollup bucket that counts fewer requests than were completed according to work_results
+type RollupDrift struct {
+	Hour                 time.Time `json:"hour"`
+	TenantID             string    `json:"tenant_id"`
+	DifficultyMultiplier int       `json:"difficulty_multiplier"`
+	Rollup               int64     `json:"rollup"`
+	Actual               int64     `json:"actual"`
+	Corrected            bool      `json:"corrected"`
+}
+
+// Small drift comes from lost increments and is safe to correct, larger drift needs someone to look at it
+// Rollups can legitimately count more than work_results, which only keeps the latest request per hash
+func (d RollupDrift) WithinTolerance(tolerance float64) bool {
+	return float64(d.Actual-d.Rollup) <= tolerance*float64(d.Actual)
+}
+
+type RollupRepo interface {
+	IncrementDifficultyRollup(tenantID string, difficultyMultiplier int, at time.Time) error
+	BackfillDifficultyRollups() error
+	GetDifficultyDistribution(tenantID string, since time.Time) ([]DifficultyBucket, error)
+	CheckDifficultyRollups(since time.Time, until time.Time, tolerance float64, correct bool) ([]RollupDrift, error)
+}
+
+type RollupService struct {
+	Db *gorm.DB
+}
+
+var _ RollupRepo = &RollupService{}
+
+func NewRollupService(db *gorm.DB) *RollupService {
+	return &RollupService{
+		Db: db,
+	}
+}
+
+func (s *RollupService) IncrementDifficultyRollup(tenantID string, difficultyMultiplier int, at time.Time) error {
+	rollup := &models.DifficultyRollup{
+		Hour:                 at.UTC().Truncate(time.Hour),
+		TenantID:             tenantID,
+		DifficultyMultiplier: difficultyMultiplier,
+		Count:                1,
+	}
+	return s.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hour"}, {Name: "tenant_id"}, {Name: "difficulty_multiplier"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("difficulty_rollups.count + 1")}),
+	}).Create(rollup).Error
+}
+
+// Seed the rollups from existing work results, only if there are none yet
+func (s *RollupService) BackfillDifficultyRollups() error {
+	var count int64
+	if err := s.Db.Model(&models.DifficultyRollup{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return s.Db.Exec("INSERT INTO difficulty_rollups (hour, tenant_id, difficulty_multiplier, count) SELECT date_trunc('hour', created_at), tenant_id, difficulty_multiplier, COUNT(*) FROM work_results GROUP BY 1, 2, 3").Error
+}
+
+// Histogram of requested difficulties since the given time, lowest difficulty first
+func (s *RollupService) GetDifficultyDistribution(tenantID string, since time.Time) ([]DifficultyBucket, error) {
+	since = since.UTC().Truncate(time.Hour)
+	cacheKey := fmt.Sprintf("difficulty_distribution:%s:%d", tenantID, since.Unix())
+	// Check cache
+	res, err := database.GetRedisDB().Get(cacheKey)
+	if err == nil || err == redis.Nil {
+		var buckets []DifficultyBucket
+		err = json.Unmarshal([]byte(res), &buckets)
+		if err == nil {
+			return buckets, nil
+		}
+	}
+
+	buckets := []DifficultyBucket{}
+	err = s.Db.Model(&models.DifficultyRollup{}).Select("difficulty_multiplier, SUM(count) as count").Where("tenant_id = ?", tenantID).Where("hour >= ?", since).Group("difficulty_multiplier").Order("difficulty_multiplier asc").Find(&buckets).Error
+
+	if err == nil {
+		b, err := json.Marshal(buckets)
+		if err == nil {
+			database.GetRedisDB().Set(cacheKey, string(b), time.Minute*5)
+		}
+	}
+
+	return buckets, err
+}
+
+// Compare the rollups with work_results between since and until, optionally correcting the drift within tolerance
+func (s *RollupService) CheckDifficultyRollups(since time.Time, until time.Time, tolerance float64, correct bool) ([]RollupDrift, error) {
+	drifts := []RollupDrift{}
+	err := s.Db.Raw(`SELECT w.hour, w.tenant_id, w.difficulty_multiplier, w.actual, COALESCE(r.count, 0) AS rollup
+		FROM (SELECT date_trunc('hour', created_at) AS hour, tenant_id, difficulty_multiplier, COUNT(*) AS actual FROM work_results WHERE created_at >= ? AND created_at < ? GROUP BY 1, 2, 3) w
+		LEFT JOIN difficulty_rollups r ON r.hour = w.hour AND r.tenant_id = w.tenant_id AND r.difficulty_multiplier = w.difficulty_multiplier
+		WHERE COALESCE(r.count, 0) < w.actual
+		ORDER BY w.hour, w.tenant_id, w.difficulty_multiplier`, since.UTC().Truncate(time.Hour), until.UTC().Truncate(time.Hour)).Scan(&drifts).Error
+	if err != nil || !correct {
+		return drifts, err
+	}
+
+	for i, drift := range drifts {
+		if !drift.WithinTolerance(tolerance) {
+			continue
+		}
+		err := s.Db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "hour"}, {Name: "tenant_id"}, {Name: "difficulty_multiplier"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("GREATEST(difficulty_rollups.count, ?)", drift.Actual)}),
+		}).Create(&models.DifficultyRollup{
+			Hour:                 drift.Hour,
+			TenantID:             drift.TenantID,
+			DifficultyMultiplier: drift.DifficultyMultiplier,
+			Count:                drift.Actual,
+		}).Error
+		if err != nil {
+			return drifts, err
+		}
+		drifts[i].Corrected = true
+		// Don't serve the uncorrected distribution from cache
+		if _, err := database.GetRedisDB().DeleteMatching(fmt.Sprintf("difficulty_distribution:%s:*", drift.TenantID)); err != nil {
+			logging.Errorf(logging.Stats, "Error invalidating difficulty distribution cache %v", err)
+		}
+	}
+	return drifts, nil
+}
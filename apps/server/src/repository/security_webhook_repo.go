@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SecurityWebhookRepo interface {
+	SetSecurityWebhook(userID uuid.UUID, url string) (*models.SecurityWebhook, error)
+	DeleteSecurityWebhook(userID uuid.UUID) error
+	GetSecurityWebhook(userID uuid.UUID) (*models.SecurityWebhook, error)
+}
+
+type SecurityWebhookService struct {
+	Db *gorm.DB
+}
+
+var _ SecurityWebhookRepo = &SecurityWebhookService{}
+
+func NewSecurityWebhookService(db *gorm.DB) *SecurityWebhookService {
+	return &SecurityWebhookService{
+		Db: db,
+	}
+}
+
+func ValidateSecurityWebhook(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return errors.New("Webhook must be an https URL")
+	}
+	return nil
+}
+
+// Replaces the user's previous webhook, every call generates a new signing secret
+func (s *SecurityWebhookService) SetSecurityWebhook(userID uuid.UUID, url string) (*models.SecurityWebhook, error) {
+	if err := ValidateSecurityWebhook(url); err != nil {
+		return nil, err
+	}
+	secret, err := auth.GenerateRandHexString()
+	if err != nil {
+		return nil, err
+	}
+	webhook := &models.SecurityWebhook{
+		UserID: userID,
+		URL:    url,
+		Secret: secret,
+	}
+	err = s.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"url", "secret", "updated_at"}),
+	}).Create(webhook).Error
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *SecurityWebhookService) DeleteSecurityWebhook(userID uuid.UUID) error {
+	return s.Db.Where("user_id = ?", userID).Delete(&models.SecurityWebhook{}).Error
+}
+
+// nil if the user didn't register one
+func (s *SecurityWebhookService) GetSecurityWebhook(userID uuid.UUID) (*models.SecurityWebhook, error) {
+	webhook := &models.SecurityWebhook{}
+	err := s.Db.Where("user_id = ?", userID).First(webhook).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
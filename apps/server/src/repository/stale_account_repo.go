@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/email"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"k8s.io/klog/v2"
+)
+
+type StaleAccountRepo interface {
+	GetDueStaleAccounts(policy models.StaleAccountPolicy, now time.Time) ([]models.User, error)
+	MarkStaleReminderSent(userID uuid.UUID, at time.Time) error
+	DisableAccount(userID uuid.UUID, at time.Time) error
+	AnonymizeAccount(userID uuid.UUID, at time.Time) error
+	SetStaleExempt(userID uuid.UUID, exempt bool) error
+	CreateStaleAccountReport(report *models.StaleAccountReport) error
+	GetStaleAccountReports(args pagination.Args) ([]models.StaleAccountReport, error)
+	CountStaleAccountReports() (int, error)
+}
+
+type StaleAccountService struct {
+	Db *gorm.DB
+}
+
+var _ StaleAccountRepo = &StaleAccountService{}
+
+func NewStaleAccountService(db *gorm.DB) *StaleAccountService {
+	return &StaleAccountService{
+		Db: db,
+	}
+}
+
+// Accounts with a step of the cleanup due, longest signed up first
+func (s *StaleAccountService) GetDueStaleAccounts(policy models.StaleAccountPolicy, now time.Time) ([]models.User, error) {
+	users := []models.User{}
+	err := s.Db.Where("email_verified = false AND stale_exempt = false AND anonymized_at IS NULL").
+		Where(s.Db.Where("disabled_at <= ?", now.Add(-policy.AnonymizeAfter)).
+			Or("disabled_at IS NULL AND stale_reminder_sent_at <= ?", now.Add(-policy.DisableAfter)).
+			Or("disabled_at IS NULL AND stale_reminder_sent_at IS NULL AND created_at <= ?", now.Add(-policy.RemindAfter))).
+		Order("created_at asc").
+		Limit(config.STALE_ACCOUNT_BATCH_SIZE).
+		Find(&users).Error
+	return users, err
+}
+
+func (s *StaleAccountService) MarkStaleReminderSent(userID uuid.UUID, at time.Time) error {
+	return s.Db.Model(&models.User{}).Where("id = ?", userID).Update("stale_reminder_sent_at", at).Error
+}
+
+// Signs the account out everywhere, it can't log in until an admin exempts it
+func (s *StaleAccountService) DisableAccount(userID uuid.UUID, at time.Time) error {
+	return s.Db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"disabled_at":         at,
+		"sessions_revoked_at": at,
+	}).Error
+}
+
+// Replaces the email and drops everything the user entered, along with the IPs of their activity
+// The row stays so work results and payments keep their user
+func (s *StaleAccountService) AnonymizeAccount(userID uuid.UUID, at time.Time) error {
+	anonymous := fmt.Sprintf("anonymized-%s@invalid", userID)
+	return s.Db.Transaction(func(tx *gorm.DB) error {
+		// Columns are set directly, BeforeSave would normalize the old email
+		err := tx.Model(&models.User{}).Where("id = ?", userID).UpdateColumns(map[string]interface{}{
+			"email":             anonymous,
+			"normalized_email":  anonymous,
+			"password":          "",
+			"service_name":      nil,
+			"service_website":   nil,
+			"ban_address":       nil,
+			"two_factor_secret": nil,
+			"anonymized_at":     at,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.AccountEvent{}).Error
+	})
+}
+
+// Exempting an account also enables it again and restarts its cleanup if the exemption is lifted
+func (s *StaleAccountService) SetStaleExempt(userID uuid.UUID, exempt bool) error {
+	updates := map[string]interface{}{"stale_exempt": exempt}
+	if exempt {
+		updates["stale_reminder_sent_at"] = nil
+		updates["disabled_at"] = nil
+	}
+	return s.Db.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error
+}
+
+func (s *StaleAccountService) CreateStaleAccountReport(report *models.StaleAccountReport) error {
+	return s.Db.Create(report).Error
+}
+
+func StaleAccountReportCursor(report models.StaleAccountReport) pagination.Cursor {
+	return pagination.Cursor{Time: report.CreatedAt, ID: report.ID.String()}
+}
+
+// Newest first
+func (s *StaleAccountService) GetStaleAccountReports(args pagination.Args) ([]models.StaleAccountReport, error) {
+	reports := []models.StaleAccountReport{}
+	err := s.Db.Scopes(args.Scope("created_at", "id")).Find(&reports).Error
+	return reports, err
+}
+
+func (s *StaleAccountService) CountStaleAccountReports() (int, error) {
+	var count int64
+	err := s.Db.Model(&models.StaleAccountReport{}).Count(&count).Error
+	return int(count), err
+}
+
+// Takes every account with a step due one step further and records a report, nothing is done if the policy is off
+// Reminders that couldn't be sent are tried again on the next run, so nobody is disabled without being reminded
+func RunStaleAccountCleanup(repo StaleAccountRepo, policy models.StaleAccountPolicy, now time.Time) (*models.StaleAccountReport, error) {
+	if !policy.Enabled() {
+		return nil, nil
+	}
+	users, err := repo.GetDueStaleAccounts(policy, now)
+	if err != nil {
+		return nil, err
+	}
+	report := &models.StaleAccountReport{}
+	for i := range users {
+		user := &users[i]
+		var err error
+		switch policy.NextStep(user, now) {
+		case models.StaleAccountRemind:
+			if err = email.SendStaleAccountEmail(user.Email, user.CreatedAt, policy.DisableOn(now), int(policy.AnonymizeAfter.Hours()/24)); err == nil {
+				err = repo.MarkStaleReminderSent(user.ID, now)
+			}
+			if err == nil {
+				report.Reminded++
+			}
+		case models.StaleAccountDisable:
+			if err = repo.DisableAccount(user.ID, now); err == nil {
+				report.Disabled++
+			}
+		case models.StaleAccountAnonymize:
+			if err = repo.AnonymizeAccount(user.ID, now); err == nil {
+				report.Anonymized++
+			}
+		}
+		if err != nil {
+			klog.Errorf("Error cleaning up stale account %s %v", user.ID, err)
+			report.Failed++
+		}
+	}
+	if err := repo.CreateStaleAccountReport(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -2,24 +2,43 @@ package repository
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/config"
 	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
 	"github.com/bananocoin/boompow/apps/server/src/models"
-	"k8s.io/klog/v2"
+	"github.com/bananocoin/boompow/apps/server/src/privacy"
+	"github.com/bananocoin/boompow/libs/utils"
 )
 
-func UpdateStats(paymentRepo PaymentRepo, workRepo WorkRepo) error {
+func UpdateStats(paymentRepo PaymentRepo, workRepo WorkRepo, tenantRepo TenantRepo) error {
+	tenants, err := tenantRepo.GetAllTenants()
+	if err != nil {
+		logging.Infof(logging.Stats, "Error retrieving tenants for stats sub %v", err)
+		return err
+	}
+	for _, tenant := range tenants {
+		if err := updateTenantStats(paymentRepo, workRepo, tenant.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func updateTenantStats(paymentRepo PaymentRepo, workRepo WorkRepo, tenantID string) error {
 	// Connected clients
-	nConnectedClients, err := database.GetRedisDB().GetNumberConnectedClients()
+	nConnectedClients, err := database.GetRedisDB().GetNumberConnectedClientsForTenant(tenantID)
 	if err != nil {
-		klog.Infof("Error retrieving connected clients for stats sub %v", err)
+		logging.Infof(logging.Stats, "Error retrieving connected clients for stats sub %v", err)
 		return err
 	}
 	// Services
-	services, err := workRepo.GetServiceStats()
+	services, err := workRepo.GetServiceStats(tenantID)
 	if err != nil {
-		klog.Infof("Error retrieving services for stats sub %v", err)
+		logging.Infof(logging.Stats, "Error retrieving services for stats sub %v", err)
 		return err
 	}
 	var serviceStats []*model.StatsServiceType
@@ -31,20 +50,43 @@ func UpdateStats(paymentRepo PaymentRepo, workRepo WorkRepo) error {
 		})
 	}
 	// Top 10
-	top10, err := workRepo.GetTopContributors(100)
+	top10, err := workRepo.GetTopContributors(tenantID, 100)
 	if err != nil {
-		klog.Infof("Error retrieving # services for stats sub %v", err)
+		logging.Infof(logging.Stats, "Error retrieving # services for stats sub %v", err)
 		return err
 	}
+	// The leaderboard is public, so exact totals are optionally blurred
+	blurrer := privacy.NewBlurrer(utils.GetPublicStatsNoisePercent())
+	now := time.Now()
 	var top10Contributors []*model.StatsUserType
 	for _, u := range top10 {
+		totalBan := u.TotalBan
+		if paid, err := strconv.ParseFloat(u.TotalBan, 64); err == nil && blurrer.Enabled() {
+			totalBan = fmt.Sprintf("%.2f", blurrer.Blur(u.BanAddress, paid, now))
+		}
 		top10Contributors = append(top10Contributors, &model.StatsUserType{
 			BanAddress:      u.BanAddress,
-			TotalPaidBanano: u.TotalBan,
+			TotalPaidBanano: totalBan,
 		})
 	}
 	// Total paid
-	totalPaidBan, err := paymentRepo.GetTotalPaidBanano()
-	models.GetStatsInstance().Stats = &model.Stats{ConnectedWorkers: int(nConnectedClients), TotalPaidBanano: fmt.Sprintf("%.2f", totalPaidBan), RegisteredServiceCount: len(services), Top10: top10Contributors, Services: serviceStats}
+	totalPaidBan, err := paymentRepo.GetTotalPaidBanano(tenantID)
+	models.GetStatsInstance().Set(tenantID, &model.Stats{ConnectedWorkers: int(nConnectedClients), TotalPaidBanano: fmt.Sprintf("%.2f", totalPaidBan), RegisteredServiceCount: len(services), Top10: top10Contributors, Services: serviceStats})
 	return nil
 }
+
+// Verify the rollups against work_results over the check window ending at now, correcting small drift and logging the rest
+func CheckStatsConsistency(rollupRepo RollupRepo, correct bool, now time.Time) ([]RollupDrift, error) {
+	drifts, err := rollupRepo.CheckDifficultyRollups(now.Add(-config.STATS_CHECK_WINDOW_HOURS*time.Hour), now, config.STATS_CHECK_TOLERANCE, correct)
+	if err != nil {
+		return nil, err
+	}
+	for _, drift := range drifts {
+		if drift.Corrected {
+			logging.Infof(logging.Stats, "Corrected difficulty rollup drift for tenant %s at %s difficulty %d: %d -> %d", drift.TenantID, drift.Hour, drift.DifficultyMultiplier, drift.Rollup, drift.Actual)
+		} else {
+			logging.Warningf(logging.Stats, "Difficulty rollup drift for tenant %s at %s difficulty %d: rollup %d, work results %d", drift.TenantID, drift.Hour, drift.DifficultyMultiplier, drift.Rollup, drift.Actual)
+		}
+	}
+	return drifts, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// A completed work request, as recorded for stats
+type WorkEvent struct {
+	TenantID             string    `json:"tenant_id"`
+	Hash                 string    `json:"hash"`
+	ProvidedBy           uuid.UUID `json:"provided_by"`
+	RequestedBy          uuid.UUID `json:"requested_by"`
+	DifficultyMultiplier int       `json:"difficulty_multiplier"`
+	SolveLatencyMs       int64     `json:"solve_latency_ms"`
+	Precache             bool      `json:"precache"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+func NewWorkEvent(workResult *models.WorkResult, at time.Time) WorkEvent {
+	return WorkEvent{
+		TenantID:             workResult.TenantID,
+		Hash:                 workResult.Hash,
+		ProvidedBy:           workResult.ProvidedBy,
+		RequestedBy:          workResult.RequestedBy,
+		DifficultyMultiplier: workResult.DifficultyMultiplier,
+		SolveLatencyMs:       workResult.SolveLatencyMs,
+		Precache:             workResult.Precache,
+		CreatedAt:            at,
+	}
+}
+
+// Write and read path of the work stats, so long-term analytics can live outside of the transactional database
+type StatsStore interface {
+	RecordWorkEvent(event WorkEvent) error
+	GetDifficultyDistribution(tenantID string, since time.Time) ([]DifficultyBucket, error)
+	GetResearchRecords(since time.Time) ([]ResearchRecord, error)
+}
+
+// Keeps the stats in the difficulty rollups and work_results tables
+type PostgresStatsStore struct {
+	rollupRepo RollupRepo
+	Db         *gorm.DB
+}
+
+var _ StatsStore = &PostgresStatsStore{}
+
+func NewPostgresStatsStore(db *gorm.DB) *PostgresStatsStore {
+	return &PostgresStatsStore{
+		rollupRepo: NewRollupService(db),
+		Db:         db,
+	}
+}
+
+// The event itself is already in work_results
+func (s *PostgresStatsStore) RecordWorkEvent(event WorkEvent) error {
+	return s.rollupRepo.IncrementDifficultyRollup(event.TenantID, event.DifficultyMultiplier, event.CreatedAt)
+}
+
+func (s *PostgresStatsStore) GetDifficultyDistribution(tenantID string, since time.Time) ([]DifficultyBucket, error) {
+	return s.rollupRepo.GetDifficultyDistribution(tenantID, since)
+}
+
+func (s *PostgresStatsStore) GetResearchRecords(since time.Time) ([]ResearchRecord, error) {
+	var results []ResearchRecord
+	err := s.Db.Model(&models.WorkResult{}).Select("provided_by, requested_by, difficulty_multiplier, solve_latency_ms, precache, created_at").Where("created_at >= ?", since).Order("created_at asc").Find(&results).Error
+	return results, err
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"gorm.io/gorm"
+)
+
+type TenantRepo interface {
+	CreateTenant(id string, name string, prizePool int, maxDifficultyMultiplier int, networkDifficultyMultiplier int, clampExcessDifficulty bool) (*models.Tenant, error)
+	GetTenant(id string) (*models.Tenant, error)
+	GetAllTenants() ([]*models.Tenant, error)
+}
+
+type TenantService struct {
+	Db *gorm.DB
+}
+
+var _ TenantRepo = &TenantService{}
+
+func NewTenantService(db *gorm.DB) *TenantService {
+	return &TenantService{
+		Db: db,
+	}
+}
+
+func (s *TenantService) CreateTenant(id string, name string, prizePool int, maxDifficultyMultiplier int, networkDifficultyMultiplier int, clampExcessDifficulty bool) (*models.Tenant, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == "" {
+		return nil, errors.New("Tenant ID is required")
+	}
+	if prizePool < 0 || maxDifficultyMultiplier < 0 || networkDifficultyMultiplier < 0 {
+		return nil, errors.New("Prize pool and difficulties can't be negative")
+	}
+	tenant := &models.Tenant{
+		ID:                          id,
+		Name:                        name,
+		PrizePool:                   prizePool,
+		MaxDifficultyMultiplier:     maxDifficultyMultiplier,
+		NetworkDifficultyMultiplier: networkDifficultyMultiplier,
+		ClampExcessDifficulty:       clampExcessDifficulty,
+	}
+	if err := s.Db.Create(tenant).Error; err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+func (s *TenantService) GetTenant(id string) (*models.Tenant, error) {
+	if id == "" {
+		id = config.DEFAULT_TENANT_ID
+	}
+	tenant := &models.Tenant{}
+	err := s.Db.Where("id = ?", id).First(tenant).Error
+	return tenant, err
+}
+
+func (s *TenantService) GetAllTenants() ([]*models.Tenant, error) {
+	tenants := []*models.Tenant{}
+	err := s.Db.Order("id asc").Find(&tenants).Error
+	return tenants, err
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTwoFactorEnabled    = errors.New("two factor authentication is already enabled")
+	ErrTwoFactorNotEnabled = errors.New("two factor authentication is not enabled")
+	ErrInvalidTwoFactor    = errors.New("invalid two factor code")
+)
+
+type TwoFactorRepo interface {
+	StartTwoFactorEnrollment(user *models.User) (string, error)
+	ConfirmTwoFactorEnrollment(user *models.User, code string, now time.Time) ([]string, error)
+	VerifyTwoFactor(user *models.User, code string, now time.Time) bool
+	RecoverWithBackupCode(user *models.User, code string, now time.Time) error
+	DisableTwoFactor(user *models.User, code string, now time.Time) error
+	RevokeSessions(userID uuid.UUID, now time.Time) error
+	EncryptPlaintextSecrets() (int, error)
+}
+
+// Secrets are stored encrypted with SecretKey, see utils.GetTwoFactorKey
+type TwoFactorService struct {
+	Db        *gorm.DB
+	SecretKey []byte
+}
+
+var _ TwoFactorRepo = &TwoFactorService{}
+
+func NewTwoFactorService(db *gorm.DB, secretKey []byte) *TwoFactorService {
+	return &TwoFactorService{
+		Db:        db,
+		SecretKey: secretKey,
+	}
+}
+
+// Whether code is the current code of the user's authenticator
+func (s *TwoFactorService) validCode(user *models.User, code string, now time.Time) bool {
+	if user.TwoFactorSecret == nil {
+		return false
+	}
+	secret, err := auth.DecryptSecret(s.SecretKey, *user.TwoFactorSecret)
+	if err != nil {
+		logging.Errorf(logging.Auth, "Error decrypting the two factor secret of %s %v", user.ID, err)
+		return false
+	}
+	return auth.ValidateTOTP(secret, code, now)
+}
+
+// Generates a new secret, it's only required at login once the enrollment is confirmed
+func (s *TwoFactorService) StartTwoFactorEnrollment(user *models.User) (string, error) {
+	if user.TwoFactorEnabled {
+		return "", ErrTwoFactorEnabled
+	}
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := auth.EncryptSecret(s.SecretKey, secret)
+	if err != nil {
+		return "", err
+	}
+	if err := s.Db.Model(&models.User{}).Where("id = ?", user.ID).Update("two_factor_secret", encrypted).Error; err != nil {
+		return "", err
+	}
+	user.TwoFactorSecret = &encrypted
+	return secret, nil
+}
+
+// Enables two factor authentication once the user proved their authenticator works, returns the backup codes
+// Only the hashes of the codes are stored, they can't be shown again
+func (s *TwoFactorService) ConfirmTwoFactorEnrollment(user *models.User, code string, now time.Time) ([]string, error) {
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	if !s.validCode(user, code, now) {
+		return nil, ErrInvalidTwoFactor
+	}
+	codes, err := auth.GenerateBackupCodes(config.BACKUP_CODE_COUNT)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.BackupCode{}).Error; err != nil {
+			return err
+		}
+		backupCodes := make([]models.BackupCode, len(codes))
+		for i, code := range codes {
+			backupCodes[i] = models.BackupCode{UserID: user.ID, CodeHash: auth.HashBackupCode(code)}
+		}
+		if err := tx.Create(&backupCodes).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Update("two_factor_enabled", true).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	user.TwoFactorEnabled = true
+	return codes, nil
+}
+
+func (s *TwoFactorService) VerifyTwoFactor(user *models.User, code string, now time.Time) bool {
+	return user.TwoFactorEnabled && s.validCode(user, code, now)
+}
+
+// Uses up a backup code to turn two factor authentication off and revoke every session
+// The user signs in with just their password afterwards and can enroll a new authenticator
+func (s *TwoFactorService) RecoverWithBackupCode(user *models.User, code string, now time.Time) error {
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	return s.Db.Transaction(func(tx *gorm.DB) error {
+		// Only one request can use a code
+		res := tx.Model(&models.BackupCode{}).Where("user_id = ?", user.ID).Where("code_hash = ?", auth.HashBackupCode(code)).Where("used_at IS NULL").Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrInvalidTwoFactor
+		}
+		// The other codes belong to the lost authenticator
+		if err := tx.Where("user_id = ?", user.ID).Where("used_at IS NULL").Delete(&models.BackupCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"two_factor_enabled":  false,
+			"two_factor_secret":   nil,
+			"sessions_revoked_at": now.Truncate(time.Second),
+		}).Error
+	})
+}
+
+// Turns two factor authentication off with a code from the authenticator, the backup codes go with it
+func (s *TwoFactorService) DisableTwoFactor(user *models.User, code string, now time.Time) error {
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if !s.validCode(user, code, now) {
+		return ErrInvalidTwoFactor
+	}
+	err := s.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.BackupCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"two_factor_enabled": false,
+			"two_factor_secret":  nil,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+	user.TwoFactorEnabled = false
+	user.TwoFactorSecret = nil
+	return nil
+}
+
+// Encrypts the secrets stored before they were encrypted, returns how many there were
+func (s *TwoFactorService) EncryptPlaintextSecrets() (int, error) {
+	var users []models.User
+	if err := s.Db.Select("id", "two_factor_secret").Where("two_factor_secret IS NOT NULL").Where("two_factor_secret NOT LIKE ?", "enc:%").Find(&users).Error; err != nil {
+		return 0, err
+	}
+	for _, user := range users {
+		encrypted, err := auth.EncryptSecret(s.SecretKey, *user.TwoFactorSecret)
+		if err != nil {
+			return 0, err
+		}
+		// Unless it was replaced in the meantime
+		if err := s.Db.Model(&models.User{}).Where("id = ?", user.ID).Where("two_factor_secret = ?", *user.TwoFactorSecret).Update("two_factor_secret", encrypted).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(users), nil
+}
+
+// Every token issued before now stops working, tokens carry their issue time to the second
+func (s *TwoFactorService) RevokeSessions(userID uuid.UUID, now time.Time) error {
+	return s.Db.Model(&models.User{}).Where("id = ?", userID).Update("sessions_revoked_at", now.Truncate(time.Second)).Error
+}
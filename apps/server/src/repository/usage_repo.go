@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/email"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"k8s.io/klog/v2"
+)
+
+type UsageRepo interface {
+	RecordUsage(userID uuid.UUID, tenantID string, difficultyMultiplier int, cached bool, at time.Time) error
+	GetUsage(userID uuid.UUID, month time.Time) ([]models.UsageRollup, error)
+	CreateUsageStatements(before time.Time) (int64, error)
+	GetUsageStatements(userID uuid.UUID, limit int) ([]models.UsageStatement, error)
+	GetUnsentUsageStatements() ([]models.UsageStatement, error)
+	MarkUsageStatementEmailed(id uuid.UUID, at time.Time) error
+}
+
+type UsageService struct {
+	Db *gorm.DB
+}
+
+var _ UsageRepo = &UsageService{}
+
+func NewUsageService(db *gorm.DB) *UsageService {
+	return &UsageService{
+		Db: db,
+	}
+}
+
+func (s *UsageService) RecordUsage(userID uuid.UUID, tenantID string, difficultyMultiplier int, cached bool, at time.Time) error {
+	rollup := &models.UsageRollup{
+		Month:                models.StartOfMonth(at),
+		UserID:               userID,
+		DifficultyMultiplier: difficultyMultiplier,
+		TenantID:             tenantID,
+	}
+	column := "requests"
+	rollup.Requests = 1
+	if cached {
+		column = "cached"
+		rollup.Requests, rollup.Cached = 0, 1
+	}
+	return s.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "month"}, {Name: "user_id"}, {Name: "difficulty_multiplier"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{column: gorm.Expr("usage_rollups." + column + " + 1")}),
+	}).Create(rollup).Error
+}
+
+// Usage of the month that month is in, lowest difficulty first
+func (s *UsageService) GetUsage(userID uuid.UUID, month time.Time) ([]models.UsageRollup, error) {
+	var usage []models.UsageRollup
+	err := s.Db.Where("user_id = ?", userID).Where("month = ?", models.StartOfMonth(month)).Order("difficulty_multiplier").Find(&usage).Error
+	return usage, err
+}
+
+// Creates the statements of all months that started before before and don't have one yet, returns how many were created
+func (s *UsageService) CreateUsageStatements(before time.Time) (int64, error) {
+	var totals []models.UsageStatement
+	err := s.Db.Model(&models.UsageRollup{}).
+		Select("user_id, month, MIN(tenant_id) AS tenant_id, SUM(requests) AS requests, SUM(cached) AS cached").
+		Where("month < ?", models.StartOfMonth(before)).
+		Where("NOT EXISTS (SELECT 1 FROM usage_statements WHERE usage_statements.user_id = usage_rollups.user_id AND usage_statements.month = usage_rollups.month)").
+		Group("user_id, month").
+		Scan(&totals).Error
+	if err != nil || len(totals) == 0 {
+		return 0, err
+	}
+	result := s.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&totals)
+	return result.RowsAffected, result.Error
+}
+
+// Newest first
+func (s *UsageService) GetUsageStatements(userID uuid.UUID, limit int) ([]models.UsageStatement, error) {
+	var statements []models.UsageStatement
+	err := s.Db.Where("user_id = ?", userID).Order("month desc").Find(&statements).Error
+	return statements, err
+}
+
+func (s *UsageService) GetUnsentUsageStatements() ([]models.UsageStatement, error) {
+	var statements []models.UsageStatement
+	err := s.Db.Where("emailed_at IS NULL").Order("month").Find(&statements).Error
+	return statements, err
+}
+
+func (s *UsageService) MarkUsageStatementEmailed(id uuid.UUID, at time.Time) error {
+	return s.Db.Model(&models.UsageStatement{}).Where("id = ?", id).Update("emailed_at", at).Error
+}
+
+// Closes the months that have ended and emails their statements, statements that failed to send are retried on the next run
+func SendUsageStatements(usageRepo UsageRepo, userRepo UserRepo, now time.Time) error {
+	if _, err := usageRepo.CreateUsageStatements(now); err != nil {
+		return err
+	}
+	statements, err := usageRepo.GetUnsentUsageStatements()
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		user, err := userRepo.GetUser(&statement.UserID, nil)
+		if err != nil {
+			klog.Errorf("Error getting user for usage statement %s %v", statement.ID, err)
+			continue
+		}
+		usage, err := usageRepo.GetUsage(statement.UserID, statement.Month)
+		if err != nil {
+			return err
+		}
+		if err := email.SendUsageStatementEmail(user.Email, &statement, usage); err != nil {
+			continue
+		}
+		if err := usageRepo.MarkUsageStatementEmailed(statement.ID, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
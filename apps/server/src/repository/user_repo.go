@@ -9,9 +9,12 @@ import (
 	"time"
 
 	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/config"
 	"github.com/bananocoin/boompow/apps/server/src/database"
 	"github.com/bananocoin/boompow/apps/server/src/email"
+	"github.com/bananocoin/boompow/apps/server/src/filter"
 	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
 	"github.com/bananocoin/boompow/libs/utils/auth"
 	"github.com/bananocoin/boompow/libs/utils/validation"
 	"github.com/google/uuid"
@@ -21,7 +24,7 @@ import (
 )
 
 type UserRepo interface {
-	CreateUser(userInput *model.UserInput, doEmail bool) (*models.User, error)
+	CreateUser(userInput *model.UserInput, tenantID string, doEmail bool) (*models.User, error)
 	SendConfirmEmailEmail(userEmail string, userType models.UserType, actuallyDoEmail bool) error
 	CreateMockUsers() error
 	DeleteUser(id uuid.UUID) error
@@ -31,10 +34,22 @@ type UserRepo interface {
 	VerifyEmailToken(verifyEmail *model.VerifyEmailInput) (bool, error)
 	VerifyService(verifyService *model.VerifyServiceInput) (bool, error)
 	GenerateResetPasswordRequest(resetPasswordInput *model.ResetPasswordInput, doEmail bool) (string, error)
+	CheckResetPasswordToken(token string) (string, error)
 	GenerateServiceToken() string
-	CreateService(email string, serviceName string, serviceWebsite string) (string, error)
+	CreateService(email string, serviceName string, serviceWebsite string, tenantID string) (string, error)
+	EnsureAdminUser(email string, password string, tenantID string) (bool, error)
+	EnsureService(email string, serviceName string, serviceWebsite string, tenantID string, token string) (bool, string, error)
+	EnsureUser(user *models.User, password string) (bool, error)
 	GetNumberServices() (int64, error)
 	ChangePassword(email string, userInput *model.ChangePasswordInput) error
+	SetIncludeWorkTimings(id uuid.UUID, enabled bool) error
+	SetPayoutStatementEmails(id uuid.UUID, enabled bool) error
+	SetDifficultyRange(id uuid.UUID, min int, max int) error
+	SetRateLimit(id uuid.UUID, perMinute *int) error
+	SetCanRequestWork(id uuid.UUID, enabled bool) (bool, error)
+	SetEmailVerified(id uuid.UUID, verified bool) (bool, error)
+	ListUsers(query *filter.Query, args pagination.Args) ([]models.User, error)
+	CountUsers(query *filter.Query) (int, error)
 }
 
 type UserService struct {
@@ -92,7 +107,7 @@ func (s *UserService) CreateMockUsers() error {
 	return nil
 }
 
-func (s *UserService) CreateService(email string, serviceName string, serviceWebsite string) (string, error) {
+func (s *UserService) CreateService(email string, serviceName string, serviceWebsite string, tenantID string) (string, error) {
 	// Validate
 	if !validation.IsValidEmail(email) {
 		return "", errors.New("Invalid email")
@@ -109,10 +124,27 @@ func (s *UserService) CreateService(email string, serviceName string, serviceWeb
 	}
 	password := b.String()
 
+	user, err := s.createServiceUser(email, password, serviceName, serviceWebsite, tenantID)
+	if err != nil {
+		return "", err
+	}
+
+	// Create token
+	// Generate token
+	token := s.GenerateServiceToken()
+
+	if err := database.GetRedisDB().AddServiceToken(user.ID, token); err != nil {
+		return "", fmt.Errorf("error generating token")
+	}
+
+	return token, nil
+}
+
+func (s *UserService) createServiceUser(email string, password string, serviceName string, serviceWebsite string, tenantID string) (*models.User, error) {
 	// Hash password
 	hashedPassword, err := auth.HashPassword(password)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	user := &models.User{
@@ -123,28 +155,20 @@ func (s *UserService) CreateService(email string, serviceName string, serviceWeb
 		ServiceWebsite: &serviceWebsite,
 		EmailVerified:  true,
 		CanRequestWork: true,
+		TenantID:       tenantID,
 	}
 	err = s.Db.Create(&user).Error
 
 	if err != nil {
 		if strings.Contains(err.(*pgconn.PgError).Message, "duplicate key value violates unique constraint") {
-			return "", errors.New("Email already exists")
+			return nil, errors.New("Email already exists")
 		}
-		return "", errors.New("Unknown error creating user")
-	}
-
-	// Create token
-	// Generate token
-	token := s.GenerateServiceToken()
-
-	if err := database.GetRedisDB().AddServiceToken(user.ID, token); err != nil {
-		return "", fmt.Errorf("error generating token")
+		return nil, errors.New("Unknown error creating user")
 	}
-
-	return token, nil
+	return user, nil
 }
 
-func (s *UserService) CreateUser(userInput *model.UserInput, doEmail bool) (*models.User, error) {
+func (s *UserService) CreateUser(userInput *model.UserInput, tenantID string, doEmail bool) (*models.User, error) {
 	// Validate
 	if !validation.IsValidEmail(userInput.Email) {
 		return nil, errors.New("Invalid email")
@@ -179,6 +203,15 @@ func (s *UserService) CreateUser(userInput *model.UserInput, doEmail bool) (*mod
 		return nil, err
 	}
 
+	// Aliases of an existing account's mailbox would be a second account for the same person
+	var aliases int64
+	if err := s.Db.Model(&models.User{}).Where("normalized_email = ?", models.NormalizeEmail(userInput.Email)).Count(&aliases).Error; err != nil {
+		return nil, errors.New("Unknown error creating user")
+	}
+	if aliases > 0 {
+		return nil, errors.New("Email already exists")
+	}
+
 	// Hash password
 	hashedPassword, err := auth.HashPassword(userInput.Password)
 	if err != nil {
@@ -192,6 +225,7 @@ func (s *UserService) CreateUser(userInput *model.UserInput, doEmail bool) (*mod
 		BanAddress:     userInput.BanAddress,
 		ServiceName:    userInput.ServiceName,
 		ServiceWebsite: userInput.ServiceWebsite,
+		TenantID:       tenantID,
 	}
 	if userInput.BanAddress != nil {
 		user.BanAddress = userInput.BanAddress
@@ -246,13 +280,14 @@ func (s *UserService) GenerateResetPasswordRequest(resetPasswordInput *model.Res
 	}
 
 	// Generate reset password token and store in database
-	resetPasswordToken, err := auth.GenerateToken(strings.ToLower(user.Email), time.Now)
+	resetPasswordToken, err := auth.GenerateTokenWithTTL(strings.ToLower(user.Email), config.EMAIL_CONFIRMATION_TOKEN_VALID_MINUTES*time.Minute, time.Now)
 	if err != nil {
 		return "", err
 	}
 	resetPasswordToken = fmt.Sprintf("resetpassword:%s", resetPasswordToken)
 
-	database.GetRedisDB().SetResetPasswordToken(resetPasswordInput.Email, resetPasswordToken)
+	// Keyed like the token's email, so the auth middleware finds it
+	database.GetRedisDB().SetResetPasswordToken(strings.ToLower(user.Email), resetPasswordToken)
 	// Send email with reset password token token
 	if doEmail {
 		email.SendResetPasswordEmail(resetPasswordInput.Email, resetPasswordToken)
@@ -260,6 +295,25 @@ func (s *UserService) GenerateResetPasswordRequest(resetPasswordInput *model.Res
 	return resetPasswordToken, err
 }
 
+// CheckResetPasswordToken returns the email of a reset password token if it's the latest one sent to it and still unused
+func (s *UserService) CheckResetPasswordToken(token string) (string, error) {
+	if !strings.HasPrefix(token, "resetpassword:") {
+		return "", database.ErrResetPasswordTokenInvalid
+	}
+	email, err := auth.ParseToken(strings.TrimPrefix(token, "resetpassword:"), time.Now)
+	if err != nil {
+		return "", database.ErrResetPasswordTokenInvalid
+	}
+	valid, err := database.GetRedisDB().ResetPasswordTokenValid(email, token)
+	if err != nil {
+		return "", err
+	}
+	if !valid {
+		return "", database.ErrResetPasswordTokenInvalid
+	}
+	return email, nil
+}
+
 func (s *UserService) ChangePassword(email string, userInput *model.ChangePasswordInput) error {
 	// Hash password
 	hashedPassword, err := auth.HashPassword(userInput.NewPassword)
@@ -268,9 +322,6 @@ func (s *UserService) ChangePassword(email string, userInput *model.ChangePasswo
 	}
 
 	if res := s.Db.Model(&models.User{}).Where("email = ?", email).Update("password", hashedPassword); res.RowsAffected > 0 {
-		// Email has been marked verified, delete the token
-		database.GetRedisDB().DeleteResetPasswordToken(email)
-
 		return nil
 	}
 	return errors.New("Could not change password")
@@ -285,7 +336,8 @@ func (s *UserService) VerifyEmailToken(verifyEmail *model.VerifyEmailInput) (boo
 		return false, errors.New("Invalid verification code")
 	}
 
-	if res := s.Db.Model(&models.User{}).Where("email = ?", lowerEmail).Update("email_verified", true); res.RowsAffected > 0 {
+	// Disabled accounts have to be exempted by an admin first
+	if res := s.Db.Model(&models.User{}).Where("email = ? AND disabled_at IS NULL", lowerEmail).Update("email_verified", true); res.RowsAffected > 0 {
 		// Email has been marked verified, delete the token
 		database.GetRedisDB().DeleteConfirmationToken(lowerEmail)
 		// Get User
@@ -345,11 +397,12 @@ func (s *UserService) GetUser(id *uuid.UUID, email *string) (*models.User, error
 	}
 	var err error
 	user := &models.User{}
+	// Roles decide what the user is allowed to do, so they're always needed
 	if id != nil {
-		err = s.Db.Where("id = ?", &id).First(user).Error
+		err = s.Db.Preload("Roles").Where("id = ?", &id).First(user).Error
 		return user, err
 	}
-	err = s.Db.Where("email = ?", &email).First(user).Error
+	err = s.Db.Preload("Roles").Where("email = ?", &email).First(user).Error
 	return user, err
 }
 
@@ -367,11 +420,44 @@ func (s *UserService) GetNumberServices() (int64, error) {
 	return count, nil
 }
 
+func (s *UserService) SetIncludeWorkTimings(id uuid.UUID, enabled bool) error {
+	return s.Db.Model(&models.User{}).Where("id = ?", id).Update("include_work_timings", enabled).Error
+}
+
+func (s *UserService) SetPayoutStatementEmails(id uuid.UUID, enabled bool) error {
+	return s.Db.Model(&models.User{}).Where("id = ?", id).Update("payout_statement_emails", enabled).Error
+}
+
+// 0 clears a bound
+func (s *UserService) SetDifficultyRange(id uuid.UUID, min int, max int) error {
+	return s.Db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{"min_difficulty_multiplier": min, "max_difficulty_multiplier": max}).Error
+}
+
+// Nil gives the user the limit of their class again
+func (s *UserService) SetRateLimit(id uuid.UUID, perMinute *int) error {
+	return s.Db.Model(&models.User{}).Where("id = ?", id).Update("rate_limit_per_minute", perMinute).Error
+}
+
+// Returns false if it already was set this way
+func (s *UserService) SetCanRequestWork(id uuid.UUID, enabled bool) (bool, error) {
+	res := s.Db.Model(&models.User{}).Where("id = ? AND can_request_work <> ?", id, enabled).Update("can_request_work", enabled)
+	return res.RowsAffected > 0, res.Error
+}
+
+// Returns false if it already was set this way
+func (s *UserService) SetEmailVerified(id uuid.UUID, verified bool) (bool, error) {
+	res := s.Db.Model(&models.User{}).Where("id = ? AND email_verified <> ?", id, verified).Update("email_verified", verified)
+	return res.RowsAffected > 0, res.Error
+}
+
 // Compare password to hashed password, return true if match false otherwise
 func (s *UserService) Authenticate(loginInput *model.LoginInput) *models.User {
 	user := &models.User{}
 	emailLower := strings.ToLower(loginInput.Email)
-	err := s.Db.Where("lower(email) = ?", &emailLower).First(user).Error
+	err := s.Db.Preload("Roles").Where("lower(email) = ?", &emailLower).First(user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		user, err = s.getUserByAlias(loginInput.Email)
+	}
 
 	if err != nil {
 		return nil
@@ -383,7 +469,165 @@ func (s *UserService) Authenticate(loginInput *model.LoginInput) *models.User {
 	return nil
 }
 
+// The account whose mailbox the email is an alias of, not found if several accounts share it
+func (s *UserService) getUserByAlias(email string) (*models.User, error) {
+	var users []models.User
+	if err := s.Db.Preload("Roles").Where("normalized_email = ?", models.NormalizeEmail(email)).Limit(2).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) != 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &users[0], nil
+}
+
 // Generate a service token (for services to request work)
 func (s *UserService) GenerateServiceToken() string {
 	return fmt.Sprintf("service:%s", uuid.New().String())
 }
+
+// Creates the admin account if it doesn't exist yet, returns whether it was created
+// An existing account only gets its email verified, so changing its password isn't undone on the next start
+func (s *UserService) EnsureAdminUser(email string, password string, tenantID string) (bool, error) {
+	email = strings.ToLower(email)
+	if !validation.IsValidEmail(email) {
+		return false, errors.New("Invalid email")
+	}
+	existing, err := s.GetUser(nil, &email)
+	if err == nil {
+		if existing.EmailVerified {
+			return false, nil
+		}
+		return false, s.Db.Model(existing).Update("email_verified", true).Error
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	if err := validation.ValidatePassword(password); err != nil {
+		return false, err
+	}
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return false, err
+	}
+	user := &models.User{
+		Email:         email,
+		Password:      hashedPassword,
+		Type:          models.REQUESTER,
+		EmailVerified: true,
+		TenantID:      tenantID,
+	}
+	if err := s.Db.Create(user).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Creates the user as given if nobody has its email yet, returns whether it was created
+func (s *UserService) EnsureUser(user *models.User, password string) (bool, error) {
+	user.Email = strings.ToLower(user.Email)
+	if !validation.IsValidEmail(user.Email) {
+		return false, errors.New("Invalid email")
+	}
+	_, err := s.GetUser(nil, &user.Email)
+	if err == nil {
+		return false, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return false, err
+	}
+	user.Password = hashedPassword
+	if err := s.Db.Create(user).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Creates the service if it doesn't exist yet and makes token its service token, returns whether it was created and the token
+// Without a token the existing one is kept, or a new one generated
+func (s *UserService) EnsureService(email string, serviceName string, serviceWebsite string, tenantID string, token string) (bool, string, error) {
+	if !validation.IsValidEmail(email) {
+		return false, "", errors.New("Invalid email")
+	}
+	if token != "" && !strings.HasPrefix(token, "service:") {
+		return false, "", errors.New("Service tokens must start with service:")
+	}
+	email = strings.ToLower(email)
+	created := false
+	user, err := s.GetUser(nil, &email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Nobody logs in as a service, the password only has to be unguessable
+		user, err = s.createServiceUser(email, uuid.NewString(), serviceName, serviceWebsite, tenantID)
+		created = true
+	}
+	if err != nil {
+		return false, "", err
+	}
+
+	current, err := database.GetRedisDB().GetServiceTokenForUser(user.ID)
+	if err == nil && (token == "" || token == current) {
+		return created, current, nil
+	}
+	if err == nil {
+		// Rotated
+		if err := database.GetRedisDB().RemoveServiceToken(current); err != nil {
+			return created, "", err
+		}
+	}
+	if token == "" {
+		token = s.GenerateServiceToken()
+	}
+	if err := database.GetRedisDB().AddServiceToken(user.ID, token); err != nil {
+		return created, "", err
+	}
+	return created, token, nil
+}
+
+// What admins can filter and sort the user list on, newest first by default
+var UserList = filter.List{
+	Fields: map[string]filter.Field{
+		"email":                  {Column: "email", Type: filter.String},
+		"type":                   {Column: "type", Type: filter.Enum, Values: []string{string(models.PROVIDER), string(models.REQUESTER)}},
+		"tenant_id":              {Column: "tenant_id", Type: filter.String},
+		"email_verified":         {Column: "email_verified", Type: filter.Bool},
+		"can_request_work":       {Column: "can_request_work", Type: filter.Bool},
+		"two_factor_enabled":     {Column: "two_factor_enabled", Type: filter.Bool},
+		"stale_exempt":           {Column: "stale_exempt", Type: filter.Bool},
+		"invalid_result_count":   {Column: "invalid_result_count", Type: filter.Int},
+		"service_name":           {Column: "service_name", Type: filter.String, Nullable: true},
+		"created_at":             {Column: "created_at", Type: filter.Time, Sortable: true},
+		"updated_at":             {Column: "updated_at", Type: filter.Time, Sortable: true},
+		"last_provided_work_at":  {Column: "last_provided_work_at", Type: filter.Time, Nullable: true},
+		"last_requested_work_at": {Column: "last_requested_work_at", Type: filter.Time, Nullable: true},
+		"disabled_at":            {Column: "disabled_at", Type: filter.Time, Nullable: true},
+	},
+	IDColumn:    "id",
+	DefaultSort: filter.Sort{Field: "created_at", Descending: true},
+}
+
+// The cursor of a user in the list sorted by sort
+func UserCursor(sort filter.Sort) func(models.User) pagination.Cursor {
+	return func(user models.User) pagination.Cursor {
+		at := user.CreatedAt
+		if sort.Field == "updated_at" {
+			at = user.UpdatedAt
+		}
+		return pagination.Cursor{Time: at, ID: user.ID.String()}
+	}
+}
+
+// With their roles
+func (s *UserService) ListUsers(query *filter.Query, args pagination.Args) ([]models.User, error) {
+	users := []models.User{}
+	err := s.Db.Scopes(query.Scope(args)).Preload("Roles").Find(&users).Error
+	return users, err
+}
+
+func (s *UserService) CountUsers(query *filter.Query) (int, error) {
+	var count int64
+	err := query.Where(s.Db.Model(&models.User{})).Count(&count).Error
+	return int(count), err
+}
@@ -4,18 +4,27 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/bananocoin/boompow/apps/server/src/config"
 	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/filter"
+	"github.com/bananocoin/boompow/apps/server/src/logging"
 	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
 	serializableModels "github.com/bananocoin/boompow/libs/models"
 	"github.com/bananocoin/boompow/libs/utils"
+	"github.com/bananocoin/boompow/libs/utils/clock"
 	"github.com/bananocoin/boompow/libs/utils/number"
 	"github.com/bananocoin/boompow/libs/utils/validation"
 	"github.com/go-redis/redis/v9"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
-	"k8s.io/klog/v2"
+	"gorm.io/gorm/clause"
 )
 
 type WorkMessage struct {
@@ -26,6 +35,10 @@ type WorkMessage struct {
 	Result               string `json:"result"`
 	DifficultyMultiplier int    `json:"difficulty_multiplier"`
 	Precache             bool   `json:"precache"`
+	SolveLatencyMs       int64  `json:"solve_latency_ms"`
+	TenantID             string `json:"tenant_id"`
+	// Share of the difficulty credited towards payouts, 0 is full credit
+	CreditPercent int `json:"credit_percent"`
 }
 
 type WorkRepo interface {
@@ -33,28 +46,51 @@ type WorkRepo interface {
 	GetWorkRecord(hash string) (*models.WorkResult, error)
 	StatsWorker(statsChan <-chan WorkMessage, blockAwardedChan *chan serializableModels.ClientMessage)
 	GetUnpaidWorkSumForUser(email string) (int, error)
-	GetUnpaidWorkSum() (int, error)
-	RetrieveWorkFromCache(hash string, difficultyMultiplier int) (string, error)
-	GetUnpaidWorkCount(tx *gorm.DB) ([]UnpaidWorkResult, error)
-	GetUnpaidWorkCountAndMarkAllPaid(tx *gorm.DB) ([]UnpaidWorkResult, error)
-	GetTopContributors(limit int) ([]Top10Result, error)
-	GetServiceStats() ([]ServicesResult, error)
+	GetUnpaidWorkSum(tenantID string) (int, error)
+	RetrieveWorkFromCache(tenantID string, hash string, difficultyMultiplier int) (string, error)
+	SubmitWork(submittedBy *models.User, hash string, result string, difficultyMultiplier int) (bool, error)
+	GetUnpaidWorkCount(tx *gorm.DB, tenantID string) ([]UnpaidWorkResult, error)
+	EstimatePayouts(tenantID string, prizePool int) ([]UnpaidWorkResult, []*big.Int, error)
+	GetUnpaidWorkCountAndMarkAllPaid(tx *gorm.DB, tenantID string) ([]UnpaidWorkResult, error)
+	GetTopContributors(tenantID string, limit int) ([]Top10Result, error)
+	GetServiceStats(tenantID string) ([]ServicesResult, error)
+	GetResearchRecords(since time.Time) ([]ResearchRecord, error)
+	ListWorkResults(query *filter.Query, args pagination.Args) ([]models.WorkResult, error)
+	CountWorkResults(query *filter.Query) (int, error)
 }
 
 type WorkService struct {
-	Db       *gorm.DB
-	userRepo UserRepo
+	Db         *gorm.DB
+	userRepo   UserRepo
+	tenantRepo TenantRepo
+	statsStore StatsStore
+	usageRepo  UsageRepo
+	clock      clock.Clock
 }
 
 var _ WorkRepo = &WorkService{}
 
 func NewWorkService(db *gorm.DB, userRepo UserRepo) *WorkService {
 	return &WorkService{
-		Db:       db,
-		userRepo: userRepo,
+		Db:         db,
+		userRepo:   userRepo,
+		tenantRepo: NewTenantService(db),
+		statsStore: NewPostgresStatsStore(db),
+		usageRepo:  NewUsageService(db),
+		clock:      clock.Real,
 	}
 }
 
+// Work events go to the Postgres rollups unless another store is set
+func (s *WorkService) SetStatsStore(store StatsStore) {
+	s.statsStore = store
+}
+
+// Work is stamped with the wall clock unless another clock is set
+func (s *WorkService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
 func (s *WorkService) SaveOrUpdateWorkResult(workMessage WorkMessage) (*models.WorkResult, error) {
 	// Get provider and requester
 	provider, err := s.userRepo.GetUser(nil, &workMessage.ProvidedByEmail)
@@ -66,6 +102,15 @@ func (s *WorkService) SaveOrUpdateWorkResult(workMessage WorkMessage) (*models.W
 	if err != nil {
 		return nil, err
 	}
+	// Work never crosses pools
+	if provider.TenantID != requester.TenantID {
+		return nil, fmt.Errorf("provider tenant %s does not match requester tenant %s", provider.TenantID, requester.TenantID)
+	}
+
+	creditPercent := workMessage.CreditPercent
+	if creditPercent == 0 {
+		creditPercent = 100
+	}
 
 	// See if exists
 	var workResult models.WorkResult
@@ -82,6 +127,9 @@ func (s *WorkService) SaveOrUpdateWorkResult(workMessage WorkMessage) (*models.W
 			ProvidedBy:           provider.ID,
 			RequestedBy:          requester.ID,
 			Precache:             workMessage.Precache,
+			SolveLatencyMs:       workMessage.SolveLatencyMs,
+			TenantID:             requester.TenantID,
+			CreditPercent:        creditPercent,
 		}
 
 		err = s.Db.Create(&workRequestDb).Error
@@ -91,25 +139,35 @@ func (s *WorkService) SaveOrUpdateWorkResult(workMessage WorkMessage) (*models.W
 		}
 
 		// Cache in redis temporarily for faster lookup
-		database.GetRedisDB().CacheWork(workMessage.Hash, workMessage.Result)
+		database.GetRedisDB().CacheWork(requester.TenantID, workMessage.Hash, workMessage.Result)
 	} else if err == nil {
 		// Update record
-		err = s.Db.Model(&workResult).Updates(map[string]interface{}{"difficulty_multiplier": workMessage.DifficultyMultiplier, "result": workMessage.Result, "provided_by": provider.ID, "requested_by": requester.ID, "awarded": false}).Error
+		err = s.Db.Model(&workResult).Updates(map[string]interface{}{"difficulty_multiplier": workMessage.DifficultyMultiplier, "result": workMessage.Result, "provided_by": provider.ID, "requested_by": requester.ID, "awarded": false, "solve_latency_ms": workMessage.SolveLatencyMs, "tenant_id": requester.TenantID, "credit_percent": creditPercent}).Error
 		if err != nil {
 			return nil, err
 		}
+		workResult.DifficultyMultiplier = workMessage.DifficultyMultiplier
+		workResult.Result = workMessage.Result
+		workResult.ProvidedBy = provider.ID
+		workResult.RequestedBy = requester.ID
+		workResult.Awarded = false
+		workResult.SolveLatencyMs = workMessage.SolveLatencyMs
+		workResult.TenantID = requester.TenantID
+		workResult.CreditPercent = creditPercent
+		workRequestDb = &workResult
 	} else {
 		return nil, err
 	}
 
 	// Update timestamps
-	err = s.Db.Model(&models.User{}).Where("id = ?", provider.ID).Updates(map[string]interface{}{"last_provided_work_at": time.Now()}).Error
+	now := s.clock.Now()
+	err = s.Db.Model(&models.User{}).Where("id = ?", provider.ID).Updates(map[string]interface{}{"last_provided_work_at": now}).Error
 	if err != nil {
-		klog.Errorf("Failed to update last_provided_work_at for provider %v", err)
+		logging.Errorf(logging.Stats, "Failed to update last_provided_work_at for provider %v", err)
 	}
-	err = s.Db.Model(&models.User{}).Where("id = ?", requester.ID).Updates(map[string]interface{}{"last_requested_work_at": time.Now()}).Error
+	err = s.Db.Model(&models.User{}).Where("id = ?", requester.ID).Updates(map[string]interface{}{"last_requested_work_at": now}).Error
 	if err != nil {
-		klog.Errorf("Failed to update last_requested_work_at for provider %v", err)
+		logging.Errorf(logging.Stats, "Failed to update last_requested_work_at for provider %v", err)
 	}
 
 	return workRequestDb, err
@@ -130,9 +188,10 @@ type ServicesResult struct {
 	ServiceWebsite string `json:"service_website"`
 }
 
-func (s *WorkService) GetServiceStats() ([]ServicesResult, error) {
+func (s *WorkService) GetServiceStats(tenantID string) ([]ServicesResult, error) {
+	cacheKey := fmt.Sprintf("service_stats:%s", tenantID)
 	// Check cache
-	res, err := database.GetRedisDB().Get("service_stats")
+	res, err := database.GetRedisDB().Get(cacheKey)
 	if err == nil || err == redis.Nil {
 		var services []ServicesResult
 		err = json.Unmarshal([]byte(res), &services)
@@ -142,12 +201,12 @@ func (s *WorkService) GetServiceStats() ([]ServicesResult, error) {
 	}
 
 	services := []ServicesResult{}
-	err = s.Db.Model(&models.WorkResult{}).Select("COUNT(*) as total_requests, service_name, service_website").Joins("JOIN users on users.id = work_results.requested_by").Where("users.email != ?", "all@banano.cc").Where("users.email != ?", "nano@banano.cc").Group("requested_by").Group("service_name").Group("service_website").Order("total_requests desc").Find(&services).Error
+	err = s.Db.Model(&models.WorkResult{}).Select("COUNT(*) as total_requests, service_name, service_website").Joins("JOIN users on users.id = work_results.requested_by").Where("users.email != ?", "all@banano.cc").Where("users.email != ?", "nano@banano.cc").Where("work_results.tenant_id = ?", tenantID).Group("requested_by").Group("service_name").Group("service_website").Order("total_requests desc").Find(&services).Error
 
 	if err == nil {
 		b, err := json.Marshal(services)
 		if err == nil {
-			database.GetRedisDB().Set("service_stats", string(b), time.Minute*1)
+			database.GetRedisDB().Set(cacheKey, string(b), time.Minute*1)
 		}
 	}
 
@@ -167,7 +226,7 @@ func (s *WorkService) GetUnpaidWorkSumForUser(email string) (int, error) {
 		return 0, err
 	}
 	var result UnpaidSumResult
-	err = s.Db.Model(&models.WorkResult{}).Select("sum(difficulty_multiplier*100) as difficulty_sum").Where("awarded = ?", false).Where("provided_by = ?", user.ID).Scan(&result).Error
+	err = s.Db.Model(&models.WorkResult{}).Select("sum(difficulty_multiplier*credit_percent) as difficulty_sum").Where("awarded = ?", false).Where("provided_by = ?", user.ID).Scan(&result).Error
 	if err != nil {
 		return 0, err
 	}
@@ -175,10 +234,10 @@ func (s *WorkService) GetUnpaidWorkSumForUser(email string) (int, error) {
 	return result.DifficultySum, nil
 }
 
-// Summate the difficulty of unpaid works for all users
-func (s *WorkService) GetUnpaidWorkSum() (int, error) {
+// Summate the difficulty of unpaid works for all users of a tenant
+func (s *WorkService) GetUnpaidWorkSum(tenantID string) (int, error) {
 	var result UnpaidSumResult
-	err := s.Db.Model(&models.WorkResult{}).Select("sum(difficulty_multiplier*100) as difficulty_sum").Where("awarded = ?", false).Scan(&result).Error
+	err := s.Db.Model(&models.WorkResult{}).Select("sum(difficulty_multiplier*credit_percent) as difficulty_sum").Where("awarded = ?", false).Where("tenant_id = ?", tenantID).Scan(&result).Error
 	if err != nil {
 		return 0, err
 	}
@@ -191,12 +250,23 @@ type UnpaidWorkResult struct {
 	UnpaidCount int       `json:"unpaid_count"`
 	ProvidedBy  uuid.UUID `json:"provided_by"`
 	BanAddress  string    `json:"ban_address"`
+	// Award in raw for work done while an award rate was in effect
+	RatedAwardRaw string `json:"rated_award_raw"`
+	// Difficulty (x credit percent) of work done without an award rate, paid as a share of the prize pool
+	UnratedDifficultySum int `json:"unrated_difficulty_sum"`
 }
 
-func (s *WorkService) GetUnpaidWorkCount(tx *gorm.DB) ([]UnpaidWorkResult, error) {
+// The award rate in effect when a work result was created, NULL if there was none
+const awardRateAtWorkTime = "(SELECT raw_per_unit FROM award_rates WHERE award_rates.tenant_id = work_results.tenant_id AND award_rates.effective_at <= work_results.created_at ORDER BY award_rates.effective_at DESC LIMIT 1)"
+
+// Sums of unpaid work per provider, x the credit percent for more precision, full credit is x 100
+const unpaidWorkColumns = "COUNT(*) as unpaid_count, provided_by, ban_address, sum(difficulty_multiplier*credit_percent) as difficulty_sum, " +
+	"coalesce(trunc(sum(difficulty_multiplier * credit_percent * coalesce(" + awardRateAtWorkTime + ", 0)) / 100), 0)::text as rated_award_raw, " +
+	"coalesce(sum(CASE WHEN coalesce(" + awardRateAtWorkTime + ", 0) > 0 THEN 0 ELSE difficulty_multiplier*credit_percent END), 0) as unrated_difficulty_sum"
+
+func (s *WorkService) GetUnpaidWorkCount(tx *gorm.DB, tenantID string) ([]UnpaidWorkResult, error) {
 	var result []UnpaidWorkResult
-	// x 100 for more precision
-	err := tx.Model(&models.WorkResult{}).Select("COUNT(*) as unpaid_count, provided_by, ban_address, sum(difficulty_multiplier*100) as difficulty_sum").Joins("JOIN users on users.id = work_results.provided_by").Group("provided_by").Group("ban_address").Where("awarded = ?", false).Find(&result).Error
+	err := tx.Model(&models.WorkResult{}).Select(unpaidWorkColumns).Joins("JOIN users on users.id = work_results.provided_by").Group("provided_by").Group("ban_address").Where("awarded = ?", false).Where("work_results.tenant_id = ?", tenantID).Find(&result).Error
 	return result, err
 }
 
@@ -206,9 +276,10 @@ type Top10Result struct {
 	TotalBan   string `json:"total_ban"`
 }
 
-func (s *WorkService) GetTopContributors(limit int) ([]Top10Result, error) {
+func (s *WorkService) GetTopContributors(tenantID string, limit int) ([]Top10Result, error) {
+	cacheKey := fmt.Sprintf("top10_result:%s", tenantID)
 	// Check cache
-	res, err := database.GetRedisDB().Get("top10_result")
+	res, err := database.GetRedisDB().Get(cacheKey)
 	if err == nil || err == redis.Nil {
 		var top []Top10Result
 		err = json.Unmarshal([]byte(res), &top)
@@ -218,12 +289,12 @@ func (s *WorkService) GetTopContributors(limit int) ([]Top10Result, error) {
 	}
 
 	var results []Top10Result
-	err = s.Db.Model(&models.WorkResult{}).Select("ban_address, (select sum(cast(send_json->>'amount'as numeric)) from payments where payments.paid_to=provided_by) as total_raw").Joins("JOIN users on users.id = work_results.provided_by").Group("ban_address").Group("provided_by").Where("type = ?", "PROVIDER").Order("sum(difficulty_multiplier) desc").Limit(limit).Find(&results).Error
+	err = s.Db.Model(&models.WorkResult{}).Select("ban_address, (select sum(cast(send_json->>'amount'as numeric)) from payments where payments.paid_to=provided_by) as total_raw").Joins("JOIN users on users.id = work_results.provided_by").Group("ban_address").Group("provided_by").Where("type = ?", "PROVIDER").Where("work_results.tenant_id = ?", tenantID).Order("sum(difficulty_multiplier) desc").Limit(limit).Find(&results).Error
 	if err == nil {
 		for i, r := range results {
 			totalBan, err := number.RawToBanano(r.TotalRaw, true)
 			if err != nil {
-				klog.Infof("Error converting %v to banano", err)
+				logging.Infof(logging.Payouts, "Error converting %v to banano", err)
 				results[i].TotalBan = "0"
 			} else {
 				results[i].TotalBan = fmt.Sprintf("%.2f", totalBan)
@@ -234,68 +305,236 @@ func (s *WorkService) GetTopContributors(limit int) ([]Top10Result, error) {
 	if err == nil {
 		b, err := json.Marshal(results)
 		if err == nil {
-			database.GetRedisDB().Set("top10_result", string(b), time.Hour*1)
+			database.GetRedisDB().Set(cacheKey, string(b), time.Hour*1)
 		}
 	}
 
 	return results, err
 }
 
-func (s *WorkService) GetUnpaidWorkCountAndMarkAllPaid(tx *gorm.DB) ([]UnpaidWorkResult, error) {
-	result, err := s.GetUnpaidWorkCount(tx)
+type ResearchRecord struct {
+	ProvidedBy           uuid.UUID `json:"provided_by"`
+	RequestedBy          uuid.UUID `json:"requested_by"`
+	DifficultyMultiplier int       `json:"difficulty_multiplier"`
+	SolveLatencyMs       int64     `json:"solve_latency_ms"`
+	Precache             bool      `json:"precache"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+// Raw work records used for the anonymized research export, the caller is responsible for anonymizing them
+func (s *WorkService) GetResearchRecords(since time.Time) ([]ResearchRecord, error) {
+	return s.statsStore.GetResearchRecords(since)
+}
+
+// The payout in raw for each result, rated work at its award rate plus a share of the prize pool for unrated work
+// The shares are rounded down and the raw left over goes to the largest remainders, so they add up to exactly the prize pool
+// Fails if a rated award isn't a number of raw, an empty one is no rated work
+func PayoutAmounts(results []UnpaidWorkResult, prizePool int) ([]*big.Int, error) {
+	totalUnrated := 0
+	for _, r := range results {
+		totalUnrated += r.UnratedDifficultySum
+	}
+	amounts := make([]*big.Int, len(results))
+	remainders := make([]*big.Int, len(results))
+	prizePoolRaw := number.BananoIntToRaw(prizePool)
+	distributed := new(big.Int)
+	for i, r := range results {
+		amounts[i] = new(big.Int)
+		remainders[i] = new(big.Int)
+		if totalUnrated > 0 {
+			share := new(big.Int).Mul(prizePoolRaw, big.NewInt(int64(r.UnratedDifficultySum)))
+			amounts[i].QuoRem(share, big.NewInt(int64(totalUnrated)), remainders[i])
+			distributed.Add(distributed, amounts[i])
+		}
+	}
+	if totalUnrated > 0 {
+		leftover := new(big.Int).Sub(prizePoolRaw, distributed).Int64()
+		order := make([]int, len(results))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return remainders[order[a]].Cmp(remainders[order[b]]) > 0
+		})
+		for i := int64(0); i < leftover; i++ {
+			amounts[order[i]].Add(amounts[order[i]], big.NewInt(1))
+		}
+	}
+	for i, r := range results {
+		if r.RatedAwardRaw == "" {
+			continue
+		}
+		rated, ok := new(big.Int).SetString(r.RatedAwardRaw, 10)
+		if !ok {
+			return nil, fmt.Errorf("rated award %q of %s is not a number of raw", r.RatedAwardRaw, r.BanAddress)
+		}
+		amounts[i].Add(amounts[i], rated)
+	}
+	return amounts, nil
+}
+
+// What every provider would be paid if the payout happened now, computed the same way moneybags does
+func (s *WorkService) EstimatePayouts(tenantID string, prizePool int) ([]UnpaidWorkResult, []*big.Int, error) {
+	unpaid, err := s.GetUnpaidWorkCount(s.Db, tenantID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	amounts, err := PayoutAmounts(unpaid, prizePool)
+	if err != nil {
+		return nil, nil, err
 	}
-	err = tx.Model(&models.WorkResult{}).Where("1=1").Update("awarded", true).Error
+	return unpaid, amounts, nil
+}
+
+// Marks the unpaid work paid and sums it in one statement, work saved while the payout runs is left for the next one
+func (s *WorkService) GetUnpaidWorkCountAndMarkAllPaid(tx *gorm.DB, tenantID string) ([]UnpaidWorkResult, error) {
+	var result []UnpaidWorkResult
+	err := tx.Raw("WITH paid AS (UPDATE work_results SET awarded = true, updated_at = ? WHERE awarded = false AND tenant_id = ? RETURNING *) "+
+		"SELECT "+unpaidWorkColumns+" FROM paid AS work_results JOIN users on users.id = work_results.provided_by GROUP BY provided_by, ban_address", s.clock.Now(), tenantID).Scan(&result).Error
 	return result, err
 }
 
-func (s *WorkService) RetrieveWorkFromCache(hash string, difficultyMultiplier int) (string, error) {
+func (s *WorkService) RetrieveWorkFromCache(tenantID string, hash string, difficultyMultiplier int) (string, error) {
 	// Check cache first
-	work, err := database.GetRedisDB().GetCachedWork(hash)
+	work, err := database.GetRedisDB().GetCachedWork(tenantID, hash)
 	if err == nil {
 		return work, nil
 	}
 
 	var workRequest models.WorkResult
-	err = s.Db.Where("hash = ?", hash).First(&workRequest).Error
-	if err != nil {
+	err = s.Db.Where("hash = ?", hash).Where("tenant_id = ?", tenantID).First(&workRequest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return "", err
 	}
 
 	// Validate difficulty is valid
-	if !validation.IsWorkValid(hash, difficultyMultiplier, workRequest.Result) {
+	if err == nil && validation.IsWorkValid(hash, difficultyMultiplier, workRequest.Result) {
+		return workRequest.Result, nil
+	}
+
+	// Fall back to work requesters submitted themselves
+	var submitted models.SubmittedWork
+	err = s.Db.Where("hash = ?", strings.ToUpper(hash)).Where("tenant_id = ?", tenantID).First(&submitted).Error
+	if err != nil {
+		return "", err
+	}
+	if !validation.IsWorkValid(hash, difficultyMultiplier, submitted.Result) {
 		return "", gorm.ErrRecordNotFound
 	}
-	return workRequest.Result, nil
+	return submitted.Result, nil
+}
+
+// Stores work a requester computed themselves in their tenant's cache, after validating it
+// Returns false if work of the same or a higher difficulty was already submitted for the hash
+func (s *WorkService) SubmitWork(submittedBy *models.User, hash string, result string, difficultyMultiplier int) (bool, error) {
+	if !validation.IsWorkValid(hash, difficultyMultiplier, result) {
+		return false, errors.New("work is not valid for this hash and difficulty")
+	}
+	submitted := &models.SubmittedWork{
+		TenantID:             submittedBy.TenantID,
+		Hash:                 strings.ToUpper(hash),
+		DifficultyMultiplier: difficultyMultiplier,
+		Result:               strings.ToLower(result),
+		SubmittedBy:          submittedBy.ID,
+	}
+	res := s.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"difficulty_multiplier", "result", "submitted_by", "updated_at"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "submitted_works.difficulty_multiplier < excluded.difficulty_multiplier"}}},
+	}).Create(submitted)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// Unpaid stats and payouts of a tenant as of computedAt
+type payoutEstimate struct {
+	unpaid     []UnpaidWorkResult
+	payouts    []*big.Int
+	prizePool  int
+	computedAt time.Time
+}
+
+// Estimating means aggregating all unpaid work, so it's only redone every AWARD_ESTIMATE_REFRESH_SECONDS, or when a provider isn't in it yet
+func (s *WorkService) payoutEstimate(estimates map[string]*payoutEstimate, tenantID string, providerID uuid.UUID) (*payoutEstimate, error) {
+	now := s.clock.Now()
+	if estimate, ok := estimates[tenantID]; ok && now.Sub(estimate.computedAt) < config.AWARD_ESTIMATE_REFRESH_SECONDS*time.Second {
+		for _, r := range estimate.unpaid {
+			if r.ProvidedBy == providerID {
+				return estimate, nil
+			}
+		}
+	}
+	prizePool := utils.GetTotalPrizePool()
+	if tenant, err := s.tenantRepo.GetTenant(tenantID); err == nil {
+		prizePool = tenant.GetPrizePool()
+	} else {
+		logging.Errorf(logging.Payouts, "Error getting tenant %s, using default prize pool %v", tenantID, err)
+	}
+	unpaid, payouts, err := s.EstimatePayouts(tenantID, prizePool)
+	if err != nil {
+		return nil, err
+	}
+	estimate := &payoutEstimate{unpaid: unpaid, payouts: payouts, prizePool: prizePool, computedAt: now}
+	estimates[tenantID] = estimate
+	return estimate, nil
 }
 
+// Returns once statsChan is closed and drained, and its block awarded messages are sent, so blockAwardedChan can be closed
 func (s *WorkService) StatsWorker(statsChan <-chan WorkMessage, blockAwardedChan *chan serializableModels.ClientMessage) {
+	var sending sync.WaitGroup
+	defer sending.Wait()
+	estimates := make(map[string]*payoutEstimate)
 	for c := range statsChan {
-		_, err := s.SaveOrUpdateWorkResult(c)
+		if c.TenantID == "" {
+			c.TenantID = config.DEFAULT_TENANT_ID
+		}
+		workResult, err := s.SaveOrUpdateWorkResult(c)
+		if err == nil {
+			// One timestamp, so work at the end of an hour or month isn't counted in the next period by one store and not the other
+			now := s.clock.Now()
+			if err := s.statsStore.RecordWorkEvent(NewWorkEvent(workResult, now)); err != nil {
+				logging.Errorf(logging.Stats, "Error recording work event %v", err)
+			}
+			if err := s.usageRepo.RecordUsage(workResult.RequestedBy, c.TenantID, workResult.DifficultyMultiplier, false, now); err != nil {
+				logging.Errorf(logging.Stats, "Error recording usage %v", err)
+			}
+		}
 		if !c.BlockAward {
 			// This request has no reward, so don't messsage the client
 			continue
 		}
 		if err != nil {
-			klog.Errorf("Error saving work stats %v", err)
+			logging.Errorf(logging.Stats, "Error saving work stats %v", err)
 			continue
 		}
 		// Process message to send to user
-		// Get total unpaid stats
-		unpaidStats, err := s.GetUnpaidWorkSum()
+		provider, err := s.userRepo.GetUser(nil, &c.ProvidedByEmail)
 		if err != nil {
-			klog.Errorf("Error getting unpaid stats %v", err)
+			logging.Errorf(logging.Stats, "Error getting provider %v", err)
+			continue
 		}
-		// Get unpaid stats for this user
-		unpaidUserStats, err := s.GetUnpaidWorkSumForUser(c.ProvidedByEmail)
+		// Get unpaid stats for everyone, the estimate is computed the same way the payout is
+		estimate, err := s.payoutEstimate(estimates, c.TenantID, provider.ID)
 		if err != nil {
-			klog.Errorf("Error getting unpaid stats for user %v", err)
+			logging.Errorf(logging.Payouts, "Error getting unpaid stats %v", err)
+			estimate = &payoutEstimate{}
+		}
+		unpaidStats, payouts := estimate.unpaid, estimate.payouts
+		logging.Debugf(logging.Payouts, "Estimating award for %s from %d unpaid providers and a prize pool of %d", c.ProvidedByEmail, len(unpaidStats), estimate.prizePool)
+		totalUnpaid := 0
+		unpaidUserStats := 0
+		estimatedAward := 0.0
+		for i, r := range unpaidStats {
+			totalUnpaid += r.DifficultySum
+			if r.ProvidedBy == provider.ID {
+				unpaidUserStats = r.DifficultySum
+				estimatedAward = number.RawIntToBanano(payouts[i])
+			}
 		}
 		// Get percentage of unpaid stats for this user
-		percentageOfPool := float64(unpaidUserStats) / float64(unpaidStats) * 100
-		prizePool := utils.GetTotalPrizePool()
-		estimatedAward := float64(prizePool) * percentageOfPool / 100
+		percentageOfPool := float64(unpaidUserStats) / float64(totalUnpaid) * 100
 		// Format client message
 		blockAwardedMsg := serializableModels.ClientMessage{
 			MessageType:          serializableModels.BlockAwarded,
@@ -304,8 +543,48 @@ func (s *WorkService) StatsWorker(statsChan <-chan WorkMessage, blockAwardedChan
 			EstimatedAward:       estimatedAward,
 			ProviderEmail:        c.ProvidedByEmail,
 			DifficultyMultiplier: c.DifficultyMultiplier,
+			TenantID:             c.TenantID,
+			MessageID:            uuid.NewString(),
 		}
 
-		go func() { *blockAwardedChan <- blockAwardedMsg }()
+		sending.Add(1)
+		go func() {
+			defer sending.Done()
+			*blockAwardedChan <- blockAwardedMsg
+		}()
 	}
 }
+
+// What admins can filter and sort the work history on, newest first by default
+var WorkResultList = filter.List{
+	Fields: map[string]filter.Field{
+		"hash":                  {Column: "hash", Type: filter.String},
+		"tenant_id":             {Column: "tenant_id", Type: filter.String},
+		"provided_by":           {Column: "provided_by", Type: filter.UUID},
+		"requested_by":          {Column: "requested_by", Type: filter.UUID},
+		"difficulty_multiplier": {Column: "difficulty_multiplier", Type: filter.Int},
+		"awarded":               {Column: "awarded", Type: filter.Bool},
+		"precache":              {Column: "precache", Type: filter.Bool},
+		"solve_latency_ms":      {Column: "solve_latency_ms", Type: filter.Int},
+		"credit_percent":        {Column: "credit_percent", Type: filter.Int},
+		"created_at":            {Column: "created_at", Type: filter.Time, Sortable: true},
+	},
+	IDColumn:    "id",
+	DefaultSort: filter.Sort{Field: "created_at", Descending: true},
+}
+
+func WorkResultCursor(result models.WorkResult) pagination.Cursor {
+	return pagination.Cursor{Time: result.CreatedAt, ID: result.ID.String()}
+}
+
+func (s *WorkService) ListWorkResults(query *filter.Query, args pagination.Args) ([]models.WorkResult, error) {
+	results := []models.WorkResult{}
+	err := s.Db.Scopes(query.Scope(args)).Find(&results).Error
+	return results, err
+}
+
+func (s *WorkService) CountWorkResults(query *filter.Query) (int, error) {
+	var count int64
+	err := query.Where(s.Db.Model(&models.WorkResult{})).Count(&count).Error
+	return int(count), err
+}
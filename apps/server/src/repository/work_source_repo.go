@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrTooManyWorkSources = errors.New("too many work sources")
+var ErrWorkSourceExists = errors.New("work source exists")
+
+// Work requests of a source over a time range
+type SourceUsage struct {
+	Source   string
+	Requests int64
+	Cached   int64
+}
+
+type WorkSourceRepo interface {
+	CreateWorkSource(userID uuid.UUID, name string, dailyQuota *int) (*models.WorkSource, error)
+	GetWorkSources(userID uuid.UUID) ([]models.WorkSource, error)
+	GetWorkSource(userID uuid.UUID, name string) (*models.WorkSource, error)
+	SetWorkSourceQuota(userID uuid.UUID, name string, dailyQuota *int) (*models.WorkSource, error)
+	DeleteWorkSource(userID uuid.UUID, name string) (bool, error)
+	RecordSourceUsage(source *models.WorkSource, cached bool, at time.Time) error
+	GetSourceUsage(userID uuid.UUID, since time.Time) ([]SourceUsage, error)
+}
+
+type WorkSourceService struct {
+	Db *gorm.DB
+}
+
+var _ WorkSourceRepo = &WorkSourceService{}
+
+func NewWorkSourceService(db *gorm.DB) *WorkSourceService {
+	return &WorkSourceService{
+		Db: db,
+	}
+}
+
+func (s *WorkSourceService) CreateWorkSource(userID uuid.UUID, name string, dailyQuota *int) (*models.WorkSource, error) {
+	source := &models.WorkSource{UserID: userID, Name: name, DailyQuota: dailyQuota}
+	err := s.Db.Transaction(func(tx *gorm.DB) error {
+		// Concurrent requests can't both take the last slot
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", userID).First(&models.User{}).Error; err != nil {
+			return err
+		}
+		var sources []models.WorkSource
+		if err := tx.Where("user_id = ?", userID).Find(&sources).Error; err != nil {
+			return err
+		}
+		for _, existing := range sources {
+			if existing.Name == name {
+				return ErrWorkSourceExists
+			}
+		}
+		if len(sources) >= config.MAX_WORK_SOURCES_PER_USER {
+			return ErrTooManyWorkSources
+		}
+		return tx.Create(source).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// Alphabetical
+func (s *WorkSourceService) GetWorkSources(userID uuid.UUID) ([]models.WorkSource, error) {
+	sources := []models.WorkSource{}
+	err := s.Db.Where("user_id = ?", userID).Order("name asc").Find(&sources).Error
+	return sources, err
+}
+
+// Nil if the user has no such source
+func (s *WorkSourceService) GetWorkSource(userID uuid.UUID, name string) (*models.WorkSource, error) {
+	var sources []models.WorkSource
+	if err := s.Db.Where("user_id = ? AND name = ?", userID, name).Limit(1).Find(&sources).Error; err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, nil
+	}
+	return &sources[0], nil
+}
+
+// Nil if the user has no such source, a nil quota doesn't limit it
+func (s *WorkSourceService) SetWorkSourceQuota(userID uuid.UUID, name string, dailyQuota *int) (*models.WorkSource, error) {
+	res := s.Db.Model(&models.WorkSource{}).Where("user_id = ? AND name = ?", userID, name).Update("daily_quota", dailyQuota)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return nil, res.Error
+	}
+	return s.GetWorkSource(userID, name)
+}
+
+// Its usage rows are kept but no longer reported, false if the user has no such source
+func (s *WorkSourceService) DeleteWorkSource(userID uuid.UUID, name string) (bool, error) {
+	res := s.Db.Where("user_id = ? AND name = ?", userID, name).Delete(&models.WorkSource{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (s *WorkSourceService) RecordSourceUsage(source *models.WorkSource, cached bool, at time.Time) error {
+	usage := &models.WorkSourceUsage{
+		Day:      models.StartOfDay(at),
+		SourceID: source.ID,
+		UserID:   source.UserID,
+	}
+	column := "requests"
+	usage.Requests = 1
+	if cached {
+		column = "cached"
+		usage.Requests, usage.Cached = 0, 1
+	}
+	return s.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "day"}, {Name: "source_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{column: gorm.Expr("work_source_usages." + column + " + 1")}),
+	}).Create(usage).Error
+}
+
+// Usage of the user's sources since the start of the day since is in, busiest first
+// Deleted sources are left out
+func (s *WorkSourceService) GetSourceUsage(userID uuid.UUID, since time.Time) ([]SourceUsage, error) {
+	usage := []SourceUsage{}
+	err := s.Db.Table("work_source_usages").
+		Select("work_sources.name AS source, SUM(work_source_usages.requests) AS requests, SUM(work_source_usages.cached) AS cached").
+		Joins("JOIN work_sources ON work_sources.id = work_source_usages.source_id").
+		Where("work_source_usages.user_id = ? AND work_source_usages.day >= ?", userID, models.StartOfDay(since)).
+		Group("work_sources.name").
+		Order("SUM(work_source_usages.requests + work_source_usages.cached) DESC, work_sources.name").
+		Scan(&usage).Error
+	return usage, err
+}
@@ -0,0 +1,112 @@
+package research
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/google/uuid"
+)
+
+// A single row of the anonymized research dataset
+type DatasetRow struct {
+	ProviderHash         string
+	RequesterHash        string
+	DifficultyMultiplier int
+	SolveLatencyMs       int64
+	Precache             bool
+	// Truncated to the bucket size so individual requests can't be matched against the chain
+	Timestamp time.Time
+}
+
+// Rows that share the same quasi-identifiers belong to the same equivalence class
+type equivalenceClass struct {
+	DifficultyMultiplier int
+	Precache             bool
+	Timestamp            time.Time
+}
+
+func (r DatasetRow) class() equivalenceClass {
+	return equivalenceClass{
+		DifficultyMultiplier: r.DifficultyMultiplier,
+		Precache:             r.Precache,
+		Timestamp:            r.Timestamp,
+	}
+}
+
+// Hash a user ID with a secret salt, so IDs are stable within an export but can't be reversed
+func HashUserID(salt []byte, id uuid.UUID) string {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(id[:])
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// Anonymize raw work records, replacing user IDs with salted hashes and truncating timestamps to the given bucket
+func Anonymize(records []repository.ResearchRecord, salt []byte, bucket time.Duration) ([]DatasetRow, error) {
+	if len(salt) == 0 {
+		return nil, errors.New("a salt is required to anonymize user IDs")
+	}
+	if bucket <= 0 {
+		return nil, errors.New("timestamp bucket must be positive")
+	}
+	rows := make([]DatasetRow, len(records))
+	for i, r := range records {
+		rows[i] = DatasetRow{
+			ProviderHash:         HashUserID(salt, r.ProvidedBy),
+			RequesterHash:        HashUserID(salt, r.RequestedBy),
+			DifficultyMultiplier: r.DifficultyMultiplier,
+			SolveLatencyMs:       r.SolveLatencyMs,
+			Precache:             r.Precache,
+			Timestamp:            r.CreatedAt.UTC().Truncate(bucket),
+		}
+	}
+	return rows, nil
+}
+
+// Drop every row whose equivalence class (difficulty, precache, time bucket) has fewer than k members
+// Returns the rows that are safe to export and the number of suppressed rows
+func EnforceKAnonymity(rows []DatasetRow, k int) ([]DatasetRow, int) {
+	if k <= 1 {
+		return rows, 0
+	}
+	counts := make(map[equivalenceClass]int)
+	for _, r := range rows {
+		counts[r.class()]++
+	}
+	kept := []DatasetRow{}
+	for _, r := range rows {
+		if counts[r.class()] >= k {
+			kept = append(kept, r)
+		}
+	}
+	return kept, len(rows) - len(kept)
+}
+
+// Write the dataset as CSV with a header row
+func WriteCSV(w io.Writer, rows []DatasetRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"provider", "requester", "difficulty_multiplier", "solve_latency_ms", "precache", "timestamp"}); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := writer.Write([]string{
+			r.ProviderHash,
+			r.RequesterHash,
+			strconv.Itoa(r.DifficultyMultiplier),
+			strconv.FormatInt(r.SolveLatencyMs, 10),
+			strconv.FormatBool(r.Precache),
+			r.Timestamp.Format(time.RFC3339),
+		}); err != nil {
+			return fmt.Errorf("error writing dataset row %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
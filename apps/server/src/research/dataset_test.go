@@ -0,0 +1,62 @@
+package research
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+func TestAnonymize(t *testing.T) {
+	provider := uuid.New()
+	requester := uuid.New()
+	ts := time.Date(2022, 10, 1, 13, 45, 12, 0, time.UTC)
+	records := []repository.ResearchRecord{
+		{ProvidedBy: provider, RequestedBy: requester, DifficultyMultiplier: 64, SolveLatencyMs: 1200, CreatedAt: ts},
+	}
+
+	_, err := Anonymize(records, nil, time.Hour)
+	utils.AssertEqual(t, true, err != nil)
+
+	rows, err := Anonymize(records, []byte("salt"), time.Hour)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(rows))
+	utils.AssertEqual(t, HashUserID([]byte("salt"), provider), rows[0].ProviderHash)
+	utils.AssertEqual(t, false, strings.Contains(rows[0].ProviderHash, provider.String()))
+	utils.AssertEqual(t, time.Date(2022, 10, 1, 13, 0, 0, 0, time.UTC), rows[0].Timestamp)
+	utils.AssertEqual(t, int64(1200), rows[0].SolveLatencyMs)
+
+	// Different salts give different pseudonyms
+	utils.AssertNotEqual(t, HashUserID([]byte("salt"), provider), HashUserID([]byte("other"), provider))
+}
+
+func TestEnforceKAnonymity(t *testing.T) {
+	ts := time.Date(2022, 10, 1, 13, 0, 0, 0, time.UTC)
+	rows := []DatasetRow{
+		{DifficultyMultiplier: 1, Timestamp: ts},
+		{DifficultyMultiplier: 1, Timestamp: ts},
+		{DifficultyMultiplier: 1, Timestamp: ts},
+		{DifficultyMultiplier: 64, Timestamp: ts},
+	}
+
+	kept, suppressed := EnforceKAnonymity(rows, 3)
+	utils.AssertEqual(t, 3, len(kept))
+	utils.AssertEqual(t, 1, suppressed)
+
+	kept, suppressed = EnforceKAnonymity(rows, 1)
+	utils.AssertEqual(t, 4, len(kept))
+	utils.AssertEqual(t, 0, suppressed)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []DatasetRow{
+		{ProviderHash: "a", RequesterHash: "b", DifficultyMultiplier: 1, SolveLatencyMs: 50, Timestamp: time.Date(2022, 10, 1, 13, 0, 0, 0, time.UTC)},
+	})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "provider,requester,difficulty_multiplier,solve_latency_ms,precache,timestamp\na,b,1,50,false,2022-10-01T13:00:00Z\n", buf.String())
+}
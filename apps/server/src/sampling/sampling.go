@@ -0,0 +1,117 @@
+// Package sampling records whole GraphQL requests and responses of some users or a share of all requests,
+// so admins can debug integrations without logging every request
+package sampling
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Set by an admin, sampling turns itself off at Until
+type Config struct {
+	// Only requests of this user, all requests if it's empty
+	UserEmail string `json:"user_email"`
+	// Share of the matching requests that's recorded, 0-100
+	Percent float64   `json:"percent"`
+	Until   time.Time `json:"until"`
+}
+
+func (c *Config) Active(now time.Time) bool {
+	return c != nil && now.Before(c.Until)
+}
+
+type Sample struct {
+	ID            string          `json:"id"`
+	UserEmail     string          `json:"user_email"`
+	OperationName string          `json:"operation_name"`
+	Query         string          `json:"query"`
+	Variables     json.RawMessage `json:"variables"`
+	Response      json.RawMessage `json:"response"`
+	Errors        []string        `json:"errors"`
+	DurationMs    int64           `json:"duration_ms"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type Sampler struct {
+	config atomic.Pointer[Config]
+}
+
+func NewSampler() *Sampler {
+	return &Sampler{}
+}
+
+// Set replaces the config, nil turns sampling off
+func (s *Sampler) Set(config *Config) {
+	s.config.Store(config)
+}
+
+// Config returns the config if sampling is on
+func (s *Sampler) Config(now time.Time) *Config {
+	config := s.config.Load()
+	if !config.Active(now) {
+		return nil
+	}
+	return config
+}
+
+// ShouldSample decides whether to record a request of userEmail (empty for anonymous requests)
+// roll is a random number in [0, 100)
+func (s *Sampler) ShouldSample(userEmail string, roll float64, now time.Time) bool {
+	config := s.Config(now)
+	if config == nil {
+		return false
+	}
+	if config.UserEmail != "" && !strings.EqualFold(config.UserEmail, userEmail) {
+		return false
+	}
+	return roll < config.Percent
+}
+
+const redacted = "[redacted]"
+
+// Fields that hold credentials, matched case insensitively anywhere in the name
+var sensitiveFields = []string{"password", "token", "secret", "otpauth", "backupcode", "twofactor", "authorization", "apikey"}
+
+func isSensitive(field string) bool {
+	field = strings.ToLower(field)
+	for _, sensitive := range sensitiveFields {
+		if strings.Contains(field, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sanitize redacts the values of credential fields in decoded JSON, e.g. variables or response data
+func Sanitize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for field, fieldValue := range v {
+			if isSensitive(field) {
+				ret[field] = redacted
+				continue
+			}
+			ret[field] = Sanitize(fieldValue)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i := range v {
+			ret[i] = Sanitize(v[i])
+		}
+		return ret
+	default:
+		return value
+	}
+}
+
+var stringLiteral = regexp.MustCompile(`"""[\s\S]*?"""|"(?:[^"\\]|\\.)*"`)
+
+// SanitizeQuery redacts every string literal, arguments written into the query can hold credentials too
+func SanitizeQuery(query string) string {
+	return stringLiteral.ReplaceAllString(query, `"`+redacted+`"`)
+}
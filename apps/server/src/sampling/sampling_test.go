@@ -0,0 +1,51 @@
+package sampling
+
+import (
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestShouldSample(t *testing.T) {
+	now := time.Unix(1664611200, 0)
+	sampler := NewSampler()
+	utils.AssertEqual(t, false, sampler.ShouldSample("", 0, now))
+
+	sampler.Set(&Config{Percent: 10, Until: now.Add(time.Hour)})
+	utils.AssertEqual(t, true, sampler.ShouldSample("", 9.9, now))
+	utils.AssertEqual(t, false, sampler.ShouldSample("joe@example.com", 10, now))
+	// Expired
+	utils.AssertEqual(t, false, sampler.ShouldSample("", 0, now.Add(time.Hour)))
+
+	sampler.Set(&Config{UserEmail: "joe@example.com", Percent: 100, Until: now.Add(time.Hour)})
+	utils.AssertEqual(t, true, sampler.ShouldSample("Joe@example.com", 99, now))
+	utils.AssertEqual(t, false, sampler.ShouldSample("", 0, now))
+	utils.AssertEqual(t, false, sampler.ShouldSample("jane@example.com", 0, now))
+
+	sampler.Set(nil)
+	utils.AssertEqual(t, (*Config)(nil), sampler.Config(now))
+}
+
+func TestSanitize(t *testing.T) {
+	sanitized := Sanitize(map[string]interface{}{
+		"input":                     map[string]interface{}{"email": "joe@example.com", "password": "hunter2", "twoFactorCode": "123456"},
+		"login":                     map[string]interface{}{"token": "jwt", "type": "PROVIDER"},
+		"list":                      []interface{}{map[string]interface{}{"secret": "abc"}, "plain"},
+		"generateOrGetServiceToken": "service:123",
+		"generateApiKey":            map[string]interface{}{"key": "service:abc"},
+	})
+	utils.AssertEqual(t, map[string]interface{}{
+		"input":                     map[string]interface{}{"email": "joe@example.com", "password": "[redacted]", "twoFactorCode": "[redacted]"},
+		"login":                     map[string]interface{}{"token": "[redacted]", "type": "PROVIDER"},
+		"list":                      []interface{}{map[string]interface{}{"secret": "[redacted]"}, "plain"},
+		"generateOrGetServiceToken": "[redacted]",
+		"generateApiKey":            "[redacted]",
+	}, sanitized)
+}
+
+func TestSanitizeQuery(t *testing.T) {
+	utils.AssertEqual(t, `mutation { login(input: {email: "[redacted]", password: "[redacted]"}) { token } }`, SanitizeQuery(`mutation { login(input: {email: "joe@example.com", password: "hun\"ter2"}) { token } }`))
+	utils.AssertEqual(t, `query { a(b: "[redacted]") }`, SanitizeQuery(`query { a(b: """multi
+line""") }`))
+}
@@ -0,0 +1,99 @@
+// Package shedding refuses some work requests while an admin sheds load during an incident,
+// so the workers that are left can keep up with the rest
+package shedding
+
+import (
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// Requesters with a priority boost running paid for their work to go first
+type Tier string
+
+const (
+	TierStandard Tier = "standard"
+	TierBoosted  Tier = "boosted"
+	// Precache requests are made by the server, not a requester
+	TierNone Tier = "none"
+)
+
+type DifficultyClass string
+
+const (
+	ClassPrecache DifficultyClass = "precache"
+	// On-demand work at difficulty multiplier 1
+	ClassBase DifficultyClass = "base"
+	// On-demand work above it
+	ClassElevated DifficultyClass = "elevated"
+)
+
+func RequestTier(precache bool, boosted bool) Tier {
+	switch {
+	case precache:
+		return TierNone
+	case boosted:
+		return TierBoosted
+	}
+	return TierStandard
+}
+
+func Classify(precache bool, difficultyMultiplier int) DifficultyClass {
+	switch {
+	case precache:
+		return ClassPrecache
+	case difficultyMultiplier > 1:
+		return ClassElevated
+	}
+	return ClassBase
+}
+
+// Set by an admin, shedding stops by itself at Until
+// Work requests of any of the tiers or difficulty classes are shed
+type Config struct {
+	Tiers             []Tier            `json:"tiers"`
+	DifficultyClasses []DifficultyClass `json:"difficulty_classes"`
+	Reason            string            `json:"reason"`
+	StartedBy         string            `json:"started_by"`
+	Until             time.Time         `json:"until"`
+}
+
+func (c *Config) Active(now time.Time) bool {
+	return c != nil && now.Before(c.Until)
+}
+
+func (c *Config) Sheds(tier Tier, class DifficultyClass) bool {
+	return slices.Contains(c.Tiers, tier) || slices.Contains(c.DifficultyClasses, class)
+}
+
+type Shedder struct {
+	config atomic.Pointer[Config]
+}
+
+func NewShedder() *Shedder {
+	return &Shedder{}
+}
+
+// Set replaces the config, nil stops shedding
+func (s *Shedder) Set(config *Config) {
+	s.config.Store(config)
+}
+
+// Config returns the config if load is being shed
+func (s *Shedder) Config(now time.Time) *Config {
+	config := s.config.Load()
+	if !config.Active(now) {
+		return nil
+	}
+	return config
+}
+
+// Sheds returns the config a work request is shed by, nil if it goes out
+func (s *Shedder) Sheds(tier Tier, class DifficultyClass, now time.Time) *Config {
+	config := s.Config(now)
+	if config == nil || !config.Sheds(tier, class) {
+		return nil
+	}
+	return config
+}
@@ -0,0 +1,34 @@
+package shedding
+
+import (
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestClassify(t *testing.T) {
+	utils.AssertEqual(t, TierNone, RequestTier(true, true))
+	utils.AssertEqual(t, TierBoosted, RequestTier(false, true))
+	utils.AssertEqual(t, TierStandard, RequestTier(false, false))
+	utils.AssertEqual(t, ClassPrecache, Classify(true, 1))
+	utils.AssertEqual(t, ClassBase, Classify(false, 1))
+	utils.AssertEqual(t, ClassElevated, Classify(false, 64))
+}
+
+func TestSheds(t *testing.T) {
+	now := time.Unix(1664611200, 0)
+	shedder := NewShedder()
+	utils.AssertEqual(t, (*Config)(nil), shedder.Sheds(TierStandard, ClassBase, now))
+
+	config := &Config{Tiers: []Tier{TierStandard}, DifficultyClasses: []DifficultyClass{ClassPrecache}, Until: now.Add(time.Hour)}
+	shedder.Set(config)
+	utils.AssertEqual(t, config, shedder.Sheds(TierStandard, ClassElevated, now))
+	utils.AssertEqual(t, config, shedder.Sheds(TierNone, ClassPrecache, now))
+	utils.AssertEqual(t, (*Config)(nil), shedder.Sheds(TierBoosted, ClassElevated, now))
+	// Expired
+	utils.AssertEqual(t, (*Config)(nil), shedder.Sheds(TierStandard, ClassBase, now.Add(time.Hour)))
+
+	shedder.Set(nil)
+	utils.AssertEqual(t, (*Config)(nil), shedder.Config(now))
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	serializableModels "github.com/bananocoin/boompow/libs/models"
+	"github.com/bananocoin/boompow/libs/utils/number"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestActivityRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	paymentRepo := repository.NewPaymentService(mockDb)
+	activityRepo := repository.NewActivityService(mockDb)
+
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+	providerEmail := "provider@gmail.com"
+	provider, _ := userRepo.GetUser(nil, &providerEmail)
+
+	utils.AssertEqual(t, nil, activityRepo.RecordAccountEvent(provider.ID, models.AccountEventLogin, "", "127.0.0.1"))
+	err = paymentRepo.BatchCreateSendRequests(mockDb, "default", nil, []serializableModels.SendRequest{{
+		BaseRequest: serializableModels.SendAction,
+		Destination: "ban_1",
+		AmountRaw:   number.BananoToRaw(5),
+		ID:          "1",
+		PaidTo:      provider.ID,
+	}})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, nil, activityRepo.RecordAccountEvent(provider.ID, models.AccountEventPayoutAddressesChanged, "2 addresses", "127.0.0.1"))
+
+	activity, err := activityRepo.GetActivity(provider.ID, pagination.Args{First: 10})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 3, len(activity))
+	utils.AssertEqual(t, string(models.AccountEventPayoutAddressesChanged), activity[0].Type)
+	utils.AssertEqual(t, repository.ActivityPayoutReceived, activity[1].Type)
+	utils.AssertEqual(t, "5.00", activity[1].AmountBanano())
+	utils.AssertEqual(t, string(models.AccountEventLogin), activity[2].Type)
+
+	count, err := activityRepo.CountActivity(provider.ID)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 3, count)
+
+	// Next page
+	after := activity[1].Cursor()
+	activity, err = activityRepo.GetActivity(provider.ID, pagination.Args{First: 10, After: &after})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(activity))
+	utils.AssertEqual(t, string(models.AccountEventLogin), activity[0].Type)
+}
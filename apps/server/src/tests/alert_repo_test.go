@@ -0,0 +1,25 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+// Test offline alert validation
+func TestValidateOfflineAlert(t *testing.T) {
+	utils.AssertEqual(t, nil, repository.ValidateOfflineAlert(models.AlertChannelEmail, "", 10))
+	utils.AssertEqual(t, nil, repository.ValidateOfflineAlert(models.AlertChannelWebhook, "https://example.com/hook", 10))
+	utils.AssertEqual(t, nil, repository.ValidateOfflineAlert(models.AlertChannelDiscord, "https://discord.com/api/webhooks/1/abc", 10))
+	// Too short or long
+	utils.AssertNotEqual(t, nil, repository.ValidateOfflineAlert(models.AlertChannelEmail, "", 1))
+	utils.AssertNotEqual(t, nil, repository.ValidateOfflineAlert(models.AlertChannelEmail, "", 10000))
+	// Not https
+	utils.AssertNotEqual(t, nil, repository.ValidateOfflineAlert(models.AlertChannelWebhook, "http://example.com/hook", 10))
+	utils.AssertNotEqual(t, nil, repository.ValidateOfflineAlert(models.AlertChannelWebhook, "", 10))
+	// Not Discord
+	utils.AssertNotEqual(t, nil, repository.ValidateOfflineAlert(models.AlertChannelDiscord, "https://example.com/api/webhooks/1/abc", 10))
+	utils.AssertNotEqual(t, nil, repository.ValidateOfflineAlert("SMS", "", 10))
+}
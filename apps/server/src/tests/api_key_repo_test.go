@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+func TestAPIKeyRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	apiKeyRepo := repository.NewAPIKeyService(mockDb)
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+	email := "requester@gmail.com"
+	user, _ := userRepo.GetUser(nil, &email)
+
+	limit := 60
+	apiKey, key, err := apiKeyRepo.CreateAPIKey(user.ID, "faucet", &limit)
+	utils.AssertEqual(t, nil, err)
+	// Only the hash is stored, the key is looked up by it
+	utils.AssertNotEqual(t, key, apiKey.KeyHash)
+	found, err := apiKeyRepo.GetAPIKey(key)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, apiKey.ID, found.ID)
+	utils.AssertEqual(t, 60, *found.RateLimitPerMinute)
+	found, err = apiKeyRepo.GetAPIKey("service:unknown")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, found == nil)
+
+	// Last use is only written once per resolution
+	now := time.Now()
+	utils.AssertEqual(t, nil, apiKeyRepo.TouchAPIKey(apiKey, now))
+	utils.AssertEqual(t, nil, apiKeyRepo.TouchAPIKey(apiKey, now.Add(time.Second)))
+	utils.AssertEqual(t, now, *apiKey.LastUsedAt)
+
+	for i := 1; i < config.MAX_API_KEYS_PER_USER; i++ {
+		_, _, err = apiKeyRepo.CreateAPIKey(user.ID, "more", nil)
+		utils.AssertEqual(t, nil, err)
+	}
+	_, _, err = apiKeyRepo.CreateAPIKey(user.ID, "one too many", nil)
+	utils.AssertEqual(t, repository.ErrTooManyAPIKeys, err)
+
+	// Other users can't revoke it
+	revoked, err := apiKeyRepo.RevokeAPIKey(uuid.New(), apiKey.ID)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, revoked)
+	revoked, err = apiKeyRepo.RevokeAPIKey(user.ID, apiKey.ID)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, revoked)
+	found, _ = apiKeyRepo.GetAPIKey(key)
+	utils.AssertEqual(t, true, found == nil)
+	apiKeys, err := apiKeyRepo.GetAPIKeys(user.ID)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, config.MAX_API_KEYS_PER_USER-1, len(apiKeys))
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/filter"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestAuditLogRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	auditRepo := repository.NewAuditLogService(mockDb)
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+	email := "provider@gmail.com"
+	user, _ := userRepo.GetUser(nil, &email)
+
+	utils.AssertEqual(t, nil, auditRepo.RecordAuditEvent(&models.AuditLogEntry{Action: models.AuditLogin, ActorID: &user.ID, ActorEmail: user.Email, Success: true}))
+	utils.AssertEqual(t, nil, auditRepo.RecordAuditEvent(&models.AuditLogEntry{Action: models.AuditTokenRejected, Detail: "JWT", ClientIP: "10.0.0.1"}))
+	utils.AssertEqual(t, nil, auditRepo.RecordAuditEvent(&models.AuditLogEntry{Action: models.AuditLoginFailed, Target: email, ClientIP: "10.0.0.1"}))
+
+	// Newest first
+	query, err := repository.AuditLogList.Compile(nil, nil)
+	utils.AssertEqual(t, nil, err)
+	entries, err := auditRepo.ListAuditLog(query, pagination.Args{First: 2})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 3, len(entries))
+	utils.AssertEqual(t, models.AuditLoginFailed, entries[0].Action)
+	count, err := auditRepo.CountAuditLog(query)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 3, count)
+
+	// What came from an IP, or without anyone signed in
+	query, err = repository.AuditLogList.Compile([]filter.Condition{{Field: "client_ip", Op: filter.Eq, Values: []string{"10.0.0.1"}}}, nil)
+	utils.AssertEqual(t, nil, err)
+	count, _ = auditRepo.CountAuditLog(query)
+	utils.AssertEqual(t, 2, count)
+	query, err = repository.AuditLogList.Compile([]filter.Condition{{Field: "actor_id", Op: filter.IsNull, Values: []string{"true"}}}, nil)
+	utils.AssertEqual(t, nil, err)
+	count, _ = auditRepo.CountAuditLog(query)
+	utils.AssertEqual(t, 2, count)
+	_, err = repository.AuditLogList.Compile([]filter.Condition{{Field: "action", Op: filter.Eq, Values: []string{"nope"}}}, nil)
+	utils.AssertNotEqual(t, nil, err)
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+// Test benchmark submission validation
+func TestValidateBenchmarkSubmission(t *testing.T) {
+	valid := repository.BenchmarkSubmission{
+		Hardware:             "RTX 3070",
+		Backend:              repository.BenchmarkBackendGPU,
+		DifficultyMultiplier: 64,
+		Runs:                 10,
+		AverageSeconds:       2.5,
+	}
+	utils.AssertEqual(t, nil, repository.ValidateBenchmarkSubmission(valid))
+
+	invalid := valid
+	invalid.Hardware = "   "
+	utils.AssertNotEqual(t, nil, repository.ValidateBenchmarkSubmission(invalid))
+	invalid = valid
+	invalid.Backend = "FPGA"
+	utils.AssertNotEqual(t, nil, repository.ValidateBenchmarkSubmission(invalid))
+	invalid = valid
+	invalid.DifficultyMultiplier = 65
+	utils.AssertNotEqual(t, nil, repository.ValidateBenchmarkSubmission(invalid))
+	invalid = valid
+	invalid.Runs = 0
+	utils.AssertNotEqual(t, nil, repository.ValidateBenchmarkSubmission(invalid))
+	invalid = valid
+	invalid.AverageSeconds = 0
+	utils.AssertNotEqual(t, nil, repository.ValidateBenchmarkSubmission(invalid))
+
+	utils.AssertEqual(t, "RTX 3070", repository.NormalizeHardware("  RTX   3070\t"))
+}
+
+// Test benchmark repo
+func TestBenchmarkRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	benchmarkRepo := repository.NewBenchmarkService(mockDb)
+
+	alice := uuid.New()
+	bob := uuid.New()
+	gpu := repository.BenchmarkSubmission{Hardware: "RTX 3070", Backend: repository.BenchmarkBackendGPU, DifficultyMultiplier: 64, Runs: 10, AverageSeconds: 4}
+	_, err = benchmarkRepo.SubmitBenchmark(alice, gpu)
+	utils.AssertEqual(t, nil, err)
+	// Replaces alice's previous profile
+	gpu.AverageSeconds = 2
+	_, err = benchmarkRepo.SubmitBenchmark(alice, gpu)
+	utils.AssertEqual(t, nil, err)
+	// Same hardware with different spelling
+	gpu.Hardware = "rtx 3070"
+	gpu.AverageSeconds = 1
+	_, err = benchmarkRepo.SubmitBenchmark(bob, gpu)
+	utils.AssertEqual(t, nil, err)
+	_, err = benchmarkRepo.SubmitBenchmark(bob, repository.BenchmarkSubmission{Hardware: "Ryzen 9 5950X", Backend: repository.BenchmarkBackendCPU, DifficultyMultiplier: 1, Runs: 5, AverageSeconds: 2})
+	utils.AssertEqual(t, nil, err)
+
+	leaderboard, err := benchmarkRepo.GetHardwareLeaderboard(nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 2, len(leaderboard))
+	utils.AssertEqual(t, repository.BenchmarkBackendGPU, leaderboard[0].Backend)
+	utils.AssertEqual(t, 2, leaderboard[0].Profiles)
+	utils.AssertEqual(t, 2, leaderboard[0].Providers)
+	// (64/2 + 64/1) / 2
+	utils.AssertEqual(t, 48.0, leaderboard[0].WorkPerSecond)
+	utils.AssertEqual(t, 0.5, leaderboard[1].WorkPerSecond)
+
+	difficulty := 1
+	leaderboard, err = benchmarkRepo.GetHardwareLeaderboard(&difficulty)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(leaderboard))
+	utils.AssertEqual(t, "Ryzen 9 5950X", leaderboard[0].Hardware)
+}
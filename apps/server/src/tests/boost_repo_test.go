@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestBoostRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	boostRepo := repository.NewBoostService(mockDb)
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+	email := "requester@gmail.com"
+	user, _ := userRepo.GetUser(nil, &email)
+	// Whoever recorded it
+	recorderEmail := "provider@gmail.com"
+	recorder, _ := userRepo.GetUser(nil, &recorderEmail)
+
+	hash := "3F93C5CD2E314FA16702189041E68E68C07B27961BF37F0B7705145BEC28CA45"
+	_, err = boostRepo.RecordDeposit(user.ID, "1000000000000000000000000000000", hash, recorder.ID)
+	utils.AssertEqual(t, nil, err)
+	// The same block can't be credited twice
+	_, err = boostRepo.RecordDeposit(user.ID, "1000000000000000000000000000000", hash, recorder.ID)
+	utils.AssertEqual(t, repository.ErrDepositRecorded, err)
+
+	pricing := models.BoostPricing{BananoPerMinute: 0.5, DailyCapMinutes: 30}
+	now := time.Now()
+	first, err := boostRepo.PurchaseBoost(user, 10, pricing, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "500000000000000000000000000000", first.PriceRaw)
+	// Stacks onto the running boost
+	second, err := boostRepo.PurchaseBoost(user, 10, pricing, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, second.StartsAt.Equal(first.EndsAt))
+	_, err = boostRepo.PurchaseBoost(user, 11, pricing, now)
+	utils.AssertEqual(t, repository.ErrBoostCapReached, err)
+
+	balance, err := boostRepo.GetCreditBalance(user.ID)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "0", balance.String())
+	_, err = boostRepo.PurchaseBoost(user, 1, pricing, now)
+	utils.AssertEqual(t, repository.ErrInsufficientCredit, err)
+
+	active, err := boostRepo.GetActiveBoost(user.ID, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, second.ID, active.ID)
+	minutes, err := boostRepo.GetBoostMinutesSince(user.ID, models.StartOfDay(now))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 20, minutes)
+	entries, err := boostRepo.GetCreditEntries(user.ID, pagination.Args{First: 10})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 3, len(entries))
+
+	economics, err := boostRepo.GetBoostEconomics(user.TenantID, now.Add(-time.Hour))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 2, economics.Boosts)
+	utils.AssertEqual(t, 20, economics.Minutes)
+	utils.AssertEqual(t, 1, economics.Buyers)
+	utils.AssertEqual(t, "1000000000000000000000000000000", economics.RevenueRaw)
+}
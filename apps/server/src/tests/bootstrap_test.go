@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+// Bootstrapping again must not change anything
+func TestBootstrapIsIdempotent(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+
+	created, err := userRepo.EnsureAdminUser("Admin@example.com", "Password123!", "default")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, created)
+	created, err = userRepo.EnsureAdminUser("admin@example.com", "Different123!", "default")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, created)
+	email := "admin@example.com"
+	admin, err := userRepo.GetUser(nil, &email)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, admin.EmailVerified)
+	utils.AssertEqual(t, models.REQUESTER, admin.Type)
+
+	created, token, err := userRepo.EnsureService("kalium@banano.cc", "kalium", "https://kalium.banano.cc", "default", "")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, created)
+	created, again, err := userRepo.EnsureService("kalium@banano.cc", "kalium", "https://kalium.banano.cc", "default", "")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, created)
+	utils.AssertEqual(t, token, again)
+
+	// Rotating the token invalidates the old one
+	_, rotated, err := userRepo.EnsureService("kalium@banano.cc", "kalium", "https://kalium.banano.cc", "default", "service:rotated")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "service:rotated", rotated)
+	_, err = database.GetRedisDB().GetServiceTokenUser(token)
+	utils.AssertNotEqual(t, nil, err)
+
+	// Bootstrapped tokens work right away, without being listed in BPOW_SERVICE_TOKENS
+	handler := middleware.AuthMiddleware(userRepo, nil, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if middleware.AuthorizedServiceToken(r.Context()) == nil {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	for token, status := range map[string]int{rotated: http.StatusOK, token: http.StatusForbidden} {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		req.Header.Set("Authorization", token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		utils.AssertEqual(t, status, rec.Code)
+	}
+
+	_, _, err = userRepo.EnsureService("kalium@banano.cc", "kalium", "https://kalium.banano.cc", "default", "nope")
+	utils.AssertNotEqual(t, nil, err)
+}
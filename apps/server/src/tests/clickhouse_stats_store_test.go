@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+// Test the ClickHouse stats store against a fake of its HTTP interface
+func TestClickHouseStatsStore(t *testing.T) {
+	var inserted []repository.WorkEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.AssertEqual(t, "boompow", r.URL.Query().Get("database"))
+		body, _ := io.ReadAll(r.Body)
+		query := r.URL.Query().Get("query")
+		switch {
+		case strings.HasPrefix(query, "INSERT INTO work_events"):
+			utils.AssertEqual(t, "1", r.URL.Query().Get("async_insert"))
+			var event repository.WorkEvent
+			utils.AssertEqual(t, nil, json.Unmarshal(body, &event))
+			inserted = append(inserted, event)
+		case strings.Contains(string(body), "GROUP BY difficulty_multiplier"):
+			utils.AssertEqual(t, "default", r.URL.Query().Get("param_tenant_id"))
+			w.Write([]byte("{\"difficulty_multiplier\":1,\"count\":2}\n{\"difficulty_multiplier\":64,\"count\":1}\n"))
+		case strings.Contains(string(body), "FROM work_events"):
+			w.Write([]byte("{\"provided_by\":\"6ba7b810-9dad-11d1-80b4-00c04fd430c8\",\"requested_by\":\"6ba7b811-9dad-11d1-80b4-00c04fd430c8\",\"difficulty_multiplier\":64,\"solve_latency_ms\":50,\"precache\":true,\"created_at_ms\":1664629200000}\n"))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Code: 62. DB::Exception: Syntax error"))
+		}
+	}))
+	defer server.Close()
+	store := repository.NewClickHouseStatsStore(server.URL + "/?database=boompow")
+
+	event := repository.WorkEvent{TenantID: "default", Hash: "1", ProvidedBy: uuid.New(), RequestedBy: uuid.New(), DifficultyMultiplier: 64, CreatedAt: time.Now().UTC()}
+	utils.AssertEqual(t, nil, store.RecordWorkEvent(event))
+	utils.AssertEqual(t, 1, len(inserted))
+	utils.AssertEqual(t, event.ProvidedBy, inserted[0].ProvidedBy)
+
+	buckets, err := store.GetDifficultyDistribution("default", time.Now().Add(-24*time.Hour))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 2, len(buckets))
+	utils.AssertEqual(t, int64(2), buckets[0].Count)
+	utils.AssertEqual(t, 64, buckets[1].DifficultyMultiplier)
+
+	records, err := store.GetResearchRecords(time.Now().Add(-24 * time.Hour))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(records))
+	utils.AssertEqual(t, true, records[0].Precache)
+	utils.AssertEqual(t, time.Date(2022, 10, 1, 13, 0, 0, 0, time.UTC), records[0].CreatedAt)
+
+	// Errors from ClickHouse are passed on
+	utils.AssertNotEqual(t, nil, store.CreateTable())
+}
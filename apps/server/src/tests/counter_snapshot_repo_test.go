@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestCounterSnapshotRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	repo := repository.NewCounterSnapshotService(mockDb)
+	redis := database.GetRedisDB()
+	redis.Del("clientscores")
+	redis.WipeAllConnectedClients()
+	now := time.Date(2022, 10, 3, 12, 2, 0, 0, time.UTC)
+
+	redis.UpdateClientScore("1.1.1.1", 5)
+	redis.AddConnectedClient("1.1.1.1", "default")
+	redis.AddConnectedClient("2.2.2.2", "default")
+	utils.AssertEqual(t, nil, repository.SnapshotCounters(repo, []string{"default", "mypool"}, now))
+	values, err := repo.GetCounterValues(now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, map[string]string{"1.1.1.1": "5"}, clientScores(values))
+
+	// Another replica in the same interval doesn't add a point
+	redis.AddConnectedClient("3.3.3.3", "default")
+	utils.AssertEqual(t, nil, repository.SnapshotCounters(repo, []string{"default"}, now.Add(time.Minute)))
+	history, err := repo.GetConnectedWorkersHistory("default", now.Add(-time.Hour))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(history))
+	utils.AssertEqual(t, int64(2), history[0].Workers)
+	utils.AssertEqual(t, time.Date(2022, 10, 3, 12, 0, 0, 0, time.UTC), history[0].TakenAt.UTC())
+	history, _ = repo.GetConnectedWorkersHistory("mypool", now.Add(-time.Hour))
+	utils.AssertEqual(t, int64(0), history[0].Workers)
+
+	// Redis lost everything, the next snapshot restores the scores instead of overwriting them
+	redis.Del("clientscores")
+	redis.Del("countersintact")
+	redis.WipeAllConnectedClients()
+	utils.AssertEqual(t, nil, repository.SnapshotCounters(repo, []string{"default"}, now.Add(10*time.Minute)))
+	utils.AssertEqual(t, 5, redis.GetClientScore("1.1.1.1"))
+	history, _ = repo.GetConnectedWorkersHistory("default", now.Add(-time.Hour))
+	utils.AssertEqual(t, 2, len(history))
+	utils.AssertEqual(t, int64(0), history[1].Workers)
+
+	// Scores wiped by a payout are gone from the copy too
+	redis.Del("clientscores")
+	utils.AssertEqual(t, nil, repository.SnapshotCounters(repo, []string{"default"}, now.Add(15*time.Minute)))
+	values, _ = repo.GetCounterValues(now)
+	utils.AssertEqual(t, map[string]string{}, clientScores(values))
+}
+
+// Other tests leave counters of their own behind
+func clientScores(values []models.CounterValue) map[string]string {
+	scores := map[string]string{}
+	for _, value := range values {
+		if value.Key == "clientscores" {
+			scores[value.Field] = value.Value
+		}
+	}
+	return scores
+}
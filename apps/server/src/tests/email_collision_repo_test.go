@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/graph/model"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestEmailCollisionRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	collisionRepo := repository.NewEmailCollisionService(mockDb)
+
+	// Accounts from before emails were normalized
+	for _, email := range []string{"joe@gmail.com", "j.o.e@gmail.com", "other@gmail.com"} {
+		created, err := userRepo.EnsureUser(&models.User{Email: email, Type: models.PROVIDER, TenantID: "default"}, "Password123!")
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, true, created)
+	}
+	err = mockDb.Model(&models.User{}).Where("1 = 1").UpdateColumn("normalized_email", "").Error
+	utils.AssertEqual(t, nil, err)
+
+	// Normalized and flagged on migration
+	err = database.Migrate(mockDb)
+	utils.AssertEqual(t, nil, err)
+	collisions, err := collisionRepo.GetEmailCollisions(false)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(collisions))
+	utils.AssertEqual(t, "joe@gmail.com", collisions[0].NormalizedEmail)
+	utils.AssertEqual(t, "joe@gmail.com", collisions[0].Users[0].Email)
+	utils.AssertEqual(t, "j.o.e@gmail.com", collisions[0].Users[1].Email)
+
+	// Ambiguous aliases don't log in, the exact emails still do
+	utils.AssertEqual(t, true, userRepo.Authenticate(&model.LoginInput{Email: "jo.e@gmail.com", Password: "Password123!"}) == nil)
+	utils.AssertEqual(t, true, userRepo.Authenticate(&model.LoginInput{Email: "j.o.e@gmail.com", Password: "Password123!"}) != nil)
+
+	email := "other@gmail.com"
+	reviewer, _ := userRepo.GetUser(nil, &email)
+	reviewed, err := collisionRepo.ReviewEmailCollision("joe@gmail.com", reviewer.ID, time.Now())
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, reviewed)
+	reviewed, err = collisionRepo.ReviewEmailCollision("joe@gmail.com", reviewer.ID, time.Now())
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, reviewed)
+	collisions, err = collisionRepo.GetEmailCollisions(false)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(collisions))
+	collisions, err = collisionRepo.GetEmailCollisions(true)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(collisions))
+
+	// Reviewed collisions aren't flagged again
+	err = database.Migrate(mockDb)
+	utils.AssertEqual(t, nil, err)
+	collisions, err = collisionRepo.GetEmailCollisions(false)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(collisions))
+}
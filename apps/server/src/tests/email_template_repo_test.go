@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+func TestEmailTemplateRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	emailTemplateRepo := repository.NewEmailTemplateService(mockDb)
+
+	// Never edited
+	template, err := emailTemplateRepo.GetEmailTemplate("offlinealert", "en")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, (*models.EmailTemplate)(nil), template)
+
+	adminID := uuid.New()
+	first := &models.EmailTemplate{Name: "offlinealert", Language: "en", Subject: "Offline", Body: "v1", CreatedBy: adminID}
+	utils.AssertEqual(t, nil, emailTemplateRepo.SaveEmailTemplate(first))
+	utils.AssertEqual(t, 1, first.Version)
+	second := &models.EmailTemplate{Name: "offlinealert", Language: "en", Subject: "Offline", Body: "v2", CreatedBy: adminID}
+	utils.AssertEqual(t, nil, emailTemplateRepo.SaveEmailTemplate(second))
+	utils.AssertEqual(t, 2, second.Version)
+	// Languages have their own versions
+	spanish := &models.EmailTemplate{Name: "offlinealert", Language: "es", Subject: "Desconectado", Body: "v1", CreatedBy: adminID}
+	utils.AssertEqual(t, nil, emailTemplateRepo.SaveEmailTemplate(spanish))
+	utils.AssertEqual(t, 1, spanish.Version)
+
+	template, err = emailTemplateRepo.GetEmailTemplate("offlinealert", "en")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "v2", template.Body)
+	template, err = emailTemplateRepo.GetEmailTemplateVersion("offlinealert", "en", 1)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "v1", template.Body)
+	_, err = emailTemplateRepo.GetEmailTemplateVersion("offlinealert", "en", 3)
+	utils.AssertNotEqual(t, nil, err)
+
+	versions, err := emailTemplateRepo.GetEmailTemplateVersions("offlinealert", "en")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 2, len(versions))
+	utils.AssertEqual(t, 2, versions[0].Version)
+
+	latest, err := emailTemplateRepo.GetEmailTemplates()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 2, len(latest))
+	utils.AssertEqual(t, "en", latest[0].Language)
+	utils.AssertEqual(t, 2, latest[0].Version)
+	utils.AssertEqual(t, "es", latest[1].Language)
+}
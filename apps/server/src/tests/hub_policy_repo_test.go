@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+func TestHubPolicyRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	hubPolicyRepo := repository.NewHubPolicyService(mockDb)
+
+	// Nothing saved yet
+	policy, err := hubPolicyRepo.GetHubPolicy()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, models.DefaultHubPolicy(), *policy)
+
+	adminID := uuid.New()
+	policy.Retries = 2
+	policy.MaxInFlightPerWorker = 4
+	policy.UpdatedBy = adminID
+	utils.AssertEqual(t, nil, hubPolicyRepo.SaveHubPolicy(policy))
+	// Saving again replaces the only row
+	policy.TimeoutSeconds = 10
+	utils.AssertEqual(t, nil, hubPolicyRepo.SaveHubPolicy(policy))
+
+	saved, err := hubPolicyRepo.GetHubPolicy()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 10, saved.TimeoutSeconds)
+	utils.AssertEqual(t, 2, saved.Retries)
+	utils.AssertEqual(t, 4, saved.MaxInFlightPerWorker)
+	utils.AssertEqual(t, adminID, saved.UpdatedBy)
+	var count int64
+	mockDb.Model(&models.HubPolicy{}).Count(&count)
+	utils.AssertEqual(t, int64(1), count)
+
+	// Invalid policies aren't saved
+	policy.TimeoutSeconds = 0
+	utils.AssertNotEqual(t, nil, hubPolicyRepo.SaveHubPolicy(policy))
+}
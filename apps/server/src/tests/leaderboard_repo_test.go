@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestLeaderboardRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	workRepo := repository.NewWorkService(mockDb, userRepo)
+	leaderboardRepo := repository.NewLeaderboardService(mockDb)
+
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+	providerEmail := "provider@gmail.com"
+	requesterEmail := "requester@gmail.com"
+	provider, _ := userRepo.GetUser(nil, &providerEmail)
+
+	for _, hash := range []string{"123", "456"} {
+		_, err = workRepo.SaveOrUpdateWorkResult(repository.WorkMessage{
+			RequestedByEmail:     requesterEmail,
+			ProvidedByEmail:      providerEmail,
+			Hash:                 hash,
+			Result:               "ac",
+			DifficultyMultiplier: 8,
+			BlockAward:           true,
+			TenantID:             "default",
+		})
+		utils.AssertEqual(t, nil, err)
+	}
+	// Older than a day
+	err = mockDb.Model(&models.WorkResult{}).Where("hash = ?", "456").Update("created_at", time.Now().Add(-48*time.Hour)).Error
+	utils.AssertEqual(t, nil, err)
+
+	// Empty until refreshed
+	entries, err := leaderboardRepo.GetLeaderboard("default", models.LeaderboardDay, 10)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(entries))
+
+	now := time.Now()
+	for _, window := range []models.LeaderboardWindow{models.LeaderboardDay, models.LeaderboardAll} {
+		err = leaderboardRepo.RefreshLeaderboard("default", window, 10, now)
+		utils.AssertEqual(t, nil, err)
+	}
+	entries, err = leaderboardRepo.GetLeaderboard("default", models.LeaderboardDay, 10)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(entries))
+	utils.AssertEqual(t, 1, entries[0].Rank)
+	utils.AssertEqual(t, provider.ID, entries[0].ProviderID)
+	utils.AssertEqual(t, *provider.BanAddress, entries[0].BanAddress)
+	utils.AssertEqual(t, int64(1), entries[0].WorkUnits)
+	utils.AssertEqual(t, int64(8), entries[0].DifficultySum)
+	entries, err = leaderboardRepo.GetLeaderboard("default", models.LeaderboardAll, 10)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int64(2), entries[0].WorkUnits)
+
+	// Refreshing again replaces the entries
+	err = leaderboardRepo.RefreshLeaderboard("default", models.LeaderboardAll, 10, now)
+	utils.AssertEqual(t, nil, err)
+	entries, err = leaderboardRepo.GetLeaderboard("default", models.LeaderboardAll, 10)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(entries))
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+func TestValidateMaintenanceWindow(t *testing.T) {
+	now := time.Now()
+	utils.AssertEqual(t, nil, repository.ValidateMaintenanceWindow(&models.MaintenanceWindow{StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)}, now))
+	// Already started is fine
+	utils.AssertEqual(t, nil, repository.ValidateMaintenanceWindow(&models.MaintenanceWindow{StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}, now))
+	utils.AssertNotEqual(t, nil, repository.ValidateMaintenanceWindow(&models.MaintenanceWindow{StartsAt: now.Add(time.Hour), EndsAt: now.Add(time.Hour)}, now))
+	utils.AssertNotEqual(t, nil, repository.ValidateMaintenanceWindow(&models.MaintenanceWindow{StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)}, now))
+	utils.AssertNotEqual(t, nil, repository.ValidateMaintenanceWindow(&models.MaintenanceWindow{StartsAt: now, EndsAt: now.Add(25 * time.Hour)}, now))
+}
+
+func TestMaintenanceRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	maintenanceRepo := repository.NewMaintenanceService(mockDb)
+
+	now := time.Now()
+	later := &models.MaintenanceWindow{Description: "later", StartsAt: now.Add(5 * time.Hour), EndsAt: now.Add(6 * time.Hour), CreatedBy: uuid.New()}
+	sooner := &models.MaintenanceWindow{Description: "sooner", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour), CreatedBy: uuid.New()}
+	utils.AssertEqual(t, nil, maintenanceRepo.CreateMaintenanceWindow(later))
+	utils.AssertEqual(t, nil, maintenanceRepo.CreateMaintenanceWindow(sooner))
+	utils.AssertNotEqual(t, nil, maintenanceRepo.CreateMaintenanceWindow(&models.MaintenanceWindow{StartsAt: now, EndsAt: now.Add(-time.Hour), CreatedBy: uuid.New()}))
+
+	windows, err := maintenanceRepo.GetMaintenanceWindows(now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 2, len(windows))
+	utils.AssertEqual(t, "sooner", windows[0].Description)
+	// Ended by then
+	windows, err = maintenanceRepo.GetMaintenanceWindows(now.Add(3 * time.Hour))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(windows))
+
+	utils.AssertEqual(t, nil, maintenanceRepo.CancelMaintenanceWindow(later.ID))
+	utils.AssertNotEqual(t, nil, maintenanceRepo.CancelMaintenanceWindow(later.ID))
+	windows, err = maintenanceRepo.GetMaintenanceWindows(now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(windows))
+}
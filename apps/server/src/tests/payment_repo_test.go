@@ -4,12 +4,14 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/bananocoin/boompow/apps/server/src/database"
 	"github.com/bananocoin/boompow/apps/server/src/repository"
 	"github.com/bananocoin/boompow/libs/models"
 	"github.com/bananocoin/boompow/libs/utils/number"
 	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
 )
 
 // Test payment repo
@@ -52,7 +54,7 @@ func TestPaymentrepo(t *testing.T) {
 			PaidTo: provider.ID,
 		})
 	}
-	err = paymentRepo.BatchCreateSendRequests(mockDb, sendRequestsRaw)
+	err = paymentRepo.BatchCreateSendRequests(mockDb, "default", nil, sendRequestsRaw)
 	utils.AssertEqual(t, nil, err)
 
 	// Get payments
@@ -77,7 +79,132 @@ func TestPaymentrepo(t *testing.T) {
 	}
 
 	// Check out total paid
-	totalPaid, err := paymentRepo.GetTotalPaidBanano()
+	totalPaid, err := paymentRepo.GetTotalPaidBanano("default")
 	utils.AssertEqual(t, nil, err)
 	utils.AssertEqual(t, 3.0+1728016, totalPaid)
+
+	// A pending payment is only claimed by one run at a time, sent ones aren't claimed
+	tx := mockDb.Begin()
+	claimed, err := paymentRepo.ClaimPendingPayment(tx, "0")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, claimed)
+	other := mockDb.Begin()
+	claimed, err = paymentRepo.ClaimPendingPayment(other, "0")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, claimed)
+	claimed, err = paymentRepo.ClaimPendingPayment(other, "1")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, claimed)
+	utils.AssertEqual(t, nil, other.Rollback().Error)
+	utils.AssertEqual(t, nil, tx.Rollback().Error)
+
+	// Idempotency keys only depend on the cycle, provider and address
+	cycleID := uuid.New()
+	utils.AssertEqual(t, repository.PaymentSendID(cycleID, provider.ID, "ban_1"), repository.PaymentSendID(cycleID, provider.ID, "ban_1"))
+	utils.AssertNotEqual(t, repository.PaymentSendID(cycleID, provider.ID, "ban_1"), repository.PaymentSendID(cycleID, provider.ID, "ban_2"))
+}
+
+func TestTryLockPayouts(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	payoutCycleRepo := repository.NewPayoutCycleService(mockDb)
+
+	tx := mockDb.Begin()
+	locked, err := payoutCycleRepo.TryLockPayouts(tx)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, locked)
+	other := mockDb.Begin()
+	locked, err = payoutCycleRepo.TryLockPayouts(other)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, locked)
+	utils.AssertEqual(t, nil, other.Rollback().Error)
+
+	// It's released with the transaction
+	utils.AssertEqual(t, nil, tx.Rollback().Error)
+	other = mockDb.Begin()
+	locked, err = payoutCycleRepo.TryLockPayouts(other)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, locked)
+	utils.AssertEqual(t, nil, other.Rollback().Error)
+}
+
+func TestPayoutStatements(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	paymentRepo := repository.NewPaymentService(mockDb)
+	payoutCycleRepo := repository.NewPayoutCycleService(mockDb)
+
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+	providerEmail := "provider@gmail.com"
+	provider, _ := userRepo.GetUser(nil, &providerEmail)
+	utils.AssertEqual(t, true, provider.PayoutStatementEmails)
+
+	before := time.Now().Add(-time.Minute)
+	cycle, err := payoutCycleRepo.CreatePayoutCycle(mockDb, "default", 10, []repository.UnpaidWorkResult{
+		{UnpaidSumResult: repository.UnpaidSumResult{DifficultySum: 3}, UnpaidCount: 3, ProvidedBy: provider.ID, BanAddress: "ban_1"},
+	})
+	utils.AssertEqual(t, nil, err)
+
+	// Not closed without payments, or while one hasn't been broadcast
+	cycles, err := payoutCycleRepo.GetClosedPayoutCycles(before)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(cycles))
+	err = paymentRepo.BatchCreateSendRequests(mockDb, "default", &cycle.ID, []models.SendRequest{
+		{BaseRequest: models.SendAction, Destination: "ban_1", AmountRaw: number.BananoToRaw(10), ID: "statement", PaidTo: provider.ID},
+	})
+	utils.AssertEqual(t, nil, err)
+	cycles, err = payoutCycleRepo.GetClosedPayoutCycles(before)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(cycles))
+	err = paymentRepo.SetBlockHash(mockDb, "statement", "statement")
+	utils.AssertEqual(t, nil, err)
+	cycles, err = payoutCycleRepo.GetClosedPayoutCycles(before)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(cycles))
+	// Too old
+	cycles, err = payoutCycleRepo.GetClosedPayoutCycles(time.Now().Add(time.Minute))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(cycles))
+
+	// Claimed once, released ones can be claimed again
+	claimed, err := payoutCycleRepo.ClaimPayoutStatement(cycle.ID, provider.ID, false)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, claimed)
+	claimed, err = payoutCycleRepo.ClaimPayoutStatement(cycle.ID, provider.ID, false)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, claimed)
+	// Everyone has theirs
+	cycles, err = payoutCycleRepo.GetClosedPayoutCycles(before)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(cycles))
+	err = payoutCycleRepo.ReleasePayoutStatement(cycle.ID, provider.ID)
+	utils.AssertEqual(t, nil, err)
+	claimed, err = payoutCycleRepo.ClaimPayoutStatement(cycle.ID, provider.ID, true)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, claimed)
+
+	err = userRepo.SetPayoutStatementEmails(provider.ID, false)
+	utils.AssertEqual(t, nil, err)
+	provider, _ = userRepo.GetUser(nil, &providerEmail)
+	utils.AssertEqual(t, false, provider.PayoutStatementEmails)
 }
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/utils/number"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+const coldWallet = "ban_3bsnis6ha3m9cepuaywskn9jykdggxcu8mxsp76yc3oinrt3n7gi77xiggtm"
+const spendingWallet = "ban_1zyb1s96twbtycqwgh1o6wsnpsksgdoohokikgjqjaz63pxnju457pz8tm3r"
+
+// Test payout split validation
+func TestValidatePayoutSplits(t *testing.T) {
+	// No splits is fine, that pays the account address
+	utils.AssertEqual(t, nil, repository.ValidatePayoutSplits([]repository.PayoutSplit{}))
+	utils.AssertEqual(t, nil, repository.ValidatePayoutSplits([]repository.PayoutSplit{
+		{BanAddress: coldWallet, Percent: 80},
+		{BanAddress: spendingWallet, Percent: 20},
+	}))
+	// Doesn't add up
+	utils.AssertNotEqual(t, nil, repository.ValidatePayoutSplits([]repository.PayoutSplit{
+		{BanAddress: coldWallet, Percent: 80},
+		{BanAddress: spendingWallet, Percent: 10},
+	}))
+	// Duplicate
+	utils.AssertNotEqual(t, nil, repository.ValidatePayoutSplits([]repository.PayoutSplit{
+		{BanAddress: coldWallet, Percent: 50},
+		{BanAddress: coldWallet, Percent: 50},
+	}))
+	// Invalid address
+	utils.AssertNotEqual(t, nil, repository.ValidatePayoutSplits([]repository.PayoutSplit{
+		{BanAddress: "ban_invalid", Percent: 100},
+	}))
+	// Zero percent
+	utils.AssertNotEqual(t, nil, repository.ValidatePayoutSplits([]repository.PayoutSplit{
+		{BanAddress: coldWallet, Percent: 100},
+		{BanAddress: spendingWallet, Percent: 0},
+	}))
+}
+
+// Test splitting a payout
+func TestSplitPayout(t *testing.T) {
+	shares := repository.SplitPayout(number.BananoIntToRaw(100), coldWallet, nil)
+	utils.AssertEqual(t, []repository.PayoutShare{{BanAddress: coldWallet, AmountRaw: number.BananoIntToRaw(100)}}, shares)
+
+	shares = repository.SplitPayout(number.BananoIntToRaw(100), "ban_default", []models.PayoutAddress{
+		{BanAddress: coldWallet, Percent: 80},
+		{BanAddress: spendingWallet, Percent: 20},
+	})
+	utils.AssertEqual(t, []repository.PayoutShare{{BanAddress: coldWallet, AmountRaw: number.BananoIntToRaw(80)}, {BanAddress: spendingWallet, AmountRaw: number.BananoIntToRaw(20)}}, shares)
+
+	// The raw that can't be split evenly goes to the first address
+	shares = repository.SplitPayout(big.NewInt(101), "ban_default", []models.PayoutAddress{
+		{BanAddress: coldWallet, Percent: 50},
+		{BanAddress: spendingWallet, Percent: 50},
+	})
+	utils.AssertEqual(t, big.NewInt(51), shares[0].AmountRaw)
+	utils.AssertEqual(t, big.NewInt(50), shares[1].AmountRaw)
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestRoleRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	roleRepo := repository.NewRoleService(mockDb)
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+	email := "provider@gmail.com"
+	user, _ := userRepo.GetUser(nil, &email)
+	utils.AssertEqual(t, 0, len(user.Roles))
+
+	granted, err := roleRepo.GrantRole(user.ID, models.RoleModerator, nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, granted)
+	// Granting it again changes nothing
+	granted, err = roleRepo.GrantRole(user.ID, models.RoleModerator, nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, granted)
+
+	// Roles are loaded with the user
+	user, _ = userRepo.GetUser(nil, &email)
+	utils.AssertEqual(t, true, user.HasPermission(models.PermissionBanUsers))
+
+	now := time.Now()
+	banned, err := roleRepo.BanUser(user.ID, nil, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, banned)
+	user, _ = userRepo.GetUser(nil, &email)
+	utils.AssertEqual(t, true, user.Banned())
+	utils.AssertEqual(t, true, user.SessionRevoked(now.Add(-time.Minute)))
+
+	revoked, err := roleRepo.RevokeRole(user.ID, models.RoleBanned)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, revoked)
+	roles, err := roleRepo.GetRoles(user.ID)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(roles))
+	utils.AssertEqual(t, models.RoleModerator, roles[0].Role)
+}
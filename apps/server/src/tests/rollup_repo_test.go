@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/utils/clock"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+// Test rollup repo
+func TestRollupRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	rollupRepo := repository.NewRollupService(mockDb)
+
+	now := time.Now()
+	utils.AssertEqual(t, nil, rollupRepo.IncrementDifficultyRollup("default", 1, now))
+	utils.AssertEqual(t, nil, rollupRepo.IncrementDifficultyRollup("default", 1, now))
+	utils.AssertEqual(t, nil, rollupRepo.IncrementDifficultyRollup("default", 64, now))
+	// Too old to be included
+	utils.AssertEqual(t, nil, rollupRepo.IncrementDifficultyRollup("default", 64, now.Add(-48*time.Hour)))
+	// Different tenant
+	utils.AssertEqual(t, nil, rollupRepo.IncrementDifficultyRollup("other", 1, now))
+
+	buckets, err := rollupRepo.GetDifficultyDistribution("default", now.Add(-24*time.Hour))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 2, len(buckets))
+	utils.AssertEqual(t, 1, buckets[0].DifficultyMultiplier)
+	utils.AssertEqual(t, int64(2), buckets[0].Count)
+	utils.AssertEqual(t, 64, buckets[1].DifficultyMultiplier)
+	utils.AssertEqual(t, int64(1), buckets[1].Count)
+}
+
+// Test checking rollups against work results
+func TestCheckDifficultyRollups(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	workRepo := repository.NewWorkService(mockDb, userRepo)
+	rollupRepo := repository.NewRollupService(mockDb)
+	utils.AssertEqual(t, nil, userRepo.CreateMockUsers())
+
+	// Work saved without its rollup increment, like a lost update
+	for _, hash := range []string{"1", "2"} {
+		_, err = workRepo.SaveOrUpdateWorkResult(repository.WorkMessage{
+			RequestedByEmail:     "requester@gmail.com",
+			ProvidedByEmail:      "provider@gmail.com",
+			Hash:                 hash,
+			Result:               "ac",
+			DifficultyMultiplier: 5,
+		})
+		utils.AssertEqual(t, nil, err)
+	}
+	utils.AssertEqual(t, nil, rollupRepo.IncrementDifficultyRollup("default", 5, time.Now()))
+
+	now := time.Now()
+	drifts, err := rollupRepo.CheckDifficultyRollups(now.Add(-time.Hour), now.Add(time.Hour), 0.5, false)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(drifts))
+	utils.AssertEqual(t, int64(1), drifts[0].Rollup)
+	utils.AssertEqual(t, int64(2), drifts[0].Actual)
+	utils.AssertEqual(t, false, drifts[0].Corrected)
+
+	// Outside of tolerance nothing is corrected
+	drifts, err = rollupRepo.CheckDifficultyRollups(now.Add(-time.Hour), now.Add(time.Hour), 0.1, true)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, drifts[0].Corrected)
+
+	drifts, err = rollupRepo.CheckDifficultyRollups(now.Add(-time.Hour), now.Add(time.Hour), 0.5, true)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, drifts[0].Corrected)
+	drifts, err = rollupRepo.CheckDifficultyRollups(now.Add(-time.Hour), now.Add(time.Hour), 0.5, false)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(drifts))
+}
+
+// Work at the very end of a month lands in the same period in the rollups and the usage
+func TestStatsWorkerPeriodBoundary(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	workRepo := repository.NewWorkService(mockDb, userRepo)
+	rollupRepo := repository.NewRollupService(mockDb)
+	usageRepo := repository.NewUsageService(mockDb)
+	utils.AssertEqual(t, nil, userRepo.CreateMockUsers())
+
+	endOfMonth := time.Date(2022, 10, 31, 23, 59, 59, 999000000, time.UTC)
+	workRepo.SetClock(clock.NewFake(endOfMonth))
+	statsChan := make(chan repository.WorkMessage, 1)
+	statsChan <- repository.WorkMessage{
+		RequestedByEmail:     "requester@gmail.com",
+		ProvidedByEmail:      "provider@gmail.com",
+		Hash:                 "1",
+		Result:               "ac",
+		DifficultyMultiplier: 5,
+	}
+	close(statsChan)
+	workRepo.StatsWorker(statsChan, nil)
+
+	buckets, err := rollupRepo.GetDifficultyDistribution("default", endOfMonth.Add(-time.Minute))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(buckets))
+	requesterEmail := "requester@gmail.com"
+	requester, err := userRepo.GetUser(nil, &requesterEmail)
+	utils.AssertEqual(t, nil, err)
+	usage, err := usageRepo.GetUsage(requester.ID, endOfMonth)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(usage))
+	utils.AssertEqual(t, int64(1), usage[0].Requests)
+}
+
+func TestRollupDriftTolerance(t *testing.T) {
+	utils.AssertEqual(t, true, repository.RollupDrift{Rollup: 95, Actual: 100}.WithinTolerance(0.05))
+	utils.AssertEqual(t, false, repository.RollupDrift{Rollup: 94, Actual: 100}.WithinTolerance(0.05))
+	utils.AssertEqual(t, false, repository.RollupDrift{Rollup: 0, Actual: 1}.WithinTolerance(0.05))
+}
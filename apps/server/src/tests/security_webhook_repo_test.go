@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestSecurityWebhookRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	webhookRepo := repository.NewSecurityWebhookService(mockDb)
+	activityRepo := repository.NewActivityService(mockDb)
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+	email := "provider@gmail.com"
+	user, _ := userRepo.GetUser(nil, &email)
+
+	webhook, err := webhookRepo.GetSecurityWebhook(user.ID)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, (*models.SecurityWebhook)(nil), webhook)
+	_, err = webhookRepo.SetSecurityWebhook(user.ID, "http://example.com/siem")
+	utils.AssertNotEqual(t, nil, err)
+
+	first, err := webhookRepo.SetSecurityWebhook(user.ID, "https://example.com/siem")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 64, len(first.Secret))
+	// Replacing it rotates the secret
+	second, err := webhookRepo.SetSecurityWebhook(user.ID, "https://example.com/other")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertNotEqual(t, first.Secret, second.Secret)
+	webhook, err = webhookRepo.GetSecurityWebhook(user.ID)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "https://example.com/other", webhook.URL)
+	utils.AssertEqual(t, second.Secret, webhook.Secret)
+
+	utils.AssertEqual(t, nil, webhookRepo.DeleteSecurityWebhook(user.ID))
+	webhook, _ = webhookRepo.GetSecurityWebhook(user.ID)
+	utils.AssertEqual(t, (*models.SecurityWebhook)(nil), webhook)
+
+	// Logins from an IP are only new the first time
+	seen, err := activityRepo.HasLoggedInFrom(user.ID, "10.0.0.1")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, seen)
+	utils.AssertEqual(t, nil, activityRepo.RecordAccountEvent(user.ID, models.AccountEventLogin, "", "10.0.0.1"))
+	utils.AssertEqual(t, nil, activityRepo.RecordAccountEvent(user.ID, models.AccountEventPasswordChanged, "", "10.0.0.2"))
+	seen, _ = activityRepo.HasLoggedInFrom(user.ID, "10.0.0.1")
+	utils.AssertEqual(t, true, seen)
+	seen, _ = activityRepo.HasLoggedInFrom(user.ID, "10.0.0.2")
+	utils.AssertEqual(t, false, seen)
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestStaleAccountRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	staleRepo := repository.NewStaleAccountService(mockDb)
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+
+	day := 24 * time.Hour
+	now := time.Now()
+	policy := models.StaleAccountPolicy{RemindAfter: 30 * day, DisableAfter: 14 * day, AnonymizeAfter: 30 * day}
+	signedUp := now.Add(-100 * day)
+	reminded := now.Add(-20 * day)
+	disabled := now.Add(-40 * day)
+	serviceName := "Stale Service"
+	newcomer := &models.User{Base: models.Base{CreatedAt: now.Add(-day)}, Type: models.REQUESTER, Email: "new@example.com", Password: "x"}
+	toRemind := &models.User{Base: models.Base{CreatedAt: signedUp}, Type: models.REQUESTER, Email: "remind@example.com", Password: "x"}
+	toDisable := &models.User{Base: models.Base{CreatedAt: signedUp}, Type: models.PROVIDER, Email: "disable@example.com", Password: "x", StaleReminderSentAt: &reminded}
+	toAnonymize := &models.User{Base: models.Base{CreatedAt: signedUp}, Type: models.REQUESTER, Email: "anonymize@example.com", Password: "x", ServiceName: &serviceName, StaleReminderSentAt: &reminded, DisabledAt: &disabled}
+	exempt := &models.User{Base: models.Base{CreatedAt: signedUp}, Type: models.REQUESTER, Email: "exempt@example.com", Password: "x", StaleExempt: true}
+	for _, user := range []*models.User{newcomer, toRemind, toDisable, toAnonymize, exempt} {
+		utils.AssertEqual(t, nil, mockDb.Create(user).Error)
+	}
+	utils.AssertEqual(t, nil, mockDb.Create(&models.AccountEvent{UserID: toAnonymize.ID, Type: models.AccountEventLogin, ClientIP: "1.2.3.4"}).Error)
+
+	due, err := staleRepo.GetDueStaleAccounts(policy, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 3, len(due))
+
+	// Email isn't configured in tests, so the reminder fails and is tried again on the next run
+	report, err := repository.RunStaleAccountCleanup(staleRepo, policy, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, report.Reminded)
+	utils.AssertEqual(t, 1, report.Disabled)
+	utils.AssertEqual(t, 1, report.Anonymized)
+	utils.AssertEqual(t, 1, report.Failed)
+
+	user, _ := userRepo.GetUser(&toRemind.ID, nil)
+	utils.AssertEqual(t, true, user.StaleReminderSentAt == nil)
+	user, _ = userRepo.GetUser(&toDisable.ID, nil)
+	utils.AssertEqual(t, true, user.Disabled())
+	utils.AssertEqual(t, true, user.SessionRevoked(now.Add(-time.Minute)))
+	user, _ = userRepo.GetUser(&toAnonymize.ID, nil)
+	utils.AssertEqual(t, "anonymized-"+toAnonymize.ID.String()+"@invalid", user.Email)
+	utils.AssertEqual(t, "", user.Password)
+	utils.AssertEqual(t, true, user.ServiceName == nil)
+	utils.AssertEqual(t, true, user.AnonymizedAt != nil)
+	var events int64
+	mockDb.Model(&models.AccountEvent{}).Where("user_id = ?", toAnonymize.ID).Count(&events)
+	utils.AssertEqual(t, int64(0), events)
+
+	// Reminders are retried, disabled accounts wait for their anonymization
+	due, err = staleRepo.GetDueStaleAccounts(policy, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(due))
+	utils.AssertEqual(t, toRemind.ID, due[0].ID)
+
+	// Exempting enables the account again
+	utils.AssertEqual(t, nil, staleRepo.SetStaleExempt(toDisable.ID, true))
+	user, _ = userRepo.GetUser(&toDisable.ID, nil)
+	utils.AssertEqual(t, false, user.Disabled())
+	utils.AssertEqual(t, true, user.StaleReminderSentAt == nil)
+	utils.AssertEqual(t, true, user.StaleExempt)
+
+	reports, err := staleRepo.GetStaleAccountReports(pagination.Args{First: 10})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(reports))
+	count, err := staleRepo.CountStaleAccountReports()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, count)
+
+	// Nothing runs while the policy is off
+	report, err = repository.RunStaleAccountCleanup(staleRepo, models.StaleAccountPolicy{}, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, report == nil)
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/middleware"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/utils/auth"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestTwoFactorRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	twoFactorRepo := repository.NewTwoFactorService(mockDb, bytes.Repeat([]byte{1}, 32))
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+	email := "provider@gmail.com"
+	user, _ := userRepo.GetUser(nil, &email)
+	now := time.Now()
+
+	secret, err := twoFactorRepo.StartTwoFactorEnrollment(user)
+	utils.AssertEqual(t, nil, err)
+	// Only the encrypted secret is stored
+	user, _ = userRepo.GetUser(nil, &email)
+	utils.AssertEqual(t, true, auth.IsEncryptedSecret(*user.TwoFactorSecret))
+	// Not required until it's confirmed
+	utils.AssertEqual(t, false, twoFactorRepo.VerifyTwoFactor(user, "000000", now))
+	_, err = twoFactorRepo.ConfirmTwoFactorEnrollment(user, "000000", now)
+	utils.AssertEqual(t, repository.ErrInvalidTwoFactor, err)
+	code, _ := auth.TOTPCode(secret, now)
+	codes, err := twoFactorRepo.ConfirmTwoFactorEnrollment(user, code, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 10, len(codes))
+	user, _ = userRepo.GetUser(nil, &email)
+	utils.AssertEqual(t, true, user.TwoFactorEnabled)
+	utils.AssertEqual(t, true, twoFactorRepo.VerifyTwoFactor(user, code, now))
+	_, err = twoFactorRepo.StartTwoFactorEnrollment(user)
+	utils.AssertEqual(t, repository.ErrTwoFactorEnabled, err)
+
+	// Recovering turns two factor authentication off and revokes older sessions
+	utils.AssertEqual(t, repository.ErrInvalidTwoFactor, twoFactorRepo.RecoverWithBackupCode(user, "nope", now))
+	utils.AssertEqual(t, nil, twoFactorRepo.RecoverWithBackupCode(user, codes[3], now))
+	user, _ = userRepo.GetUser(nil, &email)
+	utils.AssertEqual(t, false, user.TwoFactorEnabled)
+	utils.AssertEqual(t, (*string)(nil), user.TwoFactorSecret)
+	utils.AssertEqual(t, true, user.SessionRevoked(now.Add(-time.Minute)))
+	utils.AssertEqual(t, false, user.SessionRevoked(now.Add(time.Second)))
+	// The remaining codes are gone with the authenticator
+	utils.AssertEqual(t, repository.ErrTwoFactorNotEnabled, twoFactorRepo.RecoverWithBackupCode(user, codes[4], now))
+	var remaining int64
+	mockDb.Table("backup_codes").Where("used_at IS NULL").Count(&remaining)
+	utils.AssertEqual(t, int64(0), remaining)
+
+	wsToken, _ := auth.GenerateScopedToken(email, auth.WebsocketPurpose, time.Minute, func() time.Time { return now })
+	wsInit := middleware.WebsocketInit(userRepo)
+	_, err = wsInit(context.Background(), transport.InitPayload{middleware.WebsocketTokenField: wsToken})
+	utils.AssertEqual(t, nil, err)
+
+	utils.AssertEqual(t, nil, twoFactorRepo.RevokeSessions(user.ID, now.Add(time.Hour)))
+	user, _ = userRepo.GetUser(nil, &email)
+	utils.AssertEqual(t, true, user.SessionRevoked(now.Add(time.Minute)))
+	// Websocket tokens from before the revocation can't open subscriptions either
+	_, err = wsInit(context.Background(), transport.InitPayload{middleware.WebsocketTokenField: wsToken})
+	utils.AssertNotEqual(t, nil, err)
+
+	// Disabling needs a current code
+	secret, err = twoFactorRepo.StartTwoFactorEnrollment(user)
+	utils.AssertEqual(t, nil, err)
+	code, _ = auth.TOTPCode(secret, now)
+	_, err = twoFactorRepo.ConfirmTwoFactorEnrollment(user, code, now)
+	utils.AssertEqual(t, nil, err)
+	user, _ = userRepo.GetUser(nil, &email)
+	utils.AssertEqual(t, repository.ErrInvalidTwoFactor, twoFactorRepo.DisableTwoFactor(user, "000000", now))
+	utils.AssertEqual(t, nil, twoFactorRepo.DisableTwoFactor(user, code, now))
+	user, _ = userRepo.GetUser(nil, &email)
+	utils.AssertEqual(t, false, user.TwoFactorEnabled)
+	utils.AssertEqual(t, (*string)(nil), user.TwoFactorSecret)
+	utils.AssertEqual(t, repository.ErrTwoFactorNotEnabled, twoFactorRepo.DisableTwoFactor(user, code, now))
+
+	// Secrets stored before they were encrypted are encrypted in place
+	mockDb.Model(user).Updates(map[string]interface{}{"two_factor_secret": secret, "two_factor_enabled": true})
+	encrypted, err := twoFactorRepo.EncryptPlaintextSecrets()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, encrypted)
+	user, _ = userRepo.GetUser(nil, &email)
+	utils.AssertEqual(t, true, auth.IsEncryptedSecret(*user.TwoFactorSecret))
+	utils.AssertEqual(t, true, twoFactorRepo.VerifyTwoFactor(user, code, now))
+}
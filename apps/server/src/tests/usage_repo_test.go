@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"github.com/google/uuid"
+)
+
+func TestUsageRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	usageRepo := repository.NewUsageService(mockDb)
+
+	userID := uuid.New()
+	september := time.Date(2022, 9, 15, 12, 0, 0, 0, time.UTC)
+	utils.AssertEqual(t, nil, usageRepo.RecordUsage(userID, "default", 1, false, september))
+	utils.AssertEqual(t, nil, usageRepo.RecordUsage(userID, "default", 1, false, september))
+	utils.AssertEqual(t, nil, usageRepo.RecordUsage(userID, "default", 1, true, september))
+	utils.AssertEqual(t, nil, usageRepo.RecordUsage(userID, "default", 64, false, september))
+	utils.AssertEqual(t, nil, usageRepo.RecordUsage(userID, "default", 1, false, september.AddDate(0, 1, 0)))
+
+	usage, err := usageRepo.GetUsage(userID, september)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 2, len(usage))
+	utils.AssertEqual(t, int64(2), usage[0].Requests)
+	utils.AssertEqual(t, int64(1), usage[0].Cached)
+	utils.AssertEqual(t, 64, usage[1].DifficultyMultiplier)
+
+	// Only September has ended
+	created, err := usageRepo.CreateUsageStatements(september.AddDate(0, 1, 0))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int64(1), created)
+	created, err = usageRepo.CreateUsageStatements(september.AddDate(0, 1, 0))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int64(0), created)
+
+	statements, err := usageRepo.GetUnsentUsageStatements()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(statements))
+	utils.AssertEqual(t, int64(3), statements[0].Requests)
+	utils.AssertEqual(t, int64(1), statements[0].Cached)
+
+	utils.AssertEqual(t, nil, usageRepo.MarkUsageStatementEmailed(statements[0].ID, time.Now()))
+	statements, err = usageRepo.GetUnsentUsageStatements()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(statements))
+	statements, err = usageRepo.GetUsageStatements(userID, 12)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(statements))
+}
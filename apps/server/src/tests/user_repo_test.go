@@ -7,7 +7,9 @@ import (
 
 	"github.com/bananocoin/boompow/apps/server/graph/model"
 	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/filter"
 	"github.com/bananocoin/boompow/apps/server/src/models"
+	"github.com/bananocoin/boompow/apps/server/src/pagination"
 	"github.com/bananocoin/boompow/apps/server/src/repository"
 	utils "github.com/bananocoin/boompow/libs/utils/testing"
 )
@@ -35,7 +37,7 @@ func TestUserRepo(t *testing.T) {
 		Password:   "Password123!",
 		Type:       model.UserType(models.PROVIDER),
 		BanAddress: &banAddress,
-	}, false)
+	}, "default", false)
 	utils.AssertEqual(t, nil, err)
 
 	// Get user
@@ -71,6 +73,20 @@ func TestUserRepo(t *testing.T) {
 	})
 	utils.AssertEqual(t, true, authenticated == nil)
 
+	// Aliases of the mailbox can't register again, but log in to the account
+	_, err = userRepo.CreateUser(&model.UserInput{
+		Email:      "J.o.e+pow@gmail.com",
+		Password:   "Password123!",
+		Type:       model.UserType(models.PROVIDER),
+		BanAddress: &banAddress,
+	}, "default", false)
+	utils.AssertEqual(t, "Email already exists", err.Error())
+	authenticated = userRepo.Authenticate(&model.LoginInput{
+		Email:    "J.o.e+pow@gmail.com",
+		Password: "Password123!",
+	})
+	utils.AssertEqual(t, "joe@gmail.com", authenticated.Email)
+
 	// Test # Services
 	services, err := userRepo.GetNumberServices()
 	utils.AssertEqual(t, nil, err)
@@ -84,12 +100,22 @@ func TestUserRepo(t *testing.T) {
 		Type:           model.UserType(models.REQUESTER),
 		ServiceName:    &sname,
 		ServiceWebsite: &sWeb,
-	}, false)
+	}, "default", false)
 	utils.AssertEqual(t, nil, err)
 	services, err = userRepo.GetNumberServices()
 	utils.AssertEqual(t, nil, err)
 	utils.AssertEqual(t, 1, int(services))
 
+	// Reset password links work until the password is changed, whatever the case of the email they were requested for
+	resetToken, err := userRepo.GenerateResetPasswordRequest(&model.ResetPasswordInput{Email: "Joe@gmail.com"}, false)
+	utils.AssertEqual(t, nil, err)
+	resetEmail, err := userRepo.CheckResetPasswordToken(resetToken)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "joe@gmail.com", resetEmail)
+	utils.AssertEqual(t, nil, userRepo.ChangePassword(resetEmail, &model.ChangePasswordInput{NewPassword: "Password1234!"}))
+	_, err = userRepo.CheckResetPasswordToken(resetToken)
+	utils.AssertEqual(t, database.ErrResetPasswordTokenInvalid, err)
+
 	// Test delete user
 	userRepo.DeleteUser(user.ID)
 	dbUser, err = userRepo.GetUser(&user.ID, nil)
@@ -101,3 +127,92 @@ func TestUserRepo(t *testing.T) {
 	utils.AssertEqual(t, true, strings.HasPrefix(token, "service:"))
 
 }
+
+func TestListUsers(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+
+	query, err := repository.UserList.Compile([]filter.Condition{
+		{Field: "email", Op: filter.Contains, Values: []string{"GMAIL"}},
+		{Field: "type", Op: filter.Eq, Values: []string{"REQUESTER"}},
+	}, nil)
+	utils.AssertEqual(t, nil, err)
+	users, err := userRepo.ListUsers(query, pagination.Args{First: 10})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(users))
+	utils.AssertEqual(t, "requester@gmail.com", users[0].Email)
+	count, err := userRepo.CountUsers(query)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, count)
+
+	// Oldest first, one per page
+	query, err = repository.UserList.Compile(nil, &filter.Sort{Field: "created_at"})
+	utils.AssertEqual(t, nil, err)
+	args := pagination.Args{First: 1}
+	users, err = userRepo.ListUsers(query, args)
+	utils.AssertEqual(t, nil, err)
+	page := pagination.NewPage(users, args, repository.UserCursor(query.Sort))
+	utils.AssertEqual(t, "provider@gmail.com", page.Items[0].Email)
+	utils.AssertEqual(t, true, page.HasNextPage)
+	args.After = page.EndCursor
+	users, err = userRepo.ListUsers(query, args)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "requester@gmail.com", users[0].Email)
+
+	// Wildcards match literally
+	query, err = repository.UserList.Compile([]filter.Condition{{Field: "email", Op: filter.Contains, Values: []string{"%"}}}, nil)
+	utils.AssertEqual(t, nil, err)
+	count, err = userRepo.CountUsers(query)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, count)
+}
+
+func TestUserAdminSettings(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+	email := "requester@gmail.com"
+	requester, err := userRepo.GetUser(nil, &email)
+	utils.AssertEqual(t, nil, err)
+
+	changed, err := userRepo.SetCanRequestWork(requester.ID, false)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, changed)
+	// Already set
+	changed, err = userRepo.SetCanRequestWork(requester.ID, false)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, changed)
+
+	changed, err = userRepo.SetEmailVerified(requester.ID, false)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, changed)
+	requester, err = userRepo.GetUser(&requester.ID, nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, requester.CanRequestWork)
+	utils.AssertEqual(t, false, requester.EmailVerified)
+}
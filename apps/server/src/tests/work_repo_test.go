@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"math/big"
 	"os"
 	"testing"
 	"time"
@@ -8,7 +9,9 @@ import (
 	"github.com/bananocoin/boompow/apps/server/src/database"
 	"github.com/bananocoin/boompow/apps/server/src/repository"
 	serializableModels "github.com/bananocoin/boompow/libs/models"
+	"github.com/bananocoin/boompow/libs/utils/number"
 	utils "github.com/bananocoin/boompow/libs/utils/testing"
+	"gorm.io/gorm"
 )
 
 // Test stats repo
@@ -77,7 +80,7 @@ func TestStatsRepo(t *testing.T) {
 	utils.AssertEqual(t, provider.ID, workRequest.ProvidedBy)
 
 	// Get other stuff
-	workDifficultySum, err := workRepo.GetUnpaidWorkSum()
+	workDifficultySum, err := workRepo.GetUnpaidWorkSum("default")
 	utils.AssertEqual(t, nil, err)
 	utils.AssertEqual(t, 1500, workDifficultySum)
 	workDifficultySumUser, err := workRepo.GetUnpaidWorkSumForUser(providerEmail)
@@ -85,7 +88,7 @@ func TestStatsRepo(t *testing.T) {
 	utils.AssertEqual(t, 1000, workDifficultySumUser)
 
 	// Test unpaid work group by
-	workResults, err := workRepo.GetUnpaidWorkCountAndMarkAllPaid(mockDb)
+	workResults, err := workRepo.GetUnpaidWorkCountAndMarkAllPaid(mockDb, "default")
 	utils.AssertEqual(t, nil, err)
 	utils.AssertEqual(t, 2, len(workResults))
 	for _, workResult := range workResults {
@@ -100,14 +103,14 @@ func TestStatsRepo(t *testing.T) {
 	}
 
 	// Test get top 10
-	top10, err := workRepo.GetTopContributors(10)
+	top10, err := workRepo.GetTopContributors("default", 10)
 	utils.AssertEqual(t, nil, err)
 	for _, top := range top10 {
 		utils.AssertEqual(t, "ban_3bsnis6ha3m9cepuaywskn9jykdggxcu8mxsp76yc3oinrt3n7gi77xiggtm", top.BanAddress)
 	}
 
 	// Test get services
-	services, err := workRepo.GetServiceStats()
+	services, err := workRepo.GetServiceStats("default")
 	utils.AssertEqual(t, nil, err)
 	utils.AssertEqual(t, 2, len(services))
 	utils.AssertEqual(t, 2, services[0].TotalRequests)
@@ -141,3 +144,196 @@ func TestStatsRepo(t *testing.T) {
 	utils.AssertEqual(t, provider.ID, workRequest.ProvidedBy)
 	utils.AssertEqual(t, 1, len(blockAwardedChan))
 }
+
+func TestSubmitWork(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	workRepo := repository.NewWorkService(mockDb, userRepo)
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+	requesterEmail := "requester@gmail.com"
+	requester, _ := userRepo.GetUser(nil, &requesterEmail)
+
+	hash := "3f93c5cd2e314fa16702189041e68e68c07b27961bf37f0b7705145befba3aa3"
+	_, err = workRepo.SubmitWork(requester, hash, "205452237a9b01f5", 1)
+	utils.AssertNotEqual(t, nil, err)
+
+	stored, err := workRepo.SubmitWork(requester, hash, "205452237a9b01f4", 1)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, stored)
+	// Same difficulty again
+	stored, err = workRepo.SubmitWork(requester, hash, "205452237a9b01f4", 1)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, stored)
+
+	work, err := workRepo.RetrieveWorkFromCache(requester.TenantID, hash, 1)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "205452237a9b01f4", work)
+	// Not enough for a higher difficulty, and not shared with other pools
+	_, err = workRepo.RetrieveWorkFromCache(requester.TenantID, hash, 800)
+	utils.AssertNotEqual(t, nil, err)
+	_, err = workRepo.RetrieveWorkFromCache("mypool", hash, 1)
+	utils.AssertNotEqual(t, nil, err)
+}
+
+// Test that idle precache solves count for less towards payouts
+func TestCreditPercent(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	workRepo := repository.NewWorkService(mockDb, userRepo)
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+
+	providerEmail := "provider@gmail.com"
+	requesterEmail := "requester@gmail.com"
+	_, err = workRepo.SaveOrUpdateWorkResult(repository.WorkMessage{
+		RequestedByEmail:     requesterEmail,
+		ProvidedByEmail:      providerEmail,
+		Hash:                 "123",
+		Result:               "ac",
+		DifficultyMultiplier: 4,
+		BlockAward:           true,
+	})
+	utils.AssertEqual(t, nil, err)
+	workResult, err := workRepo.SaveOrUpdateWorkResult(repository.WorkMessage{
+		RequestedByEmail:     requesterEmail,
+		ProvidedByEmail:      providerEmail,
+		Hash:                 "456",
+		Result:               "ac",
+		DifficultyMultiplier: 4,
+		BlockAward:           true,
+		Precache:             true,
+		CreditPercent:        50,
+	})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 50, workResult.CreditPercent)
+
+	// 4 x 100 at full credit and 4 x 50 at half
+	sum, err := workRepo.GetUnpaidWorkSumForUser(providerEmail)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 600, sum)
+}
+
+// Test payouts with and without award rates
+// Work saved while a payout is marking work paid is left for the next payout
+func TestMarkAllPaidLeavesLaterWork(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	workRepo := repository.NewWorkService(mockDb, userRepo)
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+
+	providerEmail := "provider@gmail.com"
+	requesterEmail := "requester@gmail.com"
+	_, err = workRepo.SaveOrUpdateWorkResult(repository.WorkMessage{
+		RequestedByEmail:     requesterEmail,
+		ProvidedByEmail:      providerEmail,
+		Hash:                 "123",
+		Result:               "ac",
+		DifficultyMultiplier: 4,
+		BlockAward:           true,
+	})
+	utils.AssertEqual(t, nil, err)
+
+	// Another server saves work right after the payout's first statement, on its own connection
+	var inserted bool
+	insertLater := func(tx *gorm.DB) {
+		if inserted {
+			return
+		}
+		inserted = true
+		_, err := workRepo.SaveOrUpdateWorkResult(repository.WorkMessage{
+			RequestedByEmail:     requesterEmail,
+			ProvidedByEmail:      providerEmail,
+			Hash:                 "456",
+			Result:               "ac",
+			DifficultyMultiplier: 2,
+			BlockAward:           true,
+		})
+		utils.AssertEqual(t, nil, err)
+	}
+	utils.AssertEqual(t, nil, mockDb.Callback().Query().After("gorm:query").Register("tests:insert_later", insertLater))
+	utils.AssertEqual(t, nil, mockDb.Callback().Row().After("gorm:row").Register("tests:insert_later", insertLater))
+	var paid []repository.UnpaidWorkResult
+	err = mockDb.Transaction(func(tx *gorm.DB) error {
+		var err error
+		paid, err = workRepo.GetUnpaidWorkCountAndMarkAllPaid(tx, "default")
+		return err
+	})
+	mockDb.Callback().Query().Remove("tests:insert_later")
+	mockDb.Callback().Row().Remove("tests:insert_later")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, inserted)
+	utils.AssertEqual(t, 1, len(paid))
+	utils.AssertEqual(t, 400, paid[0].DifficultySum)
+
+	unpaid, err := workRepo.GetUnpaidWorkCount(mockDb, "default")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(unpaid))
+	utils.AssertEqual(t, 200, unpaid[0].DifficultySum)
+}
+
+func TestPayoutAmounts(t *testing.T) {
+	results := []repository.UnpaidWorkResult{
+		// Only rated work
+		{RatedAwardRaw: number.BananoIntToRaw(5).String()},
+		// Only unrated work
+		{UnratedDifficultySum: 300},
+		// Both
+		{RatedAwardRaw: number.BananoIntToRaw(1).String(), UnratedDifficultySum: 100},
+	}
+	amounts, err := repository.PayoutAmounts(results, 1000)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, []*big.Int{number.BananoIntToRaw(5), number.BananoIntToRaw(750), number.BananoIntToRaw(251)}, amounts)
+
+	// Without unrated work nothing comes out of the prize pool
+	amounts, _ = repository.PayoutAmounts([]repository.UnpaidWorkResult{{RatedAwardRaw: number.BananoIntToRaw(2).String()}}, 1000)
+	utils.AssertEqual(t, []*big.Int{number.BananoIntToRaw(2)}, amounts)
+
+	// Thirds of the prize pool don't divide evenly, the leftover raw is handed out so nothing drifts
+	amounts, _ = repository.PayoutAmounts([]repository.UnpaidWorkResult{{UnratedDifficultySum: 100}, {UnratedDifficultySum: 100}, {UnratedDifficultySum: 100}}, 1000)
+	total := new(big.Int)
+	for _, amount := range amounts {
+		total.Add(total, amount)
+	}
+	utils.AssertEqual(t, number.BananoIntToRaw(1000), total)
+	utils.AssertEqual(t, "33333333333333333333333333333334", amounts[0].String())
+	utils.AssertEqual(t, "33333333333333333333333333333333", amounts[2].String())
+
+	// A rated award that isn't raw fails instead of paying nothing for it
+	_, err = repository.PayoutAmounts([]repository.UnpaidWorkResult{{RatedAwardRaw: "1.5e30"}}, 1000)
+	utils.AssertEqual(t, true, err != nil)
+}
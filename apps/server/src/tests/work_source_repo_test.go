@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/config"
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestWorkSourceRepo(t *testing.T) {
+	os.Setenv("MOCK_REDIS", "true")
+	mockDb, err := database.NewConnection(&database.Config{
+		Host:     os.Getenv("DB_MOCK_HOST"),
+		Port:     os.Getenv("DB_MOCK_PORT"),
+		Password: os.Getenv("DB_MOCK_PASS"),
+		User:     os.Getenv("DB_MOCK_USER"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+		DBName:   "testing",
+	})
+	utils.AssertEqual(t, nil, err)
+	err = database.DropAndCreateTables(mockDb)
+	utils.AssertEqual(t, nil, err)
+	userRepo := repository.NewUserService(mockDb)
+	sourceRepo := repository.NewWorkSourceService(mockDb)
+	err = userRepo.CreateMockUsers()
+	utils.AssertEqual(t, nil, err)
+	email := "requester@gmail.com"
+	user, _ := userRepo.GetUser(nil, &email)
+
+	quota := 100
+	prod, err := sourceRepo.CreateWorkSource(user.ID, "prod", nil)
+	utils.AssertEqual(t, nil, err)
+	staging, err := sourceRepo.CreateWorkSource(user.ID, "staging", &quota)
+	utils.AssertEqual(t, nil, err)
+	_, err = sourceRepo.CreateWorkSource(user.ID, "prod", nil)
+	utils.AssertEqual(t, repository.ErrWorkSourceExists, err)
+
+	sources, err := sourceRepo.GetWorkSources(user.ID)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 2, len(sources))
+	utils.AssertEqual(t, "prod", sources[0].Name)
+
+	// Other requesters can use the same names
+	otherEmail := "provider@gmail.com"
+	other, _ := userRepo.GetUser(nil, &otherEmail)
+	_, err = sourceRepo.CreateWorkSource(other.ID, "prod", nil)
+	utils.AssertEqual(t, nil, err)
+	missing, err := sourceRepo.GetWorkSource(other.ID, "staging")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, missing == nil)
+
+	updated, err := sourceRepo.SetWorkSourceQuota(user.ID, "staging", nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, updated.DailyQuota == nil)
+	updated, err = sourceRepo.SetWorkSourceQuota(user.ID, "faucet", &quota)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, updated == nil)
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		utils.AssertEqual(t, nil, sourceRepo.RecordSourceUsage(prod, false, now))
+	}
+	utils.AssertEqual(t, nil, sourceRepo.RecordSourceUsage(prod, true, now))
+	utils.AssertEqual(t, nil, sourceRepo.RecordSourceUsage(staging, true, now))
+	// Usage of the day before
+	utils.AssertEqual(t, nil, sourceRepo.RecordSourceUsage(staging, false, now.Add(-24*time.Hour)))
+
+	usage, err := sourceRepo.GetSourceUsage(user.ID, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, []repository.SourceUsage{{Source: "prod", Requests: 3, Cached: 1}, {Source: "staging", Requests: 0, Cached: 1}}, usage)
+	usage, err = sourceRepo.GetSourceUsage(user.ID, now.Add(-24*time.Hour))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, repository.SourceUsage{Source: "staging", Requests: 1, Cached: 1}, usage[1])
+
+	deleted, err := sourceRepo.DeleteWorkSource(user.ID, "prod")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, deleted)
+	deleted, err = sourceRepo.DeleteWorkSource(user.ID, "prod")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, deleted)
+	usage, err = sourceRepo.GetSourceUsage(user.ID, now)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(usage))
+
+	for i := 1; i < config.MAX_WORK_SOURCES_PER_USER; i++ {
+		_, err = sourceRepo.CreateWorkSource(other.ID, "source-"+string(rune('a'+i)), nil)
+		utils.AssertEqual(t, nil, err)
+	}
+	_, err = sourceRepo.CreateWorkSource(other.ID, "one-more", nil)
+	utils.AssertEqual(t, repository.ErrTooManyWorkSources, err)
+}
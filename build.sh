@@ -6,8 +6,18 @@ cd ./apps/client
 go get
 
 BINARY="boompow-client"
+# Binaries for other architectures (arm64, riscv64) are named after them
+ARCH=`go env GOARCH`
+if [ "${ARCH}" != "amd64" ]; then
+  BINARY="${BINARY}-${ARCH}"
+fi
+# The race detector is only available on some architectures
+RACE=""
+if [ "${ARCH}" == "amd64" ] || [ "${ARCH}" == "arm64" ]; then
+  RACE="-race"
+fi
 rm -f ./target/${BINARY}
 
 mkdir -p ./target
-go build -o ./target/${BINARY} -tags cl -race -ldflags "-w -s -X main.WSUrl=wss://boompow.banano.cc/ws/worker -X main.GraphQLURL=https://boompow.banano.cc/graphql -X main.Version=`git tag --sort=-version:refname | head -n 1`" .
+go build -o ./target/${BINARY} -tags cl ${RACE} -ldflags "-w -s -X main.WSUrl=wss://boompow.banano.cc/ws/worker -X main.GraphQLURL=https://boompow.banano.cc/graphql -X main.Version=`git tag --sort=-version:refname | head -n 1`" .
 echo "Output ./apps/client/target/${BINARY}"
\ No newline at end of file
@@ -0,0 +1,5 @@
+#!/bin/bash
+
+echo "Build linux arm64 binary in docker (Raspberry Pi, Ampere)..."
+
+docker run --platform linux/arm64 -it --rm -v $(pwd)/apps/client/target:/src/apps/client/target $(docker build -q --platform linux/arm64 -f ./apps/client/Dockerfile.build .)
@@ -0,0 +1,116 @@
+// Package banano is a typed client for the RPCs BoomPow makes to a Banano node
+package banano
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultTimeout = 30 * time.Second
+	DefaultRetries = 3
+	DefaultBackoff = 500 * time.Millisecond
+)
+
+// Client makes RPCs to a node
+// Requests the node couldn't be asked, or that it failed with a server error, are retried, errors the node answers with are not
+type Client struct {
+	URL        string
+	HTTPClient *http.Client
+	// Attempts after the first one
+	Retries int
+	// Wait before the first retry, doubled for every retry after it
+	Backoff time.Duration
+}
+
+func NewClient(url string) *Client {
+	return &Client{
+		URL:        url,
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+		Retries:    DefaultRetries,
+		Backoff:    DefaultBackoff,
+	}
+}
+
+// The node answered the RPC with an error
+type RPCError struct {
+	Action  string
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Action, e.Message)
+}
+
+// The node couldn't handle the request, asking again may work
+type statusError struct {
+	StatusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("node responded with %d", e.StatusCode)
+}
+
+type rpcErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func (c *Client) call(ctx context.Context, action string, request interface{}, out interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	backoff := c.Backoff
+	for attempt := 0; ; attempt++ {
+		err = c.post(ctx, action, body, out)
+		var rpcErr *RPCError
+		if err == nil || errors.As(err, &rpcErr) || attempt >= c.Retries || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) post(ctx context.Context, action string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return &statusError{StatusCode: resp.StatusCode}
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	// Errors come back as {"error": ...}, sometimes with a 4xx status
+	var rpcErr rpcErrorResponse
+	if err := json.Unmarshal(raw, &rpcErr); err == nil && rpcErr.Error != "" {
+		return &RPCError{Action: action, Message: rpcErr.Error}
+	}
+	if resp.StatusCode >= 300 {
+		return &RPCError{Action: action, Message: fmt.Sprintf("node responded with %d", resp.StatusCode)}
+	}
+	return json.Unmarshal(raw, out)
+}
@@ -0,0 +1,139 @@
+package banano
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+// A node that answers every request with the next response, and the last one once they're used up
+type mockNode struct {
+	mu        sync.Mutex
+	responses []mockResponse
+	received  []map[string]string
+}
+
+type mockResponse struct {
+	status int
+	body   string
+}
+
+func newMockNode(t *testing.T, responses ...mockResponse) (*mockNode, *Client) {
+	node := &mockNode{responses: responses}
+	server := httptest.NewServer(node)
+	t.Cleanup(server.Close)
+	client := NewClient(server.URL)
+	client.HTTPClient = server.Client()
+	client.Backoff = time.Millisecond
+	return node, client
+}
+
+func (n *mockNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var request map[string]string
+	json.NewDecoder(r.Body).Decode(&request)
+	n.received = append(n.received, request)
+	response := n.responses[0]
+	if len(n.responses) > 1 {
+		n.responses = n.responses[1:]
+	}
+	w.WriteHeader(response.status)
+	fmt.Fprint(w, response.body)
+}
+
+func TestSend(t *testing.T) {
+	node, client := newMockNode(t, mockResponse{200, `{"block": "000D1BAEC8EC208142C99059B393051BAC8380F9B5A2E6B2489A277D81789F3F"}`})
+	block, err := client.Send(context.Background(), SendRequest{Wallet: "W", Source: "ban_1source", Destination: "ban_1destination", AmountRaw: "1000", ID: "payment-1"})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "000D1BAEC8EC208142C99059B393051BAC8380F9B5A2E6B2489A277D81789F3F", block)
+	utils.AssertEqual(t, map[string]string{"action": "send", "wallet": "W", "source": "ban_1source", "destination": "ban_1destination", "amount": "1000", "id": "payment-1"}, node.received[0])
+
+	// Not retried, the node would answer the same
+	node, client = newMockNode(t, mockResponse{200, `{"error": "Insufficient balance"}`})
+	_, err = client.Send(context.Background(), SendRequest{ID: "payment-2"})
+	var rpcErr *RPCError
+	utils.AssertEqual(t, true, errors.As(err, &rpcErr))
+	utils.AssertEqual(t, "send: Insufficient balance", err.Error())
+	utils.AssertEqual(t, 1, len(node.received))
+
+	_, client = newMockNode(t, mockResponse{200, `{}`})
+	_, err = client.Send(context.Background(), SendRequest{ID: "payment-3"})
+	utils.AssertEqual(t, "send response has no block", err.Error())
+}
+
+func TestRetries(t *testing.T) {
+	node, client := newMockNode(t, mockResponse{502, "bad gateway"}, mockResponse{503, ""}, mockResponse{200, `{"balance": "100", "receivable": "5"}`})
+	balance, err := client.AccountBalance(context.Background(), "ban_1account")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, &AccountBalance{Balance: "100", Receivable: "5"}, balance)
+	utils.AssertEqual(t, 3, len(node.received))
+
+	// Gives up after the last retry
+	node, client = newMockNode(t, mockResponse{500, ""})
+	_, err = client.AccountBalance(context.Background(), "ban_1account")
+	utils.AssertEqual(t, "node responded with 500", err.Error())
+	utils.AssertEqual(t, 1+DefaultRetries, len(node.received))
+
+	// Or once the context is done
+	node, client = newMockNode(t, mockResponse{500, ""})
+	client.Backoff = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.AccountBalance(ctx, "ban_1account")
+	utils.AssertNotEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(node.received))
+
+	// The node can't be reached
+	client = NewClient("http://127.0.0.1:1")
+	client.Backoff = time.Millisecond
+	_, err = client.AccountBalance(context.Background(), "ban_1account")
+	utils.AssertNotEqual(t, nil, err)
+}
+
+func TestBlockInfo(t *testing.T) {
+	node, client := newMockNode(t, mockResponse{200, `{
+		"block_account": "ban_1sender",
+		"amount": "1000",
+		"balance": "5000",
+		"height": "58",
+		"local_timestamp": "0",
+		"confirmed": "true",
+		"contents": {"type": "state", "account": "ban_1sender", "link_as_account": "ban_1receiver", "work": "8a142e07a10996d5"},
+		"subtype": "send"
+	}`})
+	info, err := client.BlockInfo(context.Background(), "HASH")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, map[string]string{"action": "block_info", "hash": "HASH", "json_block": "true"}, node.received[0])
+	utils.AssertEqual(t, true, info.IsConfirmed())
+	utils.AssertEqual(t, "send", info.Subtype)
+	utils.AssertEqual(t, "1000", info.Amount)
+	utils.AssertEqual(t, "ban_1receiver", info.Contents.LinkAsAccount)
+
+	_, client = newMockNode(t, mockResponse{200, `{"error": "Block not found"}`})
+	_, err = client.BlockInfo(context.Background(), "HASH")
+	utils.AssertEqual(t, "block_info: Block not found", err.Error())
+}
+
+func TestWorkValidate(t *testing.T) {
+	node, client := newMockNode(t, mockResponse{200, `{"valid": "1", "valid_all": "1", "valid_receive": "1"}`}, mockResponse{200, `{"valid": "0"}`}, mockResponse{200, `{"valid_all": "1"}`})
+	valid, err := client.WorkValidate(context.Background(), "AB", "CD", 0xfffffe0000000000)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, valid)
+	utils.AssertEqual(t, "fffffe0000000000", node.received[0]["difficulty"])
+
+	valid, err = client.WorkValidate(context.Background(), "AB", "CD", 0xfffffe0000000000)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, false, valid)
+
+	_, err = client.WorkValidate(context.Background(), "AB", "CD", 0xfffffe0000000000)
+	utils.AssertEqual(t, "work_validate response has no valid field", err.Error())
+}
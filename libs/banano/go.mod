@@ -0,0 +1,5 @@
+module github.com/bananocoin/boompow/libs/banano
+
+go 1.19
+
+require github.com/bananocoin/boompow/libs/utils v0.0.0-20220810021633-b4ba8d652a46
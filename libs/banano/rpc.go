@@ -0,0 +1,147 @@
+package banano
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// A send from a wallet account, amounts are in raw
+type SendRequest struct {
+	Wallet      string
+	Source      string
+	Destination string
+	AmountRaw   string
+	// The node sends only once per id, so the send can be retried safely, at most 64 characters
+	ID string
+}
+
+type sendRequest struct {
+	Action      string `json:"action"`
+	Wallet      string `json:"wallet"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	AmountRaw   string `json:"amount"`
+	ID          string `json:"id,omitempty"`
+}
+
+type sendResponse struct {
+	Block string `json:"block"`
+}
+
+// Send returns the hash of the send block
+func (c *Client) Send(ctx context.Context, request SendRequest) (string, error) {
+	var resp sendResponse
+	err := c.call(ctx, "send", sendRequest{
+		Action:      "send",
+		Wallet:      request.Wallet,
+		Source:      request.Source,
+		Destination: request.Destination,
+		AmountRaw:   request.AmountRaw,
+		ID:          request.ID,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.Block == "" {
+		return "", errors.New("send response has no block")
+	}
+	return resp.Block, nil
+}
+
+// In raw
+type AccountBalance struct {
+	Balance    string `json:"balance"`
+	Receivable string `json:"receivable"`
+}
+
+type accountRequest struct {
+	Action  string `json:"action"`
+	Account string `json:"account"`
+}
+
+func (c *Client) AccountBalance(ctx context.Context, account string) (*AccountBalance, error) {
+	var resp AccountBalance
+	if err := c.call(ctx, "account_balance", accountRequest{Action: "account_balance", Account: account}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Balance == "" {
+		return nil, errors.New("account_balance response has no balance")
+	}
+	return &resp, nil
+}
+
+// The state block, amounts are in raw
+type BlockContents struct {
+	Type           string `json:"type"`
+	Account        string `json:"account"`
+	Previous       string `json:"previous"`
+	Representative string `json:"representative"`
+	Balance        string `json:"balance"`
+	Link           string `json:"link"`
+	LinkAsAccount  string `json:"link_as_account"`
+	Signature      string `json:"signature"`
+	Work           string `json:"work"`
+}
+
+type BlockInfo struct {
+	BlockAccount   string        `json:"block_account"`
+	Amount         string        `json:"amount"`
+	Balance        string        `json:"balance"`
+	Height         string        `json:"height"`
+	LocalTimestamp string        `json:"local_timestamp"`
+	Confirmed      string        `json:"confirmed"`
+	Subtype        string        `json:"subtype"`
+	Contents       BlockContents `json:"contents"`
+}
+
+func (b *BlockInfo) IsConfirmed() bool {
+	return b.Confirmed == "true"
+}
+
+type blockInfoRequest struct {
+	Action    string `json:"action"`
+	Hash      string `json:"hash"`
+	JSONBlock string `json:"json_block"`
+}
+
+func (c *Client) BlockInfo(ctx context.Context, hash string) (*BlockInfo, error) {
+	var resp BlockInfo
+	if err := c.call(ctx, "block_info", blockInfoRequest{Action: "block_info", Hash: hash, JSONBlock: "true"}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+type workValidateRequest struct {
+	Action     string `json:"action"`
+	Hash       string `json:"hash"`
+	Work       string `json:"work"`
+	Difficulty string `json:"difficulty"`
+}
+
+type workValidateResponse struct {
+	Valid string `json:"valid"`
+}
+
+// WorkValidate checks the work against the difficulty threshold, not the node's defaults
+func (c *Client) WorkValidate(ctx context.Context, hash string, work string, difficulty uint64) (bool, error) {
+	var resp workValidateResponse
+	err := c.call(ctx, "work_validate", workValidateRequest{
+		Action:     "work_validate",
+		Hash:       hash,
+		Work:       work,
+		Difficulty: fmt.Sprintf("%016x", difficulty),
+	}, &resp)
+	if err != nil {
+		return false, err
+	}
+	// Only set when the difficulty is given explicitly
+	switch resp.Valid {
+	case "1":
+		return true, nil
+	case "0":
+		return false, nil
+	}
+	return false, errors.New("work_validate response has no valid field")
+}
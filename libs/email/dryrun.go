@@ -0,0 +1,56 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Doesn't send anything, for development
+// Emails are logged to Out, and their HTML is written to Dir if it's set so it can be opened in a browser
+type DryRunSender struct {
+	Out io.Writer
+	Dir string
+	mu  sync.Mutex
+	// Numbers the files written to Dir
+	sent int
+}
+
+func NewDryRunSender(out io.Writer, dir string) *DryRunSender {
+	return &DryRunSender{
+		Out: out,
+		Dir: dir,
+	}
+}
+
+var _ Sender = &DryRunSender{}
+
+var unsafeFilename = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+func (s *DryRunSender) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent++
+	out := s.Out
+	if out == nil {
+		out = os.Stderr
+	}
+	attachments := make([]string, len(msg.Attachments))
+	for i, a := range msg.Attachments {
+		attachments[i] = a.Filename
+	}
+	fmt.Fprintf(out, "[email dry run] to=%s subject=%q bytes=%d attachments=%v\n", msg.To, msg.Subject, len(msg.HTML), attachments)
+	if s.Dir == "" {
+		return nil
+	}
+	name := fmt.Sprintf("%s-%03d-%s.html", time.Now().UTC().Format("20060102T150405"), s.sent, unsafeFilename.ReplaceAllString(msg.To, "_"))
+	return os.WriteFile(filepath.Join(s.Dir, name), msg.HTML, 0o644)
+}
@@ -0,0 +1,107 @@
+// Package email sends HTML emails through SMTP, SendGrid or Mailgun, or only logs them in dry-run mode
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// A file sent along with an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+type Message struct {
+	From    mail.Address
+	To      string
+	Subject string
+	// The whole body, emails are only sent as HTML
+	HTML        []byte
+	Attachments []Attachment
+}
+
+// Sender is implemented by every provider
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+const (
+	ProviderSMTP     = "smtp"
+	ProviderSendGrid = "sendgrid"
+	ProviderMailgun  = "mailgun"
+	ProviderDryRun   = "dryrun"
+)
+
+var Providers = []string{ProviderSMTP, ProviderSendGrid, ProviderMailgun, ProviderDryRun}
+
+// The provider refused or failed the email
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s answered %d: %s", e.Provider, e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+func (m Message) validate() error {
+	if m.From.Address == "" || m.To == "" {
+		return errors.New("email needs a sender and a recipient")
+	}
+	if strings.ContainsAny(m.To+m.Subject, "\r\n") {
+		return errors.New("email recipient and subject can't contain line breaks")
+	}
+	return nil
+}
+
+// What NewSender needs, only the fields of the chosen provider have to be set
+type Config struct {
+	Provider string
+	// SMTP
+	SMTPServer   string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	// SendGrid
+	SendGridAPIKey string
+	// Mailgun, MailgunBaseURL defaults to the US region
+	MailgunDomain  string
+	MailgunAPIKey  string
+	MailgunBaseURL string
+	// Dry run, HTML is only written if DryRunDir is set
+	DryRunDir string
+}
+
+// The sender of the configured provider, errors if its credentials are missing
+func NewSender(cfg Config) (Sender, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case ProviderSMTP, "":
+		if cfg.SMTPServer == "" || cfg.SMTPPort == 0 || cfg.SMTPUsername == "" || cfg.SMTPPassword == "" {
+			return nil, errors.New("SMTP server, port, username and password are required")
+		}
+		return NewSMTPSender(cfg.SMTPServer, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword), nil
+	case ProviderSendGrid:
+		if cfg.SendGridAPIKey == "" {
+			return nil, errors.New("SendGrid API key is required")
+		}
+		return NewSendGridSender(cfg.SendGridAPIKey), nil
+	case ProviderMailgun:
+		if cfg.MailgunDomain == "" || cfg.MailgunAPIKey == "" {
+			return nil, errors.New("Mailgun domain and API key are required")
+		}
+		sender := NewMailgunSender(cfg.MailgunDomain, cfg.MailgunAPIKey)
+		if cfg.MailgunBaseURL != "" {
+			sender.BaseURL = strings.TrimSuffix(cfg.MailgunBaseURL, "/")
+		}
+		return sender, nil
+	case ProviderDryRun:
+		return NewDryRunSender(nil, cfg.DryRunDir), nil
+	}
+	return nil, fmt.Errorf("unknown email provider %q, must be one of %s", cfg.Provider, strings.Join(Providers, ", "))
+}
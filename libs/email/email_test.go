@@ -0,0 +1,196 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testMessage() Message {
+	return Message{
+		From:        mail.Address{Name: "BoomPoW (Banano)", Address: "noreply@mail.banano.cc"},
+		To:          "provider@example.com",
+		Subject:     "Payout statement",
+		HTML:        []byte("<p>Hello</p>"),
+		Attachments: []Attachment{{Filename: "statement.csv", ContentType: "text/csv", Content: []byte("a,b\n")}},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	msg := testMessage()
+	if err := msg.validate(); err != nil {
+		t.Fatalf("expected valid message, got %v", err)
+	}
+	msg.Subject = "Hi\r\nBcc: someone@example.com"
+	if err := msg.validate(); err == nil {
+		t.Fatal("expected line breaks in the subject to be rejected")
+	}
+	msg = testMessage()
+	msg.To = ""
+	if err := msg.validate(); err == nil {
+		t.Fatal("expected a missing recipient to be rejected")
+	}
+}
+
+func TestBuildMIME(t *testing.T) {
+	msg := testMessage()
+	msg.Attachments = nil
+	expected := "Content-Transfer-Encoding: base64\r\n" +
+		"Content-Type: text/html; charset=\"utf-8\"\r\n" +
+		"From: \"BoomPoW (Banano)\" <noreply@mail.banano.cc>\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Subject: Payout statement\r\n" +
+		"To: <provider@example.com>\r\n" +
+		"\r\n" + base64.StdEncoding.EncodeToString(msg.HTML)
+	if got := string(buildMIME(msg, "b")); got != expected {
+		t.Fatalf("unexpected message\n%q\n%q", got, expected)
+	}
+
+	withAttachment := string(buildMIME(testMessage(), "b"))
+	for _, part := range []string{
+		"Content-Type: multipart/mixed; boundary=\"b\"\r\n",
+		"Content-Disposition: attachment; filename=\"statement.csv\"\r\n",
+		base64.StdEncoding.EncodeToString([]byte("a,b\n")),
+	} {
+		if !strings.Contains(withAttachment, part) {
+			t.Fatalf("expected %q in\n%s", part, withAttachment)
+		}
+	}
+	if !strings.HasSuffix(withAttachment, "--b--\r\n") {
+		t.Fatal("expected the closing boundary")
+	}
+}
+
+func TestSendGridSender(t *testing.T) {
+	var received sendGridRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/mail/send" || r.Header.Get("Authorization") != "Bearer key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender := NewSendGridSender("key")
+	sender.BaseURL = server.URL
+	if err := sender.Send(context.Background(), testMessage()); err != nil {
+		t.Fatal(err)
+	}
+	if received.Personalizations[0].To[0].Email != "provider@example.com" || received.From.Name != "BoomPoW (Banano)" || received.Content[0].Value != "<p>Hello</p>" {
+		t.Fatalf("unexpected request %+v", received)
+	}
+	if received.Attachments[0].Content != base64.StdEncoding.EncodeToString([]byte("a,b\n")) {
+		t.Fatalf("unexpected attachment %+v", received.Attachments)
+	}
+
+	sender.APIKey = "wrong"
+	var providerErr *ProviderError
+	if err := sender.Send(context.Background(), testMessage()); !errors.As(err, &providerErr) || providerErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a provider error, got %v", err)
+	}
+}
+
+func TestMailgunSender(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, key, ok := r.BasicAuth()
+		if r.URL.Path != "/v3/mg.example.com/messages" || !ok || user != "api" || key != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.FormValue("to") != "provider@example.com" || r.FormValue("html") != "<p>Hello</p>" || r.FormValue("from") != `"BoomPoW (Banano)" <noreply@mail.banano.cc>` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		file, header, err := r.FormFile("attachment")
+		if err != nil || header.Filename != "statement.csv" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		if string(content) != "a,b\n" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := NewMailgunSender("mg.example.com", "key")
+	sender.BaseURL = server.URL
+	if err := sender.Send(context.Background(), testMessage()); err != nil {
+		t.Fatal(err)
+	}
+	sender.Domain = "other.example.com"
+	if err := sender.Send(context.Background(), testMessage()); err == nil {
+		t.Fatal("expected an error for a domain the key isn't for")
+	}
+}
+
+func TestDryRunSender(t *testing.T) {
+	var out bytes.Buffer
+	dir := t.TempDir()
+	sender := NewDryRunSender(&out, dir)
+	if err := sender.Send(context.Background(), testMessage()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "to=provider@example.com") || !strings.Contains(out.String(), "statement.csv") {
+		t.Fatalf("unexpected log %q", out.String())
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*provider_example.com.html"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one HTML file, got %v %v", files, err)
+	}
+	content, _ := os.ReadFile(files[0])
+	if string(content) != "<p>Hello</p>" {
+		t.Fatalf("unexpected HTML %q", content)
+	}
+}
+
+func TestNewSender(t *testing.T) {
+	if _, err := NewSender(Config{Provider: ProviderSendGrid}); err == nil {
+		t.Fatal("expected an error without an API key")
+	}
+	if _, err := NewSender(Config{}); err == nil {
+		t.Fatal("expected an error without SMTP credentials")
+	}
+	if _, err := NewSender(Config{Provider: "pigeon"}); err == nil {
+		t.Fatal("expected an error for an unknown provider")
+	}
+	sender, err := NewSender(Config{Provider: "Mailgun", MailgunDomain: "mg.example.com", MailgunAPIKey: "key", MailgunBaseURL: MailgunEUURL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sender.(*MailgunSender).BaseURL != MailgunEUURL {
+		t.Fatalf("unexpected base URL %s", sender.(*MailgunSender).BaseURL)
+	}
+	if _, ok := mustSender(t, Config{Provider: ProviderDryRun}).(*DryRunSender); !ok {
+		t.Fatal("expected a dry run sender")
+	}
+}
+
+func mustSender(t *testing.T, cfg Config) Sender {
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sender
+}
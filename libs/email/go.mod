@@ -0,0 +1,3 @@
+module github.com/bananocoin/boompow/libs/email
+
+go 1.19
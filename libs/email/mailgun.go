@@ -0,0 +1,71 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+)
+
+const (
+	DefaultMailgunURL = "https://api.mailgun.net"
+	// Domains in the EU region have to use this
+	MailgunEUURL = "https://api.eu.mailgun.net"
+)
+
+// Sends through the Mailgun messages API of a sending domain
+type MailgunSender struct {
+	Domain     string
+	APIKey     string
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewMailgunSender(domain string, apiKey string) *MailgunSender {
+	return &MailgunSender{
+		Domain:     domain,
+		APIKey:     apiKey,
+		BaseURL:    DefaultMailgunURL,
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+var _ Sender = &MailgunSender{}
+
+func (s *MailgunSender) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	fields := [][2]string{{"from", msg.From.String()}, {"to", msg.To}, {"subject", msg.Subject}, {"html", string(msg.HTML)}}
+	for _, field := range fields {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return err
+		}
+	}
+	for _, a := range msg.Attachments {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="attachment"; filename=%q`, a.Filename))
+		header.Set("Content-Type", a.ContentType)
+		part, err := form.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(a.Content); err != nil {
+			return err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v3/%s/messages", s.BaseURL, s.Domain), &body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("api", s.APIKey)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return doProviderRequest(s.HTTPClient, req, ProviderMailgun)
+}
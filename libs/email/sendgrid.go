@@ -0,0 +1,105 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultSendGridURL = "https://api.sendgrid.com"
+	DefaultTimeout     = 30 * time.Second
+)
+
+// Sends through the SendGrid v3 mail API
+type SendGridSender struct {
+	APIKey     string
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewSendGridSender(apiKey string) *SendGridSender {
+	return &SendGridSender{
+		APIKey:     apiKey,
+		BaseURL:    DefaultSendGridURL,
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+var _ Sender = &SendGridSender{}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content  string `json:"content"`
+	Type     string `json:"type"`
+	Filename string `json:"filename"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+func (s *SendGridSender) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	request := sendGridRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: msg.To}}}},
+		From:             sendGridAddress{Email: msg.From.Address, Name: msg.From.Name},
+		Subject:          msg.Subject,
+		Content:          []sendGridContent{{Type: "text/html", Value: string(msg.HTML)}},
+	}
+	for _, a := range msg.Attachments {
+		request.Attachments = append(request.Attachments, sendGridAttachment{
+			Content:  base64.StdEncoding.EncodeToString(a.Content),
+			Type:     a.ContentType,
+			Filename: a.Filename,
+		})
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.BaseURL+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	return doProviderRequest(s.HTTPClient, req, ProviderSendGrid)
+}
+
+// Any 2xx is accepted, SendGrid answers 202
+func doProviderRequest(client *http.Client, req *http.Request, provider string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &ProviderError{Provider: provider, StatusCode: resp.StatusCode, Body: string(b)}
+	}
+	return nil
+}
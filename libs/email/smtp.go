@@ -0,0 +1,79 @@
+package email
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type SMTPSender struct {
+	Server   string
+	Port     int
+	Username string
+	Password string
+}
+
+func NewSMTPSender(server string, port int, username string, password string) *SMTPSender {
+	return &SMTPSender{
+		Server:   server,
+		Port:     port,
+		Username: username,
+		Password: password,
+	}
+}
+
+var _ Sender = &SMTPSender{}
+
+// The context isn't used, net/smtp can't be cancelled
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	auth := smtp.PlainAuth("", s.Username, s.Password, s.Server)
+	message := buildMIME(msg, fmt.Sprintf("boompow-%d", time.Now().UnixNano()))
+	return smtp.SendMail(fmt.Sprintf("%s:%d", s.Server, s.Port), auth, msg.From.Address, []string{msg.To}, message)
+}
+
+// The message as sent over SMTP, boundary separates the body from the attachments if there are any
+func buildMIME(msg Message, boundary string) []byte {
+	to := mail.Address{Address: msg.To}
+	header := map[string]string{
+		"From": msg.From.String(),
+		"To":   to.String(),
+		// Translated subjects aren't always ASCII
+		"Subject":      mime.QEncoding.Encode("utf-8", msg.Subject),
+		"MIME-Version": "1.0",
+	}
+	if len(msg.Attachments) == 0 {
+		header["Content-Type"] = "text/html; charset=\"utf-8\""
+		header["Content-Transfer-Encoding"] = "base64"
+	} else {
+		header["Content-Type"] = fmt.Sprintf("multipart/mixed; boundary=\"%s\"", boundary)
+	}
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s: %s\r\n", k, header[k])
+	}
+	if len(msg.Attachments) == 0 {
+		b.WriteString("\r\n" + base64.StdEncoding.EncodeToString(msg.HTML))
+		return []byte(b.String())
+	}
+	fmt.Fprintf(&b, "\r\n--%s\r\nContent-Type: text/html; charset=\"utf-8\"\r\nContent-Transfer-Encoding: base64\r\n\r\n%s\r\n", boundary, base64.StdEncoding.EncodeToString(msg.HTML))
+	for _, a := range msg.Attachments {
+		fmt.Fprintf(&b, "--%s\r\nContent-Type: %s\r\nContent-Disposition: attachment; filename=\"%s\"\r\nContent-Transfer-Encoding: base64\r\n\r\n%s\r\n", boundary, a.ContentType, a.Filename, base64.StdEncoding.EncodeToString(a.Content))
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return []byte(b.String())
+}
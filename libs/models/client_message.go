@@ -1,18 +1,28 @@
 package models
 
+import "encoding/hex"
+
 type MessageType string
 
 const (
 	WorkGenerate MessageType = "work_generate"
 	WorkCancel   MessageType = "work_cancel"
 	BlockAwarded MessageType = "block_awarded"
+	// Asks clients to move to another of their configured servers
+	PreferServer MessageType = "prefer_server"
+	// Answers a worker's hello with the protocol version and features both sides speak
+	HelloAck MessageType = "hello_ack"
 )
 
+// No tenant allows difficulties anywhere near this, anything above is a protocol error
+const MaxSaneDifficultyMultiplier = 4096
+
 // Message sent from server -> client
 type ClientMessage struct {
 	// Exclude this field from serialization (don't expose requester email to client)
 	RequesterEmail string      `json:"-"`
 	BlockAward     bool        `json:"-"`
+	TenantID       string      `json:"-"`
 	MessageType    MessageType `json:"request_type"`
 	// We attach a unique request ID to each request, this links it to user requesting work
 	RequestID            string `json:"request_id"`
@@ -23,4 +33,28 @@ type ClientMessage struct {
 	PercentOfPool  float64 `json:"percent_of_pool"`
 	EstimatedAward float64 `json:"estimated_award"`
 	Precache       bool    `json:"precache"`
+	// Server to prefer, websocket or GraphQL URL
+	ServerURL string `json:"server_url,omitempty"`
+	// Set on messages that may be delivered more than once (block awarded), clients acknowledge it and ignore repeats
+	MessageID string `json:"message_id,omitempty"`
+	// Negotiated in hello acknowledgements
+	ProtocolVersion int      `json:"protocol_version,omitempty"`
+	Features        []string `json:"features,omitempty"`
+}
+
+// Checks a work request is well formed before computing it, returns the Reject* reason or "" if it is
+func (m ClientMessage) Malformed() string {
+	if m.RequestID == "" {
+		return RejectMissingRequestID
+	}
+	if len(m.Hash) != 64 {
+		return RejectInvalidHash
+	}
+	if _, err := hex.DecodeString(m.Hash); err != nil {
+		return RejectInvalidHash
+	}
+	if m.DifficultyMultiplier < 1 || m.DifficultyMultiplier > MaxSaneDifficultyMultiplier {
+		return RejectInvalidDifficulty
+	}
+	return ""
 }
@@ -35,3 +35,22 @@ func TestSerializeDeserializeClientRequest(t *testing.T) {
 	utils.AssertEqual(t, float64(3), deserialized["difficulty_multiplier"])
 	utils.AssertEqual(t, true, deserialized["precache"])
 }
+
+func TestMalformed(t *testing.T) {
+	msg := ClientMessage{MessageType: WorkGenerate, RequestID: "123", Hash: "3F93C5CD2E314FA16702189041E68E68C07B27961BF37F0B7705145BEFBA3AA3", DifficultyMultiplier: 1}
+	utils.AssertEqual(t, "", msg.Malformed())
+
+	bad := msg
+	bad.RequestID = ""
+	utils.AssertEqual(t, RejectMissingRequestID, bad.Malformed())
+	bad = msg
+	bad.Hash = "3F93"
+	utils.AssertEqual(t, RejectInvalidHash, bad.Malformed())
+	bad.Hash = strings.Repeat("Z", 64)
+	utils.AssertEqual(t, RejectInvalidHash, bad.Malformed())
+	bad = msg
+	bad.DifficultyMultiplier = 0
+	utils.AssertEqual(t, RejectInvalidDifficulty, bad.Malformed())
+	bad.DifficultyMultiplier = MaxSaneDifficultyMultiplier + 1
+	utils.AssertEqual(t, RejectInvalidDifficulty, bad.Malformed())
+}
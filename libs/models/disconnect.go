@@ -0,0 +1,101 @@
+package models
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Why the hub disconnected a worker, sent in the close frame so workers can log it and react
+// The close reason is the Code, followed by ": " and details if there are any
+type DisconnectReason struct {
+	Code string
+	// Reasons reconnecting can't fix get an application close code, clients before reasons stop on those too
+	// The others get standard codes those clients reconnect after
+	CloseCode int
+	// Whether reconnecting can help, workers stop on reasons it can't
+	Reconnect bool
+}
+
+// Standard close codes
+const (
+	closeGoingAway       = 1001
+	closePolicyViolation = 1008
+	closeTryAgainLater   = 1013
+)
+
+var (
+	// The hub doesn't serve the worker's protocol version anymore, details say which versions it serves
+	DisconnectProtocolRejected = DisconnectReason{Code: "protocol_rejected", CloseCode: ProtocolRejectedCloseCode}
+	// No provider could be authenticated with the worker's token
+	DisconnectAuthFailed = DisconnectReason{Code: "auth_failed", CloseCode: 4002}
+	// The provider was banned
+	DisconnectBanned = DisconnectReason{Code: "banned", CloseCode: 4003}
+	// The provider has to verify their email again
+	DisconnectEmailUnverified = DisconnectReason{Code: "email_unverified", CloseCode: 4004}
+	// Workers aren't served from the worker's network
+	DisconnectBlockedNetwork = DisconnectReason{Code: "blocked_network", CloseCode: 4005}
+	// The worker sent too many malformed frames, it's let back in once the quarantine is over
+	DisconnectQuarantined = DisconnectReason{Code: "quarantined", CloseCode: closePolicyViolation, Reconnect: true}
+	// Another worker is connected from the same IP
+	DisconnectAlreadyConnected = DisconnectReason{Code: "already_connected", CloseCode: closeTryAgainLater, Reconnect: true}
+	// The worker stopped answering pings
+	DisconnectIdleTimeout = DisconnectReason{Code: "idle_timeout", CloseCode: closeGoingAway, Reconnect: true}
+	// The worker didn't read its messages fast enough
+	DisconnectSlowConsumer = DisconnectReason{Code: "slow_consumer", CloseCode: closeTryAgainLater, Reconnect: true}
+	// The server is shutting down, e.g. for a deploy
+	DisconnectShutdown = DisconnectReason{Code: "server_shutdown", CloseCode: closeGoingAway, Reconnect: true}
+	// The server is shutting down during a maintenance window, details say until when
+	DisconnectMaintenance = DisconnectReason{Code: "maintenance", CloseCode: closeGoingAway, Reconnect: true}
+)
+
+var DisconnectReasons = []DisconnectReason{
+	DisconnectProtocolRejected,
+	DisconnectAuthFailed,
+	DisconnectBanned,
+	DisconnectEmailUnverified,
+	DisconnectBlockedNetwork,
+	DisconnectQuarantined,
+	DisconnectAlreadyConnected,
+	DisconnectIdleTimeout,
+	DisconnectSlowConsumer,
+	DisconnectShutdown,
+	DisconnectMaintenance,
+}
+
+// Close reasons can't be longer than this, the rest of a control frame is the close code
+const maxCloseReasonBytes = 123
+
+// The close reason with details, cut to fit in a close frame
+func (r DisconnectReason) Text(detail string) string {
+	text := r.Code
+	if detail != "" {
+		text += ": " + detail
+	}
+	if len(text) <= maxCloseReasonBytes {
+		return text
+	}
+	text = text[:maxCloseReasonBytes]
+	for !utf8.ValidString(text) {
+		text = text[:len(text)-1]
+	}
+	return text
+}
+
+// The reason and details of a close frame
+// Hubs before reasons only sent protocol rejections with bare details, other close frames without a known reason give false
+func ParseDisconnect(closeCode int, text string) (DisconnectReason, string, bool) {
+	code, detail, _ := strings.Cut(text, ": ")
+	for _, reason := range DisconnectReasons {
+		if reason.Code == code && reason.CloseCode == closeCode {
+			return reason, detail, true
+		}
+	}
+	if closeCode == ProtocolRejectedCloseCode {
+		return DisconnectProtocolRejected, text, true
+	}
+	// Unknown application codes can't be reconnected after either
+	if closeCode >= 4000 {
+		return DisconnectReason{Code: code, CloseCode: closeCode}, detail, true
+	}
+	return DisconnectReason{}, "", false
+}
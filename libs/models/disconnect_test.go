@@ -0,0 +1,42 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestDisconnectReasonText(t *testing.T) {
+	utils.AssertEqual(t, "banned", DisconnectBanned.Text(""))
+	utils.AssertEqual(t, "maintenance: until 12:00 UTC", DisconnectMaintenance.Text("until 12:00 UTC"))
+	// Cut to fit a close frame without splitting characters
+	long := DisconnectQuarantined.Text(strings.Repeat("é", 100))
+	utils.AssertEqual(t, true, len(long) <= 123 && len(long) >= 121)
+	utils.AssertEqual(t, true, strings.HasSuffix(long, "é"))
+}
+
+func TestParseDisconnect(t *testing.T) {
+	for _, reason := range DisconnectReasons {
+		parsed, detail, ok := ParseDisconnect(reason.CloseCode, reason.Text("details"))
+		utils.AssertEqual(t, true, ok)
+		utils.AssertEqual(t, reason, parsed)
+		utils.AssertEqual(t, "details", detail)
+	}
+
+	// Hubs before reasons
+	reason, detail, ok := ParseDisconnect(ProtocolRejectedCloseCode, "upgrade to version 2")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, DisconnectProtocolRejected, reason)
+	utils.AssertEqual(t, "upgrade to version 2", detail)
+	_, _, ok = ParseDisconnect(1001, "server shutting down")
+	utils.AssertEqual(t, false, ok)
+
+	// Reasons of newer hubs
+	reason, _, ok = ParseDisconnect(4999, "new_reason")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, false, reason.Reconnect)
+	utils.AssertEqual(t, "new_reason", reason.Code)
+	_, _, ok = ParseDisconnect(1001, "new_reason")
+	utils.AssertEqual(t, false, ok)
+}
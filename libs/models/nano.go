@@ -29,6 +29,19 @@ type SendResponse struct {
 	Block string `json:"block"`
 }
 
+// account_balance
+var AccountBalanceAction BaseRequest = BaseRequest{Action: "account_balance"}
+
+type AccountBalanceRequest struct {
+	BaseRequest
+	Account string `json:"account"`
+}
+
+type AccountBalanceResponse struct {
+	Balance    string `json:"balance"`
+	Receivable string `json:"receivable"`
+}
+
 // Type of nano payment object as JSONB
 func (j SendRequest) Value() (driver.Value, error) {
 	valueString, err := json.Marshal(j)
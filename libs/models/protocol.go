@@ -0,0 +1,50 @@
+package models
+
+// Version of the worker protocol, bumped when a change needs both the hub and the worker to understand it
+// Workers that don't say hello speak version 1
+const ProtocolVersion = 2
+
+// Features a worker and the hub agree on in the hello, neither side relies on one the other didn't advertise
+const (
+	// Acknowledges messages that carry a MessageID, so the hub can redeliver them
+	FeatureAck = "ack"
+	// Rejects malformed work requests instead of ignoring them
+	FeatureReject = "reject"
+	// Reports progress on long solves
+	FeatureProgress = "progress"
+	// Follows prefer_server messages
+	FeaturePreferServer = "prefer_server"
+	// Only wants work requests within the difficulty range of its hello, and no precache requests if it skips them
+	FeatureDifficultyRange = "difficulty_range"
+)
+
+// Every feature of this protocol version
+var ProtocolFeatures = []string{FeatureAck, FeatureReject, FeatureProgress, FeaturePreferServer, FeatureDifficultyRange}
+
+// The hub closes connections with this code when it doesn't serve the worker's protocol version anymore, the reason says which versions it serves
+const ProtocolRejectedCloseCode = 4001
+
+// The first frame a worker sends on every connection
+type WorkerHello struct {
+	ProtocolVersion int      `json:"protocol_version"`
+	Features        []string `json:"features"`
+	ClientVersion   string   `json:"client_version,omitempty"`
+	// Difficulty multipliers the worker takes, 0 doesn't bound it
+	MinDifficulty int  `json:"min_difficulty,omitempty"`
+	MaxDifficulty int  `json:"max_difficulty,omitempty"`
+	SkipPrecache  bool `json:"skip_precache,omitempty"`
+	// Names of the GPUs the worker solves on, empty if it only uses its CPU
+	GPUs       []string `json:"gpus,omitempty"`
+	CPUThreads int      `json:"cpu_threads,omitempty"`
+}
+
+// Whether the worker takes work requests of this difficulty
+func (h WorkerHello) Wants(difficultyMultiplier int, precache bool) bool {
+	if h.MinDifficulty > 0 && difficultyMultiplier < h.MinDifficulty {
+		return false
+	}
+	if h.MaxDifficulty > 0 && difficultyMultiplier > h.MaxDifficulty {
+		return false
+	}
+	return !(precache && h.SkipPrecache)
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestHelloWants(t *testing.T) {
+	utils.AssertEqual(t, true, WorkerHello{}.Wants(64, true))
+
+	hello := WorkerHello{MinDifficulty: 2, MaxDifficulty: 16, SkipPrecache: true}
+	utils.AssertEqual(t, false, hello.Wants(1, false))
+	utils.AssertEqual(t, true, hello.Wants(2, false))
+	utils.AssertEqual(t, true, hello.Wants(16, false))
+	utils.AssertEqual(t, false, hello.Wants(17, false))
+	utils.AssertEqual(t, false, hello.Wants(8, true))
+}
+
+func TestSerializeHello(t *testing.T) {
+	bytes, err := json.Marshal(ClientWorkResponse{Hello: &WorkerHello{ProtocolVersion: ProtocolVersion, Features: []string{FeatureAck}, MaxDifficulty: 64}})
+	utils.AssertEqual(t, nil, err)
+	var deserialized ClientWorkResponse
+	utils.AssertEqual(t, nil, json.Unmarshal(bytes, &deserialized))
+	utils.AssertEqual(t, ProtocolVersion, deserialized.Hello.ProtocolVersion)
+	utils.AssertEqual(t, []string{FeatureAck}, deserialized.Hello.Features)
+	utils.AssertEqual(t, 64, deserialized.Hello.MaxDifficulty)
+
+	// Other frames don't carry one
+	bytes, err = json.Marshal(ClientWorkResponse{AckMessageID: "1"})
+	utils.AssertEqual(t, nil, err)
+	var raw map[string]interface{}
+	utils.AssertEqual(t, nil, json.Unmarshal(bytes, &raw))
+	_, ok := raw["hello"]
+	utils.AssertEqual(t, false, ok)
+}
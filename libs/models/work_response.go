@@ -1,8 +1,39 @@
 package models
 
+// Reasons a client rejects a malformed work request instead of computing it
+const (
+	RejectInvalidHash       = "invalid_hash"
+	RejectInvalidDifficulty = "invalid_difficulty"
+	RejectMissingRequestID  = "missing_request_id"
+)
+
 // Work response sent from client -> server
 type ClientWorkResponse struct {
 	RequestID string `json:"request_id"`
 	Hash      string `json:"hash"`
 	Result    string `json:"result"`
+	// Acknowledges a message with this MessageID instead of responding with work
+	AckMessageID string `json:"ack_message_id,omitempty"`
+	// Rejects the work request with RequestID instead of responding with work, one of the Reject* reasons
+	Rejected string `json:"rejected,omitempty"`
+	// Work requests the client rejected since it started, by reason, sent along with rejections
+	RejectedCounts map[string]int `json:"rejected_counts,omitempty"`
+	// Reports how far the client got on RequestID instead of responding with work, sent while a long solve is still running
+	Progress *WorkProgress `json:"progress,omitempty"`
+	// Introduces the worker instead of responding with work, the first frame on every connection
+	Hello *WorkerHello `json:"hello,omitempty"`
+}
+
+// How far a client got on a solve, the hub waits longer for results of workers that keep reporting it
+type WorkProgress struct {
+	// Nonces tried so far, 0 if the client can't count them (GPUs)
+	Iterations uint64 `json:"iterations"`
+	// Since the client started solving
+	ElapsedMs int64 `json:"elapsed_ms"`
+}
+
+// Sent over HTTP by clients whose websocket is down, results are handled like the ones sent over the websocket
+// Every request counts as a heartbeat, so it can be sent without results while a solve is still running
+type WorkerFallbackRequest struct {
+	Results []ClientWorkResponse `json:"results"`
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	utils "github.com/bananocoin/boompow/libs/utils/testing"
@@ -25,3 +26,47 @@ func TestSerializeDeserializeWorkResponse(t *testing.T) {
 	utils.AssertEqual(t, "hash", deserialized["hash"])
 	utils.AssertEqual(t, "3", deserialized["result"])
 }
+
+func TestSerializeAck(t *testing.T) {
+	bytes, err := json.Marshal(ClientWorkResponse{AckMessageID: "abc"})
+	utils.AssertEqual(t, nil, err)
+
+	var deserialized ClientWorkResponse
+	err = json.Unmarshal(bytes, &deserialized)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "abc", deserialized.AckMessageID)
+
+	// Work responses don't carry the field
+	bytes, _ = json.Marshal(ClientWorkResponse{RequestID: "123"})
+	utils.AssertEqual(t, false, strings.Contains(string(bytes), "ack_message_id"))
+}
+
+func TestSerializeRejection(t *testing.T) {
+	bytes, err := json.Marshal(ClientWorkResponse{RequestID: "123", Rejected: RejectInvalidHash, RejectedCounts: map[string]int{RejectInvalidHash: 2}})
+	utils.AssertEqual(t, nil, err)
+
+	var deserialized ClientWorkResponse
+	err = json.Unmarshal(bytes, &deserialized)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "invalid_hash", deserialized.Rejected)
+	utils.AssertEqual(t, 2, deserialized.RejectedCounts[RejectInvalidHash])
+
+	// Work responses don't carry the fields
+	bytes, _ = json.Marshal(ClientWorkResponse{RequestID: "123"})
+	utils.AssertEqual(t, false, strings.Contains(string(bytes), "rejected"))
+}
+
+func TestSerializeWorkerFallbackRequest(t *testing.T) {
+	bytes, err := json.Marshal(WorkerFallbackRequest{Results: []ClientWorkResponse{{RequestID: "123", Result: "3"}}})
+	utils.AssertEqual(t, nil, err)
+
+	var deserialized WorkerFallbackRequest
+	err = json.Unmarshal(bytes, &deserialized)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 1, len(deserialized.Results))
+	utils.AssertEqual(t, "3", deserialized.Results[0].Result)
+
+	// Heartbeats have no results
+	err = json.Unmarshal([]byte(`{}`), &deserialized)
+	utils.AssertEqual(t, nil, err)
+}
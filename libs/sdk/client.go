@@ -0,0 +1,27 @@
+// Package sdk is a typed client for the BoomPoW GraphQL API, generated from the server's example operations
+package sdk
+
+import (
+	"net/http"
+
+	"github.com/Khan/genqlient/graphql"
+)
+
+type authedTransport struct {
+	wrapped http.RoundTripper
+	token   string
+}
+
+func (t *authedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", t.token)
+	return t.wrapped.RoundTrip(req)
+}
+
+// A client for the API at url, e.g. https://boompow.banano.cc/graphql
+// The token is a service token or API key for workGenerate, or the JWT from Login, empty for public operations
+func NewClient(url string, token string) graphql.Client {
+	if token == "" {
+		return graphql.NewClient(url, http.DefaultClient)
+	}
+	return graphql.NewClient(url, &http.Client{Transport: &authedTransport{wrapped: http.DefaultTransport, token: token}})
+}
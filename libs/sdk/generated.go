@@ -0,0 +1,457 @@
+// Code generated by github.com/Khan/genqlient, DO NOT EDIT.
+
+package sdk
+
+import (
+	"context"
+
+	"github.com/Khan/genqlient/graphql"
+)
+
+// DifficultyPresetsDifficultyPresetsDifficultyPreset includes the requested fields of the GraphQL type DifficultyPreset.
+type DifficultyPresetsDifficultyPresetsDifficultyPreset struct {
+	Name                 string `json:"name"`
+	Network              string `json:"network"`
+	Version              int    `json:"version"`
+	Threshold            string `json:"threshold"`
+	DifficultyMultiplier int    `json:"difficultyMultiplier"`
+	Current              bool   `json:"current"`
+}
+
+// GetName returns DifficultyPresetsDifficultyPresetsDifficultyPreset.Name, and is useful for accessing the field via an interface.
+func (v *DifficultyPresetsDifficultyPresetsDifficultyPreset) GetName() string { return v.Name }
+
+// GetNetwork returns DifficultyPresetsDifficultyPresetsDifficultyPreset.Network, and is useful for accessing the field via an interface.
+func (v *DifficultyPresetsDifficultyPresetsDifficultyPreset) GetNetwork() string { return v.Network }
+
+// GetVersion returns DifficultyPresetsDifficultyPresetsDifficultyPreset.Version, and is useful for accessing the field via an interface.
+func (v *DifficultyPresetsDifficultyPresetsDifficultyPreset) GetVersion() int { return v.Version }
+
+// GetThreshold returns DifficultyPresetsDifficultyPresetsDifficultyPreset.Threshold, and is useful for accessing the field via an interface.
+func (v *DifficultyPresetsDifficultyPresetsDifficultyPreset) GetThreshold() string {
+	return v.Threshold
+}
+
+// GetDifficultyMultiplier returns DifficultyPresetsDifficultyPresetsDifficultyPreset.DifficultyMultiplier, and is useful for accessing the field via an interface.
+func (v *DifficultyPresetsDifficultyPresetsDifficultyPreset) GetDifficultyMultiplier() int {
+	return v.DifficultyMultiplier
+}
+
+// GetCurrent returns DifficultyPresetsDifficultyPresetsDifficultyPreset.Current, and is useful for accessing the field via an interface.
+func (v *DifficultyPresetsDifficultyPresetsDifficultyPreset) GetCurrent() bool { return v.Current }
+
+// DifficultyPresetsResponse is returned by DifficultyPresets on success.
+type DifficultyPresetsResponse struct {
+	DifficultyPresets []DifficultyPresetsDifficultyPresetsDifficultyPreset `json:"difficultyPresets"`
+}
+
+// GetDifficultyPresets returns DifficultyPresetsResponse.DifficultyPresets, and is useful for accessing the field via an interface.
+func (v *DifficultyPresetsResponse) GetDifficultyPresets() []DifficultyPresetsDifficultyPresetsDifficultyPreset {
+	return v.DifficultyPresets
+}
+
+// GenerateOrGetServiceTokenResponse is returned by GenerateOrGetServiceToken on success.
+type GenerateOrGetServiceTokenResponse struct {
+	GenerateOrGetServiceToken string `json:"generateOrGetServiceToken"`
+}
+
+// GetGenerateOrGetServiceToken returns GenerateOrGetServiceTokenResponse.GenerateOrGetServiceToken, and is useful for accessing the field via an interface.
+func (v *GenerateOrGetServiceTokenResponse) GetGenerateOrGetServiceToken() string {
+	return v.GenerateOrGetServiceToken
+}
+
+type LoginInput struct {
+	Email         string  `json:"email"`
+	Password      string  `json:"password"`
+	TwoFactorCode *string `json:"twoFactorCode"`
+}
+
+// GetEmail returns LoginInput.Email, and is useful for accessing the field via an interface.
+func (v *LoginInput) GetEmail() string { return v.Email }
+
+// GetPassword returns LoginInput.Password, and is useful for accessing the field via an interface.
+func (v *LoginInput) GetPassword() string { return v.Password }
+
+// GetTwoFactorCode returns LoginInput.TwoFactorCode, and is useful for accessing the field via an interface.
+func (v *LoginInput) GetTwoFactorCode() *string { return v.TwoFactorCode }
+
+// LoginLoginLoginResponse includes the requested fields of the GraphQL type LoginResponse.
+type LoginLoginLoginResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// GetToken returns LoginLoginLoginResponse.Token, and is useful for accessing the field via an interface.
+func (v *LoginLoginLoginResponse) GetToken() string { return v.Token }
+
+// GetRefreshToken returns LoginLoginLoginResponse.RefreshToken, and is useful for accessing the field via an interface.
+func (v *LoginLoginLoginResponse) GetRefreshToken() string { return v.RefreshToken }
+
+// LoginResponse is returned by Login on success.
+type LoginResponse struct {
+	Login LoginLoginLoginResponse `json:"login"`
+}
+
+// GetLogin returns LoginResponse.Login, and is useful for accessing the field via an interface.
+func (v *LoginResponse) GetLogin() LoginLoginLoginResponse { return v.Login }
+
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// GetRefreshToken returns RefreshTokenInput.RefreshToken, and is useful for accessing the field via an interface.
+func (v *RefreshTokenInput) GetRefreshToken() string { return v.RefreshToken }
+
+// RefreshTokenRefreshTokenTokenPair includes the requested fields of the GraphQL type TokenPair.
+type RefreshTokenRefreshTokenTokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// GetToken returns RefreshTokenRefreshTokenTokenPair.Token, and is useful for accessing the field via an interface.
+func (v *RefreshTokenRefreshTokenTokenPair) GetToken() string { return v.Token }
+
+// GetRefreshToken returns RefreshTokenRefreshTokenTokenPair.RefreshToken, and is useful for accessing the field via an interface.
+func (v *RefreshTokenRefreshTokenTokenPair) GetRefreshToken() string { return v.RefreshToken }
+
+// RefreshTokenResponse is returned by RefreshToken on success.
+type RefreshTokenResponse struct {
+	RefreshToken RefreshTokenRefreshTokenTokenPair `json:"refreshToken"`
+}
+
+// GetRefreshToken returns RefreshTokenResponse.RefreshToken, and is useful for accessing the field via an interface.
+func (v *RefreshTokenResponse) GetRefreshToken() RefreshTokenRefreshTokenTokenPair {
+	return v.RefreshToken
+}
+
+type ValidateWorkInput struct {
+	Hash                 string `json:"hash"`
+	Work                 string `json:"work"`
+	DifficultyMultiplier int    `json:"difficultyMultiplier"`
+}
+
+// GetHash returns ValidateWorkInput.Hash, and is useful for accessing the field via an interface.
+func (v *ValidateWorkInput) GetHash() string { return v.Hash }
+
+// GetWork returns ValidateWorkInput.Work, and is useful for accessing the field via an interface.
+func (v *ValidateWorkInput) GetWork() string { return v.Work }
+
+// GetDifficultyMultiplier returns ValidateWorkInput.DifficultyMultiplier, and is useful for accessing the field via an interface.
+func (v *ValidateWorkInput) GetDifficultyMultiplier() int { return v.DifficultyMultiplier }
+
+// ValidateWorkResponse is returned by ValidateWork on success.
+type ValidateWorkResponse struct {
+	ValidateWork bool `json:"validateWork"`
+}
+
+// GetValidateWork returns ValidateWorkResponse.ValidateWork, and is useful for accessing the field via an interface.
+func (v *ValidateWorkResponse) GetValidateWork() bool { return v.ValidateWork }
+
+type WorkGenerateInput struct {
+	Hash                 string  `json:"hash"`
+	DifficultyMultiplier *int    `json:"difficultyMultiplier"`
+	Preset               *string `json:"preset"`
+	Difficulty           *string `json:"difficulty"`
+	BlockAward           *bool   `json:"blockAward"`
+}
+
+// GetHash returns WorkGenerateInput.Hash, and is useful for accessing the field via an interface.
+func (v *WorkGenerateInput) GetHash() string { return v.Hash }
+
+// GetDifficultyMultiplier returns WorkGenerateInput.DifficultyMultiplier, and is useful for accessing the field via an interface.
+func (v *WorkGenerateInput) GetDifficultyMultiplier() *int { return v.DifficultyMultiplier }
+
+// GetPreset returns WorkGenerateInput.Preset, and is useful for accessing the field via an interface.
+func (v *WorkGenerateInput) GetPreset() *string { return v.Preset }
+
+// GetDifficulty returns WorkGenerateInput.Difficulty, and is useful for accessing the field via an interface.
+func (v *WorkGenerateInput) GetDifficulty() *string { return v.Difficulty }
+
+// GetBlockAward returns WorkGenerateInput.BlockAward, and is useful for accessing the field via an interface.
+func (v *WorkGenerateInput) GetBlockAward() *bool { return v.BlockAward }
+
+// WorkGenerateResponse is returned by WorkGenerate on success.
+type WorkGenerateResponse struct {
+	WorkGenerate string `json:"workGenerate"`
+}
+
+// GetWorkGenerate returns WorkGenerateResponse.WorkGenerate, and is useful for accessing the field via an interface.
+func (v *WorkGenerateResponse) GetWorkGenerate() string { return v.WorkGenerate }
+
+// WorkSourcesResponse is returned by WorkSources on success.
+type WorkSourcesResponse struct {
+	WorkSources []WorkSourcesWorkSourcesWorkSource `json:"workSources"`
+}
+
+// GetWorkSources returns WorkSourcesResponse.WorkSources, and is useful for accessing the field via an interface.
+func (v *WorkSourcesResponse) GetWorkSources() []WorkSourcesWorkSourcesWorkSource {
+	return v.WorkSources
+}
+
+// WorkSourcesWorkSourcesWorkSource includes the requested fields of the GraphQL type WorkSource.
+type WorkSourcesWorkSourcesWorkSource struct {
+	Name          string `json:"name"`
+	DailyQuota    *int   `json:"dailyQuota"`
+	RequestsToday int    `json:"requestsToday"`
+	CreatedAt     string `json:"createdAt"`
+}
+
+// GetName returns WorkSourcesWorkSourcesWorkSource.Name, and is useful for accessing the field via an interface.
+func (v *WorkSourcesWorkSourcesWorkSource) GetName() string { return v.Name }
+
+// GetDailyQuota returns WorkSourcesWorkSourcesWorkSource.DailyQuota, and is useful for accessing the field via an interface.
+func (v *WorkSourcesWorkSourcesWorkSource) GetDailyQuota() *int { return v.DailyQuota }
+
+// GetRequestsToday returns WorkSourcesWorkSourcesWorkSource.RequestsToday, and is useful for accessing the field via an interface.
+func (v *WorkSourcesWorkSourcesWorkSource) GetRequestsToday() int { return v.RequestsToday }
+
+// GetCreatedAt returns WorkSourcesWorkSourcesWorkSource.CreatedAt, and is useful for accessing the field via an interface.
+func (v *WorkSourcesWorkSourcesWorkSource) GetCreatedAt() string { return v.CreatedAt }
+
+// __LoginInput is used internally by genqlient
+type __LoginInput struct {
+	Input LoginInput `json:"input"`
+}
+
+// GetInput returns __LoginInput.Input, and is useful for accessing the field via an interface.
+func (v *__LoginInput) GetInput() LoginInput { return v.Input }
+
+// __RefreshTokenInput is used internally by genqlient
+type __RefreshTokenInput struct {
+	Input RefreshTokenInput `json:"input"`
+}
+
+// GetInput returns __RefreshTokenInput.Input, and is useful for accessing the field via an interface.
+func (v *__RefreshTokenInput) GetInput() RefreshTokenInput { return v.Input }
+
+// __ValidateWorkInput is used internally by genqlient
+type __ValidateWorkInput struct {
+	Input ValidateWorkInput `json:"input"`
+}
+
+// GetInput returns __ValidateWorkInput.Input, and is useful for accessing the field via an interface.
+func (v *__ValidateWorkInput) GetInput() ValidateWorkInput { return v.Input }
+
+// __WorkGenerateInput is used internally by genqlient
+type __WorkGenerateInput struct {
+	Input WorkGenerateInput `json:"input"`
+}
+
+// GetInput returns __WorkGenerateInput.Input, and is useful for accessing the field via an interface.
+func (v *__WorkGenerateInput) GetInput() WorkGenerateInput { return v.Input }
+
+func DifficultyPresets(
+	ctx context.Context,
+	client graphql.Client,
+) (*DifficultyPresetsResponse, error) {
+	req := &graphql.Request{
+		OpName: "DifficultyPresets",
+		Query: `
+query DifficultyPresets {
+	difficultyPresets {
+		name
+		network
+		version
+		threshold
+		difficultyMultiplier
+		current
+	}
+}
+`,
+	}
+	var err error
+
+	var data DifficultyPresetsResponse
+	resp := &graphql.Response{Data: &data}
+
+	err = client.MakeRequest(
+		ctx,
+		req,
+		resp,
+	)
+
+	return &data, err
+}
+
+func GenerateOrGetServiceToken(
+	ctx context.Context,
+	client graphql.Client,
+) (*GenerateOrGetServiceTokenResponse, error) {
+	req := &graphql.Request{
+		OpName: "GenerateOrGetServiceToken",
+		Query: `
+mutation GenerateOrGetServiceToken {
+	generateOrGetServiceToken
+}
+`,
+	}
+	var err error
+
+	var data GenerateOrGetServiceTokenResponse
+	resp := &graphql.Response{Data: &data}
+
+	err = client.MakeRequest(
+		ctx,
+		req,
+		resp,
+	)
+
+	return &data, err
+}
+
+func Login(
+	ctx context.Context,
+	client graphql.Client,
+	input LoginInput,
+) (*LoginResponse, error) {
+	req := &graphql.Request{
+		OpName: "Login",
+		Query: `
+mutation Login ($input: LoginInput!) {
+	login(input: $input) {
+		token
+		refreshToken
+	}
+}
+`,
+		Variables: &__LoginInput{
+			Input: input,
+		},
+	}
+	var err error
+
+	var data LoginResponse
+	resp := &graphql.Response{Data: &data}
+
+	err = client.MakeRequest(
+		ctx,
+		req,
+		resp,
+	)
+
+	return &data, err
+}
+
+func RefreshToken(
+	ctx context.Context,
+	client graphql.Client,
+	input RefreshTokenInput,
+) (*RefreshTokenResponse, error) {
+	req := &graphql.Request{
+		OpName: "RefreshToken",
+		Query: `
+mutation RefreshToken ($input: RefreshTokenInput!) {
+	refreshToken(input: $input) {
+		token
+		refreshToken
+	}
+}
+`,
+		Variables: &__RefreshTokenInput{
+			Input: input,
+		},
+	}
+	var err error
+
+	var data RefreshTokenResponse
+	resp := &graphql.Response{Data: &data}
+
+	err = client.MakeRequest(
+		ctx,
+		req,
+		resp,
+	)
+
+	return &data, err
+}
+
+func ValidateWork(
+	ctx context.Context,
+	client graphql.Client,
+	input ValidateWorkInput,
+) (*ValidateWorkResponse, error) {
+	req := &graphql.Request{
+		OpName: "ValidateWork",
+		Query: `
+query ValidateWork ($input: ValidateWorkInput!) {
+	validateWork(input: $input)
+}
+`,
+		Variables: &__ValidateWorkInput{
+			Input: input,
+		},
+	}
+	var err error
+
+	var data ValidateWorkResponse
+	resp := &graphql.Response{Data: &data}
+
+	err = client.MakeRequest(
+		ctx,
+		req,
+		resp,
+	)
+
+	return &data, err
+}
+
+func WorkGenerate(
+	ctx context.Context,
+	client graphql.Client,
+	input WorkGenerateInput,
+) (*WorkGenerateResponse, error) {
+	req := &graphql.Request{
+		OpName: "WorkGenerate",
+		Query: `
+mutation WorkGenerate ($input: WorkGenerateInput!) {
+	workGenerate(input: $input)
+}
+`,
+		Variables: &__WorkGenerateInput{
+			Input: input,
+		},
+	}
+	var err error
+
+	var data WorkGenerateResponse
+	resp := &graphql.Response{Data: &data}
+
+	err = client.MakeRequest(
+		ctx,
+		req,
+		resp,
+	)
+
+	return &data, err
+}
+
+func WorkSources(
+	ctx context.Context,
+	client graphql.Client,
+) (*WorkSourcesResponse, error) {
+	req := &graphql.Request{
+		OpName: "WorkSources",
+		Query: `
+query WorkSources {
+	workSources {
+		name
+		dailyQuota
+		requestsToday
+		createdAt
+	}
+}
+`,
+	}
+	var err error
+
+	var data WorkSourcesResponse
+	resp := &graphql.Response{Data: &data}
+
+	err = client.MakeRequest(
+		ctx,
+		req,
+		resp,
+	)
+
+	return &data, err
+}
@@ -0,0 +1,8 @@
+module github.com/bananocoin/boompow/libs/sdk
+
+go 1.19
+
+require (
+	github.com/Khan/genqlient v0.5.0
+	github.com/vektah/gqlparser/v2 v2.5.1 // indirect
+)
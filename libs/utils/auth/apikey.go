@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Prefix of API keys, they're sent in the Authorization header like the service tokens that came before them
+const APIKeyPrefix = "service:"
+
+// GenerateAPIKey generates a random key, only its hash is stored
+func GenerateAPIKey() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return APIKeyPrefix + hex.EncodeToString(bytes), nil
+}
+
+// HashAPIKey is what an API key is looked up by
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+	return hex.EncodeToString(sum[:])
+}
+
+// The start of the key shown in listings, so keys can be told apart without being stored
+func APIKeyHint(key string) string {
+	key = strings.TrimPrefix(key, APIKeyPrefix)
+	if len(key) > 8 {
+		key = key[:8]
+	}
+	return key
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, err := GenerateAPIKey()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, strings.HasPrefix(key, APIKeyPrefix))
+	utils.AssertEqual(t, len(APIKeyPrefix)+64, len(key))
+	other, _ := GenerateAPIKey()
+	utils.AssertNotEqual(t, key, other)
+
+	utils.AssertEqual(t, HashAPIKey(key), HashAPIKey(" "+key+"\n"))
+	utils.AssertNotEqual(t, HashAPIKey(key), HashAPIKey(other))
+	utils.AssertEqual(t, key[len(APIKeyPrefix):len(APIKeyPrefix)+8], APIKeyHint(key))
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestEncryptSecret(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	encrypted, err := EncryptSecret(key, "JBSWY3DPEHPK3PXP")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, IsEncryptedSecret(encrypted))
+	utils.AssertEqual(t, false, strings.Contains(encrypted, "JBSWY3DPEHPK3PXP"))
+	// Every encryption has its own nonce
+	again, _ := EncryptSecret(key, "JBSWY3DPEHPK3PXP")
+	utils.AssertNotEqual(t, encrypted, again)
+
+	decrypted, err := DecryptSecret(key, encrypted)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "JBSWY3DPEHPK3PXP", decrypted)
+	_, err = DecryptSecret(bytes.Repeat([]byte{2}, 32), encrypted)
+	utils.AssertNotEqual(t, nil, err)
+	_, err = DecryptSecret(key, encrypted[:len(encrypted)-2])
+	utils.AssertNotEqual(t, nil, err)
+
+	// Secrets stored before encryption still work
+	decrypted, err = DecryptSecret(key, "JBSWY3DPEHPK3PXP")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "JBSWY3DPEHPK3PXP", decrypted)
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"log"
 	"time"
 
@@ -15,14 +16,45 @@ var (
 	SecretKey = utils.GetJwtKey()
 )
 
+// How long access tokens from GenerateToken are valid, sessions are kept alive with refresh tokens
+const TokenTTL = time.Minute * 15
+
+// Tokens without an iat claim were issued before access tokens became short lived
+const legacyTokenTTL = time.Hour * 24
+
+// Returned for tokens that were valid, so clients know to refresh instead of logging in again
+var ErrTokenExpired = errors.New("token is expired")
+
+// Claim of access tokens whose session was signed in with a second factor
+const twoFactorClaim = "2fa"
+
 // GenerateToken generates a jwt token and assign a email to it's claims and return it
 func GenerateToken(email string, nowFunc func() time.Time) (string, error) {
+	return GenerateTokenWithTTL(email, TokenTTL, nowFunc)
+}
+
+// GenerateSessionToken is GenerateToken for sessions, twoFactor says whether the session was signed in with a second factor
+func GenerateSessionToken(email string, twoFactor bool, nowFunc func() time.Time) (string, error) {
+	return generateToken(email, TokenTTL, twoFactor, nowFunc)
+}
+
+// GenerateTokenWithTTL is GenerateToken for tokens that have to outlive an access token, e.g. in emailed links
+func GenerateTokenWithTTL(email string, ttl time.Duration, nowFunc func() time.Time) (string, error) {
+	return generateToken(email, ttl, false, nowFunc)
+}
+
+func generateToken(email string, ttl time.Duration, twoFactor bool, nowFunc func() time.Time) (string, error) {
 	token := jwt.New(jwt.SigningMethodHS256)
 	/* Create a map to store our claims */
 	claims := token.Claims.(jwt.MapClaims)
 	/* Set token claims */
+	now := nowFunc()
 	claims["email"] = email
-	claims["exp"] = nowFunc().Add(time.Hour * 24).Unix()
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(ttl).Unix()
+	if twoFactor {
+		claims[twoFactorClaim] = true
+	}
 	tokenString, err := token.SignedString(SecretKey)
 	if err != nil {
 		log.Fatal("Error in Generating key")
@@ -31,20 +63,119 @@ func GenerateToken(email string, nowFunc func() time.Time) (string, error) {
 	return tokenString, nil
 }
 
-// ParseToken parses a jwt token and returns the email in it's claims
-func ParseToken(tokenStr string) (string, error) {
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+// Purpose of tokens that can only open websocket connections
+const WebsocketPurpose = "ws"
+
+// Purpose of tokens in unsubscribe links, they can only turn off emails
+const UnsubscribePurpose = "unsubscribe"
+
+var errWrongPurpose = errors.New("token is not valid for this purpose")
+
+// GenerateScopedToken generates a short lived jwt token that can only be used for purpose
+// It's stamped with when it was issued, so revoking a user's sessions revokes their scoped tokens too
+func GenerateScopedToken(email string, purpose string, ttl time.Duration, nowFunc func() time.Time) (string, error) {
+	now := nowFunc()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"email":   email,
+		"purpose": purpose,
+		"iat":     now.Unix(),
+		"exp":     now.Add(ttl).Unix(),
+	})
+	return token.SignedString(SecretKey)
+}
+
+// Expiry is checked against nowFunc instead of the wall clock, so tests can parse tokens right at the end of their life
+func parseClaims(tokenStr string, nowFunc func() time.Time) (jwt.MapClaims, error) {
+	token, err := jwt.NewParser(jwt.WithoutClaimsValidation()).Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
 		return SecretKey, nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	now := nowFunc().Unix()
+	if !claims.VerifyExpiresAt(now, false) || !claims.VerifyIssuedAt(now, false) || !claims.VerifyNotBefore(now, false) {
+		return nil, ErrTokenExpired
+	}
+	if _, ok := claims["email"].(string); !ok {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// ParseToken parses a jwt token and returns the email in it's claims
+// Scoped tokens are rejected, they are only valid for their purpose
+func ParseToken(tokenStr string, nowFunc func() time.Time) (string, error) {
+	claims, err := parseClaims(tokenStr, nowFunc)
 	if err != nil {
 		return "", err
 	}
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		email := claims["email"].(string)
-		return email, nil
-	} else {
+	if _, scoped := claims["purpose"]; scoped {
+		return "", errWrongPurpose
+	}
+	return claims["email"].(string), nil
+}
+
+// What an access token says about its session
+type Session struct {
+	Email string
+	// To reject tokens of revoked sessions
+	IssuedAt time.Time
+	// Whether the session was signed in with a second factor
+	TwoFactor bool
+}
+
+// ParseSessionToken is ParseToken that also returns when the token was issued and how its session was signed in
+func ParseSessionToken(tokenStr string, nowFunc func() time.Time) (Session, error) {
+	claims, err := parseClaims(tokenStr, nowFunc)
+	if err != nil {
+		return Session{}, err
+	}
+	if _, scoped := claims["purpose"]; scoped {
+		return Session{}, errWrongPurpose
+	}
+	twoFactor, _ := claims[twoFactorClaim].(bool)
+	if iat, ok := claims["iat"].(float64); ok {
+		return Session{Email: claims["email"].(string), IssuedAt: time.Unix(int64(iat), 0), TwoFactor: twoFactor}, nil
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return Session{}, errors.New("invalid token")
+	}
+	// Legacy tokens were always issued for legacyTokenTTL
+	return Session{Email: claims["email"].(string), IssuedAt: time.Unix(int64(exp), 0).Add(-legacyTokenTTL)}, nil
+}
+
+// ParseScopedToken parses a token generated for purpose and returns the email in it's claims
+func ParseScopedToken(tokenStr string, purpose string, nowFunc func() time.Time) (string, error) {
+	claims, err := parseClaims(tokenStr, nowFunc)
+	if err != nil {
 		return "", err
 	}
+	if claims["purpose"] != purpose {
+		return "", errWrongPurpose
+	}
+	return claims["email"].(string), nil
+}
+
+// ParseScopedSessionToken is ParseScopedToken that also returns when the token was issued
+// Tokens without an iat claim are rejected, their session can't be checked
+func ParseScopedSessionToken(tokenStr string, purpose string, nowFunc func() time.Time) (Session, error) {
+	claims, err := parseClaims(tokenStr, nowFunc)
+	if err != nil {
+		return Session{}, err
+	}
+	if claims["purpose"] != purpose {
+		return Session{}, errWrongPurpose
+	}
+	iat, ok := claims["iat"].(float64)
+	if !ok {
+		return Session{}, errors.New("invalid token")
+	}
+	return Session{Email: claims["email"].(string), IssuedAt: time.Unix(int64(iat), 0)}, nil
 }
 
 // Generate random 32-byte hex string
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 test vectors, truncated to 6 digits
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	code, err := TOTPCode(secret, time.Unix(59, 0))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "287082", code)
+	code, _ = TOTPCode(secret, time.Unix(1111111109, 0))
+	utils.AssertEqual(t, "081804", code)
+	code, _ = TOTPCode(secret, time.Unix(2000000000, 0))
+	utils.AssertEqual(t, "279037", code)
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	utils.AssertEqual(t, nil, err)
+	now := time.Unix(1664611200, 0)
+	code, _ := TOTPCode(secret, now)
+	utils.AssertEqual(t, true, ValidateTOTP(secret, code, now))
+	// A step off is fine, two aren't
+	utils.AssertEqual(t, true, ValidateTOTP(secret, code, now.Add(30*time.Second)))
+	utils.AssertEqual(t, false, ValidateTOTP(secret, code, now.Add(61*time.Second)))
+	utils.AssertEqual(t, false, ValidateTOTP(secret, "", now))
+	utils.AssertEqual(t, false, ValidateTOTP("not base32!", code, now))
+}
+
+func TestTOTPURL(t *testing.T) {
+	utils.AssertEqual(t, "otpauth://totp/BoomPow:joe@gmail.com?issuer=BoomPow&secret=ABC", TOTPURL("BoomPow", "joe@gmail.com", "ABC"))
+}
+
+func TestBackupCodes(t *testing.T) {
+	codes, err := GenerateBackupCodes(10)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 10, len(codes))
+	utils.AssertEqual(t, 11, len(codes[0]))
+	utils.AssertNotEqual(t, codes[0], codes[1])
+	// Typed in differently
+	utils.AssertEqual(t, HashBackupCode(codes[0]), HashBackupCode(" "+strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))))
+	utils.AssertNotEqual(t, HashBackupCode(codes[0]), HashBackupCode(codes[1]))
+}
@@ -0,0 +1,20 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2022, 10, 1, 23, 59, 59, 0, time.UTC)
+	fake := NewFake(start)
+	utils.AssertEqual(t, start, fake.Now())
+
+	fake.Advance(time.Second)
+	utils.AssertEqual(t, time.Date(2022, 10, 2, 0, 0, 0, 0, time.UTC), fake.Now())
+
+	fake.Set(start)
+	utils.AssertEqual(t, start, fake.Now())
+}
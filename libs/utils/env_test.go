@@ -2,7 +2,11 @@ package utils
 
 import (
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	utils "github.com/bananocoin/boompow/libs/utils/testing"
 )
@@ -54,3 +58,262 @@ func TestGetSmtpConnInformation(t *testing.T) {
 	utils.AssertEqual(t, "joe", connInfo.Username)
 	utils.AssertEqual(t, "jeff", connInfo.Password)
 }
+
+func TestGetRateLimitSettings(t *testing.T) {
+	utils.AssertEqual(t, "reject", GetRateLimitMode())
+	utils.AssertEqual(t, 10, GetRateLimitQueueSize())
+	utils.AssertEqual(t, 30*time.Second, GetRateLimitMaxWait())
+
+	os.Setenv("BPOW_RATE_LIMIT_MODE", "Queue")
+	os.Setenv("BPOW_RATE_LIMIT_QUEUE_SIZE", "5")
+	os.Setenv("BPOW_RATE_LIMIT_MAX_WAIT", "2m")
+	defer os.Unsetenv("BPOW_RATE_LIMIT_MODE")
+	defer os.Unsetenv("BPOW_RATE_LIMIT_QUEUE_SIZE")
+	defer os.Unsetenv("BPOW_RATE_LIMIT_MAX_WAIT")
+	utils.AssertEqual(t, "queue", GetRateLimitMode())
+	utils.AssertEqual(t, 5, GetRateLimitQueueSize())
+	utils.AssertEqual(t, 2*time.Minute, GetRateLimitMaxWait())
+
+	os.Setenv("BPOW_RATE_LIMIT_MAX_WAIT", "bad")
+	utils.AssertEqual(t, 30*time.Second, GetRateLimitMaxWait())
+}
+
+func TestGetPowChallengeDifficulty(t *testing.T) {
+	utils.AssertEqual(t, uint64(0), GetPowChallengeDifficulty())
+
+	os.Setenv("BPOW_POW_CHALLENGE_DIFFICULTY", "fff0000000000000")
+	defer os.Unsetenv("BPOW_POW_CHALLENGE_DIFFICULTY")
+	utils.AssertEqual(t, uint64(0xfff0000000000000), GetPowChallengeDifficulty())
+
+	os.Setenv("BPOW_POW_CHALLENGE_DIFFICULTY", "nothex")
+	utils.AssertEqual(t, uint64(0), GetPowChallengeDifficulty())
+}
+
+func TestGetSecret(t *testing.T) {
+	value, err := GetSecret("MY_SECRET")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "", value)
+
+	path := filepath.Join(t.TempDir(), "secret")
+	os.WriteFile(path, []byte("from file\n"), 0600)
+	os.Setenv("MY_SECRET_FILE", path)
+	defer os.Unsetenv("MY_SECRET_FILE")
+	value, err = GetSecret("MY_SECRET")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "from file", value)
+
+	// The variable itself wins
+	os.Setenv("MY_SECRET", "from env")
+	defer os.Unsetenv("MY_SECRET")
+	value, err = GetSecret("MY_SECRET")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "from env", value)
+
+	os.Unsetenv("MY_SECRET")
+	os.Setenv("MY_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+	_, err = GetSecret("MY_SECRET")
+	utils.AssertNotEqual(t, nil, err)
+}
+
+func TestGetAdminEmails(t *testing.T) {
+	os.Setenv("BPOW_ADMIN_EMAILS", "a@example.com")
+	os.Setenv("BPOW_BOOTSTRAP_ADMIN_EMAIL", "Root@example.com")
+	defer os.Unsetenv("BPOW_ADMIN_EMAILS")
+	defer os.Unsetenv("BPOW_BOOTSTRAP_ADMIN_EMAIL")
+	utils.AssertEqual(t, []string{"a@example.com", "root@example.com"}, GetAdminEmails())
+
+	// Spaces, case and empty entries from a hand written list
+	os.Setenv("BPOW_ADMIN_EMAILS", " A@Example.com, ,b@example.com,")
+	utils.AssertEqual(t, []string{"a@example.com", "b@example.com", "root@example.com"}, GetAdminEmails())
+}
+
+func TestDevLoginEnabled(t *testing.T) {
+	utils.AssertEqual(t, false, DevLoginEnabled())
+	os.Setenv("BPOW_DEV_LOGIN", "true")
+	defer os.Unsetenv("BPOW_DEV_LOGIN")
+	utils.AssertEqual(t, true, DevLoginEnabled())
+	utils.AssertEqual(t, DevAdminEmail, GetAdminEmails()[len(GetAdminEmails())-1])
+
+	// Never outside of development
+	os.Setenv("ENVIRONMENT", "production")
+	defer os.Unsetenv("ENVIRONMENT")
+	utils.AssertEqual(t, false, DevLoginEnabled())
+}
+
+func TestGetWorkSubmitters(t *testing.T) {
+	os.Setenv("BPOW_WORK_SUBMITTERS", "A@example.com,b@example.com")
+	defer os.Unsetenv("BPOW_WORK_SUBMITTERS")
+	utils.AssertEqual(t, []string{"a@example.com", "b@example.com"}, GetWorkSubmitters())
+}
+
+func TestGetPayoutHourUTC(t *testing.T) {
+	utils.AssertEqual(t, 8, GetPayoutHourUTC())
+
+	os.Setenv("BPOW_PAYOUT_HOUR_UTC", "20")
+	defer os.Unsetenv("BPOW_PAYOUT_HOUR_UTC")
+	utils.AssertEqual(t, 20, GetPayoutHourUTC())
+
+	os.Setenv("BPOW_PAYOUT_HOUR_UTC", "24")
+	utils.AssertEqual(t, 8, GetPayoutHourUTC())
+}
+
+func TestGetPayoutSchedule(t *testing.T) {
+	utils.AssertEqual(t, "0 8 * * *", GetPayoutSchedule())
+	os.Setenv("BPOW_PAYOUT_HOUR_UTC", "20")
+	defer os.Unsetenv("BPOW_PAYOUT_HOUR_UTC")
+	utils.AssertEqual(t, "0 20 * * *", GetPayoutSchedule())
+	os.Setenv("BPOW_PAYOUT_SCHEDULE", "0 */12 * * *")
+	defer os.Unsetenv("BPOW_PAYOUT_SCHEDULE")
+	utils.AssertEqual(t, "0 */12 * * *", GetPayoutSchedule())
+}
+
+func TestGetPublicStatsNoisePercent(t *testing.T) {
+	utils.AssertEqual(t, 0.0, GetPublicStatsNoisePercent())
+
+	os.Setenv("BPOW_PUBLIC_STATS_NOISE_PERCENT", "2.5")
+	defer os.Unsetenv("BPOW_PUBLIC_STATS_NOISE_PERCENT")
+	utils.AssertEqual(t, 2.5, GetPublicStatsNoisePercent())
+
+	os.Setenv("BPOW_PUBLIC_STATS_NOISE_PERCENT", "80")
+	utils.AssertEqual(t, 0.0, GetPublicStatsNoisePercent())
+}
+
+func TestGetGeoIPDatabasePath(t *testing.T) {
+	utils.AssertEqual(t, "", GetGeoIPDatabasePath())
+
+	os.Setenv("BPOW_GEOIP_DB_PATH", "/data/GeoLite2-Country.mmdb")
+	defer os.Unsetenv("BPOW_GEOIP_DB_PATH")
+	utils.AssertEqual(t, "/data/GeoLite2-Country.mmdb", GetGeoIPDatabasePath())
+}
+
+func TestGetEmailLanguage(t *testing.T) {
+	utils.AssertEqual(t, "en", GetEmailLanguage())
+
+	os.Setenv("BPOW_EMAIL_LANGUAGE", " ES ")
+	defer os.Unsetenv("BPOW_EMAIL_LANGUAGE")
+	utils.AssertEqual(t, "es", GetEmailLanguage())
+}
+
+func TestPreflightSettings(t *testing.T) {
+	utils.AssertEqual(t, false, PreflightOnStartup())
+	utils.AssertEqual(t, "", GetPreflightEmail())
+
+	os.Setenv("BPOW_PREFLIGHT", "true")
+	os.Setenv("BPOW_PREFLIGHT_EMAIL", "sink@example.com")
+	defer os.Unsetenv("BPOW_PREFLIGHT")
+	defer os.Unsetenv("BPOW_PREFLIGHT_EMAIL")
+	utils.AssertEqual(t, true, PreflightOnStartup())
+	utils.AssertEqual(t, "sink@example.com", GetPreflightEmail())
+}
+
+func TestGetBoostPricing(t *testing.T) {
+	utils.AssertEqual(t, 0.0, GetBoostPriceBananoPerMinute())
+	utils.AssertEqual(t, 60, GetBoostDailyCapMinutes())
+
+	os.Setenv("BPOW_BOOST_PRICE_BANANO_PER_MINUTE", "0.5")
+	os.Setenv("BPOW_BOOST_DAILY_CAP_MINUTES", "120")
+	defer os.Unsetenv("BPOW_BOOST_PRICE_BANANO_PER_MINUTE")
+	defer os.Unsetenv("BPOW_BOOST_DAILY_CAP_MINUTES")
+	utils.AssertEqual(t, 0.5, GetBoostPriceBananoPerMinute())
+	utils.AssertEqual(t, 120, GetBoostDailyCapMinutes())
+
+	os.Setenv("BPOW_BOOST_PRICE_BANANO_PER_MINUTE", "-1")
+	os.Setenv("BPOW_BOOST_DAILY_CAP_MINUTES", "0")
+	utils.AssertEqual(t, 0.0, GetBoostPriceBananoPerMinute())
+	utils.AssertEqual(t, 60, GetBoostDailyCapMinutes())
+}
+
+func TestGetStaleAccountDays(t *testing.T) {
+	utils.AssertEqual(t, 0, GetStaleAccountRemindDays())
+	utils.AssertEqual(t, 14, GetStaleAccountDisableDays())
+	utils.AssertEqual(t, 30, GetStaleAccountAnonymizeDays())
+
+	os.Setenv("BPOW_STALE_ACCOUNT_REMIND_DAYS", "60")
+	os.Setenv("BPOW_STALE_ACCOUNT_DISABLE_DAYS", "0")
+	defer os.Unsetenv("BPOW_STALE_ACCOUNT_REMIND_DAYS")
+	defer os.Unsetenv("BPOW_STALE_ACCOUNT_DISABLE_DAYS")
+	utils.AssertEqual(t, 60, GetStaleAccountRemindDays())
+	// Accounts aren't disabled the day they're reminded
+	utils.AssertEqual(t, 14, GetStaleAccountDisableDays())
+}
+
+func TestHubSettings(t *testing.T) {
+	utils.AssertEqual(t, "local", GetHubMode())
+	hostname, _ := os.Hostname()
+	utils.AssertEqual(t, hostname, GetReplicaID())
+
+	os.Setenv("BPOW_HUB_MODE", "Distributed")
+	os.Setenv("BPOW_REPLICA_ID", "server-1")
+	defer os.Unsetenv("BPOW_HUB_MODE")
+	defer os.Unsetenv("BPOW_REPLICA_ID")
+	utils.AssertEqual(t, "distributed", GetHubMode())
+	utils.AssertEqual(t, "server-1", GetReplicaID())
+}
+
+func TestGetValidationWorkers(t *testing.T) {
+	utils.AssertEqual(t, runtime.NumCPU(), GetValidationWorkers())
+	os.Setenv("BPOW_VALIDATION_WORKERS", "4")
+	defer os.Unsetenv("BPOW_VALIDATION_WORKERS")
+	utils.AssertEqual(t, 4, GetValidationWorkers())
+	os.Setenv("BPOW_VALIDATION_WORKERS", "0")
+	utils.AssertEqual(t, runtime.NumCPU(), GetValidationWorkers())
+}
+
+func TestGetEmailProvider(t *testing.T) {
+	utils.AssertEqual(t, "smtp", GetEmailProvider())
+	os.Setenv("BPOW_EMAIL_PROVIDER", "SendGrid")
+	defer os.Unsetenv("BPOW_EMAIL_PROVIDER")
+	utils.AssertEqual(t, "sendgrid", GetEmailProvider())
+}
+
+func TestSimulationSettings(t *testing.T) {
+	utils.AssertEqual(t, 0, GetSimulatedWorkers())
+	utils.AssertEqual(t, 500*time.Millisecond, GetSimulatedLatency())
+	utils.AssertEqual(t, 0.0, GetSimulatedErrorPercent())
+
+	os.Setenv("BPOW_SIMULATED_WORKERS", "5")
+	os.Setenv("BPOW_SIMULATED_LATENCY", "2s")
+	os.Setenv("BPOW_SIMULATED_ERROR_PERCENT", "10")
+	defer os.Unsetenv("BPOW_SIMULATED_WORKERS")
+	defer os.Unsetenv("BPOW_SIMULATED_LATENCY")
+	defer os.Unsetenv("BPOW_SIMULATED_ERROR_PERCENT")
+	utils.AssertEqual(t, 5, GetSimulatedWorkers())
+	utils.AssertEqual(t, 2*time.Second, GetSimulatedLatency())
+	utils.AssertEqual(t, 10.0, GetSimulatedErrorPercent())
+
+	// Never outside development
+	os.Setenv("ENVIRONMENT", "production")
+	defer os.Unsetenv("ENVIRONMENT")
+	utils.AssertEqual(t, 0, GetSimulatedWorkers())
+}
+
+func TestGetTwoFactorKey(t *testing.T) {
+	derived, err := GetTwoFactorKey()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 32, len(derived))
+
+	os.Setenv("BPOW_TWO_FACTOR_KEY", strings.Repeat("ab", 32))
+	defer os.Unsetenv("BPOW_TWO_FACTOR_KEY")
+	key, err := GetTwoFactorKey()
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, byte(0xab), key[31])
+	utils.AssertNotEqual(t, derived, key)
+
+	os.Setenv("BPOW_TWO_FACTOR_KEY", "short")
+	_, err = GetTwoFactorKey()
+	utils.AssertNotEqual(t, nil, err)
+}
+
+func TestGetAllowedOrigins(t *testing.T) {
+	utils.AssertEqual(t, []string{"*"}, GetAllowedOrigins())
+
+	os.Setenv("BPOW_ALLOWED_ORIGINS", "https://banano.cc, https://*.banano.cc,")
+	defer os.Unsetenv("BPOW_ALLOWED_ORIGINS")
+	utils.AssertEqual(t, []string{"https://banano.cc", "https://*.banano.cc"}, GetAllowedOrigins())
+
+	// Nothing cross-origin outside development unless it's set
+	os.Setenv("ENVIRONMENT", "production")
+	defer os.Unsetenv("ENVIRONMENT")
+	os.Unsetenv("BPOW_ALLOWED_ORIGINS")
+	utils.AssertEqual(t, 0, len(GetAllowedOrigins()))
+}
@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"strconv"
 )
 
 const rawPerBananoStr = "100000000000000000000000000000"
 
 var rawPerBanano, _ = new(big.Float).SetString(rawPerBananoStr)
 
+// RawPerBananoInt is 1 BANANO in raw, callers must not modify it
+var RawPerBananoInt, _ = new(big.Int).SetString(rawPerBananoStr, 10)
+
 const bananoPrecision = 100 // 0.01 BANANO precision
 
 // Raw to Big - converts raw amount to a big.Int
@@ -38,12 +42,57 @@ func RawToBanano(raw string, truncate bool) (float64, error) {
 	return math.Trunc(f/0.01) * 0.01, nil
 }
 
-// BananoToRaw - Converts Banano amount to Raw amount
+// BananoToRaw - Converts Banano amount to Raw amount, rounded to 0.01 BANANO
 func BananoToRaw(banano float64) string {
-	bananoInt := int(banano * 100)
+	// Rounded, 1.13 * 100 is 112.99999999999999
+	bananoInt := int(math.Round(banano * 100))
 	bananoRaw, _ := new(big.Int).SetString("1000000000000000000000000000", 10)
 
 	res := bananoRaw.Mul(bananoRaw, big.NewInt(int64(bananoInt)))
 
 	return fmt.Sprintf("%d", res)
 }
+
+// BananoToRawExact - Converts a Banano amount to Raw from its shortest decimal form, so 0.01 is exactly 10^27 raw
+// Fails for amounts that are negative or more precise than 1 raw
+func BananoToRawExact(banano float64) (*big.Int, error) {
+	if banano < 0 || math.IsInf(banano, 0) || math.IsNaN(banano) {
+		return nil, fmt.Errorf("%v is not a Banano amount", banano)
+	}
+	raw, _ := new(big.Rat).SetString(strconv.FormatFloat(banano, 'f', -1, 64))
+	raw.Mul(raw, new(big.Rat).SetInt(RawPerBananoInt))
+	if !raw.IsInt() {
+		return nil, fmt.Errorf("%v Banano is more precise than 1 raw", banano)
+	}
+	return new(big.Int).Set(raw.Num()), nil
+}
+
+// BananoIntToRaw - Converts a whole Banano amount, e.g. a prize pool, to Raw
+func BananoIntToRaw(banano int) *big.Int {
+	return new(big.Int).Mul(big.NewInt(int64(banano)), RawPerBananoInt)
+}
+
+// RawIntToBanano - Converts a Raw amount to Banano for display, only use the result for formatting
+func RawIntToBanano(raw *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(raw), rawPerBanano).Float64()
+	return f
+}
+
+// FormatRaw - Formats a Raw amount as Banano with the given decimals, truncated rather than rounded so it's never more than the amount
+func FormatRaw(raw *big.Int, decimals int) string {
+	sign := ""
+	abs := new(big.Int).Abs(raw)
+	if raw.Sign() < 0 {
+		sign = "-"
+	}
+	whole, fraction := new(big.Int).QuoRem(abs, RawPerBananoInt, new(big.Int))
+	if decimals <= 0 {
+		return sign + whole.String()
+	}
+	// Pad the fraction to the 29 digits of a raw amount before cutting it down
+	digits := fmt.Sprintf("%0*s", len(rawPerBananoStr)-1, fraction.String())
+	if decimals < len(digits) {
+		digits = digits[:decimals]
+	}
+	return fmt.Sprintf("%s%s.%s", sign, whole.String(), digits)
+}
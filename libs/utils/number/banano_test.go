@@ -62,6 +62,74 @@ func TestBananoToRaw(t *testing.T) {
 	if converted != expected {
 		t.Errorf("Expected %s but got %s", expected, converted)
 	}
+	// 1.13 isn't exact as a float
+	expected = "113000000000000000000000000000"
+	amount = 1.13
+	converted = BananoToRaw(amount)
+	if converted != expected {
+		t.Errorf("Expected %s but got %s", expected, converted)
+	}
+}
+
+func TestBananoToRawExact(t *testing.T) {
+	for amount, expected := range map[float64]string{
+		0:        "0",
+		0.01:     "1000000000000000000000000000",
+		1.13:     "113000000000000000000000000000",
+		0.000123: "12300000000000000000000000",
+	} {
+		converted, err := BananoToRawExact(amount)
+		if err != nil || converted.String() != expected {
+			t.Errorf("Expected %s but got %v %v", expected, converted, err)
+		}
+	}
+	if _, err := BananoToRawExact(1e-30); err == nil {
+		t.Errorf("Expected an error for less than 1 raw")
+	}
+	if _, err := BananoToRawExact(-1); err == nil {
+		t.Errorf("Expected an error for a negative amount")
+	}
+}
+
+func TestBananoIntToRaw(t *testing.T) {
+	expected := "100000000000000000000000000000000"
+	converted := BananoIntToRaw(1000).String()
+	if converted != expected {
+		t.Errorf("Expected %s but got %s", expected, converted)
+	}
+}
+
+func TestRawIntToBanano(t *testing.T) {
+	raw, _ := RawToBigInt("101900000000000000000000000000")
+	converted := RawIntToBanano(raw)
+	if converted != 1.019 {
+		t.Errorf("Expected %f but got %f", 1.019, converted)
+	}
+}
+
+func TestFormatRaw(t *testing.T) {
+	cases := map[string]string{
+		"101900000000000000000000000000":   "1.01",
+		"100000000000000000000000000000":   "1.00",
+		"1":                                "0.00",
+		"333333333333333333333333333333":   "3.33",
+		"-150000000000000000000000000000":  "-1.50",
+		"10000000000000000000000000000000": "100.00",
+	}
+	for raw, expected := range cases {
+		rawInt, _ := RawToBigInt(raw)
+		if converted := FormatRaw(rawInt, 2); converted != expected {
+			t.Errorf("Expected %s but got %s", expected, converted)
+		}
+	}
+	rawInt, _ := RawToBigInt("1")
+	if converted := FormatRaw(rawInt, 29); converted != "0.00000000000000000000000000001" {
+		t.Errorf("Expected %s but got %s", "0.00000000000000000000000000001", converted)
+	}
+	rawInt, _ = RawToBigInt("190000000000000000000000000000")
+	if converted := FormatRaw(rawInt, 0); converted != "1" {
+		t.Errorf("Expected %s but got %s", "1", converted)
+	}
 }
 
 // 100000000000000000000000000000
@@ -1,8 +1,50 @@
 package validation
 
-import "net/mail"
+import (
+	"net/mail"
+	"strings"
+)
 
 func IsValidEmail(email string) bool {
 	_, err := mail.ParseAddress(email)
 	return err == nil
 }
+
+// Which aliases of a mailbox NormalizeEmail folds together, case is always folded
+const (
+	EmailAliasesNone = "none"
+	// Dots and +tags of gmail.com and googlemail.com addresses
+	EmailAliasesGmail = "gmail"
+	// Gmail's aliases and +tags of every domain
+	EmailAliasesAll = "all"
+)
+
+var EmailAliasModes = []string{EmailAliasesNone, EmailAliasesGmail, EmailAliasesAll}
+
+var gmailDomains = []string{"gmail.com", "googlemail.com"}
+
+// NormalizeEmail returns the mailbox an email delivers to, so aliases of it can be told apart from different people
+func NormalizeEmail(email string, aliases string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at < 0 || aliases == EmailAliasesNone {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	gmail := false
+	for _, d := range gmailDomains {
+		if domain == d {
+			gmail = true
+		}
+	}
+	if gmail || aliases == EmailAliasesAll {
+		if tag := strings.Index(local, "+"); tag > 0 {
+			local = local[:tag]
+		}
+	}
+	if gmail {
+		local = strings.ReplaceAll(local, ".", "")
+		domain = gmailDomains[0]
+	}
+	return local + "@" + domain
+}
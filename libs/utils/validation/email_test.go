@@ -10,3 +10,15 @@ func TestEmailValidation(t *testing.T) {
 	utils.AssertEqual(t, true, IsValidEmail("helloworld@gmail.com"))
 	utils.AssertEqual(t, false, IsValidEmail("helloworldgmail.com"))
 }
+
+func TestNormalizeEmail(t *testing.T) {
+	utils.AssertEqual(t, "hello.world+pow@example.com", NormalizeEmail(" Hello.World+Pow@Example.com ", EmailAliasesNone))
+	utils.AssertEqual(t, "helloworld@gmail.com", NormalizeEmail("Hello.World+pow@gmail.com", EmailAliasesGmail))
+	utils.AssertEqual(t, "helloworld@gmail.com", NormalizeEmail("hello.world@googlemail.com", EmailAliasesGmail))
+	utils.AssertEqual(t, "hello.world+pow@example.com", NormalizeEmail("hello.world+pow@example.com", EmailAliasesGmail))
+	utils.AssertEqual(t, "hello.world@example.com", NormalizeEmail("hello.world+pow@example.com", EmailAliasesAll))
+	utils.AssertEqual(t, "helloworld@gmail.com", NormalizeEmail("hello.world+pow@gmail.com", EmailAliasesAll))
+	// A local part that's only a tag is left alone
+	utils.AssertEqual(t, "+pow@example.com", NormalizeEmail("+pow@example.com", EmailAliasesAll))
+	utils.AssertEqual(t, "not an email", NormalizeEmail("Not an email", EmailAliasesAll))
+}
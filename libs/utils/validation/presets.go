@@ -0,0 +1,77 @@
+package validation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A named difficulty a network needs for a kind of block
+// Networks raise their difficulty in epoch upgrades, every epoch that changed a preset's threshold is a version of it
+type DifficultyPreset struct {
+	Name      string
+	Network   string
+	Version   int
+	Threshold uint64
+}
+
+// Requests with the custom preset give their own hex threshold
+const CustomPreset = "custom"
+
+// Oldest version first
+var DifficultyPresets = []DifficultyPreset{
+	{Name: "banano_send", Network: "banano", Version: 1, Threshold: 0xfffffe0000000000},
+	{Name: "banano_receive", Network: "banano", Version: 1, Threshold: 0xfffffe0000000000},
+	// Epoch 1 had one threshold for every block
+	{Name: "nano_send", Network: "nano", Version: 1, Threshold: 0xffffffc000000000},
+	{Name: "nano_receive", Network: "nano", Version: 1, Threshold: 0xffffffc000000000},
+	{Name: "nano_send", Network: "nano", Version: 2, Threshold: 0xfffffff800000000},
+	{Name: "nano_receive", Network: "nano", Version: 2, Threshold: 0xfffffe0000000000},
+}
+
+// The name of this version, e.g. nano_send_v2
+func (p DifficultyPreset) VersionedName() string {
+	return fmt.Sprintf("%s_v%d", p.Name, p.Version)
+}
+
+func (p DifficultyPreset) DifficultyMultiplier() int {
+	return MultiplierForThreshold(p.Threshold)
+}
+
+// Whether the preset is the newest version of its name
+func (p DifficultyPreset) Current() bool {
+	current, _ := LookupDifficultyPreset(p.Name)
+	return current.Version == p.Version
+}
+
+// A name without a version is the newest version, false if there's no such preset
+func LookupDifficultyPreset(name string) (DifficultyPreset, bool) {
+	name = strings.ToLower(name)
+	var found DifficultyPreset
+	ok := false
+	for _, preset := range DifficultyPresets {
+		if preset.VersionedName() == name {
+			return preset, true
+		}
+		if preset.Name == name && (!ok || preset.Version > found.Version) {
+			found, ok = preset, true
+		}
+	}
+	return found, ok
+}
+
+// Threshold of a custom preset, as 16 hex characters
+func ParseThreshold(hexThreshold string) (uint64, error) {
+	if len(hexThreshold) != 16 {
+		return 0, fmt.Errorf("difficulty must be 16 hex characters")
+	}
+	return strconv.ParseUint(hexThreshold, 16, 64)
+}
+
+// The lowest difficulty multiplier whose work meets threshold, 1 for thresholds at or below the base difficulty
+func MultiplierForThreshold(threshold uint64) int {
+	if threshold <= baseMaxUint64-baseDifficulty {
+		return 1
+	}
+	return int(baseDifficulty/(baseMaxUint64-threshold+1)) + 1
+}
@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"testing"
+
+	utils "github.com/bananocoin/boompow/libs/utils/testing"
+)
+
+func TestMultiplierForThreshold(t *testing.T) {
+	utils.AssertEqual(t, 1, MultiplierForThreshold(0))
+	utils.AssertEqual(t, 1, MultiplierForThreshold(0xfffffe0000000000))
+	utils.AssertEqual(t, 8, MultiplierForThreshold(0xffffffc000000000))
+	utils.AssertEqual(t, 64, MultiplierForThreshold(0xfffffff800000000))
+	// Rounded up, work at the multiplier is at least as hard
+	utils.AssertEqual(t, 65, MultiplierForThreshold(0xfffffff800000001))
+	for _, threshold := range []uint64{0xfffffe0000000001, 0xfffffff000000000, 0xfffffff800000001, 0xffffffffffff0000} {
+		multiplier := MultiplierForThreshold(threshold)
+		utils.AssertEqual(t, true, CalculateDifficulty(int64(multiplier)) >= threshold)
+		utils.AssertEqual(t, true, CalculateDifficulty(int64(multiplier-1)) < threshold)
+	}
+}
+
+func TestLookupDifficultyPreset(t *testing.T) {
+	preset, ok := LookupDifficultyPreset("nano_send")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, 2, preset.Version)
+	utils.AssertEqual(t, 64, preset.DifficultyMultiplier())
+	utils.AssertEqual(t, true, preset.Current())
+
+	preset, ok = LookupDifficultyPreset("NANO_SEND_V1")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, "nano_send_v1", preset.VersionedName())
+	utils.AssertEqual(t, 8, preset.DifficultyMultiplier())
+	utils.AssertEqual(t, false, preset.Current())
+
+	preset, ok = LookupDifficultyPreset("banano_receive")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, 1, preset.DifficultyMultiplier())
+
+	_, ok = LookupDifficultyPreset("nano_send_v3")
+	utils.AssertEqual(t, false, ok)
+	_, ok = LookupDifficultyPreset(CustomPreset)
+	utils.AssertEqual(t, false, ok)
+
+	threshold, err := ParseThreshold("fffffff800000000")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, uint64(0xfffffff800000000), threshold)
+	_, err = ParseThreshold("fffffff8")
+	utils.AssertNotEqual(t, nil, err)
+	_, err = ParseThreshold("zzzzzzzzzzzzzzzz")
+	utils.AssertNotEqual(t, nil, err)
+}
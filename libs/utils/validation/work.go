@@ -25,7 +25,11 @@ func CalculateDifficulty(multiplier int64) uint64 {
 }
 
 func IsWorkValid(previous string, difficultyMultiplier int, w string) bool {
-	difficult := CalculateDifficulty(int64(difficultyMultiplier))
+	return IsWorkValidForThreshold(previous, CalculateDifficulty(int64(difficultyMultiplier)), w)
+}
+
+// Same as IsWorkValid, but for an arbitrary difficulty threshold instead of a multiple of the base difficulty
+func IsWorkValidForThreshold(previous string, difficult uint64, w string) bool {
 	previousEnc, err := hex.DecodeString(previous)
 	if err != nil {
 		return false
@@ -32,3 +32,12 @@ func TestWorkValidation(t *testing.T) {
 	workResult = "00000000002d7708"
 	utils.AssertEqual(t, false, IsWorkValid(hash, 1, workResult))
 }
+
+func TestWorkValidationForThreshold(t *testing.T) {
+	workResult := "205452237a9b01f4"
+	hash := "3F93C5CD2E314FA16702189041E68E68C07B27961BF37F0B7705145BEFBA3AA3"
+
+	utils.AssertEqual(t, true, IsWorkValidForThreshold(hash, 0, workResult))
+	utils.AssertEqual(t, true, IsWorkValidForThreshold(hash, CalculateDifficulty(1), workResult))
+	utils.AssertEqual(t, false, IsWorkValidForThreshold(hash, baseMaxUint64, workResult))
+}
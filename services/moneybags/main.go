@@ -6,27 +6,26 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"strings"
+	"time"
 
 	"github.com/bananocoin/boompow/apps/server/src/database"
-	"github.com/bananocoin/boompow/apps/server/src/repository"
-	"github.com/bananocoin/boompow/libs/models"
+	"github.com/bananocoin/boompow/libs/banano"
 	"github.com/bananocoin/boompow/libs/utils"
-	"github.com/bananocoin/boompow/libs/utils/number"
-	"github.com/google/uuid"
+	"github.com/go-co-op/gocron"
 	"github.com/joho/godotenv"
-	"gorm.io/gorm"
 )
 
 // The way this process works is:
 // 1) We get the unpaid works for each user
-// 2) We figure out what percentage of the total prize pool this user has earned
+// 2) We figure out what this user has earned, at the award rate in effect when the work was done or as a percentage of the total prize pool
 // 3) We build payments for each user based on that amount and save in database
 // 4) We ship the payments
+// Either as two separate runs (without and with -rpc-send), or on a schedule with -schedule
 
 func main() {
 	dryRun := flag.Bool("dry-run", false, "Dry run")
 	rpcSend := flag.Bool("rpc-send", false, "Broadcast pending payments")
+	schedule := flag.Bool("schedule", false, "Keep running and pay out on the BPOW_PAYOUT_SCHEDULE cron schedule (UTC), computing and broadcasting payments each time")
 	flag.Parse()
 
 	godotenv.Load()
@@ -45,105 +44,25 @@ func main() {
 		panic(err)
 	}
 
-	userRepo := repository.NewUserService(db)
-	workRepo := repository.NewWorkService(db, userRepo)
-	paymentRepo := repository.NewPaymentService(db)
-	rppClient := &RPCClient{
-		Url: os.Getenv("RPC_URL"),
-	}
-
-	// Do all of this within a transaction
-	err = db.Transaction(func(tx *gorm.DB) error {
-		if !*rpcSend {
-			fmt.Println("👽 Getting unpaid works...")
-			var res []repository.UnpaidWorkResult
-			if *dryRun {
-				fmt.Println("🏃 Dry run mode - not actually sending payments")
-				res, err = workRepo.GetUnpaidWorkCount(tx)
-			} else {
-				res, err = workRepo.GetUnpaidWorkCountAndMarkAllPaid(tx)
-			}
-
-			if err != nil {
-				fmt.Printf("❌ Error retrieving unpaid works %v", err)
-				return err
-			}
-
-			if len(res) == 0 {
-				fmt.Println("🤷 No unpaid works found")
-				return nil
-			}
-
-			// Compute the entire sum of the unpaid works
-			totalSum := 0
-			for _, v := range res {
-				totalSum += v.DifficultySum
-			}
-
-			sendRequestsRaw := []models.SendRequest{}
-
-			// Compute the percentage each user has earned and build payments
-			for _, v := range res {
-				percentageOfPool := float64(v.DifficultySum) / float64(totalSum)
-				paymentAmount := percentageOfPool * float64(utils.GetTotalPrizePool())
-
-				sendRequestsRaw = append(sendRequestsRaw, models.SendRequest{
-					BaseRequest: models.SendAction,
-					Wallet:      utils.GetWalletID(),
-					Source:      utils.GetWalletAddress(),
-					Destination: v.BanAddress,
-					AmountRaw:   number.BananoToRaw(paymentAmount),
-					// Just a unique payment identifier
-					ID:     fmt.Sprintf("%s:%s", v.BanAddress, uuid.New().String()),
-					PaidTo: v.ProvidedBy,
-				})
-
-				fmt.Printf("💸 %s has earned %f%% of the pool, and will be paid %f\n", v.BanAddress, percentageOfPool*100, paymentAmount)
-			}
+	payer := NewPayer(db, banano.NewClient(os.Getenv("RPC_URL")))
 
-			if !*dryRun {
-				err = paymentRepo.BatchCreateSendRequests(tx, sendRequestsRaw)
-				if err != nil {
-					fmt.Printf("❌ Error creating send requests %v", err)
-					return err
-				}
-			}
-			return nil
-		}
-
-		// Alternative job retrieves all payments from database with null block-hash and broadcasts them to the node
-		fmt.Println("👽 Getting pending payments...")
-
-		payments, err := paymentRepo.GetPendingPayments(tx)
-		if err != nil {
-			return err
-		}
-
-		for _, payment := range payments {
-			if !*dryRun {
-				// Keep original to update the database
-				origPaymentID := strings.Clone(payment.ID)
-				// Ensure ID is not longer than 64 chars
-				payment.ID = Sha256(payment.ID)
-				res, err := rppClient.MakeSendRequest(payment)
-				if err != nil {
-					fmt.Printf("\n❌ Error sending payment, ID %s, %v", origPaymentID, err)
-					fmt.Printf("\nContinuing tho...")
-					continue
-				}
-				fmt.Printf("\n💸 Sent payment, ID %s, %v", origPaymentID, res.Block)
-				err = paymentRepo.SetBlockHash(tx, origPaymentID, res.Block)
-				if err != nil {
-					fmt.Printf("\n❌ Error setting payment block hash, ID %s, hash %s, %v", origPaymentID, res.Block, err)
-					fmt.Printf("\nContinuing tho...")
-				}
-			} else {
-				fmt.Printf("\n💸 Would send payment, amount %s, to %s", payment.AmountRaw, payment.Destination)
-			}
+	if *schedule {
+		scheduler := gocron.NewScheduler(time.UTC)
+		// A payout that runs long delays the next one instead of overlapping it
+		scheduler.SingletonModeAll()
+		if _, err := scheduler.Cron(utils.GetPayoutSchedule()).Do(payer.Run); err != nil {
+			fmt.Printf("❌ Invalid payout schedule %s %v\n", utils.GetPayoutSchedule(), err)
+			os.Exit(1)
 		}
+		fmt.Printf("📅 Paying out on schedule %s\n", utils.GetPayoutSchedule())
+		scheduler.StartBlocking()
+	}
 
-		return nil
-	})
+	if *rpcSend {
+		err = payer.SendPendingPayments(*dryRun)
+	} else {
+		err = payer.ComputePayments(*dryRun)
+	}
 
 	database.GetRedisDB().WipeClientScores()
 
@@ -151,6 +70,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *rpcSend && !*dryRun {
+		payer.RecordPrizePoolBalances()
+	}
+
 	// Success
 	os.Exit(0)
 }
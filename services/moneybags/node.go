@@ -0,0 +1,16 @@
+package main
+
+import (
+	"context"
+
+	"github.com/bananocoin/boompow/libs/banano"
+)
+
+// What moneybags needs from a node, anything that speaks its send and account_balance RPCs can stand in for the RPC client
+// Sends with an id the node has seen before must not send again
+type Node interface {
+	Send(ctx context.Context, request banano.SendRequest) (string, error)
+	AccountBalance(ctx context.Context, account string) (*banano.AccountBalance, error)
+}
+
+var _ Node = &banano.Client{}
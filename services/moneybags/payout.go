@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bananocoin/boompow/apps/server/src/database"
+	"github.com/bananocoin/boompow/apps/server/src/repository"
+	"github.com/bananocoin/boompow/libs/banano"
+	"github.com/bananocoin/boompow/libs/models"
+	"github.com/bananocoin/boompow/libs/utils/number"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Computes what providers earned and pays them through the node
+type Payer struct {
+	db                *gorm.DB
+	workRepo          repository.WorkRepo
+	paymentRepo       repository.PaymentRepo
+	tenantRepo        repository.TenantRepo
+	payoutAddressRepo repository.PayoutAddressRepo
+	payoutCycleRepo   repository.PayoutCycleRepo
+	node              Node
+}
+
+func NewPayer(db *gorm.DB, node Node) *Payer {
+	userRepo := repository.NewUserService(db)
+	return &Payer{
+		db:                db,
+		workRepo:          repository.NewWorkService(db, userRepo),
+		paymentRepo:       repository.NewPaymentService(db),
+		tenantRepo:        repository.NewTenantService(db),
+		payoutAddressRepo: repository.NewPayoutAddressService(db),
+		payoutCycleRepo:   repository.NewPayoutCycleService(db),
+		node:              node,
+	}
+}
+
+// Creates the payments for the unpaid works of every tenant and marks the works paid, all within one transaction
+func (p *Payer) ComputePayments(dryRun bool) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		locked, err := p.payoutCycleRepo.TryLockPayouts(tx)
+		if err != nil {
+			fmt.Printf("❌ Error locking payouts %v", err)
+			return err
+		}
+		if !locked {
+			fmt.Println("🔒 Another payout job is computing payments, skipping")
+			return nil
+		}
+
+		tenants, err := p.tenantRepo.GetAllTenants()
+		if err != nil {
+			fmt.Printf("❌ Error retrieving tenants %v", err)
+			return err
+		}
+
+		// Every tenant is its own pool, with its own prize pool and wallet
+		for _, tenant := range tenants {
+			fmt.Printf("👽 Getting unpaid works for tenant %s...\n", tenant.ID)
+			var res []repository.UnpaidWorkResult
+			if dryRun {
+				fmt.Println("🏃 Dry run mode - not actually sending payments")
+				res, err = p.workRepo.GetUnpaidWorkCount(tx, tenant.ID)
+			} else {
+				res, err = p.workRepo.GetUnpaidWorkCountAndMarkAllPaid(tx, tenant.ID)
+			}
+
+			if err != nil {
+				fmt.Printf("❌ Error retrieving unpaid works %v", err)
+				return err
+			}
+
+			if len(res) == 0 {
+				fmt.Println("🤷 No unpaid works found")
+				continue
+			}
+
+			// Compute the entire sum of the unpaid works
+			totalSum := 0
+			for _, v := range res {
+				totalSum += v.DifficultySum
+			}
+
+			// Providers can split their payouts between several addresses
+			providerIDs := make([]uuid.UUID, len(res))
+			for i, v := range res {
+				providerIDs[i] = v.ProvidedBy
+			}
+			payoutAddresses, err := p.payoutAddressRepo.GetPayoutAddressesForUsers(tx, providerIDs)
+			if err != nil {
+				fmt.Printf("❌ Error retrieving payout addresses %v", err)
+				return err
+			}
+
+			// What the payments are computed from, so anyone can check them in the cycle's report
+			// Dry runs don't record it, their payments only get a made up cycle
+			cycleID := uuid.New()
+			if !dryRun {
+				cycle, err := p.payoutCycleRepo.CreatePayoutCycle(tx, tenant.ID, tenant.GetPrizePool(), res)
+				if err != nil {
+					fmt.Printf("❌ Error creating payout cycle %v", err)
+					return err
+				}
+				fmt.Printf("🧾 Payout cycle %s\n", cycle.ID)
+				cycleID = cycle.ID
+			}
+
+			sendRequestsRaw := []models.SendRequest{}
+
+			// Compute what each user has earned, at the award rate in effect when the work was done or as a share of the prize pool
+			paymentAmounts, err := repository.PayoutAmounts(res, tenant.GetPrizePool())
+			if err != nil {
+				fmt.Printf("❌ Error computing payouts %v", err)
+				return err
+			}
+
+			// Build payments
+			for i, v := range res {
+				percentageOfPool := float64(v.DifficultySum) / float64(totalSum)
+				paymentAmount := paymentAmounts[i]
+
+				fmt.Printf("💸 %s has earned %f%% of the pool, and will be paid %s\n", v.BanAddress, percentageOfPool*100, number.FormatRaw(paymentAmount, 6))
+
+				for _, share := range repository.SplitPayout(paymentAmount, v.BanAddress, payoutAddresses[v.ProvidedBy]) {
+					sendRequestsRaw = append(sendRequestsRaw, models.SendRequest{
+						BaseRequest: models.SendAction,
+						Wallet:      tenant.GetWalletID(),
+						Source:      tenant.GetWalletAddress(),
+						Destination: share.BanAddress,
+						AmountRaw:   share.AmountRaw.String(),
+						ID:          repository.PaymentSendID(cycleID, v.ProvidedBy, share.BanAddress),
+						PaidTo:      v.ProvidedBy,
+					})
+					if share.BanAddress != v.BanAddress {
+						fmt.Printf("   ↳ %s to %s\n", number.FormatRaw(share.AmountRaw, 6), share.BanAddress)
+					}
+				}
+			}
+
+			if !dryRun {
+				err = p.paymentRepo.BatchCreateSendRequests(tx, tenant.ID, &cycleID, sendRequestsRaw)
+				if err != nil {
+					fmt.Printf("❌ Error creating send requests %v", err)
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Broadcasts the payments that have no block hash yet
+// Each one is claimed and its block hash recorded in its own transaction, so a crash or another job running at the same time never sends it twice
+func (p *Payer) SendPendingPayments(dryRun bool) error {
+	fmt.Println("👽 Getting pending payments...")
+
+	payments, err := p.paymentRepo.GetPendingPayments(p.db)
+	if err != nil {
+		return err
+	}
+
+	for _, payment := range payments {
+		if dryRun {
+			fmt.Printf("\n💸 Would send payment, amount %s, to %s", payment.AmountRaw, payment.Destination)
+			continue
+		}
+		err := p.db.Transaction(func(tx *gorm.DB) error {
+			claimed, err := p.paymentRepo.ClaimPendingPayment(tx, payment.ID)
+			if err != nil || !claimed {
+				return err
+			}
+			// The node only sends once per id, in case a payment was sent but its block hash wasn't recorded
+			// Ensure ID is not longer than 64 chars
+			block, err := p.node.Send(context.Background(), banano.SendRequest{
+				Wallet:      payment.Wallet,
+				Source:      payment.Source,
+				Destination: payment.Destination,
+				AmountRaw:   payment.AmountRaw,
+				ID:          Sha256(payment.ID),
+			})
+			if err != nil {
+				fmt.Printf("\n❌ Error sending payment, ID %s, %v", payment.ID, err)
+				return err
+			}
+			fmt.Printf("\n💸 Sent payment, ID %s, %v", payment.ID, block)
+			if err := p.paymentRepo.SetBlockHash(tx, payment.ID, block); err != nil {
+				fmt.Printf("\n❌ Error setting payment block hash, ID %s, hash %s, %v", payment.ID, block, err)
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("\nContinuing tho...")
+		}
+	}
+
+	return nil
+}
+
+// Once the payments are out the wallet balances are what's left for the next cycles, shown in the payout calendar
+func (p *Payer) RecordPrizePoolBalances() {
+	tenants, err := p.tenantRepo.GetAllTenants()
+	if err != nil {
+		fmt.Printf("\n❌ Error retrieving tenants %v", err)
+		return
+	}
+	for _, tenant := range tenants {
+		res, err := p.node.AccountBalance(context.Background(), tenant.GetWalletAddress())
+		if err != nil {
+			fmt.Printf("\n❌ Error getting the wallet balance of tenant %s, %v", tenant.ID, err)
+			continue
+		}
+		balance, err := number.RawToBigInt(res.Balance)
+		if err != nil {
+			fmt.Printf("\n❌ Error converting the wallet balance of tenant %s, %v", tenant.ID, err)
+			continue
+		}
+		if err := database.GetRedisDB().SetPrizePoolBalance(tenant.ID, database.PrizePoolBalance{Raw: balance.String(), CheckedAt: time.Now()}); err != nil {
+			fmt.Printf("\n❌ Error recording the wallet balance of tenant %s, %v", tenant.ID, err)
+			continue
+		}
+		fmt.Printf("\n🏦 Wallet of tenant %s has %s left", tenant.ID, number.FormatRaw(balance, 2))
+	}
+}
+
+// A whole payout, what the scheduler runs
+func (p *Payer) Run() {
+	fmt.Printf("\n⏰ Paying out at %s\n", time.Now().UTC().Format(time.RFC3339))
+	err := p.ComputePayments(false)
+	database.GetRedisDB().WipeClientScores()
+	if err != nil {
+		// Whatever is pending from earlier cycles still goes out
+		fmt.Printf("\n❌ Error computing payments, only sending pending ones %v", err)
+	}
+	if err := p.SendPendingPayments(false); err != nil {
+		fmt.Printf("\n❌ Error sending pending payments %v", err)
+	}
+	p.RecordPrizePoolBalances()
+	fmt.Println()
+}